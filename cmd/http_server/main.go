@@ -5,6 +5,7 @@ import (
 	_ "go-photo/docs"
 	"go-photo/internal/app"
 	"log"
+	"os"
 )
 
 // @title Go-Photo API
@@ -22,7 +23,27 @@ import (
 func main() {
 	ctx := context.Background()
 
-	a, err := app.NewApp(ctx)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := app.RunMigrate(ctx, os.Args[2:]); err != nil {
+				log.Fatalf("migrate: %s", err.Error())
+			}
+			return
+		case "fsck":
+			if err := app.RunFsck(ctx, os.Args[2:]); err != nil {
+				log.Fatalf("fsck: %s", err.Error())
+			}
+			return
+		case "config":
+			if err := app.RunConfig(os.Args[2:]); err != nil {
+				log.Fatalf("config: %s", err.Error())
+			}
+			return
+		}
+	}
+
+	a, err := app.NewApp(ctx, os.Args[1:])
 	if err != nil {
 		log.Fatalf("failed to init app: %s", err.Error())
 	}
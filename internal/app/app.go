@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -9,54 +10,138 @@ import (
 	log "github.com/sirupsen/logrus"
 	"go-photo/internal/config"
 	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/v1/albums"
 	"go-photo/internal/handler/v1/auth"
 	"go-photo/internal/handler/v1/docs"
+	"go-photo/internal/handler/v1/health"
 	"go-photo/internal/handler/v1/photos"
 	"go-photo/internal/handler/v1/public"
+	"go-photo/internal/handler/v1/publishing"
+	"go-photo/internal/handler/v1/sharing"
+	"go-photo/internal/handler/v1/timeline"
+	"go-photo/internal/handler/v1/trash"
 	"go-photo/internal/handler/v1/user"
+	"go-photo/internal/handler/v1/watermark"
+	"go-photo/internal/utils"
 	desc "go-photo/pkg/account_v1"
+	"go-photo/pkg/closer"
+	"go-photo/pkg/migrator"
 	"go-photo/pkg/repository"
+	"go-photo/pkg/tracing"
+	"go-photo/schema"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
 type App struct {
+	grpcConn   *grpc.ClientConn
 	grpcClient desc.AccountServiceClient
-	httpServer *gin.Engine
+	httpServer *http.Server
 
 	db *sqlx.DB
 
+	tracerProvider *sdktrace.TracerProvider
+
+	// args аргументы командной строки (без имени программы), переопределяющие конфигурацию
+	args []string
+
 	sp *serviceProvider
+
+	// closer освобождает ресурсы при остановке в обратном порядке их создания
+	closer *closer.Closer
+	// draining выставляется при получении сигнала остановки, /readyz начинает отвечать 503
+	draining atomic.Bool
 }
 
-func NewApp(ctx context.Context) (*App, error) {
-	a := &App{}
+func NewApp(ctx context.Context, args []string) (*App, error) {
+	a := &App{args: args, closer: closer.New()}
 
 	err := a.initDeps(ctx)
 	if err != nil {
-		return nil, err
+		closeErr := a.closer.CloseAll(ctx)
+		return nil, errors.Join(err, closeErr)
 	}
 
 	return a, nil
 }
 
+// Run запускает HTTP сервер и блокируется до получения SIGINT/SIGTERM или ошибки сервера.
+// После этого выполняет graceful shutdown: перестает принимать соединения,
+// дожидается завершения текущих запросов и освобождает ресурсы в пределах ShutdownTimeout.
 func (a *App) Run() error {
-	return a.runHTTPServer()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- a.runHTTPServer()
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		log.Info("shutdown signal received")
+	case err := <-serverErr:
+		if err != nil {
+			runErr = fmt.Errorf("http server failed: %w", err)
+		}
+	}
+	// Повторный сигнал завершит процесс немедленно
+	stop()
+
+	return errors.Join(runErr, a.shutdown())
+}
+
+func (a *App) shutdown() error {
+	a.draining.Store(true)
+
+	timeout := a.sp.BaseConfig().ShutdownTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Infof("shutting down, waiting up to %s for in-flight requests", timeout)
+
+	var errs []error
+	if err := a.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to shutdown http server: %w", err))
+	}
+
+	if err := a.closer.CloseAll(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		log.Info("shutdown completed")
+	}
+
+	return errors.Join(errs...)
 }
 
 func (a *App) initDeps(ctx context.Context) error {
 	inits := []func(context.Context) error{
 		a.initConfig,
-		a.initServiceProvider,
 		// TODO: см. ниже
 		a.initFolders,
 		a.initLogging,
+		a.initTracing,
 		a.initPGConnection,
+		a.initMigrations,
 		a.initGRPCClient,
+		a.initUploadExecutors,
+		a.initUploadReconciler,
+		a.initTrashPurger,
+		a.initPlaceholderBackfill,
+		a.initExifBackfill,
 		a.initHTTPServer,
 	}
 
@@ -71,17 +156,13 @@ func (a *App) initDeps(ctx context.Context) error {
 }
 
 func (a *App) initConfig(_ context.Context) error {
-	err := config.Load(".env")
+	cfg, err := loadConfig("go-photo", a.args)
 	if err != nil {
-		log.Warnf("failed to load config: %v", err)
-		log.Info("loading without .env")
+		return err
 	}
 
-	return nil
-}
+	a.sp = newServiceProvider(cfg)
 
-func (a *App) initServiceProvider(_ context.Context) error {
-	a.sp = newServiceProvider()
 	return nil
 }
 
@@ -103,18 +184,17 @@ func (a *App) initFolders(_ context.Context) error {
 
 func (a *App) initLogging(_ context.Context) error {
 	log.SetOutput(os.Stdout)
-	//log.SetFormatter(&log.TextFormatter{
-	//	ForceColors: true,
-	//})
-	log.SetFormatter(&config.CustomFormatter{
-		TimestampFormat: time.DateTime,
-	})
 
+	formatter, err := config.NewLogFormatter(a.sp.BaseConfig().LogFormat())
+	if err != nil {
+		return fmt.Errorf("failed to init logging: %w", err)
+	}
+	log.SetFormatter(formatter)
+
+	// Уровень логирования уже проверен при валидации конфигурации
 	logLevel, err := log.ParseLevel(a.sp.BaseConfig().LogLevel())
 	if err != nil {
-		log.Printf("failed to parse log level: %v", err)
-		log.Printf("use default log level: %s", log.DebugLevel)
-		logLevel = log.DebugLevel
+		return fmt.Errorf("failed to init logging: %w", err)
 	}
 
 	log.SetLevel(logLevel)
@@ -122,14 +202,58 @@ func (a *App) initLogging(_ context.Context) error {
 	return nil
 }
 
+func (a *App) initTracing(ctx context.Context) error {
+	var exporter sdktrace.SpanExporter
+
+	endpoint := a.sp.BaseConfig().OTLPEndpoint()
+	if endpoint != "" {
+		var err error
+		exporter, err = tracing.NewOTLPExporter(ctx, endpoint)
+		if err != nil {
+			return fmt.Errorf("failed to init tracing: %w", err)
+		}
+		log.Infof("exporting traces to %s", endpoint)
+	} else {
+		log.Info("otlp endpoint is not set, traces will not be exported")
+	}
+
+	a.tracerProvider = tracing.NewTracerProvider(a.sp.BaseConfig().ServiceName(), exporter)
+	tracing.SetGlobal(a.tracerProvider)
+	a.closer.Add("tracer provider", a.tracerProvider.Shutdown)
+
+	return nil
+}
+
 func (a *App) initPGConnection(_ context.Context) error {
-	pgConfig := a.sp.PSQLConfig()
+	pgConfig := a.sp.BaseConfig().PSQLConfig()
 	db, err := repository.NewPostgresDB(pgConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create postgres connection: %w with config: %v", err, pgConfig)
+		return fmt.Errorf("failed to create postgres connection to %s:%s/%s: %w", pgConfig.Host, pgConfig.Port, pgConfig.DBName, err)
 	}
 
 	a.db = db
+	a.closer.Add("postgres pool", func(_ context.Context) error {
+		return a.db.Close()
+	})
+
+	return nil
+}
+
+// initMigrations применяет миграции, если включен AUTO_MIGRATE,
+// и отказывается запускать приложение, если схема БД отстает от кода.
+func (a *App) initMigrations(ctx context.Context) error {
+	m := migrator.New(a.db.DB, schema.FS)
+
+	if a.sp.BaseConfig().AutoMigrate() {
+		log.Info("applying database migrations")
+		if err := m.Up(ctx); err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+	}
+
+	if err := m.CheckUpToDate(ctx); err != nil {
+		return fmt.Errorf("refusing to start: %w (run `migrate up` or set AUTO_MIGRATE=true)", err)
+	}
 
 	return nil
 }
@@ -137,6 +261,7 @@ func (a *App) initPGConnection(_ context.Context) error {
 func (a *App) initGRPCClient(_ context.Context) error {
 	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallSendMsgSize(100*1024*1024), // 10 MB
 			grpc.MaxCallRecvMsgSize(100*1024*1024), // 10 MB
@@ -148,6 +273,11 @@ func (a *App) initGRPCClient(_ context.Context) error {
 		return fmt.Errorf("failed to create grpc client: %w", err)
 	}
 
+	a.grpcConn = conn
+	a.closer.Add("grpc connection", func(_ context.Context) error {
+		return a.grpcConn.Close()
+	})
+
 	if conn.GetState() == connectivity.TransientFailure || conn.GetState() == connectivity.Shutdown {
 		return fmt.Errorf("grpc connection is in invalid state: %v", conn.GetState())
 	}
@@ -163,18 +293,109 @@ func (a *App) initGRPCClient(_ context.Context) error {
 	return nil
 }
 
+// initUploadExecutors создает пулы загрузки. При остановке сначала закрывается пул записи
+// на диск: его задачи еще ставят задачи в пул сохранения в БД.
+func (a *App) initUploadExecutors(_ context.Context) error {
+	dbExecutor := a.sp.UploadDBExecutor()
+	a.closer.Add("upload db executor", dbExecutor.Close)
+
+	fileExecutor := a.sp.UploadFileExecutor()
+	a.closer.Add("upload file executor", fileExecutor.Close)
+
+	return nil
+}
+
+// initUploadReconciler завершает загрузки, прерванные предыдущей остановкой,
+// и затем периодически проверяет журнал загрузок в фоне.
+func (a *App) initUploadReconciler(_ context.Context) error {
+	photoSvc := a.sp.PhotoService(a.db)
+	a.startBackgroundJob("upload reconciler", a.sp.BaseConfig().Upload().ReconcileInterval.Duration, photoSvc.ReconcileUploads)
+
+	return nil
+}
+
+// initTrashPurger периодически удаляет фото, пролежавшие в корзине дольше срока хранения.
+func (a *App) initTrashPurger(_ context.Context) error {
+	photoSvc := a.sp.PhotoService(a.db)
+	a.startBackgroundJob("trash purger", a.sp.BaseConfig().Trash().PurgeInterval.Duration, photoSvc.PurgeTrash)
+
+	return nil
+}
+
+// initPlaceholderBackfill периодически рассчитывает заглушки версий фото, для которых они еще не рассчитаны.
+func (a *App) initPlaceholderBackfill(_ context.Context) error {
+	photoSvc := a.sp.PhotoService(a.db)
+	a.startBackgroundJob("placeholder backfill", a.sp.BaseConfig().Placeholders().BackfillInterval.Duration, photoSvc.BackfillPlaceholders)
+
+	return nil
+}
+
+// initExifBackfill периодически читает координаты и время съемки оригиналов, из которых они еще не прочитаны.
+func (a *App) initExifBackfill(_ context.Context) error {
+	photoSvc := a.sp.PhotoService(a.db)
+	a.startBackgroundJob("EXIF backfill", a.sp.BaseConfig().Exif().BackfillInterval.Duration, photoSvc.BackfillExif)
+
+	return nil
+}
+
+// startBackgroundJob запускает job сразу и затем каждые interval до остановки приложения.
+// Ошибки только логируются: задача повторится на следующем запуске.
+// При остановке дожидается завершения текущего запуска.
+func (a *App) startBackgroundJob(name string, interval time.Duration, job func(context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := job(ctx); err != nil && ctx.Err() == nil {
+				log.Errorf("%s failed: %v", name, err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	a.closer.Add(name, func(closeCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-closeCtx.Done():
+			return closeCtx.Err()
+		}
+	})
+}
+
 func (a *App) initHTTPServer(_ context.Context) error {
 	if a.grpcClient == nil {
 		return fmt.Errorf("grpc client is not initialized")
 	}
 
+	httpCfg := a.sp.BaseConfig().HTTP()
+
 	router := gin.New()
+	// Контекст запроса (с span'ом трассировки) должен быть доступен через gin.Context
+	router.ContextWithFallback = true
 
 	router.Use(gin.Recovery())
+	router.Use(middleware.Tracing())
+	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger())
 
 	base := router.Group("/")
 
+	healthHandler := health.NewHandler(&a.draining, a.readinessChecks()...)
+	healthHandler.RegisterRoutes(base)
+
 	publicHandler := public.NewHandler(a.sp.PhotoService(a.db))
 	publicHandler.RegisterRoutes(base)
 
@@ -184,18 +405,91 @@ func (a *App) initHTTPServer(_ context.Context) error {
 	docsHandler := docs.NewHandler()
 	authHandler := auth.NewHandler(a.sp.UserService(a.grpcClient))
 	usersHandler := user.NewHandler(a.sp.UserService(a.grpcClient))
-	photosHandler := photos.NewHandler(a.sp.PhotoService(a.db), a.sp.TokenService(a.grpcClient))
+	uploadCfg := a.sp.BaseConfig().Upload()
+	photosHandler := photos.NewHandler(a.sp.PhotoService(a.db), a.sp.TokenService(a.grpcClient), photos.Options{
+		RequestTimeout: httpCfg.RequestTimeout.Duration,
+		MaxFileSize:    uploadCfg.MaxFileSize,
+		MaxRequestSize: uploadCfg.MaxRequestSize,
+		MaxBatchFiles:  uploadCfg.MaxBatchFiles,
+		RetryAfter:     uploadCfg.RetryAfter.Duration,
+		MaxBulkPhotos:  httpCfg.MaxBulkPhotos,
+	})
+	trashHandler := trash.NewHandler(a.sp.PhotoService(a.db), a.sp.TokenService(a.grpcClient), trash.Options{
+		RequestTimeout: httpCfg.RequestTimeout.Duration,
+	})
+	watermarkHandler := watermark.NewHandler(a.sp.PhotoService(a.db), a.sp.TokenService(a.grpcClient), watermark.Options{
+		RequestTimeout: httpCfg.RequestTimeout.Duration,
+		MaxFileSize:    uploadCfg.MaxFileSize,
+		MaxRequestSize: uploadCfg.MaxRequestSize,
+	})
+	publishingHandler := publishing.NewHandler(a.sp.PhotoService(a.db), a.sp.TokenService(a.grpcClient), publishing.Options{
+		RequestTimeout: httpCfg.RequestTimeout.Duration,
+	})
+	timelineHandler := timeline.NewHandler(a.sp.PhotoService(a.db), a.sp.TokenService(a.grpcClient), timeline.Options{
+		RequestTimeout: httpCfg.RequestTimeout.Duration,
+	})
+	albumsHandler := albums.NewHandler(a.sp.PhotoService(a.db), a.sp.TokenService(a.grpcClient), albums.Options{
+		RequestTimeout: httpCfg.RequestTimeout.Duration,
+	})
+	sharingHandler := sharing.NewHandler(a.sp.PhotoService(a.db), a.sp.TokenService(a.grpcClient), sharing.Options{
+		RequestTimeout: httpCfg.RequestTimeout.Duration,
+	})
 
 	docsHandler.RegisterRoutes(v1)
 	authHandler.RegisterRoutes(v1)
 	usersHandler.RegisterRoutes(v1)
 	photosHandler.RegisterRoutes(v1)
-
-	a.httpServer = router
+	trashHandler.RegisterRoutes(v1)
+	watermarkHandler.RegisterRoutes(v1)
+	publishingHandler.RegisterRoutes(v1)
+	timelineHandler.RegisterRoutes(v1)
+	albumsHandler.RegisterRoutes(v1)
+	sharingHandler.RegisterRoutes(v1)
+
+	a.httpServer = &http.Server{
+		Addr:              a.sp.BaseConfig().HTTPAddr(),
+		Handler:           router,
+		ReadHeaderTimeout: httpCfg.ReadHeaderTimeout.Duration,
+		ReadTimeout:       httpCfg.ReadTimeout.Duration,
+		WriteTimeout:      httpCfg.WriteTimeout.Duration,
+		IdleTimeout:       httpCfg.IdleTimeout.Duration,
+	}
 
 	return nil
 }
 
+// readinessChecks возвращает проверки зависимостей для /readyz.
+func (a *App) readinessChecks() []health.Check {
+	return []health.Check{
+		{
+			Name: "database",
+			Func: func(ctx context.Context) error {
+				return a.db.PingContext(ctx)
+			},
+		},
+		{
+			Name: "storage",
+			Func: func(_ context.Context) error {
+				return utils.CheckWritable(a.sp.BaseConfig().StorageFolder())
+			},
+		},
+		{
+			Name: "account_service",
+			Func: func(ctx context.Context) error {
+				_, err := a.grpcClient.HealthCheck(ctx, &emptypb.Empty{})
+				return err
+			},
+		},
+	}
+}
+
 func (a *App) runHTTPServer() error {
-	return a.httpServer.Run(a.sp.BaseConfig().HTTPAddr())
+	log.Infof("http server is listening on %s", a.httpServer.Addr)
+
+	err := a.httpServer.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"go-photo/internal/config"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+)
+
+const configUsage = `usage: config [flags] <command>
+
+commands:
+  print         print effective configuration (secrets are redacted)`
+
+// loadConfig загружает .env, собирает настройки из всех источников и валидирует их.
+func loadConfig(name string, args []string) (config.Config, error) {
+	loadDotEnv()
+
+	s, _, err := config.Parse(name, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config:\n%w", err)
+	}
+
+	cfg, err := config.NewConfig(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return cfg, nil
+}
+
+func loadDotEnv() {
+	if err := config.LoadDotEnv(".env"); err != nil {
+		log.Warnf("failed to load .env: %v", err)
+		log.Info("loading without .env")
+	}
+}
+
+// RunConfig выполняет подкоманду config. args не содержат само слово "config".
+func RunConfig(args []string) error {
+	return runConfig(args, os.Stdout)
+}
+
+func runConfig(args []string, out io.Writer) error {
+	loadDotEnv()
+
+	s, rest, err := config.Parse("config", args)
+	if err != nil {
+		return fmt.Errorf("failed to load config:\n%w", err)
+	}
+
+	if len(rest) == 0 {
+		return errors.New(configUsage)
+	}
+
+	switch rest[0] {
+	case "print":
+		enc := yaml.NewEncoder(out)
+		enc.SetIndent(2)
+		if err := enc.Encode(s.Redacted()); err != nil {
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+
+		// Печатаем конфигурацию даже если она невалидна, чтобы было видно, откуда взялось значение
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("invalid config:\n%w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown config command %q\n%s", rest[0], configUsage)
+	}
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go-photo/internal/config"
+	photoRepo "go-photo/internal/repository/photo"
+	fsckService "go-photo/internal/service/fsck"
+	fsckModel "go-photo/internal/service/fsck/model"
+	"go-photo/pkg/repository"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+const fsckUsage = `usage: fsck [flags] <command> [options]
+
+commands:
+  check         report problems without changing anything
+  repair        report problems and fix them
+
+options:
+  -format text|json   report format (default text)
+  -checksum           verify file checksums (reads every file)
+  -dry-run            repair: only show what would be done
+  -orphans keep|quarantine|delete
+                      repair: what to do with files without a version (default quarantine)
+  -rederive           repair: recreate missing or damaged derived versions from the original (default true)
+
+exit status is non-zero if unresolved problems remain`
+
+const (
+	fsckFormatText = "text"
+	fsckFormatJSON = "json"
+)
+
+// UnresolvedIssuesError возвращается fsck, если после проверки остались неисправленные проблемы.
+var UnresolvedIssuesError = errors.New("storage has unresolved issues")
+
+// RunFsck выполняет подкоманду fsck. args не содержат само слово "fsck".
+func RunFsck(ctx context.Context, args []string) error {
+	return runFsck(ctx, args, os.Stdout)
+}
+
+func runFsck(ctx context.Context, args []string, out io.Writer) error {
+	loadDotEnv()
+
+	s, rest, err := config.Parse("fsck", args)
+	if err != nil {
+		return fmt.Errorf("failed to load config:\n%w", err)
+	}
+
+	if len(rest) == 0 {
+		return errors.New(fsckUsage)
+	}
+
+	opts, format, err := parseFsckOptions(rest[0], rest[1:])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.NewConfig(s)
+	if err != nil {
+		return fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	db, err := repository.NewPostgresDB(cfg.PSQLConfig())
+	if err != nil {
+		return fmt.Errorf("failed to create postgres connection: %w", err)
+	}
+	defer db.Close()
+
+	checker := fsckService.NewService(fsckService.Deps{
+		StorageFolderPath: cfg.StorageFolder(),
+		Versions:          cfg.Versions(),
+		OrphanGracePeriod: cfg.Upload().StaleAfter.Duration,
+	}, photoRepo.NewRepository(db))
+
+	report, err := checker.Check(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFsckReport(out, report, format); err != nil {
+		return err
+	}
+
+	if n := report.Unresolved(); n > 0 {
+		return fmt.Errorf("%w: %d", UnresolvedIssuesError, n)
+	}
+
+	return nil
+}
+
+func parseFsckOptions(command string, args []string) (fsckModel.Options, string, error) {
+	var opts fsckModel.Options
+
+	fs := flag.NewFlagSet("fsck "+command, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", fsckFormatText, "")
+	fs.BoolVar(&opts.VerifyChecksums, "checksum", false, "")
+	orphans := string(fsckModel.OrphanKeep)
+
+	switch command {
+	case "check":
+	case "repair":
+		orphans = string(fsckModel.OrphanQuarantine)
+		fs.StringVar(&orphans, "orphans", orphans, "")
+		fs.BoolVar(&opts.DryRun, "dry-run", false, "")
+		fs.BoolVar(&opts.Rederive, "rederive", true, "")
+	default:
+		return opts, "", fmt.Errorf("unknown fsck command %q\n%s", command, fsckUsage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, "", fmt.Errorf("%w\n%s", err, fsckUsage)
+	}
+	if fs.NArg() > 0 {
+		return opts, "", fmt.Errorf("unexpected arguments %v\n%s", fs.Args(), fsckUsage)
+	}
+	if *format != fsckFormatText && *format != fsckFormatJSON {
+		return opts, "", fmt.Errorf("invalid -format %q, expected %q or %q", *format, fsckFormatText, fsckFormatJSON)
+	}
+
+	opts.Orphans = fsckModel.OrphanPolicy(orphans)
+	switch opts.Orphans {
+	case fsckModel.OrphanKeep, fsckModel.OrphanQuarantine, fsckModel.OrphanDelete:
+	default:
+		return opts, "", fmt.Errorf("invalid -orphans %q, expected keep, quarantine or delete", orphans)
+	}
+
+	return opts, *format, nil
+}
+
+func writeFsckReport(out io.Writer, report *fsckModel.Report, format string) error {
+	if format == fsckFormatJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Fprintf(out, "%-17s %s", issue.Kind, fsckIssueSubject(issue))
+		if issue.Expected != "" || issue.Actual != "" {
+			fmt.Fprintf(out, " expected=%s actual=%s", issue.Expected, issue.Actual)
+		}
+		if issue.Action != fsckModel.ActionNone {
+			state := "done"
+			switch {
+			case issue.Error != "":
+				state = "failed: " + issue.Error
+			case !issue.Fixed:
+				state = "planned"
+			}
+			fmt.Fprintf(out, " action=%s (%s)", issue.Action, state)
+		} else if issue.Error != "" {
+			fmt.Fprintf(out, " error=%s", issue.Error)
+		}
+		fmt.Fprintln(out)
+	}
+
+	kinds := make([]string, 0, len(report.Summary))
+	for kind := range report.Summary {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+
+	fmt.Fprintf(out, "checked %d versions and %d files in %s", report.CheckedVersions, report.CheckedFiles,
+		report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond))
+	if report.DryRun {
+		fmt.Fprint(out, " (dry run)")
+	}
+	fmt.Fprintln(out)
+	for _, kind := range kinds {
+		fmt.Fprintf(out, "  %s: %d\n", kind, report.Summary[fsckModel.IssueKind(kind)])
+	}
+	fmt.Fprintf(out, "unresolved: %d\n", report.Unresolved())
+
+	return nil
+}
+
+func fsckIssueSubject(issue fsckModel.Issue) string {
+	if issue.Path != "" {
+		return issue.Path
+	}
+	return fmt.Sprintf("photo=%d version=%d", issue.PhotoID, issue.VersionID)
+}
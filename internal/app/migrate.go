@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/config"
+	"go-photo/pkg/migrator"
+	"go-photo/pkg/repository"
+	"go-photo/schema"
+	"io"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `usage: migrate [flags] <command>
+
+commands:
+  up            apply all pending migrations
+  down [N]      roll back N migrations (default 1)
+  status        show applied and pending migrations
+  version       show current schema version`
+
+// RunMigrate выполняет подкоманду migrate. args не содержат само слово "migrate".
+func RunMigrate(ctx context.Context, args []string) error {
+	return runMigrate(ctx, args, os.Stdout)
+}
+
+func runMigrate(ctx context.Context, args []string, out io.Writer) error {
+	loadDotEnv()
+
+	s, args, err := config.Parse("migrate", args)
+	if err != nil {
+		return fmt.Errorf("failed to load config:\n%w", err)
+	}
+
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// Для миграций достаточно секции postgres, остальная конфигурация не проверяется
+	pgConfig, err := s.PSQLConfig()
+	if err != nil {
+		return fmt.Errorf("invalid postgres config:\n%w", err)
+	}
+
+	db, err := repository.NewPostgresDB(pgConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create postgres connection: %w", err)
+	}
+	defer db.Close()
+
+	m := migrator.New(db.DB, schema.FS)
+
+	switch args[0] {
+	case "up":
+		if err := m.Up(ctx); err != nil {
+			return err
+		}
+		return printVersion(ctx, m, out)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid steps %q: %w", args[1], err)
+			}
+		}
+		if err := m.Down(ctx, steps); err != nil {
+			return err
+		}
+		return printVersion(ctx, m, out)
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "version: %d (latest %d), dirty: %t\n", status.Version, status.Latest, status.Dirty)
+		for _, mg := range status.Migrations {
+			state := "pending"
+			if mg.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(out, "%06d %-30s %s\n", mg.Version, mg.Identifier, state)
+		}
+		return nil
+	case "version":
+		return printVersion(ctx, m, out)
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], migrateUsage)
+	}
+}
+
+func printVersion(ctx context.Context, m *migrator.Migrator, out io.Writer) error {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "version: %d, dirty: %t\n", version, dirty)
+
+	return nil
+}
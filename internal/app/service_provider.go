@@ -9,50 +9,29 @@ import (
 	photoService "go-photo/internal/service/photo"
 	userService "go-photo/internal/service/user"
 	desc "go-photo/pkg/account_v1"
-	pkgRepo "go-photo/pkg/repository"
-	"log"
+	"go-photo/pkg/executor"
 )
 
 type serviceProvider struct {
-	bc       config.Config
-	pgConfig *pkgRepo.PSQLConfig
+	bc config.Config
 
 	photoRepository repository.PhotoRepository
 
 	userSevice   service.UserService
 	photoService service.PhotoService
+
+	uploadFileExecutor *executor.Executor
+	uploadDBExecutor   *executor.Executor
 }
 
-func newServiceProvider() *serviceProvider {
-	return &serviceProvider{}
+func newServiceProvider(cfg config.Config) *serviceProvider {
+	return &serviceProvider{bc: cfg}
 }
 
 func (s *serviceProvider) BaseConfig() config.Config {
-	if s.bc == nil {
-		cfg, err := config.NewConfig()
-		if err != nil {
-			log.Fatalf("failed to get base config: %s", err.Error())
-		}
-
-		s.bc = cfg
-	}
-
 	return s.bc
 }
 
-func (s *serviceProvider) PSQLConfig() pkgRepo.PSQLConfig {
-	if s.pgConfig == nil {
-		cfg, err := config.NewPSQLConfig()
-		if err != nil {
-			log.Fatalf("failed to get psql config: %s", err.Error())
-		}
-
-		s.pgConfig = &cfg
-	}
-
-	return *s.pgConfig
-}
-
 func (s *serviceProvider) PhotoRepository(db *sqlx.DB) repository.PhotoRepository {
 	if s.photoRepository == nil {
 		s.photoRepository = photoRepository.NewRepository(db)
@@ -77,9 +56,41 @@ func (s *serviceProvider) PhotoService(db *sqlx.DB) service.PhotoService {
 	if s.photoService == nil {
 		deps := photoService.Deps{
 			StorageFolderPath: s.BaseConfig().StorageFolder(),
+			FileExecutor:      s.UploadFileExecutor(),
+			DBExecutor:        s.UploadDBExecutor(),
+			StaleUploadAfter:  s.BaseConfig().Upload().StaleAfter.Duration,
+			TrashRetention:    s.BaseConfig().Trash().Retention.Duration,
+			Resize:            s.BaseConfig().Resize(),
+			WebP:              s.BaseConfig().WebP(),
+			Versions:          s.BaseConfig().Versions(),
 		}
 		s.photoService = photoService.NewService(deps, s.PhotoRepository(db), nil)
 	}
 
 	return s.photoService
 }
+
+// UploadFileExecutor возвращает общий для процесса пул записи загружаемых файлов на диск.
+// Ограничение очереди применяется на этом этапе, поэтому пул сохранения в БД его не имеет.
+func (s *serviceProvider) UploadFileExecutor() *executor.Executor {
+	if s.uploadFileExecutor == nil {
+		cfg := s.BaseConfig().Upload()
+		s.uploadFileExecutor = executor.New(executor.Config{
+			Workers:          cfg.FileWorkers,
+			MaxPending:       cfg.MaxQueue,
+			MaxPendingPerKey: cfg.MaxQueuePerUser,
+		})
+	}
+
+	return s.uploadFileExecutor
+}
+
+func (s *serviceProvider) UploadDBExecutor() *executor.Executor {
+	if s.uploadDBExecutor == nil {
+		s.uploadDBExecutor = executor.New(executor.Config{
+			Workers: s.BaseConfig().Upload().DBWorkers,
+		})
+	}
+
+	return s.uploadDBExecutor
+}
@@ -1,64 +1,121 @@
 package config
 
 import (
-	"errors"
 	"github.com/joho/godotenv"
-	log "github.com/sirupsen/logrus"
+	"go-photo/pkg/repository"
 	"net"
-	"os"
-	"path/filepath"
+	"time"
 )
 
 const (
-	httpPortEnvName   = "HTTP_PORT"
-	logLevelEnvName   = "LOG_LEVEL"
-	grpcAddrEnvName   = "GRPC_ADDR"
-	storageFolderPath = "STORAGE_FOLDER"
+	httpPortEnvName            = "HTTP_PORT"
+	httpReadHeaderTimeoutEnv   = "HTTP_READ_HEADER_TIMEOUT"
+	httpReadTimeoutEnv         = "HTTP_READ_TIMEOUT"
+	httpWriteTimeoutEnv        = "HTTP_WRITE_TIMEOUT"
+	httpIdleTimeoutEnv         = "HTTP_IDLE_TIMEOUT"
+	httpRequestTimeoutEnv      = "HTTP_REQUEST_TIMEOUT"
+	httpMaxBulkPhotosEnv       = "HTTP_MAX_BULK_PHOTOS"
+	logLevelEnvName            = "LOG_LEVEL"
+	logFormatEnvName           = "LOG_FORMAT"
+	grpcAddrEnvName            = "GRPC_ADDR"
+	storageBackendEnv          = "STORAGE_BACKEND"
+	storageFolderPath          = "STORAGE_FOLDER"
+	uploadMaxFileSizeEnv       = "UPLOAD_MAX_FILE_SIZE"
+	uploadMaxRequestSizeEnv    = "UPLOAD_MAX_REQUEST_SIZE"
+	uploadMaxBatchFilesEnv     = "UPLOAD_MAX_BATCH_FILES"
+	uploadFileWorkersEnv       = "UPLOAD_FILE_WORKERS"
+	uploadDBWorkersEnv         = "UPLOAD_DB_WORKERS"
+	uploadMaxQueueEnv          = "UPLOAD_MAX_QUEUE"
+	uploadMaxQueuePerUserEnv   = "UPLOAD_MAX_QUEUE_PER_USER"
+	uploadRetryAfterEnv        = "UPLOAD_RETRY_AFTER"
+	uploadStaleAfterEnv        = "UPLOAD_STALE_AFTER"
+	uploadReconcileIntervalEnv = "UPLOAD_RECONCILE_INTERVAL"
+	trashRetentionEnv          = "TRASH_RETENTION"
+	trashPurgeIntervalEnv      = "TRASH_PURGE_INTERVAL"
+	placeholdersBackfillEnv    = "PLACEHOLDERS_BACKFILL_INTERVAL"
+	exifBackfillEnv            = "EXIF_BACKFILL_INTERVAL"
+	webpEnabledEnv             = "WEBP_ENABLED"
+	webpQualityEnv             = "WEBP_QUALITY"
+	postgresHostEnv            = "POSTGRES_HOST"
+	postgresPortEnv            = "POSTGRES_PORT"
+	postgresUserEnv            = "POSTGRES_USER"
+	postgresPasswordEnv        = "POSTGRES_PASSWORD"
+	postgresDBEnv              = "POSTGRES_DB"
+	postgresSSLModeEnv         = "POSTGRES_SSL_MODE"
+	postgresMaxOpenConnsEnv    = "POSTGRES_MAX_OPEN_CONNS"
+	postgresMaxIdleConnsEnv    = "POSTGRES_MAX_IDLE_CONNS"
+	postgresConnMaxLifetimeEnv = "POSTGRES_CONN_MAX_LIFETIME"
+	postgresConnMaxIdleTimeEnv = "POSTGRES_CONN_MAX_IDLE_TIME"
+	otlpEndpointEnv            = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	serviceNameEnv             = "OTEL_SERVICE_NAME"
+	shutdownTimeoutEnv         = "SHUTDOWN_TIMEOUT"
+	autoMigrateEnv             = "AUTO_MIGRATE"
 )
 
 type Config interface {
 	HTTPAddr() string
+	// HTTP возвращает таймауты HTTP сервера и обработки запросов.
+	HTTP() HTTPSettings
 	GRPCAddr() string
 
 	LogLevel() string
+	// LogFormat возвращает формат логов: text или json.
+	LogFormat() string
 
+	// StorageFolder возвращает абсолютный путь к папке с фотографиями.
 	StorageFolder() string
+	// Upload возвращает ограничения на загрузку и количество воркеров.
+	Upload() UploadSettings
+	// Trash возвращает настройки корзины.
+	Trash() TrashSettings
+	// Placeholders возвращает настройки расчета заглушек фото.
+	Placeholders() PlaceholderSettings
+	// Locations возвращает настройки чтения координат съемки.
+	Exif() ExifSettings
+	// Versions возвращает пресеты версий фото.
+	Versions() []VersionPreset
+	// Resize возвращает разрешенные параметры изменения размера публичных фото.
+	Resize() ResizeSettings
+	// WebP возвращает настройки выдачи фото в WebP.
+	WebP() WebPSettings
+
+	// PSQLConfig возвращает настройки подключения к Postgres, включая настройки пула.
+	PSQLConfig() repository.PSQLConfig
+
+	// OTLPEndpoint возвращает адрес OTLP коллектора для экспорта трейсов.
+	// Пустая строка означает, что экспорт отключен.
+	OTLPEndpoint() string
+	ServiceName() string
+
+	// ShutdownTimeout возвращает максимальное время на завершение обработки
+	// текущих запросов и освобождение ресурсов при остановке приложения.
+	ShutdownTimeout() time.Duration
+
+	// AutoMigrate определяет, применять ли миграции при старте приложения.
+	AutoMigrate() bool
 }
 
 type baseConfig struct {
-	httpPort          string
-	grpcAddr          string
-	logLevel          string
-	storageFolderPath string
+	s    Settings
+	psql repository.PSQLConfig
 }
 
-func NewConfig() (Config, error) {
-	port := os.Getenv(httpPortEnvName)
-	if len(port) == 0 {
-		return nil, errors.New("http port not found")
+// NewConfig валидирует настройки и возвращает Config поверх них.
+func NewConfig(s *Settings) (Config, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
 	}
 
-	logLever := os.Getenv(logLevelEnvName)
-
-	grpcAddr := os.Getenv(grpcAddrEnvName)
-	if len(grpcAddr) == 0 {
-		return nil, errors.New("grpc addr not found")
-	}
-
-	storageFolder := os.Getenv(storageFolderPath)
-	if len(storageFolder) == 0 {
-		storageFolder = DefaultStorageFolderPath
+	psql, err := s.PSQLConfig()
+	if err != nil {
+		return nil, err
 	}
 
-	return &baseConfig{
-		httpPort:          port,
-		grpcAddr:          grpcAddr,
-		logLevel:          logLever,
-		storageFolderPath: storageFolder,
-	}, nil
+	return &baseConfig{s: *s, psql: psql}, nil
 }
 
-func Load(path string) error {
+// LoadDotEnv загружает переменные окружения из .env файла.
+func LoadDotEnv(path string) error {
 	err := godotenv.Load(path)
 	if err != nil {
 		return err
@@ -68,23 +125,76 @@ func Load(path string) error {
 }
 
 func (c *baseConfig) HTTPAddr() string {
-	return net.JoinHostPort("0.0.0.0", c.httpPort)
+	return net.JoinHostPort("0.0.0.0", c.s.HTTP.Port)
+}
+
+func (c *baseConfig) HTTP() HTTPSettings {
+	return c.s.HTTP
 }
 
 func (c *baseConfig) GRPCAddr() string {
-	return c.grpcAddr
+	return c.s.GRPC.Addr
 }
 
 func (c *baseConfig) LogLevel() string {
-	return c.logLevel
+	return c.s.Log.Level
+}
+
+func (c *baseConfig) LogFormat() string {
+	return c.s.Log.Format
 }
 
 func (c *baseConfig) StorageFolder() string {
-	wd, err := os.Getwd()
-	if err != nil {
-		log.Panicf("failed to get working directory: %v", err)
+	return c.s.Storage.Folder
+}
+
+func (c *baseConfig) Upload() UploadSettings {
+	return c.s.Upload
+}
+
+func (c *baseConfig) Trash() TrashSettings {
+	return c.s.Trash
+}
+
+func (c *baseConfig) Placeholders() PlaceholderSettings {
+	return c.s.Placeholders
+}
+
+func (c *baseConfig) Exif() ExifSettings {
+	return c.s.Exif
+}
+
+func (c *baseConfig) Versions() []VersionPreset {
+	return append([]VersionPreset(nil), c.s.Versions...)
+}
+
+func (c *baseConfig) Resize() ResizeSettings {
+	return ResizeSettings{
+		Sizes:     append([]ImageSize(nil), c.s.Resize.Sizes...),
+		Qualities: append([]int(nil), c.s.Resize.Qualities...),
 	}
+}
+
+func (c *baseConfig) WebP() WebPSettings {
+	return c.s.WebP
+}
+
+func (c *baseConfig) PSQLConfig() repository.PSQLConfig {
+	return c.psql
+}
+
+func (c *baseConfig) OTLPEndpoint() string {
+	return c.s.Tracing.OTLPEndpoint
+}
+
+func (c *baseConfig) ServiceName() string {
+	return c.s.Tracing.ServiceName
+}
+
+func (c *baseConfig) ShutdownTimeout() time.Duration {
+	return c.s.ShutdownTimeout.Duration
+}
 
-	// TODO: решить как правильно хранить путь к папке
-	return filepath.Join(wd, c.storageFolderPath)
+func (c *baseConfig) AutoMigrate() bool {
+	return c.s.AutoMigrate
 }
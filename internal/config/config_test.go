@@ -0,0 +1,174 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	return path
+}
+
+func TestParse_Precedence(t *testing.T) {
+	yamlFile := writeFile(t, "config.yaml", `
+http:
+  port: "9000"
+  read_timeout: 15s
+grpc:
+  addr: file:50051
+log:
+  level: warn
+upload:
+  file_workers: 8
+versions:
+  - name: small
+    width: 100
+    height: 100
+    quality: 70
+`)
+	tomlFile := writeFile(t, "config.toml", `
+[http]
+port = "9000"
+read_timeout = "15s"
+
+[grpc]
+addr = "file:50051"
+
+[log]
+level = "warn"
+
+[upload]
+file_workers = 8
+
+[[versions]]
+name = "small"
+width = 100
+height = 100
+quality = 70
+`)
+
+	for _, path := range []string{yamlFile, tomlFile} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			t.Setenv(grpcAddrEnvName, "env:50051")
+			t.Setenv(logLevelEnvName, "error")
+			t.Setenv(postgresPasswordEnv, "secret")
+
+			s, rest, err := Parse("test", []string{"-config", path, "-log-level", "debug", "up"})
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"up"}, rest)
+			// файл
+			assert.Equal(t, "9000", s.HTTP.Port)
+			assert.Equal(t, 15*time.Second, s.HTTP.ReadTimeout.Duration)
+			assert.Equal(t, 8, s.Upload.FileWorkers)
+			assert.Equal(t, []VersionPreset{{Name: "small", Width: 100, Height: 100, Quality: 70}}, s.Versions)
+			// окружение поверх файла
+			assert.Equal(t, "env:50051", s.GRPC.Addr)
+			assert.Equal(t, "secret", s.Postgres.Password)
+			// флаг поверх окружения
+			assert.Equal(t, "debug", s.Log.Level)
+			// значения по умолчанию
+			assert.Equal(t, DefaultUploadDBWorkers, s.Upload.DBWorkers)
+			assert.Equal(t, DefaultShutdownTimeout, s.ShutdownTimeout.Duration)
+			assert.True(t, filepath.IsAbs(s.Storage.Folder))
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		env    map[string]string
+		errMsg []string
+	}{
+		{
+			name:   "Unknown field in file",
+			file:   writeFile(t, "config.yaml", "http:\n  prot: \"80\"\n"),
+			errMsg: []string{"field prot not found"},
+		},
+		{
+			name:   "Unsupported extension",
+			file:   writeFile(t, "config.json", "{}"),
+			errMsg: []string{"unsupported config file extension"},
+		},
+		{
+			name: "Invalid env values reported together",
+			env: map[string]string{
+				shutdownTimeoutEnv:   "soon",
+				uploadFileWorkersEnv: "many",
+			},
+			errMsg: []string{"invalid SHUTDOWN_TIMEOUT", "invalid UPLOAD_FILE_WORKERS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var args []string
+			if tt.file != "" {
+				args = []string{"-config", tt.file}
+			}
+
+			_, _, err := Parse("test", args)
+			require.Error(t, err)
+			for _, msg := range tt.errMsg {
+				assert.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+}
+
+func TestSettings_Validate(t *testing.T) {
+	valid := Default()
+	valid.GRPC.Addr = "localhost:50051"
+	valid.Postgres.User = "postgres"
+	valid.Postgres.Password = "postgres"
+	valid.Postgres.DBName = "go-photo"
+
+	require.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.HTTP.Port = "http"
+	invalid.HTTP.MaxBulkPhotos = 0
+	invalid.Log.Format = "xml"
+	invalid.Storage.Backend = "s3"
+	invalid.Upload.DBWorkers = 0
+	invalid.Versions = []VersionPreset{
+		{Name: "thumb", Width: 10, Height: 10, Quality: 80},
+		{Name: "thumb", Width: 10, Height: 10, Quality: 80},
+	}
+	invalid.Resize = ResizeSettings{Sizes: []ImageSize{{Width: 100, Height: 0}}}
+	invalid.WebP = WebPSettings{Enabled: true, Quality: 0}
+	invalid.Postgres.Password = ""
+
+	err := invalid.Validate()
+	require.Error(t, err)
+	for _, field := range []string{
+		"http.port", "http.max_bulk_photos", "log.format", "storage.backend", "upload.db_workers", "versions[1].name",
+		"resize.sizes[0].height", "resize.qualities", "webp.quality", "postgres.password",
+	} {
+		assert.Contains(t, err.Error(), field)
+	}
+}
+
+func TestSettings_Redacted(t *testing.T) {
+	s := Default()
+	s.Postgres.Password = "secret"
+
+	assert.Equal(t, redacted, s.Redacted().Postgres.Password)
+	assert.Equal(t, "secret", s.Postgres.Password)
+}
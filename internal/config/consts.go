@@ -7,6 +7,8 @@ const (
 	LogsDir                  = "logs"
 )
 
+const StorageBackendLocal = "local"
+
 const (
 	RSAPublicKeyDefaultTTL = time.Hour * 1
 )
@@ -15,8 +17,82 @@ const (
 	PostgresDefaultHost    = "localhost"
 	PostgresDefaultPort    = "5432"
 	PostgresDefaultSSLMode = "disable"
+
+	DefaultPostgresMaxOpenConns    = 20
+	DefaultPostgresMaxIdleConns    = 10
+	DefaultPostgresConnMaxLifetime = time.Minute * 30
+)
+
+const (
+	DefaultHTTPPort = "8080"
+	DefaultLogLevel = "info"
 )
 
+const DefaultServiceName = "go-photo"
+
 const DefaultUsersFoldername = "/home"
 
 const DefaultContextTimeout = time.Duration(time.Second * 5)
+
+const (
+	DefaultShutdownTimeout   = time.Second * 30
+	DefaultReadHeaderTimeout = time.Second * 10
+	DefaultIdleTimeout       = time.Second * 120
+)
+
+const (
+	DefaultMaxFileSize       = 32 << 20  // 32 MiB
+	DefaultMaxRequestSize    = 512 << 20 // 512 MiB
+	DefaultMaxBatchFiles     = 100
+	DefaultMaxBulkPhotos     = 100
+	DefaultUploadFileWorkers = 4
+	DefaultUploadDBWorkers   = 2
+
+	DefaultUploadMaxQueue        = 1000
+	DefaultUploadMaxQueuePerUser = 200
+	DefaultUploadRetryAfter      = time.Second * 5
+
+	DefaultUploadStaleAfter        = time.Minute * 15
+	DefaultUploadReconcileInterval = time.Minute * 5
+)
+
+const (
+	DefaultTrashRetention     = time.Hour * 24 * 30
+	DefaultTrashPurgeInterval = time.Hour
+)
+
+const DefaultPlaceholderBackfillInterval = time.Minute * 10
+
+const DefaultExifBackfillInterval = time.Minute * 10
+
+const (
+	DefaultPhotoListLimit = 50
+	MaxPhotoListLimit     = 200
+)
+
+// MaxSearchTerms максимальное количество слов в поисковом запросе
+const MaxSearchTerms = 10
+
+// Количество периодов на странице ленты
+const (
+	DefaultTimelineLimit = 100
+	MaxTimelineLimit     = 1000
+)
+
+// Кластеры фото на карте: ячейка сетки - 1/MapClusterCellsPerTile тайла по каждой стороне
+const (
+	MaxMapZoom             = 22
+	MapClusterCellsPerTile = 4
+)
+
+// Допуск поиска по цвету - расстояние CIE76 в пространстве Lab
+const (
+	DefaultColorTolerance = 10
+	MaxColorTolerance     = 50
+)
+
+const (
+	DefaultResizeQuality = 85
+	DefaultWebPQuality   = 80
+	MaxResizeDimension   = 8192
+)
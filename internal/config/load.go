@@ -0,0 +1,232 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const configFileEnv = "CONFIG_FILE"
+
+// Parse собирает конфигурацию из значений по умолчанию, файла, переменных окружения
+// и флагов args (в порядке возрастания приоритета). Путь к файлу задается флагом
+// -config или переменной CONFIG_FILE; формат определяется по расширению (.yaml, .yml, .toml).
+//
+// Возвращает аргументы, оставшиеся после флагов. Ошибки разбора всех источников
+// возвращаются вместе; валидация значений выполняется отдельно через Validate.
+func Parse(name string, args []string) (*Settings, []string, error) {
+	// Первый проход нужен только чтобы узнать путь к файлу:
+	// значения флагов должны применяться поверх файла и окружения.
+	var scratch Settings
+	var configPath string
+	fs := newFlagSet(name, &scratch, &configPath)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if configPath == "" {
+		configPath = os.Getenv(configFileEnv)
+	}
+
+	s := Default()
+	var errs []error
+
+	if configPath != "" {
+		if err := readFile(configPath, &s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, readEnv(&s)...)
+
+	fs = newFlagSet(name, &s, &configPath)
+	if err := fs.Parse(args); err != nil {
+		errs = append(errs, err)
+	}
+
+	if s.Storage.Folder != "" {
+		folder, err := filepath.Abs(s.Storage.Folder)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("storage.folder: %w", err))
+		}
+		s.Storage.Folder = folder
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	return &s, fs.Args(), nil
+}
+
+func newFlagSet(name string, s *Settings, configPath *string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	fs.StringVar(configPath, "config", *configPath, "path to config file (.yaml, .yml or .toml)")
+	fs.StringVar(&s.HTTP.Port, "http-port", s.HTTP.Port, "http server port")
+	fs.StringVar(&s.GRPC.Addr, "grpc-addr", s.GRPC.Addr, "account service address")
+	fs.StringVar(&s.Log.Level, "log-level", s.Log.Level, "log level")
+	fs.StringVar(&s.Log.Format, "log-format", s.Log.Format, "log format: text or json")
+	fs.StringVar(&s.Storage.Folder, "storage-folder", s.Storage.Folder, "photos storage folder")
+	fs.IntVar(&s.Upload.FileWorkers, "upload-file-workers", s.Upload.FileWorkers, "number of upload file workers")
+	fs.IntVar(&s.Upload.DBWorkers, "upload-db-workers", s.Upload.DBWorkers, "number of upload database workers")
+	fs.StringVar(&s.Postgres.Host, "postgres-host", s.Postgres.Host, "postgres host")
+	fs.StringVar(&s.Postgres.Port, "postgres-port", s.Postgres.Port, "postgres port")
+	fs.Var(&s.ShutdownTimeout, "shutdown-timeout", "graceful shutdown timeout")
+	fs.BoolVar(&s.AutoMigrate, "auto-migrate", s.AutoMigrate, "apply migrations on startup")
+
+	return fs
+}
+
+func readFile(path string, s *Settings) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(s)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(s)
+	default:
+		return fmt.Errorf("unsupported config file extension %q, expected .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readEnv переопределяет значения переменными окружения.
+// Непустая переменная с некорректным значением считается ошибкой.
+func readEnv(s *Settings) []error {
+	r := envReader{}
+
+	r.string(&s.HTTP.Port, httpPortEnvName)
+	r.duration(&s.HTTP.ReadHeaderTimeout, httpReadHeaderTimeoutEnv)
+	r.duration(&s.HTTP.ReadTimeout, httpReadTimeoutEnv)
+	r.duration(&s.HTTP.WriteTimeout, httpWriteTimeoutEnv)
+	r.duration(&s.HTTP.IdleTimeout, httpIdleTimeoutEnv)
+	r.duration(&s.HTTP.RequestTimeout, httpRequestTimeoutEnv)
+	r.int(&s.HTTP.MaxBulkPhotos, httpMaxBulkPhotosEnv)
+
+	r.string(&s.GRPC.Addr, grpcAddrEnvName)
+
+	r.string(&s.Log.Level, logLevelEnvName)
+	r.string(&s.Log.Format, logFormatEnvName)
+
+	r.string(&s.Tracing.OTLPEndpoint, otlpEndpointEnv)
+	r.string(&s.Tracing.ServiceName, serviceNameEnv)
+
+	r.string(&s.Storage.Backend, storageBackendEnv)
+	r.string(&s.Storage.Folder, storageFolderPath)
+
+	r.int64(&s.Upload.MaxFileSize, uploadMaxFileSizeEnv)
+	r.int64(&s.Upload.MaxRequestSize, uploadMaxRequestSizeEnv)
+	r.int(&s.Upload.MaxBatchFiles, uploadMaxBatchFilesEnv)
+	r.int(&s.Upload.FileWorkers, uploadFileWorkersEnv)
+	r.int(&s.Upload.DBWorkers, uploadDBWorkersEnv)
+	r.int(&s.Upload.MaxQueue, uploadMaxQueueEnv)
+	r.int(&s.Upload.MaxQueuePerUser, uploadMaxQueuePerUserEnv)
+	r.duration(&s.Upload.RetryAfter, uploadRetryAfterEnv)
+	r.duration(&s.Upload.StaleAfter, uploadStaleAfterEnv)
+	r.duration(&s.Upload.ReconcileInterval, uploadReconcileIntervalEnv)
+
+	r.duration(&s.Trash.Retention, trashRetentionEnv)
+	r.duration(&s.Trash.PurgeInterval, trashPurgeIntervalEnv)
+
+	r.duration(&s.Placeholders.BackfillInterval, placeholdersBackfillEnv)
+	r.duration(&s.Exif.BackfillInterval, exifBackfillEnv)
+
+	r.bool(&s.WebP.Enabled, webpEnabledEnv)
+	r.int(&s.WebP.Quality, webpQualityEnv)
+
+	r.string(&s.Postgres.Host, postgresHostEnv)
+	r.string(&s.Postgres.Port, postgresPortEnv)
+	r.string(&s.Postgres.User, postgresUserEnv)
+	r.string(&s.Postgres.Password, postgresPasswordEnv)
+	r.string(&s.Postgres.DBName, postgresDBEnv)
+	r.string(&s.Postgres.SSLMode, postgresSSLModeEnv)
+	r.int(&s.Postgres.MaxOpenConns, postgresMaxOpenConnsEnv)
+	r.int(&s.Postgres.MaxIdleConns, postgresMaxIdleConnsEnv)
+	r.duration(&s.Postgres.ConnMaxLifetime, postgresConnMaxLifetimeEnv)
+	r.duration(&s.Postgres.ConnMaxIdleTime, postgresConnMaxIdleTimeEnv)
+
+	r.duration(&s.ShutdownTimeout, shutdownTimeoutEnv)
+	r.bool(&s.AutoMigrate, autoMigrateEnv)
+
+	return r.errs
+}
+
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) lookup(name string) (string, bool) {
+	v := os.Getenv(name)
+	return v, len(v) != 0
+}
+
+func (r *envReader) string(dst *string, name string) {
+	if v, ok := r.lookup(name); ok {
+		*dst = v
+	}
+}
+
+func (r *envReader) int(dst *int, name string) {
+	if v, ok := r.lookup(name); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("invalid %s: %w", name, err))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (r *envReader) int64(dst *int64, name string) {
+	if v, ok := r.lookup(name); ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("invalid %s: %w", name, err))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (r *envReader) bool(dst *bool, name string) {
+	if v, ok := r.lookup(name); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("invalid %s: %w", name, err))
+			return
+		}
+		*dst = b
+	}
+}
+
+func (r *envReader) duration(dst *Duration, name string) {
+	if v, ok := r.lookup(name); ok {
+		if err := dst.Set(v); err != nil {
+			r.errs = append(r.errs, fmt.Errorf("invalid %s: %w", name, err))
+		}
+	}
+}
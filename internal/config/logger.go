@@ -5,8 +5,40 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"strings"
+	"time"
 )
 
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// NewLogFormatter возвращает форматтер логов по названию формата:
+//   - text: цветной человекочитаемый вывод (CustomFormatter)
+//   - json: одна JSON запись на строку со стабильными именами полей
+//     (ts, level, msg, request_id, trace_id, span_id, ...)
+func NewLogFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case "", LogFormatText:
+		return &CustomFormatter{
+			TimestampFormat: time.DateTime,
+		}, nil
+	case LogFormatJSON:
+		return &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime:  "ts",
+				logrus.FieldKeyLevel: "level",
+				logrus.FieldKeyMsg:   "msg",
+				logrus.FieldKeyFunc:  "caller",
+				logrus.FieldKeyFile:  "file",
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected %q or %q", format, LogFormatText, LogFormatJSON)
+	}
+}
+
 type CustomFormatter struct {
 	TimestampFormat string
 }
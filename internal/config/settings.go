@@ -0,0 +1,260 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Settings полная конфигурация приложения.
+// Значения собираются в порядке возрастания приоритета:
+// значения по умолчанию (Default) -> файл (YAML/TOML) -> переменные окружения -> флаги.
+type Settings struct {
+	HTTP         HTTPSettings        `yaml:"http" toml:"http"`
+	GRPC         GRPCSettings        `yaml:"grpc" toml:"grpc"`
+	Log          LogSettings         `yaml:"log" toml:"log"`
+	Tracing      TracingSettings     `yaml:"tracing" toml:"tracing"`
+	Storage      StorageSettings     `yaml:"storage" toml:"storage"`
+	Upload       UploadSettings      `yaml:"upload" toml:"upload"`
+	Trash        TrashSettings       `yaml:"trash" toml:"trash"`
+	Placeholders PlaceholderSettings `yaml:"placeholders" toml:"placeholders"`
+	Exif         ExifSettings        `yaml:"exif" toml:"exif"`
+	Versions     []VersionPreset     `yaml:"versions" toml:"versions"`
+	Resize       ResizeSettings      `yaml:"resize" toml:"resize"`
+	WebP         WebPSettings        `yaml:"webp" toml:"webp"`
+	Postgres     PostgresSettings    `yaml:"postgres" toml:"postgres"`
+
+	// ShutdownTimeout максимальное время на graceful shutdown.
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// AutoMigrate применять ли миграции при старте.
+	AutoMigrate bool `yaml:"auto_migrate" toml:"auto_migrate"`
+}
+
+type HTTPSettings struct {
+	Port              string   `yaml:"port" toml:"port"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	// ReadTimeout ограничивает чтение всего запроса вместе с телом (0 - без ограничения).
+	ReadTimeout  Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout  Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// RequestTimeout таймаут обработки запроса в хендлерах.
+	RequestTimeout Duration `yaml:"request_timeout" toml:"request_timeout"`
+	// MaxBulkPhotos максимальное количество фото в одном групповом действии.
+	MaxBulkPhotos int `yaml:"max_bulk_photos" toml:"max_bulk_photos"`
+}
+
+type GRPCSettings struct {
+	// Addr адрес account-service.
+	Addr string `yaml:"addr" toml:"addr"`
+}
+
+type LogSettings struct {
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
+}
+
+type TracingSettings struct {
+	// OTLPEndpoint адрес OTLP коллектора. Пустая строка отключает экспорт.
+	OTLPEndpoint string `yaml:"otlp_endpoint" toml:"otlp_endpoint"`
+	ServiceName  string `yaml:"service_name" toml:"service_name"`
+}
+
+type StorageSettings struct {
+	// Backend тип хранилища. Пока поддерживается только local.
+	Backend string `yaml:"backend" toml:"backend"`
+	// Folder папка с фотографиями. После загрузки конфигурации всегда абсолютный путь.
+	Folder string `yaml:"folder" toml:"folder"`
+}
+
+type UploadSettings struct {
+	// MaxFileSize максимальный размер одного файла в байтах.
+	MaxFileSize int64 `yaml:"max_file_size" toml:"max_file_size"`
+	// MaxRequestSize максимальный размер тела запроса на загрузку в байтах.
+	MaxRequestSize int64 `yaml:"max_request_size" toml:"max_request_size"`
+	// MaxBatchFiles максимальное количество файлов в пакетной загрузке.
+	MaxBatchFiles int `yaml:"max_batch_files" toml:"max_batch_files"`
+	// FileWorkers количество воркеров, записывающих файлы на диск.
+	FileWorkers int `yaml:"file_workers" toml:"file_workers"`
+	// DBWorkers количество воркеров, сохраняющих информацию о фото в БД.
+	DBWorkers int `yaml:"db_workers" toml:"db_workers"`
+	// MaxQueue максимальное количество файлов в очереди загрузки по всем пользователям. 0 - без ограничения.
+	MaxQueue int `yaml:"max_queue" toml:"max_queue"`
+	// MaxQueuePerUser максимальное количество файлов одного пользователя в очереди. 0 - без ограничения.
+	MaxQueuePerUser int `yaml:"max_queue_per_user" toml:"max_queue_per_user"`
+	// RetryAfter значение заголовка Retry-After при переполненной очереди.
+	RetryAfter Duration `yaml:"retry_after" toml:"retry_after"`
+	// StaleAfter возраст незавершенной загрузки, после которого она считается прерванной
+	// и доводится до конца или откатывается. Должен превышать http.request_timeout.
+	StaleAfter Duration `yaml:"stale_after" toml:"stale_after"`
+	// ReconcileInterval период проверки прерванных загрузок.
+	ReconcileInterval Duration `yaml:"reconcile_interval" toml:"reconcile_interval"`
+}
+
+type TrashSettings struct {
+	// Retention сколько фото хранится в корзине до окончательного удаления.
+	Retention Duration `yaml:"retention" toml:"retention"`
+	// PurgeInterval период очистки корзины.
+	PurgeInterval Duration `yaml:"purge_interval" toml:"purge_interval"`
+}
+
+type PlaceholderSettings struct {
+	// BackfillInterval период расчета заглушек версий, для которых они еще не рассчитаны.
+	BackfillInterval Duration `yaml:"backfill_interval" toml:"backfill_interval"`
+}
+
+type ExifSettings struct {
+	// BackfillInterval период чтения координат и времени съемки оригиналов, из которых они еще не прочитаны.
+	BackfillInterval Duration `yaml:"backfill_interval" toml:"backfill_interval"`
+}
+
+// ResizeSettings ограничивает изменение размера публичных фото на лету.
+// Разрешены только перечисленные размеры и качества, чтобы нельзя было заполнить кэш
+// произвольными вариантами. Пустой список размеров отключает изменение размера.
+type ResizeSettings struct {
+	Sizes []ImageSize `yaml:"sizes" toml:"sizes"`
+	// Qualities допустимые значения качества JPEG, первое используется по умолчанию.
+	Qualities []int `yaml:"qualities" toml:"qualities"`
+}
+
+// WebPSettings управляет выдачей фото в WebP клиентам, которые его принимают (Accept: image/webp).
+type WebPSettings struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Quality качество кодирования WebP от 1 до 100.
+	Quality int `yaml:"quality" toml:"quality"`
+}
+
+type ImageSize struct {
+	Width  int `yaml:"width" toml:"width"`
+	Height int `yaml:"height" toml:"height"`
+}
+
+// VersionPreset описывает версию фото, которую можно получить из оригинала
+// (например, миниатюру). Изображение вписывается в Width x Height с сохранением пропорций.
+type VersionPreset struct {
+	Name    string `yaml:"name" toml:"name"`
+	Width   int    `yaml:"width" toml:"width"`
+	Height  int    `yaml:"height" toml:"height"`
+	Quality int    `yaml:"quality" toml:"quality"`
+}
+
+type PostgresSettings struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     string `yaml:"port" toml:"port"`
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	DBName   string `yaml:"db" toml:"db"`
+	SSLMode  string `yaml:"ssl_mode" toml:"ssl_mode"`
+
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+}
+
+// Default возвращает конфигурацию со значениями по умолчанию.
+func Default() Settings {
+	return Settings{
+		HTTP: HTTPSettings{
+			Port:              DefaultHTTPPort,
+			ReadHeaderTimeout: Duration{DefaultReadHeaderTimeout},
+			IdleTimeout:       Duration{DefaultIdleTimeout},
+			RequestTimeout:    Duration{DefaultContextTimeout},
+			MaxBulkPhotos:     DefaultMaxBulkPhotos,
+		},
+		Log: LogSettings{
+			Level:  DefaultLogLevel,
+			Format: LogFormatText,
+		},
+		Tracing: TracingSettings{
+			ServiceName: DefaultServiceName,
+		},
+		Storage: StorageSettings{
+			Backend: StorageBackendLocal,
+			Folder:  DefaultStorageFolderPath,
+		},
+		Upload: UploadSettings{
+			MaxFileSize:       DefaultMaxFileSize,
+			MaxRequestSize:    DefaultMaxRequestSize,
+			MaxBatchFiles:     DefaultMaxBatchFiles,
+			FileWorkers:       DefaultUploadFileWorkers,
+			DBWorkers:         DefaultUploadDBWorkers,
+			MaxQueue:          DefaultUploadMaxQueue,
+			MaxQueuePerUser:   DefaultUploadMaxQueuePerUser,
+			RetryAfter:        Duration{DefaultUploadRetryAfter},
+			StaleAfter:        Duration{DefaultUploadStaleAfter},
+			ReconcileInterval: Duration{DefaultUploadReconcileInterval},
+		},
+		Trash: TrashSettings{
+			Retention:     Duration{DefaultTrashRetention},
+			PurgeInterval: Duration{DefaultTrashPurgeInterval},
+		},
+		Placeholders: PlaceholderSettings{
+			BackfillInterval: Duration{DefaultPlaceholderBackfillInterval},
+		},
+		Exif: ExifSettings{
+			BackfillInterval: Duration{DefaultExifBackfillInterval},
+		},
+		Versions: []VersionPreset{
+			{Name: "thumbnail", Width: 320, Height: 320, Quality: 80},
+			{Name: "preview", Width: 1280, Height: 1280, Quality: 85},
+		},
+		Resize: ResizeSettings{
+			Sizes: []ImageSize{
+				{Width: 160, Height: 160},
+				{Width: 320, Height: 320},
+				{Width: 640, Height: 640},
+				{Width: 1280, Height: 1280},
+				{Width: 2048, Height: 2048},
+			},
+			Qualities: []int{DefaultResizeQuality, 60},
+		},
+		WebP: WebPSettings{
+			Enabled: true,
+			Quality: DefaultWebPQuality,
+		},
+		Postgres: PostgresSettings{
+			Host:            PostgresDefaultHost,
+			Port:            PostgresDefaultPort,
+			SSLMode:         PostgresDefaultSSLMode,
+			MaxOpenConns:    DefaultPostgresMaxOpenConns,
+			MaxIdleConns:    DefaultPostgresMaxIdleConns,
+			ConnMaxLifetime: Duration{DefaultPostgresConnMaxLifetime},
+		},
+		ShutdownTimeout: Duration{DefaultShutdownTimeout},
+	}
+}
+
+// Redacted возвращает копию конфигурации со скрытыми секретами.
+func (s Settings) Redacted() Settings {
+	if s.Postgres.Password != "" {
+		s.Postgres.Password = redacted
+	}
+
+	return s
+}
+
+const redacted = "<redacted>"
+
+// Duration time.Duration, который читается и записывается строкой вида "30s"
+// в YAML, TOML, переменных окружения и флагах.
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	return d.Set(string(text))
+}
+
+// Set реализует flag.Value.
+func (d *Duration) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+	d.Duration = v
+
+	return nil
+}
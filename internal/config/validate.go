@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+	"time"
+)
+
+// Validate проверяет конфигурацию целиком и возвращает все найденные ошибки разом.
+func (s *Settings) Validate() error {
+	v := validator{}
+
+	v.check(isPort(s.HTTP.Port), "http.port", "must be a port number, got %q", s.HTTP.Port)
+	v.check(s.HTTP.ReadHeaderTimeout.Duration > 0, "http.read_header_timeout", "must be positive")
+	v.check(s.HTTP.ReadTimeout.Duration >= 0, "http.read_timeout", "must not be negative")
+	v.check(s.HTTP.WriteTimeout.Duration >= 0, "http.write_timeout", "must not be negative")
+	v.check(s.HTTP.IdleTimeout.Duration >= 0, "http.idle_timeout", "must not be negative")
+	v.check(s.HTTP.RequestTimeout.Duration > 0, "http.request_timeout", "must be positive")
+	v.check(s.HTTP.MaxBulkPhotos > 0, "http.max_bulk_photos", "must be positive")
+
+	v.check(s.GRPC.Addr != "", "grpc.addr", "must be set")
+
+	_, err := log.ParseLevel(s.Log.Level)
+	v.check(err == nil, "log.level", "unknown level %q", s.Log.Level)
+	_, err = NewLogFormatter(s.Log.Format)
+	v.check(err == nil, "log.format", "must be %q or %q, got %q", LogFormatText, LogFormatJSON, s.Log.Format)
+
+	v.check(s.Tracing.ServiceName != "", "tracing.service_name", "must be set")
+
+	v.check(s.Storage.Backend == StorageBackendLocal, "storage.backend", "unsupported backend %q, expected %q", s.Storage.Backend, StorageBackendLocal)
+	v.check(s.Storage.Folder != "", "storage.folder", "must be set")
+
+	v.check(s.Upload.MaxFileSize > 0, "upload.max_file_size", "must be positive")
+	v.check(s.Upload.MaxRequestSize >= s.Upload.MaxFileSize, "upload.max_request_size", "must not be less than upload.max_file_size")
+	v.check(s.Upload.MaxBatchFiles > 0, "upload.max_batch_files", "must be positive")
+	v.check(s.Upload.FileWorkers > 0, "upload.file_workers", "must be positive")
+	v.check(s.Upload.DBWorkers > 0, "upload.db_workers", "must be positive")
+	v.check(s.Upload.MaxQueue >= 0, "upload.max_queue", "must not be negative")
+	v.check(s.Upload.MaxQueuePerUser >= 0, "upload.max_queue_per_user", "must not be negative")
+	// Иначе пакет максимального размера никогда не поместится в очередь
+	v.check(s.Upload.MaxQueue == 0 || s.Upload.MaxBatchFiles <= s.Upload.MaxQueue,
+		"upload.max_queue", "must not be less than upload.max_batch_files")
+	v.check(s.Upload.MaxQueuePerUser == 0 || s.Upload.MaxBatchFiles <= s.Upload.MaxQueuePerUser,
+		"upload.max_queue_per_user", "must not be less than upload.max_batch_files")
+	v.check(s.Upload.RetryAfter.Duration >= time.Second, "upload.retry_after", "must be at least 1s")
+	// Иначе reconciler может откатить загрузку, которая еще выполняется
+	v.check(s.Upload.StaleAfter.Duration > s.HTTP.RequestTimeout.Duration,
+		"upload.stale_after", "must be greater than http.request_timeout")
+	v.check(s.Upload.ReconcileInterval.Duration > 0, "upload.reconcile_interval", "must be positive")
+
+	v.check(s.Trash.Retention.Duration > 0, "trash.retention", "must be positive")
+	v.check(s.Trash.PurgeInterval.Duration > 0, "trash.purge_interval", "must be positive")
+
+	v.check(s.Placeholders.BackfillInterval.Duration > 0, "placeholders.backfill_interval", "must be positive")
+	v.check(s.Exif.BackfillInterval.Duration > 0, "exif.backfill_interval", "must be positive")
+
+	names := make(map[string]struct{}, len(s.Versions))
+	for i, p := range s.Versions {
+		field := fmt.Sprintf("versions[%d]", i)
+		v.check(p.Name != "", field+".name", "must be set")
+		_, dup := names[p.Name]
+		v.check(!dup, field+".name", "duplicate preset %q", p.Name)
+		names[p.Name] = struct{}{}
+		v.check(p.Width > 0, field+".width", "must be positive")
+		v.check(p.Height > 0, field+".height", "must be positive")
+		v.check(p.Quality >= 1 && p.Quality <= 100, field+".quality", "must be between 1 and 100")
+	}
+
+	v.add(s.Resize.validate())
+	v.check(!s.WebP.Enabled || s.WebP.Quality >= 1 && s.WebP.Quality <= 100, "webp.quality", "must be between 1 and 100")
+
+	v.add(s.Postgres.validate())
+
+	v.check(s.ShutdownTimeout.Duration > 0, "shutdown_timeout", "must be positive")
+
+	return v.err()
+}
+
+func (r *ResizeSettings) validate() error {
+	v := validator{}
+
+	for i, size := range r.Sizes {
+		field := fmt.Sprintf("resize.sizes[%d]", i)
+		v.check(size.Width > 0 && size.Width <= MaxResizeDimension, field+".width", "must be between 1 and %d", MaxResizeDimension)
+		v.check(size.Height > 0 && size.Height <= MaxResizeDimension, field+".height", "must be between 1 and %d", MaxResizeDimension)
+	}
+	v.check(len(r.Sizes) == 0 || len(r.Qualities) > 0, "resize.qualities", "must be set when resize.sizes is set")
+	for i, q := range r.Qualities {
+		v.check(q >= 1 && q <= 100, fmt.Sprintf("resize.qualities[%d]", i), "must be between 1 and 100")
+	}
+
+	return v.err()
+}
+
+func (p *PostgresSettings) validate() error {
+	v := validator{}
+
+	v.check(p.Host != "", "postgres.host", "must be set")
+	v.check(isPort(p.Port), "postgres.port", "must be a port number, got %q", p.Port)
+	v.check(p.User != "", "postgres.user", "must be set")
+	v.check(p.Password != "", "postgres.password", "must be set")
+	v.check(p.DBName != "", "postgres.db", "must be set")
+	v.check(p.MaxOpenConns >= 0, "postgres.max_open_conns", "must not be negative")
+	v.check(p.MaxIdleConns >= 0, "postgres.max_idle_conns", "must not be negative")
+	v.check(p.MaxOpenConns == 0 || p.MaxIdleConns <= p.MaxOpenConns, "postgres.max_idle_conns", "must not exceed postgres.max_open_conns")
+	v.check(p.ConnMaxLifetime.Duration >= 0, "postgres.conn_max_lifetime", "must not be negative")
+	v.check(p.ConnMaxIdleTime.Duration >= 0, "postgres.conn_max_idle_time", "must not be negative")
+
+	return v.err()
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, field, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: "+format, append([]any{field}, args...)...))
+	}
+}
+
+func (v *validator) add(err error) {
+	if err != nil {
+		v.errs = append(v.errs, err)
+	}
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
+
+func isPort(s string) bool {
+	port, err := strconv.Atoi(s)
+	return err == nil && port > 0 && port <= 65535
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// MaxBodySize ограничивает размер тела запроса. При превышении лимита чтение тела
+// (например, c.MultipartForm) вернет ошибку, которую можно проверить через IsBodyTooLarge.
+// limit <= 0 отключает ограничение.
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+
+		c.Next()
+	}
+}
+
+// IsBodyTooLarge сообщает, вызвана ли ошибка превышением лимита MaxBodySize.
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
+	"go-photo/pkg/logger"
 	"strings"
 	"time"
 )
@@ -26,7 +27,7 @@ func Logger() gin.HandlerFunc {
 			"user_agent": userAgent,
 		}
 
-		log.WithFields(fields).Info("Request started")
+		logger.FromContext(c.Request.Context()).WithFields(fields).Info("Request started")
 		c.Next()
 
 		latency := time.Since(start)
@@ -43,7 +44,7 @@ func Logger() gin.HandlerFunc {
 			"user_agent":   userAgent,
 		}
 
-		entry := log.WithFields(fields)
+		entry := logger.FromContext(c.Request.Context()).WithFields(fields)
 		if errorMessage != "" {
 			if statusCode >= 500 {
 				entry.Error(errorMessage)
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go-photo/pkg/logger"
+	"regexp"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	RequestIDCtx    = "request_id"
+)
+
+// validRequestID ограничивает входящий X-Request-ID, чтобы клиент не мог
+// записать в логи произвольные данные.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID присваивает каждому запросу идентификатор.
+// Если клиент прислал корректный заголовок X-Request-ID, используется он, иначе генерируется UUID.
+// Идентификатор возвращается в заголовке ответа и кладется в контекст запроса
+// вместе с логгером (см. logger.FromContext).
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(RequestIDCtx, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		entry := log.WithField(RequestIDCtx, requestID)
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), entry))
+
+		c.Next()
+	}
+}
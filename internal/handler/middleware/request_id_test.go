@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/pkg/logger"
+)
+
+func TestMiddleware_RequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		incomingID       string
+		expectGenerated  bool
+		expectedResponse string
+	}{
+		{
+			name:             "Incoming request id",
+			incomingID:       "req-123",
+			expectedResponse: "req-123",
+		},
+		{
+			name:            "No request id",
+			incomingID:      "",
+			expectGenerated: true,
+		},
+		{
+			name:            "Invalid request id",
+			incomingID:      "bad id\nwith newline",
+			expectGenerated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			log.SetFormatter(&log.JSONFormatter{})
+			t.Cleanup(func() {
+				log.SetOutput(gin.DefaultWriter)
+				log.SetFormatter(&log.TextFormatter{})
+			})
+
+			var ctxRequestID string
+			router := gin.New()
+			router.Use(RequestID())
+			router.GET("/", func(c *gin.Context) {
+				ctxRequestID = c.GetString(RequestIDCtx)
+				logger.FromContext(c.Request.Context()).Info("from handler")
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incomingID != "" {
+				req.Header.Set(RequestIDHeader, tt.incomingID)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			responseID := rec.Header().Get(RequestIDHeader)
+			if tt.expectGenerated {
+				_, err := uuid.Parse(responseID)
+				assert.NoError(t, err, "должен быть сгенерирован UUID")
+			} else {
+				assert.Equal(t, tt.expectedResponse, responseID)
+			}
+			assert.Equal(t, responseID, ctxRequestID)
+
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &entry))
+			assert.Equal(t, responseID, entry[RequestIDCtx])
+		})
+	}
+}
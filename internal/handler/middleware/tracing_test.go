@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracing.SetGlobal(tp)
+	t.Cleanup(func() { _ = tp.Shutdown(t.Context()) })
+
+	return exporter
+}
+
+func TestMiddleware_Tracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		handlerStatus    int
+		traceparent      string
+		expectedParentID string
+		expectedTraceID  string
+		expectedStatus   codes.Code
+	}{
+		{
+			name:           "New trace",
+			handlerStatus:  http.StatusOK,
+			expectedStatus: codes.Unset,
+		},
+		{
+			name:             "Propagated trace",
+			handlerStatus:    http.StatusOK,
+			traceparent:      "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expectedParentID: "00f067aa0ba902b7",
+			expectedTraceID:  "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedStatus:   codes.Unset,
+		},
+		{
+			name:           "Server error",
+			handlerStatus:  http.StatusInternalServerError,
+			expectedStatus: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := setupTracing(t)
+
+			var handlerSpanCtx trace.SpanContext
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.Use(Tracing())
+			router.GET("/photos/:id", func(c *gin.Context) {
+				handlerSpanCtx = trace.SpanContextFromContext(c)
+				c.Status(tt.handlerStatus)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/photos/1", nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+
+			span := spans[0]
+			assert.Equal(t, "GET /photos/:id", span.Name)
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+			assert.Equal(t, tt.expectedStatus, span.Status.Code)
+			assert.Equal(t, span.SpanContext.SpanID(), handlerSpanCtx.SpanID(), "span должен быть доступен через gin.Context")
+
+			if tt.expectedParentID != "" {
+				assert.Equal(t, tt.expectedParentID, span.Parent.SpanID().String())
+				assert.Equal(t, tt.expectedTraceID, span.SpanContext.TraceID().String())
+			} else {
+				assert.False(t, span.Parent.IsValid())
+			}
+		})
+	}
+}
+
+func TestMiddleware_LoggerTraceFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	exporter := setupTracing(t)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFormatter(&log.JSONFormatter{})
+	t.Cleanup(func() {
+		log.SetOutput(gin.DefaultWriter)
+		log.SetFormatter(&log.TextFormatter{})
+	})
+
+	router := gin.New()
+	router.Use(Tracing(), Logger())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	for _, line := range lines {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &entry))
+		assert.Equal(t, spans[0].SpanContext.TraceID().String(), entry["trace_id"])
+		assert.Equal(t, spans[0].SpanContext.SpanID().String(), entry["span_id"])
+	}
+}
@@ -43,7 +43,7 @@ func userIdentity(c *gin.Context, verify VerifyTokenFunc) {
 		return
 	}
 
-	resp, err := verify(context.Background(), token)
+	resp, err := verify(c.Request.Context(), token)
 	if err != nil {
 		response.NewErr(c, http.StatusUnauthorized, response.AuthTokenInvalid, err, "Token is invalid or user cannot be found.")
 		return
@@ -0,0 +1,28 @@
+package request
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AcceptsWebP сообщает, перечислен ли image/webp в заголовке Accept с ненулевым весом.
+// Шаблоны image/* и */* не учитываются: их отправляют и клиенты, которые не умеют декодировать WebP.
+func AcceptsWebP(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), "image/webp") {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				return err == nil && q > 0
+			}
+		}
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package request
+
+import "encoding/json"
+
+type BulkPhotos struct {
+	// Action одно из: publish, unpublish, delete, add_to_album, tag
+	Action   string `json:"action" binding:"required"`
+	PhotoIDs []int  `json:"photo_ids" binding:"required,min=1"`
+	// AlbumID альбом для действия add_to_album
+	AlbumID int `json:"album_id"`
+	// Tag тег для действия tag
+	Tag string `json:"tag"`
+}
+
+type EditPhoto struct {
+	// Operations операции в порядке применения к оригиналу
+	Operations []EditOperation `json:"operations" binding:"required,min=1"`
+}
+
+// EditOperation операция правки. Type одно из: rotate (angle, кратен 90), flip (direction: horizontal, vertical),
+// crop (x, y, width, height), brightness и contrast (value от -100 до 100), grayscale.
+type EditOperation struct {
+	Type      string `json:"type" binding:"required"`
+	Angle     int    `json:"angle,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	X         int    `json:"x,omitempty"`
+	Y         int    `json:"y,omitempty"`
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+	Value     int    `json:"value,omitempty"`
+}
+
+// WatermarkSettings настройки водяного знака. Type одно из: text (text обязателен), logo (логотип загружается отдельно).
+// Position одно из: top-left, top-right, bottom-left, bottom-right (по умолчанию), center.
+// Opacity (непрозрачность) и Scale (ширина знака относительно ширины фото) в процентах, от 1 до 100.
+type WatermarkSettings struct {
+	Type     string `json:"type" binding:"required"`
+	Text     string `json:"text,omitempty"`
+	Position string `json:"position,omitempty"`
+	Opacity  int    `json:"opacity,omitempty"`
+	Scale    int    `json:"scale,omitempty"`
+}
+
+// PublishSettings настройки публикации. MetadataPolicy одно из: keep (файлы отдаются как есть),
+// strip_private (без геолокации, сведений о владельце и серийных номеров), strip_all (без всех метаданных).
+type PublishSettings struct {
+	MetadataPolicy string `json:"metadata_policy" binding:"required"`
+}
+
+// SaveAlbum название, фильтры и режим альбома. Manual - ручной альбом из добавленных фото, фото владельца
+// попадают в него только по непустому фильтру. Filter - объект с полями, как у параметров списка фото:
+// favorite, hidden (true, false или any; по умолчанию скрытые фото не попадают в альбом), min_rating, max_rating,
+// taken_from и taken_to (YYYY-MM-DD), tz (часовой пояс IANA для фото без времени съемки в EXIF), camera, published, tag,
+// color, tolerance и bbox (west, south, east, north).
+type SaveAlbum struct {
+	Title  string          `json:"title" binding:"required"`
+	Filter json.RawMessage `json:"filter,omitempty" swaggertype:"object"`
+	Manual bool            `json:"manual"`
+}
+
+// Share роль доступа: viewer (просмотр) или contributor (еще и добавление своих фото, только для альбомов).
+type Share struct {
+	Role string `json:"role" binding:"required"`
+}
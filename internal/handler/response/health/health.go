@@ -0,0 +1,12 @@
+package health
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+type Status struct {
+	Status string `json:"status"`
+	// Checks содержит результат каждой проверки: "ok" или текст ошибки.
+	Checks map[string]string `json:"checks,omitempty"`
+}
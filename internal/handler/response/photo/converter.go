@@ -14,6 +14,14 @@ func ToPhotoVersionFromModel(photoVersion model.PhotoVersion) PhotoVersion {
 		Height:       photoVersion.Height,
 		Width:        photoVersion.Width,
 		SavedAt:      photoVersion.SavedAt.Format(time.DateTime),
+		Placeholder:  ToPlaceholderFromModel(photoVersion.Placeholder),
+	}
+}
+
+func ToPlaceholderFromModel(placeholder model.Placeholder) Placeholder {
+	return Placeholder{
+		BlurHash: placeholder.BlurHash,
+		Palette:  placeholder.Palette,
 	}
 }
 
@@ -24,3 +32,111 @@ func ToPhotoVersionsFromModel(photoVersions []model.PhotoVersion) []PhotoVersion
 	}
 	return photoVersionsResponse
 }
+
+func ToPhotoRevisionFromModel(version model.PhotoVersion) PhotoRevision {
+	return PhotoRevision{
+		Revision:     version.Revision,
+		Current:      version.VersionType == model.Original,
+		UUIDFilename: version.UUIDFilename,
+		Size:         version.Size,
+		Height:       version.Height,
+		Width:        version.Width,
+		SavedAt:      version.SavedAt.Format(time.DateTime),
+	}
+}
+
+func ToPhotoRevisionsFromModel(versions []model.PhotoVersion) []PhotoRevision {
+	revisions := make([]PhotoRevision, len(versions))
+	for i, v := range versions {
+		revisions[i] = ToPhotoRevisionFromModel(v)
+	}
+	return revisions
+}
+
+func ToPhotoFromModel(photo model.Photo) Photo {
+	return Photo{
+		PhotoID:     photo.ID,
+		Filename:    photo.Filename,
+		Title:       photo.Title,
+		Caption:     photo.Caption,
+		Favorite:    photo.Favorite,
+		Rating:      photo.Rating,
+		Hidden:      photo.Hidden,
+		UploadedAt:  photo.UploadedAt.Format(time.DateTime),
+		UpdatedAt:   photo.UpdatedAt.Format(time.DateTime),
+		Placeholder: ToPlaceholderFromModel(photo.Placeholder),
+		Location:    ToLocationFromModel(photo.Location),
+		TakenAt:     toTakenAtFromModel(photo.TakenAt),
+	}
+}
+
+func toTakenAtFromModel(takenAt time.Time) string {
+	if takenAt.IsZero() {
+		return ""
+	}
+	return takenAt.Format(time.DateTime)
+}
+
+func ToTimelineBucketsFromModel(granularity model.TimelineGranularity, buckets []model.TimelineBucket) []TimelineBucket {
+	res := make([]TimelineBucket, len(buckets))
+	for i, b := range buckets {
+		res[i] = TimelineBucket{
+			Date:         b.Start.Format(granularity.Layout()),
+			Count:        b.Count,
+			CoverPhotoID: b.CoverPhotoID,
+		}
+	}
+	return res
+}
+
+func ToLocationFromModel(location *model.Location) *Location {
+	if location == nil {
+		return nil
+	}
+	return &Location{Latitude: location.Latitude, Longitude: location.Longitude}
+}
+
+func ToPhotoClustersFromModel(clusters []model.PhotoCluster) []PhotoCluster {
+	res := make([]PhotoCluster, len(clusters))
+	for i, c := range clusters {
+		res[i] = PhotoCluster{
+			Location: Location{Latitude: c.Location.Latitude, Longitude: c.Location.Longitude},
+			Count:    c.Count,
+			PhotoID:  c.PhotoID,
+		}
+	}
+	return res
+}
+
+func ToPhotosFromModel(photos []model.Photo) []Photo {
+	photosResponse := make([]Photo, len(photos))
+	for i, p := range photos {
+		photosResponse[i] = ToPhotoFromModel(p)
+	}
+	return photosResponse
+}
+
+func ToFoundPhotosFromModel(photos []model.FoundPhoto) []FoundPhoto {
+	photosResponse := make([]FoundPhoto, len(photos))
+	for i, p := range photos {
+		photosResponse[i] = FoundPhoto{
+			Photo:     ToPhotoFromModel(p.Photo),
+			Rank:      p.Rank,
+			Highlight: p.Highlight,
+		}
+	}
+	return photosResponse
+}
+
+func ToPublicPhotosFromModel(photos []model.Photo) []PublicPhoto {
+	photosResponse := make([]PublicPhoto, len(photos))
+	for i, p := range photos {
+		photosResponse[i] = PublicPhoto{
+			PhotoID:     p.ID,
+			Title:       p.Title,
+			Caption:     p.Caption,
+			Placeholder: ToPlaceholderFromModel(p.Placeholder),
+		}
+	}
+	return photosResponse
+}
@@ -1,5 +1,7 @@
 package photo
 
+import "encoding/json"
+
 type UploadPhotoResponse struct {
 	PhotoID int `json:"photo_id"`
 }
@@ -16,6 +18,40 @@ type UploadInfo struct {
 	Error    error  `json:"error,omitempty"`
 }
 
+type GetPhotosResponse struct {
+	Photos []Photo `json:"photos"`
+}
+
+type Photo struct {
+	PhotoID    int    `json:"photo_id"`
+	Filename   string `json:"filename"`
+	Title      string `json:"title"`
+	Caption    string `json:"caption"`
+	Favorite   bool   `json:"favorite"`
+	Rating     int    `json:"rating"`
+	Hidden     bool   `json:"hidden"`
+	UploadedAt string `json:"uploaded_at"`
+	UpdatedAt  string `json:"updated_at"`
+	Placeholder
+	// Location координаты съемки, не отдаются, если их нет
+	Location *Location `json:"location,omitempty"`
+	// TakenAt местное время съемки из EXIF, не отдается, если его нет
+	TakenAt string `json:"taken_at,omitempty"`
+}
+
+type SearchPhotosResponse struct {
+	Photos []FoundPhoto `json:"photos"`
+}
+
+// FoundPhoto фото, найденное поиском, с релевантностью и выделенными совпадениями.
+type FoundPhoto struct {
+	Photo
+	Rank float64 `json:"rank"`
+	// Highlight фрагменты названия, подписи и имени файла, совпадения выделены тегом <b>.
+	// Текст фото не экранируется
+	Highlight string `json:"highlight"`
+}
+
 type GetPhotoVersionsResponse struct {
 	Versions []PhotoVersion `json:"versions"`
 }
@@ -28,8 +64,187 @@ type PhotoVersion struct {
 	Height       int    `json:"height"`
 	Width        int    `json:"width"`
 	SavedAt      string `json:"saved_at"`
+	Placeholder
+}
+
+// Placeholder заглушка, которую клиент показывает, пока загружается файл.
+// Не отдается, если еще не рассчитана.
+type Placeholder struct {
+	BlurHash string   `json:"blurhash,omitempty"`
+	Palette  []string `json:"palette,omitempty"`
+}
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type GetPhotoClustersResponse struct {
+	Clusters []PhotoCluster `json:"clusters"`
+}
+
+// PhotoCluster фото, попавшие в одну ячейку сетки на карте.
+type PhotoCluster struct {
+	// Location среднее положение фото ячейки
+	Location Location `json:"location"`
+	Count    int      `json:"count"`
+	// PhotoID фото, которое представляет ячейку на карте
+	PhotoID int `json:"photo_id"`
+}
+
+type GetTimelineResponse struct {
+	Granularity string           `json:"granularity"`
+	Buckets     []TimelineBucket `json:"buckets"`
+	// NextCursor передается в cursor для получения следующей страницы, не отдается на последней странице
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// TimelineBucket фото, снятые за один период ленты.
+type TimelineBucket struct {
+	// Date ключ периода: 2006, 2006-01 или 2006-01-02
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+	// CoverPhotoID фото, которое представляет период
+	CoverPhotoID int `json:"cover_photo_id"`
 }
 
 type PublishPhotoResponse struct {
 	PublicToken string `json:"public_token"`
 }
+
+type GetTrashResponse struct {
+	Photos []TrashedPhoto `json:"photos"`
+}
+
+type TrashedPhoto struct {
+	PhotoID    int    `json:"photo_id"`
+	Filename   string `json:"filename"`
+	UploadedAt string `json:"uploaded_at"`
+	DeletedAt  string `json:"deleted_at"`
+	PurgeAt    string `json:"purge_at"`
+}
+
+type BulkPhotosResponse struct {
+	TotalCount   int          `json:"total_count"`
+	SuccessCount int          `json:"success_count"`
+	Results      []BulkResult `json:"results"`
+}
+
+type BulkResult struct {
+	PhotoID     int    `json:"photo_id"`
+	PublicToken string `json:"public_token,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type PhotoEditResponse struct {
+	PhotoID    int             `json:"photo_id"`
+	Operations []EditOperation `json:"operations"`
+	Version    PhotoVersion    `json:"version"`
+	UpdatedAt  string          `json:"updated_at"`
+}
+
+type EditOperation struct {
+	Type      string `json:"type"`
+	Angle     int    `json:"angle,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	X         int    `json:"x,omitempty"`
+	Y         int    `json:"y,omitempty"`
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+	Value     int    `json:"value,omitempty"`
+}
+
+// PhotoRevision ревизия оригинала фото. Текущая ревизия отдается как оригинал.
+type PhotoRevision struct {
+	Revision     int    `json:"revision"`
+	Current      bool   `json:"current"`
+	UUIDFilename string `json:"uuid_filename"`
+	Size         int64  `json:"size"`
+	Height       int    `json:"height"`
+	Width        int    `json:"width"`
+	SavedAt      string `json:"saved_at"`
+}
+
+type GetPhotoRevisionsResponse struct {
+	Revisions []PhotoRevision `json:"revisions"`
+}
+
+// Watermark настройки водяного знака пользователя.
+type Watermark struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Position string `json:"position"`
+	Opacity  int    `json:"opacity"`
+	Scale    int    `json:"scale"`
+	// HasLogo загружен ли логотип, он сохраняется и при текстовом знаке
+	HasLogo   bool   `json:"has_logo"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type PublishSettings struct {
+	MetadataPolicy string `json:"metadata_policy"`
+	// UpdatedAt не заполнено, пока пользователь не менял настройки по умолчанию
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+type GetAlbumsResponse struct {
+	Albums []Album `json:"albums"`
+}
+
+// Album альбом, фото которого отбираются сохраненными фильтрами. Manual - ручной альбом из добавленных фото.
+type Album struct {
+	AlbumID int    `json:"album_id"`
+	Title   string `json:"title"`
+	// Filter фильтры альбома, поля как у параметров списка фото
+	Filter json.RawMessage `json:"filter" swaggertype:"object"`
+	Manual bool            `json:"manual"`
+	// PublicToken, MetadataPolicy и Watermark не отдаются, пока альбом не опубликован
+	PublicToken    string `json:"public_token,omitempty"`
+	MetadataPolicy string `json:"metadata_policy,omitempty"`
+	Watermark      bool   `json:"watermark,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+type PublishAlbumResponse struct {
+	PublicToken string `json:"public_token"`
+}
+
+// PublicAlbum опубликованный альбом. Фото отдаются без имен файлов, координат и атрибутов владельца.
+type PublicAlbum struct {
+	Title  string        `json:"title"`
+	Photos []PublicPhoto `json:"photos"`
+}
+
+type PublicPhoto struct {
+	PhotoID int    `json:"photo_id"`
+	Title   string `json:"title"`
+	Caption string `json:"caption"`
+	Placeholder
+}
+
+// Share доступ к фото или альбому, выданный другому пользователю.
+type Share struct {
+	UserUUID string `json:"user_uuid"`
+	// Role viewer или contributor
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GetSharesResponse struct {
+	Shares []Share `json:"shares"`
+}
+
+// SharedAlbum альбом другого пользователя, к которому выдан доступ. Публикация альбома не отдается.
+type SharedAlbum struct {
+	Album
+	OwnerUUID string `json:"owner_uuid"`
+	Role      string `json:"role"`
+}
+
+// SharedWithMeResponse фото и альбомы других пользователей, к которым выдан доступ.
+type SharedWithMeResponse struct {
+	// Photos страница фото, доступ к которым выдан напрямую
+	Photos []Photo       `json:"photos"`
+	Albums []SharedAlbum `json:"albums"`
+}
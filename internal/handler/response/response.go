@@ -21,6 +21,7 @@ const (
 	InvalidReqestsQueryParams ErrMessage = "invalid_request_query_params"
 	ParamsMissing             ErrMessage = "params_missing"
 	UnsupportedFileType       ErrMessage = "unsupported_file_type"
+	RequestTooLarge           ErrMessage = "request_too_large"
 	InvalidCredentials        ErrMessage = "invalid_credentials"
 	UserAlreadyExists         ErrMessage = "user_already_exists"
 	AuthHeaderEmpty           ErrMessage = "auth_header_empty"
@@ -28,8 +29,19 @@ const (
 	AuthTokenInvalid          ErrMessage = "auth_token_invalid"
 	Unauthorized              ErrMessage = "unauthorized"
 	Forbidden                 ErrMessage = "access_denied"
+	ServiceBusy               ErrMessage = "service_busy"
+	PreconditionFailed        ErrMessage = "precondition_failed"
+	UnsupportedContentType    ErrMessage = "unsupported_content_type"
 
-	PhotoNotFound ErrMessage = "photo_not_found"
+	PhotoNotFound         ErrMessage = "photo_not_found"
+	PhotoAlreadyPublished ErrMessage = "photo_already_published"
+
+	WatermarkNotFound ErrMessage = "watermark_not_found"
+	WatermarkInUse    ErrMessage = "watermark_in_use"
+
+	AlbumNotFound ErrMessage = "album_not_found"
+
+	ShareNotFound ErrMessage = "share_not_found"
 )
 
 type Message struct {
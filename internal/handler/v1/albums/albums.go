@@ -0,0 +1,446 @@
+package albums
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/request"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	metadataQueryParam  = "metadata"
+	watermarkQueryParam = "watermark"
+)
+
+// @Summary Create album
+// @Description Create an album. The album shows the user's photos matching its filter at the moment of reading
+// @Description and photos added to it. Filter fields are the same as photo list filters. A manual album shows only
+// @Description added photos unless its filter sets at least one criterion
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Security JWTAuth
+// @Param input body request.SaveAlbum true "Album title and filter"
+// @Success 200 {object} photo.Album
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums [post]
+func (h *handler) createAlbum(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	var input request.SaveAlbum
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid request body format.")
+		return
+	}
+
+	album, err := h.photoService.CreateAlbum(ctx, userUUID, serviceModel.SaveAlbumParams{
+		Title:  input.Title,
+		Filter: input.Filter,
+		Manual: input.Manual,
+	})
+	if handleAlbumError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toAlbumResponse(album))
+}
+
+// @Summary Get albums
+// @Description Get user's albums, most recently created first
+// @Tags albums
+// @Produce json
+// @Security JWTAuth
+// @Success 200 {object} photo.GetAlbumsResponse
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums [get]
+func (h *handler) getAlbums(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	albums, err := h.photoService.GetAlbums(ctx, userUUID)
+	if handleAlbumError(c, err) {
+		return
+	}
+
+	res := photoResp.GetAlbumsResponse{Albums: make([]photoResp.Album, len(albums))}
+	for i := range albums {
+		res.Albums[i] = toAlbumResponse(&albums[i])
+	}
+
+	response.NewOk(c, res)
+}
+
+// @Summary Get album
+// @Description Get album with its filter and publication. Albums shared by other users are returned without publication
+// @Tags albums
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Success 200 {object} photo.Album
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id} [get]
+func (h *handler) getAlbum(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	album, err := h.photoService.GetAlbum(ctx, userUUID, albumID)
+	if handleAlbumError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toAlbumResponse(album))
+}
+
+// @Summary Update album
+// @Description Replace album title, filter and mode. A published album keeps its public link
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Param input body request.SaveAlbum true "Album title and filter"
+// @Success 200 {object} photo.Album
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id} [put]
+func (h *handler) updateAlbum(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	var input request.SaveAlbum
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid request body format.")
+		return
+	}
+
+	album, err := h.photoService.UpdateAlbum(ctx, userUUID, albumID, serviceModel.SaveAlbumParams{
+		Title:  input.Title,
+		Filter: input.Filter,
+		Manual: input.Manual,
+	})
+	if handleAlbumError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toAlbumResponse(album))
+}
+
+// @Summary Delete album
+// @Description Delete album and its public link. Photos are not deleted
+// @Tags albums
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Success 200 {object} nil
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id} [delete]
+func (h *handler) deleteAlbum(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	err := h.photoService.DeleteAlbum(ctx, userUUID, albumID)
+	if handleAlbumError(c, err) {
+		return
+	}
+
+	response.NewOk(c, nil)
+}
+
+// @Summary Get album photos
+// @Description Get a page of photos matching the album filter, most recently uploaded first.
+// @Description Photos added to the album by contributors are included too.
+// @Description Hidden photos are shown only to the album owner
+// @Tags albums
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Param limit query int false "Page size, 50 by default"
+// @Param offset query int false "Number of photos to skip"
+// @Success 200 {object} photo.GetPhotosResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id}/photos [get]
+func (h *handler) getAlbumPhotos(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	limit, offset, err := queryPage(c)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+
+	photos, err := h.photoService.GetAlbumPhotos(ctx, userUUID, albumID, limit, offset)
+	if handleAlbumError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.GetPhotosResponse{
+		Photos: photoResp.ToPhotosFromModel(photos),
+	})
+}
+
+// @Summary Remove album photo
+// @Description Remove a photo added to the album by a contributor. The photo itself stays with its uploader.
+// @Description Photos matching the album filter can not be removed this way. Only the album owner can remove photos
+// @Tags albums
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Param photoID path int true "Photo ID"
+// @Success 200 {object} nil
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found or photo was not added to it."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id}/photos/{photoID} [delete]
+func (h *handler) removeAlbumPhoto(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	photoID, err := strconv.Atoi(c.Param("photoID"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	err = h.photoService.RemoveAlbumPhoto(ctx, userUUID, albumID, photoID)
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo was not added to the album.")
+		return
+	}
+	if handleAlbumError(c, err) {
+		return
+	}
+
+	response.NewOk(c, nil)
+}
+
+// @Summary Publish album
+// @Description Make an album public. Anyone with the link sees the album title and photos currently matching its filter,
+// @Description except hidden ones. Publishing again keeps the link and changes the metadata policy and watermark.
+// @Description By default the policy from the user's publish settings is used
+// @Tags albums
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Param watermark query bool false "Serve public files with the album owner's watermark" default(false)
+// @Param metadata query string false "Metadata policy" Enums(keep, strip_private, strip_all)
+// @Success 200 {object} photo.PublishAlbumResponse
+// @Failure 400 {object} response.Error "Bad Request or watermark is not configured."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id}/publish [post]
+func (h *handler) publishAlbum(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	var opts serviceModel.PublishOptions
+	if value, ok := c.GetQuery(watermarkQueryParam); ok {
+		watermark, err := strconv.ParseBool(value)
+		if err != nil {
+			response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err,
+				fmt.Sprintf("Invalid %s, expected true or false.", watermarkQueryParam))
+			return
+		}
+		opts.Watermark = watermark
+	}
+	if value, ok := c.GetQuery(metadataQueryParam); ok {
+		policy, err := serviceModel.ParseMetadataPolicy(value)
+		if err != nil {
+			response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err,
+				fmt.Sprintf("Invalid %s, expected keep, strip_private or strip_all.", metadataQueryParam))
+			return
+		}
+		opts.Metadata = policy
+	}
+
+	publicToken, err := h.photoService.PublishAlbum(ctx, userUUID, albumID, opts)
+	if handleAlbumError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.PublishAlbumResponse{
+		PublicToken: publicToken,
+	})
+}
+
+// @Summary Unpublish album
+// @Description Remove album publication, the public link stops working
+// @Tags albums
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Success 200 {object} nil
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found or not published."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id}/publish [delete]
+func (h *handler) unpublishAlbum(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	err := h.photoService.UnpublishAlbum(ctx, userUUID, albumID)
+	if handleAlbumError(c, err) {
+		return
+	}
+
+	response.NewOk(c, nil)
+}
+
+// albumRequest возвращает пользователя и ID альбома из пути. Если их нет, отвечает клиенту ошибкой.
+func albumRequest(c *gin.Context) (string, int, bool) {
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return "", 0, false
+	}
+
+	albumID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid album id.")
+		return "", 0, false
+	}
+
+	return userUUID, albumID, true
+}
+
+// queryPage возвращает параметры limit и offset, 0 - не указан.
+func queryPage(c *gin.Context) (int, int, error) {
+	var page [2]int
+	for i, key := range []string{"limit", "offset"} {
+		v, ok := c.GetQuery(key)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid %s, expected integer.", key)
+		}
+		page[i] = n
+	}
+
+	return page[0], page[1], nil
+}
+
+// handleAlbumError отвечает клиенту ошибкой сервиса альбомов. Возвращает false, если ошибки нет.
+func handleAlbumError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, serviceErr.InvalidAlbumError):
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, err.Error())
+	case errors.Is(err, serviceErr.InvalidFilterError):
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid filter.")
+	case errors.Is(err, serviceErr.InvalidMetadataPolicyError):
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid metadata policy.")
+	case errors.Is(err, serviceErr.AlbumNotFoundError):
+		response.NewErr(c, http.StatusNotFound, response.AlbumNotFound, err, "Album not found.")
+	case errors.Is(err, serviceErr.WatermarkNotFoundError):
+		response.NewErr(c, http.StatusBadRequest, response.WatermarkNotFound, err, "Watermark is not configured.")
+	default:
+		return response.HandleError(c, err)
+	}
+	return true
+}
+
+func toAlbumResponse(a *serviceModel.Album) photoResp.Album {
+	// Ошибки быть не может: фильтры состоят из простых значений
+	filter, _ := json.Marshal(a.Filter)
+
+	return photoResp.Album{
+		AlbumID:        a.ID,
+		Title:          a.Title,
+		Filter:         filter,
+		Manual:         a.Manual,
+		PublicToken:    a.PublicToken,
+		MetadataPolicy: string(a.MetadataPolicy),
+		Watermark:      a.Watermark,
+		CreatedAt:      a.CreatedAt.Format(time.DateTime),
+		UpdatedAt:      a.UpdatedAt.Format(time.DateTime),
+	}
+}
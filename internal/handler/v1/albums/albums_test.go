@@ -0,0 +1,366 @@
+package albums
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	serviceUserModel "go-photo/internal/service/user/model"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAlbum = &serviceModel.Album{
+	ID:        1,
+	Title:     "Best",
+	Filter:    serviceModel.AlbumFilter{Camera: "fuji"},
+	CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+const testAlbumResponse = `{"album_id":1,"title":"Best","filter":{"camera":"fuji"},"manual":false,` +
+	`"created_at":"2024-01-01 00:00:00","updated_at":"2024-01-01 00:00:00"}`
+
+func TestHandler_createAlbum(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Valid",
+			body: `{"title": "Best", "filter": {"camera": "fuji"}}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().CreateAlbum(gomock.Any(), userUUID, serviceModel.SaveAlbumParams{
+					Title: "Best", Filter: []byte(`{"camera": "fuji"}`),
+				}).Return(testAlbum, nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: testAlbumResponse,
+		},
+		{
+			name: "Manual album",
+			body: `{"title": "Trip", "manual": true}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().CreateAlbum(gomock.Any(), userUUID, serviceModel.SaveAlbumParams{Title: "Trip", Manual: true}).
+					Return(&serviceModel.Album{ID: 2, Title: "Trip", Manual: true, CreatedAt: testAlbum.CreatedAt, UpdatedAt: testAlbum.UpdatedAt}, nil).
+					Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"album_id":2,"title":"Trip","filter":{},"manual":true,` +
+				`"created_at":"2024-01-01 00:00:00","updated_at":"2024-01-01 00:00:00"}`,
+		},
+		{
+			name: "Invalid filter",
+			body: `{"title": "Tagged", "filter": {"tags": ["cats"]}}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().CreateAlbum(gomock.Any(), userUUID, gomock.Any()).
+					Return(nil, serviceErr.InvalidAlbumError).Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"invalid album"}`,
+		},
+		{
+			name:                 "No title",
+			body:                 `{"filter": {}}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Invalid request body format."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.POST("/albums", h.createAlbum)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/albums", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getAlbumPhotos(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		albumID              string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:    "Valid",
+			albumID: "1",
+			query:   "?limit=10&offset=20",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetAlbumPhotos(gomock.Any(), userUUID, 1, 10, 20).
+					Return([]model.Photo{{ID: 5, Filename: "a.jpg"}}, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"photos":[{"photo_id":5,"filename":"a.jpg","title":"","caption":"","favorite":false,"rating":0,` +
+				`"hidden":false,"uploaded_at":"0001-01-01 00:00:00","updated_at":"0001-01-01 00:00:00"}]}`,
+		},
+		{
+			name:    "Not found",
+			albumID: "1",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetAlbumPhotos(gomock.Any(), userUUID, 1, 0, 0).
+					Return(nil, serviceErr.AlbumNotFoundError).Times(1)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"error":"album_not_found","message":"Album not found."}`,
+		},
+		{
+			name:    "Album of other user",
+			albumID: "1",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetAlbumPhotos(gomock.Any(), userUUID, 1, 0, 0).
+					Return(nil, serviceErr.AccessDeniedError).Times(1)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"error":"access_denied","message":"access denied"}`,
+		},
+		{
+			name:                 "Invalid id",
+			albumID:              "one",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Invalid album id."}`,
+		},
+		{
+			name:                 "Invalid limit",
+			albumID:              "1",
+			query:                "?limit=ten",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid limit, expected integer."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.GET("/albums/:id/photos", h.getAlbumPhotos)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/albums/"+tt.albumID+"/photos"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_removeAlbumPhoto(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		photoID              string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:    "Valid",
+			photoID: "5",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().RemoveAlbumPhoto(gomock.Any(), userUUID, 1, 5).Return(nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `null`,
+		},
+		{
+			name:    "Photo not added",
+			photoID: "5",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().RemoveAlbumPhoto(gomock.Any(), userUUID, 1, 5).Return(serviceErr.PhotoNotFoundError).Times(1)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"error":"photo_not_found","message":"Photo was not added to the album."}`,
+		},
+		{
+			name:    "Not album owner",
+			photoID: "5",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().RemoveAlbumPhoto(gomock.Any(), userUUID, 1, 5).Return(serviceErr.AccessDeniedError).Times(1)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"error":"access_denied","message":"access denied"}`,
+		},
+		{
+			name:                 "Invalid photo id",
+			photoID:              "five",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Invalid photo id."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.DELETE("/albums/:id/photos/:photoID", h.removeAlbumPhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/albums/1/photos/"+tt.photoID, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_publishAlbum(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Valid",
+			query: "?metadata=strip_all",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().PublishAlbum(gomock.Any(), userUUID, 1, serviceModel.PublishOptions{Metadata: serviceModel.MetadataStripAll}).
+					Return("token", nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"public_token":"token"}`,
+		},
+		{
+			name: "Default policy",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().PublishAlbum(gomock.Any(), userUUID, 1, serviceModel.PublishOptions{}).Return("token", nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"public_token":"token"}`,
+		},
+		{
+			name:  "Watermark",
+			query: "?watermark=true",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().PublishAlbum(gomock.Any(), userUUID, 1, serviceModel.PublishOptions{Watermark: true}).Return("token", nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"public_token":"token"}`,
+		},
+		{
+			name:  "Watermark not configured",
+			query: "?watermark=true",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().PublishAlbum(gomock.Any(), userUUID, 1, gomock.Any()).Return("", serviceErr.WatermarkNotFoundError).Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"watermark_not_found","message":"Watermark is not configured."}`,
+		},
+		{
+			name:                 "Invalid watermark",
+			query:                "?watermark=maybe",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid watermark, expected true or false."}`,
+		},
+		{
+			name:                 "Invalid policy",
+			query:                "?metadata=strip_some",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid metadata, expected keep, strip_private or strip_all."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.POST("/albums/:id/publish", h.publishAlbum)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/albums/1/publish"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func newRouter(userUUID string) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
+		if token == "valid-token" {
+			return serviceUserModel.TokenPayload{UserUUID: userUUID}, nil
+		}
+		return serviceUserModel.TokenPayload{}, errors.New("invalid token")
+	}))
+	return r
+}
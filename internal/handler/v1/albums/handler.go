@@ -0,0 +1,55 @@
+package albums
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-photo/internal/config"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/service"
+	"time"
+)
+
+// maxAlbumSize ограничение тела запроса на сохранение альбома
+const maxAlbumSize = 4 << 10
+
+type Options struct {
+	RequestTimeout time.Duration
+}
+
+type handler struct {
+	photoService service.PhotoService
+	tokenService service.TokenService
+	opts         Options
+}
+
+func NewHandler(photoService service.PhotoService, tokenService service.TokenService, opts Options) *handler {
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = config.DefaultContextTimeout
+	}
+
+	return &handler{
+		photoService: photoService,
+		tokenService: tokenService,
+		opts:         opts,
+	}
+}
+
+func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
+	albumsGroup := router.Group("/albums")
+
+	albumsGroup.Use(middleware.UserIdentity(h.tokenService.VerifyToken))
+
+	{
+		albumsGroup.POST("", middleware.MaxBodySize(maxAlbumSize), h.createAlbum)
+		albumsGroup.GET("", h.getAlbums)
+		albumsGroup.GET("/:id", h.getAlbum)
+		albumsGroup.PUT("/:id", middleware.MaxBodySize(maxAlbumSize), h.updateAlbum)
+		albumsGroup.DELETE("/:id", h.deleteAlbum)
+		albumsGroup.GET("/:id/photos", h.getAlbumPhotos)
+		albumsGroup.DELETE("/:id/photos/:photoID", h.removeAlbumPhoto)
+		albumsGroup.POST("/:id/publish", h.publishAlbum)
+		albumsGroup.DELETE("/:id/publish", h.unpublishAlbum)
+		albumsGroup.GET("/:id/shares", h.getAlbumShares)
+		albumsGroup.PUT("/:id/shares/:userUUID", middleware.MaxBodySize(maxAlbumSize), h.shareAlbum)
+		albumsGroup.DELETE("/:id/shares/:userUUID", h.unshareAlbum)
+	}
+}
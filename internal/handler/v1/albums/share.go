@@ -0,0 +1,145 @@
+package albums
+
+import (
+	"context"
+	"errors"
+	"go-photo/internal/handler/request"
+	"go-photo/internal/handler/response"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get album shares
+// @Description Get users the album is shared with, in the order access was granted
+// @Tags albums
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Success 200 {object} photo.GetSharesResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id}/shares [get]
+func (h *handler) getAlbumShares(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	shares, err := h.photoService.GetAlbumShares(ctx, userUUID, albumID)
+	if handleShareError(c, err) {
+		return
+	}
+
+	res := photoResp.GetSharesResponse{Shares: make([]photoResp.Share, len(shares))}
+	for i := range shares {
+		res.Shares[i] = toShareResponse(&shares[i])
+	}
+
+	response.NewOk(c, res)
+}
+
+// @Summary Share album
+// @Description Let another user view the album and photos matching its filter, except hidden ones,
+// @Description or change the role of an existing share. Role is viewer or contributor
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Param userUUID path string true "UUID of the user to share with"
+// @Param input body request.Share true "Share role"
+// @Success 200 {object} photo.Share
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id}/shares/{userUUID} [put]
+func (h *handler) shareAlbum(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	var input request.Share
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid request body format.")
+		return
+	}
+
+	share, err := h.photoService.ShareAlbum(ctx, userUUID, albumID, c.Param("userUUID"), input.Role)
+	if handleShareError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toShareResponse(share))
+}
+
+// @Summary Unshare album
+// @Description Revoke another user's access to the album
+// @Tags albums
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Album ID"
+// @Param userUUID path string true "UUID of the user to revoke access from"
+// @Success 200 {object} nil
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found or not shared with the user."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/albums/{id}/shares/{userUUID} [delete]
+func (h *handler) unshareAlbum(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, albumID, ok := albumRequest(c)
+	if !ok {
+		return
+	}
+
+	err := h.photoService.UnshareAlbum(ctx, userUUID, albumID, c.Param("userUUID"))
+	if handleShareError(c, err) {
+		return
+	}
+
+	response.NewOk(c, nil)
+}
+
+// handleShareError отвечает клиенту ошибкой сервиса доступа к альбому. Возвращает false, если ошибки нет.
+func handleShareError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, serviceErr.InvalidShareError):
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, err.Error())
+	case errors.Is(err, serviceErr.ShareNotFoundError):
+		response.NewErr(c, http.StatusNotFound, response.ShareNotFound, err, "Album is not shared with the user.")
+	default:
+		return handleAlbumError(c, err)
+	}
+	return true
+}
+
+func toShareResponse(s *serviceModel.Share) photoResp.Share {
+	return photoResp.Share{
+		UserUUID:  s.UserUUID,
+		Role:      string(s.Role),
+		CreatedAt: s.CreatedAt.Format(time.DateTime),
+	}
+}
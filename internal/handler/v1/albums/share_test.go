@@ -0,0 +1,78 @@
+package albums
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_getAlbumShares(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Shares",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetAlbumShares(gomock.Any(), userUUID, 1).Return([]serviceModel.Share{{
+					UserUUID:  "6f1d2c3e-0000-4000-8000-000000000002",
+					Role:      serviceModel.ShareContributor,
+					CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+				}}, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"shares":[{"user_uuid":"6f1d2c3e-0000-4000-8000-000000000002","role":"contributor",` +
+				`"created_at":"2024-05-01 12:00:00"}]}`,
+		},
+		{
+			name: "Album of another user",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetAlbumShares(gomock.Any(), userUUID, 1).Return(nil, serviceErr.AccessDeniedError).Times(1)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"error":"access_denied","message":"access denied"}`,
+		},
+		{
+			name: "Album not found",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetAlbumShares(gomock.Any(), userUUID, 1).Return(nil, serviceErr.AlbumNotFoundError).Times(1)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"error":"album_not_found","message":"Album not found."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.GET("/albums/:id/shares", h.getAlbumShares)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/albums/1/shares", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package health
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"sync/atomic"
+)
+
+// CheckFunc проверяет доступность зависимости. Возвращает ошибку, если зависимость недоступна.
+type CheckFunc func(ctx context.Context) error
+
+type Check struct {
+	Name string
+	Func CheckFunc
+}
+
+type handler struct {
+	checks   []Check
+	draining *atomic.Bool
+}
+
+// NewHandler создает обработчик liveness/readiness проверок.
+// Пока draining установлен в true (приложение завершается), /readyz отвечает 503.
+func NewHandler(draining *atomic.Bool, checks ...Check) *handler {
+	return &handler{
+		checks:   checks,
+		draining: draining,
+	}
+}
+
+func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/healthz", h.healthz)
+	router.GET("/readyz", h.readyz)
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	healthResp "go-photo/internal/handler/response/health"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const checkTimeout = 2 * time.Second
+
+const drainingCheckName = "shutdown"
+
+// @Summary Liveness probe
+// @Description Process is up and able to serve HTTP
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Status
+// @Router /healthz [get]
+func (h *handler) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, healthResp.Status{Status: healthResp.StatusOK})
+}
+
+// @Summary Readiness probe
+// @Description Checks database, storage and account service availability
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Status
+// @Failure 503 {object} health.Status
+// @Router /readyz [get]
+func (h *handler) readyz(c *gin.Context) {
+	if h.draining != nil && h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, healthResp.Status{
+			Status: healthResp.StatusUnavailable,
+			Checks: map[string]string{drainingCheckName: "server is shutting down"},
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, checkTimeout)
+	defer cancel()
+
+	results := make(map[string]string, len(h.checks))
+	ready := true
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			result := healthResp.StatusOK
+			err := check.Func(ctx)
+			if err != nil {
+				result = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[check.Name] = result
+			if err != nil {
+				ready = false
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, healthResp.Status{Status: healthResp.StatusUnavailable, Checks: results})
+		return
+	}
+
+	c.JSON(http.StatusOK, healthResp.Status{Status: healthResp.StatusOK, Checks: results})
+}
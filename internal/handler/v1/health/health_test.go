@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthResp "go-photo/internal/handler/response/health"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHandler_readyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	okCheck := func(ctx context.Context) error { return nil }
+	failCheck := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name               string
+		draining           bool
+		checks             []Check
+		expectedStatusCode int
+		expectedBody       healthResp.Status
+	}{
+		{
+			name:               "All checks passed",
+			checks:             []Check{{Name: "database", Func: okCheck}, {Name: "storage", Func: okCheck}},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: healthResp.Status{
+				Status: healthResp.StatusOK,
+				Checks: map[string]string{"database": "ok", "storage": "ok"},
+			},
+		},
+		{
+			name:               "One check failed",
+			checks:             []Check{{Name: "database", Func: failCheck}, {Name: "storage", Func: okCheck}},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody: healthResp.Status{
+				Status: healthResp.StatusUnavailable,
+				Checks: map[string]string{"database": "connection refused", "storage": "ok"},
+			},
+		},
+		{
+			name:               "Draining",
+			draining:           true,
+			checks:             []Check{{Name: "database", Func: okCheck}},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody: healthResp.Status{
+				Status: healthResp.StatusUnavailable,
+				Checks: map[string]string{drainingCheckName: "server is shutting down"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var draining atomic.Bool
+			draining.Store(tt.draining)
+
+			r := gin.New()
+			NewHandler(&draining, tt.checks...).RegisterRoutes(r.Group("/"))
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+
+			var body healthResp.Status
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedBody, body)
+		})
+	}
+}
+
+func TestHandler_healthz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var draining atomic.Bool
+	draining.Store(true)
+
+	r := gin.New()
+	NewHandler(&draining, Check{Name: "database", Func: func(ctx context.Context) error {
+		return errors.New("down")
+	}}).RegisterRoutes(r.Group("/"))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
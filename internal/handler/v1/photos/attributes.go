@@ -0,0 +1,313 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	"go-photo/internal/service/photo/model"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxPatchSize ограничение тела запроса на изменение атрибутов
+	maxPatchSize = 64 << 10
+
+	mergePatchContentType = "application/merge-patch+json"
+
+	// hiddenAny значение параметра hidden, при котором скрытые фото не фильтруются
+	hiddenAny = "any"
+)
+
+// @Summary Get photos
+// @Description Get user's photos, most recently uploaded first. Hidden photos are excluded unless hidden=true or hidden=any
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param favorite query bool false "Only favorite (true) or not favorite (false) photos"
+// @Param hidden query string false "true, false (default) or any"
+// @Param min_rating query int false "Minimum rating, 0-5"
+// @Param max_rating query int false "Maximum rating, 0-5"
+// @Param taken_from query string false "First day of capture, YYYY-MM-DD. Photos without capture time in EXIF are matched by upload day"
+// @Param taken_to query string false "Last day of capture, YYYY-MM-DD"
+// @Param tz query string false "IANA time zone of capture days for photos without capture time in EXIF, UTC by default"
+// @Param camera query string false "Part of camera make or model, case-insensitive"
+// @Param tag query string false "Photo tag, case-insensitive"
+// @Param published query bool false "Only published (true) or not published (false) photos"
+// @Param color query string false "Only photos with a dominant color close to this one, #rrggbb"
+// @Param tolerance query int false "Allowed color difference (CIE76), 1-50, 10 by default"
+// @Param limit query int false "Page size, 50 by default"
+// @Param offset query int false "Number of photos to skip"
+// @Success 200 {object} photo.GetPhotosResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos [get]
+func (h *handler) getPhotos(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	filter, err := parsePhotoFilter(c)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+
+	photos, err := h.photoService.GetPhotos(ctx, userUUID, filter)
+	if errors.Is(err, serviceErr.InvalidFilterError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid filter.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.GetPhotosResponse{
+		Photos: photoResp.ToPhotosFromModel(photos),
+	})
+}
+
+// @Summary Get photo
+// @Description Get photo attributes. ETag header holds the attributes version for conditional PATCH
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {object} photo.Photo
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id} [get]
+func (h *handler) getPhoto(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	idParam := c.Param("id")
+	photoID, err := strconv.Atoi(idParam)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	photo, err := h.photoService.GetPhoto(ctx, userUUID, photoID)
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found.")
+		return
+	}
+	if errors.Is(err, serviceErr.AccessDeniedError) {
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	c.Header("ETag", photo.ETag())
+	response.NewOk(c, photoResp.ToPhotoFromModel(*photo))
+}
+
+// @Summary Update photo attributes
+// @Description Change title, caption, favorite, rating or hidden with JSON Merge Patch (RFC 7396): omitted fields are kept, null resets a field.
+// @Description If-Match with the ETag from a previous response rejects the change when the photo was modified since.
+// @Tags photos
+// @Accept json
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Param If-Match header string false "ETag of the photo"
+// @Param input body model.PhotoAttributes true "Merge patch"
+// @Success 200 {object} photo.Photo
+// @Failure 400 {object} response.Error "Invalid patch."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found."
+// @Failure 412 {object} response.Error "Photo was modified."
+// @Failure 413 {object} response.Error "Request is too large."
+// @Failure 415 {object} response.Error "Unsupported content type."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id} [patch]
+func (h *handler) patchPhoto(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	idParam := c.Param("id")
+	photoID, err := strconv.Atoi(idParam)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != mergePatchContentType && mediaType != gin.MIMEJSON {
+		response.NewErr(c, http.StatusUnsupportedMediaType, response.UnsupportedContentType, nil,
+			fmt.Sprintf("Content-Type must be %s or %s.", mergePatchContentType, gin.MIMEJSON))
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if middleware.IsBodyTooLarge(err) {
+		response.NewErr(c, http.StatusRequestEntityTooLarge, response.RequestTooLarge, err, "Request body is too large.")
+		return
+	}
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Failed to read request body.")
+		return
+	}
+
+	photo, err := h.photoService.UpdatePhotoAttributes(ctx, userUUID, photoID, patch, c.GetHeader("If-Match"))
+	if errors.Is(err, serviceErr.InvalidPatchError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, err.Error())
+		return
+	}
+	if errors.Is(err, serviceErr.PreconditionFailedError) {
+		response.NewErr(c, http.StatusPreconditionFailed, response.PreconditionFailed, err,
+			"Photo was modified, fetch it again and retry.")
+		return
+	}
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found.")
+		return
+	}
+	if errors.Is(err, serviceErr.AccessDeniedError) {
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	c.Header("ETag", photo.ETag())
+	response.NewOk(c, photoResp.ToPhotoFromModel(*photo))
+}
+
+func parsePhotoFilter(c *gin.Context) (model.PhotoFilter, error) {
+	var filter model.PhotoFilter
+	var err error
+
+	if filter.Favorite, err = queryBool(c, "favorite"); err != nil {
+		return filter, err
+	}
+
+	// По умолчанию скрытые фото не показываются
+	hidden := false
+	filter.Hidden = &hidden
+	if c.Query("hidden") == hiddenAny {
+		filter.Hidden = nil
+	} else if v, err := queryBool(c, "hidden"); err != nil {
+		return filter, err
+	} else if v != nil {
+		filter.Hidden = v
+	}
+
+	if filter.MinRating, err = queryInt(c, "min_rating"); err != nil {
+		return filter, err
+	}
+	if filter.MaxRating, err = queryInt(c, "max_rating"); err != nil {
+		return filter, err
+	}
+
+	if filter.TakenFrom, err = queryDate(c, "taken_from"); err != nil {
+		return filter, err
+	}
+	if filter.TakenTo, err = queryDate(c, "taken_to"); err != nil {
+		return filter, err
+	}
+	filter.TimeZone = c.Query("tz")
+	filter.Camera = c.Query("camera")
+	filter.Tag = c.Query("tag")
+	if filter.Published, err = queryBool(c, "published"); err != nil {
+		return filter, err
+	}
+
+	filter.Color = c.Query("color")
+	if tolerance, err := queryInt(c, "tolerance"); err != nil {
+		return filter, err
+	} else if tolerance != nil {
+		filter.ColorTolerance = *tolerance
+	}
+
+	if limit, err := queryInt(c, "limit"); err != nil {
+		return filter, err
+	} else if limit != nil {
+		filter.Limit = *limit
+	}
+	if offset, err := queryInt(c, "offset"); err != nil {
+		return filter, err
+	} else if offset != nil {
+		filter.Offset = *offset
+	}
+
+	return filter, nil
+}
+
+func queryBool(c *gin.Context, key string) (*bool, error) {
+	v, ok := c.GetQuery(key)
+	if !ok {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s, expected true or false.", key)
+	}
+
+	return &b, nil
+}
+
+func queryDate(c *gin.Context, key string) (*time.Time, error) {
+	v, ok := c.GetQuery(key)
+	if !ok {
+		return nil, nil
+	}
+
+	d, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s, expected YYYY-MM-DD.", key)
+	}
+
+	return &d, nil
+}
+
+func queryInt(c *gin.Context, key string) (*int, error) {
+	v, ok := c.GetQuery(key)
+	if !ok {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s, expected integer.", key)
+	}
+
+	return &n, nil
+}
@@ -0,0 +1,255 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	serviceUserModel "go-photo/internal/service/user/model"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_patchPhoto(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := &model.Photo{ID: 123, Filename: "a.jpg", Title: "Sunset", Rating: 5, UpdatedAt: updatedAt}
+
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		contentType          string
+		ifMatch              string
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedETag         string
+		expectedResponseBody string
+	}{
+		{
+			name:        "Valid",
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"123-1"`,
+			body:        `{"title": "Sunset", "rating": 5}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().
+					UpdatePhotoAttributes(gomock.Any(), userUUID, 123, []byte(`{"title": "Sunset", "rating": 5}`), `"123-1"`).
+					Return(updated, nil).
+					Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedETag:         updated.ETag(),
+			expectedResponseBody: `{"photo_id":123,"filename":"a.jpg","title":"Sunset","caption":"","favorite":false,"rating":5,"hidden":false,"uploaded_at":"0001-01-01 00:00:00","updated_at":"2024-01-01 00:00:00"}`,
+		},
+		{
+			name:        "Stale ETag",
+			contentType: "application/json",
+			ifMatch:     `"123-0"`,
+			body:        `{"rating": 5}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().
+					UpdatePhotoAttributes(gomock.Any(), userUUID, 123, gomock.Any(), `"123-0"`).
+					Return(nil, serviceErr.PreconditionFailedError).
+					Times(1)
+			},
+			expectedStatusCode:   412,
+			expectedResponseBody: `{"error":"precondition_failed","message":"Photo was modified, fetch it again and retry."}`,
+		},
+		{
+			name:        "Invalid patch",
+			contentType: "application/json",
+			body:        `{"rating": 6}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().
+					UpdatePhotoAttributes(gomock.Any(), userUUID, 123, gomock.Any(), "").
+					Return(nil, serviceErr.InvalidPatchError).
+					Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"invalid patch"}`,
+		},
+		{
+			name:                 "Unsupported content type",
+			contentType:          "text/plain",
+			body:                 `{"rating": 5}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   415,
+			expectedResponseBody: `{"error":"unsupported_content_type","message":"Content-Type must be application/merge-patch+json or application/json."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.PATCH("/photos/:id", h.patchPhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/photos/123", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedETag, w.Header().Get("ETag"))
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getPhotos(t *testing.T) {
+	visible := false
+	favorite := true
+	minRating := 4
+	takenFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	takenTo := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedFilter     *serviceModel.PhotoFilter
+		expectedStatusCode int
+	}{
+		{
+			name:               "Hidden photos excluded by default",
+			query:              "",
+			expectedFilter:     &serviceModel.PhotoFilter{Hidden: &visible},
+			expectedStatusCode: 200,
+		},
+		{
+			name:  "All filters",
+			query: "?favorite=true&hidden=any&min_rating=4&limit=10&offset=20",
+			expectedFilter: &serviceModel.PhotoFilter{
+				Favorite: &favorite, MinRating: &minRating, Limit: 10, Offset: 20,
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Color",
+			query:              "?color=%233366cc&tolerance=20",
+			expectedFilter:     &serviceModel.PhotoFilter{Hidden: &visible, Color: "#3366cc", ColorTolerance: 20},
+			expectedStatusCode: 200,
+		},
+		{
+			name:  "Capture days, camera and publication",
+			query: "?taken_from=2024-01-01&taken_to=2024-12-31&tz=Europe/Moscow&camera=fujifilm&published=false",
+			expectedFilter: &serviceModel.PhotoFilter{
+				Hidden: &visible, TakenFrom: &takenFrom, TakenTo: &takenTo, TimeZone: "Europe/Moscow", Camera: "fujifilm",
+				Published: &visible,
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Tag",
+			query:              "?tag=sea",
+			expectedFilter:     &serviceModel.PhotoFilter{Hidden: &visible, Tag: "sea"},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Invalid date",
+			query:              "?taken_from=2024",
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Invalid bool",
+			query:              "?favorite=maybe",
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Invalid int",
+			query:              "?limit=ten",
+			expectedStatusCode: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			if tt.expectedFilter != nil {
+				mockPhotoService.EXPECT().
+					GetPhotos(gomock.Any(), userUUID, *tt.expectedFilter).
+					Return([]model.Photo{}, nil).
+					Times(1)
+			}
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.GET("/photos", h.getPhotos)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/photos"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_getPhotos_Placeholder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const userUUID = "1abc4"
+	mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+	mockPhotoService.EXPECT().GetPhotos(gomock.Any(), userUUID, gomock.Any()).Return([]model.Photo{
+		{ID: 1, Filename: "a.jpg", Placeholder: model.Placeholder{BlurHash: "LKO2?U%2Tw=w", Palette: []string{"#3366cc", "#cc2211"}}},
+		{ID: 2, Filename: "b.jpg"},
+	}, nil)
+
+	h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+	r := newAuthRouter(userUUID)
+	r.GET("/photos", h.getPhotos)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/photos", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"photos":[`+
+		`{"photo_id":1,"filename":"a.jpg","title":"","caption":"","favorite":false,"rating":0,"hidden":false,`+
+		`"uploaded_at":"0001-01-01 00:00:00","updated_at":"0001-01-01 00:00:00",`+
+		`"blurhash":"LKO2?U%2Tw=w","palette":["#3366cc","#cc2211"]},`+
+		`{"photo_id":2,"filename":"b.jpg","title":"","caption":"","favorite":false,"rating":0,"hidden":false,`+
+		`"uploaded_at":"0001-01-01 00:00:00","updated_at":"0001-01-01 00:00:00"}]}`, w.Body.String())
+}
+
+func newAuthRouter(userUUID string) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
+		if token == "valid-token" {
+			return serviceUserModel.TokenPayload{UserUUID: userUUID}, nil
+		}
+		return serviceUserModel.TokenPayload{}, errors.New("invalid token")
+	}))
+	return r
+}
@@ -0,0 +1,130 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/request"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	"go-photo/internal/service/photo/model"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Bulk photos action
+// @Description Apply an action (publish, unpublish, delete, add_to_album, tag) to several photos. Each photo is checked separately, failed photos do not affect the others.
+// @Description add_to_album adds own photos to the album album_id, which requires contributor access to it
+// @Description tag adds the tag to own photos. Tags are trimmed and lowercased, at most 50 characters
+// @Tags photos
+// @Accept json
+// @Produce json
+// @Security JWTAuth
+// @Param input body request.BulkPhotos true "Action and photo IDs"
+// @Success 200 {object} photo.BulkPhotosResponse
+// @Failure 206 {object} photo.BulkPhotosResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/bulk [post]
+func (h *handler) bulkPhotos(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	respStatus := http.StatusOK
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	var input request.BulkPhotos
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid request body format.")
+		return
+	}
+
+	if len(input.PhotoIDs) > h.opts.MaxBulkPhotos {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, nil,
+			fmt.Sprintf("Too many photos, at most %d allowed.", h.opts.MaxBulkPhotos))
+		return
+	}
+
+	action, err := model.ParseBulkAction(input.Action)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err,
+			fmt.Sprintf("Unsupported action %q.", input.Action))
+		return
+	}
+
+	if action == model.BulkAddToAlbum && input.AlbumID == 0 {
+		response.NewErr(c, http.StatusBadRequest, response.ParamsMissing, nil,
+			fmt.Sprintf("album_id is required for %s.", action))
+		return
+	}
+
+	if action == model.BulkTag {
+		if strings.TrimSpace(input.Tag) == "" {
+			response.NewErr(c, http.StatusBadRequest, response.ParamsMissing, nil,
+				fmt.Sprintf("tag is required for %s.", action))
+			return
+		}
+		if _, err := model.NormalizeTag(input.Tag); err != nil {
+			response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err,
+				fmt.Sprintf("Tag must be at most %d characters.", model.MaxTagLength))
+			return
+		}
+	}
+
+	results, err := h.photoService.BulkUpdatePhotos(ctx, userUUID, action, input.PhotoIDs,
+		model.BulkOptions{AlbumID: input.AlbumID, Tag: input.Tag})
+	if errors.Is(err, serviceErr.AllFailedError) {
+		respStatus = http.StatusBadRequest
+	} else if errors.Is(err, serviceErr.ParticalSuccessError) {
+		respStatus = http.StatusPartialContent
+	} else if response.HandleError(c, err) {
+		return
+	}
+
+	body := photoResp.BulkPhotosResponse{
+		TotalCount:   len(results),
+		SuccessCount: results.SuccessCount(),
+		Results:      make([]photoResp.BulkResult, 0, len(results)),
+	}
+	for _, result := range results {
+		body.Results = append(body.Results, photoResp.BulkResult{
+			PhotoID:     result.PhotoID,
+			PublicToken: result.PublicToken,
+			Error:       string(bulkErrMessage(result.Error)),
+		})
+	}
+
+	c.JSON(respStatus, body)
+}
+
+// bulkErrMessage возвращает код ошибки для результата одного фото, пустой при успехе.
+func bulkErrMessage(err error) response.ErrMessage {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, serviceErr.PhotoNotFoundError):
+		return response.PhotoNotFound
+	case errors.Is(err, serviceErr.AccessDeniedError):
+		return response.Forbidden
+	case errors.Is(err, serviceErr.AlbumNotFoundError):
+		return response.AlbumNotFound
+	case errors.Is(err, serviceErr.AlreadyExists):
+		return response.PhotoAlreadyPublished
+	case errors.Is(err, context.DeadlineExceeded):
+		return response.TimedOut
+	default:
+		return response.InternalServerError
+	}
+}
@@ -0,0 +1,187 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/handler/middleware"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	serviceUserModel "go-photo/internal/service/user/model"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_bulkPhotos(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		userUUID             string
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "Valid",
+			userUUID: "1abc4",
+			body:     `{"action": "publish", "photo_ids": [1, 2]}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().BulkUpdatePhotos(gomock.Any(), userUUID, serviceModel.BulkPublish, []int{1, 2}, serviceModel.BulkOptions{}).
+					Return(serviceModel.BulkResults{
+						{PhotoID: 1, PublicToken: "token-1"},
+						{PhotoID: 2, PublicToken: "token-2"},
+					}, nil).
+					Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"total_count":2,"success_count":2,"results":[{"photo_id":1,"public_token":"token-1"},{"photo_id":2,"public_token":"token-2"}]}`,
+		},
+		{
+			name:     "Partial success",
+			userUUID: "1abc4",
+			body:     `{"action": "delete", "photo_ids": [1, 2, 3]}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().BulkUpdatePhotos(gomock.Any(), userUUID, serviceModel.BulkDelete, []int{1, 2, 3}, serviceModel.BulkOptions{}).
+					Return(serviceModel.BulkResults{
+						{PhotoID: 1},
+						{PhotoID: 2, Error: serviceErr.AccessDeniedError},
+						{PhotoID: 3, Error: serviceErr.PhotoNotFoundError},
+					}, serviceErr.ParticalSuccessError).
+					Times(1)
+			},
+			expectedStatusCode:   206,
+			expectedResponseBody: `{"total_count":3,"success_count":1,"results":[{"photo_id":1},{"photo_id":2,"error":"access_denied"},{"photo_id":3,"error":"photo_not_found"}]}`,
+		},
+		{
+			name:     "All failed",
+			userUUID: "1abc4",
+			body:     `{"action": "publish", "photo_ids": [1]}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().BulkUpdatePhotos(gomock.Any(), userUUID, serviceModel.BulkPublish, []int{1}, serviceModel.BulkOptions{}).
+					Return(serviceModel.BulkResults{
+						{PhotoID: 1, Error: serviceErr.AlreadyExists},
+					}, serviceErr.AllFailedError).
+					Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"total_count":1,"success_count":0,"results":[{"photo_id":1,"error":"photo_already_published"}]}`,
+		},
+		{
+			name:     "Add to album",
+			userUUID: "1abc4",
+			body:     `{"action": "add_to_album", "photo_ids": [1, 2], "album_id": 7}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().BulkUpdatePhotos(gomock.Any(), userUUID, serviceModel.BulkAddToAlbum, []int{1, 2}, serviceModel.BulkOptions{AlbumID: 7}).
+					Return(serviceModel.BulkResults{
+						{PhotoID: 1},
+						{PhotoID: 2, Error: serviceErr.AlbumNotFoundError},
+					}, serviceErr.ParticalSuccessError).
+					Times(1)
+			},
+			expectedStatusCode:   206,
+			expectedResponseBody: `{"total_count":2,"success_count":1,"results":[{"photo_id":1},{"photo_id":2,"error":"album_not_found"}]}`,
+		},
+		{
+			name:                 "Add to album without album id",
+			userUUID:             "1abc4",
+			body:                 `{"action": "add_to_album", "photo_ids": [1]}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"params_missing","message":"album_id is required for add_to_album."}`,
+		},
+		{
+			name:     "Tag",
+			userUUID: "1abc4",
+			body:     `{"action": "tag", "photo_ids": [1, 2], "tag": "Sea"}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().BulkUpdatePhotos(gomock.Any(), userUUID, serviceModel.BulkTag, []int{1, 2}, serviceModel.BulkOptions{Tag: "Sea"}).
+					Return(serviceModel.BulkResults{
+						{PhotoID: 1},
+						{PhotoID: 2, Error: serviceErr.AccessDeniedError},
+					}, serviceErr.ParticalSuccessError).
+					Times(1)
+			},
+			expectedStatusCode:   206,
+			expectedResponseBody: `{"total_count":2,"success_count":1,"results":[{"photo_id":1},{"photo_id":2,"error":"access_denied"}]}`,
+		},
+		{
+			name:                 "Tag without tag",
+			userUUID:             "1abc4",
+			body:                 `{"action": "tag", "photo_ids": [1], "tag": " "}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"params_missing","message":"tag is required for tag."}`,
+		},
+		{
+			name:                 "Tag too long",
+			userUUID:             "1abc4",
+			body:                 `{"action": "tag", "photo_ids": [1], "tag": "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Tag must be at most 50 characters."}`,
+		},
+		{
+			name:                 "Unsupported action",
+			userUUID:             "1abc4",
+			body:                 `{"action": "rotate", "photo_ids": [1]}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Unsupported action \"rotate\"."}`,
+		},
+		{
+			name:                 "No photo ids",
+			userUUID:             "1abc4",
+			body:                 `{"action": "delete", "photo_ids": []}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Invalid request body format."}`,
+		},
+		{
+			name:                 "Too many photo ids",
+			userUUID:             "1abc4",
+			body:                 `{"action": "delete", "photo_ids": [1, 2, 3, 4]}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Too many photos, at most 3 allowed."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, tt.userUUID)
+
+			mockTokenService := mockservice.NewMockTokenService(ctrl)
+
+			h := NewHandler(mockPhotoService, mockTokenService, Options{MaxBulkPhotos: 3})
+
+			r := gin.New()
+			r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
+				if token == "valid-token" {
+					return serviceUserModel.TokenPayload{UserUUID: tt.userUUID}, nil
+				}
+				return serviceUserModel.TokenPayload{}, errors.New("invalid token")
+			}))
+			r.POST("/photos/bulk", h.bulkPhotos)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/photos/bulk", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/request"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	"go-photo/internal/service/photo/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Edit photo
+// @Description Apply operations (rotate, flip, crop, brightness, contrast, grayscale) to the original photo
+// @Description and save the result as the "edited" version, replacing the previous one. The original is not changed
+// @Tags photos
+// @Accept json
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Param input body request.EditPhoto true "Edit operations"
+// @Success 200 {object} photo.PhotoEditResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/edits [post]
+func (h *handler) editPhoto(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	var input request.EditPhoto
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid request body format.")
+		return
+	}
+	if len(input.Operations) > model.MaxEditOperations {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, nil,
+			fmt.Sprintf("Too many operations, at most %d allowed.", model.MaxEditOperations))
+		return
+	}
+
+	edit, err := h.photoService.EditPhoto(ctx, userUUID, photoID, toEditOperations(input.Operations))
+	if handleEditError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toPhotoEditResponse(edit))
+}
+
+// @Summary Get photo edit
+// @Description Get the edit recipe of a photo and its edited version
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {object} photo.PhotoEditResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found or not edited."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/edits [get]
+func (h *handler) getPhotoEdit(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	edit, err := h.photoService.GetPhotoEdit(ctx, userUUID, photoID)
+	if handleEditError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toPhotoEditResponse(edit))
+}
+
+// @Summary Reapply photo edit
+// @Description Create the edited version again from the original by the saved recipe
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {object} photo.PhotoEditResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found or not edited."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/edits/reapply [post]
+func (h *handler) reapplyPhotoEdit(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	edit, err := h.photoService.ReapplyPhotoEdit(ctx, userUUID, photoID)
+	if handleEditError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toPhotoEditResponse(edit))
+}
+
+// @Summary Revert photo edit
+// @Description Delete the edited version of a photo together with its recipe
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {object} nil
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found or not edited."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/edits [delete]
+func (h *handler) revertPhotoEdit(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	err = h.photoService.RevertPhotoEdit(ctx, userUUID, photoID)
+	if handleEditError(c, err) {
+		return
+	}
+
+	response.NewOk(c, nil)
+}
+
+// handleEditError отвечает клиенту ошибкой сервиса правок. Возвращает false, если ошибки нет.
+func handleEditError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, serviceErr.InvalidEditError):
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, err.Error())
+	case errors.Is(err, serviceErr.PhotoNotFoundError):
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found or not edited.")
+	case errors.Is(err, serviceErr.AccessDeniedError):
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+	default:
+		return response.HandleError(c, err)
+	}
+	return true
+}
+
+func toEditOperations(ops []request.EditOperation) []model.EditOperation {
+	res := make([]model.EditOperation, len(ops))
+	for i, op := range ops {
+		res[i] = model.EditOperation{
+			Type:      model.EditOperationType(op.Type),
+			Angle:     op.Angle,
+			Direction: op.Direction,
+			X:         op.X,
+			Y:         op.Y,
+			Width:     op.Width,
+			Height:    op.Height,
+			Value:     op.Value,
+		}
+	}
+	return res
+}
+
+func toPhotoEditResponse(edit *model.PhotoEdit) photoResp.PhotoEditResponse {
+	ops := make([]photoResp.EditOperation, len(edit.Operations))
+	for i, op := range edit.Operations {
+		ops[i] = photoResp.EditOperation{
+			Type:      string(op.Type),
+			Angle:     op.Angle,
+			Direction: op.Direction,
+			X:         op.X,
+			Y:         op.Y,
+			Width:     op.Width,
+			Height:    op.Height,
+			Value:     op.Value,
+		}
+	}
+
+	return photoResp.PhotoEditResponse{
+		PhotoID:    edit.PhotoID,
+		Operations: ops,
+		Version:    photoResp.ToPhotoVersionFromModel(edit.Version),
+		UpdatedAt:  edit.UpdatedAt.Format(time.DateTime),
+	}
+}
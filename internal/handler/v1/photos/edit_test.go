@@ -0,0 +1,147 @@
+package photos
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_editPhoto(t *testing.T) {
+	savedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ops := []serviceModel.EditOperation{
+		{Type: serviceModel.EditRotate, Angle: 90},
+		{Type: serviceModel.EditCrop, X: 1, Y: 2, Width: 10, Height: 20},
+	}
+	edit := &serviceModel.PhotoEdit{
+		PhotoID:    123,
+		Operations: ops,
+		Version: model.PhotoVersion{
+			ID: 7, PhotoID: 123, VersionType: model.Edited, UUIDFilename: "edited.jpg", Size: 100, Height: 20, Width: 10, SavedAt: savedAt,
+		},
+		UpdatedAt: savedAt,
+	}
+
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Valid",
+			body: `{"operations": [{"type": "rotate", "angle": 90}, {"type": "crop", "x": 1, "y": 2, "width": 10, "height": 20}]}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().EditPhoto(gomock.Any(), userUUID, 123, ops).Return(edit, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"photo_id":123,"operations":[{"type":"rotate","angle":90},{"type":"crop","x":1,"y":2,"width":10,"height":20}],` +
+				`"version":{"photo_id":123,"version_type":"edited","uuid_filename":"edited.jpg","size":100,"height":20,"width":10,"saved_at":"2024-01-01 00:00:00"},` +
+				`"updated_at":"2024-01-01 00:00:00"}`,
+		},
+		{
+			name: "Invalid operation",
+			body: `{"operations": [{"type": "rotate", "angle": 45}]}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().EditPhoto(gomock.Any(), userUUID, 123, gomock.Any()).Return(nil, serviceErr.InvalidEditError).Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"invalid edit"}`,
+		},
+		{
+			name:                 "No operations",
+			body:                 `{"operations": []}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Invalid request body format."}`,
+		},
+		{
+			name: "Photo not found",
+			body: `{"operations": [{"type": "grayscale"}]}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().EditPhoto(gomock.Any(), userUUID, 123, gomock.Any()).Return(nil, serviceErr.PhotoNotFoundError).Times(1)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"error":"photo_not_found","message":"Photo not found or not edited."}`,
+		},
+		{
+			name: "Access denied",
+			body: `{"operations": [{"type": "grayscale"}]}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().EditPhoto(gomock.Any(), userUUID, 123, gomock.Any()).Return(nil, serviceErr.AccessDeniedError).Times(1)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"error":"access_denied","message":"You do not have access to this photo."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.POST("/photos/:id/edits", h.editPhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/photos/123/edits", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_revertPhotoEdit(t *testing.T) {
+	tests := []struct {
+		name               string
+		serviceErr         error
+		expectedStatusCode int
+	}{
+		{name: "Reverted", expectedStatusCode: 200},
+		{name: "Not edited", serviceErr: serviceErr.PhotoNotFoundError, expectedStatusCode: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			mockPhotoService.EXPECT().RevertPhotoEdit(gomock.Any(), userUUID, 123).Return(tt.serviceErr).Times(1)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.DELETE("/photos/:id/edits", h.revertPhotoEdit)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/photos/123/edits", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
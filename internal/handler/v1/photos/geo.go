@@ -0,0 +1,144 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	"go-photo/internal/service/photo/model"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get photos on map
+// @Description Get user's photos taken inside the bounding box, most recently uploaded first.
+// @Description Accepts the same filters as the photo list. Hidden photos are excluded unless hidden=true or hidden=any
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param bbox query string true "Bounding box: west,south,east,north in degrees. West greater than east crosses the antimeridian"
+// @Param favorite query bool false "Only favorite (true) or not favorite (false) photos"
+// @Param hidden query string false "true, false (default) or any"
+// @Param limit query int false "Page size, 50 by default"
+// @Param offset query int false "Number of photos to skip"
+// @Success 200 {object} photo.GetPhotosResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/geo [get]
+func (h *handler) getGeoPhotos(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	filter, err := parsePhotoFilter(c)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+	bbox, err := queryBBox(c, "bbox")
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+	filter.BBox = &bbox
+
+	photos, err := h.photoService.GetPhotos(ctx, userUUID, filter)
+	if errors.Is(err, serviceErr.InvalidFilterError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid filter.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.GetPhotosResponse{
+		Photos: photoResp.ToPhotosFromModel(photos),
+	})
+}
+
+// @Summary Get photo clusters on map
+// @Description Group user's photos taken inside the bounding box into grid cells sized for the map zoom level.
+// @Description Each cluster has the number of photos, their average location and a representative photo. Hidden photos are excluded
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param bbox query string true "Bounding box: west,south,east,north in degrees. West greater than east crosses the antimeridian"
+// @Param zoom query int true "Map zoom level, 0-22"
+// @Success 200 {object} photo.GetPhotoClustersResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/geo/clusters [get]
+func (h *handler) getPhotoClusters(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	bbox, err := queryBBox(c, "bbox")
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+	zoom, err := queryInt(c, "zoom")
+	if err == nil && zoom == nil {
+		err = errors.New("Missing zoom.")
+	}
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+
+	clusters, err := h.photoService.GetPhotoClusters(ctx, userUUID, model.ClusterFilter{BBox: bbox, Zoom: *zoom})
+	if errors.Is(err, serviceErr.InvalidFilterError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid filter.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.GetPhotoClustersResponse{
+		Clusters: photoResp.ToPhotoClustersFromModel(clusters),
+	})
+}
+
+// queryBBox разбирает обязательный параметр с прямоугольником в формате west,south,east,north.
+func queryBBox(c *gin.Context, key string) (model.BBox, error) {
+	v, ok := c.GetQuery(key)
+	if !ok {
+		return model.BBox{}, fmt.Errorf("Missing %s.", key)
+	}
+
+	parts := strings.Split(v, ",")
+	if len(parts) != 4 {
+		return model.BBox{}, fmt.Errorf("Invalid %s, expected west,south,east,north.", key)
+	}
+	var coords [4]float64
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return model.BBox{}, fmt.Errorf("Invalid %s, expected west,south,east,north.", key)
+		}
+		coords[i] = n
+	}
+
+	return model.BBox{West: coords[0], South: coords[1], East: coords[2], North: coords[3]}, nil
+}
@@ -0,0 +1,143 @@
+package photos
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_getGeoPhotos(t *testing.T) {
+	visible := false
+
+	tests := []struct {
+		name                 string
+		query                string
+		expectedFilter       *serviceModel.PhotoFilter
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:               "Photos in bounding box",
+			query:              "?bbox=37.3,55.5,37.9,56&limit=10",
+			expectedFilter:     &serviceModel.PhotoFilter{Hidden: &visible, BBox: &serviceModel.BBox{West: 37.3, South: 55.5, East: 37.9, North: 56}, Limit: 10},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"photos":[{"photo_id":1,"filename":"a.jpg","title":"","caption":"","favorite":false,"rating":0,"hidden":false,` +
+				`"uploaded_at":"0001-01-01 00:00:00","updated_at":"0001-01-01 00:00:00","location":{"latitude":55.75,"longitude":37.61}}]}`,
+		},
+		{
+			name:                 "No bounding box",
+			query:                "",
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Missing bbox."}`,
+		},
+		{
+			name:                 "Invalid bounding box",
+			query:                "?bbox=37.3,55.5,37.9",
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid bbox, expected west,south,east,north."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			if tt.expectedFilter != nil {
+				mockPhotoService.EXPECT().
+					GetPhotos(gomock.Any(), userUUID, *tt.expectedFilter).
+					Return([]model.Photo{{ID: 1, Filename: "a.jpg", Location: &model.Location{Latitude: 55.75, Longitude: 37.61}}}, nil).
+					Times(1)
+			}
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.GET("/photos/geo", h.getGeoPhotos)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/photos/geo"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getPhotoClusters(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Clusters",
+			query: "?bbox=170,-50,-170,-30&zoom=5",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetPhotoClusters(gomock.Any(), userUUID, serviceModel.ClusterFilter{
+					BBox: serviceModel.BBox{West: 170, South: -50, East: -170, North: -30},
+					Zoom: 5,
+				}).Return([]model.PhotoCluster{
+					{Location: model.Location{Latitude: -41.29, Longitude: 174.78}, Count: 12, PhotoID: 7},
+				}, nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"clusters":[{"location":{"latitude":-41.29,"longitude":174.78},"count":12,"photo_id":7}]}`,
+		},
+		{
+			name:                 "No zoom",
+			query:                "?bbox=37.3,55.5,37.9,56",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Missing zoom."}`,
+		},
+		{
+			name:  "Invalid filter",
+			query: "?bbox=37.3,55.5,37.9,56&zoom=30",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetPhotoClusters(gomock.Any(), userUUID, gomock.Any()).Return(nil, serviceErr.InvalidFilterError).Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid filter."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.GET("/photos/geo/clusters", h.getPhotoClusters)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/photos/geo/clusters"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
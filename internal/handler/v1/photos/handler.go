@@ -2,19 +2,49 @@ package photos
 
 import (
 	"github.com/gin-gonic/gin"
+	"go-photo/internal/config"
 	"go-photo/internal/handler/middleware"
 	"go-photo/internal/service"
+	"time"
 )
 
+// Options таймауты и ограничения хендлеров фото.
+// Нулевые значения означают таймаут по умолчанию и отсутствие ограничений.
+type Options struct {
+	RequestTimeout time.Duration
+	// MaxFileSize максимальный размер одного файла в байтах.
+	MaxFileSize int64
+	// MaxRequestSize максимальный размер тела запроса на загрузку в байтах.
+	MaxRequestSize int64
+	// MaxBatchFiles максимальное количество файлов в пакетной загрузке.
+	MaxBatchFiles int
+	// RetryAfter значение заголовка Retry-After, когда очередь загрузок переполнена.
+	RetryAfter time.Duration
+	// MaxBulkPhotos максимальное количество фото в одном групповом действии.
+	MaxBulkPhotos int
+}
+
 type handler struct {
 	photoService service.PhotoService
 	tokenService service.TokenService
+	opts         Options
 }
 
-func NewHandler(photoService service.PhotoService, tokenService service.TokenService) *handler {
+func NewHandler(photoService service.PhotoService, tokenService service.TokenService, opts Options) *handler {
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = config.DefaultContextTimeout
+	}
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = config.DefaultUploadRetryAfter
+	}
+	if opts.MaxBulkPhotos <= 0 {
+		opts.MaxBulkPhotos = config.DefaultMaxBulkPhotos
+	}
+
 	return &handler{
 		photoService: photoService,
 		tokenService: tokenService,
+		opts:         opts,
 	}
 }
 
@@ -24,14 +54,35 @@ func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
 	photosGroup.Use(middleware.UserIdentity(h.tokenService.VerifyToken))
 
 	{
-		photosGroup.POST("/", h.uploadPhoto)
-		photosGroup.POST("/batch", h.uploadBatchPhotos)
+		maxBody := middleware.MaxBodySize(h.opts.MaxRequestSize)
+
+		photosGroup.GET("", h.getPhotos)
+		photosGroup.POST("/", maxBody, h.uploadPhoto)
+		photosGroup.POST("/batch", maxBody, h.uploadBatchPhotos)
+		photosGroup.POST("/bulk", middleware.MaxBodySize(maxPatchSize), h.bulkPhotos)
+		photosGroup.GET("/search", h.searchPhotos)
+		photosGroup.GET("/geo", h.getGeoPhotos)
+		photosGroup.GET("/geo/clusters", h.getPhotoClusters)
 		{
 			photoGroup := photosGroup.Group("/:id")
 
+			photoGroup.GET("", h.getPhoto)
+			photoGroup.PATCH("", middleware.MaxBodySize(maxPatchSize), h.patchPhoto)
+			photoGroup.DELETE("", h.deletePhoto)
 			photoGroup.GET("/versions", h.getPhotoVersions)
+			photoGroup.POST("/versions", maxBody, h.uploadPhotoRevision)
+			photoGroup.GET("/revisions", h.getPhotoRevisions)
+			photoGroup.POST("/revisions/:revision/restore", h.restorePhotoRevision)
+			photoGroup.GET("/file", h.getPhotoFile)
+			photoGroup.GET("/edits", h.getPhotoEdit)
+			photoGroup.POST("/edits", middleware.MaxBodySize(maxPatchSize), h.editPhoto)
+			photoGroup.POST("/edits/reapply", h.reapplyPhotoEdit)
+			photoGroup.DELETE("/edits", h.revertPhotoEdit)
 			photoGroup.POST("/publicate", h.publishPhoto)
 			photoGroup.DELETE("/unpublicate", h.unpublicatePhoto)
+			photoGroup.GET("/shares", h.getPhotoShares)
+			photoGroup.PUT("/shares/:userUUID", middleware.MaxBodySize(maxPatchSize), h.sharePhoto)
+			photoGroup.DELETE("/shares/:userUUID", h.unsharePhoto)
 		}
 
 	}
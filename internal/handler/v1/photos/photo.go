@@ -4,18 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"go-photo/internal/config"
 	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/request"
 	"go-photo/internal/handler/response"
 	"go-photo/internal/handler/response/auth"
 	photoResp "go-photo/internal/handler/response/photo"
 	serviceErr "go-photo/internal/service/error"
 	"go-photo/internal/service/photo/model"
 	"go-photo/internal/utils"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,6 +27,17 @@ const (
 	FormPhotoBatchFiles = "batch_photo_files"
 )
 
+const (
+	versionQueryParam        = "version"
+	versionQueryParamDefault = "original"
+)
+
+const (
+	watermarkQueryParam = "watermark"
+	metadataQueryParam  = "metadata"
+	albumIDQueryParam   = "album_id"
+)
+
 // @Summary Upload photo
 // @Description Upload single photo
 // @Tags photos
@@ -35,10 +48,12 @@ const (
 // @Success 200 {object} photo.UploadPhotoResponse
 // @Failure 400 {object} response.Error "Bad Request."
 // @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 413 {object} response.Error "File is too large."
 // @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Failure 503 {object} response.Error "Upload queue is full, retry after Retry-After seconds."
 // @Router /api/v1/photos/ [post]
 func (h *handler) uploadPhoto(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c, config.DefaultContextTimeout)
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
 	defer cancel()
 
 	uuid, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
@@ -47,11 +62,21 @@ func (h *handler) uploadPhoto(c *gin.Context) {
 	}
 
 	fileHeader, err := c.FormFile(FormPhotoFile)
+	if middleware.IsBodyTooLarge(err) {
+		response.NewErr(c, http.StatusRequestEntityTooLarge, response.RequestTooLarge, err, "Request body is too large.")
+		return
+	}
 	if err != nil {
 		response.NewErr(c, http.StatusBadRequest, response.ParamsMissing, err, fmt.Sprintf("No %s in form.", FormPhotoFile))
 		return
 	}
 
+	if h.fileTooLarge(fileHeader) {
+		response.NewErr(c, http.StatusRequestEntityTooLarge, response.RequestTooLarge, nil,
+			fmt.Sprintf("File %s exceeds %d bytes.", fileHeader.Filename, h.opts.MaxFileSize))
+		return
+	}
+
 	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
 	if !utils.IsPhoto(ext) {
 		response.NewErr(c, http.StatusBadRequest, response.UnsupportedFileType, nil, "Unsupported file type: "+ext)
@@ -59,6 +84,10 @@ func (h *handler) uploadPhoto(c *gin.Context) {
 	}
 
 	photoID, err := h.photoService.UploadPhoto(ctx, uuid, fileHeader)
+	if errors.Is(err, serviceErr.UploadQueueFullError) {
+		h.uploadQueueFull(c, err)
+		return
+	}
 	if response.HandleError(c, err) {
 		return
 	}
@@ -67,20 +96,26 @@ func (h *handler) uploadPhoto(c *gin.Context) {
 }
 
 // @Summary Upload batch photos
-// @Description Upload multiple photos
+// @Description Upload multiple photos. With album_id the photos are also added to the album,
+// @Description which requires contributor access to it. Uploaded photos belong to the uploader
 // @Tags photos
 // @Accept multipart/form-data
 // @Produce json
 // @Security JWTAuth
 // @Param batch_photo_files formData file true "Batch photo files"
+// @Param album_id query int false "Album to add the photos to"
 // @Success 200 {object} photo.UploadBatchPhotosResponse
 // @Failure 206 {object} photo.UploadBatchPhotosResponse
 // @Failure 400 {object} response.Error "Bad Request."
 // @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Album not found."
+// @Failure 413 {object} response.Error "Request or file is too large."
 // @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Failure 503 {object} response.Error "Upload queue is full, retry after Retry-After seconds."
 // @Router /api/v1/photos/batch [post]
 func (h *handler) uploadBatchPhotos(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c, config.DefaultContextTimeout)
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
 	defer cancel()
 
 	respStatus := http.StatusOK
@@ -91,7 +126,21 @@ func (h *handler) uploadBatchPhotos(c *gin.Context) {
 		return
 	}
 
+	albumID := 0
+	if v, ok := c.GetQuery(albumIDQueryParam); ok {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid album_id, expected integer.")
+			return
+		}
+		albumID = id
+	}
+
 	form, err := c.MultipartForm()
+	if middleware.IsBodyTooLarge(err) {
+		response.NewErr(c, http.StatusRequestEntityTooLarge, response.RequestTooLarge, err, "Request body is too large.")
+		return
+	}
 	if err != nil {
 		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "No form data.")
 		return
@@ -103,12 +152,39 @@ func (h *handler) uploadBatchPhotos(c *gin.Context) {
 		return
 	}
 
+	if h.opts.MaxBatchFiles > 0 && len(files) > h.opts.MaxBatchFiles {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, nil,
+			fmt.Sprintf("Too many files, at most %d allowed.", h.opts.MaxBatchFiles))
+		return
+	}
+
+	for _, file := range files {
+		if h.fileTooLarge(file) {
+			response.NewErr(c, http.StatusRequestEntityTooLarge, response.RequestTooLarge, nil,
+				fmt.Sprintf("File %s exceeds %d bytes.", file.Filename, h.opts.MaxFileSize))
+			return
+		}
+	}
+
 	if ok, notPhoto := utils.IsAllPhotos(files); !ok {
 		response.NewErr(c, http.StatusBadRequest, response.UnsupportedFileType, err, fmt.Sprintf("File %s is not a photo.", notPhoto))
 		return
 	}
 
-	uploads, err := h.photoService.UploadBatchPhotos(ctx, uuid, files)
+	var uploads *model.UploadInfoList
+	if albumID != 0 {
+		uploads, err = h.photoService.UploadAlbumPhotos(ctx, uuid, albumID, files)
+	} else {
+		uploads, err = h.photoService.UploadBatchPhotos(ctx, uuid, files)
+	}
+	if errors.Is(err, serviceErr.UploadQueueFullError) {
+		h.uploadQueueFull(c, err)
+		return
+	}
+	if errors.Is(err, serviceErr.AlbumNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.AlbumNotFound, err, "Album not found.")
+		return
+	}
 	if errors.Is(err, serviceErr.AllFailedError) {
 		respStatus = http.StatusBadRequest
 	} else if errors.Is(err, serviceErr.ParticalSuccessError) {
@@ -140,7 +216,7 @@ func (h *handler) uploadBatchPhotos(c *gin.Context) {
 // @Failure 500 {object} response.Error "Unexpected error occurred."
 // @Router /api/v1/photos/{id}/versions [get]
 func (h *handler) getPhotoVersions(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c, config.DefaultContextTimeout)
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
 	defer cancel()
 
 	uuid, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
@@ -174,14 +250,77 @@ func (h *handler) getPhotoVersions(c *gin.Context) {
 	})
 }
 
+// @Summary Download photo file
+// @Description Download a file of a photo version. Clients sending Accept: image/webp get a WebP variant
+// @Description when it is smaller than the stored file.
+// @Tags photos
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce image/webp
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Param version query string false "Version of photo" default(original)
+// @Success 200 {file} string "Photo file"
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/file [get]
+func (h *handler) getPhotoFile(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	uuid, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	// Формат ответа зависит от Accept, кэши должны хранить ответы для разных Accept отдельно
+	c.Header("Vary", "Accept")
+
+	version := c.DefaultQuery(versionQueryParam, versionQueryParamDefault)
+	file, err := h.photoService.GetPhotoFile(ctx, uuid, photoID, version, model.FileOptions{
+		AcceptWebP: request.AcceptsWebP(c.GetHeader("Accept")),
+	})
+	if errors.Is(err, serviceErr.InvalidVersionTypeError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid version type.")
+		return
+	}
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found.")
+		return
+	}
+	if errors.Is(err, serviceErr.AccessDeniedError) {
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
 // @Summary Publish photo
-// @Description Make a photo public
+// @Description Make a photo public. With watermark=true the public files are served with the owner's watermark,
+// @Description the owner still downloads clean files. Metadata policy defines which metadata is removed
+// @Description from public files, by default the policy from the user's publish settings is used
 // @Tags photos
 // @Produce json
 // @Security JWTAuth
 // @Param id path int true "Photo ID"
+// @Param watermark query bool false "Serve public files with the watermark" default(false)
+// @Param metadata query string false "Metadata policy" Enums(keep, strip_private, strip_all)
 // @Success 200 {object} photo.PublishPhotoResponse
-// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 400 {object} response.Error "Bad Request or watermark is not configured."
 // @Failure 401 {object} response.Error "Unauthorized."
 // @Failure 403 {object} response.Error "Access denied."
 // @Failure 404 {object} response.Error "Photo not found."
@@ -189,7 +328,7 @@ func (h *handler) getPhotoVersions(c *gin.Context) {
 // @Failure 500 {object} response.Error "Unexpected error occurred."
 // @Router /api/v1/photos/{id}/publicate [post]
 func (h *handler) publishPhoto(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c, config.DefaultContextTimeout)
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
 	defer cancel()
 
 	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
@@ -205,11 +344,34 @@ func (h *handler) publishPhoto(c *gin.Context) {
 		return
 	}
 
-	publicToken, err := h.photoService.PublishPhoto(ctx, userUUID, photoID)
+	watermark, err := queryBool(c, watermarkQueryParam)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+
+	var metadata model.MetadataPolicy
+	if value, ok := c.GetQuery(metadataQueryParam); ok {
+		metadata, err = model.ParseMetadataPolicy(value)
+		if err != nil {
+			response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err,
+				fmt.Sprintf("Invalid %s, expected keep, strip_private or strip_all.", metadataQueryParam))
+			return
+		}
+	}
+
+	publicToken, err := h.photoService.PublishPhoto(ctx, userUUID, photoID, model.PublishOptions{
+		Watermark: watermark != nil && *watermark,
+		Metadata:  metadata,
+	})
 	if errors.Is(err, serviceErr.PhotoNotFoundError) {
 		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found.")
 		return
 	}
+	if errors.Is(err, serviceErr.WatermarkNotFoundError) {
+		response.NewErr(c, http.StatusBadRequest, response.WatermarkNotFound, err, "Watermark is not configured.")
+		return
+	}
 	if errors.Is(err, serviceErr.AlreadyExists) {
 		response.New(c, http.StatusNoContent, "Photo already published.")
 		return
@@ -237,7 +399,7 @@ func (h *handler) publishPhoto(c *gin.Context) {
 // @Failure 500 {object} response.Error "Unexpected error occurred."
 // @Router /api/v1/photos/{id}/unpublicate [delete]
 func (h *handler) unpublicatePhoto(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c, config.DefaultContextTimeout)
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
 	defer cancel()
 
 	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
@@ -264,3 +426,59 @@ func (h *handler) unpublicatePhoto(c *gin.Context) {
 
 	response.NewOk(c, nil)
 }
+
+// @Summary Delete photo
+// @Description Move a photo to trash. Trashed photo is hidden and its public link stops working until restored
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {object} nil
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id} [delete]
+func (h *handler) deletePhoto(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	idParam := c.Param("id")
+	photoID, err := strconv.Atoi(idParam)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	err = h.photoService.DeletePhoto(ctx, userUUID, photoID)
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found.")
+		return
+	}
+	if errors.Is(err, serviceErr.AccessDeniedError) {
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, nil)
+}
+
+func (h *handler) fileTooLarge(file *multipart.FileHeader) bool {
+	return h.opts.MaxFileSize > 0 && file.Size > h.opts.MaxFileSize
+}
+
+// uploadQueueFull отвечает 503 с Retry-After, чтобы клиент повторил загрузку позже.
+func (h *handler) uploadQueueFull(c *gin.Context, err error) {
+	c.Header("Retry-After", strconv.Itoa(int(h.opts.RetryAfter.Round(time.Second).Seconds())))
+	response.NewErr(c, http.StatusServiceUnavailable, response.ServiceBusy, err, "Too many uploads in progress, try again later.")
+}
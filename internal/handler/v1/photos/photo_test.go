@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"mime/multipart"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -127,7 +128,7 @@ func TestHandler_uploadPhoto(t *testing.T) {
 
 			mockTokenService := mockservice.NewMockTokenService(ctrl)
 
-			h := NewHandler(mockPhotoService, mockTokenService)
+			h := NewHandler(mockPhotoService, mockTokenService, Options{})
 
 			r := gin.New()
 			gin.DefaultWriter = ioutil.Discard
@@ -170,10 +171,12 @@ func TestHandler_uploadBatchPhotos(t *testing.T) {
 	tests := []struct {
 		name               string
 		userUUID           string
+		query              string
 		multipartBody      func() (*bytes.Buffer, string)
 		mockBehavior       mockBehavior
 		expectedStatusCode int
 		expectedResponse   any
+		expectedRetryAfter string
 	}{
 		{
 			name:     "Valid",
@@ -249,6 +252,93 @@ func TestHandler_uploadBatchPhotos(t *testing.T) {
 				UploadInfos:  serviceModel.ToUploadsInfoFromService(createFailedUploads().Get()),
 			},
 		},
+		{
+			name:     "Upload queue is full",
+			userUUID: "123e4567-e89b-12d3-a456-426614174000",
+			multipartBody: func() (*bytes.Buffer, string) {
+				return createMultipartBody(2, "tt%d.jpg", "fake image data")
+			},
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string, files []*multipart.FileHeader) {
+				s.EXPECT().
+					UploadBatchPhotos(gomock.Any(), userUUID, gomock.Any()).
+					Return(nil, serviceErr.UploadQueueFullError).
+					Times(1)
+			},
+			expectedStatusCode: 503,
+			expectedResponse: response.Error{
+				Error: response.ServiceBusy,
+			},
+			expectedRetryAfter: "5",
+		},
+		{
+			name:     "Upload to album",
+			userUUID: "123e4567-e89b-12d3-a456-426614174000",
+			query:    "?album_id=7",
+			multipartBody: func() (*bytes.Buffer, string) {
+				return createMultipartBody(3, "tt%d.jpg", "fake image data")
+			},
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string, files []*multipart.FileHeader) {
+				s.EXPECT().
+					UploadAlbumPhotos(gomock.Any(), userUUID, 7, gomock.Any()).
+					Return(&defaultUploads, nil).
+					Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponse: photo.UploadBatchPhotosResponse{
+				TotalCount:   3,
+				SuccessCount: 3,
+				UploadInfos:  serviceModel.ToUploadsInfoFromService(defaultUploads.Get()),
+			},
+		},
+		{
+			name:     "Album viewer",
+			userUUID: "123e4567-e89b-12d3-a456-426614174000",
+			query:    "?album_id=7",
+			multipartBody: func() (*bytes.Buffer, string) {
+				return createMultipartBody(1, "tt%d.jpg", "fake image data")
+			},
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string, files []*multipart.FileHeader) {
+				s.EXPECT().
+					UploadAlbumPhotos(gomock.Any(), userUUID, 7, gomock.Any()).
+					Return(nil, serviceErr.AccessDeniedError).
+					Times(1)
+			},
+			expectedStatusCode: 403,
+			expectedResponse: response.Error{
+				Error: response.Forbidden,
+			},
+		},
+		{
+			name:     "Album not found",
+			userUUID: "123e4567-e89b-12d3-a456-426614174000",
+			query:    "?album_id=7",
+			multipartBody: func() (*bytes.Buffer, string) {
+				return createMultipartBody(1, "tt%d.jpg", "fake image data")
+			},
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string, files []*multipart.FileHeader) {
+				s.EXPECT().
+					UploadAlbumPhotos(gomock.Any(), userUUID, 7, gomock.Any()).
+					Return(nil, serviceErr.AlbumNotFoundError).
+					Times(1)
+			},
+			expectedStatusCode: 404,
+			expectedResponse: response.Error{
+				Error: response.AlbumNotFound,
+			},
+		},
+		{
+			name:     "Invalid album id",
+			userUUID: "123e4567-e89b-12d3-a456-426614174000",
+			query:    "?album_id=seven",
+			multipartBody: func() (*bytes.Buffer, string) {
+				return createMultipartBody(1, "tt%d.jpg", "fake image data")
+			},
+			mockBehavior:       func(s *mockservice.MockPhotoService, userUUID string, files []*multipart.FileHeader) {},
+			expectedStatusCode: 400,
+			expectedResponse: response.Error{
+				Error: response.InvalidReqestsQueryParams,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -261,7 +351,7 @@ func TestHandler_uploadBatchPhotos(t *testing.T) {
 
 			mockTokenService := mockservice.NewMockTokenService(ctrl)
 
-			h := NewHandler(mockPhotoService, mockTokenService)
+			h := NewHandler(mockPhotoService, mockTokenService, Options{})
 
 			r := gin.New()
 			r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
@@ -274,7 +364,7 @@ func TestHandler_uploadBatchPhotos(t *testing.T) {
 
 			w := httptest.NewRecorder()
 			body, contentType := tt.multipartBody()
-			req := httptest.NewRequest("POST", "/uploadBatch", body)
+			req := httptest.NewRequest("POST", "/uploadBatch"+tt.query, body)
 			req.Header.Set("Content-Type", contentType)
 			req.Header.Set("Authorization", "Bearer valid-token")
 
@@ -284,6 +374,7 @@ func TestHandler_uploadBatchPhotos(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedRetryAfter, w.Header().Get("Retry-After"))
 
 			switch tt.expectedResponse.(type) {
 			case response.Error:
@@ -407,7 +498,7 @@ func TestHandler_getPhotoVersions(t *testing.T) {
 
 			mockTokenService := mockservice.NewMockTokenService(ctrl)
 
-			h := NewHandler(mockPhotoService, mockTokenService)
+			h := NewHandler(mockPhotoService, mockTokenService, Options{})
 
 			r := gin.New()
 			r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
@@ -429,6 +520,297 @@ func TestHandler_getPhotoVersions(t *testing.T) {
 	}
 }
 
+func TestHandler_getPhotoFile(t *testing.T) {
+	const userUUID = "1abc4"
+
+	type mockBehavior func(s *mockservice.MockPhotoService)
+
+	tests := []struct {
+		name                string
+		query               string
+		accept              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name: "Valid default version",
+			mockBehavior: func(s *mockservice.MockPhotoService) {
+				s.EXPECT().
+					GetPhotoFile(gomock.Any(), userUUID, 123, "original", serviceModel.FileOptions{}).
+					Return(&serviceModel.PhotoFile{Data: []byte("jpeg-data"), ContentType: "image/jpeg"}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "image/jpeg",
+			expectedBody:        "jpeg-data",
+		},
+		{
+			name:   "WebP accepted",
+			query:  "?version=preview",
+			accept: "image/webp,*/*",
+			mockBehavior: func(s *mockservice.MockPhotoService) {
+				s.EXPECT().
+					GetPhotoFile(gomock.Any(), userUUID, 123, "preview", serviceModel.FileOptions{AcceptWebP: true}).
+					Return(&serviceModel.PhotoFile{Data: []byte("webp-data"), ContentType: "image/webp"}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "image/webp",
+			expectedBody:        "webp-data",
+		},
+		{
+			name:  "Invalid version",
+			query: "?version=huge",
+			mockBehavior: func(s *mockservice.MockPhotoService) {
+				s.EXPECT().
+					GetPhotoFile(gomock.Any(), userUUID, 123, "huge", gomock.Any()).
+					Return(nil, serviceErr.InvalidVersionTypeError)
+			},
+			expectedStatusCode:  400,
+			expectedContentType: "application/json",
+		},
+		{
+			name: "Photo not found",
+			mockBehavior: func(s *mockservice.MockPhotoService) {
+				s.EXPECT().
+					GetPhotoFile(gomock.Any(), userUUID, 123, "original", gomock.Any()).
+					Return(nil, serviceErr.PhotoNotFoundError)
+			},
+			expectedStatusCode:  404,
+			expectedContentType: "application/json",
+		},
+		{
+			name: "Access denied",
+			mockBehavior: func(s *mockservice.MockPhotoService) {
+				s.EXPECT().
+					GetPhotoFile(gomock.Any(), userUUID, 123, "original", gomock.Any()).
+					Return(nil, serviceErr.AccessDeniedError)
+			},
+			expectedStatusCode:  403,
+			expectedContentType: "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.GET("/photos/:id/file", h.getPhotoFile)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/photos/123/file"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Accept", tt.accept)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			assert.Contains(t, w.Header().Get("Content-Type"), tt.expectedContentType)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_deletePhoto(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string, photoID int)
+
+	tests := []struct {
+		name               string
+		userUUID           string
+		photoID            string
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+		expectedResponse   any
+	}{
+		{
+			name:     "Valid",
+			userUUID: "1abc4",
+			photoID:  "123",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string, photoID int) {
+				s.EXPECT().DeletePhoto(gomock.Any(), userUUID, photoID).Return(nil).Times(1)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Invalid photo id",
+			userUUID:           "1abc4",
+			photoID:            "abc",
+			mockBehavior:       func(s *mockservice.MockPhotoService, userUUID string, photoID int) {},
+			expectedStatusCode: 400,
+			expectedResponse: response.Error{
+				Error:   response.InvalidRequestParams,
+				Message: "Invalid photo id.",
+			},
+		},
+		{
+			name:     "Photo not found",
+			userUUID: "1abc4",
+			photoID:  "123",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string, photoID int) {
+				s.EXPECT().DeletePhoto(gomock.Any(), userUUID, photoID).Return(serviceErr.PhotoNotFoundError).Times(1)
+			},
+			expectedStatusCode: 404,
+			expectedResponse: response.Error{
+				Error:   response.PhotoNotFound,
+				Message: "Photo not found.",
+			},
+		},
+		{
+			name:     "Access denied",
+			userUUID: "1abc4",
+			photoID:  "123",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string, photoID int) {
+				s.EXPECT().DeletePhoto(gomock.Any(), userUUID, photoID).Return(serviceErr.AccessDeniedError).Times(1)
+			},
+			expectedStatusCode: 403,
+			expectedResponse: response.Error{
+				Error:   response.Forbidden,
+				Message: "You do not have access to this photo.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			photoID, _ := strconv.Atoi(tt.photoID)
+			tt.mockBehavior(mockPhotoService, tt.userUUID, photoID)
+
+			mockTokenService := mockservice.NewMockTokenService(ctrl)
+
+			h := NewHandler(mockPhotoService, mockTokenService, Options{})
+
+			r := gin.New()
+			r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
+				if token == "valid-token" {
+					return serviceUserModel.TokenPayload{UserUUID: tt.userUUID}, nil
+				}
+				return serviceUserModel.TokenPayload{}, errors.New("invalid token")
+			}))
+			r.DELETE("/photos/:id", h.deletePhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/photos/"+tt.photoID, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedResponse != nil {
+				expectedBody, _ := json.Marshal(tt.expectedResponse)
+				assert.JSONEq(t, string(expectedBody), w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_publishPhoto(t *testing.T) {
+	const userUUID = "1abc4"
+
+	type mockBehavior func(s *mockservice.MockPhotoService)
+
+	tests := []struct {
+		name               string
+		query              string
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+		expectedResponse   any
+	}{
+		{
+			name:  "Default options",
+			query: "",
+			mockBehavior: func(s *mockservice.MockPhotoService) {
+				s.EXPECT().PublishPhoto(gomock.Any(), userUUID, 123, serviceModel.PublishOptions{}).Return("token", nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponse:   photo.PublishPhotoResponse{PublicToken: "token"},
+		},
+		{
+			name:  "Watermark and metadata policy",
+			query: "?watermark=true&metadata=strip_all",
+			mockBehavior: func(s *mockservice.MockPhotoService) {
+				s.EXPECT().PublishPhoto(gomock.Any(), userUUID, 123, serviceModel.PublishOptions{
+					Watermark: true,
+					Metadata:  serviceModel.MetadataStripAll,
+				}).Return("token", nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponse:   photo.PublishPhotoResponse{PublicToken: "token"},
+		},
+		{
+			name:               "Invalid metadata policy",
+			query:              "?metadata=none",
+			mockBehavior:       func(s *mockservice.MockPhotoService) {},
+			expectedStatusCode: 400,
+			expectedResponse: response.Error{
+				Error:   response.InvalidReqestsQueryParams,
+				Message: "Invalid metadata, expected keep, strip_private or strip_all.",
+			},
+		},
+		{
+			name:               "Invalid watermark",
+			query:              "?watermark=yes",
+			mockBehavior:       func(s *mockservice.MockPhotoService) {},
+			expectedStatusCode: 400,
+			expectedResponse: response.Error{
+				Error:   response.InvalidReqestsQueryParams,
+				Message: "Invalid watermark, expected true or false.",
+			},
+		},
+		{
+			name:  "Watermark is not configured",
+			query: "?watermark=true",
+			mockBehavior: func(s *mockservice.MockPhotoService) {
+				s.EXPECT().PublishPhoto(gomock.Any(), userUUID, 123, serviceModel.PublishOptions{Watermark: true}).
+					Return("", serviceErr.WatermarkNotFoundError).Times(1)
+			},
+			expectedStatusCode: 400,
+			expectedResponse: response.Error{
+				Error:   response.WatermarkNotFound,
+				Message: "Watermark is not configured.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.POST("/photos/:id/publicate", h.publishPhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/photos/123/publicate"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			expectedBody, _ := json.Marshal(tt.expectedResponse)
+			assert.JSONEq(t, string(expectedBody), w.Body.String())
+		})
+	}
+}
+
 // Вспомогательные функции
 
 func createMultipartBody(count int, filenamePattern, content string) (*bytes.Buffer, string) {
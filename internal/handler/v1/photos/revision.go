@@ -0,0 +1,189 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	"go-photo/internal/utils"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Upload photo revision
+// @Description Upload a replacement or an externally edited file as the new current revision of the photo original.
+// @Description Previous revisions are kept in history, derived versions are regenerated from the new revision
+// @Tags photos
+// @Accept multipart/form-data
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Param photo_file formData file true "Photo file"
+// @Success 200 {object} photo.PhotoRevision
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found."
+// @Failure 413 {object} response.Error "File is too large."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/versions [post]
+func (h *handler) uploadPhotoRevision(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	fileHeader, err := c.FormFile(FormPhotoFile)
+	if middleware.IsBodyTooLarge(err) {
+		response.NewErr(c, http.StatusRequestEntityTooLarge, response.RequestTooLarge, err, "Request body is too large.")
+		return
+	}
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.ParamsMissing, err, fmt.Sprintf("No %s in form.", FormPhotoFile))
+		return
+	}
+
+	if h.fileTooLarge(fileHeader) {
+		response.NewErr(c, http.StatusRequestEntityTooLarge, response.RequestTooLarge, nil,
+			fmt.Sprintf("File %s exceeds %d bytes.", fileHeader.Filename, h.opts.MaxFileSize))
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	if !utils.IsPhoto(ext) {
+		response.NewErr(c, http.StatusBadRequest, response.UnsupportedFileType, nil, "Unsupported file type: "+ext)
+		return
+	}
+
+	revision, err := h.photoService.UploadPhotoRevision(ctx, userUUID, photoID, fileHeader)
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found.")
+		return
+	}
+	if errors.Is(err, serviceErr.AccessDeniedError) {
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.ToPhotoRevisionFromModel(*revision))
+}
+
+// @Summary Get photo revisions
+// @Description Get the revision history of the photo original, newest first
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {object} photo.GetPhotoRevisionsResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/revisions [get]
+func (h *handler) getPhotoRevisions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	revisions, err := h.photoService.GetPhotoRevisions(ctx, userUUID, photoID)
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found.")
+		return
+	}
+	if errors.Is(err, serviceErr.AccessDeniedError) {
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.GetPhotoRevisionsResponse{
+		Revisions: photoResp.ToPhotoRevisionsFromModel(revisions),
+	})
+}
+
+// @Summary Restore photo revision
+// @Description Make an older revision of the photo original current again and regenerate derived versions from it
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} photo.PhotoRevision
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo or revision not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/revisions/{revision}/restore [post]
+func (h *handler) restorePhotoRevision(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	revisionNumber, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revisionNumber < 1 {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid revision number.")
+		return
+	}
+
+	revision, err := h.photoService.RestorePhotoRevision(ctx, userUUID, photoID, revisionNumber)
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo or revision not found.")
+		return
+	}
+	if errors.Is(err, serviceErr.AccessDeniedError) {
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.ToPhotoRevisionFromModel(*revision))
+}
@@ -0,0 +1,180 @@
+package photos
+
+import (
+	"bytes"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_uploadPhotoRevision(t *testing.T) {
+	savedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	revision := &model.PhotoVersion{
+		ID: 5, PhotoID: 123, VersionType: model.Original, UUIDFilename: "rev2.jpg", Size: 100, Height: 20, Width: 10, SavedAt: savedAt, Revision: 2,
+	}
+
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		filename             string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "Valid",
+			filename: "new.jpg",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().UploadPhotoRevision(gomock.Any(), userUUID, 123, gomock.Any()).Return(revision, nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"revision":2,"current":true,"uuid_filename":"rev2.jpg","size":100,"height":20,"width":10,"saved_at":"2024-01-01 00:00:00"}`,
+		},
+		{
+			name:                 "Unsupported file type",
+			filename:             "new.txt",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"unsupported_file_type","message":"Unsupported file type: .txt"}`,
+		},
+		{
+			name:     "Photo not found",
+			filename: "new.jpg",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().UploadPhotoRevision(gomock.Any(), userUUID, 123, gomock.Any()).Return(nil, serviceErr.PhotoNotFoundError).Times(1)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"error":"photo_not_found","message":"Photo not found."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.POST("/photos/:id/versions", h.uploadPhotoRevision)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			fileWriter, _ := writer.CreateFormFile(FormPhotoFile, tt.filename)
+			fileWriter.Write([]byte("test content"))
+			writer.Close()
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/photos/123/versions", body)
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getPhotoRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const userUUID = "1abc4"
+	savedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+	mockPhotoService.EXPECT().GetPhotoRevisions(gomock.Any(), userUUID, 123).Return([]model.PhotoVersion{
+		{ID: 5, PhotoID: 123, VersionType: model.Original, UUIDFilename: "rev2.jpg", Size: 100, Height: 20, Width: 10, SavedAt: savedAt, Revision: 2},
+		{ID: 1, PhotoID: 123, VersionType: model.Revision, UUIDFilename: "rev1.jpg", Size: 50, Height: 10, Width: 5, SavedAt: savedAt, Revision: 1},
+	}, nil).Times(1)
+
+	h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+	r := newAuthRouter(userUUID)
+	r.GET("/photos/:id/revisions", h.getPhotoRevisions)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/photos/123/revisions", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"revisions":[`+
+		`{"revision":2,"current":true,"uuid_filename":"rev2.jpg","size":100,"height":20,"width":10,"saved_at":"2024-01-01 00:00:00"},`+
+		`{"revision":1,"current":false,"uuid_filename":"rev1.jpg","size":50,"height":10,"width":5,"saved_at":"2024-01-01 00:00:00"}]}`,
+		w.Body.String())
+}
+
+func TestHandler_restorePhotoRevision(t *testing.T) {
+	revision := &model.PhotoVersion{ID: 1, PhotoID: 123, VersionType: model.Original, UUIDFilename: "rev1.jpg", Revision: 1}
+
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name               string
+		revision           string
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:     "Restored",
+			revision: "1",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().RestorePhotoRevision(gomock.Any(), userUUID, 123, 1).Return(revision, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Invalid revision",
+			revision:           "0",
+			mockBehavior:       func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode: 400,
+		},
+		{
+			name:     "Revision not found",
+			revision: "7",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().RestorePhotoRevision(gomock.Any(), userUUID, 123, 7).Return(nil, serviceErr.PhotoNotFoundError).Times(1)
+			},
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.POST("/photos/:id/revisions/:revision/restore", h.restorePhotoRevision)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/photos/123/revisions/"+tt.revision+"/restore", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
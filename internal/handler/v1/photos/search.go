@@ -0,0 +1,77 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Search photos
+// @Description Full-text search over title, caption, titles of albums the photo was added to, filename and camera make and model, most relevant first.
+// @Description Every word of the query must match the beginning of a word of the photo. Matches in the title weigh more than in the caption, album titles, filename and camera.
+// @Description Accepts the same filters as the photo list. Hidden photos are excluded unless hidden=true or hidden=any
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param q query string true "Search query, up to 10 words"
+// @Param favorite query bool false "Only favorite (true) or not favorite (false) photos"
+// @Param hidden query string false "true, false (default) or any"
+// @Param min_rating query int false "Minimum rating, 0-5"
+// @Param max_rating query int false "Maximum rating, 0-5"
+// @Param taken_from query string false "First day of capture, YYYY-MM-DD. Photos without capture time in EXIF are matched by upload day"
+// @Param taken_to query string false "Last day of capture, YYYY-MM-DD"
+// @Param tz query string false "IANA time zone of capture days for photos without capture time in EXIF, UTC by default"
+// @Param camera query string false "Part of camera make or model, case-insensitive"
+// @Param tag query string false "Photo tag, case-insensitive"
+// @Param published query bool false "Only published (true) or not published (false) photos"
+// @Param color query string false "Only photos with a dominant color close to this one, #rrggbb"
+// @Param tolerance query int false "Allowed color difference (CIE76), 1-50, 10 by default"
+// @Param limit query int false "Page size, 50 by default"
+// @Param offset query int false "Number of photos to skip"
+// @Success 200 {object} photo.SearchPhotosResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/search [get]
+func (h *handler) searchPhotos(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, nil, "Missing q.")
+		return
+	}
+	filter, err := parsePhotoFilter(c)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+
+	photos, err := h.photoService.SearchPhotos(ctx, userUUID, query, filter)
+	if errors.Is(err, serviceErr.InvalidFilterError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid filter.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.SearchPhotosResponse{
+		Photos: photoResp.ToFoundPhotosFromModel(photos),
+	})
+}
@@ -0,0 +1,90 @@
+package photos
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_searchPhotos(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+	visible := false
+	favorite := true
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Found",
+			query: "?q=%D0%BC%D0%BE%D1%80%D0%B5+img&favorite=true&limit=10",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().SearchPhotos(gomock.Any(), userUUID, "море img", serviceModel.PhotoFilter{Favorite: &favorite, Hidden: &visible, Limit: 10}).
+					Return([]model.FoundPhoto{{
+						Photo:     model.Photo{ID: 1, Filename: "IMG_1.jpg", Title: "Море"},
+						Rank:      0.6,
+						Highlight: "<b>Море</b> <b>IMG</b>_1.jpg",
+					}}, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"photos":[{"photo_id":1,"filename":"IMG_1.jpg","title":"Море","caption":"","favorite":false,"rating":0,"hidden":false,` +
+				`"uploaded_at":"0001-01-01 00:00:00","updated_at":"0001-01-01 00:00:00","rank":0.6,"highlight":"<b>Море</b> <b>IMG</b>_1.jpg"}]}`,
+		},
+		{
+			name:                 "No query",
+			query:                "?q=%20",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Missing q."}`,
+		},
+		{
+			name:                 "Invalid list filter",
+			query:                "?q=canon&min_rating=high",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid min_rating, expected integer."}`,
+		},
+		{
+			name:  "Invalid filter",
+			query: "?q=%21%21%21",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().SearchPhotos(gomock.Any(), userUUID, "!!!", gomock.Any()).Return(nil, serviceErr.InvalidFilterError).Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid filter."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.GET("/photos/search", h.searchPhotos)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/photos/search"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
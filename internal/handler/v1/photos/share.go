@@ -0,0 +1,169 @@
+package photos
+
+import (
+	"context"
+	"errors"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/request"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get photo shares
+// @Description Get users the photo is shared with, in the order access was granted
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {object} photo.GetSharesResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/shares [get]
+func (h *handler) getPhotoShares(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, photoID, ok := photoRequest(c)
+	if !ok {
+		return
+	}
+
+	shares, err := h.photoService.GetPhotoShares(ctx, userUUID, photoID)
+	if handleShareError(c, err) {
+		return
+	}
+
+	res := photoResp.GetSharesResponse{Shares: make([]photoResp.Share, len(shares))}
+	for i := range shares {
+		res.Shares[i] = toShareResponse(&shares[i])
+	}
+
+	response.NewOk(c, res)
+}
+
+// @Summary Share photo
+// @Description Let another user view the photo and its files, or change the role of an existing share.
+// @Description Only the viewer role can be granted on a photo
+// @Tags photos
+// @Accept json
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Param userUUID path string true "UUID of the user to share with"
+// @Param input body request.Share true "Share role"
+// @Success 200 {object} photo.Share
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/shares/{userUUID} [put]
+func (h *handler) sharePhoto(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, photoID, ok := photoRequest(c)
+	if !ok {
+		return
+	}
+
+	var input request.Share
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid request body format.")
+		return
+	}
+
+	share, err := h.photoService.SharePhoto(ctx, userUUID, photoID, c.Param("userUUID"), input.Role)
+	if handleShareError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toShareResponse(share))
+}
+
+// @Summary Unshare photo
+// @Description Revoke another user's access to the photo
+// @Tags photos
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Param userUUID path string true "UUID of the user to revoke access from"
+// @Success 200 {object} nil
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found or not shared with the user."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/photos/{id}/shares/{userUUID} [delete]
+func (h *handler) unsharePhoto(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, photoID, ok := photoRequest(c)
+	if !ok {
+		return
+	}
+
+	err := h.photoService.UnsharePhoto(ctx, userUUID, photoID, c.Param("userUUID"))
+	if handleShareError(c, err) {
+		return
+	}
+
+	response.NewOk(c, nil)
+}
+
+// photoRequest возвращает пользователя и ID фото из пути. Если их нет, отвечает клиенту ошибкой.
+func photoRequest(c *gin.Context) (string, int, bool) {
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return "", 0, false
+	}
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return "", 0, false
+	}
+
+	return userUUID, photoID, true
+}
+
+// handleShareError отвечает клиенту ошибкой сервиса доступа к фото. Возвращает false, если ошибки нет.
+func handleShareError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, serviceErr.InvalidShareError):
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, err.Error())
+	case errors.Is(err, serviceErr.PhotoNotFoundError):
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found.")
+	case errors.Is(err, serviceErr.ShareNotFoundError):
+		response.NewErr(c, http.StatusNotFound, response.ShareNotFound, err, "Photo is not shared with the user.")
+	case errors.Is(err, serviceErr.AccessDeniedError):
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+	default:
+		return response.HandleError(c, err)
+	}
+	return true
+}
+
+func toShareResponse(s *serviceModel.Share) photoResp.Share {
+	return photoResp.Share{
+		UserUUID:  s.UserUUID,
+		Role:      string(s.Role),
+		CreatedAt: s.CreatedAt.Format(time.DateTime),
+	}
+}
@@ -0,0 +1,132 @@
+package photos
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_sharePhoto(t *testing.T) {
+	const granteeUUID = "6f1d2c3e-0000-4000-8000-000000000002"
+
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Shared",
+			body: `{"role": "viewer"}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().SharePhoto(gomock.Any(), userUUID, 123, granteeUUID, "viewer").Return(&serviceModel.Share{
+					UserUUID:  granteeUUID,
+					Role:      serviceModel.ShareViewer,
+					CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+				}, nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"user_uuid":"` + granteeUUID + `","role":"viewer","created_at":"2024-05-01 12:00:00"}`,
+		},
+		{
+			name: "Invalid role",
+			body: `{"role": "contributor"}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().SharePhoto(gomock.Any(), userUUID, 123, granteeUUID, "contributor").
+					Return(nil, serviceErr.InvalidShareError).Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"invalid share"}`,
+		},
+		{
+			name: "Photo of another user",
+			body: `{"role": "viewer"}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().SharePhoto(gomock.Any(), userUUID, 123, granteeUUID, "viewer").
+					Return(nil, serviceErr.AccessDeniedError).Times(1)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"error":"access_denied","message":"You do not have access to this photo."}`,
+		},
+		{
+			name:                 "No role",
+			body:                 `{}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Invalid request body format."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.PUT("/photos/:id/shares/:userUUID", h.sharePhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/photos/123/shares/"+granteeUUID, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_unsharePhoto(t *testing.T) {
+	const granteeUUID = "6f1d2c3e-0000-4000-8000-000000000002"
+
+	tests := []struct {
+		name               string
+		serviceErr         error
+		expectedStatusCode int
+	}{
+		{name: "Unshared", expectedStatusCode: 200},
+		{name: "Not shared", serviceErr: serviceErr.ShareNotFoundError, expectedStatusCode: 404},
+		{name: "Photo not found", serviceErr: serviceErr.PhotoNotFoundError, expectedStatusCode: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			mockPhotoService.EXPECT().UnsharePhoto(gomock.Any(), userUUID, 123, granteeUUID).Return(tt.serviceErr).Times(1)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newAuthRouter(userUUID)
+			r.DELETE("/photos/:id/shares/:userUUID", h.unsharePhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/photos/123/shares/"+granteeUUID, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
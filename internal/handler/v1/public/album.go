@@ -0,0 +1,125 @@
+package public
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go-photo/internal/handler/request"
+	"go-photo/internal/handler/response"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Get public album by token
+// @Description Get title and a page of photos of a published album. Photos are the owner's photos currently matching
+// @Description the album filter, hidden photos are never shown. Files are available at /a/{publicToken}/photos/{id}
+// @Tags public
+// @Produce json
+// @Param publicToken path string true "Public token of album"
+// @Param limit query int false "Page size, 50 by default"
+// @Param offset query int false "Number of photos to skip"
+// @Success 200 {object} photo.PublicAlbum
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 404 {object} response.Error "Album not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /a/{publicToken} [get]
+func (h *handler) getPublicAlbum(c *gin.Context) {
+	tokenParam := c.Param(publicPhotoParam)
+
+	limit, offset, err := queryPage(c)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+
+	album, err := h.photoService.GetPublicAlbum(c, tokenParam, limit, offset)
+	if errors.Is(err, serviceErr.AlbumNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.AlbumNotFound, err, "Album not found by token")
+		return
+	}
+	if errors.Is(err, serviceErr.InvalidFilterError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid filter.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.PublicAlbum{
+		Title:  album.Title,
+		Photos: photoResp.ToPublicPhotosFromModel(album.Photos),
+	})
+}
+
+// @Summary Get photo of public album
+// @Description Get file of a photo from a published album with metadata removed by the album's metadata policy.
+// @Description The photo is available only while it matches the album filter.
+// @Description Clients sending Accept: image/webp get a WebP variant when it is smaller than the stored file
+// @Tags public
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce image/webp
+// @Param publicToken path string true "Public token of album"
+// @Param id path int true "Photo ID"
+// @Param version query string false "Version of photo" default(original)
+// @Success 200 {file} string "image/jpeg"
+// @Failure 400 {object} response.Error "Version type is not valid."
+// @Failure 404 {object} response.Error "Album or photo not found."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /a/{publicToken}/photos/{id} [get]
+func (h *handler) getPublicAlbumPhoto(c *gin.Context) {
+	tokenParam := c.Param(publicPhotoParam)
+
+	// Формат ответа зависит от Accept, кэши должны хранить ответы для разных Accept отдельно
+	c.Header("Vary", "Accept")
+
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	versionQuery := c.DefaultQuery(versionQueryParam, versionQueryParamDefault)
+
+	file, err := h.photoService.GetPublicAlbumPhotoFile(c, tokenParam, photoID, versionQuery, serviceModel.FileOptions{
+		AcceptWebP: request.AcceptsWebP(c.GetHeader("Accept")),
+	})
+	if errors.Is(err, serviceErr.AlbumNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.AlbumNotFound, err, "Album not found by token")
+		return
+	}
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found in album")
+		return
+	}
+	if errors.Is(err, serviceErr.InvalidVersionTypeError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid version type")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// queryPage возвращает параметры limit и offset, 0 - не указан.
+func queryPage(c *gin.Context) (int, int, error) {
+	var page [2]int
+	for i, key := range []string{"limit", "offset"} {
+		v, ok := c.GetQuery(key)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid %s, expected integer.", key)
+		}
+		page[i] = n
+	}
+
+	return page[0], page[1], nil
+}
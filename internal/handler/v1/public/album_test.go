@@ -0,0 +1,150 @@
+package public
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	mock_service "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_getPublicAlbum(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockPhotoService)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Valid",
+			query: "?limit=10",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().GetPublicAlbum(gomock.Any(), "token", 10, 0).Return(&serviceModel.PublicAlbum{
+					Title: "Best",
+					Photos: []model.Photo{{
+						ID: 5, Filename: "IMG_0001.jpg", Title: "Sunset", Rating: 5,
+						Location: &model.Location{Latitude: 55.75, Longitude: 37.61},
+					}},
+				}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"title":"Best","photos":[{"photo_id":5,"title":"Sunset","caption":""}]}`,
+		},
+		{
+			name: "Not published",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().GetPublicAlbum(gomock.Any(), "token", 0, 0).Return(nil, serviceErr.AlbumNotFoundError)
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"error":"album_not_found","message":"Album not found by token"}`,
+		},
+		{
+			name:                 "Invalid offset",
+			query:                "?offset=last",
+			mockBehavior:         func(s *mock_service.MockPhotoService) {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid offset, expected integer."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPhotoService := mock_service.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService)
+
+			h := NewHandler(mockPhotoService)
+
+			r := gin.New()
+			gin.DefaultWriter = io.Discard
+			r.GET("/a/:publicToken", h.getPublicAlbum)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/a/token"+tt.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getPublicAlbumPhoto(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockPhotoService)
+
+	tests := []struct {
+		name                string
+		path                string
+		accept              string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedContentType string
+	}{
+		{
+			name:   "Valid",
+			path:   "/a/token/photos/5?version=preview",
+			accept: "image/webp",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().GetPublicAlbumPhotoFile(gomock.Any(), "token", 5, "preview", serviceModel.FileOptions{AcceptWebP: true}).
+					Return(&serviceModel.PhotoFile{Data: []byte("webp-data"), ContentType: "image/webp"}, nil)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "image/webp",
+		},
+		{
+			name: "Photo not in album",
+			path: "/a/token/photos/6",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().GetPublicAlbumPhotoFile(gomock.Any(), "token", 6, "original", serviceModel.FileOptions{}).
+					Return(nil, serviceErr.PhotoNotFoundError)
+			},
+			expectedStatusCode:  http.StatusNotFound,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "Invalid photo id",
+			path:                "/a/token/photos/six",
+			mockBehavior:        func(s *mock_service.MockPhotoService) {},
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPhotoService := mock_service.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService)
+
+			h := NewHandler(mockPhotoService)
+
+			r := gin.New()
+			gin.DefaultWriter = io.Discard
+			r.GET("/a/:publicToken/photos/:id", h.getPublicAlbumPhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Accept", tt.accept)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			assert.Contains(t, w.Header().Get("Content-Type"), tt.expectedContentType)
+		})
+	}
+}
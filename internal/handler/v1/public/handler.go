@@ -20,4 +20,10 @@ func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
 	{
 		publicGroup.GET("/:publicToken", h.getPublicPhoto)
 	}
+
+	albumGroup := router.Group("/a")
+	{
+		albumGroup.GET("/:publicToken", h.getPublicAlbum)
+		albumGroup.GET("/:publicToken/photos/:id", h.getPublicAlbumPhoto)
+	}
 }
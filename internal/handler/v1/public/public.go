@@ -2,10 +2,14 @@ package public
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"go-photo/internal/handler/request"
 	"go-photo/internal/handler/response"
 	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
 	"net/http"
+	"strconv"
 )
 
 const (
@@ -17,24 +21,52 @@ const (
 	versionQueryParamDefault = "original"
 )
 
+const (
+	widthQueryParam   = "w"
+	heightQueryParam  = "h"
+	fitQueryParam     = "fit"
+	qualityQueryParam = "q"
+	formatQueryParam  = "format"
+)
+
 // @Summary Get public photo by token
-// @Description Get public photo by token
+// @Description Get public photo by token. If w and h are set, the photo is resized on the fly
+// @Description to one of the allowed sizes; version is ignored in that case.
+// @Description If only q or format is set, the stored version is re-encoded at its own size with the allowed quality.
+// @Description Clients sending Accept: image/webp get a WebP variant when it is smaller than the stored file.
 // @Tags public
 // @Accept json
 // @Produce image/jpeg
+// @Produce image/png
+// @Produce image/webp
 // @Param publicToken path string true "Public token of photo"
 // @Param version query string false "Version of photo" default(original)
+// @Param w query int false "Width of resized photo, set together with h"
+// @Param h query int false "Height of resized photo, set together with w"
+// @Param fit query string false "Resize mode" Enums(contain, cover) default(contain)
+// @Param q query int false "JPEG or WebP quality, one of allowed"
+// @Param format query string false "Output format, defaults to WebP if accepted, otherwise format of photo" Enums(jpeg, png, webp)
 // @Success 200 {file} string "image/jpeg"
-// @Failure 400 {object} response.Error "Version type is not valid."
+// @Failure 400 {object} response.Error "Version type or resize params are not valid."
 // @Failure 404 {object} response.Error "Photo not found."
 // @Failure 500 {object} response.Error "Unexpected error occurred."
 // @Router /p/{publicToken} [get]
 func (h *handler) getPublicPhoto(c *gin.Context) {
 	tokenParam := c.Param(publicPhotoParam)
 
+	// Формат ответа зависит от Accept, кэши должны хранить ответы для разных Accept отдельно
+	c.Header("Vary", "Accept")
+
+	if hasResizeParams(c) {
+		h.getResizedPublicPhoto(c, tokenParam)
+		return
+	}
+
 	versionQuery := c.DefaultQuery(versionQueryParam, versionQueryParamDefault)
 
-	imgData, err := h.photoService.GetPhotoFileByVersionAndToken(c, tokenParam, versionQuery)
+	file, err := h.photoService.GetPhotoFileByVersionAndToken(c, tokenParam, versionQuery, serviceModel.FileOptions{
+		AcceptWebP: request.AcceptsWebP(c.GetHeader("Accept")),
+	})
 	if errors.Is(err, serviceErr.PhotoNotFoundError) {
 		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found by token and version")
 		return
@@ -47,5 +79,76 @@ func (h *handler) getPublicPhoto(c *gin.Context) {
 		return
 	}
 
-	c.Data(200, "image/jpeg", imgData)
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+func (h *handler) getResizedPublicPhoto(c *gin.Context, token string) {
+	opts, err := parseResizeOptions(c)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+
+	file, err := h.photoService.GetResizedPhotoFileByToken(c, token, opts)
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found by token")
+		return
+	}
+	if errors.Is(err, serviceErr.InvalidResizeParamsError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Resize params are not allowed")
+		return
+	}
+	if errors.Is(err, serviceErr.InvalidVersionTypeError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid version type")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+func hasResizeParams(c *gin.Context) bool {
+	for _, key := range []string{widthQueryParam, heightQueryParam, fitQueryParam, qualityQueryParam, formatQueryParam} {
+		if _, ok := c.GetQuery(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseResizeOptions разбирает параметры изменения размера. Допустимость значений проверяет сервис.
+func parseResizeOptions(c *gin.Context) (serviceModel.ResizeOptions, error) {
+	var opts serviceModel.ResizeOptions
+	var err error
+
+	// Без размера перекодируется сохраненная версия, размер задается только целиком
+	_, hasWidth := c.GetQuery(widthQueryParam)
+	_, hasHeight := c.GetQuery(heightQueryParam)
+	if hasWidth || hasHeight {
+		opts.Width, err = strconv.Atoi(c.Query(widthQueryParam))
+		if err != nil {
+			return opts, fmt.Errorf("Invalid %s, expected integer.", widthQueryParam)
+		}
+		opts.Height, err = strconv.Atoi(c.Query(heightQueryParam))
+		if err != nil {
+			return opts, fmt.Errorf("Invalid %s, expected integer.", heightQueryParam)
+		}
+	} else {
+		opts.Version = c.DefaultQuery(versionQueryParam, versionQueryParamDefault)
+	}
+
+	if q, ok := c.GetQuery(qualityQueryParam); ok {
+		opts.Quality, err = strconv.Atoi(q)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid %s, expected integer.", qualityQueryParam)
+		}
+	}
+
+	opts.Fit = serviceModel.FitMode(c.Query(fitQueryParam))
+	opts.Format = serviceModel.ImageFormat(c.Query(formatQueryParam))
+	opts.AcceptWebP = request.AcceptsWebP(c.GetHeader("Accept"))
+
+	return opts, nil
 }
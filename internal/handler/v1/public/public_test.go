@@ -9,6 +9,7 @@ import (
 	"go-photo/internal/handler/response"
 	serviceErr "go-photo/internal/service/error"
 	mock_service "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -18,10 +19,13 @@ import (
 func TestHandler_getPublicPhoto(t *testing.T) {
 	type mockBehavior func(s *mock_service.MockPhotoService, token string, versionQuery string)
 
+	jpegFile := &serviceModel.PhotoFile{Data: []byte("test-data"), ContentType: "image/jpeg"}
+
 	tests := []struct {
 		name                 string
 		token                string
 		versionQuery         string
+		accept               string
 		mockBehavior         mockBehavior
 		expectedStatusCode   int
 		expectedContentType  string
@@ -33,8 +37,8 @@ func TestHandler_getPublicPhoto(t *testing.T) {
 			versionQuery: "original",
 			mockBehavior: func(s *mock_service.MockPhotoService, token string, versionQuery string) {
 				s.EXPECT().
-					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery).
-					Return([]byte("test-data"), nil)
+					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery, serviceModel.FileOptions{}).
+					Return(jpegFile, nil)
 			},
 			expectedStatusCode:   http.StatusOK,
 			expectedContentType:  "image/jpeg",
@@ -46,8 +50,8 @@ func TestHandler_getPublicPhoto(t *testing.T) {
 			versionQuery: "",
 			mockBehavior: func(s *mock_service.MockPhotoService, token string, versionQuery string) {
 				s.EXPECT().
-					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery).
-					Return([]byte("test-data"), nil)
+					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery, serviceModel.FileOptions{}).
+					Return(jpegFile, nil)
 			},
 			expectedStatusCode:   http.StatusOK,
 			expectedContentType:  "image/jpeg",
@@ -59,8 +63,36 @@ func TestHandler_getPublicPhoto(t *testing.T) {
 			versionQuery: "",
 			mockBehavior: func(s *mock_service.MockPhotoService, token string, versionQuery string) {
 				s.EXPECT().
-					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery).
-					Return([]byte("test-data"), nil)
+					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery, serviceModel.FileOptions{}).
+					Return(jpegFile, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedContentType:  "image/jpeg",
+			expectedResponseBody: []byte("test-data"),
+		},
+		{
+			name:         "WebP accepted",
+			token:        "valid-token",
+			versionQuery: "original",
+			accept:       "image/avif,image/webp,image/*;q=0.8",
+			mockBehavior: func(s *mock_service.MockPhotoService, token string, versionQuery string) {
+				s.EXPECT().
+					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery, serviceModel.FileOptions{AcceptWebP: true}).
+					Return(&serviceModel.PhotoFile{Data: []byte("webp-data"), ContentType: "image/webp"}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedContentType:  "image/webp",
+			expectedResponseBody: []byte("webp-data"),
+		},
+		{
+			name:         "WebP refused",
+			token:        "valid-token",
+			versionQuery: "original",
+			accept:       "image/webp;q=0, */*",
+			mockBehavior: func(s *mock_service.MockPhotoService, token string, versionQuery string) {
+				s.EXPECT().
+					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery, serviceModel.FileOptions{}).
+					Return(jpegFile, nil)
 			},
 			expectedStatusCode:   http.StatusOK,
 			expectedContentType:  "image/jpeg",
@@ -72,7 +104,7 @@ func TestHandler_getPublicPhoto(t *testing.T) {
 			versionQuery: "invalid-version",
 			mockBehavior: func(s *mock_service.MockPhotoService, token string, versionQuery string) {
 				s.EXPECT().
-					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery).
+					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery, serviceModel.FileOptions{}).
 					Return(nil, serviceErr.InvalidVersionTypeError)
 			},
 			expectedStatusCode:  http.StatusBadRequest,
@@ -87,7 +119,7 @@ func TestHandler_getPublicPhoto(t *testing.T) {
 			versionQuery: "original",
 			mockBehavior: func(s *mock_service.MockPhotoService, token string, versionQuery string) {
 				s.EXPECT().
-					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery).
+					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery, serviceModel.FileOptions{}).
 					Return(nil, serviceErr.PhotoNotFoundError)
 			},
 			expectedStatusCode:  http.StatusNotFound,
@@ -102,7 +134,7 @@ func TestHandler_getPublicPhoto(t *testing.T) {
 			versionQuery: "original",
 			mockBehavior: func(s *mock_service.MockPhotoService, token string, versionQuery string) {
 				s.EXPECT().
-					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery).
+					GetPhotoFileByVersionAndToken(gomock.Any(), token, versionQuery, serviceModel.FileOptions{}).
 					Return(nil, serviceErr.UnexpectedError)
 			},
 			expectedStatusCode:  http.StatusInternalServerError,
@@ -130,10 +162,12 @@ func TestHandler_getPublicPhoto(t *testing.T) {
 			w := httptest.NewRecorder()
 			url := fmt.Sprintf("/p/%s?version=%s", tt.token, tt.versionQuery)
 			req := httptest.NewRequest("GET", url, nil)
+			req.Header.Set("Accept", tt.accept)
 
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
 			assert.Contains(t, w.Header().Get("Content-Type"), tt.expectedContentType)
 			switch tt.expectedResponseBody.(type) {
 			case []byte:
@@ -148,3 +182,166 @@ func TestHandler_getPublicPhoto(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_getResizedPublicPhoto(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockPhotoService)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedContentType  string
+		expectedResponseBody any
+	}{
+		{
+			name:  "Valid",
+			query: "w=320&h=320&fit=cover&q=60&format=png",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().
+					GetResizedPhotoFileByToken(gomock.Any(), "valid-token", serviceModel.ResizeOptions{
+						Width: 320, Height: 320, Fit: serviceModel.FitCover, Quality: 60, Format: serviceModel.FormatPNG,
+					}).
+					Return(&serviceModel.PhotoFile{Data: []byte("test-data"), ContentType: "image/png"}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedContentType:  "image/png",
+			expectedResponseBody: []byte("test-data"),
+		},
+		{
+			name:  "Valid defaults",
+			query: "w=320&h=320",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().
+					GetResizedPhotoFileByToken(gomock.Any(), "valid-token", serviceModel.ResizeOptions{Width: 320, Height: 320}).
+					Return(&serviceModel.PhotoFile{Data: []byte("test-data"), ContentType: "image/jpeg"}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedContentType:  "image/jpeg",
+			expectedResponseBody: []byte("test-data"),
+		},
+		{
+			name:  "Format only",
+			query: "format=webp",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().
+					GetResizedPhotoFileByToken(gomock.Any(), "valid-token", serviceModel.ResizeOptions{
+						Format: serviceModel.FormatWebP, Version: "original",
+					}).
+					Return(&serviceModel.PhotoFile{Data: []byte("test-data"), ContentType: "image/webp"}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedContentType:  "image/webp",
+			expectedResponseBody: []byte("test-data"),
+		},
+		{
+			name:  "Quality only",
+			query: "q=80&version=preview",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().
+					GetResizedPhotoFileByToken(gomock.Any(), "valid-token", serviceModel.ResizeOptions{
+						Quality: 80, Version: "preview",
+					}).
+					Return(&serviceModel.PhotoFile{Data: []byte("test-data"), ContentType: "image/jpeg"}, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedContentType:  "image/jpeg",
+			expectedResponseBody: []byte("test-data"),
+		},
+		{
+			name:  "Format with invalid version",
+			query: "format=png&version=huge",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().
+					GetResizedPhotoFileByToken(gomock.Any(), "valid-token", gomock.Any()).
+					Return(nil, serviceErr.InvalidVersionTypeError)
+			},
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedResponseBody: response.Error{
+				Error: response.InvalidReqestsQueryParams,
+			},
+		},
+		{
+			name:                "Missing height",
+			query:               "w=320",
+			mockBehavior:        func(s *mock_service.MockPhotoService) {},
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedResponseBody: response.Error{
+				Error: response.InvalidReqestsQueryParams,
+			},
+		},
+		{
+			name:                "Invalid quality",
+			query:               "w=320&h=320&q=high",
+			mockBehavior:        func(s *mock_service.MockPhotoService) {},
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedResponseBody: response.Error{
+				Error: response.InvalidReqestsQueryParams,
+			},
+		},
+		{
+			name:  "Size not allowed",
+			query: "w=321&h=320",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().
+					GetResizedPhotoFileByToken(gomock.Any(), "valid-token", gomock.Any()).
+					Return(nil, serviceErr.InvalidResizeParamsError)
+			},
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedResponseBody: response.Error{
+				Error: response.InvalidReqestsQueryParams,
+			},
+		},
+		{
+			name:  "Public photo not found",
+			query: "w=320&h=320",
+			mockBehavior: func(s *mock_service.MockPhotoService) {
+				s.EXPECT().
+					GetResizedPhotoFileByToken(gomock.Any(), "valid-token", gomock.Any()).
+					Return(nil, serviceErr.PhotoNotFoundError)
+			},
+			expectedStatusCode:  http.StatusNotFound,
+			expectedContentType: "application/json",
+			expectedResponseBody: response.Error{
+				Error: response.PhotoNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPhotoService := mock_service.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService)
+
+			h := NewHandler(mockPhotoService)
+
+			r := gin.New()
+			gin.DefaultWriter = io.Discard
+			r.GET("/p/:publicToken", h.getPublicPhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/p/valid-token?"+tt.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), tt.expectedContentType)
+			switch tt.expectedResponseBody.(type) {
+			case []byte:
+				assert.Equal(t, string(tt.expectedResponseBody.([]byte)), w.Body.String())
+			case response.Error:
+				var resp response.Error
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResponseBody.(response.Error).Error, resp.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package publishing
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-photo/internal/config"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/service"
+	"time"
+)
+
+// maxSettingsSize ограничение тела запроса на изменение настроек
+const maxSettingsSize = 1 << 10
+
+type Options struct {
+	RequestTimeout time.Duration
+}
+
+type handler struct {
+	photoService service.PhotoService
+	tokenService service.TokenService
+	opts         Options
+}
+
+func NewHandler(photoService service.PhotoService, tokenService service.TokenService, opts Options) *handler {
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = config.DefaultContextTimeout
+	}
+
+	return &handler{
+		photoService: photoService,
+		tokenService: tokenService,
+		opts:         opts,
+	}
+}
+
+func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
+	settingsGroup := router.Group("/publish-settings")
+
+	settingsGroup.Use(middleware.UserIdentity(h.tokenService.VerifyToken))
+
+	{
+		settingsGroup.GET("", h.getPublishSettings)
+		settingsGroup.PUT("", middleware.MaxBodySize(maxSettingsSize), h.savePublishSettings)
+	}
+}
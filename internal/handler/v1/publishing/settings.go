@@ -0,0 +1,97 @@
+package publishing
+
+import (
+	"context"
+	"errors"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/request"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get publish settings
+// @Description Get publish settings of the user. Users who never changed them get the defaults
+// @Tags publishing
+// @Produce json
+// @Security JWTAuth
+// @Success 200 {object} photo.PublishSettings
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/publish-settings [get]
+func (h *handler) getPublishSettings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	settings, err := h.photoService.GetPublishSettings(ctx, userUUID)
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toPublishSettingsResponse(settings))
+}
+
+// @Summary Save publish settings
+// @Description Save publish settings of the user. They apply to photos published afterwards,
+// @Description already published photos keep their metadata policy
+// @Tags publishing
+// @Accept json
+// @Produce json
+// @Security JWTAuth
+// @Param input body request.PublishSettings true "Publish settings"
+// @Success 200 {object} photo.PublishSettings
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/publish-settings [put]
+func (h *handler) savePublishSettings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	var input request.PublishSettings
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid request body format.")
+		return
+	}
+
+	settings, err := h.photoService.SavePublishSettings(ctx, userUUID, serviceModel.PublishSettings{
+		MetadataPolicy: serviceModel.MetadataPolicy(input.MetadataPolicy),
+	})
+	if errors.Is(err, serviceErr.InvalidMetadataPolicyError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err,
+			"Invalid metadata_policy, expected keep, strip_private or strip_all.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toPublishSettingsResponse(settings))
+}
+
+func toPublishSettingsResponse(s *serviceModel.PublishSettings) photoResp.PublishSettings {
+	resp := photoResp.PublishSettings{MetadataPolicy: string(s.MetadataPolicy)}
+	if !s.UpdatedAt.IsZero() {
+		resp.UpdatedAt = s.UpdatedAt.Format(time.DateTime)
+	}
+	return resp
+}
@@ -0,0 +1,147 @@
+package publishing
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/handler/middleware"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	serviceUserModel "go-photo/internal/service/user/model"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_getPublishSettings(t *testing.T) {
+	tests := []struct {
+		name                 string
+		settings             *serviceModel.PublishSettings
+		expectedResponseBody string
+	}{
+		{
+			name:                 "Defaults",
+			settings:             &serviceModel.PublishSettings{MetadataPolicy: serviceModel.MetadataStripPrivate},
+			expectedResponseBody: `{"metadata_policy":"strip_private"}`,
+		},
+		{
+			name: "Saved",
+			settings: &serviceModel.PublishSettings{
+				MetadataPolicy: serviceModel.MetadataKeep,
+				UpdatedAt:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expectedResponseBody: `{"metadata_policy":"keep","updated_at":"2024-01-01 00:00:00"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			mockPhotoService.EXPECT().GetPublishSettings(gomock.Any(), userUUID).Return(tt.settings, nil).Times(1)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.GET("/publish-settings", h.getPublishSettings)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/publish-settings", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, 200, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_savePublishSettings(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Valid",
+			body: `{"metadata_policy": "strip_all"}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().SavePublishSettings(gomock.Any(), userUUID, serviceModel.PublishSettings{
+					MetadataPolicy: serviceModel.MetadataStripAll,
+				}).Return(&serviceModel.PublishSettings{
+					MetadataPolicy: serviceModel.MetadataStripAll,
+					UpdatedAt:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				}, nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"metadata_policy":"strip_all","updated_at":"2024-01-01 00:00:00"}`,
+		},
+		{
+			name: "Unknown policy",
+			body: `{"metadata_policy": "none"}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().SavePublishSettings(gomock.Any(), userUUID, gomock.Any()).
+					Return(nil, serviceErr.InvalidMetadataPolicyError).Times(1)
+			},
+			expectedStatusCode: 400,
+			expectedResponseBody: `{"error":"invalid_request_params",` +
+				`"message":"Invalid metadata_policy, expected keep, strip_private or strip_all."}`,
+		},
+		{
+			name:                 "No policy",
+			body:                 `{}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Invalid request body format."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.PUT("/publish-settings", h.savePublishSettings)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/publish-settings", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func newRouter(userUUID string) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
+		if token == "valid-token" {
+			return serviceUserModel.TokenPayload{UserUUID: userUUID}, nil
+		}
+		return serviceUserModel.TokenPayload{}, errors.New("invalid token")
+	}))
+	return r
+}
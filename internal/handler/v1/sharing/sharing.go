@@ -0,0 +1,103 @@
+package sharing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get shared with me
+// @Description Get a page of other users' photos shared with the user directly, most recently uploaded first,
+// @Description and all albums shared with the user, most recently shared first
+// @Tags sharing
+// @Produce json
+// @Security JWTAuth
+// @Param limit query int false "Page size of photos, 50 by default"
+// @Param offset query int false "Number of photos to skip"
+// @Success 200 {object} photo.SharedWithMeResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/shared [get]
+func (h *handler) getSharedWithMe(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	limit, offset, err := queryPage(c)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, err.Error())
+		return
+	}
+
+	shared, err := h.photoService.GetSharedWithMe(ctx, userUUID, limit, offset)
+	if errors.Is(err, serviceErr.InvalidFilterError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid filter.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	res := photoResp.SharedWithMeResponse{
+		Photos: photoResp.ToPhotosFromModel(shared.Photos),
+		Albums: make([]photoResp.SharedAlbum, len(shared.Albums)),
+	}
+	for i := range shared.Albums {
+		res.Albums[i] = toSharedAlbumResponse(&shared.Albums[i])
+	}
+
+	response.NewOk(c, res)
+}
+
+// queryPage возвращает параметры limit и offset, 0 - не указан.
+func queryPage(c *gin.Context) (int, int, error) {
+	var page [2]int
+	for i, key := range []string{"limit", "offset"} {
+		v, ok := c.GetQuery(key)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid %s, expected integer.", key)
+		}
+		page[i] = n
+	}
+
+	return page[0], page[1], nil
+}
+
+func toSharedAlbumResponse(a *serviceModel.SharedAlbum) photoResp.SharedAlbum {
+	// Ошибки быть не может: фильтры состоят из простых значений
+	filter, _ := json.Marshal(a.Filter)
+
+	return photoResp.SharedAlbum{
+		Album: photoResp.Album{
+			AlbumID:   a.ID,
+			Title:     a.Title,
+			Filter:    filter,
+			CreatedAt: a.CreatedAt.Format(time.DateTime),
+			UpdatedAt: a.UpdatedAt.Format(time.DateTime),
+		},
+		OwnerUUID: a.OwnerUUID,
+		Role:      string(a.Role),
+	}
+}
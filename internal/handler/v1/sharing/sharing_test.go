@@ -0,0 +1,97 @@
+package sharing
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/model"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	serviceUserModel "go-photo/internal/service/user/model"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_getSharedWithMe(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Photos and albums",
+			query: "?limit=10&offset=20",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetSharedWithMe(gomock.Any(), userUUID, 10, 20).Return(&serviceModel.SharedWithMe{
+					Photos: []model.Photo{{ID: 3, Filename: "a.jpg", UploadedAt: createdAt, UpdatedAt: createdAt}},
+					Albums: []serviceModel.SharedAlbum{{
+						Album: serviceModel.Album{
+							ID: 7, Title: "Trip", Filter: serviceModel.AlbumFilter{Camera: "fuji"},
+							CreatedAt: createdAt, UpdatedAt: createdAt,
+						},
+						OwnerUUID: "owner-id",
+						Role:      serviceModel.ShareContributor,
+					}},
+				}, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"photos":[{"photo_id":3,"filename":"a.jpg","title":"","caption":"","favorite":false,"rating":0,` +
+				`"hidden":false,"uploaded_at":"2024-01-01 00:00:00","updated_at":"2024-01-01 00:00:00"}],` +
+				`"albums":[{"album_id":7,"title":"Trip","filter":{"camera":"fuji"},"manual":false,"created_at":"2024-01-01 00:00:00",` +
+				`"updated_at":"2024-01-01 00:00:00","owner_uuid":"owner-id","role":"contributor"}]}`,
+		},
+		{
+			name:                 "Invalid offset",
+			query:                "?offset=last",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid offset, expected integer."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.GET("/shared", h.getSharedWithMe)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/shared"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func newRouter(userUUID string) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
+		if token == "valid-token" {
+			return serviceUserModel.TokenPayload{UserUUID: userUUID}, nil
+		}
+		return serviceUserModel.TokenPayload{}, errors.New("invalid token")
+	}))
+	return r
+}
@@ -0,0 +1,47 @@
+package timeline
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-photo/internal/config"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/service"
+	"time"
+)
+
+type Options struct {
+	RequestTimeout time.Duration
+}
+
+type handler struct {
+	photoService service.PhotoService
+	tokenService service.TokenService
+	opts         Options
+}
+
+func NewHandler(photoService service.PhotoService, tokenService service.TokenService, opts Options) *handler {
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = config.DefaultContextTimeout
+	}
+
+	return &handler{
+		photoService: photoService,
+		tokenService: tokenService,
+		opts:         opts,
+	}
+}
+
+func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
+	timelineGroup := router.Group("/timeline")
+	timelineGroup.Use(middleware.UserIdentity(h.tokenService.VerifyToken))
+
+	{
+		timelineGroup.GET("", h.getTimeline)
+	}
+
+	memoriesGroup := router.Group("/memories")
+	memoriesGroup.Use(middleware.UserIdentity(h.tokenService.VerifyToken))
+
+	{
+		memoriesGroup.GET("/on-this-day", h.getOnThisDay)
+	}
+}
@@ -0,0 +1,143 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get timeline
+// @Description Group user's photos by day, month or year they were taken, most recent first.
+// @Description Photos without capture time in EXIF are grouped by upload time in the given time zone. Hidden photos are excluded
+// @Tags timeline
+// @Produce json
+// @Security JWTAuth
+// @Param granularity query string false "day, month (default) or year"
+// @Param tz query string false "IANA time zone, UTC by default"
+// @Param cursor query string false "Bucket to start from: 2006, 2006-01 or 2006-01-02 depending on granularity"
+// @Param limit query int false "Number of buckets, 100 by default"
+// @Success 200 {object} photo.GetTimelineResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/timeline [get]
+func (h *handler) getTimeline(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	filter := serviceModel.TimelineFilter{
+		TimeZone: c.Query("tz"),
+		Cursor:   c.Query("cursor"),
+	}
+	if v, ok := c.GetQuery("granularity"); ok {
+		granularity, err := model.ParseTimelineGranularity(v)
+		if err != nil {
+			response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid granularity, expected day, month or year.")
+			return
+		}
+		filter.Granularity = granularity
+	}
+	limit, err := queryLimit(c)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid limit, expected integer.")
+		return
+	}
+	filter.Limit = limit
+
+	timeline, err := h.photoService.GetTimeline(ctx, userUUID, filter)
+	if errors.Is(err, serviceErr.InvalidFilterError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid filter.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.GetTimelineResponse{
+		Granularity: string(timeline.Granularity),
+		Buckets:     photoResp.ToTimelineBucketsFromModel(timeline.Granularity, timeline.Buckets),
+		NextCursor:  timeline.NextCursor,
+	})
+}
+
+// @Summary Get photos taken on this day
+// @Description Get user's photos taken on the same calendar day in previous years, most recent first.
+// @Description Photos without capture time in EXIF are matched by upload time in the given time zone. Hidden photos are excluded
+// @Tags timeline
+// @Produce json
+// @Security JWTAuth
+// @Param tz query string false "IANA time zone, UTC by default. Also defines today"
+// @Param date query string false "Day in 2006-01-02 format, today by default"
+// @Param limit query int false "Number of photos, 50 by default"
+// @Success 200 {object} photo.GetPhotosResponse
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/memories/on-this-day [get]
+func (h *handler) getOnThisDay(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	filter := serviceModel.OnThisDayFilter{
+		TimeZone: c.Query("tz"),
+	}
+	if v, ok := c.GetQuery("date"); ok {
+		date, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid date, expected YYYY-MM-DD.")
+			return
+		}
+		filter.Date = date
+	}
+	limit, err := queryLimit(c)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid limit, expected integer.")
+		return
+	}
+	filter.Limit = limit
+
+	photos, err := h.photoService.GetPhotosOnThisDay(ctx, userUUID, filter)
+	if errors.Is(err, serviceErr.InvalidFilterError) {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidReqestsQueryParams, err, "Invalid filter.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.GetPhotosResponse{
+		Photos: photoResp.ToPhotosFromModel(photos),
+	})
+}
+
+// queryLimit возвращает параметр limit, 0 - не указан.
+func queryLimit(c *gin.Context) (int, error) {
+	v, ok := c.GetQuery("limit")
+	if !ok {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
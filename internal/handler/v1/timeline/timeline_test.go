@@ -0,0 +1,178 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	serviceUserModel "go-photo/internal/service/user/model"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_getTimeline(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Months",
+			query: "?tz=Europe/Moscow&cursor=2023-05&limit=1",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetTimeline(gomock.Any(), userUUID, serviceModel.TimelineFilter{
+					TimeZone: "Europe/Moscow",
+					Cursor:   "2023-05",
+					Limit:    1,
+				}).Return(&serviceModel.Timeline{
+					Granularity: model.TimelineMonth,
+					Buckets:     []model.TimelineBucket{{Start: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), Count: 3, CoverPhotoID: 5}},
+					NextCursor:  "2022-12",
+				}, nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"granularity":"month","buckets":[{"date":"2023-05","count":3,"cover_photo_id":5}],"next_cursor":"2022-12"}`,
+		},
+		{
+			name:  "Days",
+			query: "?granularity=day",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetTimeline(gomock.Any(), userUUID, serviceModel.TimelineFilter{Granularity: model.TimelineDay}).
+					Return(&serviceModel.Timeline{
+						Granularity: model.TimelineDay,
+						Buckets:     []model.TimelineBucket{{Start: time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC), Count: 1, CoverPhotoID: 9}},
+					}, nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"granularity":"day","buckets":[{"date":"2024-07-14","count":1,"cover_photo_id":9}]}`,
+		},
+		{
+			name:                 "Invalid granularity",
+			query:                "?granularity=week",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid granularity, expected day, month or year."}`,
+		},
+		{
+			name:  "Invalid filter",
+			query: "?tz=Mars/Olympus",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetTimeline(gomock.Any(), userUUID, gomock.Any()).Return(nil, serviceErr.InvalidFilterError).Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid filter."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.GET("/timeline", h.getTimeline)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/timeline"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getOnThisDay(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Photos",
+			query: "?tz=Europe/Moscow&date=2024-07-14",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetPhotosOnThisDay(gomock.Any(), userUUID, serviceModel.OnThisDayFilter{
+					TimeZone: "Europe/Moscow",
+					Date:     time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC),
+				}).Return([]model.Photo{{ID: 1, Filename: "a.jpg", TakenAt: time.Date(2021, 7, 14, 18, 30, 0, 0, time.UTC)}}, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"photos":[{"photo_id":1,"filename":"a.jpg","title":"","caption":"","favorite":false,"rating":0,"hidden":false,` +
+				`"uploaded_at":"0001-01-01 00:00:00","updated_at":"0001-01-01 00:00:00","taken_at":"2021-07-14 18:30:00"}]}`,
+		},
+		{
+			name:                 "Invalid date",
+			query:                "?date=14.07.2024",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid date, expected YYYY-MM-DD."}`,
+		},
+		{
+			name:                 "Invalid limit",
+			query:                "?limit=many",
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_query_params","message":"Invalid limit, expected integer."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.GET("/memories/on-this-day", h.getOnThisDay)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/memories/on-this-day"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func newRouter(userUUID string) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
+		if token == "valid-token" {
+			return serviceUserModel.TokenPayload{UserUUID: userUUID}, nil
+		}
+		return serviceUserModel.TokenPayload{}, errors.New("invalid token")
+	}))
+	return r
+}
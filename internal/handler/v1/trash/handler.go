@@ -0,0 +1,42 @@
+package trash
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-photo/internal/config"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/service"
+	"time"
+)
+
+type Options struct {
+	RequestTimeout time.Duration
+}
+
+type handler struct {
+	photoService service.PhotoService
+	tokenService service.TokenService
+	opts         Options
+}
+
+func NewHandler(photoService service.PhotoService, tokenService service.TokenService, opts Options) *handler {
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = config.DefaultContextTimeout
+	}
+
+	return &handler{
+		photoService: photoService,
+		tokenService: tokenService,
+		opts:         opts,
+	}
+}
+
+func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
+	trashGroup := router.Group("/trash")
+
+	trashGroup.Use(middleware.UserIdentity(h.tokenService.VerifyToken))
+
+	{
+		trashGroup.GET("", h.getTrash)
+		trashGroup.POST("/:id/restore", h.restorePhoto)
+	}
+}
@@ -0,0 +1,91 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get trash
+// @Description Get photos in trash with the time they will be permanently deleted
+// @Tags trash
+// @Produce json
+// @Security JWTAuth
+// @Success 200 {object} photo.GetTrashResponse
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/trash [get]
+func (h *handler) getTrash(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	photos, err := h.photoService.GetTrash(ctx, userUUID)
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, photoResp.GetTrashResponse{
+		Photos: serviceModel.ToTrashedPhotosFromService(photos),
+	})
+}
+
+// @Summary Restore photo
+// @Description Restore a photo from trash together with its publication
+// @Tags trash
+// @Produce json
+// @Security JWTAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {object} nil
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 403 {object} response.Error "Access denied."
+// @Failure 404 {object} response.Error "Photo not found in trash."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/trash/{id}/restore [post]
+func (h *handler) restorePhoto(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	idParam := c.Param("id")
+	photoID, err := strconv.Atoi(idParam)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid photo id.")
+		return
+	}
+
+	err = h.photoService.RestorePhoto(ctx, userUUID, photoID)
+	if errors.Is(err, serviceErr.PhotoNotFoundError) {
+		response.NewErr(c, http.StatusNotFound, response.PhotoNotFound, err, "Photo not found in trash.")
+		return
+	}
+	if errors.Is(err, serviceErr.AccessDeniedError) {
+		response.NewErr(c, http.StatusForbidden, response.Forbidden, err, "You do not have access to this photo.")
+		return
+	}
+	if response.HandleError(c, err) {
+		return
+	}
+
+	response.NewOk(c, nil)
+}
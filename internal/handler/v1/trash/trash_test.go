@@ -0,0 +1,195 @@
+package trash
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/photo"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	serviceUserModel "go-photo/internal/service/user/model"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_getTrash(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name               string
+		userUUID           string
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+		expectedResponse   any
+	}{
+		{
+			name:     "Valid",
+			userUUID: "1abc4",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetTrash(gomock.Any(), userUUID).Return([]serviceModel.TrashedPhoto{
+					{
+						Photo: model.Photo{
+							ID:         1,
+							UserUUID:   userUUID,
+							Filename:   "a.jpg",
+							UploadedAt: deletedAt.Add(-time.Hour),
+							DeletedAt:  deletedAt,
+						},
+						PurgeAt: deletedAt.Add(24 * time.Hour),
+					},
+				}, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponse: photo.GetTrashResponse{
+				Photos: []photo.TrashedPhoto{
+					{
+						PhotoID:    1,
+						Filename:   "a.jpg",
+						UploadedAt: "2024-01-01 11:00:00",
+						DeletedAt:  "2024-01-01 12:00:00",
+						PurgeAt:    "2024-01-02 12:00:00",
+					},
+				},
+			},
+		},
+		{
+			name:     "Empty trash",
+			userUUID: "1abc4",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetTrash(gomock.Any(), userUUID).Return(nil, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+			expectedResponse: photo.GetTrashResponse{
+				Photos: []photo.TrashedPhoto{},
+			},
+		},
+		{
+			name:     "Service error",
+			userUUID: "1abc4",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().GetTrash(gomock.Any(), userUUID).Return(nil, serviceErr.DbError).Times(1)
+			},
+			expectedStatusCode: 500,
+			expectedResponse: response.Error{
+				Error:   response.InternalServerError,
+				Message: "Unexpected error occurred.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, tt.userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(tt.userUUID)
+			r.GET("/trash", h.getTrash)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/trash", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			expectedBody, _ := json.Marshal(tt.expectedResponse)
+			assert.JSONEq(t, string(expectedBody), w.Body.String())
+		})
+	}
+}
+
+func TestHandler_restorePhoto(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name               string
+		userUUID           string
+		photoID            string
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:     "Valid",
+			userUUID: "1abc4",
+			photoID:  "123",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().RestorePhoto(gomock.Any(), userUUID, 123).Return(nil).Times(1)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Invalid photo id",
+			userUUID:           "1abc4",
+			photoID:            "abc",
+			mockBehavior:       func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode: 400,
+		},
+		{
+			name:     "Not in trash",
+			userUUID: "1abc4",
+			photoID:  "123",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().RestorePhoto(gomock.Any(), userUUID, 123).Return(serviceErr.PhotoNotFoundError).Times(1)
+			},
+			expectedStatusCode: 404,
+		},
+		{
+			name:     "Access denied",
+			userUUID: "1abc4",
+			photoID:  "123",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().RestorePhoto(gomock.Any(), userUUID, 123).Return(serviceErr.AccessDeniedError).Times(1)
+			},
+			expectedStatusCode: 403,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, tt.userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(tt.userUUID)
+			r.POST("/trash/:id/restore", h.restorePhoto)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/trash/"+tt.photoID+"/restore", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func newRouter(userUUID string) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
+		if token == "valid-token" {
+			return serviceUserModel.TokenPayload{UserUUID: userUUID}, nil
+		}
+		return serviceUserModel.TokenPayload{}, errors.New("invalid token")
+	}))
+	return r
+}
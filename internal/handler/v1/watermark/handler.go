@@ -0,0 +1,51 @@
+package watermark
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-photo/internal/config"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/service"
+	"time"
+)
+
+// maxSettingsSize ограничение тела запроса на изменение настроек
+const maxSettingsSize = 4 << 10
+
+type Options struct {
+	RequestTimeout time.Duration
+	// MaxFileSize максимальный размер файла логотипа в байтах.
+	MaxFileSize int64
+	// MaxRequestSize максимальный размер тела запроса на загрузку логотипа в байтах.
+	MaxRequestSize int64
+}
+
+type handler struct {
+	photoService service.PhotoService
+	tokenService service.TokenService
+	opts         Options
+}
+
+func NewHandler(photoService service.PhotoService, tokenService service.TokenService, opts Options) *handler {
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = config.DefaultContextTimeout
+	}
+
+	return &handler{
+		photoService: photoService,
+		tokenService: tokenService,
+		opts:         opts,
+	}
+}
+
+func (h *handler) RegisterRoutes(router *gin.RouterGroup) {
+	watermarkGroup := router.Group("/watermark")
+
+	watermarkGroup.Use(middleware.UserIdentity(h.tokenService.VerifyToken))
+
+	{
+		watermarkGroup.GET("", h.getWatermark)
+		watermarkGroup.PUT("", middleware.MaxBodySize(maxSettingsSize), h.saveWatermark)
+		watermarkGroup.DELETE("", h.deleteWatermark)
+		watermarkGroup.PUT("/logo", middleware.MaxBodySize(h.opts.MaxRequestSize), h.uploadWatermarkLogo)
+	}
+}
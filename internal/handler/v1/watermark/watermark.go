@@ -0,0 +1,208 @@
+package watermark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/handler/middleware"
+	"go-photo/internal/handler/request"
+	"go-photo/internal/handler/response"
+	"go-photo/internal/handler/response/auth"
+	photoResp "go-photo/internal/handler/response/photo"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const FormLogoFile = "logo_file"
+
+// @Summary Get watermark
+// @Description Get watermark settings of the user
+// @Tags watermark
+// @Produce json
+// @Security JWTAuth
+// @Success 200 {object} photo.Watermark
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 404 {object} response.Error "Watermark is not configured."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/watermark [get]
+func (h *handler) getWatermark(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	watermark, err := h.photoService.GetWatermark(ctx, userUUID)
+	if handleWatermarkError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toWatermarkResponse(watermark))
+}
+
+// @Summary Save watermark
+// @Description Create or replace watermark settings. Photos published with a watermark are served with the new one.
+// @Description The logo type requires an uploaded logo
+// @Tags watermark
+// @Accept json
+// @Produce json
+// @Security JWTAuth
+// @Param input body request.WatermarkSettings true "Watermark settings"
+// @Success 200 {object} photo.Watermark
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/watermark [put]
+func (h *handler) saveWatermark(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	var input request.WatermarkSettings
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, "Invalid request body format.")
+		return
+	}
+
+	watermark, err := h.photoService.SaveWatermark(ctx, userUUID, serviceModel.WatermarkSettings{
+		Type:     serviceModel.WatermarkType(input.Type),
+		Text:     input.Text,
+		Position: serviceModel.WatermarkPosition(input.Position),
+		Opacity:  input.Opacity,
+		Scale:    input.Scale,
+	})
+	if handleWatermarkError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toWatermarkResponse(watermark))
+}
+
+// @Summary Upload watermark logo
+// @Description Upload a PNG logo and switch the watermark to it, replacing the previous logo.
+// @Description Creates watermark settings with default position, opacity and scale if there are none
+// @Tags watermark
+// @Accept multipart/form-data
+// @Produce json
+// @Security JWTAuth
+// @Param logo_file formData file true "PNG logo"
+// @Success 200 {object} photo.Watermark
+// @Failure 400 {object} response.Error "Bad Request."
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 413 {object} response.Error "File is too large."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/watermark/logo [put]
+func (h *handler) uploadWatermarkLogo(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	fileHeader, err := c.FormFile(FormLogoFile)
+	if middleware.IsBodyTooLarge(err) {
+		response.NewErr(c, http.StatusRequestEntityTooLarge, response.RequestTooLarge, err, "Request body is too large.")
+		return
+	}
+	if err != nil {
+		response.NewErr(c, http.StatusBadRequest, response.ParamsMissing, err, fmt.Sprintf("No %s in form.", FormLogoFile))
+		return
+	}
+
+	if h.opts.MaxFileSize > 0 && fileHeader.Size > h.opts.MaxFileSize {
+		response.NewErr(c, http.StatusRequestEntityTooLarge, response.RequestTooLarge, nil,
+			fmt.Sprintf("File %s exceeds %d bytes.", fileHeader.Filename, h.opts.MaxFileSize))
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	if ext != ".png" {
+		response.NewErr(c, http.StatusBadRequest, response.UnsupportedFileType, nil, "Logo must be a PNG file, got: "+ext)
+		return
+	}
+
+	watermark, err := h.photoService.UploadWatermarkLogo(ctx, userUUID, fileHeader)
+	if handleWatermarkError(c, err) {
+		return
+	}
+
+	response.NewOk(c, toWatermarkResponse(watermark))
+}
+
+// @Summary Delete watermark
+// @Description Delete watermark settings together with the logo. The watermark can't be deleted while photos or albums
+// @Description are published with it
+// @Tags watermark
+// @Produce json
+// @Security JWTAuth
+// @Success 200 {object} nil
+// @Failure 401 {object} response.Error "Unauthorized."
+// @Failure 404 {object} response.Error "Watermark is not configured."
+// @Failure 409 {object} response.Error "Watermark is used by publications."
+// @Failure 500 {object} response.Error "Unexpected error occurred."
+// @Router /api/v1/watermark [delete]
+func (h *handler) deleteWatermark(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, h.opts.RequestTimeout)
+	defer cancel()
+
+	userUUID, ok := auth.MustGetUUID(c, middleware.UserUUIDCtx)
+	if !ok {
+		response.NewErr(c, http.StatusUnauthorized, response.Unauthorized, nil, "Try logging in again.")
+		return
+	}
+
+	err := h.photoService.DeleteWatermark(ctx, userUUID)
+	if handleWatermarkError(c, err) {
+		return
+	}
+
+	response.NewOk(c, nil)
+}
+
+// handleWatermarkError отвечает клиенту ошибкой сервиса водяных знаков. Возвращает false, если ошибки нет.
+func handleWatermarkError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, serviceErr.InvalidWatermarkError):
+		response.NewErr(c, http.StatusBadRequest, response.InvalidRequestParams, err, err.Error())
+	case errors.Is(err, serviceErr.WatermarkNotFoundError):
+		response.NewErr(c, http.StatusNotFound, response.WatermarkNotFound, err, "Watermark is not configured.")
+	case errors.Is(err, serviceErr.WatermarkInUseError):
+		response.NewErr(c, http.StatusConflict, response.WatermarkInUse, err,
+			"Watermark is used by published photos or albums, unpublish them first.")
+	default:
+		return response.HandleError(c, err)
+	}
+	return true
+}
+
+func toWatermarkResponse(w *serviceModel.Watermark) photoResp.Watermark {
+	return photoResp.Watermark{
+		Type:      string(w.Type),
+		Text:      w.Text,
+		Position:  string(w.Position),
+		Opacity:   w.Opacity,
+		Scale:     w.Scale,
+		HasLogo:   w.HasLogo,
+		UpdatedAt: w.UpdatedAt.Format(time.DateTime),
+	}
+}
@@ -0,0 +1,273 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/handler/middleware"
+	serviceErr "go-photo/internal/service/error"
+	mockservice "go-photo/internal/service/mock"
+	serviceModel "go-photo/internal/service/photo/model"
+	serviceUserModel "go-photo/internal/service/user/model"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testWatermark = &serviceModel.Watermark{
+	WatermarkSettings: serviceModel.WatermarkSettings{
+		Type:     serviceModel.WatermarkText,
+		Text:     "(c) me",
+		Position: serviceModel.WatermarkBottomRight,
+		Opacity:  50,
+		Scale:    20,
+	},
+	UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+const testWatermarkResponse = `{"type":"text","text":"(c) me","position":"bottom-right","opacity":50,"scale":20,` +
+	`"has_logo":false,"updated_at":"2024-01-01 00:00:00"}`
+
+func TestHandler_getWatermark(t *testing.T) {
+	tests := []struct {
+		name                 string
+		watermark            *serviceModel.Watermark
+		serviceErr           error
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "Valid",
+			watermark:            testWatermark,
+			expectedStatusCode:   200,
+			expectedResponseBody: testWatermarkResponse,
+		},
+		{
+			name:                 "Not configured",
+			serviceErr:           serviceErr.WatermarkNotFoundError,
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"error":"watermark_not_found","message":"Watermark is not configured."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			mockPhotoService.EXPECT().GetWatermark(gomock.Any(), userUUID).Return(tt.watermark, tt.serviceErr).Times(1)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.GET("/watermark", h.getWatermark)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/watermark", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_saveWatermark(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name                 string
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Valid",
+			body: `{"type": "text", "text": "(c) me"}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().SaveWatermark(gomock.Any(), userUUID, serviceModel.WatermarkSettings{
+					Type: serviceModel.WatermarkText, Text: "(c) me",
+				}).Return(testWatermark, nil).Times(1)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: testWatermarkResponse,
+		},
+		{
+			name: "Invalid settings",
+			body: `{"type": "logo"}`,
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().SaveWatermark(gomock.Any(), userUUID, gomock.Any()).
+					Return(nil, serviceErr.InvalidWatermarkError).Times(1)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"invalid watermark"}`,
+		},
+		{
+			name:                 "No type",
+			body:                 `{"text": "(c) me"}`,
+			mockBehavior:         func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"error":"invalid_request_params","message":"Invalid request body format."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.PUT("/watermark", h.saveWatermark)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/watermark", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_uploadWatermarkLogo(t *testing.T) {
+	type mockBehavior func(s *mockservice.MockPhotoService, userUUID string)
+
+	tests := []struct {
+		name               string
+		filename           string
+		maxFileSize        int64
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:     "Valid",
+			filename: "logo.png",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().UploadWatermarkLogo(gomock.Any(), userUUID, gomock.Any()).Return(testWatermark, nil).Times(1)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:     "Invalid PNG",
+			filename: "logo.png",
+			mockBehavior: func(s *mockservice.MockPhotoService, userUUID string) {
+				s.EXPECT().UploadWatermarkLogo(gomock.Any(), userUUID, gomock.Any()).
+					Return(nil, serviceErr.InvalidWatermarkError).Times(1)
+			},
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Not a PNG file",
+			filename:           "logo.jpg",
+			mockBehavior:       func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "File too large",
+			filename:           "logo.png",
+			maxFileSize:        2,
+			mockBehavior:       func(s *mockservice.MockPhotoService, userUUID string) {},
+			expectedStatusCode: 413,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			tt.mockBehavior(mockPhotoService, userUUID)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{MaxFileSize: tt.maxFileSize})
+
+			r := newRouter(userUUID)
+			r.PUT("/watermark/logo", h.uploadWatermarkLogo)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, err := writer.CreateFormFile(FormLogoFile, tt.filename)
+			require.NoError(t, err)
+			_, err = part.Write([]byte("logo"))
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/watermark/logo", body)
+			req.Header.Set("Authorization", "Bearer valid-token")
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_deleteWatermark(t *testing.T) {
+	tests := []struct {
+		name               string
+		serviceErr         error
+		expectedStatusCode int
+	}{
+		{name: "Deleted", expectedStatusCode: 200},
+		{name: "Not configured", serviceErr: serviceErr.WatermarkNotFoundError, expectedStatusCode: 404},
+		{name: "Used by publications", serviceErr: serviceErr.WatermarkInUseError, expectedStatusCode: 409},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			const userUUID = "1abc4"
+			mockPhotoService := mockservice.NewMockPhotoService(ctrl)
+			mockPhotoService.EXPECT().DeleteWatermark(gomock.Any(), userUUID).Return(tt.serviceErr).Times(1)
+
+			h := NewHandler(mockPhotoService, mockservice.NewMockTokenService(ctrl), Options{})
+
+			r := newRouter(userUUID)
+			r.DELETE("/watermark", h.deleteWatermark)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/watermark", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func newRouter(userUUID string) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.UserIdentity(func(ctx context.Context, token string) (serviceUserModel.TokenPayload, error) {
+		if token == "valid-token" {
+			return serviceUserModel.TokenPayload{UserUUID: userUUID}, nil
+		}
+		return serviceUserModel.TokenPayload{}, errors.New("invalid token")
+	}))
+	return r
+}
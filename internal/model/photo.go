@@ -11,6 +11,11 @@ const (
 	Original  PhotoVersionType = "original"
 	Thumbnail PhotoVersionType = "thumbnail"
 	Preview                    = "preview"
+	// Edited версия, полученная применением правок к оригиналу
+	Edited PhotoVersionType = "edited"
+	// Revision прежняя ревизия оригинала. Текущая ревизия всегда имеет тип Original,
+	// а прежние запрашиваются по номеру, поэтому тип не разбирается ParseVersionType.
+	Revision PhotoVersionType = "revision"
 )
 
 func ParseVersionType(version string) (PhotoVersionType, error) {
@@ -21,6 +26,8 @@ func ParseVersionType(version string) (PhotoVersionType, error) {
 		return Thumbnail, nil
 	case "preview":
 		return Preview, nil
+	case "edited":
+		return Edited, nil
 	default:
 		return "", fmt.Errorf("invalid version type: %s", version)
 	}
@@ -32,6 +39,28 @@ type Photo struct {
 	Filename   string
 	Versions   []PhotoVersion
 	UploadedAt time.Time
+	// DeletedAt время переноса в корзину, нулевое у неудаленных фото
+	DeletedAt time.Time
+	Title     string
+	Caption   string
+	Favorite  bool
+	// Rating оценка от 0 до 5, 0 - без оценки
+	Rating int
+	// Hidden скрытые фото не попадают в список фото по умолчанию
+	Hidden bool
+	// UpdatedAt время последнего изменения атрибутов
+	UpdatedAt time.Time
+	// Placeholder заглушка оригинала, заполняется только в списке фото
+	Placeholder Placeholder
+	// Location координаты съемки оригинала, заполняются только в списке фото. nil - координат нет.
+	Location *Location
+	// TakenAt местное время съемки оригинала из EXIF, заполняется только в списке фото. Нулевое - время неизвестно.
+	TakenAt time.Time
+}
+
+// ETag возвращает версию атрибутов фото для условных запросов (If-Match).
+func (p *Photo) ETag() string {
+	return fmt.Sprintf(`"%d-%d"`, p.ID, p.UpdatedAt.UnixMicro())
 }
 
 type PhotoVersion struct {
@@ -43,4 +72,90 @@ type PhotoVersion struct {
 	Height       int
 	Width        int
 	SavedAt      time.Time
+	// Revision номер ревизии оригинала, 0 у производных и отредактированных версий
+	Revision int
+	// Placeholder заглушка версии для отображения до загрузки файла
+	Placeholder Placeholder
+}
+
+// Placeholder заглушка фото, которую клиент показывает, пока загружается файл.
+type Placeholder struct {
+	// BlurHash размытое изображение в формате https://blurha.sh.
+	// Пустой, если заглушка еще не рассчитана или файл не удалось разобрать.
+	BlurHash string
+	// Palette преобладающие цвета в виде #rrggbb, начиная с самого частого
+	Palette []string
+}
+
+// Location координаты съемки в градусах WGS 84.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Camera производитель и модель камеры из EXIF. Пустая строка - значение неизвестно.
+type Camera struct {
+	Make  string
+	Model string
+}
+
+// PhotoCluster фото, попавшие в одну ячейку сетки на карте.
+type PhotoCluster struct {
+	// Location среднее положение фото ячейки
+	Location Location
+	Count    int
+	// PhotoID фото, которое представляет ячейку на карте
+	PhotoID int
+}
+
+// FoundPhoto фото, найденное полнотекстовым поиском.
+type FoundPhoto struct {
+	Photo
+	// Rank релевантность фото запросу, больше - релевантнее
+	Rank float64
+	// Highlight фрагменты названия, подписи и имени файла, совпадения выделены тегом <b>
+	Highlight string
+}
+
+// TimelineGranularity размер периода, по которым фото группируются в ленте.
+type TimelineGranularity string
+
+const (
+	TimelineDay   TimelineGranularity = "day"
+	TimelineMonth TimelineGranularity = "month"
+	TimelineYear  TimelineGranularity = "year"
+)
+
+func ParseTimelineGranularity(granularity string) (TimelineGranularity, error) {
+	switch granularity {
+	case "day":
+		return TimelineDay, nil
+	case "month":
+		return TimelineMonth, nil
+	case "year":
+		return TimelineYear, nil
+	default:
+		return "", fmt.Errorf("invalid timeline granularity: %s", granularity)
+	}
+}
+
+// Layout возвращает формат ключа периода: 2006, 2006-01 или 2006-01-02.
+func (g TimelineGranularity) Layout() string {
+	switch g {
+	case TimelineYear:
+		return "2006"
+	case TimelineMonth:
+		return "2006-01"
+	default:
+		return "2006-01-02"
+	}
+}
+
+// TimelineBucket фото, снятые за один период ленты.
+type TimelineBucket struct {
+	// Start начало периода по местному времени
+	Start time.Time
+	Count int
+	// CoverPhotoID фото, которое представляет период
+	CoverPhotoID int
 }
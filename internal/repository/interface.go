@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	repoModel "go-photo/internal/repository/photo/model"
+	"time"
 )
 
 //go:generate mockgen -source=interface.go -destination=mock/mocks.go
@@ -10,17 +11,43 @@ import (
 type PhotoRepository interface {
 	// CreateOriginalPhoto создает новую запись repoModel.Photo в БД и к ней repoModel.PhotoVersion.
 	// Гарантируется, что у фото будет original версия.
+	// Если задан PendingUploadID, в той же транзакции помечает запись журнала загрузки сохраненной.
 	CreateOriginalPhoto(ctx context.Context, photo *repoModel.CreateOriginalPhotoParams) (int, error)
 
 	// CreatePhotoPublishedInfo создает новую запись repoModel.PublishedPhotoInfo в БД.
 	// Возвращает уникальный токен для доступа к фото.
+	// Если params.Watermark, файлы публикации отдаются с водяным знаком владельца.
 	// Если запись уже существует, возвращает ошибку.
-	CreatePhotoPublishedInfo(ctx context.Context, photoID int) (string, error)
+	CreatePhotoPublishedInfo(ctx context.Context, params *repoModel.CreatePhotoPublishedInfoParams) (string, error)
 
 	// GetPhotoByID возвращает фото по его ID.
 	// Если фото не найдено, возвращает ошибку PhotoNotFound.
 	GetPhotoByID(ctx context.Context, photoID int) (*repoModel.Photo, error)
 
+	// GetUserPhotos возвращает фото пользователя не из корзины, начиная с загруженных последними,
+	// вместе с заглушкой, координатами и временем съемки оригинала.
+	GetUserPhotos(ctx context.Context, userUUID string, listParams *repoModel.PhotoListParams) ([]repoModel.Photo, error)
+
+	// GetUserPhotoClusters группирует фото пользователя с координатами внутри params.BBox по ячейкам сетки.
+	// Фото из корзины и скрытые фото не учитываются.
+	GetUserPhotoClusters(ctx context.Context, userUUID string, params *repoModel.PhotoClusterParams) ([]repoModel.PhotoCluster, error)
+
+	// GetUserTimeline группирует фото пользователя по периодам съемки, начиная с последнего.
+	// Фото без времени съемки в EXIF относятся к периоду загрузки. Фото из корзины и скрытые фото не учитываются.
+	GetUserTimeline(ctx context.Context, userUUID string, params *repoModel.TimelineParams) ([]repoModel.TimelineBucket, error)
+
+	// GetUserPhotosOnThisDay возвращает фото пользователя, снятые в день params.Date в прошлые годы,
+	// начиная со снятых последними. Фото из корзины и скрытые фото не возвращаются.
+	GetUserPhotosOnThisDay(ctx context.Context, userUUID string, params *repoModel.OnThisDayParams) ([]repoModel.Photo, error)
+
+	// SearchUserPhotos ищет фото пользователя не из корзины по названию, подписи, названиям альбомов, в которые
+	// фото добавлено, имени файла и камере оригинала, начиная с самых релевантных, и применяет фильтры списка фото.
+	SearchUserPhotos(ctx context.Context, userUUID string, params *repoModel.PhotoSearchParams) ([]repoModel.FoundPhoto, error)
+
+	// UpdatePhotoAttributes обновляет редактируемые атрибуты фото и возвращает новое значение updated_at.
+	// Если фото не найдено, в корзине или было изменено после params.UpdatedAt, возвращает ошибку NotFoundError.
+	UpdatePhotoAttributes(ctx context.Context, photoID int, params *repoModel.UpdatePhotoAttributesParams) (time.Time, error)
+
 	// GetPhotoVersions возвращает все версии фото по его ID.
 	GetPhotoVersions(ctx context.Context, photoID int) ([]repoModel.PhotoVersion, error)
 
@@ -31,7 +58,223 @@ type PhotoRepository interface {
 	// TODO: tests
 	GetPublicPhotosByTokenPrefix(ctx context.Context, tokenPrefix string, filterParams *repoModel.FilterParams) ([]repoModel.PhotoWithPhotoVersion, error)
 
+	// GetPublicPhotoVersions возвращает все версии опубликованного фото по точному токену.
+	// Фото в корзине не возвращаются. Если фото не найдено, возвращает ошибку NotFoundError.
+	GetPublicPhotoVersions(ctx context.Context, token string) ([]repoModel.PhotoWithPhotoVersion, error)
+
+	// GetAllPhotoVersions возвращает все версии всех фото вместе с владельцами, упорядоченные по ID.
+	// Возвращает и версии, фото которых не существует.
+	GetAllPhotoVersions(ctx context.Context) ([]repoModel.PhotoVersionWithOwner, error)
+
+	// UpdatePhotoVersionFile обновляет размеры и контрольную сумму версии после повторной записи ее файла,
+	// удаляет ее варианты в других форматах и сбрасывает заглушку.
+	// Если версия не найдена, возвращает ошибку NotFoundError.
+	UpdatePhotoVersionFile(ctx context.Context, versionID int, params *repoModel.UpdatePhotoVersionFileParams) error
+
+	// GetPhotoVersionRendition возвращает вариант версии фото в формате format.
+	// Если варианта нет, возвращает ошибку NotFoundError.
+	GetPhotoVersionRendition(ctx context.Context, versionID int, format string) (*repoModel.PhotoVersionRendition, error)
+
+	// SavePhotoVersionRendition сохраняет вариант версии фото, заменяя существующий вариант в том же формате.
+	// Варианты удаляются вместе с версией и при обновлении ее файла.
+	SavePhotoVersionRendition(ctx context.Context, params *repoModel.SavePhotoVersionRenditionParams) error
+
+	// GetVersionsWithoutPlaceholder возвращает не более limit версий всех фото вместе с владельцами,
+	// для которых еще не рассчитана заглушка, упорядоченные по ID. Прежние ревизии не возвращаются.
+	GetVersionsWithoutPlaceholder(ctx context.Context, limit int) ([]repoModel.PhotoVersionWithOwner, error)
+
+	// SavePhotoVersionPlaceholder сохраняет рассчитанную заглушку версии фото.
+	// Если версия не найдена, возвращает ошибку NotFoundError.
+	SavePhotoVersionPlaceholder(ctx context.Context, versionID int, params *repoModel.SavePhotoVersionPlaceholderParams) error
+
+	// GetVersionsWithoutExif возвращает не более limit оригиналов всех фото вместе с владельцами,
+	// из файлов которых еще не прочитаны координаты и время съемки, упорядоченные по ID.
+	GetVersionsWithoutExif(ctx context.Context, limit int) ([]repoModel.PhotoVersionWithOwner, error)
+
+	// SavePhotoVersionExif сохраняет координаты и время съемки, прочитанные из файла версии.
+	// Если версия не найдена, возвращает ошибку NotFoundError.
+	SavePhotoVersionExif(ctx context.Context, versionID int, exif repoModel.PhotoVersionExif) error
+
+	// GetPhotoEdit возвращает рецепт правок фото.
+	// Если фото не редактировалось, возвращает ошибку NotFoundError.
+	GetPhotoEdit(ctx context.Context, photoID int) (*repoModel.PhotoEdit, error)
+
+	// SavePhotoEdit сохраняет отредактированную версию фото вместе с рецептом правок,
+	// заменяя прежнюю отредактированную версию. Возвращает ID новой версии и замененную версию
+	// (nil, если ее не было), файл которой нужно удалить.
+	// Если фото не найдено или в корзине, возвращает ошибку NotFoundError.
+	SavePhotoEdit(ctx context.Context, params *repoModel.SavePhotoEditParams) (int, *repoModel.PhotoVersion, error)
+
+	// DeletePhotoEdit удаляет отредактированную версию фото вместе с рецептом и возвращает ее.
+	// Если версии нет, возвращает ошибку NotFoundError.
+	DeletePhotoEdit(ctx context.Context, photoID int) (*repoModel.PhotoVersion, error)
+
+	// GetPhotoRevisions возвращает ревизии оригинала фото от новых к старым.
+	// Текущая ревизия имеет тип original, прежние - revision.
+	GetPhotoRevisions(ctx context.Context, photoID int) ([]repoModel.PhotoVersion, error)
+
+	// CreatePhotoRevision сохраняет файл как новую текущую ревизию оригинала фото, прежняя текущая
+	// ревизия остается в истории. Возвращает новую ревизию и прежнюю текущую (nil, если ее не было).
+	// Если фото не найдено или в корзине, возвращает ошибку NotFoundError.
+	CreatePhotoRevision(ctx context.Context, params *repoModel.CreatePhotoRevisionParams) (*repoModel.PhotoVersion, *repoModel.PhotoVersion, error)
+
+	// RestorePhotoRevision делает ревизию с номером revision текущей. Возвращает ее и прежнюю
+	// текущую ревизию (nil, если ревизия уже была текущей).
+	// Если фото или ревизия не найдены, возвращает ошибку NotFoundError.
+	RestorePhotoRevision(ctx context.Context, photoID, revision int) (*repoModel.PhotoVersion, *repoModel.PhotoVersion, error)
+
+	// GetWatermarkByToken возвращает настройки водяного знака владельца публикации.
+	// Если публикация не найдена или опубликована без водяного знака, возвращает ошибку NotFoundError.
+	// Если публикация со знаком, а настроек у владельца нет, возвращает другую ошибку.
+	GetWatermarkByToken(ctx context.Context, token string) (*repoModel.WatermarkSettings, error)
+
+	// GetWatermarkSettings возвращает настройки водяного знака пользователя.
+	// Если настроек нет, возвращает ошибку NotFoundError.
+	GetWatermarkSettings(ctx context.Context, userUUID string) (*repoModel.WatermarkSettings, error)
+
+	// SaveWatermarkSettings создает или заменяет настройки водяного знака пользователя, не меняя логотип.
+	SaveWatermarkSettings(ctx context.Context, params *repoModel.SaveWatermarkSettingsParams) (*repoModel.WatermarkSettings, error)
+
+	// SaveWatermarkLogo сохраняет логотип пользователя и делает водяной знак логотипом. Если настроек нет,
+	// создает их со значениями по умолчанию. Возвращает настройки и имя файла прежнего логотипа
+	// (пустое, если его не было), который нужно удалить.
+	SaveWatermarkLogo(ctx context.Context, userUUID, logoFilename string) (*repoModel.WatermarkSettings, string, error)
+
+	// DeleteWatermarkSettings удаляет настройки водяного знака пользователя и возвращает их.
+	// Если настроек нет, возвращает ошибку NotFoundError. Если есть фото или альбомы, опубликованные
+	// с водяным знаком, настройки не удаляются и возвращается ошибка ConflictError.
+	DeleteWatermarkSettings(ctx context.Context, userUUID string) (*repoModel.WatermarkSettings, error)
+
+	// GetMetadataPolicyByToken возвращает политику метаданных публикации.
+	// Если публикация не найдена или фото в корзине, возвращает ошибку NotFoundError.
+	GetMetadataPolicyByToken(ctx context.Context, token string) (string, error)
+
+	// GetPublishSettings возвращает настройки публикации пользователя.
+	// Если настроек нет, возвращает ошибку NotFoundError.
+	GetPublishSettings(ctx context.Context, userUUID string) (*repoModel.PublishSettings, error)
+
+	// SavePublishSettings создает или заменяет настройки публикации пользователя.
+	SavePublishSettings(ctx context.Context, params *repoModel.SavePublishSettingsParams) (*repoModel.PublishSettings, error)
+
+	// CreateAlbum создает альбом пользователя и возвращает его.
+	CreateAlbum(ctx context.Context, userUUID string, params *repoModel.SaveAlbumParams) (*repoModel.Album, error)
+
+	// GetAlbumByID возвращает альбом по ID.
+	// Если альбом не найден, возвращает ошибку NotFoundError.
+	GetAlbumByID(ctx context.Context, albumID int) (*repoModel.Album, error)
+
+	// GetAlbumByToken возвращает опубликованный альбом по публичному токену.
+	// Если альбом не найден, возвращает ошибку NotFoundError.
+	GetAlbumByToken(ctx context.Context, token string) (*repoModel.Album, error)
+
+	// GetUserAlbums возвращает альбомы пользователя, начиная с созданных последними.
+	GetUserAlbums(ctx context.Context, userUUID string) ([]repoModel.Album, error)
+
+	// UpdateAlbum заменяет название, фильтры и режим альбома и возвращает его.
+	// Если альбом не найден, возвращает ошибку NotFoundError.
+	UpdateAlbum(ctx context.Context, albumID int, params *repoModel.SaveAlbumParams) (*repoModel.Album, error)
+
+	// DeleteAlbum удаляет альбом. Фото альбома не удаляются.
+	// Если альбом не найден, возвращает ошибку NotFoundError.
+	DeleteAlbum(ctx context.Context, albumID int) error
+
+	// PublishAlbum публикует альбом с политикой метаданных metadataPolicy и возвращает публичный токен.
+	// Если watermark, файлы публикации отдаются с водяным знаком владельца альбома.
+	// Повторная публикация сохраняет токен. Если альбом не найден, возвращает ошибку NotFoundError.
+	PublishAlbum(ctx context.Context, albumID int, metadataPolicy string, watermark bool) (string, error)
+
+	// UnpublishAlbum снимает альбом с публикации, токен перестает действовать.
+	// Если альбом не найден или не опубликован, возвращает ошибку NotFoundError.
+	UnpublishAlbum(ctx context.Context, albumID int) error
+
+	// SavePhotoShare выдает пользователю доступ к фото или меняет роль уже выданного доступа.
+	SavePhotoShare(ctx context.Context, photoID int, params *repoModel.SaveShareParams) (*repoModel.Share, error)
+
+	// SaveAlbumShare выдает пользователю доступ к альбому или меняет роль уже выданного доступа.
+	SaveAlbumShare(ctx context.Context, albumID int, params *repoModel.SaveShareParams) (*repoModel.Share, error)
+
+	// GetPhotoShares возвращает доступы к фото в порядке выдачи.
+	GetPhotoShares(ctx context.Context, photoID int) ([]repoModel.Share, error)
+
+	// GetAlbumShares возвращает доступы к альбому в порядке выдачи.
+	GetAlbumShares(ctx context.Context, albumID int) ([]repoModel.Share, error)
+
+	// GetPhotoShare возвращает доступ пользователя к фото.
+	// Если доступ не выдан, возвращает ошибку NotFoundError.
+	GetPhotoShare(ctx context.Context, photoID int, granteeUUID string) (*repoModel.Share, error)
+
+	// GetAlbumShare возвращает доступ пользователя к альбому.
+	// Если доступ не выдан, возвращает ошибку NotFoundError.
+	GetAlbumShare(ctx context.Context, albumID int, granteeUUID string) (*repoModel.Share, error)
+
+	// DeletePhotoShare отзывает доступ пользователя к фото.
+	// Если доступ не выдан, возвращает ошибку NotFoundError.
+	DeletePhotoShare(ctx context.Context, photoID int, granteeUUID string) error
+
+	// DeleteAlbumShare отзывает доступ пользователя к альбому.
+	// Если доступ не выдан, возвращает ошибку NotFoundError.
+	DeleteAlbumShare(ctx context.Context, albumID int, granteeUUID string) error
+
+	// GetSharedPhotos возвращает фото не из корзины, к которым пользователю выдан доступ,
+	// начиная с загруженных последними. Доступ через альбомы не учитывается.
+	GetSharedPhotos(ctx context.Context, granteeUUID string, listParams *repoModel.PhotoListParams) ([]repoModel.Photo, error)
+
+	// GetSharedAlbums возвращает альбомы, к которым пользователю выдан доступ, начиная с выданных последними.
+	GetSharedAlbums(ctx context.Context, granteeUUID string) ([]repoModel.SharedAlbum, error)
+
+	// AddAlbumPhoto добавляет фото, загруженное пользователем contributorUUID, в альбом.
+	// Повторное добавление ничего не меняет. Если альбом или фото не найдены, возвращает NotFoundError.
+	AddAlbumPhoto(ctx context.Context, albumID int, photoID int, contributorUUID string) error
+
+	// DeleteAlbumPhoto убирает добавленное фото из альбома, само фото не удаляется.
+	// Если фото не добавлено в альбом, возвращает NotFoundError.
+	DeleteAlbumPhoto(ctx context.Context, albumID int, photoID int) error
+
+	// GetAlbumPhotos возвращает фото альбома не из корзины, начиная с загруженных последними: добавленные
+	// в альбом фото и, если ownerPhotos, фото владельца, отобранные фильтрами listParams. К добавленным фото
+	// применяются только фильтры Hidden и PhotoID.
+	GetAlbumPhotos(ctx context.Context, albumID int, listParams *repoModel.PhotoListParams, ownerPhotos bool) ([]repoModel.Photo, error)
+
+	// GetContributedAlbums возвращает альбомы, в которые добавлено фото, в порядке добавления.
+	GetContributedAlbums(ctx context.Context, photoID int) ([]repoModel.Album, error)
+
+	// AddPhotoTag добавляет фото нормализованный тег. Повторное добавление ничего не меняет.
+	// Если фото не найдено, возвращает NotFoundError.
+	AddPhotoTag(ctx context.Context, photoID int, tag string) error
+
 	// DeletePhotoPublishedInfo удаляет запись repoModel.PublishedPhotoInfo из БД.
 	// Если запись не найдена, возвращает ошибку.
 	DeletePhotoPublishedInfo(ctx context.Context, photoID int) error
+
+	// TrashPhoto переносит фото в корзину.
+	// Если фото не найдено или уже в корзине, возвращает ошибку NotFoundError.
+	TrashPhoto(ctx context.Context, photoID int) error
+
+	// RestorePhoto восстанавливает фото из корзины.
+	// Если фото не найдено или не в корзине, возвращает ошибку NotFoundError.
+	RestorePhoto(ctx context.Context, photoID int) error
+
+	// GetTrashedPhotos возвращает фото пользователя в корзине, начиная с удаленных последними.
+	GetTrashedPhotos(ctx context.Context, userUUID string) ([]repoModel.Photo, error)
+
+	// GetPhotosTrashedBefore возвращает не более limit фото всех пользователей, перенесенных в корзину раньше before.
+	GetPhotosTrashedBefore(ctx context.Context, before time.Time, limit int) ([]repoModel.Photo, error)
+
+	// DeleteTrashedPhoto окончательно удаляет фото из корзины вместе с версиями и публикацией.
+	// Файлы версий не удаляются. Если фото не найдено или не в корзине, возвращает ошибку NotFoundError.
+	DeleteTrashedPhoto(ctx context.Context, photoID int) error
+
+	// CreatePendingUpload создает запись журнала незавершенной загрузки. Возвращает ее ID.
+	CreatePendingUpload(ctx context.Context, params *repoModel.CreatePendingUploadParams) (int, error)
+
+	// GetPendingUploads возвращает все записи журнала загрузок.
+	GetPendingUploads(ctx context.Context) ([]repoModel.PendingUpload, error)
+
+	// DeletePendingUpload удаляет запись журнала загрузки.
+	// Если запись не найдена, возвращает ошибку NotFoundError.
+	DeletePendingUpload(ctx context.Context, id int) error
+
+	// DeleteUncommittedPendingUpload удаляет запись журнала, только если фото еще не сохранено в БД.
+	// Если запись не найдена или уже сохранена, возвращает ошибку NotFoundError.
+	DeleteUncommittedPendingUpload(ctx context.Context, id int) error
 }
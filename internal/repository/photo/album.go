@@ -0,0 +1,205 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const albumSelectColumns = `id, user_uuid, title, filter, manual, public_token, metadata_policy, watermark, created_at, updated_at`
+
+func (r *repository) CreateAlbum(ctx context.Context, userUUID string, params *repoModel.SaveAlbumParams) (_ *repoModel.Album, err error) {
+	ctx, span := startSpan(ctx, "CreateAlbum")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return nil, repoErr.NilParamsError
+	}
+	if userUUID == "" || !params.IsValid() {
+		return nil, fmt.Errorf("%w: user %q, %v", repoErr.InvalidParamsError, userUUID, params)
+	}
+
+	var album repoModel.Album
+
+	query := `
+		INSERT INTO albums (user_uuid, title, filter, manual)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + albumSelectColumns
+
+	err = r.db.GetContext(ctx, &album, query, userUUID, params.Title, string(params.Filter), params.Manual)
+	if err != nil {
+		return nil, fmt.Errorf("album %w: %v", repoErr.InsertError, err)
+	}
+
+	return &album, nil
+}
+
+func (r *repository) GetAlbumByID(ctx context.Context, albumID int) (_ *repoModel.Album, err error) {
+	ctx, span := startSpan(ctx, "GetAlbumByID", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var album repoModel.Album
+
+	query := `
+		SELECT ` + albumSelectColumns + `
+		FROM albums
+		WHERE id = $1`
+
+	err = r.db.GetContext(ctx, &album, query, albumID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no album found with id %d", repoErr.NotFoundError, albumID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album by id: %w", err)
+	}
+
+	return &album, nil
+}
+
+func (r *repository) GetAlbumByToken(ctx context.Context, token string) (_ *repoModel.Album, err error) {
+	ctx, span := startSpan(ctx, "GetAlbumByToken")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var album repoModel.Album
+
+	query := `
+		SELECT ` + albumSelectColumns + `
+		FROM albums
+		WHERE public_token = $1`
+
+	err = r.db.GetContext(ctx, &album, query, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no album with public token %s", repoErr.NotFoundError, token)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album by token: %w", err)
+	}
+
+	return &album, nil
+}
+
+func (r *repository) GetUserAlbums(ctx context.Context, userUUID string) (_ []repoModel.Album, err error) {
+	ctx, span := startSpan(ctx, "GetUserAlbums")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	albums := []repoModel.Album{}
+
+	query := `
+		SELECT ` + albumSelectColumns + `
+		FROM albums
+		WHERE user_uuid = $1
+		ORDER BY created_at DESC, id DESC`
+
+	err = r.db.SelectContext(ctx, &albums, query, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user albums: %w", err)
+	}
+
+	return albums, nil
+}
+
+func (r *repository) UpdateAlbum(ctx context.Context, albumID int, params *repoModel.SaveAlbumParams) (_ *repoModel.Album, err error) {
+	ctx, span := startSpan(ctx, "UpdateAlbum", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	var album repoModel.Album
+
+	query := `
+		UPDATE albums
+		SET title = $1, filter = $2, manual = $3, updated_at = now()
+		WHERE id = $4
+		RETURNING ` + albumSelectColumns
+
+	err = r.db.GetContext(ctx, &album, query, params.Title, string(params.Filter), params.Manual, albumID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no album found with id %d", repoErr.NotFoundError, albumID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update album: %w", err)
+	}
+
+	return &album, nil
+}
+
+func (r *repository) DeleteAlbum(ctx context.Context, albumID int) (err error) {
+	ctx, span := startSpan(ctx, "DeleteAlbum", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		DELETE FROM albums
+		WHERE id = $1`
+
+	return r.updateAlbumAffectingOne(ctx, query, albumID)
+}
+
+func (r *repository) PublishAlbum(ctx context.Context, albumID int, metadataPolicy string, watermark bool) (_ string, err error) {
+	ctx, span := startSpan(ctx, "PublishAlbum", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if metadataPolicy == "" {
+		return "", fmt.Errorf("%w: empty metadata policy", repoErr.InvalidParamsError)
+	}
+
+	var publicToken string
+
+	// Повторная публикация сохраняет ссылку и меняет только политику метаданных и водяной знак
+	query := `
+		UPDATE albums
+		SET public_token = COALESCE(public_token, substring(replace(gen_random_uuid()::text, '-', '') from 1 for 16)),
+		    metadata_policy = $1,
+		    watermark = $2,
+		    updated_at = now()
+		WHERE id = $3
+		RETURNING public_token`
+
+	err = r.db.GetContext(ctx, &publicToken, query, metadataPolicy, watermark, albumID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: no album found with id %d", repoErr.NotFoundError, albumID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to publish album: %w", err)
+	}
+
+	return publicToken, nil
+}
+
+func (r *repository) UnpublishAlbum(ctx context.Context, albumID int) (err error) {
+	ctx, span := startSpan(ctx, "UnpublishAlbum", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		UPDATE albums
+		SET public_token = NULL, metadata_policy = NULL, watermark = false, updated_at = now()
+		WHERE id = $1 AND public_token IS NOT NULL`
+
+	return r.updateAlbumAffectingOne(ctx, query, albumID)
+}
+
+func (r *repository) updateAlbumAffectingOne(ctx context.Context, query string, albumID int) error {
+	res, err := r.db.ExecContext(ctx, query, albumID)
+	if err != nil {
+		return fmt.Errorf("failed to update album: %w", err)
+	}
+
+	affectedCnt, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+	if affectedCnt < 1 {
+		return fmt.Errorf("%w: no rows affected with album id %d", repoErr.NotFoundError, albumID)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	pkgRepo "go-photo/pkg/repository"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (r *repository) AddAlbumPhoto(ctx context.Context, albumID int, photoID int, contributorUUID string) (err error) {
+	ctx, span := startSpan(ctx, "AddAlbumPhoto", attribute.Int("album.id", albumID), attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if contributorUUID == "" {
+		return fmt.Errorf("%w: empty contributor", repoErr.InvalidParamsError)
+	}
+
+	query := `
+		INSERT INTO album_photos (album_id, photo_id, contributor_uuid)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (album_id, photo_id) DO NOTHING`
+
+	_, err = r.db.ExecContext(ctx, query, albumID, photoID, contributorUUID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pkgRepo.ForeignKeyViolationErrorCode {
+			return fmt.Errorf("%w: no album %d or photo %d: %v", repoErr.NotFoundError, albumID, photoID, err)
+		}
+		return fmt.Errorf("failed to add photo to album: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteAlbumPhoto(ctx context.Context, albumID int, photoID int) (err error) {
+	ctx, span := startSpan(ctx, "DeleteAlbumPhoto", attribute.Int("album.id", albumID), attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		DELETE FROM album_photos
+		WHERE album_id = $1 AND photo_id = $2`
+
+	res, err := r.db.ExecContext(ctx, query, albumID, photoID)
+	if err != nil {
+		return fmt.Errorf("failed to delete photo from album: %w", err)
+	}
+
+	affectedCnt, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+	if affectedCnt < 1 {
+		return fmt.Errorf("%w: photo %d is not added to album %d", repoErr.NotFoundError, photoID, albumID)
+	}
+
+	return nil
+}
+
+func (r *repository) GetAlbumPhotos(ctx context.Context, albumID int, listParams *repoModel.PhotoListParams, ownerPhotos bool) (_ []repoModel.Photo, err error) {
+	ctx, span := startSpan(ctx, "GetAlbumPhotos", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if listParams == nil {
+		return nil, repoErr.NilParamsError
+	}
+
+	photos := []repoModel.Photo{}
+
+	params := map[string]interface{}{
+		"album_id": albumID,
+		"limit":    listParams.Limit,
+		"offset":   listParams.Offset,
+	}
+
+	photoFilter := repoModel.PhotoListParams{Hidden: listParams.Hidden, PhotoID: listParams.PhotoID}
+	albumPhotos := `id IN (SELECT ap.photo_id FROM album_photos ap WHERE ap.album_id = :album_id)`
+
+	query := `
+		SELECT ` + photoSelectColumns + `,` + originalSelectColumns + `
+		FROM photos
+		WHERE deleted_at IS NULL`
+	if ownerPhotos {
+		// Фильтры альбома отбирают только фото владельца, добавленные фото попадают в альбом без них
+		ownerFilter := *listParams
+		ownerFilter.Hidden, ownerFilter.PhotoID = nil, nil
+		query += `
+		  AND ((user_uuid = (SELECT a.user_uuid FROM albums a WHERE a.id = :album_id)` + ownerFilter.MapToArgs(params) + `)
+		    OR ` + albumPhotos + `)`
+	} else {
+		query += `
+		  AND ` + albumPhotos
+	}
+	query += photoFilter.MapToArgs(params)
+	query += `
+		ORDER BY uploaded_at DESC, id DESC
+		LIMIT :limit OFFSET :offset`
+
+	namedQuery, args, err := sqlx.Named(query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare query: %w", err)
+	}
+
+	err = r.db.SelectContext(ctx, &photos, r.db.Rebind(namedQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album photos: %w", err)
+	}
+
+	return photos, nil
+}
+
+func (r *repository) GetContributedAlbums(ctx context.Context, photoID int) (_ []repoModel.Album, err error) {
+	ctx, span := startSpan(ctx, "GetContributedAlbums", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	albums := []repoModel.Album{}
+
+	query := `
+		SELECT a.id, a.user_uuid, a.title, a.filter, a.manual, a.public_token, a.metadata_policy, a.watermark, a.created_at, a.updated_at
+		FROM album_photos ap
+		JOIN albums a ON a.id = ap.album_id
+		WHERE ap.photo_id = $1
+		ORDER BY ap.added_at, a.id`
+
+	err = r.db.SelectContext(ctx, &albums, query, photoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contributed albums: %w", err)
+	}
+
+	return albums, nil
+}
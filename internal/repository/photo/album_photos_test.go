@@ -0,0 +1,158 @@
+package photo
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+func TestRepository_AddAndDeleteAlbumPhoto(t *testing.T) {
+	tests := []struct {
+		name          string
+		call          func(repo *repository) error
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "Add",
+			call: func(repo *repository) error { return repo.AddAlbumPhoto(context.Background(), 1, 5, "2def5") },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO album_photos \(album_id, photo_id, contributor_uuid\) VALUES \(\$1, \$2, \$3\) `+
+					`ON CONFLICT \(album_id, photo_id\) DO NOTHING`).
+					WithArgs(1, 5, "2def5").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Add to deleted album",
+			call: func(repo *repository) error { return repo.AddAlbumPhoto(context.Background(), 1, 5, "2def5") },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO album_photos`).
+					WithArgs(1, 5, "2def5").
+					WillReturnError(&pq.Error{Code: "23503"})
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name:          "Add without contributor",
+			call:          func(repo *repository) error { return repo.AddAlbumPhoto(context.Background(), 1, 5, "") },
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+		{
+			name: "Delete",
+			call: func(repo *repository) error { return repo.DeleteAlbumPhoto(context.Background(), 1, 5) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM album_photos WHERE album_id = \$1 AND photo_id = \$2`).
+					WithArgs(1, 5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Delete not added",
+			call: func(repo *repository) error { return repo.DeleteAlbumPhoto(context.Background(), 1, 5) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM album_photos WHERE album_id = \$1 AND photo_id = \$2`).
+					WithArgs(1, 5).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			err = tt.call(repo)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetAlbumPhotos(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(sqlx.NewDb(db, "postgres"))
+
+	visible := false
+	minRating := 4
+	mock.ExpectQuery(`FROM photos WHERE deleted_at IS NULL `+
+		`AND \(\(user_uuid = \(SELECT a.user_uuid FROM albums a WHERE a.id = \$1\) AND rating >= \$2\) `+
+		`OR id IN \(SELECT ap.photo_id FROM album_photos ap WHERE ap.album_id = \$3\)\) `+
+		`AND hidden = \$4 ORDER BY uploaded_at DESC, id DESC LIMIT \$5 OFFSET \$6`).
+		WithArgs(1, 4, 1, false, 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_uuid", "filename"}).
+			AddRow(7, "2def5", "contributed.jpg").
+			AddRow(3, "1abc4", "own.jpg"))
+
+	photos, err := repo.GetAlbumPhotos(context.Background(), 1, &model.PhotoListParams{
+		Hidden: &visible, MinRating: &minRating, Limit: 10, Offset: 20,
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, photos, 2)
+	assert.Equal(t, "2def5", photos[0].UserUUID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetAlbumPhotos_Manual(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(sqlx.NewDb(db, "postgres"))
+
+	// Без фото владельца запрос отбирает только добавленные фото: фото владельца, которого нет в album_photos,
+	// не попадает в альбом участника или зрителя
+	visible := false
+	mock.ExpectQuery(`FROM photos WHERE deleted_at IS NULL `+
+		`AND id IN \(SELECT ap.photo_id FROM album_photos ap WHERE ap.album_id = \$1\) `+
+		`AND hidden = \$2 ORDER BY uploaded_at DESC, id DESC LIMIT \$3 OFFSET \$4$`).
+		WithArgs(1, false, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_uuid", "filename"}).
+			AddRow(7, "2def5", "contributed.jpg"))
+
+	photos, err := repo.GetAlbumPhotos(context.Background(), 1, &model.PhotoListParams{Hidden: &visible, Limit: 10}, false)
+	require.NoError(t, err)
+	require.Len(t, photos, 1)
+	assert.Equal(t, 7, photos[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetContributedAlbums(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(sqlx.NewDb(db, "postgres"))
+
+	mock.ExpectQuery(`FROM album_photos ap JOIN albums a ON a.id = ap.album_id WHERE ap.photo_id = \$1`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows(albumColumns).
+			AddRow(1, "1abc4", "Trip", []byte(`{}`), true, nil, nil, false, time.Now(), time.Now()))
+
+	albums, err := repo.GetContributedAlbums(context.Background(), 5)
+	require.NoError(t, err)
+	require.Len(t, albums, 1)
+	assert.Equal(t, "1abc4", albums[0].UserUUID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,267 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+var albumColumns = []string{"id", "user_uuid", "title", "filter", "manual", "public_token", "metadata_policy", "watermark", "created_at", "updated_at"}
+
+func TestRepository_CreateAlbum(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		params        *model.SaveAlbumParams
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedAlbum *model.Album
+		expectedError error
+	}{
+		{
+			name:   "Created",
+			params: &model.SaveAlbumParams{Title: "Favorites", Filter: []byte(`{"favorite":true}`)},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO albums \(user_uuid, title, filter, manual\) VALUES \(\$1, \$2, \$3, \$4\) RETURNING id, user_uuid`).
+					WithArgs("1abc4", "Favorites", `{"favorite":true}`, false).
+					WillReturnRows(sqlmock.NewRows(albumColumns).
+						AddRow(1, "1abc4", "Favorites", []byte(`{"favorite": true}`), false, nil, nil, false, createdAt, createdAt))
+			},
+			expectedAlbum: &model.Album{
+				ID: 1, UserUUID: "1abc4", Title: "Favorites", Filter: []byte(`{"favorite": true}`),
+				CreatedAt: createdAt, UpdatedAt: createdAt,
+			},
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+		{
+			name:          "Invalid filter",
+			params:        &model.SaveAlbumParams{Title: "Favorites", Filter: []byte(`{`)},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			album, err := repo.CreateAlbum(context.Background(), "1abc4", tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedAlbum, album)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetAlbumByID(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedAlbum *model.Album
+		expectedError error
+	}{
+		{
+			name: "Published album",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, user_uuid, title, filter, manual, public_token, metadata_policy, watermark, created_at, updated_at FROM albums WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(albumColumns).
+						AddRow(1, "1abc4", "Favorites", []byte(`{}`), false, "token", "strip_all", true, createdAt, createdAt))
+			},
+			expectedAlbum: &model.Album{
+				ID: 1, UserUUID: "1abc4", Title: "Favorites", Filter: []byte(`{}`),
+				PublicToken:    sql.NullString{String: "token", Valid: true},
+				MetadataPolicy: sql.NullString{String: "strip_all", Valid: true},
+				Watermark:      true,
+				CreatedAt:      createdAt, UpdatedAt: createdAt,
+			},
+		},
+		{
+			name: "Not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM albums").
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			album, err := repo.GetAlbumByID(context.Background(), 1)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedAlbum, album)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_UpdateAlbum(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "Updated",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE albums SET title = \$1, filter = \$2, manual = \$3, updated_at = now\(\) WHERE id = \$4 RETURNING id`).
+					WithArgs("Best", `{"min_rating":4}`, true, 1).
+					WillReturnRows(sqlmock.NewRows(albumColumns).
+						AddRow(1, "1abc4", "Best", []byte(`{"min_rating": 4}`), true, nil, nil, false, time.Now(), time.Now()))
+			},
+		},
+		{
+			name: "Not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE albums").
+					WithArgs("Best", `{"min_rating":4}`, true, 1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			_, err = repo.UpdateAlbum(context.Background(), 1, &model.SaveAlbumParams{Title: "Best", Filter: []byte(`{"min_rating":4}`), Manual: true})
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_PublishAndUnpublishAlbum(t *testing.T) {
+	tests := []struct {
+		name          string
+		call          func(repo *repository) (string, error)
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedToken string
+		expectedError error
+	}{
+		{
+			name: "Publish",
+			call: func(repo *repository) (string, error) {
+				return repo.PublishAlbum(context.Background(), 1, "strip_all", true)
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE albums SET public_token = COALESCE\(public_token, (.+)\), metadata_policy = \$1, watermark = \$2, updated_at = now\(\) `+
+					`WHERE id = \$3 RETURNING public_token`).
+					WithArgs("strip_all", true, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"public_token"}).AddRow("token"))
+			},
+			expectedToken: "token",
+		},
+		{
+			name:          "Publish without policy",
+			call:          func(repo *repository) (string, error) { return repo.PublishAlbum(context.Background(), 1, "", false) },
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+		{
+			name: "Publish not found",
+			call: func(repo *repository) (string, error) {
+				return repo.PublishAlbum(context.Background(), 1, "keep", false)
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE albums").
+					WithArgs("keep", false, 1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Unpublish",
+			call: func(repo *repository) (string, error) { return "", repo.UnpublishAlbum(context.Background(), 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE albums SET public_token = NULL, metadata_policy = NULL, watermark = false, updated_at = now\(\) WHERE id = \$1 AND public_token IS NOT NULL`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Unpublish not published",
+			call: func(repo *repository) (string, error) { return "", repo.UnpublishAlbum(context.Background(), 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE albums").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Delete",
+			call: func(repo *repository) (string, error) { return "", repo.DeleteAlbum(context.Background(), 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM albums WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			token, err := tt.call(repo)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedToken, token)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"time"
+)
+
+// photoSelectColumns колонки photos, из которых собирается repoModel.Photo
+const photoSelectColumns = `id, user_uuid, filename, uploaded_at, deleted_at, title, caption, favorite, rating, hidden, updated_at`
+
+// originalSelectColumns заглушка, координаты и время съемки текущего оригинала фото из таблицы photos.
+const originalSelectColumns = `
+		       (SELECT blurhash FROM photo_versions pv WHERE pv.photo_id = photos.id AND pv.version_type = 'original') AS blurhash,
+		       (SELECT palette FROM photo_versions pv WHERE pv.photo_id = photos.id AND pv.version_type = 'original') AS palette,
+		       (SELECT latitude FROM photo_versions pv WHERE pv.photo_id = photos.id AND pv.version_type = 'original') AS latitude,
+		       (SELECT longitude FROM photo_versions pv WHERE pv.photo_id = photos.id AND pv.version_type = 'original') AS longitude,
+		       (SELECT taken_at FROM photo_versions pv WHERE pv.photo_id = photos.id AND pv.version_type = 'original') AS taken_at`
+
+func (r *repository) GetUserPhotos(ctx context.Context, userUUID string, listParams *repoModel.PhotoListParams) (_ []repoModel.Photo, err error) {
+	ctx, span := startSpan(ctx, "GetUserPhotos")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if listParams == nil {
+		return nil, repoErr.NilParamsError
+	}
+
+	photos := []repoModel.Photo{}
+
+	query := `
+		SELECT ` + photoSelectColumns + `,` + originalSelectColumns + `
+		FROM photos
+		WHERE user_uuid = :user_uuid AND deleted_at IS NULL`
+
+	params := map[string]interface{}{
+		"user_uuid": userUUID,
+		"limit":     listParams.Limit,
+		"offset":    listParams.Offset,
+	}
+	query += listParams.MapToArgs(params)
+	query += `
+		ORDER BY uploaded_at DESC, id DESC
+		LIMIT :limit OFFSET :offset`
+
+	namedQuery, args, err := sqlx.Named(query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare query: %w", err)
+	}
+
+	err = r.db.SelectContext(ctx, &photos, r.db.Rebind(namedQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user photos: %w", err)
+	}
+
+	return photos, nil
+}
+
+func (r *repository) UpdatePhotoAttributes(ctx context.Context, photoID int, params *repoModel.UpdatePhotoAttributesParams) (_ time.Time, err error) {
+	ctx, span := startSpan(ctx, "UpdatePhotoAttributes", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return time.Time{}, repoErr.NilParamsError
+	}
+
+	// updated_at гарантированно увеличивается, даже если часы БД не сдвинулись с прошлого изменения,
+	// иначе два изменения подряд получили бы одинаковый ETag
+	query := `
+		UPDATE photos
+		SET title = NULLIF($2, ''),
+		    caption = NULLIF($3, ''),
+		    favorite = $4,
+		    rating = $5,
+		    hidden = $6,
+		    updated_at = GREATEST(clock_timestamp(), updated_at + INTERVAL '1 microsecond')
+		WHERE id = $1 AND deleted_at IS NULL AND updated_at = $7
+		RETURNING updated_at`
+
+	var updatedAt time.Time
+	err = r.db.QueryRowContext(ctx, query,
+		photoID, params.Title, params.Caption, params.Favorite, params.Rating, params.Hidden, params.UpdatedAt,
+	).Scan(&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("%w: no photo with id %d and updated_at %s", repoErr.NotFoundError, photoID, params.UpdatedAt)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to update photo attributes: %w", err)
+	}
+
+	return updatedAt, nil
+}
@@ -0,0 +1,237 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"go-photo/pkg/imaging"
+	"testing"
+	"time"
+)
+
+var photoAttributesColumns = []string{
+	"id", "user_uuid", "filename", "uploaded_at", "deleted_at",
+	"title", "caption", "favorite", "rating", "hidden", "updated_at",
+}
+
+func TestRepository_GetUserPhotos(t *testing.T) {
+	updatedAt := time.Now()
+	favorite := true
+	minRating := 3
+	takenFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	takenBefore := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	camera := "x_t4"
+	published := false
+	photoID := 7
+	tag := "sea"
+
+	tests := []struct {
+		name           string
+		listParams     *model.PhotoListParams
+		mockSetup      func(mock sqlmock.Sqlmock)
+		expectedPhotos []model.Photo
+		expectedError  error
+	}{
+		{
+			name:       "Without filters",
+			listParams: &model.PhotoListParams{Limit: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM photos WHERE user_uuid = \$1 AND deleted_at IS NULL ORDER BY uploaded_at DESC, id DESC LIMIT \$2 OFFSET \$3`).
+					WithArgs("user-uuid", 10, 0).
+					WillReturnRows(sqlmock.NewRows(photoAttributesColumns).
+						AddRow(1, "user-uuid", "a.png", nil, nil, "Sunset", nil, true, 5, false, updatedAt))
+			},
+			expectedPhotos: []model.Photo{
+				{
+					ID: 1, UserUUID: "user-uuid", Filename: "a.png",
+					Title: sql.NullString{String: "Sunset", Valid: true}, Favorite: true, Rating: 5, UpdatedAt: updatedAt,
+				},
+			},
+		},
+		{
+			name:       "With filters",
+			listParams: &model.PhotoListParams{Favorite: &favorite, MinRating: &minRating, Limit: 10, Offset: 20},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AND favorite = \$2 AND rating >= \$3 ORDER BY uploaded_at DESC, id DESC LIMIT \$4 OFFSET \$5`).
+					WithArgs("user-uuid", true, 3, 10, 20).
+					WillReturnRows(sqlmock.NewRows(photoAttributesColumns))
+			},
+			expectedPhotos: []model.Photo{},
+		},
+		{
+			name:       "By color",
+			listParams: &model.PhotoListParams{Color: &model.ColorFilter{Color: imaging.Lab{L: 45, A: 5, B: -50}, Tolerance: 10}, Limit: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AND id IN \( SELECT pv.photo_id FROM photo_version_colors pvc .* WHERE pvc.bucket = ANY\(\$2\) `+
+					`AND power\(pvc.l - \$3, 2\) \+ power\(pvc.a - \$4, 2\) \+ power\(pvc.b - \$5, 2\) <= \$6\) ORDER BY`).
+					WithArgs("user-uuid", sqlmock.AnyArg(), 45.0, 5.0, -50.0, 100.0, 10, 0).
+					WillReturnRows(sqlmock.NewRows(append(photoAttributesColumns, "blurhash", "palette")).
+						AddRow(1, "user-uuid", "a.png", nil, nil, nil, nil, false, 0, false, updatedAt, "LKO2?U%2Tw=w", "{#3366cc}"))
+			},
+			expectedPhotos: []model.Photo{
+				{
+					ID: 1, UserUUID: "user-uuid", Filename: "a.png", UpdatedAt: updatedAt,
+					Blurhash: sql.NullString{String: "LKO2?U%2Tw=w", Valid: true}, Palette: pq.StringArray{"#3366cc"},
+				},
+			},
+		},
+		{
+			name:       "By bounding box",
+			listParams: &model.PhotoListParams{BBox: &model.BBox{West: 37, South: 55, East: 38, North: 56}, Limit: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AND id IN \( SELECT pv.photo_id FROM photo_versions pv WHERE pv.version_type = 'original' AND pv.latitude IS NOT NULL `+
+					`AND point\(pv.longitude, pv.latitude\) <@ box\(point\(\$2, \$3\), point\(\$4, \$5\)\)\) ORDER BY`).
+					WithArgs("user-uuid", 37.0, 55.0, 38.0, 56.0, 10, 0).
+					WillReturnRows(sqlmock.NewRows(append(photoAttributesColumns, "latitude", "longitude")).
+						AddRow(1, "user-uuid", "a.png", nil, nil, nil, nil, false, 0, false, updatedAt, 55.75, 37.61))
+			},
+			expectedPhotos: []model.Photo{
+				{
+					ID: 1, UserUUID: "user-uuid", Filename: "a.png", UpdatedAt: updatedAt,
+					Latitude: sql.NullFloat64{Float64: 55.75, Valid: true}, Longitude: sql.NullFloat64{Float64: 37.61, Valid: true},
+				},
+			},
+		},
+		{
+			name: "By capture time, camera, publication and ID",
+			listParams: &model.PhotoListParams{
+				TakenFrom: &takenFrom, TakenBefore: &takenBefore, Camera: &camera, Published: &published, PhotoID: &photoID, Limit: 1,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AND COALESCE\( \(SELECT pv.taken_at FROM photo_versions pv WHERE pv.photo_id = photos.id AND pv.version_type = 'original'\), `+
+					`photos.uploaded_at AT TIME ZONE 'UTC' AT TIME ZONE \$2\) >= \$3 `+
+					`AND COALESCE\(.*\$4\) < \$5 `+
+					`AND id IN \( SELECT pv.photo_id FROM photo_versions pv WHERE pv.version_type = 'original' AND concat_ws\(' ', pv.camera_make, pv.camera_model\) ILIKE \$6\) `+
+					`AND id NOT IN \(SELECT photo_id FROM published_photo_info\) AND id = \$7 ORDER BY`).
+					WithArgs("user-uuid", "UTC", takenFrom, "UTC", takenBefore, `%x\_t4%`, 7, 1, 0).
+					WillReturnRows(sqlmock.NewRows(photoAttributesColumns))
+			},
+			expectedPhotos: []model.Photo{},
+		},
+		{
+			name:       "By capture time in time zone",
+			listParams: &model.PhotoListParams{TakenFrom: &takenFrom, TimeZone: "Europe/Moscow", Limit: 1},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AND COALESCE\(.*photos.uploaded_at AT TIME ZONE 'UTC' AT TIME ZONE \$2\) >= \$3 ORDER BY`).
+					WithArgs("user-uuid", "Europe/Moscow", takenFrom, 1, 0).
+					WillReturnRows(sqlmock.NewRows(photoAttributesColumns))
+			},
+			expectedPhotos: []model.Photo{},
+		},
+		{
+			name:       "By tag",
+			listParams: &model.PhotoListParams{Tag: &tag, Limit: 1},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AND id IN \(SELECT pt.photo_id FROM photo_tags pt WHERE pt.tag = \$2\) ORDER BY`).
+					WithArgs("user-uuid", "sea", 1, 0).
+					WillReturnRows(sqlmock.NewRows(photoAttributesColumns))
+			},
+			expectedPhotos: []model.Photo{},
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Именованные параметры переводятся в плейсхолдеры по имени драйвера
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			photos, err := repo.GetUserPhotos(context.Background(), "user-uuid", tt.listParams)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPhotos, photos)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_UpdatePhotoAttributes(t *testing.T) {
+	readAt := time.Now()
+	updatedAt := readAt.Add(time.Second)
+	params := &model.UpdatePhotoAttributesParams{
+		Title:     "Sunset",
+		Favorite:  true,
+		Rating:    4,
+		UpdatedAt: readAt,
+	}
+
+	tests := []struct {
+		name              string
+		mockSetup         func(mock sqlmock.Sqlmock)
+		expectedUpdatedAt time.Time
+		expectedError     error
+	}{
+		{
+			name: "Updated",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE photos .* WHERE id = \$1 AND deleted_at IS NULL AND updated_at = \$7 RETURNING updated_at`).
+					WithArgs(1, "Sunset", "", true, 4, false, readAt).
+					WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(updatedAt))
+			},
+			expectedUpdatedAt: updatedAt,
+		},
+		{
+			name: "Changed after read",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE photos").
+					WithArgs(1, "Sunset", "", true, 4, false, readAt).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE photos").
+					WithArgs(1, "Sunset", "", true, 4, false, readAt).
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: errors.New("failed to update photo attributes: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+			tt.mockSetup(mock)
+
+			updatedAt, err := repo.UpdatePhotoAttributes(context.Background(), 1, params)
+			if tt.expectedError != nil {
+				if errors.Is(tt.expectedError, def.NotFoundError) {
+					assert.ErrorIs(t, err, tt.expectedError)
+				} else {
+					assert.EqualError(t, err, tt.expectedError.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedUpdatedAt, updatedAt)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+)
+
+// insertVersionColors сохраняет цвета палитры версии для поиска по цвету.
+// Прежние цвета версии должны быть удалены до вызова.
+func insertVersionColors(ctx context.Context, tx *sql.Tx, versionID int, palette []string) error {
+	colors := repoModel.NewVersionColors(palette)
+	if len(colors) == 0 {
+		return nil
+	}
+
+	positions := make([]int64, len(colors))
+	l, a, b := make([]float64, len(colors)), make([]float64, len(colors)), make([]float64, len(colors))
+	buckets := make([]int64, len(colors))
+	for i, c := range colors {
+		positions[i] = int64(i)
+		l[i], a[i], b[i] = c.Lab.L, c.Lab.A, c.Lab.B
+		buckets[i] = int64(c.Bucket)
+	}
+
+	query := `
+		INSERT INTO photo_version_colors (version_id, position, l, a, b, bucket)
+		SELECT $1, c.position, c.l, c.a, c.b, c.bucket
+		FROM unnest($2::smallint[], $3::real[], $4::real[], $5::real[], $6::integer[]) AS c(position, l, a, b, bucket)`
+
+	_, err := tx.ExecContext(ctx, query, versionID,
+		pq.Int64Array(positions), pq.Float64Array(l), pq.Float64Array(a), pq.Float64Array(b), pq.Int64Array(buckets))
+	if err != nil {
+		return fmt.Errorf("photo version colors %w: %v", repoErr.InsertError, err)
+	}
+
+	return nil
+}
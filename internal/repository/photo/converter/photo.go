@@ -1,18 +1,34 @@
 package converter
 
 import (
+	"database/sql"
+	"github.com/lib/pq"
 	"go-photo/internal/model"
 	repoModel "go-photo/internal/repository/photo/model"
 )
 
 func ToPhotoFromRepo(photo *repoModel.Photo, versions []repoModel.PhotoVersion) *model.Photo {
-	return &model.Photo{
-		ID:         photo.ID,
-		UserUUID:   photo.UserUUID,
-		Filename:   photo.Filename,
-		Versions:   ToPhotoVersionsFromRepo(versions),
-		UploadedAt: photo.UploadedAt.Time,
+	res := &model.Photo{
+		ID:          photo.ID,
+		UserUUID:    photo.UserUUID,
+		Filename:    photo.Filename,
+		Versions:    ToPhotoVersionsFromRepo(versions),
+		DeletedAt:   photo.DeletedAt.Time,
+		Title:       photo.Title.String,
+		Caption:     photo.Caption.String,
+		Favorite:    photo.Favorite,
+		Rating:      photo.Rating,
+		Hidden:      photo.Hidden,
+		UpdatedAt:   photo.UpdatedAt,
+		Placeholder: ToPlaceholderFromRepo(photo.Blurhash, photo.Palette),
+		Location:    ToLocationFromRepo(photo.Latitude, photo.Longitude),
+		TakenAt:     photo.TakenAt.Time,
 	}
+	if photo.UploadedAt != nil {
+		res.UploadedAt = photo.UploadedAt.Time
+	}
+
+	return res
 }
 
 func ToPhotoVersionsFromRepo(versions []repoModel.PhotoVersion) []model.PhotoVersion {
@@ -27,7 +43,7 @@ func ToPhotoVersionsFromRepo(versions []repoModel.PhotoVersion) []model.PhotoVer
 
 func ToPhotoVersionFromRepo(version repoModel.PhotoVersion) model.PhotoVersion {
 	return model.PhotoVersion{
-		ID:           version.PhotoID,
+		ID:           version.ID,
 		PhotoID:      version.PhotoID,
 		VersionType:  model.PhotoVersionType(version.VersionType.String),
 		UUIDFilename: version.UUIDFilename,
@@ -35,5 +51,77 @@ func ToPhotoVersionFromRepo(version repoModel.PhotoVersion) model.PhotoVersion {
 		Height:       version.Height,
 		Width:        version.Width,
 		SavedAt:      version.SavedAt.Time,
+		Revision:     int(version.Revision.Int64),
+		Placeholder:  ToPlaceholderFromRepo(version.Blurhash, version.Palette),
+	}
+}
+
+func ToPlaceholderFromRepo(blurhash sql.NullString, palette pq.StringArray) model.Placeholder {
+	return model.Placeholder{
+		BlurHash: blurhash.String,
+		Palette:  palette,
+	}
+}
+
+func ToLocationFromRepo(latitude, longitude sql.NullFloat64) *model.Location {
+	if !latitude.Valid || !longitude.Valid {
+		return nil
 	}
+
+	return &model.Location{
+		Latitude:  latitude.Float64,
+		Longitude: longitude.Float64,
+	}
+}
+
+func ToPhotoClustersFromRepo(clusters []repoModel.PhotoCluster) []model.PhotoCluster {
+	res := make([]model.PhotoCluster, 0, len(clusters))
+
+	for _, c := range clusters {
+		res = append(res, model.PhotoCluster{
+			Location: model.Location{Latitude: c.Latitude, Longitude: c.Longitude},
+			Count:    c.Count,
+			PhotoID:  c.PhotoID,
+		})
+	}
+
+	return res
+}
+
+func ToTimelineBucketsFromRepo(buckets []repoModel.TimelineBucket) []model.TimelineBucket {
+	res := make([]model.TimelineBucket, 0, len(buckets))
+
+	for _, b := range buckets {
+		res = append(res, model.TimelineBucket{
+			Start:        b.Start,
+			Count:        b.Count,
+			CoverPhotoID: b.CoverPhotoID,
+		})
+	}
+
+	return res
+}
+
+func ToFoundPhotosFromRepo(photos []repoModel.FoundPhoto) []model.FoundPhoto {
+	res := make([]model.FoundPhoto, 0, len(photos))
+
+	for i := range photos {
+		res = append(res, model.FoundPhoto{
+			Photo:     *ToPhotoFromRepo(&photos[i].Photo, nil),
+			Rank:      photos[i].Rank,
+			Highlight: photos[i].Highlight,
+		})
+	}
+
+	return res
+}
+
+func ToPhotosFromRepo(photos []repoModel.Photo) []model.Photo {
+	res := make([]model.Photo, 0, len(photos))
+
+	for i := range photos {
+		res = append(res, *ToPhotoFromRepo(&photos[i], nil))
+	}
+
+	return res
 }
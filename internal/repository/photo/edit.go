@@ -0,0 +1,136 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/logger"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// versionSelectColumns колонки photo_versions, из которых собирается repoModel.PhotoVersion
+const versionSelectColumns = `id, photo_id, version_type, uuid_filename, size, height, width, saved_at, checksum, revision, blurhash, palette`
+
+func (r *repository) GetPhotoEdit(ctx context.Context, photoID int) (_ *repoModel.PhotoEdit, err error) {
+	ctx, span := startSpan(ctx, "GetPhotoEdit", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var edit repoModel.PhotoEdit
+
+	query := `
+		SELECT photo_id, version_id, operations, updated_at
+		FROM photo_edits
+		WHERE photo_id = $1`
+
+	err = r.db.GetContext(ctx, &edit, query, photoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no edits of photo %d", repoErr.NotFoundError, photoID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photo edit: %w", err)
+	}
+
+	return &edit, nil
+}
+
+func (r *repository) SavePhotoEdit(ctx context.Context, params *repoModel.SavePhotoEditParams) (_ int, _ *repoModel.PhotoVersion, err error) {
+	ctx, span := startSpan(ctx, "SavePhotoEdit")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return 0, nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return 0, nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+	span.SetAttributes(attribute.Int("photo.id", params.PhotoID))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", repoErr.BeginTxError, err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.FromContext(ctx).Errorf("failed to rollback transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	// Блокировка фото не дает одновременным правкам создать две отредактированные версии
+	err = lockPhoto(ctx, tx, params.PhotoID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Прежняя версия удаляется вместе с рецептом и вариантами в других форматах
+	var replaced repoModel.PhotoVersion
+	err = tx.GetContext(ctx, &replaced, `
+		DELETE FROM photo_versions
+		WHERE photo_id = $1 AND version_type = 'edited'
+		RETURNING `+versionSelectColumns, params.PhotoID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, fmt.Errorf("failed to delete previous edited version: %w", err)
+	}
+
+	var versionID int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO photo_versions (photo_id, version_type, uuid_filename, size, height, width, saved_at, checksum)
+		VALUES ($1, 'edited', $2, $3, $4, $5, $6, NULLIF($7, ''))
+		RETURNING id`,
+		params.PhotoID,
+		params.UUIDFilename,
+		params.Size,
+		params.Height,
+		params.Width,
+		params.SavedAt,
+		params.Checksum).Scan(&versionID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("edited version %w: %v", repoErr.InsertError, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO photo_edits (photo_id, version_id, operations)
+		VALUES ($1, $2, $3)`, params.PhotoID, versionID, params.Operations)
+	if err != nil {
+		return 0, nil, fmt.Errorf("photo edit %w: %v", repoErr.InsertError, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if replaced.ID == 0 {
+		return versionID, nil, nil
+	}
+	return versionID, &replaced, nil
+}
+
+func (r *repository) DeletePhotoEdit(ctx context.Context, photoID int) (_ *repoModel.PhotoVersion, err error) {
+	ctx, span := startSpan(ctx, "DeletePhotoEdit", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var version repoModel.PhotoVersion
+
+	// Рецепт и варианты версии в других форматах удаляются каскадно
+	query := `
+		DELETE FROM photo_versions
+		WHERE photo_id = $1 AND version_type = 'edited'
+		RETURNING ` + versionSelectColumns
+
+	err = r.db.GetContext(ctx, &version, query, photoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no edited version of photo %d", repoErr.NotFoundError, photoID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete edited version: %w", err)
+	}
+
+	return &version, nil
+}
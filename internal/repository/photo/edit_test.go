@@ -0,0 +1,250 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+var versionWithChecksumColumns = []string{"id", "photo_id", "version_type", "uuid_filename", "size", "height", "width", "saved_at", "checksum"}
+
+func TestRepository_GetPhotoEdit(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	operations := []byte(`[{"type":"grayscale"}]`)
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedEdit  *model.PhotoEdit
+		expectedError error
+	}{
+		{
+			name: "Found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM photo_edits WHERE photo_id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"photo_id", "version_id", "operations", "updated_at"}).
+						AddRow(1, 5, operations, updatedAt))
+			},
+			expectedEdit: &model.PhotoEdit{PhotoID: 1, VersionID: 5, Operations: operations, UpdatedAt: updatedAt},
+		},
+		{
+			name: "Not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM photo_edits`).WithArgs(1).WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			edit, err := repo.GetPhotoEdit(context.Background(), 1)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedEdit, edit)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_SavePhotoEdit(t *testing.T) {
+	savedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	params := &model.SavePhotoEditParams{
+		PhotoID:      1,
+		Operations:   []byte(`[{"type":"grayscale"}]`),
+		UUIDFilename: "edited.jpg",
+		Size:         100,
+		Height:       10,
+		Width:        20,
+		Checksum:     "abc",
+		SavedAt:      savedAt,
+	}
+
+	expectInsert := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`INSERT INTO photo_versions \(photo_id, version_type, .*\) VALUES \(\$1, 'edited'`).
+			WithArgs(1, "edited.jpg", int64(100), 10, 20, savedAt, "abc").
+			WillReturnRows(sqlmock.NewRows(idColumn).AddRow(7))
+		mock.ExpectExec(`INSERT INTO photo_edits`).
+			WithArgs(1, 7, params.Operations).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	tests := []struct {
+		name             string
+		params           *model.SavePhotoEditParams
+		mockSetup        func(mock sqlmock.Sqlmock)
+		expectedID       int
+		expectedReplaced *model.PhotoVersion
+		expectedError    error
+	}{
+		{
+			name:   "First edit",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM photos WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+				mock.ExpectQuery(`DELETE FROM photo_versions WHERE photo_id = \$1 AND version_type = 'edited'`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(versionWithChecksumColumns))
+				expectInsert(mock)
+				mock.ExpectCommit()
+			},
+			expectedID: 7,
+		},
+		{
+			name:   "Replaces previous edit",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM photos`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+				mock.ExpectQuery(`DELETE FROM photo_versions`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(versionWithChecksumColumns).
+						AddRow(6, 1, "edited", "old.jpg", 90, 10, 20, savedAt, nil))
+				expectInsert(mock)
+				mock.ExpectCommit()
+			},
+			expectedID: 7,
+			expectedReplaced: &model.PhotoVersion{
+				ID: 6, PhotoID: 1, VersionType: sql.NullString{String: "edited", Valid: true},
+				UUIDFilename: "old.jpg", Size: 90, Height: 10, Width: 20, SavedAt: &sql.NullTime{Time: savedAt, Valid: true},
+			},
+		},
+		{
+			name:   "Photo not found",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM photos`).WithArgs(1).WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name:   "Insert error",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM photos`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+				mock.ExpectQuery(`DELETE FROM photo_versions`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(versionWithChecksumColumns))
+				mock.ExpectQuery(`INSERT INTO photo_versions`).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			expectedError: def.InsertError,
+		},
+		{
+			name:          "Invalid params",
+			params:        &model.SavePhotoEditParams{PhotoID: 1},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			id, replaced, err := repo.SavePhotoEdit(context.Background(), tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedID, id)
+				assert.Equal(t, tt.expectedReplaced, replaced)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_DeletePhotoEdit(t *testing.T) {
+	savedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		mockSetup       func(mock sqlmock.Sqlmock)
+		expectedVersion *model.PhotoVersion
+		expectedError   error
+	}{
+		{
+			name: "Deleted",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`DELETE FROM photo_versions WHERE photo_id = \$1 AND version_type = 'edited' RETURNING`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(versionWithChecksumColumns).
+						AddRow(6, 1, "edited", "edited.jpg", 90, 10, 20, savedAt, "abc"))
+			},
+			expectedVersion: &model.PhotoVersion{
+				ID: 6, PhotoID: 1, VersionType: sql.NullString{String: "edited", Valid: true},
+				UUIDFilename: "edited.jpg", Size: 90, Height: 10, Width: 20, SavedAt: &sql.NullTime{Time: savedAt, Valid: true},
+				Checksum: sql.NullString{String: "abc", Valid: true},
+			},
+		},
+		{
+			name: "No edited version",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`DELETE FROM photo_versions`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(versionWithChecksumColumns))
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			version, err := repo.DeletePhotoEdit(context.Background(), 1)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedVersion, version)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (r *repository) GetVersionsWithoutExif(ctx context.Context, limit int) (_ []repoModel.PhotoVersionWithOwner, err error) {
+	ctx, span := startSpan(ctx, "GetVersionsWithoutExif")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var versions []repoModel.PhotoVersionWithOwner
+
+	// EXIF нужен только текущему оригиналу: восстановленная ревизия снова станет оригиналом
+	query := `
+		SELECT pv.id, pv.photo_id, pv.version_type, pv.uuid_filename, pv.size, pv.height, pv.width, pv.saved_at,
+		       pv.checksum, p.user_uuid
+		FROM photo_versions pv
+		JOIN photos p ON p.id = pv.photo_id
+		WHERE NOT pv.exif_extracted AND pv.version_type = 'original'
+		ORDER BY pv.id
+		LIMIT $1`
+
+	err = r.db.SelectContext(ctx, &versions, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get versions without exif: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (r *repository) SavePhotoVersionExif(ctx context.Context, versionID int, exif repoModel.PhotoVersionExif) (err error) {
+	ctx, span := startSpan(ctx, "SavePhotoVersionExif", attribute.Int("photo_version.id", versionID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var latitude, longitude sql.NullFloat64
+	if exif.Location != nil {
+		latitude = sql.NullFloat64{Float64: exif.Location.Latitude, Valid: true}
+		longitude = sql.NullFloat64{Float64: exif.Location.Longitude, Valid: true}
+	}
+	var takenAt sql.NullTime
+	if exif.TakenAt != nil {
+		takenAt = sql.NullTime{Time: *exif.TakenAt, Valid: true}
+	}
+
+	query := `
+		UPDATE photo_versions
+		SET latitude = $1, longitude = $2, taken_at = $3, camera_make = NULLIF($4, ''), camera_model = NULLIF($5, ''),
+		    exif_extracted = TRUE
+		WHERE id = $6`
+
+	res, err := r.db.ExecContext(ctx, query, latitude, longitude, takenAt, exif.CameraMake, exif.CameraModel, versionID)
+	if err != nil {
+		return fmt.Errorf("failed to save photo version exif: %w", err)
+	}
+
+	affectedCnt, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+	if affectedCnt < 1 {
+		return fmt.Errorf("%w: no photo version found with id %d", repoErr.NotFoundError, versionID)
+	}
+
+	return nil
+}
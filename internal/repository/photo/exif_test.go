@@ -0,0 +1,115 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+func TestRepository_GetVersionsWithoutExif(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`WHERE NOT pv.exif_extracted AND pv.version_type = 'original' ORDER BY pv.id LIMIT \$1`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "photo_id", "version_type", "uuid_filename", "user_uuid"}).
+			AddRow(3, 1, "original", "a.jpg", "user"))
+
+	repo := NewRepository(sqlx.NewDb(db, "postgres"))
+
+	versions, err := repo.GetVersionsWithoutExif(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.PhotoVersionWithOwner{{
+		PhotoVersion: model.PhotoVersion{ID: 3, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "a.jpg"},
+		UserUUID:     sql.NullString{String: "user", Valid: true},
+	}}, versions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_SavePhotoVersionExif(t *testing.T) {
+	takenAt := time.Date(2024, 7, 14, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		exif          model.PhotoVersionExif
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "Saved",
+			exif: model.PhotoVersionExif{
+				Location:    &model.GeoPoint{Latitude: 55.75, Longitude: 37.61},
+				TakenAt:     &takenAt,
+				CameraMake:  "Canon",
+				CameraModel: "Canon EOS R6",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE photo_versions SET latitude = \$1, longitude = \$2, taken_at = \$3, `+
+					`camera_make = NULLIF\(\$4, ''\), camera_model = NULLIF\(\$5, ''\), exif_extracted = TRUE WHERE id = \$6`).
+					WithArgs(55.75, 37.61, takenAt, "Canon", "Canon EOS R6", 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Location only",
+			exif: model.PhotoVersionExif{Location: &model.GeoPoint{Latitude: 55.75, Longitude: 37.61}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE photo_versions`).
+					WithArgs(55.75, 37.61, nil, "", "", 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "No EXIF",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE photo_versions`).
+					WithArgs(nil, nil, nil, "", "", 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE photo_versions`).
+					WithArgs(nil, nil, nil, "", "", 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Update error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE photo_versions`).WillReturnError(errors.New("update error"))
+			},
+			expectedError: errors.New("update error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			err = repo.SavePhotoVersionExif(context.Background(), 3, tt.exif)
+			if tt.expectedError != nil {
+				assert.ErrorContains(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
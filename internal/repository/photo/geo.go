@@ -0,0 +1,56 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+)
+
+func (r *repository) GetUserPhotoClusters(ctx context.Context, userUUID string, params *repoModel.PhotoClusterParams) (_ []repoModel.PhotoCluster, err error) {
+	ctx, span := startSpan(ctx, "GetUserPhotoClusters")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	clusters := []repoModel.PhotoCluster{}
+
+	// Ячейку представляет избранное фото с наибольшей оценкой, из равных - загруженное последним
+	query := `
+		SELECT count(*) AS count,
+		       avg(pv.latitude) AS latitude,
+		       avg(pv.longitude) AS longitude,
+		       (array_agg(p.id ORDER BY p.favorite DESC, p.rating DESC, p.uploaded_at DESC, p.id DESC))[1] AS photo_id
+		FROM photos p
+		JOIN photo_versions pv ON pv.photo_id = p.id AND pv.version_type = 'original'
+		WHERE p.user_uuid = :user_uuid AND p.deleted_at IS NULL AND NOT p.hidden
+		  AND pv.latitude IS NOT NULL`
+
+	queryParams := map[string]interface{}{
+		"user_uuid": userUUID,
+		"cell_size": params.CellSize,
+	}
+	query += `
+		  AND ` + params.BBox.MapToArgs("point(pv.longitude, pv.latitude)", queryParams) + `
+		GROUP BY floor(pv.longitude / :cell_size), floor(pv.latitude / :cell_size)
+		ORDER BY count DESC, photo_id`
+
+	namedQuery, args, err := sqlx.Named(query, queryParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare query: %w", err)
+	}
+
+	err = r.db.SelectContext(ctx, &clusters, r.db.Rebind(namedQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user photo clusters: %w", err)
+	}
+
+	return clusters, nil
+}
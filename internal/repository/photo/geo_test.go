@@ -0,0 +1,84 @@
+package photo
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+)
+
+func TestRepository_GetUserPhotoClusters(t *testing.T) {
+	clusterColumns := []string{"count", "latitude", "longitude", "photo_id"}
+
+	tests := []struct {
+		name             string
+		params           *model.PhotoClusterParams
+		mockSetup        func(mock sqlmock.Sqlmock)
+		expectedClusters []model.PhotoCluster
+		expectedError    error
+	}{
+		{
+			name:   "Clusters",
+			params: &model.PhotoClusterParams{BBox: model.BBox{West: 30, South: 50, East: 40, North: 60}, CellSize: 0.5},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE p.user_uuid = \$1 AND p.deleted_at IS NULL AND NOT p.hidden AND pv.latitude IS NOT NULL `+
+					`AND point\(pv.longitude, pv.latitude\) <@ box\(point\(\$2, \$3\), point\(\$4, \$5\)\) `+
+					`GROUP BY floor\(pv.longitude / \$6\), floor\(pv.latitude / \$7\) ORDER BY count DESC, photo_id`).
+					WithArgs("user-uuid", 30.0, 50.0, 40.0, 60.0, 0.5, 0.5).
+					WillReturnRows(sqlmock.NewRows(clusterColumns).
+						AddRow(12, 55.75, 37.61, 7).
+						AddRow(1, 59.93, 30.33, 3))
+			},
+			expectedClusters: []model.PhotoCluster{
+				{Count: 12, Latitude: 55.75, Longitude: 37.61, PhotoID: 7},
+				{Count: 1, Latitude: 59.93, Longitude: 30.33, PhotoID: 3},
+			},
+		},
+		{
+			name:   "Across antimeridian",
+			params: &model.PhotoClusterParams{BBox: model.BBox{West: 170, South: -50, East: -170, North: -30}, CellSize: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AND \(point\(pv.longitude, pv.latitude\) <@ box\(point\(\$2, \$3\), point\(180, \$4\)\) `+
+					`OR point\(pv.longitude, pv.latitude\) <@ box\(point\(-180, \$5\), point\(\$6, \$7\)\)\) GROUP BY`).
+					WithArgs("user-uuid", 170.0, -50.0, -30.0, -50.0, -170.0, -30.0, 10.0, 10.0).
+					WillReturnRows(sqlmock.NewRows(clusterColumns))
+			},
+			expectedClusters: []model.PhotoCluster{},
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+		{
+			name:          "No cell size",
+			params:        &model.PhotoClusterParams{BBox: model.BBox{West: 30, South: 50, East: 40, North: 60}},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			clusters, err := repo.GetUserPhotoClusters(context.Background(), "user-uuid", tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedClusters, clusters)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
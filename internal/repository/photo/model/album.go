@@ -0,0 +1,38 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Album альбом: фото владельца отбираются при чтении по сохраненным фильтрам, добавленные фото
+// хранятся в album_photos.
+type Album struct {
+	ID       int    `db:"id"`
+	UserUUID string `db:"user_uuid"`
+	Title    string `db:"title"`
+	// Filter фильтры списка фото в JSON
+	Filter []byte `db:"filter"`
+	// Manual ручной альбом: фото владельца отбираются только непустым фильтром
+	Manual bool `db:"manual"`
+	// PublicToken не заполнен, пока альбом не опубликован
+	PublicToken sql.NullString `db:"public_token"`
+	// MetadataPolicy политика метаданных файлов опубликованного альбома
+	MetadataPolicy sql.NullString `db:"metadata_policy"`
+	// Watermark файлы опубликованного альбома отдаются с водяным знаком владельца альбома
+	Watermark bool      `db:"watermark"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// SaveAlbumParams название и фильтры нового или изменяемого альбома.
+type SaveAlbumParams struct {
+	Title  string
+	Filter []byte
+	Manual bool
+}
+
+func (p *SaveAlbumParams) IsValid() bool {
+	return p.Title != "" && json.Valid(p.Filter)
+}
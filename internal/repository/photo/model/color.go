@@ -0,0 +1,93 @@
+package model
+
+import (
+	"go-photo/pkg/imaging"
+	"math"
+)
+
+// Сетка, по ячейкам которой индексируются цвета. Все цвета sRGB укладываются
+// в L от 0 до 100 и a, b от -128 до 128.
+const (
+	colorBucketSize   = 10
+	colorBucketOffset = 128
+	colorBucketsL     = 100/colorBucketSize + 1
+	colorBucketsAB    = 2*colorBucketOffset/colorBucketSize + 1
+)
+
+// ColorFilter оставляет фото, один из преобладающих цветов оригинала которых
+// отличается от Color не больше чем на Tolerance (CIE76).
+type ColorFilter struct {
+	Color     imaging.Lab
+	Tolerance float64
+}
+
+// VersionColor преобладающий цвет версии фото, как он хранится для поиска.
+type VersionColor struct {
+	Lab    imaging.Lab
+	Bucket int
+}
+
+// NewVersionColors переводит палитру версии (#rrggbb) в цвета для поиска.
+// Цвета, которые не удалось разобрать, пропускаются.
+func NewVersionColors(palette []string) []VersionColor {
+	colors := make([]VersionColor, 0, len(palette))
+	for _, hex := range palette {
+		c, err := imaging.ParseHexColor(hex)
+		if err != nil {
+			continue
+		}
+		lab := imaging.ToLab(c)
+		colors = append(colors, VersionColor{Lab: lab, Bucket: ColorBucket(lab)})
+	}
+	return colors
+}
+
+// ColorBucket возвращает номер ячейки сетки, в которую попадает цвет.
+func ColorBucket(c imaging.Lab) int {
+	l, a, b := colorCell(c.L, 0, colorBucketsL), colorCell(c.A, colorBucketOffset, colorBucketsAB), colorCell(c.B, colorBucketOffset, colorBucketsAB)
+	return (l*colorBucketsAB+a)*colorBucketsAB + b
+}
+
+// BucketsWithin возвращает номера ячеек, в которых могут быть цвета не дальше Tolerance от Color.
+func (f *ColorFilter) BucketsWithin() []int {
+	var buckets []int
+
+	lFrom, lTo := colorCell(f.Color.L-f.Tolerance, 0, colorBucketsL), colorCell(f.Color.L+f.Tolerance, 0, colorBucketsL)
+	aFrom, aTo := colorCell(f.Color.A-f.Tolerance, colorBucketOffset, colorBucketsAB), colorCell(f.Color.A+f.Tolerance, colorBucketOffset, colorBucketsAB)
+	bFrom, bTo := colorCell(f.Color.B-f.Tolerance, colorBucketOffset, colorBucketsAB), colorCell(f.Color.B+f.Tolerance, colorBucketOffset, colorBucketsAB)
+
+	for l := lFrom; l <= lTo; l++ {
+		for a := aFrom; a <= aTo; a++ {
+			for b := bFrom; b <= bTo; b++ {
+				// Ячейки в углах куба вокруг цвета могут не пересекаться со сферой допуска
+				dl := cellDistance(f.Color.L, l, 0)
+				da := cellDistance(f.Color.A, a, colorBucketOffset)
+				db := cellDistance(f.Color.B, b, colorBucketOffset)
+				if dl*dl+da*da+db*db <= f.Tolerance*f.Tolerance {
+					buckets = append(buckets, (l*colorBucketsAB+a)*colorBucketsAB+b)
+				}
+			}
+		}
+	}
+
+	return buckets
+}
+
+func colorCell(v, offset float64, cells int) int {
+	cell := int(math.Floor((v + offset) / colorBucketSize))
+	return max(0, min(cells-1, cell))
+}
+
+// cellDistance расстояние по одной оси от значения v до ближайшей точки ячейки cell.
+func cellDistance(v float64, cell int, offset float64) float64 {
+	from := float64(cell*colorBucketSize) - offset
+	to := from + colorBucketSize
+	switch {
+	case v < from:
+		return from - v
+	case v > to:
+		return v - to
+	default:
+		return 0
+	}
+}
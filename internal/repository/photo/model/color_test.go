@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-photo/pkg/imaging"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+func TestColorFilter_BucketsWithin(t *testing.T) {
+	query := imaging.ToLab(color.RGBA{R: 0x33, G: 0x66, B: 0xCC, A: 255})
+
+	for _, tolerance := range []float64{1, 10, 25} {
+		filter := &ColorFilter{Color: query, Tolerance: tolerance}
+		buckets := filter.BucketsWithin()
+
+		// Цвет в пределах допуска всегда попадает в одну из ячеек
+		for r := 0; r < 256; r += 5 {
+			for g := 0; g < 256; g += 5 {
+				for b := 0; b < 256; b += 5 {
+					lab := imaging.ToLab(color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255})
+					if query.DeltaE(lab) <= tolerance && !slices.Contains(buckets, ColorBucket(lab)) {
+						t.Fatalf("color #%02x%02x%02x within %.0f is not in buckets", r, g, b, tolerance)
+					}
+				}
+			}
+		}
+	}
+
+	// Из куба 3x3x3 вокруг цвета отбрасываются два дальних угла
+	filter := &ColorFilter{Color: imaging.Lab{L: 55, A: 5, B: 5}, Tolerance: 10}
+	assert.Len(t, filter.BucketsWithin(), 3*3*3-2)
+}
+
+func TestNewVersionColors(t *testing.T) {
+	colors := NewVersionColors([]string{"#ffffff", "bad", "#000000"})
+
+	assert.Len(t, colors, 2)
+	assert.InDelta(t, 100, colors[0].Lab.L, 0.01)
+	assert.Equal(t, ColorBucket(colors[0].Lab), colors[0].Bucket)
+	assert.Equal(t, ColorBucket(imaging.Lab{}), colors[1].Bucket)
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// PhotoEdit рецепт правок фото и его отредактированная версия.
+type PhotoEdit struct {
+	PhotoID   int `db:"photo_id"`
+	VersionID int `db:"version_id"`
+	// Operations операции правок в JSON в порядке применения к оригиналу
+	Operations []byte    `db:"operations"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// SavePhotoEditParams описывает рецепт правок и файл версии, полученный по нему.
+type SavePhotoEditParams struct {
+	PhotoID      int
+	Operations   []byte
+	UUIDFilename string
+	Size         int64
+	Height       int
+	Width        int
+	Checksum     string
+	SavedAt      time.Time
+}
+
+func (p *SavePhotoEditParams) IsValid() bool {
+	return p.PhotoID > 0 && len(p.Operations) > 0 && p.UUIDFilename != "" && p.Size > 0 && p.Height > 0 && p.Width > 0 && !p.SavedAt.IsZero()
+}
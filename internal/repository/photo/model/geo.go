@@ -0,0 +1,52 @@
+package model
+
+// GeoPoint координаты в градусах WGS 84.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// BBox прямоугольник на карте в градусах. Если West больше East, прямоугольник пересекает 180-й меридиан.
+type BBox struct {
+	West  float64
+	South float64
+	East  float64
+	North float64
+}
+
+// MapToArgs возвращает условие попадания точки point (выражение вида point(долгота, широта)) в прямоугольник.
+func (b *BBox) MapToArgs(point string, params map[string]interface{}) string {
+	params["bbox_west"] = b.West
+	params["bbox_south"] = b.South
+	params["bbox_east"] = b.East
+	params["bbox_north"] = b.North
+
+	if b.West <= b.East {
+		return point + ` <@ box(point(:bbox_west, :bbox_south), point(:bbox_east, :bbox_north))`
+	}
+
+	// Прямоугольник через 180-й меридиан делится на два
+	return `(` + point + ` <@ box(point(:bbox_west, :bbox_south), point(180, :bbox_north))` +
+		` OR ` + point + ` <@ box(point(-180, :bbox_south), point(:bbox_east, :bbox_north)))`
+}
+
+// PhotoClusterParams область карты и размер ячеек, по которым группируются фото.
+type PhotoClusterParams struct {
+	BBox BBox
+	// CellSize сторона ячейки сетки в градусах
+	CellSize float64
+}
+
+func (p *PhotoClusterParams) IsValid() bool {
+	return p.CellSize > 0
+}
+
+// PhotoCluster фото, попавшие в одну ячейку сетки.
+type PhotoCluster struct {
+	Count int `db:"count"`
+	// Latitude и Longitude среднее положение фото ячейки
+	Latitude  float64 `db:"latitude"`
+	Longitude float64 `db:"longitude"`
+	// PhotoID фото, которое представляет ячейку на карте
+	PhotoID int `db:"photo_id"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PendingUpload запись журнала незавершенной загрузки.
+// PhotoID заполнен, если фото уже сохранено в БД, но файл еще не перенесен из staging.
+type PendingUpload struct {
+	ID           int           `db:"id"`
+	UserUUID     string        `db:"user_uuid"`
+	UUIDFilename string        `db:"uuid_filename"`
+	PhotoID      sql.NullInt64 `db:"photo_id"`
+	CreatedAt    time.Time     `db:"created_at"`
+}
+
+// IsCommitted сообщает, сохранено ли фото в БД.
+func (p *PendingUpload) IsCommitted() bool {
+	return p.PhotoID.Valid
+}
+
+type CreatePendingUploadParams struct {
+	UserUUID     string
+	UUIDFilename string
+}
+
+func (p *CreatePendingUploadParams) IsValid() bool {
+	return p.UserUUID != "" && p.UUIDFilename != ""
+}
@@ -2,7 +2,9 @@ package model
 
 import (
 	"database/sql"
+	"github.com/lib/pq"
 	"go-photo/internal/model"
+	"strings"
 	"time"
 )
 
@@ -11,6 +13,28 @@ type Photo struct {
 	UserUUID   string        `db:"user_uuid"`
 	Filename   string        `db:"filename"`
 	UploadedAt *sql.NullTime `db:"uploaded_at"`
+	// DeletedAt время переноса в корзину, не заполнено у неудаленных фото
+	DeletedAt sql.NullTime   `db:"deleted_at"`
+	Title     sql.NullString `db:"title"`
+	Caption   sql.NullString `db:"caption"`
+	Favorite  bool           `db:"favorite"`
+	Rating    int            `db:"rating"`
+	Hidden    bool           `db:"hidden"`
+	// UpdatedAt время последнего изменения атрибутов
+	UpdatedAt time.Time `db:"updated_at"`
+	// Blurhash и Palette заглушка оригинала, заполняются только в списке фото пользователя
+	Blurhash sql.NullString `db:"blurhash"`
+	Palette  pq.StringArray `db:"palette"`
+	// Latitude и Longitude координаты съемки оригинала, заполняются только в списке фото пользователя
+	Latitude  sql.NullFloat64 `db:"latitude"`
+	Longitude sql.NullFloat64 `db:"longitude"`
+	// TakenAt местное время съемки оригинала из EXIF, заполняется только в списках фото пользователя
+	TakenAt sql.NullTime `db:"taken_at"`
+}
+
+// IsTrashed сообщает, находится ли фото в корзине.
+func (p *Photo) IsTrashed() bool {
+	return p.DeletedAt.Valid
 }
 
 type PhotoVersion struct {
@@ -22,12 +46,50 @@ type PhotoVersion struct {
 	Height       int            `db:"height"`
 	Width        int            `db:"width"`
 	SavedAt      *sql.NullTime  `db:"saved_at"`
+	// Checksum SHA-256 файла в hex, не заполнен у старых версий
+	Checksum sql.NullString `db:"checksum"`
+	// Revision номер ревизии оригинала, не заполнен у производных и отредактированных версий
+	Revision sql.NullInt64 `db:"revision"`
+	// Blurhash заглушка версии, не заполнена, пока не рассчитана. Пустая строка - файл не удалось разобрать.
+	Blurhash sql.NullString `db:"blurhash"`
+	// Palette преобладающие цвета версии в виде #rrggbb, начиная с самого частого
+	Palette pq.StringArray `db:"palette"`
+}
+
+// PhotoVersionWithOwner версия фото вместе с владельцем.
+// UserUUID не заполнен, если фото версии не существует.
+type PhotoVersionWithOwner struct {
+	PhotoVersion
+	UserUUID sql.NullString `db:"user_uuid"`
+}
+
+// UpdatePhotoVersionFileParams описывает файл, заново записанный для существующей версии.
+type UpdatePhotoVersionFileParams struct {
+	Size     int64
+	Height   int
+	Width    int
+	Checksum string
+	SavedAt  time.Time
 }
 
 type PublishedPhotoInfo struct {
 	ID          int          `db:"id"`
 	PublishedAt sql.NullTime `db:"published_at"`
 	PublicToken string       `db:"public_token"`
+	// Watermark файлы публикации отдаются с водяным знаком владельца
+	Watermark bool `db:"watermark"`
+	// MetadataPolicy какие метаданные удаляются из файлов публикации: keep, strip_private или strip_all
+	MetadataPolicy string `db:"metadata_policy"`
+}
+
+type CreatePhotoPublishedInfoParams struct {
+	PhotoID        int
+	Watermark      bool
+	MetadataPolicy string
+}
+
+func (p *CreatePhotoPublishedInfoParams) IsValid() bool {
+	return p.PhotoID > 0 && p.MetadataPolicy != ""
 }
 
 type PhotoWithPhotoVersion struct {
@@ -52,6 +114,164 @@ type CreateOriginalPhotoParams struct {
 	Height       int
 	Width        int
 	SavedAt      time.Time
+	// Checksum SHA-256 файла в hex
+	Checksum string
+	// Blurhash и Palette заглушка оригинала. Пустой Blurhash сохраняется как NULL,
+	// и заглушку рассчитает фоновая задача.
+	Blurhash string
+	Palette  []string
+	// Location координаты съемки из EXIF, nil - в файле их нет
+	Location *GeoPoint
+	// TakenAt местное время съемки из EXIF, nil - в файле его нет
+	TakenAt *time.Time
+	// CameraMake и CameraModel производитель и модель камеры из EXIF, пустые сохраняются как NULL
+	CameraMake  string
+	CameraModel string
+	// ExifUnknown файл не удалось прочитать, EXIF прочитает фоновая задача
+	ExifUnknown bool
+	// PendingUploadID запись журнала загрузки, которая помечается сохраненной в той же транзакции.
+	// 0, если загрузка не журналируется.
+	PendingUploadID int
+}
+
+// PhotoVersionExif сведения о съемке, прочитанные из EXIF версии. nil и пустые поля - в файле их нет.
+type PhotoVersionExif struct {
+	Location    *GeoPoint
+	TakenAt     *time.Time
+	CameraMake  string
+	CameraModel string
+}
+
+// UpdatePhotoAttributesParams новые значения атрибутов фото.
+// Пустые Title и Caption сохраняются как NULL.
+type UpdatePhotoAttributesParams struct {
+	Title    string
+	Caption  string
+	Favorite bool
+	Rating   int
+	Hidden   bool
+	// UpdatedAt значение updated_at, с которым были прочитаны атрибуты.
+	// Если фото изменилось после чтения, обновление не выполняется.
+	UpdatedAt time.Time
+}
+
+// LocalTakenAt местное время съемки фото из photos. EXIF хранит местное время камеры, а время загрузки
+// фото без EXIF считается временем UTC и переводится в часовой пояс :time_zone.
+const LocalTakenAt = `COALESCE(
+	(SELECT pv.taken_at FROM photo_versions pv WHERE pv.photo_id = photos.id AND pv.version_type = 'original'),
+	photos.uploaded_at AT TIME ZONE 'UTC' AT TIME ZONE :time_zone)`
+
+// PhotoListParams фильтры и пагинация списка фото пользователя. nil фильтр не применяется.
+// Условия MapToArgs рассчитаны на запрос к photos без псевдонима.
+type PhotoListParams struct {
+	Favorite  *bool
+	Hidden    *bool
+	MinRating *int
+	MaxRating *int
+	Color     *ColorFilter
+	// BBox оставляет фото, снятые внутри прямоугольника
+	BBox *BBox
+	// TakenFrom и TakenBefore ограничивают время съемки оригинала, TakenBefore не включается.
+	// Фото без времени съемки в EXIF отбираются по времени загрузки в часовом поясе TimeZone
+	TakenFrom   *time.Time
+	TakenBefore *time.Time
+	// TimeZone часовой пояс IANA для TakenFrom и TakenBefore, пустая строка - UTC
+	TimeZone string
+	// Camera подстрока производителя или модели камеры оригинала без учета регистра
+	Camera *string
+	// Published оставляет опубликованные (true) или неопубликованные (false) фото
+	Published *bool
+	// Tag оставляет фото с этим нормализованным тегом
+	Tag *string
+	// PhotoID оставляет только фото с этим ID
+	PhotoID *int
+	Limit   int
+	Offset  int
+}
+
+func (p *PhotoListParams) MapToArgs(params map[string]interface{}) string {
+	addQuery := ""
+
+	if p.Favorite != nil {
+		addQuery += " AND favorite = :favorite"
+		params["favorite"] = *p.Favorite
+	}
+	if p.Hidden != nil {
+		addQuery += " AND hidden = :hidden"
+		params["hidden"] = *p.Hidden
+	}
+	if p.MinRating != nil {
+		addQuery += " AND rating >= :min_rating"
+		params["min_rating"] = *p.MinRating
+	}
+	if p.MaxRating != nil {
+		addQuery += " AND rating <= :max_rating"
+		params["max_rating"] = *p.MaxRating
+	}
+	if p.Color != nil {
+		// Индекс по ячейкам отбирает кандидатов, расстояние проверяется точно
+		addQuery += ` AND id IN (
+			SELECT pv.photo_id
+			FROM photo_version_colors pvc
+			JOIN photo_versions pv ON pv.id = pvc.version_id AND pv.version_type = 'original'
+			WHERE pvc.bucket = ANY(:color_buckets)
+			  AND power(pvc.l - :color_l, 2) + power(pvc.a - :color_a, 2) + power(pvc.b - :color_b, 2) <= :color_tolerance_sq)`
+		params["color_buckets"] = pq.Array(p.Color.BucketsWithin())
+		params["color_l"] = p.Color.Color.L
+		params["color_a"] = p.Color.Color.A
+		params["color_b"] = p.Color.Color.B
+		params["color_tolerance_sq"] = p.Color.Tolerance * p.Color.Tolerance
+	}
+	if p.BBox != nil {
+		addQuery += ` AND id IN (
+			SELECT pv.photo_id
+			FROM photo_versions pv
+			WHERE pv.version_type = 'original' AND pv.latitude IS NOT NULL
+			  AND ` + p.BBox.MapToArgs("point(pv.longitude, pv.latitude)", params) + `)`
+	}
+	if p.TakenFrom != nil || p.TakenBefore != nil {
+		params["time_zone"] = "UTC"
+		if p.TimeZone != "" {
+			params["time_zone"] = p.TimeZone
+		}
+		if p.TakenFrom != nil {
+			addQuery += " AND " + LocalTakenAt + " >= :taken_from"
+			params["taken_from"] = *p.TakenFrom
+		}
+		if p.TakenBefore != nil {
+			addQuery += " AND " + LocalTakenAt + " < :taken_before"
+			params["taken_before"] = *p.TakenBefore
+		}
+	}
+	if p.Camera != nil {
+		addQuery += ` AND id IN (
+			SELECT pv.photo_id
+			FROM photo_versions pv
+			WHERE pv.version_type = 'original' AND concat_ws(' ', pv.camera_make, pv.camera_model) ILIKE :camera)`
+		params["camera"] = "%" + escapeLike(*p.Camera) + "%"
+	}
+	if p.Published != nil {
+		if *p.Published {
+			addQuery += " AND id IN (SELECT photo_id FROM published_photo_info)"
+		} else {
+			addQuery += " AND id NOT IN (SELECT photo_id FROM published_photo_info)"
+		}
+	}
+	if p.Tag != nil {
+		addQuery += " AND id IN (SELECT pt.photo_id FROM photo_tags pt WHERE pt.tag = :tag)"
+		params["tag"] = *p.Tag
+	}
+	if p.PhotoID != nil {
+		addQuery += " AND id = :photo_id"
+		params["photo_id"] = *p.PhotoID
+	}
+
+	return addQuery
+}
+
+// escapeLike экранирует символы шаблона LIKE, чтобы строка искалась как есть.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 type FilterParams struct {
@@ -73,3 +293,7 @@ func (f *FilterParams) MapToArgs(params map[string]interface{}) string {
 func (p *CreateOriginalPhotoParams) IsValid() bool {
 	return p.UserUUID != "" && p.Filename != "" && p.UUIDFilename != "" && p.Size > 0 && p.Height > 0 && p.Width > 0 && !p.SavedAt.IsZero()
 }
+
+func (p *UpdatePhotoVersionFileParams) IsValid() bool {
+	return p.Size > 0 && p.Height > 0 && p.Width > 0 && p.Checksum != "" && !p.SavedAt.IsZero()
+}
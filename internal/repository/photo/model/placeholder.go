@@ -0,0 +1,12 @@
+package model
+
+// SavePhotoVersionPlaceholderParams рассчитанная заглушка версии фото.
+// Пустой Blurhash без палитры означает, что файл не удалось разобрать, и заглушка больше не рассчитывается.
+type SavePhotoVersionPlaceholderParams struct {
+	Blurhash string
+	Palette  []string
+}
+
+func (p *SavePhotoVersionPlaceholderParams) IsValid() bool {
+	return p.Blurhash != "" || len(p.Palette) == 0
+}
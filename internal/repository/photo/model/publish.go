@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// PublishSettings настройки публикации пользователя, применяемые по умолчанию.
+type PublishSettings struct {
+	UserUUID string `db:"user_uuid"`
+	// MetadataPolicy keep, strip_private или strip_all
+	MetadataPolicy string    `db:"metadata_policy"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+type SavePublishSettingsParams struct {
+	UserUUID       string
+	MetadataPolicy string
+}
+
+func (p *SavePublishSettingsParams) IsValid() bool {
+	return p.UserUUID != "" && p.MetadataPolicy != ""
+}
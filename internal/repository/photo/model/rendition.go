@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// PhotoVersionRendition файл версии фото, перекодированный в другой формат
+// или очищенный от метаданных для публикации.
+type PhotoVersionRendition struct {
+	ID        int `db:"id"`
+	VersionID int `db:"version_id"`
+	// Format формат варианта, для копии без метаданных - политика метаданных (strip_private, strip_all)
+	Format       string    `db:"format"`
+	UUIDFilename string    `db:"uuid_filename"`
+	Size         int64     `db:"size"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+type SavePhotoVersionRenditionParams struct {
+	VersionID    int
+	Format       string
+	UUIDFilename string
+	Size         int64
+}
+
+func (p *SavePhotoVersionRenditionParams) IsValid() bool {
+	return p.VersionID > 0 && p.Format != "" && p.UUIDFilename != "" && p.Size > 0
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// CreatePhotoRevisionParams описывает файл новой ревизии оригинала фото.
+type CreatePhotoRevisionParams struct {
+	PhotoID      int
+	UUIDFilename string
+	Size         int64
+	Height       int
+	Width        int
+	SavedAt      time.Time
+	// Checksum SHA-256 файла в hex
+	Checksum string
+	// PendingUploadID запись журнала загрузки, которая помечается сохраненной в той же транзакции.
+	// 0, если загрузка не журналируется.
+	PendingUploadID int
+}
+
+func (p *CreatePhotoRevisionParams) IsValid() bool {
+	return p.PhotoID > 0 && p.UUIDFilename != "" && p.Size > 0 && p.Height > 0 && p.Width > 0 && !p.SavedAt.IsZero()
+}
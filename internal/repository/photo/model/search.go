@@ -0,0 +1,44 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PhotoSearchParams параметры полнотекстового поиска фото. Фильтры и пагинация те же, что у списка фото.
+type PhotoSearchParams struct {
+	// Terms слова запроса из букв и цифр. Фото должно содержать все слова, каждое ищется как начало слова
+	Terms []string
+	PhotoListParams
+}
+
+func (p *PhotoSearchParams) IsValid() bool {
+	if len(p.Terms) == 0 {
+		return false
+	}
+	for _, term := range p.Terms {
+		if term == "" || strings.IndexFunc(term, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TSQuery возвращает запрос to_tsquery: все слова с поиском по префиксу.
+// Слова состоят только из букв и цифр, поэтому не содержат операторов tsquery.
+func (p *PhotoSearchParams) TSQuery() string {
+	terms := make([]string, 0, len(p.Terms))
+	for _, term := range p.Terms {
+		terms = append(terms, term+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// FoundPhoto фото, найденное полнотекстовым поиском.
+type FoundPhoto struct {
+	Photo
+	// Rank релевантность фото запросу, больше - релевантнее
+	Rank float64 `db:"rank"`
+	// Highlight фрагменты названия, подписи и имени файла с выделенными совпадениями
+	Highlight string `db:"highlight"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Share доступ пользователя к фото или альбому.
+type Share struct {
+	GranteeUUID string `db:"grantee_uuid"`
+	// Role viewer или contributor, contributor только для альбомов
+	Role      string    `db:"role"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// SharedAlbum альбом другого пользователя с ролью, выданной пользователю.
+type SharedAlbum struct {
+	Album
+	Role string `db:"role"`
+}
+
+type SaveShareParams struct {
+	GranteeUUID string
+	Role        string
+}
+
+func (p *SaveShareParams) IsValid() bool {
+	return p.GranteeUUID != "" && p.Role != ""
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// TimelineParams параметры группировки фото по периодам.
+type TimelineParams struct {
+	// Granularity единица date_trunc: day, month или year
+	Granularity string
+	// TimeZone часовой пояс IANA, в котором время загрузки переводится в местное
+	TimeZone string
+	// Before оставляет периоды, начинающиеся не позже Before
+	Before *time.Time
+	Limit  int
+}
+
+func (p *TimelineParams) IsValid() bool {
+	switch p.Granularity {
+	case "day", "month", "year":
+	default:
+		return false
+	}
+	return p.TimeZone != "" && p.Limit > 0
+}
+
+// TimelineBucket фото, снятые за один период.
+type TimelineBucket struct {
+	// Start начало периода по местному времени
+	Start time.Time `db:"start"`
+	Count int       `db:"count"`
+	// CoverPhotoID фото, которое представляет период
+	CoverPhotoID int `db:"cover_photo_id"`
+}
+
+// OnThisDayParams параметры поиска фото, снятых в тот же день в прошлые годы.
+type OnThisDayParams struct {
+	TimeZone string
+	// Date день по местному времени, время суток не учитывается
+	Date  time.Time
+	Limit int
+}
+
+func (p *OnThisDayParams) IsValid() bool {
+	return p.TimeZone != "" && !p.Date.IsZero() && p.Limit > 0
+}
+
+// Days возвращает дни в формате MM-DD, фото которых относятся к Date.
+// 28 февраля невисокосного года забирает и фото, снятые 29 февраля.
+func (p *OnThisDayParams) Days() []string {
+	days := []string{p.Date.Format("01-02")}
+	if p.Date.Month() == time.February && p.Date.Day() == 28 && p.Date.AddDate(0, 0, 1).Month() == time.March {
+		days = append(days, "02-29")
+	}
+	return days
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// WatermarkSettings настройки водяного знака пользователя.
+type WatermarkSettings struct {
+	UserUUID string `db:"user_uuid"`
+	// Type text или logo
+	Type string         `db:"type"`
+	Text sql.NullString `db:"text"`
+	// LogoFilename имя файла логотипа в папке водяных знаков, не заполнено, пока логотип не загружен
+	LogoFilename sql.NullString `db:"logo_filename"`
+	Position     string         `db:"position"`
+	Opacity      int            `db:"opacity"`
+	Scale        int            `db:"scale"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+}
+
+// SaveWatermarkSettingsParams новые настройки водяного знака. Логотип сохраняется отдельно.
+// Пустой Text сохраняется как NULL.
+type SaveWatermarkSettingsParams struct {
+	UserUUID string
+	Type     string
+	Text     string
+	Position string
+	Opacity  int
+	Scale    int
+}
+
+func (p *SaveWatermarkSettingsParams) IsValid() bool {
+	return p.UserUUID != "" && p.Type != "" && p.Position != "" && p.Opacity > 0 && p.Scale > 0
+}
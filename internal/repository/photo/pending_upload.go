@@ -0,0 +1,121 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (r *repository) CreatePendingUpload(ctx context.Context, params *repoModel.CreatePendingUploadParams) (_ int, err error) {
+	ctx, span := startSpan(ctx, "CreatePendingUpload")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return 0, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return 0, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	query := `
+		INSERT INTO pending_uploads (user_uuid, uuid_filename)
+		VALUES ($1, $2)
+		RETURNING id`
+
+	var id int
+	err = r.db.QueryRowContext(ctx, query, params.UserUUID, params.UUIDFilename).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("pending upload %w: %v", repoErr.InsertError, err)
+	}
+
+	return id, nil
+}
+
+func (r *repository) GetPendingUploads(ctx context.Context) (_ []repoModel.PendingUpload, err error) {
+	ctx, span := startSpan(ctx, "GetPendingUploads")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var uploads []repoModel.PendingUpload
+
+	query := `
+		SELECT id, user_uuid, uuid_filename, photo_id, created_at
+		FROM pending_uploads
+		ORDER BY id`
+
+	err = r.db.SelectContext(ctx, &uploads, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending uploads: %w", err)
+	}
+
+	return uploads, nil
+}
+
+func (r *repository) DeletePendingUpload(ctx context.Context, id int) (err error) {
+	ctx, span := startSpan(ctx, "DeletePendingUpload", attribute.Int("pending_upload.id", id))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		DELETE FROM pending_uploads
+		WHERE id = $1`
+
+	return r.execAffectingOne(ctx, query, id)
+}
+
+func (r *repository) DeleteUncommittedPendingUpload(ctx context.Context, id int) (err error) {
+	ctx, span := startSpan(ctx, "DeleteUncommittedPendingUpload", attribute.Int("pending_upload.id", id))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Условие на photo_id проверяется после снятия блокировки строки, поэтому загрузка,
+	// которая успела сохранить фото в параллельной транзакции, не будет удалена
+	query := `
+		DELETE FROM pending_uploads
+		WHERE id = $1 AND photo_id IS NULL`
+
+	return r.execAffectingOne(ctx, query, id)
+}
+
+func (r *repository) execAffectingOne(ctx context.Context, query string, id int) error {
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete pending upload: %w", err)
+	}
+
+	affectedCnt, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+	if affectedCnt < 1 {
+		return fmt.Errorf("%w: no pending upload with id %d", repoErr.NotFoundError, id)
+	}
+
+	return nil
+}
+
+// commitPendingUpload помечает запись журнала сохраненной в транзакции создания фото.
+// Если записи нет (ее удалил reconciler как устаревшую, а staging файл мог быть уже удален),
+// транзакция должна быть отменена.
+func commitPendingUpload(ctx context.Context, tx *sql.Tx, pendingUploadID, photoID int) error {
+	query := `
+		UPDATE pending_uploads
+		SET photo_id = $1
+		WHERE id = $2 AND photo_id IS NULL`
+
+	res, err := tx.ExecContext(ctx, query, photoID, pendingUploadID)
+	if err != nil {
+		return fmt.Errorf("failed to commit pending upload: %w", err)
+	}
+
+	affectedCnt, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+	if affectedCnt < 1 {
+		return fmt.Errorf("%w: no uncommitted pending upload with id %d", repoErr.NotFoundError, pendingUploadID)
+	}
+
+	return nil
+}
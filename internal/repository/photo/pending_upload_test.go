@@ -0,0 +1,137 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+var pendingUploadColumns = []string{"id", "user_uuid", "uuid_filename", "photo_id", "created_at"}
+
+func TestRepository_CreatePendingUpload(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        *model.CreatePendingUploadParams
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedID    int
+		expectedError error
+	}{
+		{
+			name:   "Valid",
+			params: &model.CreatePendingUploadParams{UserUUID: "user-uuid", UUIDFilename: "uuid.png"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO pending_uploads").
+					WithArgs("user-uuid", "uuid.png").
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(5))
+			},
+			expectedID: 5,
+		},
+		{
+			name:   "Insert error",
+			params: &model.CreatePendingUploadParams{UserUUID: "user-uuid", UUIDFilename: "uuid.png"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO pending_uploads").
+					WithArgs("user-uuid", "uuid.png").
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: def.InsertError,
+		},
+		{
+			name:          "Invalid params",
+			params:        &model.CreatePendingUploadParams{UserUUID: "user-uuid"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+			tt.mockSetup(mock)
+
+			id, err := repo.CreatePendingUpload(context.Background(), tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedID, id)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetPendingUploads(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	createdAt := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM pending_uploads").
+		WillReturnRows(sqlmock.NewRows(pendingUploadColumns).
+			AddRow(1, "user-uuid", "a.png", nil, createdAt).
+			AddRow(2, "user-uuid", "b.png", 10, createdAt))
+
+	repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+	uploads, err := repo.GetPendingUploads(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []model.PendingUpload{
+		{ID: 1, UserUUID: "user-uuid", UUIDFilename: "a.png", CreatedAt: createdAt},
+		{ID: 2, UserUUID: "user-uuid", UUIDFilename: "b.png", PhotoID: sql.NullInt64{Int64: 10, Valid: true}, CreatedAt: createdAt},
+	}, uploads)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_DeleteUncommittedPendingUpload(t *testing.T) {
+	tests := []struct {
+		name          string
+		affected      int64
+		expectedError error
+	}{
+		{
+			name:     "Deleted",
+			affected: 1,
+		},
+		{
+			name:          "Already committed or missing",
+			affected:      0,
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectExec("DELETE FROM pending_uploads WHERE id = \\$1 AND photo_id IS NULL").
+				WithArgs(3).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+			err = repo.DeleteUncommittedPendingUpload(context.Background(), 3)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
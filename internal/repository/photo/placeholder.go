@@ -0,0 +1,96 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"github.com/lib/pq"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/logger"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (r *repository) GetVersionsWithoutPlaceholder(ctx context.Context, limit int) (_ []repoModel.PhotoVersionWithOwner, err error) {
+	ctx, span := startSpan(ctx, "GetVersionsWithoutPlaceholder")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var versions []repoModel.PhotoVersionWithOwner
+
+	// Прежние ревизии не показываются в списках, их заглушка рассчитывается, пока они оригинал
+	query := `
+		SELECT pv.id, pv.photo_id, pv.version_type, pv.uuid_filename, pv.size, pv.height, pv.width, pv.saved_at,
+		       pv.checksum, p.user_uuid
+		FROM photo_versions pv
+		JOIN photos p ON p.id = pv.photo_id
+		WHERE pv.blurhash IS NULL AND pv.version_type <> 'revision'
+		ORDER BY pv.id
+		LIMIT $1`
+
+	err = r.db.SelectContext(ctx, &versions, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get versions without placeholder: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (r *repository) SavePhotoVersionPlaceholder(ctx context.Context, versionID int, params *repoModel.SavePhotoVersionPlaceholderParams) (err error) {
+	ctx, span := startSpan(ctx, "SavePhotoVersionPlaceholder", attribute.Int("photo_version.id", versionID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", repoErr.BeginTxError, err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.FromContext(ctx).Errorf("failed to rollback transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `
+		UPDATE photo_versions
+		SET blurhash = $1, palette = $2
+		WHERE id = $3`
+
+	res, err := tx.ExecContext(ctx, query, params.Blurhash, pq.StringArray(params.Palette), versionID)
+	if err != nil {
+		return fmt.Errorf("failed to save photo version placeholder: %w", err)
+	}
+
+	affectedCnt, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+	if affectedCnt < 1 {
+		return fmt.Errorf("%w: no photo version found with id %d", repoErr.NotFoundError, versionID)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM photo_version_colors WHERE version_id = $1`, versionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete photo version colors: %w", err)
+	}
+
+	err = insertVersionColors(ctx, tx, versionID, params.Palette)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+)
+
+func TestRepository_GetVersionsWithoutPlaceholder(t *testing.T) {
+	tests := []struct {
+		name             string
+		mockSetup        func(mock sqlmock.Sqlmock)
+		expectedVersions []model.PhotoVersionWithOwner
+		expectedError    error
+	}{
+		{
+			name: "Found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM photo_versions pv JOIN photos p ON p.id = pv.photo_id WHERE pv.blurhash IS NULL AND pv.version_type <> 'revision' ORDER BY pv.id LIMIT \$1`).
+					WithArgs(10).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "photo_id", "version_type", "uuid_filename", "user_uuid"}).
+						AddRow(3, 1, "original", "a.jpg", "user"))
+			},
+			expectedVersions: []model.PhotoVersionWithOwner{{
+				PhotoVersion: model.PhotoVersion{ID: 3, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "a.jpg"},
+				UserUUID:     sql.NullString{String: "user", Valid: true},
+			}},
+		},
+		{
+			name: "Select error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM photo_versions pv`).WithArgs(10).WillReturnError(errors.New("select error"))
+			},
+			expectedError: errors.New("select error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			versions, err := repo.GetVersionsWithoutPlaceholder(context.Background(), 10)
+			if tt.expectedError != nil {
+				assert.ErrorContains(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedVersions, versions)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_SavePhotoVersionPlaceholder(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        *model.SavePhotoVersionPlaceholderParams
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name:   "Saved",
+			params: &model.SavePhotoVersionPlaceholderParams{Blurhash: "LKO2?U%2Tw=w", Palette: []string{"#3366cc", "#cc2211"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE photo_versions SET blurhash = \$1, palette = \$2 WHERE id = \$3`).
+					WithArgs("LKO2?U%2Tw=w", pq.StringArray{"#3366cc", "#cc2211"}, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM photo_version_colors WHERE version_id = \$1`).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`INSERT INTO photo_version_colors \(version_id, position, l, a, b, bucket\)`).
+					WithArgs(3, pq.Int64Array{0, 1}, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "Undecodable file",
+			params: &model.SavePhotoVersionPlaceholderParams{},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE photo_versions`).
+					WithArgs("", nil, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM photo_version_colors`).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+		{
+			name:          "Palette without blurhash",
+			params:        &model.SavePhotoVersionPlaceholderParams{Palette: []string{"#3366cc"}},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+		{
+			name:   "Version not found",
+			params: &model.SavePhotoVersionPlaceholderParams{Blurhash: "LKO2?U%2Tw=w"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE photo_versions`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name:   "Colors insert error",
+			params: &model.SavePhotoVersionPlaceholderParams{Blurhash: "LKO2?U%2Tw=w", Palette: []string{"#3366cc"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE photo_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM photo_version_colors`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO photo_version_colors`).WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
+			},
+			expectedError: def.InsertError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			err = repo.SavePhotoVersionPlaceholder(context.Background(), 3, tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
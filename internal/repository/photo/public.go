@@ -0,0 +1,45 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+)
+
+func (r *repository) GetPublicPhotoVersions(ctx context.Context, token string) (_ []repoModel.PhotoWithPhotoVersion, err error) {
+	ctx, span := startSpan(ctx, "GetPublicPhotoVersions")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var rows []repoModel.PhotoWithPhotoVersion
+
+	query := `
+		SELECT
+			p.id AS photo_id,
+			p.user_uuid,
+			p.filename,
+			p.uploaded_at,
+			pv.id AS version_id,
+			pv.version_type,
+			pv.uuid_filename,
+			pv.size,
+			pv.height,
+			pv.width,
+			pv.saved_at
+		FROM published_photo_info ppi
+		JOIN photos p ON ppi.photo_id = p.id AND p.deleted_at IS NULL
+		JOIN photo_versions pv ON ppi.photo_id = pv.photo_id AND pv.version_type IS DISTINCT FROM 'revision'
+		WHERE ppi.public_token = $1
+		ORDER BY pv.id`
+
+	err = r.db.SelectContext(ctx, &rows, query, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public photo versions: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no public photo found with token %s", repoErr.NotFoundError, token)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,93 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+)
+
+func TestRepository_GetPublicPhotoVersions(t *testing.T) {
+	columns := []string{
+		"photo_id", "user_uuid", "filename", "uploaded_at", "version_id",
+		"version_type", "uuid_filename", "size", "height", "width", "saved_at",
+	}
+
+	tests := []struct {
+		name             string
+		mockSetup        func(mock sqlmock.Sqlmock)
+		expectedVersions []model.PhotoWithPhotoVersion
+		expectedError    error
+	}{
+		{
+			name: "Found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM published_photo_info ppi (.+) WHERE ppi.public_token = \$1`).
+					WithArgs("token").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, "user-uuid", "a.png", nil, 10, "original", "uuid1.png", 100, 1000, 2000, nil).
+						AddRow(1, "user-uuid", "a.png", nil, 11, "preview", "uuid2.png", 10, 320, 640, nil))
+			},
+			expectedVersions: []model.PhotoWithPhotoVersion{
+				{
+					PhotoID: 1, UserUUID: "user-uuid", Filename: "a.png", VersionID: 10,
+					VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "uuid1.png",
+					Size: 100, Height: 1000, Width: 2000,
+				},
+				{
+					PhotoID: 1, UserUUID: "user-uuid", Filename: "a.png", VersionID: 11,
+					VersionType: sql.NullString{String: "preview", Valid: true}, UUIDFilename: "uuid2.png",
+					Size: 10, Height: 320, Width: 640,
+				},
+			},
+		},
+		{
+			name: "Not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM published_photo_info`).
+					WithArgs("token").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM published_photo_info`).
+					WithArgs("token").
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: errors.New("failed to get public photo versions: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			versions, err := repo.GetPublicPhotoVersions(context.Background(), "token")
+			if tt.expectedError != nil {
+				if errors.Is(tt.expectedError, def.NotFoundError) {
+					assert.ErrorIs(t, err, tt.expectedError)
+				} else {
+					assert.EqualError(t, err, tt.expectedError.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedVersions, versions)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+)
+
+func (r *repository) GetMetadataPolicyByToken(ctx context.Context, token string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "GetMetadataPolicyByToken")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var policy string
+
+	query := `
+		SELECT ppi.metadata_policy
+		FROM published_photo_info ppi
+		JOIN photos p ON ppi.photo_id = p.id AND p.deleted_at IS NULL
+		WHERE ppi.public_token = $1`
+
+	err = r.db.GetContext(ctx, &policy, query, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: no publication with public token %s", repoErr.NotFoundError, token)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get metadata policy by token: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (r *repository) GetPublishSettings(ctx context.Context, userUUID string) (_ *repoModel.PublishSettings, err error) {
+	ctx, span := startSpan(ctx, "GetPublishSettings")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var settings repoModel.PublishSettings
+
+	query := `
+		SELECT user_uuid, metadata_policy, updated_at
+		FROM publish_settings
+		WHERE user_uuid = $1`
+
+	err = r.db.GetContext(ctx, &settings, query, userUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no publish settings of user %s", repoErr.NotFoundError, userUUID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publish settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *repository) SavePublishSettings(ctx context.Context, params *repoModel.SavePublishSettingsParams) (_ *repoModel.PublishSettings, err error) {
+	ctx, span := startSpan(ctx, "SavePublishSettings")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	var settings repoModel.PublishSettings
+
+	query := `
+		INSERT INTO publish_settings (user_uuid, metadata_policy)
+		VALUES ($1, $2)
+		ON CONFLICT (user_uuid) DO UPDATE
+		SET metadata_policy = EXCLUDED.metadata_policy,
+		    updated_at = now()
+		RETURNING user_uuid, metadata_policy, updated_at`
+
+	err = r.db.GetContext(ctx, &settings, query, params.UserUUID, params.MetadataPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("publish settings %w: %v", repoErr.InsertError, err)
+	}
+
+	return &settings, nil
+}
@@ -0,0 +1,176 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+func TestRepository_CreatePhotoPublishedInfo(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        *model.CreatePhotoPublishedInfoParams
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedToken string
+		expectedError error
+	}{
+		{
+			name:   "Created",
+			params: &model.CreatePhotoPublishedInfoParams{PhotoID: 1, Watermark: true, MetadataPolicy: "strip_all"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO published_photo_info \(photo_id, watermark, metadata_policy\)`).
+					WithArgs(1, true, "strip_all").
+					WillReturnRows(sqlmock.NewRows([]string{"public_token"}).AddRow("token"))
+			},
+			expectedToken: "token",
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+		{
+			name:          "No metadata policy",
+			params:        &model.CreatePhotoPublishedInfoParams{PhotoID: 1},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			token, err := repo.CreatePhotoPublishedInfo(context.Background(), tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedToken, token)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetMetadataPolicyByToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(mock sqlmock.Sqlmock)
+		expectedPolicy string
+		expectedError  error
+	}{
+		{
+			name: "Published",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT ppi.metadata_policy\s+FROM published_photo_info ppi\s+JOIN photos p ON ppi.photo_id = p.id AND p.deleted_at IS NULL`).
+					WithArgs("token").
+					WillReturnRows(sqlmock.NewRows([]string{"metadata_policy"}).AddRow("strip_private"))
+			},
+			expectedPolicy: "strip_private",
+		},
+		{
+			name: "Not published",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM published_photo_info`).WithArgs("token").WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			policy, err := repo.GetMetadataPolicyByToken(context.Background(), "token")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPolicy, policy)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_SavePublishSettings(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		params           *model.SavePublishSettingsParams
+		mockSetup        func(mock sqlmock.Sqlmock)
+		expectedSettings *model.PublishSettings
+		expectedError    error
+	}{
+		{
+			name:   "Saved",
+			params: &model.SavePublishSettingsParams{UserUUID: "user", MetadataPolicy: "keep"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO publish_settings .* ON CONFLICT \(user_uuid\) DO UPDATE`).
+					WithArgs("user", "keep").
+					WillReturnRows(sqlmock.NewRows([]string{"user_uuid", "metadata_policy", "updated_at"}).
+						AddRow("user", "keep", updatedAt))
+			},
+			expectedSettings: &model.PublishSettings{UserUUID: "user", MetadataPolicy: "keep", UpdatedAt: updatedAt},
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+		{
+			name:          "Invalid params",
+			params:        &model.SavePublishSettingsParams{UserUUID: "user"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+		{
+			name:   "Check violation",
+			params: &model.SavePublishSettingsParams{UserUUID: "user", MetadataPolicy: "unknown"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO publish_settings`).WillReturnError(errors.New("check violation"))
+			},
+			expectedError: def.InsertError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			settings, err := repo.SavePublishSettings(context.Background(), tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedSettings, settings)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (r *repository) GetPhotoVersionRendition(ctx context.Context, versionID int, format string) (_ *repoModel.PhotoVersionRendition, err error) {
+	ctx, span := startSpan(ctx, "GetPhotoVersionRendition", attribute.Int("photo_version.id", versionID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var rendition repoModel.PhotoVersionRendition
+
+	query := `
+		SELECT id, version_id, format, uuid_filename, size, created_at
+		FROM photo_version_renditions
+		WHERE version_id = $1 AND format = $2`
+
+	err = r.db.GetContext(ctx, &rendition, query, versionID, format)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no %s rendition of photo version %d", repoErr.NotFoundError, format, versionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photo version rendition: %w", err)
+	}
+
+	return &rendition, nil
+}
+
+func (r *repository) SavePhotoVersionRendition(ctx context.Context, params *repoModel.SavePhotoVersionRenditionParams) (err error) {
+	ctx, span := startSpan(ctx, "SavePhotoVersionRendition")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	query := `
+		INSERT INTO photo_version_renditions (version_id, format, uuid_filename, size)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (version_id, format) DO UPDATE
+		SET uuid_filename = EXCLUDED.uuid_filename, size = EXCLUDED.size, created_at = now()`
+
+	_, err = r.db.ExecContext(ctx, query, params.VersionID, params.Format, params.UUIDFilename, params.Size)
+	if err != nil {
+		return fmt.Errorf("photo version rendition %w: %v", repoErr.InsertError, err)
+	}
+
+	return nil
+}
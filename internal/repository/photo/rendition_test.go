@@ -0,0 +1,141 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+func TestRepository_GetPhotoVersionRendition(t *testing.T) {
+	columns := []string{"id", "version_id", "format", "uuid_filename", "size", "created_at"}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		mockSetup         func(mock sqlmock.Sqlmock)
+		expectedRendition *model.PhotoVersionRendition
+		expectedError     error
+	}{
+		{
+			name: "Found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM photo_version_renditions WHERE version_id = \$1 AND format = \$2`).
+					WithArgs(3, "webp").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 3, "webp", "uuid.webp", 100, createdAt))
+			},
+			expectedRendition: &model.PhotoVersionRendition{
+				ID: 1, VersionID: 3, Format: "webp", UUIDFilename: "uuid.webp", Size: 100, CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "Not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM photo_version_renditions`).
+					WithArgs(3, "webp").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM photo_version_renditions`).
+					WithArgs(3, "webp").
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: errors.New("failed to get photo version rendition: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			rendition, err := repo.GetPhotoVersionRendition(context.Background(), 3, "webp")
+			if tt.expectedError != nil {
+				if errors.Is(tt.expectedError, def.NotFoundError) {
+					assert.ErrorIs(t, err, tt.expectedError)
+				} else {
+					assert.EqualError(t, err, tt.expectedError.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedRendition, rendition)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_SavePhotoVersionRendition(t *testing.T) {
+	validParams := &model.SavePhotoVersionRenditionParams{VersionID: 3, Format: "webp", UUIDFilename: "uuid.webp", Size: 100}
+
+	tests := []struct {
+		name          string
+		params        *model.SavePhotoVersionRenditionParams
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name:   "Valid",
+			params: validParams,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO photo_version_renditions (.+) ON CONFLICT \(version_id, format\) DO UPDATE`).
+					WithArgs(3, "webp", "uuid.webp", int64(100)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:   "Insert error",
+			params: validParams,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO photo_version_renditions").
+					WithArgs(3, "webp", "uuid.webp", int64(100)).
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: def.InsertError,
+		},
+		{
+			name:          "Invalid params",
+			params:        &model.SavePhotoVersionRenditionParams{VersionID: 3, Format: "webp"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			err = repo.SavePhotoVersionRendition(context.Background(), tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -7,15 +7,22 @@ import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
-	log "github.com/sirupsen/logrus"
 	def "go-photo/internal/repository"
 	repoErr "go-photo/internal/repository/error"
 	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/logger"
 	pkgRepo "go-photo/pkg/repository"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ def.PhotoRepository = (*repository)(nil)
 
+const tracerName = "go-photo/internal/repository/photo"
+
 type repository struct {
 	db *sqlx.DB
 }
@@ -26,7 +33,10 @@ func NewRepository(db *sqlx.DB) *repository {
 	}
 }
 
-func (r *repository) CreateOriginalPhoto(ctx context.Context, params *repoModel.CreateOriginalPhotoParams) (int, error) {
+func (r *repository) CreateOriginalPhoto(ctx context.Context, params *repoModel.CreateOriginalPhotoParams) (_ int, err error) {
+	ctx, span := startSpan(ctx, "CreateOriginalPhoto")
+	defer func() { tracing.EndSpan(span, err) }()
+
 	if params == nil {
 		return 0, repoErr.NilParamsError
 	}
@@ -41,8 +51,9 @@ func (r *repository) CreateOriginalPhoto(ctx context.Context, params *repoModel.
 
 	defer func() {
 		if err != nil {
-			err = tx.Rollback()
-			log.Errorf("failed to rollback transaction: %v\ncontext: %v", err, ctx)
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.FromContext(ctx).Errorf("failed to rollback transaction: %v", rbErr)
+			}
 		}
 	}()
 
@@ -60,37 +71,81 @@ func (r *repository) CreateOriginalPhoto(ctx context.Context, params *repoModel.
 	}
 
 	photoVersionQuery := `
-		INSERT INTO photo_versions (photo_id, uuid_filename, size, height, width, saved_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err = tx.ExecContext(ctx,
+		INSERT INTO photo_versions (photo_id, uuid_filename, size, height, width, saved_at, checksum, revision, blurhash, palette,
+		                            latitude, longitude, taken_at, camera_make, camera_model, exif_extracted)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), 1, NULLIF($8, ''), $9, $10, $11, $12, NULLIF($13, ''), NULLIF($14, ''), NOT $15)
+		RETURNING id`
+	var latitude, longitude sql.NullFloat64
+	if params.Location != nil {
+		latitude = sql.NullFloat64{Float64: params.Location.Latitude, Valid: true}
+		longitude = sql.NullFloat64{Float64: params.Location.Longitude, Valid: true}
+	}
+	var takenAt sql.NullTime
+	if params.TakenAt != nil {
+		takenAt = sql.NullTime{Time: *params.TakenAt, Valid: true}
+	}
+	var versionID int
+	err = tx.QueryRowContext(ctx,
 		photoVersionQuery,
 		photoID,
 		params.UUIDFilename,
 		params.Size,
 		params.Height,
 		params.Width,
-		params.SavedAt)
+		params.SavedAt,
+		params.Checksum,
+		params.Blurhash,
+		pq.StringArray(params.Palette),
+		latitude,
+		longitude,
+		takenAt,
+		params.CameraMake,
+		params.CameraModel,
+		params.ExifUnknown).Scan(&versionID)
 	if err != nil {
 		return 0, fmt.Errorf("version %w: %v", repoErr.InsertError, err)
 	}
 
-	commitErr := tx.Commit()
-	if commitErr != nil {
-		return 0, fmt.Errorf("failed to commit transaction: %w", commitErr)
+	err = insertVersionColors(ctx, tx, versionID, params.Palette)
+	if err != nil {
+		return 0, err
+	}
+
+	if params.PendingUploadID != 0 {
+		err = commitPendingUpload(ctx, tx, params.PendingUploadID, photoID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return photoID, nil
 }
 
-func (r *repository) CreatePhotoPublishedInfo(ctx context.Context, photoID int) (string, error) {
+func (r *repository) CreatePhotoPublishedInfo(ctx context.Context, params *repoModel.CreatePhotoPublishedInfoParams) (_ string, err error) {
+	ctx, span := startSpan(ctx, "CreatePhotoPublishedInfo")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return "", repoErr.NilParamsError
+	}
+	span.SetAttributes(attribute.Int("photo.id", params.PhotoID))
+	if !params.IsValid() {
+		return "", fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
 	query := `
-		INSERT INTO published_photo_info (photo_id)
-		VALUES ($1)
+		INSERT INTO published_photo_info (photo_id, watermark, metadata_policy)
+		VALUES ($1, $2, $3)
 		RETURNING public_token`
 
 	var publicToken string
-	row := r.db.QueryRowContext(ctx, query, photoID)
-	err := row.Scan(&publicToken)
+	row := r.db.QueryRowContext(ctx, query, params.PhotoID, params.Watermark, params.MetadataPolicy)
+	err = row.Scan(&publicToken)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == pkgRepo.UniqueViolationErrorCode {
@@ -102,15 +157,18 @@ func (r *repository) CreatePhotoPublishedInfo(ctx context.Context, photoID int)
 	return publicToken, nil
 }
 
-func (r *repository) GetPhotoByID(ctx context.Context, photoID int) (*repoModel.Photo, error) {
+func (r *repository) GetPhotoByID(ctx context.Context, photoID int) (_ *repoModel.Photo, err error) {
+	ctx, span := startSpan(ctx, "GetPhotoByID", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	var photo repoModel.Photo
 
 	query := `
-		SELECT id, user_uuid, filename, uploaded_at
+		SELECT ` + photoSelectColumns + `
 		FROM photos
 		WHERE id = $1`
 
-	err := r.db.GetContext(ctx, &photo, query, photoID)
+	err = r.db.GetContext(ctx, &photo, query, photoID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("%w: no photo found with id %d", repoErr.NotFoundError, photoID)
@@ -125,12 +183,16 @@ func (r *repository) GetPhotoVersionByToken(
 	ctx context.Context,
 	token string,
 	filterParams *repoModel.FilterParams,
-) (*repoModel.PhotoVersion, error) {
+) (_ *repoModel.PhotoVersion, err error) {
+	ctx, span := startSpan(ctx, "GetPhotoVersionByToken")
+	defer func() { tracing.EndSpan(span, err) }()
+
 	var photoVersion repoModel.PhotoVersion
 
 	query := `
 		SELECT pv.id, pv.photo_id, pv.version_type, pv.uuid_filename, pv.size, pv.height, pv.width, pv.saved_at
 		FROM published_photo_info ppi
+		JOIN photos p ON ppi.photo_id = p.id AND p.deleted_at IS NULL
 		JOIN photo_versions pv ON ppi.photo_id = pv.photo_id
 		WHERE ppi.public_token = :token`
 
@@ -162,7 +224,10 @@ func (r *repository) GetPublicPhotosByTokenPrefix(
 	ctx context.Context,
 	tokenPrefix string,
 	filterParams *repoModel.FilterParams,
-) ([]repoModel.PhotoWithPhotoVersion, error) {
+) (_ []repoModel.PhotoWithPhotoVersion, err error) {
+	ctx, span := startSpan(ctx, "GetPublicPhotosByTokenPrefix")
+	defer func() { tracing.EndSpan(span, err) }()
+
 	var rows []repoModel.PhotoWithPhotoVersion
 
 	query := `
@@ -182,8 +247,8 @@ func (r *repository) GetPublicPhotosByTokenPrefix(
 	INNER JOIN published_photo_info pi
     	ON p.id = pi.photo_id
 	INNER JOIN photo_versions pv
-        ON p.id = pv.photo_id
-	WHERE pi.public_token LIKE :tokenPrefix
+        ON p.id = pv.photo_id AND pv.version_type IS DISTINCT FROM 'revision'
+	WHERE pi.public_token LIKE :tokenPrefix AND p.deleted_at IS NULL
 	`
 
 	params := map[string]interface{}{
@@ -204,16 +269,19 @@ func (r *repository) GetPublicPhotosByTokenPrefix(
 	return rows, err
 }
 
-func (r *repository) GetPhotoVersions(ctx context.Context, photoID int) ([]repoModel.PhotoVersion, error) {
+func (r *repository) GetPhotoVersions(ctx context.Context, photoID int) (_ []repoModel.PhotoVersion, err error) {
+	ctx, span := startSpan(ctx, "GetPhotoVersions", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	var versions []repoModel.PhotoVersion
 
 	query := `
-		SELECT id, photo_id, version_type, uuid_filename, size, height, width, saved_at
+		SELECT id, photo_id, version_type, uuid_filename, size, height, width, saved_at, blurhash, palette
 		FROM photo_versions 
 		WHERE photo_id = $1
 		ORDER BY size`
 
-	err := r.db.SelectContext(ctx, &versions, query, photoID)
+	err = r.db.SelectContext(ctx, &versions, query, photoID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("%w: no photo versions found with id %d", repoErr.NotFoundError, photoID)
@@ -224,7 +292,69 @@ func (r *repository) GetPhotoVersions(ctx context.Context, photoID int) ([]repoM
 	return versions, nil
 }
 
-func (r *repository) DeletePhotoPublishedInfo(ctx context.Context, photoID int) error {
+func (r *repository) GetAllPhotoVersions(ctx context.Context) (_ []repoModel.PhotoVersionWithOwner, err error) {
+	ctx, span := startSpan(ctx, "GetAllPhotoVersions")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var versions []repoModel.PhotoVersionWithOwner
+
+	query := `
+		SELECT pv.id, pv.photo_id, pv.version_type, pv.uuid_filename, pv.size, pv.height, pv.width, pv.saved_at,
+		       pv.checksum, p.user_uuid
+		FROM photo_versions pv
+		LEFT JOIN photos p ON p.id = pv.photo_id
+		ORDER BY pv.id`
+
+	err = r.db.SelectContext(ctx, &versions, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photo versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (r *repository) UpdatePhotoVersionFile(ctx context.Context, versionID int, params *repoModel.UpdatePhotoVersionFileParams) (err error) {
+	ctx, span := startSpan(ctx, "UpdatePhotoVersionFile", attribute.Int("photo_version.id", versionID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	// Варианты версии в других форматах, заглушка и цвета рассчитаны по старому файлу и больше не подходят
+	query := `
+		WITH dropped AS (
+			DELETE FROM photo_version_renditions WHERE version_id = $6
+		), dropped_colors AS (
+			DELETE FROM photo_version_colors WHERE version_id = $6
+		)
+		UPDATE photo_versions
+		SET size = $1, height = $2, width = $3, checksum = $4, saved_at = $5, blurhash = NULL, palette = NULL
+		WHERE id = $6`
+
+	res, err := r.db.ExecContext(ctx, query, params.Size, params.Height, params.Width, params.Checksum, params.SavedAt, versionID)
+	if err != nil {
+		return fmt.Errorf("failed to update photo version: %w", err)
+	}
+
+	affectedCnt, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+	if affectedCnt < 1 {
+		return fmt.Errorf("%w: no photo version found with id %d", repoErr.NotFoundError, versionID)
+	}
+
+	return nil
+}
+
+func (r *repository) DeletePhotoPublishedInfo(ctx context.Context, photoID int) (err error) {
+	ctx, span := startSpan(ctx, "DeletePhotoPublishedInfo", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	query := `
 		DELETE FROM published_photo_info
 		WHERE photo_id=$(1)`
@@ -244,3 +374,13 @@ func (r *repository) DeletePhotoPublishedInfo(ctx context.Context, photoID int)
 
 	return nil
 }
+
+// startSpan начинает span для SQL-вызова репозитория.
+func startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation))
+
+	return otel.Tracer(tracerName).Start(ctx, "PhotoRepository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
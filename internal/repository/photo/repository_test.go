@@ -13,6 +13,10 @@ import (
 	domainModel "go-photo/internal/model"
 	def "go-photo/internal/repository/error"
 	"go-photo/internal/repository/photo/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"regexp"
 	"testing"
 	"time"
@@ -34,6 +38,7 @@ func TestRepository_CreateOriginalPhoto(t *testing.T) {
 		Width:        100,
 		SavedAt:      time.Now(),
 	}
+	takenAt := time.Date(2024, 7, 14, 18, 30, 0, 0, time.UTC)
 
 	tests := []struct {
 		name          string
@@ -53,9 +58,9 @@ func TestRepository_CreateOriginalPhoto(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows(idColumn).
 						AddRow(1))
 
-				mock.ExpectExec("INSERT INTO photo_versions").
-					WithArgs(1, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg()).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO photo_versions").
+					WithArgs(1, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg(), "", "", nil, nil, nil, nil, "", "", false).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(10))
 
 				mock.ExpectCommit()
 			},
@@ -82,9 +87,9 @@ func TestRepository_CreateOriginalPhoto(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows(idColumn).
 						AddRow(1))
 
-				mock.ExpectExec("INSERT INTO photo_versions").
-					WithArgs(1, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg()).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO photo_versions").
+					WithArgs(1, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg(), "", "", nil, nil, nil, nil, "", "", false).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(10))
 
 				mock.ExpectCommit().WillReturnError(def.CommitTxError)
 			},
@@ -117,8 +122,8 @@ func TestRepository_CreateOriginalPhoto(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows(idColumn).
 						AddRow(1))
 
-				mock.ExpectExec("INSERT INTO photo_versions").
-					WithArgs(1, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg()).
+				mock.ExpectQuery("INSERT INTO photo_versions").
+					WithArgs(1, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg(), "", "", nil, nil, nil, nil, "", "", false).
 					WillReturnError(def.InsertError)
 
 				mock.ExpectRollback()
@@ -134,14 +139,89 @@ func TestRepository_CreateOriginalPhoto(t *testing.T) {
 				mock.ExpectQuery("INSERT INTO photos").
 					WithArgs("user-uuid", "test.png", sqlmock.AnyArg()).
 					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(123))
-				mock.ExpectExec("INSERT INTO photo_versions").
-					WithArgs(123, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg()).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO photo_versions").
+					WithArgs(123, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg(), "", "", nil, nil, nil, nil, "", "", false).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(10))
 				mock.ExpectCommit()
 			},
 			expectedID:    123,
 			expectedError: nil,
 		},
+		{
+			name: "EXIF and palette",
+			params: func() *model.CreateOriginalPhotoParams {
+				p := defaultParams
+				p.Blurhash = "LEHV6nWB2yk8pyo0adR*.7kCMdnj"
+				p.Palette = []string{"#3366cc"}
+				p.Location = &model.GeoPoint{Latitude: 55.75, Longitude: 37.61}
+				p.TakenAt = &takenAt
+				p.CameraMake = "Apple"
+				p.CameraModel = "iPhone 15 Pro"
+				return &p
+			}(),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO photos").
+					WithArgs("user-uuid", "test.png", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+				mock.ExpectQuery(`INSERT INTO photo_versions .* latitude, longitude, taken_at, camera_make, camera_model, exif_extracted\)`).
+					WithArgs(1, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg(), "",
+						"LEHV6nWB2yk8pyo0adR*.7kCMdnj", sqlmock.AnyArg(), 55.75, 37.61, takenAt, "Apple", "iPhone 15 Pro", false).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(10))
+				mock.ExpectExec("INSERT INTO photo_version_colors").
+					WithArgs(10, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedID:    1,
+			expectedError: nil,
+		},
+		{
+			name: "Pending upload committed",
+			params: func() *model.CreateOriginalPhotoParams {
+				p := defaultParams
+				p.PendingUploadID = 7
+				return &p
+			}(),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO photos").
+					WithArgs("user-uuid", "test.png", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+				mock.ExpectQuery("INSERT INTO photo_versions").
+					WithArgs(1, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg(), "", "", nil, nil, nil, nil, "", "", false).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(10))
+				mock.ExpectExec("UPDATE pending_uploads").
+					WithArgs(1, 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedID:    1,
+			expectedError: nil,
+		},
+		{
+			name: "Pending upload already reconciled",
+			params: func() *model.CreateOriginalPhotoParams {
+				p := defaultParams
+				p.PendingUploadID = 7
+				return &p
+			}(),
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO photos").
+					WithArgs("user-uuid", "test.png", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+				mock.ExpectQuery("INSERT INTO photo_versions").
+					WithArgs(1, "home/user-uuid/test.png", 12345, 100, 100, sqlmock.AnyArg(), "", "", nil, nil, nil, nil, "", "", false).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(10))
+				mock.ExpectExec("UPDATE pending_uploads").
+					WithArgs(1, 7).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedID:    0,
+			expectedError: def.NotFoundError,
+		},
 		{
 			name:          "Nil params",
 			params:        nil,
@@ -188,7 +268,7 @@ func TestRepository_CreateOriginalPhoto(t *testing.T) {
 
 func TestRepository_GetPhotoVersions(t *testing.T) {
 	uploadedAt := sql.NullTime{Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
-	query := "SELECT id, photo_id, version_type, uuid_filename, size, height, width, saved_at FROM photo_versions WHERE photo_id = \\$1 ORDER BY size"
+	query := "SELECT id, photo_id, version_type, uuid_filename, size, height, width, saved_at, blurhash, palette FROM photo_versions WHERE photo_id = \\$1 ORDER BY size"
 
 	tests := []struct {
 		name           string
@@ -294,6 +374,7 @@ func TestRepository_GetPhotoVersionByToken(t *testing.T) {
 	query := `
 	SELECT pv.id, pv.photo_id, pv.version_type, pv.uuid_filename, pv.size, pv.height, pv.width, pv.saved_at
 	FROM published_photo_info ppi
+	JOIN photos p ON ppi.photo_id = p.id AND p.deleted_at IS NULL
 	JOIN photo_versions pv ON ppi.photo_id = pv.photo_id
 	WHERE ppi.public_token = ? AND version_type = ?`
 
@@ -381,3 +462,172 @@ func TestRepository_GetPhotoVersionByToken(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_GetAllPhotoVersions(t *testing.T) {
+	savedAt := sql.NullTime{Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	columns := append(append([]string(nil), photoVersionColumns...), "checksum", "user_uuid")
+
+	tests := []struct {
+		name           string
+		mockSetup      func(mock sqlmock.Sqlmock)
+		expectedResult []model.PhotoVersionWithOwner
+		expectedError  error
+	}{
+		{
+			name: "Valid",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM photo_versions pv LEFT JOIN photos p").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, 1, "original", "a.png", 12345, 100, 100, savedAt, "abc", "user-uuid").
+						AddRow(2, 7, "thumbnail", "b.png", 123, 10, 10, savedAt, nil, nil))
+			},
+			expectedResult: []model.PhotoVersionWithOwner{
+				{
+					PhotoVersion: model.PhotoVersion{
+						ID: 1, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true},
+						UUIDFilename: "a.png", Size: 12345, Height: 100, Width: 100, SavedAt: &savedAt,
+						Checksum: sql.NullString{String: "abc", Valid: true},
+					},
+					UserUUID: sql.NullString{String: "user-uuid", Valid: true},
+				},
+				{
+					PhotoVersion: model.PhotoVersion{
+						ID: 2, PhotoID: 7, VersionType: sql.NullString{String: "thumbnail", Valid: true},
+						UUIDFilename: "b.png", Size: 123, Height: 10, Width: 10, SavedAt: &savedAt,
+					},
+				},
+			},
+		},
+		{
+			name: "Select error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM photo_versions pv").
+					WillReturnError(errors.New("select error"))
+			},
+			expectedError: errors.New("select error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+
+			tt.mockSetup(mock)
+
+			versions, err := repo.GetAllPhotoVersions(context.Background())
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, versions)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_UpdatePhotoVersionFile(t *testing.T) {
+	savedAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	validParams := &model.UpdatePhotoVersionFileParams{Size: 123, Height: 10, Width: 20, Checksum: "abc", SavedAt: savedAt}
+
+	tests := []struct {
+		name          string
+		versionID     int
+		params        *model.UpdatePhotoVersionFileParams
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name:      "Valid",
+			versionID: 3,
+			params:    validParams,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE photo_versions").
+					WithArgs(int64(123), 10, 20, "abc", savedAt, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:      "Version not found",
+			versionID: 3,
+			params:    validParams,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE photo_versions").
+					WithArgs(int64(123), 10, 20, "abc", savedAt, 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name:          "Invalid params",
+			versionID:     3,
+			params:        &model.UpdatePhotoVersionFileParams{Size: 123},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+		{
+			name:          "Nil params",
+			versionID:     3,
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+
+			tt.mockSetup(mock)
+
+			err = repo.UpdatePhotoVersionFile(context.Background(), tt.versionID, tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery("SELECT id, user_uuid, filename, uploaded_at, deleted_at, title, caption, favorite, rating, hidden, updated_at FROM photos").
+		WithArgs(1).
+		WillReturnError(sql.ErrNoRows)
+
+	parentCtx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, err = repo.GetPhotoByID(parentCtx, 1)
+	parent.End()
+	require.ErrorIs(t, err, def.NotFoundError)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	span := spans[0]
+	assert.Equal(t, "PhotoRepository.GetPhotoByID", span.Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Len(t, span.Events, 1, "ошибка должна быть записана в span")
+}
@@ -0,0 +1,198 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/logger"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (r *repository) GetPhotoRevisions(ctx context.Context, photoID int) (_ []repoModel.PhotoVersion, err error) {
+	ctx, span := startSpan(ctx, "GetPhotoRevisions", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var revisions []repoModel.PhotoVersion
+
+	query := `
+		SELECT ` + versionSelectColumns + `
+		FROM photo_versions
+		WHERE photo_id = $1 AND revision IS NOT NULL
+		ORDER BY revision DESC`
+
+	err = r.db.SelectContext(ctx, &revisions, query, photoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photo revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+func (r *repository) CreatePhotoRevision(ctx context.Context, params *repoModel.CreatePhotoRevisionParams) (_ *repoModel.PhotoVersion, _ *repoModel.PhotoVersion, err error) {
+	ctx, span := startSpan(ctx, "CreatePhotoRevision")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return nil, nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return nil, nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+	span.SetAttributes(attribute.Int("photo.id", params.PhotoID))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", repoErr.BeginTxError, err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.FromContext(ctx).Errorf("failed to rollback transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	err = lockPhoto(ctx, tx, params.PhotoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	previous, err := demoteCurrentRevision(ctx, tx, params.PhotoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var created repoModel.PhotoVersion
+	err = tx.GetContext(ctx, &created, `
+		INSERT INTO photo_versions (photo_id, version_type, uuid_filename, size, height, width, saved_at, checksum, revision)
+		VALUES ($1, 'original', $2, $3, $4, $5, $6, NULLIF($7, ''),
+		        (SELECT COALESCE(MAX(revision), 0) + 1 FROM photo_versions WHERE photo_id = $1))
+		RETURNING `+versionSelectColumns,
+		params.PhotoID,
+		params.UUIDFilename,
+		params.Size,
+		params.Height,
+		params.Width,
+		params.SavedAt,
+		params.Checksum)
+	if err != nil {
+		return nil, nil, fmt.Errorf("revision %w: %v", repoErr.InsertError, err)
+	}
+
+	if params.PendingUploadID != 0 {
+		err = commitPendingUpload(ctx, tx.Tx, params.PendingUploadID, params.PhotoID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &created, previous, nil
+}
+
+func (r *repository) RestorePhotoRevision(ctx context.Context, photoID, revision int) (_ *repoModel.PhotoVersion, _ *repoModel.PhotoVersion, err error) {
+	ctx, span := startSpan(ctx, "RestorePhotoRevision", attribute.Int("photo.id", photoID), attribute.Int("photo.revision", revision))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", repoErr.BeginTxError, err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.FromContext(ctx).Errorf("failed to rollback transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	err = lockPhoto(ctx, tx, photoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var restored repoModel.PhotoVersion
+	err = tx.GetContext(ctx, &restored, `
+		SELECT `+versionSelectColumns+`
+		FROM photo_versions
+		WHERE photo_id = $1 AND revision = $2`, photoID, revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("%w: no revision %d of photo %d", repoErr.NotFoundError, revision, photoID)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get photo revision: %w", err)
+	}
+
+	var previous *repoModel.PhotoVersion
+	if restored.VersionType.String != "original" {
+		previous, err = demoteCurrentRevision(ctx, tx, photoID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = tx.GetContext(ctx, &restored, `
+			UPDATE photo_versions
+			SET version_type = 'original'
+			WHERE id = $1
+			RETURNING `+versionSelectColumns, restored.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to restore photo revision: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &restored, previous, nil
+}
+
+// lockPhoto блокирует неудаленное фото до конца транзакции, чтобы одновременные изменения
+// ревизий не создали две текущие ревизии и фото не удалили, пока они меняются.
+func lockPhoto(ctx context.Context, tx *sqlx.Tx, photoID int) error {
+	var id int
+	err := tx.QueryRowContext(ctx, `
+		SELECT id
+		FROM photos
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE`, photoID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: no photo with id %d", repoErr.NotFoundError, photoID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock photo: %w", err)
+	}
+
+	return nil
+}
+
+// demoteCurrentRevision переводит текущую ревизию оригинала в историю и возвращает ее,
+// или nil, если текущей ревизии нет.
+func demoteCurrentRevision(ctx context.Context, tx *sqlx.Tx, photoID int) (*repoModel.PhotoVersion, error) {
+	var current repoModel.PhotoVersion
+	err := tx.GetContext(ctx, &current, `
+		UPDATE photo_versions
+		SET version_type = 'revision'
+		WHERE photo_id = $1 AND (version_type = 'original' OR version_type IS NULL)
+		RETURNING `+versionSelectColumns, photoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to move current revision to history: %w", err)
+	}
+
+	return &current, nil
+}
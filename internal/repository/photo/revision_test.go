@@ -0,0 +1,261 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+var revisionColumns = append(append([]string(nil), versionWithChecksumColumns...), "revision")
+
+func TestRepository_GetPhotoRevisions(t *testing.T) {
+	savedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM photo_versions WHERE photo_id = \$1 AND revision IS NOT NULL ORDER BY revision DESC`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(revisionColumns).
+			AddRow(5, 1, "original", "rev2.jpg", 200, 20, 40, savedAt, "abc", 2).
+			AddRow(1, 1, "revision", "rev1.jpg", 100, 10, 20, savedAt, nil, 1))
+
+	repo := NewRepository(sqlx.NewDb(db, "postgres"))
+	revisions, err := repo.GetPhotoRevisions(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "original", revisions[0].VersionType.String)
+	assert.Equal(t, sql.NullInt64{Int64: 2, Valid: true}, revisions[0].Revision)
+	assert.Equal(t, "rev1.jpg", revisions[1].UUIDFilename)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CreatePhotoRevision(t *testing.T) {
+	savedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	params := &model.CreatePhotoRevisionParams{
+		PhotoID:         1,
+		UUIDFilename:    "rev2.jpg",
+		Size:            200,
+		Height:          20,
+		Width:           40,
+		SavedAt:         savedAt,
+		Checksum:        "abc",
+		PendingUploadID: 9,
+	}
+
+	expectLock := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT id FROM photos WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+	}
+
+	tests := []struct {
+		name             string
+		params           *model.CreatePhotoRevisionParams
+		mockSetup        func(mock sqlmock.Sqlmock)
+		expectedRevision int64
+		expectedPrevious *model.PhotoVersion
+		expectedError    error
+	}{
+		{
+			name:   "Valid",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock)
+				mock.ExpectQuery(`UPDATE photo_versions SET version_type = 'revision' WHERE photo_id = \$1 AND \(version_type = 'original'`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(revisionColumns).
+						AddRow(1, 1, "revision", "rev1.jpg", 100, 10, 20, savedAt, nil, 1))
+				mock.ExpectQuery(`INSERT INTO photo_versions (.+) VALUES \(\$1, 'original', (.+) COALESCE\(MAX\(revision\), 0\) \+ 1`).
+					WithArgs(1, "rev2.jpg", int64(200), 20, 40, savedAt, "abc").
+					WillReturnRows(sqlmock.NewRows(revisionColumns).
+						AddRow(5, 1, "original", "rev2.jpg", 200, 20, 40, savedAt, "abc", 2))
+				mock.ExpectExec("UPDATE pending_uploads").
+					WithArgs(1, 9).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedRevision: 2,
+			expectedPrevious: &model.PhotoVersion{
+				ID: 1, PhotoID: 1, VersionType: sql.NullString{String: "revision", Valid: true}, UUIDFilename: "rev1.jpg",
+				Size: 100, Height: 10, Width: 20, SavedAt: &sql.NullTime{Time: savedAt, Valid: true},
+				Revision: sql.NullInt64{Int64: 1, Valid: true},
+			},
+		},
+		{
+			name:   "Photo not found",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM photos`).WithArgs(1).WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name:   "Pending upload removed by reconciler",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock)
+				mock.ExpectQuery(`UPDATE photo_versions`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(revisionColumns))
+				mock.ExpectQuery(`INSERT INTO photo_versions`).
+					WillReturnRows(sqlmock.NewRows(revisionColumns).
+						AddRow(5, 1, "original", "rev2.jpg", 200, 20, 40, savedAt, "abc", 1))
+				mock.ExpectExec("UPDATE pending_uploads").
+					WithArgs(1, 9).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name:   "Insert error",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock)
+				mock.ExpectQuery(`UPDATE photo_versions`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(revisionColumns))
+				mock.ExpectQuery(`INSERT INTO photo_versions`).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			expectedError: def.InsertError,
+		},
+		{
+			name:          "Invalid params",
+			params:        &model.CreatePhotoRevisionParams{PhotoID: 1},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			created, previous, err := repo.CreatePhotoRevision(context.Background(), tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedRevision, created.Revision.Int64)
+				assert.Equal(t, "original", created.VersionType.String)
+				assert.Equal(t, tt.expectedPrevious, previous)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_RestorePhotoRevision(t *testing.T) {
+	savedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	expectLock := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT id FROM photos`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+	}
+
+	tests := []struct {
+		name               string
+		mockSetup          func(mock sqlmock.Sqlmock)
+		expectedType       string
+		expectedPreviousID int
+		expectedError      error
+	}{
+		{
+			name: "Restored",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock)
+				mock.ExpectQuery(`FROM photo_versions WHERE photo_id = \$1 AND revision = \$2`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows(revisionColumns).
+						AddRow(1, 1, "revision", "rev1.jpg", 100, 10, 20, savedAt, nil, 1))
+				mock.ExpectQuery(`UPDATE photo_versions SET version_type = 'revision'`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(revisionColumns).
+						AddRow(5, 1, "revision", "rev2.jpg", 200, 20, 40, savedAt, "abc", 2))
+				mock.ExpectQuery(`UPDATE photo_versions SET version_type = 'original' WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(revisionColumns).
+						AddRow(1, 1, "original", "rev1.jpg", 100, 10, 20, savedAt, nil, 1))
+				mock.ExpectCommit()
+			},
+			expectedType:       "original",
+			expectedPreviousID: 5,
+		},
+		{
+			name: "Already current",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock)
+				mock.ExpectQuery(`FROM photo_versions`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows(revisionColumns).
+						AddRow(1, 1, "original", "rev1.jpg", 100, 10, 20, savedAt, nil, 1))
+				mock.ExpectCommit()
+			},
+			expectedType: "original",
+		},
+		{
+			name: "Revision not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock)
+				mock.ExpectQuery(`FROM photo_versions`).WithArgs(1, 1).WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			restored, previous, err := repo.RestorePhotoRevision(context.Background(), 1, 1)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedType, restored.VersionType.String)
+				if tt.expectedPreviousID == 0 {
+					assert.Nil(t, previous)
+				} else {
+					assert.Equal(t, tt.expectedPreviousID, previous.ID)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
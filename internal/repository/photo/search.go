@@ -0,0 +1,60 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+)
+
+// searchHeadlineOptions выделяет совпадения тегом <b> и оставляет до двух фрагментов текста
+const searchHeadlineOptions = `StartSel=<b>, StopSel=</b>, MaxFragments=2, FragmentDelimiter=" … "`
+
+func (r *repository) SearchUserPhotos(ctx context.Context, userUUID string, params *repoModel.PhotoSearchParams) (_ []repoModel.FoundPhoto, err error) {
+	ctx, span := startSpan(ctx, "SearchUserPhotos")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	photos := []repoModel.FoundPhoto{}
+
+	// Документ поиска search_vector поддерживают триггеры photos, photo_versions, albums и album_photos
+	query := `
+		SELECT ` + photoSelectColumns + `,` + originalSelectColumns + `,
+		       ts_rank(search_vector, q.query) AS rank,
+		       ts_headline('simple', concat_ws(' ', title, caption, filename), q.query, :headline_options) AS highlight
+		FROM photos
+		CROSS JOIN to_tsquery('simple', :query) AS q(query)
+		WHERE user_uuid = :user_uuid AND deleted_at IS NULL AND search_vector @@ q.query`
+
+	queryParams := map[string]interface{}{
+		"headline_options": searchHeadlineOptions,
+		"query":            params.TSQuery(),
+		"user_uuid":        userUUID,
+		"limit":            params.Limit,
+		"offset":           params.Offset,
+	}
+	query += params.MapToArgs(queryParams)
+	query += `
+		ORDER BY rank DESC, uploaded_at DESC, id DESC
+		LIMIT :limit OFFSET :offset`
+
+	namedQuery, args, err := sqlx.Named(query, queryParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare query: %w", err)
+	}
+
+	err = r.db.SelectContext(ctx, &photos, r.db.Rebind(namedQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search user photos: %w", err)
+	}
+
+	return photos, nil
+}
@@ -0,0 +1,95 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+)
+
+func TestRepository_SearchUserPhotos(t *testing.T) {
+	favorite := true
+
+	tests := []struct {
+		name           string
+		params         *model.PhotoSearchParams
+		mockSetup      func(mock sqlmock.Sqlmock)
+		expectedPhotos []model.FoundPhoto
+		expectedError  error
+	}{
+		{
+			name:   "Ranked with highlight",
+			params: &model.PhotoSearchParams{Terms: []string{"море", "img"}, PhotoListParams: model.PhotoListParams{Limit: 50}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`ts_rank\(search_vector, q.query\) AS rank, `+
+					`ts_headline\('simple', concat_ws\(' ', title, caption, filename\), q.query, \$1\) AS highlight `+
+					`FROM photos CROSS JOIN to_tsquery\('simple', \$2\) AS q\(query\) `+
+					`WHERE user_uuid = \$3 AND deleted_at IS NULL AND search_vector @@ q.query `+
+					`ORDER BY rank DESC, uploaded_at DESC, id DESC LIMIT \$4 OFFSET \$5`).
+					WithArgs(searchHeadlineOptions, "море:* & img:*", "user-uuid", 50, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_uuid", "filename", "rank", "highlight"}).
+						AddRow(1, "user-uuid", "IMG_1.jpg", 0.6, "<b>Море</b> <b>IMG</b>_1.jpg"))
+			},
+			expectedPhotos: []model.FoundPhoto{
+				{Photo: model.Photo{ID: 1, UserUUID: "user-uuid", Filename: "IMG_1.jpg"}, Rank: 0.6, Highlight: "<b>Море</b> <b>IMG</b>_1.jpg"},
+			},
+		},
+		{
+			name: "With list filters",
+			params: &model.PhotoSearchParams{
+				Terms:           []string{"canon"},
+				PhotoListParams: model.PhotoListParams{Favorite: &favorite, Limit: 10, Offset: 20},
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AND search_vector @@ q.query AND favorite = \$4 ORDER BY rank DESC`).
+					WithArgs(searchHeadlineOptions, "canon:*", "user-uuid", true, 10, 20).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			expectedPhotos: []model.FoundPhoto{},
+		},
+		{
+			name:   "Select error",
+			params: &model.PhotoSearchParams{Terms: []string{"canon"}, PhotoListParams: model.PhotoListParams{Limit: 10}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM photos CROSS JOIN to_tsquery`).WillReturnError(errors.New("select error"))
+			},
+			expectedError: errors.New("select error"),
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+		{
+			name:          "Term with tsquery operators",
+			params:        &model.PhotoSearchParams{Terms: []string{"a|b"}, PhotoListParams: model.PhotoListParams{Limit: 10}},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			photos, err := repo.SearchUserPhotos(context.Background(), "user-uuid", tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorContains(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPhotos, photos)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (r *repository) SavePhotoShare(ctx context.Context, photoID int, params *repoModel.SaveShareParams) (_ *repoModel.Share, err error) {
+	ctx, span := startSpan(ctx, "SavePhotoShare", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		INSERT INTO shares (photo_id, grantee_uuid, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (photo_id, grantee_uuid) WHERE photo_id IS NOT NULL DO UPDATE
+		SET role = EXCLUDED.role
+		RETURNING grantee_uuid, role, created_at`
+
+	return r.saveShare(ctx, query, photoID, params)
+}
+
+func (r *repository) SaveAlbumShare(ctx context.Context, albumID int, params *repoModel.SaveShareParams) (_ *repoModel.Share, err error) {
+	ctx, span := startSpan(ctx, "SaveAlbumShare", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		INSERT INTO shares (album_id, grantee_uuid, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (album_id, grantee_uuid) WHERE album_id IS NOT NULL DO UPDATE
+		SET role = EXCLUDED.role
+		RETURNING grantee_uuid, role, created_at`
+
+	return r.saveShare(ctx, query, albumID, params)
+}
+
+func (r *repository) GetPhotoShares(ctx context.Context, photoID int) (_ []repoModel.Share, err error) {
+	ctx, span := startSpan(ctx, "GetPhotoShares", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		SELECT grantee_uuid, role, created_at
+		FROM shares
+		WHERE photo_id = $1
+		ORDER BY created_at, id`
+
+	return r.getShares(ctx, query, photoID)
+}
+
+func (r *repository) GetAlbumShares(ctx context.Context, albumID int) (_ []repoModel.Share, err error) {
+	ctx, span := startSpan(ctx, "GetAlbumShares", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		SELECT grantee_uuid, role, created_at
+		FROM shares
+		WHERE album_id = $1
+		ORDER BY created_at, id`
+
+	return r.getShares(ctx, query, albumID)
+}
+
+func (r *repository) GetPhotoShare(ctx context.Context, photoID int, granteeUUID string) (_ *repoModel.Share, err error) {
+	ctx, span := startSpan(ctx, "GetPhotoShare", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		SELECT grantee_uuid, role, created_at
+		FROM shares
+		WHERE photo_id = $1 AND grantee_uuid = $2`
+
+	return r.getShare(ctx, query, photoID, granteeUUID)
+}
+
+func (r *repository) GetAlbumShare(ctx context.Context, albumID int, granteeUUID string) (_ *repoModel.Share, err error) {
+	ctx, span := startSpan(ctx, "GetAlbumShare", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		SELECT grantee_uuid, role, created_at
+		FROM shares
+		WHERE album_id = $1 AND grantee_uuid = $2`
+
+	return r.getShare(ctx, query, albumID, granteeUUID)
+}
+
+func (r *repository) DeletePhotoShare(ctx context.Context, photoID int, granteeUUID string) (err error) {
+	ctx, span := startSpan(ctx, "DeletePhotoShare", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		DELETE FROM shares
+		WHERE photo_id = $1 AND grantee_uuid = $2`
+
+	return r.deleteShare(ctx, query, photoID, granteeUUID)
+}
+
+func (r *repository) DeleteAlbumShare(ctx context.Context, albumID int, granteeUUID string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteAlbumShare", attribute.Int("album.id", albumID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		DELETE FROM shares
+		WHERE album_id = $1 AND grantee_uuid = $2`
+
+	return r.deleteShare(ctx, query, albumID, granteeUUID)
+}
+
+func (r *repository) GetSharedPhotos(ctx context.Context, granteeUUID string, listParams *repoModel.PhotoListParams) (_ []repoModel.Photo, err error) {
+	ctx, span := startSpan(ctx, "GetSharedPhotos")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if listParams == nil {
+		return nil, repoErr.NilParamsError
+	}
+
+	photos := []repoModel.Photo{}
+
+	query := `
+		SELECT ` + photoSelectColumns + `,` + originalSelectColumns + `
+		FROM photos
+		WHERE deleted_at IS NULL
+		  AND id IN (SELECT s.photo_id FROM shares s WHERE s.grantee_uuid = :grantee_uuid)`
+
+	params := map[string]interface{}{
+		"grantee_uuid": granteeUUID,
+		"limit":        listParams.Limit,
+		"offset":       listParams.Offset,
+	}
+	query += listParams.MapToArgs(params)
+	query += `
+		ORDER BY uploaded_at DESC, id DESC
+		LIMIT :limit OFFSET :offset`
+
+	namedQuery, args, err := sqlx.Named(query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare query: %w", err)
+	}
+
+	err = r.db.SelectContext(ctx, &photos, r.db.Rebind(namedQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared photos: %w", err)
+	}
+
+	return photos, nil
+}
+
+func (r *repository) GetSharedAlbums(ctx context.Context, granteeUUID string) (_ []repoModel.SharedAlbum, err error) {
+	ctx, span := startSpan(ctx, "GetSharedAlbums")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	albums := []repoModel.SharedAlbum{}
+
+	query := `
+		SELECT a.id, a.user_uuid, a.title, a.filter, a.manual, a.public_token, a.metadata_policy, a.watermark, a.created_at, a.updated_at, s.role
+		FROM shares s
+		JOIN albums a ON a.id = s.album_id
+		WHERE s.grantee_uuid = $1
+		ORDER BY s.created_at DESC, s.id DESC`
+
+	err = r.db.SelectContext(ctx, &albums, query, granteeUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared albums: %w", err)
+	}
+
+	return albums, nil
+}
+
+func (r *repository) saveShare(ctx context.Context, query string, id int, params *repoModel.SaveShareParams) (*repoModel.Share, error) {
+	if params == nil {
+		return nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	var share repoModel.Share
+
+	err := r.db.GetContext(ctx, &share, query, id, params.GranteeUUID, params.Role)
+	if err != nil {
+		return nil, fmt.Errorf("share %w: %v", repoErr.InsertError, err)
+	}
+
+	return &share, nil
+}
+
+func (r *repository) getShares(ctx context.Context, query string, id int) ([]repoModel.Share, error) {
+	shares := []repoModel.Share{}
+
+	err := r.db.SelectContext(ctx, &shares, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+func (r *repository) getShare(ctx context.Context, query string, id int, granteeUUID string) (*repoModel.Share, error) {
+	var share repoModel.Share
+
+	err := r.db.GetContext(ctx, &share, query, id, granteeUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no share of %d with user %s", repoErr.NotFoundError, id, granteeUUID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share: %w", err)
+	}
+
+	return &share, nil
+}
+
+func (r *repository) deleteShare(ctx context.Context, query string, id int, granteeUUID string) error {
+	res, err := r.db.ExecContext(ctx, query, id, granteeUUID)
+	if err != nil {
+		return fmt.Errorf("failed to delete share: %w", err)
+	}
+
+	affectedCnt, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+	if affectedCnt < 1 {
+		return fmt.Errorf("%w: no share of %d with user %s", repoErr.NotFoundError, id, granteeUUID)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+var shareColumns = []string{"grantee_uuid", "role", "created_at"}
+
+func TestRepository_SaveShare(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		call          func(repo *repository) (*model.Share, error)
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedShare *model.Share
+		expectedError error
+	}{
+		{
+			name: "Photo",
+			call: func(repo *repository) (*model.Share, error) {
+				return repo.SavePhotoShare(context.Background(), 1, &model.SaveShareParams{GranteeUUID: "2def5", Role: "viewer"})
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO shares \(photo_id, grantee_uuid, role\) VALUES \(\$1, \$2, \$3\) `+
+					`ON CONFLICT \(photo_id, grantee_uuid\) WHERE photo_id IS NOT NULL DO UPDATE SET role = EXCLUDED.role`).
+					WithArgs(1, "2def5", "viewer").
+					WillReturnRows(sqlmock.NewRows(shareColumns).AddRow("2def5", "viewer", createdAt))
+			},
+			expectedShare: &model.Share{GranteeUUID: "2def5", Role: "viewer", CreatedAt: createdAt},
+		},
+		{
+			name: "Album",
+			call: func(repo *repository) (*model.Share, error) {
+				return repo.SaveAlbumShare(context.Background(), 1, &model.SaveShareParams{GranteeUUID: "2def5", Role: "contributor"})
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO shares \(album_id, grantee_uuid, role\) VALUES \(\$1, \$2, \$3\) `+
+					`ON CONFLICT \(album_id, grantee_uuid\) WHERE album_id IS NOT NULL DO UPDATE SET role = EXCLUDED.role`).
+					WithArgs(1, "2def5", "contributor").
+					WillReturnRows(sqlmock.NewRows(shareColumns).AddRow("2def5", "contributor", createdAt))
+			},
+			expectedShare: &model.Share{GranteeUUID: "2def5", Role: "contributor", CreatedAt: createdAt},
+		},
+		{
+			name: "No role",
+			call: func(repo *repository) (*model.Share, error) {
+				return repo.SavePhotoShare(context.Background(), 1, &model.SaveShareParams{GranteeUUID: "2def5"})
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			share, err := tt.call(repo)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedShare, share)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetAndDeleteShare(t *testing.T) {
+	tests := []struct {
+		name          string
+		call          func(repo *repository) error
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "Get photo share",
+			call: func(repo *repository) error {
+				_, err := repo.GetPhotoShare(context.Background(), 1, "2def5")
+				return err
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT grantee_uuid, role, created_at FROM shares WHERE photo_id = \$1 AND grantee_uuid = \$2`).
+					WithArgs(1, "2def5").
+					WillReturnRows(sqlmock.NewRows(shareColumns).AddRow("2def5", "viewer", time.Now()))
+			},
+		},
+		{
+			name: "Get album share not found",
+			call: func(repo *repository) error {
+				_, err := repo.GetAlbumShare(context.Background(), 1, "2def5")
+				return err
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT grantee_uuid, role, created_at FROM shares WHERE album_id = \$1 AND grantee_uuid = \$2`).
+					WithArgs(1, "2def5").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Delete photo share",
+			call: func(repo *repository) error { return repo.DeletePhotoShare(context.Background(), 1, "2def5") },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM shares WHERE photo_id = \$1 AND grantee_uuid = \$2`).
+					WithArgs(1, "2def5").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Delete album share not found",
+			call: func(repo *repository) error { return repo.DeleteAlbumShare(context.Background(), 1, "2def5") },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM shares WHERE album_id = \$1 AND grantee_uuid = \$2`).
+					WithArgs(1, "2def5").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			err = tt.call(repo)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetSharedPhotos(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(sqlx.NewDb(db, "postgres"))
+
+	favorite := true
+	mock.ExpectQuery(`FROM photos WHERE deleted_at IS NULL AND id IN \(SELECT s.photo_id FROM shares s WHERE s.grantee_uuid = \$1\) `+
+		`AND favorite = \$2 ORDER BY uploaded_at DESC, id DESC LIMIT \$3 OFFSET \$4`).
+		WithArgs("2def5", true, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_uuid", "filename"}).AddRow(1, "1abc4", "a.jpg"))
+
+	photos, err := repo.GetSharedPhotos(context.Background(), "2def5", &model.PhotoListParams{Favorite: &favorite, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, photos, 1)
+	assert.Equal(t, "1abc4", photos[0].UserUUID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetSharedAlbums(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(sqlx.NewDb(db, "postgres"))
+
+	mock.ExpectQuery(`SELECT a.id, (.+), s.role FROM shares s JOIN albums a ON a.id = s.album_id WHERE s.grantee_uuid = \$1`).
+		WithArgs("2def5").
+		WillReturnRows(sqlmock.NewRows(append(append([]string{}, albumColumns...), "role")).
+			AddRow(1, "1abc4", "Best", []byte(`{}`), false, nil, nil, false, time.Now(), time.Now(), "contributor"))
+
+	albums, err := repo.GetSharedAlbums(context.Background(), "2def5")
+	require.NoError(t, err)
+	require.Len(t, albums, 1)
+	assert.Equal(t, "1abc4", albums[0].UserUUID)
+	assert.Equal(t, "contributor", albums[0].Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,37 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	repoErr "go-photo/internal/repository/error"
+	pkgRepo "go-photo/pkg/repository"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (r *repository) AddPhotoTag(ctx context.Context, photoID int, tag string) (err error) {
+	ctx, span := startSpan(ctx, "AddPhotoTag", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if tag == "" {
+		return fmt.Errorf("%w: empty tag", repoErr.InvalidParamsError)
+	}
+
+	query := `
+		INSERT INTO photo_tags (photo_id, tag)
+		VALUES ($1, $2)
+		ON CONFLICT (photo_id, tag) DO NOTHING`
+
+	_, err = r.db.ExecContext(ctx, query, photoID, tag)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pkgRepo.ForeignKeyViolationErrorCode {
+			return fmt.Errorf("%w: no photo %d: %v", repoErr.NotFoundError, photoID, err)
+		}
+		return fmt.Errorf("failed to add photo tag: %w", err)
+	}
+
+	return nil
+}
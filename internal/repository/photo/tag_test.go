@@ -0,0 +1,66 @@
+package photo
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"testing"
+)
+
+func TestRepository_AddPhotoTag(t *testing.T) {
+	tests := []struct {
+		name          string
+		tag           string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "Success",
+			tag:  "sea",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO photo_tags \(photo_id, tag\) VALUES \(\$1, \$2\) ON CONFLICT \(photo_id, tag\) DO NOTHING`).
+					WithArgs(5, "sea").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Deleted photo",
+			tag:  "sea",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO photo_tags`).
+					WithArgs(5, "sea").
+					WillReturnError(&pq.Error{Code: "23503"})
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name:          "Empty tag",
+			tag:           "",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			err = repo.AddPhotoTag(context.Background(), 5, tt.tag)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+)
+
+// localTakenAtJoin добавляет к photos местное время съемки t.local_taken_at в часовом поясе :time_zone.
+// Выражение общее с фильтром дней съемки списка фото, чтобы границы дней совпадали.
+const localTakenAtJoin = `
+		CROSS JOIN LATERAL (
+			SELECT ` + repoModel.LocalTakenAt + ` AS local_taken_at
+		) t`
+
+func (r *repository) GetUserTimeline(ctx context.Context, userUUID string, params *repoModel.TimelineParams) (_ []repoModel.TimelineBucket, err error) {
+	ctx, span := startSpan(ctx, "GetUserTimeline")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	buckets := []repoModel.TimelineBucket{}
+
+	// Период представляет избранное фото с наибольшей оценкой, из равных - снятое последним
+	query := `
+		SELECT date_trunc(:granularity, t.local_taken_at) AS start,
+		       count(*) AS count,
+		       (array_agg(photos.id ORDER BY photos.favorite DESC, photos.rating DESC, t.local_taken_at DESC, photos.id DESC))[1] AS cover_photo_id
+		FROM photos` + localTakenAtJoin + `
+		WHERE photos.user_uuid = :user_uuid AND photos.deleted_at IS NULL AND NOT photos.hidden`
+
+	queryParams := map[string]interface{}{
+		"granularity": params.Granularity,
+		"time_zone":   params.TimeZone,
+		"user_uuid":   userUUID,
+		"limit":       params.Limit,
+	}
+	if params.Before != nil {
+		query += `
+		  AND date_trunc(:granularity, t.local_taken_at) <= :before`
+		queryParams["before"] = *params.Before
+	}
+	query += `
+		GROUP BY start
+		ORDER BY start DESC
+		LIMIT :limit`
+
+	namedQuery, args, err := sqlx.Named(query, queryParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare query: %w", err)
+	}
+
+	err = r.db.SelectContext(ctx, &buckets, r.db.Rebind(namedQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user timeline: %w", err)
+	}
+
+	return buckets, nil
+}
+
+func (r *repository) GetUserPhotosOnThisDay(ctx context.Context, userUUID string, params *repoModel.OnThisDayParams) (_ []repoModel.Photo, err error) {
+	ctx, span := startSpan(ctx, "GetUserPhotosOnThisDay")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	photos := []repoModel.Photo{}
+
+	query := `
+		SELECT ` + photoSelectColumns + `,` + originalSelectColumns + `
+		FROM photos` + localTakenAtJoin + `
+		WHERE user_uuid = :user_uuid AND deleted_at IS NULL AND NOT hidden
+		  AND to_char(t.local_taken_at, 'MM-DD') = ANY(:days)
+		  AND date_part('year', t.local_taken_at) < :year
+		ORDER BY t.local_taken_at DESC, id DESC
+		LIMIT :limit`
+
+	queryParams := map[string]interface{}{
+		"time_zone": params.TimeZone,
+		"user_uuid": userUUID,
+		"days":      pq.Array(params.Days()),
+		"year":      params.Date.Year(),
+		"limit":     params.Limit,
+	}
+
+	namedQuery, args, err := sqlx.Named(query, queryParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare query: %w", err)
+	}
+
+	err = r.db.SelectContext(ctx, &photos, r.db.Rebind(namedQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user photos on this day: %w", err)
+	}
+
+	return photos, nil
+}
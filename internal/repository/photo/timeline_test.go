@@ -0,0 +1,156 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+func TestRepository_GetUserTimeline(t *testing.T) {
+	bucketColumns := []string{"start", "count", "cover_photo_id"}
+	before := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		params          *model.TimelineParams
+		mockSetup       func(mock sqlmock.Sqlmock)
+		expectedBuckets []model.TimelineBucket
+		expectedError   error
+	}{
+		{
+			name:   "Months",
+			params: &model.TimelineParams{Granularity: "month", TimeZone: "Europe/Moscow", Limit: 2},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT date_trunc\(\$1, t.local_taken_at\) AS start, .* FROM photos CROSS JOIN LATERAL \( `+
+					`SELECT COALESCE\( \(SELECT pv.taken_at .*\), photos.uploaded_at AT TIME ZONE 'UTC' AT TIME ZONE \$2\) AS local_taken_at \) t `+
+					`WHERE photos.user_uuid = \$3 AND photos.deleted_at IS NULL AND NOT photos.hidden GROUP BY start ORDER BY start DESC LIMIT \$4`).
+					WithArgs("month", "Europe/Moscow", "user-uuid", 2).
+					WillReturnRows(sqlmock.NewRows(bucketColumns).
+						AddRow(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), 12, 7).
+						AddRow(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), 1, 3))
+			},
+			expectedBuckets: []model.TimelineBucket{
+				{Start: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), Count: 12, CoverPhotoID: 7},
+				{Start: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Count: 1, CoverPhotoID: 3},
+			},
+		},
+		{
+			name:   "From cursor",
+			params: &model.TimelineParams{Granularity: "month", TimeZone: "UTC", Before: &before, Limit: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AND NOT photos.hidden AND date_trunc\(\$4, t.local_taken_at\) <= \$5 GROUP BY start`).
+					WithArgs("month", "UTC", "user-uuid", "month", before, 10).
+					WillReturnRows(sqlmock.NewRows(bucketColumns))
+			},
+			expectedBuckets: []model.TimelineBucket{},
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+		{
+			name:          "Invalid granularity",
+			params:        &model.TimelineParams{Granularity: "week", TimeZone: "UTC", Limit: 10},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			buckets, err := repo.GetUserTimeline(context.Background(), "user-uuid", tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedBuckets, buckets)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetUserPhotosOnThisDay(t *testing.T) {
+	takenAt := time.Date(2021, 7, 14, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		params         *model.OnThisDayParams
+		mockSetup      func(mock sqlmock.Sqlmock)
+		expectedPhotos []model.Photo
+		expectedError  error
+	}{
+		{
+			name:   "Same day in previous years",
+			params: &model.OnThisDayParams{TimeZone: "Europe/Moscow", Date: time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC), Limit: 50},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`AT TIME ZONE \$1\) AS local_taken_at \) t `+
+					`WHERE user_uuid = \$2 AND deleted_at IS NULL AND NOT hidden AND to_char\(t.local_taken_at, 'MM-DD'\) = ANY\(\$3\) `+
+					`AND date_part\('year', t.local_taken_at\) < \$4 ORDER BY t.local_taken_at DESC, id DESC LIMIT \$5`).
+					WithArgs("Europe/Moscow", "user-uuid", pq.Array([]string{"07-14"}), 2024, 50).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_uuid", "filename", "taken_at"}).
+						AddRow(1, "user-uuid", "a.jpg", takenAt))
+			},
+			expectedPhotos: []model.Photo{
+				{ID: 1, UserUUID: "user-uuid", Filename: "a.jpg", TakenAt: sql.NullTime{Time: takenAt, Valid: true}},
+			},
+		},
+		{
+			name:   "February 28 of a common year",
+			params: &model.OnThisDayParams{TimeZone: "UTC", Date: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC), Limit: 50},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`= ANY\(\$3\)`).
+					WithArgs("UTC", "user-uuid", pq.Array([]string{"02-28", "02-29"}), 2023, 50).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			expectedPhotos: []model.Photo{},
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+		{
+			name:          "No date",
+			params:        &model.OnThisDayParams{TimeZone: "UTC", Limit: 50},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			photos, err := repo.GetUserPhotosOnThisDay(context.Background(), "user-uuid", tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPhotos, photos)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
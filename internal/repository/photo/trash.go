@@ -0,0 +1,143 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/logger"
+	"go-photo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"time"
+)
+
+func (r *repository) TrashPhoto(ctx context.Context, photoID int) (err error) {
+	ctx, span := startSpan(ctx, "TrashPhoto", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		UPDATE photos
+		SET deleted_at = now()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	return r.updatePhotoAffectingOne(ctx, query, photoID)
+}
+
+func (r *repository) RestorePhoto(ctx context.Context, photoID int) (err error) {
+	ctx, span := startSpan(ctx, "RestorePhoto", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	query := `
+		UPDATE photos
+		SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	return r.updatePhotoAffectingOne(ctx, query, photoID)
+}
+
+func (r *repository) GetTrashedPhotos(ctx context.Context, userUUID string) (_ []repoModel.Photo, err error) {
+	ctx, span := startSpan(ctx, "GetTrashedPhotos")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var photos []repoModel.Photo
+
+	query := `
+		SELECT id, user_uuid, filename, uploaded_at, deleted_at
+		FROM photos
+		WHERE user_uuid = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC`
+
+	err = r.db.SelectContext(ctx, &photos, query, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed photos: %w", err)
+	}
+
+	return photos, nil
+}
+
+func (r *repository) GetPhotosTrashedBefore(ctx context.Context, before time.Time, limit int) (_ []repoModel.Photo, err error) {
+	ctx, span := startSpan(ctx, "GetPhotosTrashedBefore")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var photos []repoModel.Photo
+
+	query := `
+		SELECT id, user_uuid, filename, uploaded_at, deleted_at
+		FROM photos
+		WHERE deleted_at < $1
+		ORDER BY deleted_at, id
+		LIMIT $2`
+
+	err = r.db.SelectContext(ctx, &photos, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed photos: %w", err)
+	}
+
+	return photos, nil
+}
+
+func (r *repository) DeleteTrashedPhoto(ctx context.Context, photoID int) (err error) {
+	ctx, span := startSpan(ctx, "DeleteTrashedPhoto", attribute.Int("photo.id", photoID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", repoErr.BeginTxError, err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.FromContext(ctx).Errorf("failed to rollback transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	// Блокируем фото, чтобы его не восстановили, пока удаляются зависимые записи
+	var id int
+	err = tx.QueryRowContext(ctx, `
+		SELECT id
+		FROM photos
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		FOR UPDATE`, photoID).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("%w: no trashed photo with id %d: %v", repoErr.NotFoundError, photoID, err)
+	}
+
+	dependents := []string{
+		`DELETE FROM published_photo_info WHERE photo_id = $1`,
+		`DELETE FROM pending_uploads WHERE photo_id = $1`,
+		`DELETE FROM photo_versions WHERE photo_id = $1`,
+		`DELETE FROM photos WHERE id = $1`,
+	}
+	for _, query := range dependents {
+		_, err = tx.ExecContext(ctx, query, photoID)
+		if err != nil {
+			return fmt.Errorf("failed to delete photo: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) updatePhotoAffectingOne(ctx context.Context, query string, photoID int) error {
+	res, err := r.db.ExecContext(ctx, query, photoID)
+	if err != nil {
+		return fmt.Errorf("failed to update photo: %w", err)
+	}
+
+	affectedCnt, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+	if affectedCnt < 1 {
+		return fmt.Errorf("%w: no rows affected with id %d", repoErr.NotFoundError, photoID)
+	}
+
+	return nil
+}
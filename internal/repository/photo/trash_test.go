@@ -0,0 +1,196 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+var trashedPhotoColumns = []string{"id", "user_uuid", "filename", "uploaded_at", "deleted_at"}
+
+func TestRepository_TrashAndRestorePhoto(t *testing.T) {
+	tests := []struct {
+		name          string
+		call          func(repo *repository) error
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "Trash",
+			call: func(repo *repository) error { return repo.TrashPhoto(context.Background(), 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE photos SET deleted_at = now\(\) WHERE id = \$1 AND deleted_at IS NULL`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Trash already trashed",
+			call: func(repo *repository) error { return repo.TrashPhoto(context.Background(), 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE photos").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Restore",
+			call: func(repo *repository) error { return repo.RestorePhoto(context.Background(), 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE photos SET deleted_at = NULL WHERE id = \$1 AND deleted_at IS NOT NULL`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Restore not trashed",
+			call: func(repo *repository) error { return repo.RestorePhoto(context.Background(), 1) },
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE photos").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+
+			tt.mockSetup(mock)
+
+			err = tt.call(repo)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetTrashedPhotos(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery("SELECT (.+) FROM photos WHERE user_uuid = \\$1 AND deleted_at IS NOT NULL").
+		WithArgs("user-uuid").
+		WillReturnRows(sqlmock.NewRows(trashedPhotoColumns).AddRow(1, "user-uuid", "a.png", nil, deletedAt))
+
+	photos, err := repo.GetTrashedPhotos(context.Background(), "user-uuid")
+	require.NoError(t, err)
+	assert.Equal(t, []model.Photo{
+		{ID: 1, UserUUID: "user-uuid", Filename: "a.png", DeletedAt: sql.NullTime{Time: deletedAt, Valid: true}},
+	}, photos)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetPhotosTrashedBefore(t *testing.T) {
+	before := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery("SELECT (.+) FROM photos WHERE deleted_at < \\$1 ORDER BY deleted_at, id LIMIT \\$2").
+		WithArgs(before, 10).
+		WillReturnRows(sqlmock.NewRows(trashedPhotoColumns).AddRow(1, "user-uuid", "a.png", nil, before.Add(-time.Hour)))
+
+	photos, err := repo.GetPhotosTrashedBefore(context.Background(), before, 10)
+	require.NoError(t, err)
+	assert.Len(t, photos, 1)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_DeleteTrashedPhoto(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "Valid",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM photos WHERE id = \\$1 AND deleted_at IS NOT NULL FOR UPDATE").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+				mock.ExpectExec("DELETE FROM published_photo_info").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM pending_uploads").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM photo_versions").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM photos").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Photo not in trash",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM photos").
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Delete error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM photos").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(idColumn).AddRow(1))
+				mock.ExpectExec("DELETE FROM published_photo_info").WithArgs(1).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "sqlmock"))
+
+			tt.mockSetup(mock)
+
+			err = repo.DeleteTrashedPhoto(context.Background(), 1)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/tracing"
+)
+
+const watermarkSelectColumns = `user_uuid, type, text, logo_filename, position, opacity, scale, updated_at`
+
+func (r *repository) GetWatermarkByToken(ctx context.Context, token string) (_ *repoModel.WatermarkSettings, err error) {
+	ctx, span := startSpan(ctx, "GetWatermarkByToken")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var settings repoModel.WatermarkSettings
+
+	query := `
+		SELECT ws.user_uuid, ws.type, ws.text, ws.logo_filename, ws.position, ws.opacity, ws.scale, ws.updated_at
+		FROM published_photo_info ppi
+		JOIN photos p ON ppi.photo_id = p.id AND p.deleted_at IS NULL
+		JOIN watermark_settings ws ON ws.user_uuid = p.user_uuid
+		WHERE ppi.public_token = $1 AND ppi.watermark`
+
+	err = r.db.GetContext(ctx, &settings, query, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, r.missingPublicationWatermark(ctx, token)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermark by token: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// missingPublicationWatermark объясняет, почему для публикации не нашелся водяной знак. Публикация без знака -
+// NotFoundError, а отсутствие настроек у публикации со знаком - ошибка: файлы нельзя отдавать без знака.
+func (r *repository) missingPublicationWatermark(ctx context.Context, token string) error {
+	var watermarked bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM published_photo_info ppi
+			JOIN photos p ON ppi.photo_id = p.id AND p.deleted_at IS NULL
+			WHERE ppi.public_token = $1 AND ppi.watermark)`
+
+	err := r.db.GetContext(ctx, &watermarked, query, token)
+	if err != nil {
+		return fmt.Errorf("failed to check publication watermark: %w", err)
+	}
+	if watermarked {
+		return fmt.Errorf("watermark settings are missing for public token %s", token)
+	}
+
+	return fmt.Errorf("%w: no watermark for public token %s", repoErr.NotFoundError, token)
+}
+
+func (r *repository) GetWatermarkSettings(ctx context.Context, userUUID string) (_ *repoModel.WatermarkSettings, err error) {
+	ctx, span := startSpan(ctx, "GetWatermarkSettings")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var settings repoModel.WatermarkSettings
+
+	query := `
+		SELECT ` + watermarkSelectColumns + `
+		FROM watermark_settings
+		WHERE user_uuid = $1`
+
+	err = r.db.GetContext(ctx, &settings, query, userUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no watermark settings of user %s", repoErr.NotFoundError, userUUID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermark settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *repository) SaveWatermarkSettings(ctx context.Context, params *repoModel.SaveWatermarkSettingsParams) (_ *repoModel.WatermarkSettings, err error) {
+	ctx, span := startSpan(ctx, "SaveWatermarkSettings")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if params == nil {
+		return nil, repoErr.NilParamsError
+	}
+	if !params.IsValid() {
+		return nil, fmt.Errorf("%w: %v", repoErr.InvalidParamsError, params)
+	}
+
+	var settings repoModel.WatermarkSettings
+
+	query := `
+		INSERT INTO watermark_settings (user_uuid, type, text, position, opacity, scale)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		ON CONFLICT (user_uuid) DO UPDATE
+		SET type = EXCLUDED.type,
+		    text = EXCLUDED.text,
+		    position = EXCLUDED.position,
+		    opacity = EXCLUDED.opacity,
+		    scale = EXCLUDED.scale,
+		    updated_at = now()
+		RETURNING ` + watermarkSelectColumns
+
+	err = r.db.GetContext(ctx, &settings, query,
+		params.UserUUID,
+		params.Type,
+		params.Text,
+		params.Position,
+		params.Opacity,
+		params.Scale)
+	if err != nil {
+		return nil, fmt.Errorf("watermark settings %w: %v", repoErr.InsertError, err)
+	}
+
+	return &settings, nil
+}
+
+func (r *repository) SaveWatermarkLogo(ctx context.Context, userUUID, logoFilename string) (_ *repoModel.WatermarkSettings, _ string, err error) {
+	ctx, span := startSpan(ctx, "SaveWatermarkLogo")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if userUUID == "" || logoFilename == "" {
+		return nil, "", fmt.Errorf("%w: user %q, logo %q", repoErr.InvalidParamsError, userUUID, logoFilename)
+	}
+
+	var row struct {
+		repoModel.WatermarkSettings
+		PreviousLogoFilename sql.NullString `db:"previous_logo_filename"`
+	}
+
+	// previous читает строку до изменения: все части запроса видят один снимок данных
+	query := `
+		WITH previous AS (
+			SELECT logo_filename
+			FROM watermark_settings
+			WHERE user_uuid = $1
+			FOR UPDATE
+		), saved AS (
+			INSERT INTO watermark_settings (user_uuid, type, logo_filename)
+			VALUES ($1, 'logo', $2)
+			ON CONFLICT (user_uuid) DO UPDATE
+			SET type = 'logo',
+			    logo_filename = EXCLUDED.logo_filename,
+			    updated_at = now()
+			RETURNING ` + watermarkSelectColumns + `
+		)
+		SELECT saved.*, (SELECT logo_filename FROM previous) AS previous_logo_filename
+		FROM saved`
+
+	err = r.db.GetContext(ctx, &row, query, userUUID, logoFilename)
+	if err != nil {
+		return nil, "", fmt.Errorf("watermark logo %w: %v", repoErr.InsertError, err)
+	}
+
+	return &row.WatermarkSettings, row.PreviousLogoFilename.String, nil
+}
+
+func (r *repository) DeleteWatermarkSettings(ctx context.Context, userUUID string) (_ *repoModel.WatermarkSettings, err error) {
+	ctx, span := startSpan(ctx, "DeleteWatermarkSettings")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var settings repoModel.WatermarkSettings
+
+	// Пока есть публикации со знаком, настройки не удаляются: иначе их файлы остались бы без знака
+	query := `
+		DELETE FROM watermark_settings ws
+		WHERE ws.user_uuid = $1
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM published_photo_info ppi
+			JOIN photos p ON ppi.photo_id = p.id
+			WHERE p.user_uuid = ws.user_uuid AND ppi.watermark)
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM albums a
+			WHERE a.user_uuid = ws.user_uuid AND a.public_token IS NOT NULL AND a.watermark)
+		RETURNING ` + watermarkSelectColumns
+
+	err = r.db.GetContext(ctx, &settings, query, userUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, r.undeletedWatermark(ctx, userUUID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete watermark settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// undeletedWatermark объясняет, почему настройки водяного знака не удалились: их нет (NotFoundError)
+// или они используются публикациями (ConflictError).
+func (r *repository) undeletedWatermark(ctx context.Context, userUUID string) error {
+	var exists bool
+
+	query := `
+		SELECT EXISTS (SELECT 1 FROM watermark_settings WHERE user_uuid = $1)`
+
+	err := r.db.GetContext(ctx, &exists, query, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to check watermark settings: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("%w: watermark of user %s is used by publications", repoErr.ConflictError, userUUID)
+	}
+
+	return fmt.Errorf("%w: no watermark settings of user %s", repoErr.NotFoundError, userUUID)
+}
@@ -0,0 +1,256 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	def "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/model"
+	"testing"
+	"time"
+)
+
+var watermarkColumns = []string{"user_uuid", "type", "text", "logo_filename", "position", "opacity", "scale", "updated_at"}
+
+func TestRepository_GetWatermarkByToken(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		mockSetup        func(mock sqlmock.Sqlmock)
+		expectedSettings *model.WatermarkSettings
+		expectedError    error
+	}{
+		{
+			name: "Watermarked publication",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`JOIN watermark_settings ws ON ws.user_uuid = p.user_uuid\s+WHERE ppi.public_token = \$1 AND ppi.watermark`).
+					WithArgs("token").
+					WillReturnRows(sqlmock.NewRows(watermarkColumns).
+						AddRow("user", "text", "(c) me", nil, "center", 50, 20, updatedAt))
+			},
+			expectedSettings: &model.WatermarkSettings{
+				UserUUID:  "user",
+				Type:      "text",
+				Text:      sql.NullString{String: "(c) me", Valid: true},
+				Position:  "center",
+				Opacity:   50,
+				Scale:     20,
+				UpdatedAt: updatedAt,
+			},
+		},
+		{
+			name: "No watermark",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM published_photo_info`).WithArgs("token").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM published_photo_info ppi .* WHERE ppi.public_token = \$1 AND ppi.watermark\)`).
+					WithArgs("token").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedError: def.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			settings, err := repo.GetWatermarkByToken(context.Background(), "token")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedSettings, settings)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetWatermarkByToken_MissingSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(sqlx.NewDb(db, "postgres"))
+	mock.ExpectQuery(`FROM published_photo_info`).WithArgs("token").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`SELECT EXISTS`).WithArgs("token").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// Публикация со знаком без настроек не должна выглядеть как публикация без знака
+	_, err = repo.GetWatermarkByToken(context.Background(), "token")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, def.NotFoundError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_SaveWatermarkSettings(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	params := &model.SaveWatermarkSettingsParams{
+		UserUUID: "user",
+		Type:     "text",
+		Text:     "(c) me",
+		Position: "top-left",
+		Opacity:  80,
+		Scale:    30,
+	}
+
+	tests := []struct {
+		name          string
+		params        *model.SaveWatermarkSettingsParams
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name:   "Saved",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO watermark_settings .* ON CONFLICT \(user_uuid\) DO UPDATE`).
+					WithArgs("user", "text", "(c) me", "top-left", 80, 30).
+					WillReturnRows(sqlmock.NewRows(watermarkColumns).
+						AddRow("user", "text", "(c) me", "logo.png", "top-left", 80, 30, updatedAt))
+			},
+		},
+		{
+			name:          "Nil params",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.NilParamsError,
+		},
+		{
+			name:          "Invalid params",
+			params:        &model.SaveWatermarkSettingsParams{UserUUID: "user", Type: "text"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: def.InvalidParamsError,
+		},
+		{
+			name:   "Check violation",
+			params: params,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO watermark_settings`).WillReturnError(errors.New("check violation"))
+			},
+			expectedError: def.InsertError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			settings, err := repo.SaveWatermarkSettings(context.Background(), tt.params)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "logo.png", settings.LogoFilename.String, "logo must be kept")
+				assert.Equal(t, updatedAt, settings.UpdatedAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_SaveWatermarkLogo(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	columns := append(watermarkColumns, "previous_logo_filename")
+
+	tests := []struct {
+		name             string
+		previous         any
+		expectedPrevious string
+	}{
+		{name: "First logo", previous: nil, expectedPrevious: ""},
+		{name: "Replaced logo", previous: "old.png", expectedPrevious: "old.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			mock.ExpectQuery(`WITH previous AS .* FOR UPDATE .* INSERT INTO watermark_settings`).
+				WithArgs("user", "new.png").
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("user", "logo", nil, "new.png", "bottom-right", 50, 20, updatedAt, tt.previous))
+
+			settings, previous, err := repo.SaveWatermarkLogo(context.Background(), "user", "new.png")
+			require.NoError(t, err)
+			assert.Equal(t, "logo", settings.Type)
+			assert.Equal(t, "new.png", settings.LogoFilename.String)
+			assert.Equal(t, tt.expectedPrevious, previous)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_DeleteWatermarkSettings(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "Deleted",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`DELETE FROM watermark_settings ws WHERE ws.user_uuid = \$1 AND NOT EXISTS \(.*ppi.watermark\) ` +
+					`AND NOT EXISTS \(.*a.public_token IS NOT NULL AND a.watermark\) RETURNING`).
+					WithArgs("user").
+					WillReturnRows(sqlmock.NewRows(watermarkColumns).
+						AddRow("user", "text", "(c) me", nil, "center", 50, 20, time.Now()))
+			},
+		},
+		{
+			name: "Not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`DELETE FROM watermark_settings`).WithArgs("user").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM watermark_settings WHERE user_uuid = \$1\)`).
+					WithArgs("user").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedError: def.NotFoundError,
+		},
+		{
+			name: "Used by publications",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`DELETE FROM watermark_settings`).WithArgs("user").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs("user").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			expectedError: def.ConflictError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewRepository(sqlx.NewDb(db, "postgres"))
+			tt.mockSetup(mock)
+
+			_, err = repo.DeleteWatermarkSettings(context.Background(), "user")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
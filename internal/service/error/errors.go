@@ -23,6 +23,10 @@ var (
 
 	ParticalSuccessError = errors.New("partical success")
 	AllFailedError       = errors.New("all failed")
+	// UploadQueueFullError возвращается, если очередь загрузок переполнена и запрос стоит повторить позже
+	UploadQueueFullError = errors.New("upload queue is full")
+	// UnsupportedBulkActionError возвращается для неизвестного действия над группой фото
+	UnsupportedBulkActionError = errors.New("unsupported bulk action")
 
 	UserNotFoundError         = errors.New("user not found")
 	UserAlreadyExistsError    = errors.New("user already exists")
@@ -30,4 +34,39 @@ var (
 
 	PhotoNotFoundError      = errors.New("photo not found")
 	InvalidVersionTypeError = errors.New("invalid version type")
+
+	// InvalidResizeParamsError возвращается, если параметры изменения размера не разрешены конфигурацией
+	InvalidResizeParamsError = errors.New("invalid resize params")
+
+	// InvalidPatchError возвращается, если документ изменения некорректен или дает недопустимые значения
+	InvalidPatchError = errors.New("invalid patch")
+	// PreconditionFailedError возвращается, если фото изменилось после того, как клиент получил его версию (ETag)
+	PreconditionFailedError = errors.New("precondition failed")
+	// InvalidFilterError возвращается при недопустимых параметрах списка фото
+	InvalidFilterError = errors.New("invalid filter")
+	// InvalidEditError возвращается, если операции правки неизвестны или неприменимы к фото
+	InvalidEditError = errors.New("invalid edit")
+
+	// InvalidWatermarkError возвращается при недопустимых настройках или логотипе водяного знака
+	InvalidWatermarkError = errors.New("invalid watermark")
+	// WatermarkNotFoundError возвращается, если пользователь не настроил водяной знак
+	WatermarkNotFoundError = errors.New("watermark not found")
+	// WatermarkInUseError возвращается при удалении водяного знака, с которым опубликованы фото или альбомы
+	WatermarkInUseError = errors.New("watermark in use")
+
+	// InvalidMetadataPolicyError возвращается при неизвестной политике метаданных публикации
+	InvalidMetadataPolicyError = errors.New("invalid metadata policy")
+
+	// AlbumNotFoundError возвращается, если альбом не найден или не опубликован
+	AlbumNotFoundError = errors.New("album not found")
+	// InvalidAlbumError возвращается при недопустимом названии или фильтрах альбома
+	InvalidAlbumError = errors.New("invalid album")
+
+	// InvalidTagError возвращается при пустом или слишком длинном теге фото
+	InvalidTagError = errors.New("invalid tag")
+
+	// InvalidShareError возвращается при недопустимом пользователе или роли доступа
+	InvalidShareError = errors.New("invalid share")
+	// ShareNotFoundError возвращается, если пользователю не выдан доступ
+	ShareNotFoundError = errors.New("share not found")
 )
@@ -0,0 +1,235 @@
+package fsck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/model"
+	repoModel "go-photo/internal/repository/photo/model"
+	fsckModel "go-photo/internal/service/fsck/model"
+	"go-photo/internal/utils"
+	"go-photo/pkg/logger"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (s *service) Check(ctx context.Context, opts fsckModel.Options) (*fsckModel.Report, error) {
+	report := fsckModel.NewReport(time.Now(), opts.DryRun)
+
+	versions, err := s.photoRepository.GetAllPhotoVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photo versions: %w", err)
+	}
+	report.CheckedVersions = len(versions)
+
+	known := make(map[string]struct{}, len(versions))
+	originals := make(map[int]string)
+	for _, v := range versions {
+		if !v.UserUUID.Valid {
+			continue
+		}
+		path := s.versionPath(v)
+		known[path] = struct{}{}
+		if versionType(v) == string(model.Original) {
+			originals[v.PhotoID] = path
+		}
+	}
+
+	for _, v := range versions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		issue, ok := s.checkVersion(v, opts.VerifyChecksums)
+		if !ok {
+			continue
+		}
+
+		if opts.Rederive && issue.Kind != fsckModel.OrphanVersion && isDerived(v) {
+			issue.Action = fsckModel.ActionRederive
+			if !opts.DryRun {
+				s.fix(ctx, &issue, func() error {
+					return s.rederive(ctx, v, originals[v.PhotoID])
+				})
+			}
+		}
+
+		report.Add(issue)
+	}
+
+	err = s.checkFiles(ctx, report, known, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report.FinishedAt = time.Now()
+
+	return report, nil
+}
+
+// checkVersion сверяет файл версии с записью в БД. Возвращает false, если проблем нет.
+func (s *service) checkVersion(v repoModel.PhotoVersionWithOwner, verifyChecksum bool) (fsckModel.Issue, bool) {
+	issue := fsckModel.Issue{
+		PhotoID:     v.PhotoID,
+		VersionID:   v.ID,
+		VersionType: versionType(v),
+	}
+
+	if !v.UserUUID.Valid {
+		issue.Kind = fsckModel.OrphanVersion
+		return issue, true
+	}
+
+	issue.UserUUID = v.UserUUID.String
+	issue.Path = s.versionPath(v)
+
+	info, err := os.Stat(issue.Path)
+	if err != nil {
+		issue.Kind = fsckModel.MissingFile
+		if !errors.Is(err, fs.ErrNotExist) {
+			issue.Error = err.Error()
+		}
+		return issue, true
+	}
+
+	if info.Size() != v.Size {
+		issue.Kind = fsckModel.SizeMismatch
+		issue.Expected = strconv.FormatInt(v.Size, 10)
+		issue.Actual = strconv.FormatInt(info.Size(), 10)
+		return issue, true
+	}
+
+	if verifyChecksum && v.Checksum.Valid {
+		checksum, err := utils.FileChecksum(issue.Path)
+		if err != nil || checksum != v.Checksum.String {
+			issue.Kind = fsckModel.ChecksumMismatch
+			issue.Expected = v.Checksum.String
+			issue.Actual = checksum
+			if err != nil {
+				issue.Error = err.Error()
+			}
+			return issue, true
+		}
+	}
+
+	return issue, false
+}
+
+// checkFiles ищет в папках пользователей файлы, не принадлежащие ни одной версии.
+// Служебные папки хранилища (начинаются с точки) пропускаются.
+func (s *service) checkFiles(ctx context.Context, report *fsckModel.Report, known map[string]struct{}, opts fsckModel.Options) error {
+	userDirs, err := os.ReadDir(s.d.StorageFolderPath)
+	if err != nil {
+		return fmt.Errorf("failed to read storage folder: %w", err)
+	}
+
+	// Файлы моложе начала проверки с запасом могли быть закоммичены после чтения версий из БД
+	newerThan := report.StartedAt.Add(-s.d.OrphanGracePeriod)
+
+	for _, userDir := range userDirs {
+		if !userDir.IsDir() || strings.HasPrefix(userDir.Name(), ".") {
+			continue
+		}
+
+		userUUID := userDir.Name()
+		entries, err := os.ReadDir(filepath.Join(s.d.StorageFolderPath, userUUID))
+		if err != nil {
+			return fmt.Errorf("failed to read user folder %s: %w", userUUID, err)
+		}
+
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				continue
+			}
+			report.CheckedFiles++
+
+			path := filepath.Join(s.d.StorageFolderPath, userUUID, entry.Name())
+			if _, ok := known[path]; ok {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil || info.ModTime().After(newerThan) {
+				continue
+			}
+
+			issue := fsckModel.Issue{
+				Kind:     fsckModel.OrphanFile,
+				Path:     path,
+				UserUUID: userUUID,
+				Actual:   strconv.FormatInt(info.Size(), 10),
+			}
+			s.handleOrphan(ctx, &issue, opts)
+			report.Add(issue)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) handleOrphan(ctx context.Context, issue *fsckModel.Issue, opts fsckModel.Options) {
+	switch opts.Orphans {
+	case fsckModel.OrphanQuarantine:
+		issue.Action = fsckModel.ActionQuarantine
+		if !opts.DryRun {
+			s.fix(ctx, issue, func() error {
+				return s.quarantine(issue.UserUUID, issue.Path)
+			})
+		}
+	case fsckModel.OrphanDelete:
+		issue.Action = fsckModel.ActionDelete
+		if !opts.DryRun {
+			s.fix(ctx, issue, func() error {
+				return os.Remove(issue.Path)
+			})
+		}
+	}
+}
+
+// fix выполняет действие над проблемой и записывает результат в issue.
+func (s *service) fix(ctx context.Context, issue *fsckModel.Issue, action func() error) {
+	err := action()
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Failed to %s %s: %v", issue.Action, issue.Path, err)
+		issue.Error = err.Error()
+		return
+	}
+
+	issue.Fixed = true
+	issue.Error = ""
+}
+
+func (s *service) quarantine(userUUID, path string) error {
+	dir := filepath.Join(s.d.StorageFolderPath, QuarantineFolderName, userUUID)
+	if err := utils.EnsureDirectoryExists(dir); err != nil {
+		return err
+	}
+
+	return os.Rename(path, filepath.Join(dir, filepath.Base(path)))
+}
+
+func (s *service) versionPath(v repoModel.PhotoVersionWithOwner) string {
+	return filepath.Join(s.d.StorageFolderPath, v.UserUUID.String, v.UUIDFilename)
+}
+
+func versionType(v repoModel.PhotoVersionWithOwner) string {
+	if !v.VersionType.Valid {
+		return string(model.Original)
+	}
+	return v.VersionType.String
+}
+
+// isDerived сообщает, получена ли версия из оригинала по пресету и может ли быть пересоздана.
+// Отредактированная версия пересоздается по своему рецепту через API правок,
+// прежние ревизии оригинала загружены пользователем и пересозданы быть не могут.
+func isDerived(v repoModel.PhotoVersionWithOwner) bool {
+	t := versionType(v)
+	return t != string(model.Original) && t != string(model.Edited) && t != string(model.Revision)
+}
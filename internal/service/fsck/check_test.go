@@ -0,0 +1,281 @@
+package fsck
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/config"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	fsckModel "go-photo/internal/service/fsck/model"
+	"go-photo/internal/utils"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testUserUUID = "user-uuid"
+
+func TestService_Check(t *testing.T) {
+	original := encodeJPEG(t, 40, 20)
+	originalChecksum := checksum(original)
+
+	originalVersion := version(1, 1, "original", "orig.jpg", int64(len(original)), originalChecksum)
+	thumbnailVersion := version(2, 1, "thumbnail", "thumb.jpg", 100, "")
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name     string
+		versions []repoModel.PhotoVersionWithOwner
+		// files файлы в папке пользователя, old - с mtime за пределами grace периода
+		files        map[string][]byte
+		oldFiles     map[string][]byte
+		opts         fsckModel.Options
+		mockBehavior mockBehavior
+		expected     []fsckModel.Issue
+		// expectedFiles файлы, которые должны существовать после проверки (относительно хранилища)
+		expectedFiles   []string
+		expectedMissing []string
+	}{
+		{
+			name:     "Consistent storage",
+			versions: []repoModel.PhotoVersionWithOwner{originalVersion},
+			files:    map[string][]byte{"orig.jpg": original},
+			opts:     fsckModel.Options{VerifyChecksums: true},
+		},
+		{
+			name:     "Missing file",
+			versions: []repoModel.PhotoVersionWithOwner{originalVersion},
+			expected: []fsckModel.Issue{
+				{Kind: fsckModel.MissingFile, Path: "user-uuid/orig.jpg", UserUUID: testUserUUID, PhotoID: 1, VersionID: 1, VersionType: "original"},
+			},
+		},
+		{
+			name:     "Size mismatch",
+			versions: []repoModel.PhotoVersionWithOwner{originalVersion},
+			files:    map[string][]byte{"orig.jpg": original[:10]},
+			expected: []fsckModel.Issue{
+				{
+					Kind: fsckModel.SizeMismatch, Path: "user-uuid/orig.jpg", UserUUID: testUserUUID, PhotoID: 1, VersionID: 1, VersionType: "original",
+					Expected: itoa(len(original)), Actual: "10",
+				},
+			},
+		},
+		{
+			name:     "Checksum mismatch",
+			versions: []repoModel.PhotoVersionWithOwner{originalVersion},
+			files:    map[string][]byte{"orig.jpg": corrupt(original)},
+			opts:     fsckModel.Options{VerifyChecksums: true},
+			expected: []fsckModel.Issue{
+				{
+					Kind: fsckModel.ChecksumMismatch, Path: "user-uuid/orig.jpg", UserUUID: testUserUUID, PhotoID: 1, VersionID: 1, VersionType: "original",
+					Expected: originalChecksum, Actual: checksum(corrupt(original)),
+				},
+			},
+		},
+		{
+			name: "Orphan version",
+			versions: []repoModel.PhotoVersionWithOwner{
+				{PhotoVersion: repoModel.PhotoVersion{ID: 5, PhotoID: 9, VersionType: sql.NullString{String: "original", Valid: true}}},
+			},
+			expected: []fsckModel.Issue{
+				{Kind: fsckModel.OrphanVersion, PhotoID: 9, VersionID: 5, VersionType: "original"},
+			},
+		},
+		{
+			name:          "Orphan file is quarantined",
+			oldFiles:      map[string][]byte{"orphan.jpg": original},
+			opts:          fsckModel.Options{Orphans: fsckModel.OrphanQuarantine},
+			expectedFiles: []string{".quarantine/user-uuid/orphan.jpg"},
+			expected: []fsckModel.Issue{
+				{
+					Kind: fsckModel.OrphanFile, Path: "user-uuid/orphan.jpg", UserUUID: testUserUUID, Actual: itoa(len(original)),
+					Action: fsckModel.ActionQuarantine, Fixed: true,
+				},
+			},
+		},
+		{
+			name:            "Orphan file is deleted",
+			oldFiles:        map[string][]byte{"orphan.jpg": original},
+			opts:            fsckModel.Options{Orphans: fsckModel.OrphanDelete},
+			expectedMissing: []string{"user-uuid/orphan.jpg"},
+			expected: []fsckModel.Issue{
+				{
+					Kind: fsckModel.OrphanFile, Path: "user-uuid/orphan.jpg", UserUUID: testUserUUID, Actual: itoa(len(original)),
+					Action: fsckModel.ActionDelete, Fixed: true,
+				},
+			},
+		},
+		{
+			name:          "Dry run does not touch orphans",
+			oldFiles:      map[string][]byte{"orphan.jpg": original},
+			opts:          fsckModel.Options{Orphans: fsckModel.OrphanDelete, DryRun: true},
+			expectedFiles: []string{"user-uuid/orphan.jpg"},
+			expected: []fsckModel.Issue{
+				{
+					Kind: fsckModel.OrphanFile, Path: "user-uuid/orphan.jpg", UserUUID: testUserUUID, Actual: itoa(len(original)),
+					Action: fsckModel.ActionDelete,
+				},
+			},
+		},
+		{
+			name:          "Recent file is not an orphan",
+			files:         map[string][]byte{"uploading.jpg": original},
+			opts:          fsckModel.Options{Orphans: fsckModel.OrphanDelete},
+			expectedFiles: []string{"user-uuid/uploading.jpg"},
+		},
+		{
+			name:     "Missing derived version is rederived",
+			versions: []repoModel.PhotoVersionWithOwner{originalVersion, thumbnailVersion},
+			files:    map[string][]byte{"orig.jpg": original},
+			opts:     fsckModel.Options{Rederive: true},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().UpdatePhotoVersionFile(gomock.Any(), 2, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int, params *repoModel.UpdatePhotoVersionFileParams) error {
+						assert.Equal(t, 10, params.Width)
+						assert.Equal(t, 5, params.Height)
+						return nil
+					})
+			},
+			expectedFiles: []string{"user-uuid/thumb.jpg"},
+			expected: []fsckModel.Issue{
+				{
+					Kind: fsckModel.MissingFile, Path: "user-uuid/thumb.jpg", UserUUID: testUserUUID, PhotoID: 1, VersionID: 2, VersionType: "thumbnail",
+					Action: fsckModel.ActionRederive, Fixed: true,
+				},
+			},
+		},
+		{
+			name:     "Missing edited version is not rederived",
+			versions: []repoModel.PhotoVersionWithOwner{originalVersion, version(3, 1, "edited", "edited.jpg", 100, "")},
+			files:    map[string][]byte{"orig.jpg": original},
+			opts:     fsckModel.Options{Rederive: true},
+			expected: []fsckModel.Issue{
+				{Kind: fsckModel.MissingFile, Path: "user-uuid/edited.jpg", UserUUID: testUserUUID, PhotoID: 1, VersionID: 3, VersionType: "edited"},
+			},
+		},
+		{
+			name:     "Missing previous revision is not rederived",
+			versions: []repoModel.PhotoVersionWithOwner{originalVersion, version(4, 1, "revision", "rev1.jpg", 100, "")},
+			files:    map[string][]byte{"orig.jpg": original},
+			opts:     fsckModel.Options{Rederive: true},
+			expected: []fsckModel.Issue{
+				{Kind: fsckModel.MissingFile, Path: "user-uuid/rev1.jpg", UserUUID: testUserUUID, PhotoID: 1, VersionID: 4, VersionType: "revision"},
+			},
+		},
+		{
+			name:     "Rederive without original fails",
+			versions: []repoModel.PhotoVersionWithOwner{thumbnailVersion},
+			opts:     fsckModel.Options{Rederive: true},
+			expected: []fsckModel.Issue{
+				{
+					Kind: fsckModel.MissingFile, Path: "user-uuid/thumb.jpg", UserUUID: testUserUUID, PhotoID: 1, VersionID: 2, VersionType: "thumbnail",
+					Action: fsckModel.ActionRederive, Error: "photo has no original version",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageDir := t.TempDir()
+			userDir := filepath.Join(storageDir, testUserUUID)
+			require.NoError(t, os.MkdirAll(userDir, 0755))
+			// Служебные папки не проверяются
+			require.NoError(t, os.MkdirAll(filepath.Join(storageDir, ".staging"), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(storageDir, ".staging", "staged.jpg"), original, 0644))
+
+			for name, data := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(userDir, name), data, 0644))
+			}
+			old := time.Now().Add(-time.Hour)
+			for name, data := range tt.oldFiles {
+				path := filepath.Join(userDir, name)
+				require.NoError(t, os.WriteFile(path, data, 0644))
+				require.NoError(t, os.Chtimes(path, old, old))
+			}
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			mockRepo.EXPECT().GetAllPhotoVersions(gomock.Any()).Return(tt.versions, nil)
+			if tt.mockBehavior != nil {
+				tt.mockBehavior(mockRepo)
+			}
+
+			s := NewService(Deps{
+				StorageFolderPath: storageDir,
+				Versions:          []config.VersionPreset{{Name: "thumbnail", Width: 10, Height: 10, Quality: 80}},
+				OrphanGracePeriod: time.Minute,
+			}, mockRepo)
+
+			report, err := s.Check(context.Background(), tt.opts)
+			require.NoError(t, err)
+
+			for i := range tt.expected {
+				if tt.expected[i].Path != "" {
+					tt.expected[i].Path = filepath.Join(storageDir, tt.expected[i].Path)
+				}
+			}
+			if tt.expected == nil {
+				tt.expected = []fsckModel.Issue{}
+			}
+			assert.Equal(t, tt.expected, report.Issues)
+			assert.Equal(t, len(tt.versions), report.CheckedVersions)
+			assert.Equal(t, tt.opts.DryRun, report.DryRun)
+
+			for _, path := range tt.expectedFiles {
+				assert.FileExists(t, filepath.Join(storageDir, path))
+			}
+			for _, path := range tt.expectedMissing {
+				assert.NoFileExists(t, filepath.Join(storageDir, path))
+			}
+		})
+	}
+}
+
+func version(id, photoID int, versionType, filename string, size int64, checksum string) repoModel.PhotoVersionWithOwner {
+	return repoModel.PhotoVersionWithOwner{
+		PhotoVersion: repoModel.PhotoVersion{
+			ID:           id,
+			PhotoID:      photoID,
+			VersionType:  sql.NullString{String: versionType, Valid: true},
+			UUIDFilename: filename,
+			Size:         size,
+			Checksum:     sql.NullString{String: checksum, Valid: checksum != ""},
+		},
+		UserUUID: sql.NullString{String: testUserUUID, Valid: true},
+	}
+}
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+func checksum(data []byte) string {
+	h := utils.NewChecksum()
+	h.Write(data)
+	return utils.FormatChecksum(h)
+}
+
+// corrupt возвращает копию данных того же размера с измененным последним байтом
+func corrupt(data []byte) []byte {
+	c := append([]byte(nil), data...)
+	c[len(c)-1] ^= 0xff
+	return c
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,99 @@
+package model
+
+import "time"
+
+type IssueKind string
+
+const (
+	// MissingFile у версии фото нет файла в хранилище.
+	MissingFile IssueKind = "missing_file"
+	// OrphanFile файл в папке пользователя не принадлежит ни одной версии.
+	OrphanFile IssueKind = "orphan_file"
+	// SizeMismatch размер файла не совпадает с photo_versions.size.
+	SizeMismatch IssueKind = "size_mismatch"
+	// ChecksumMismatch контрольная сумма файла не совпадает с photo_versions.checksum.
+	ChecksumMismatch IssueKind = "checksum_mismatch"
+	// OrphanVersion версия ссылается на несуществующее фото.
+	OrphanVersion IssueKind = "orphan_version"
+)
+
+type Action string
+
+const (
+	ActionNone       Action = ""
+	ActionQuarantine Action = "quarantine"
+	ActionDelete     Action = "delete"
+	ActionRederive   Action = "rederive"
+)
+
+// OrphanPolicy что делать с файлами без версии.
+type OrphanPolicy string
+
+const (
+	OrphanKeep       OrphanPolicy = "keep"
+	OrphanQuarantine OrphanPolicy = "quarantine"
+	OrphanDelete     OrphanPolicy = "delete"
+)
+
+type Options struct {
+	// Orphans действие над файлами без версии.
+	Orphans OrphanPolicy
+	// Rederive пересоздавать отсутствующие и поврежденные производные версии из оригинала.
+	Rederive bool
+	// VerifyChecksums сверять контрольные суммы. Требует чтения всех файлов.
+	VerifyChecksums bool
+	// DryRun только показать действия, не выполняя их.
+	DryRun bool
+}
+
+// Issue найденная проблема. Action - действие, выбранное для ее исправления;
+// Fixed выставляется, если действие выполнено, Error - если выполнить не удалось.
+type Issue struct {
+	Kind        IssueKind `json:"kind"`
+	Path        string    `json:"path,omitempty"`
+	UserUUID    string    `json:"user_uuid,omitempty"`
+	PhotoID     int       `json:"photo_id,omitempty"`
+	VersionID   int       `json:"version_id,omitempty"`
+	VersionType string    `json:"version_type,omitempty"`
+	Expected    string    `json:"expected,omitempty"`
+	Actual      string    `json:"actual,omitempty"`
+	Action      Action    `json:"action,omitempty"`
+	Fixed       bool      `json:"fixed,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type Report struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DryRun     bool      `json:"dry_run"`
+	// CheckedVersions и CheckedFiles количество проверенных версий в БД и файлов в хранилище
+	CheckedVersions int               `json:"checked_versions"`
+	CheckedFiles    int               `json:"checked_files"`
+	Summary         map[IssueKind]int `json:"summary"`
+	Issues          []Issue           `json:"issues"`
+}
+
+func NewReport(startedAt time.Time, dryRun bool) *Report {
+	return &Report{
+		StartedAt: startedAt,
+		DryRun:    dryRun,
+		Summary:   make(map[IssueKind]int),
+		Issues:    []Issue{},
+	}
+}
+
+func (r *Report) Add(issue Issue) {
+	r.Issues = append(r.Issues, issue)
+	r.Summary[issue.Kind]++
+}
+
+// Unresolved возвращает количество проблем, которые остались неисправленными.
+func (r *Report) Unresolved() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if !issue.Fixed {
+			n++
+		}
+	}
+	return n
+}
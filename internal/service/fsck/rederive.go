@@ -0,0 +1,105 @@
+package fsck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/config"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/internal/utils"
+	"go-photo/pkg/imaging"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// rederive заново создает файл производной версии из оригинала по пресету с тем же именем
+// и обновляет размеры версии в БД.
+func (s *service) rederive(ctx context.Context, v repoModel.PhotoVersionWithOwner, originalPath string) error {
+	if originalPath == "" {
+		return errors.New("photo has no original version")
+	}
+
+	preset, ok := s.preset(versionType(v))
+	if !ok {
+		return fmt.Errorf("no version preset %q", versionType(v))
+	}
+
+	src, err := os.Open(originalPath)
+	if err != nil {
+		return fmt.Errorf("failed to open original: %w", err)
+	}
+	defer src.Close()
+
+	img, _, err := image.Decode(src)
+	if err != nil {
+		return fmt.Errorf("failed to decode original: %w", err)
+	}
+	img = imaging.Fit(img, preset.Width, preset.Height)
+
+	path := s.versionPath(v)
+	size, checksum, err := writeImageAtomically(path, img, preset.Quality)
+	if err != nil {
+		return err
+	}
+
+	return s.photoRepository.UpdatePhotoVersionFile(ctx, v.ID, &repoModel.UpdatePhotoVersionFileParams{
+		Size:     size,
+		Height:   img.Bounds().Dy(),
+		Width:    img.Bounds().Dx(),
+		Checksum: checksum,
+		SavedAt:  time.Now(),
+	})
+}
+
+func (s *service) preset(name string) (config.VersionPreset, bool) {
+	for _, p := range s.d.Versions {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return config.VersionPreset{}, false
+}
+
+// writeImageAtomically кодирует изображение во временный файл рядом с path и переименовывает его,
+// чтобы при ошибке не оставить поврежденный файл. Формат определяется расширением path.
+func writeImageAtomically(path string, img image.Image, quality int) (int64, string, error) {
+	dir := filepath.Dir(path)
+	if err := utils.EnsureDirectoryExists(dir); err != nil {
+		return 0, "", err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".rederive-*")
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	checksum := utils.NewChecksum()
+	err = imaging.Encode(io.MultiWriter(tmp, checksum), img, filepath.Ext(path), quality)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return 0, "", fmt.Errorf("failed to sync file: %w", err)
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, "", fmt.Errorf("failed to replace file: %w", err)
+	}
+
+	return info.Size(), utils.FormatChecksum(checksum), nil
+}
@@ -0,0 +1,37 @@
+package fsck
+
+import (
+	"go-photo/internal/config"
+	"go-photo/internal/repository"
+	def "go-photo/internal/service"
+	"time"
+)
+
+// Проверка на соответствие интерфейсу StorageChecker (для статической проверки)
+var _ def.StorageChecker = (*service)(nil)
+
+// QuarantineFolderName папка внутри хранилища, куда переносятся файлы без версий.
+// Структура внутри повторяет папки пользователей.
+const QuarantineFolderName = ".quarantine"
+
+type Deps struct {
+	// абсолютный путь к папке с фотографиями
+	StorageFolderPath string
+	// Versions пресеты, по которым пересоздаются производные версии
+	Versions []config.VersionPreset
+	// OrphanGracePeriod файлы моложе этого возраста не считаются лишними:
+	// их загрузка может еще не быть закоммичена.
+	OrphanGracePeriod time.Duration
+}
+
+type service struct {
+	d               Deps
+	photoRepository repository.PhotoRepository
+}
+
+func NewService(d Deps, photoRepository repository.PhotoRepository) *service {
+	return &service{
+		d:               d,
+		photoRepository: photoRepository,
+	}
+}
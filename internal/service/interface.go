@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"go-photo/internal/model"
+	fsckModel "go-photo/internal/service/fsck/model"
 	servicePhotoModel "go-photo/internal/service/photo/model"
 	serviceUserModel "go-photo/internal/service/user/model"
 	"mime/multipart"
@@ -34,8 +35,44 @@ type PhotoService interface {
 	UploadBatchPhotos(ctx context.Context, userUUID string, photoFiles []*multipart.FileHeader) (*servicePhotoModel.UploadInfoList, error)
 
 	// PublishPhoto публикует фотографию, делая ее доступной для других пользователей.
+	// Если opts.Watermark, публичные файлы отдаются с водяным знаком владельца; если он не настроен,
+	// возвращает WatermarkNotFoundError.
+	// opts.Metadata задает политику метаданных публикации, пустая - политику из настроек пользователя.
 	// Осуществляет проверку прав доступа к фотографии.
-	PublishPhoto(ctx context.Context, userUUID string, photoID int) (string, error)
+	PublishPhoto(ctx context.Context, userUUID string, photoID int, opts servicePhotoModel.PublishOptions) (string, error)
+
+	// GetPhotos возвращает фотографии пользователя не из корзины, начиная с загруженных последними.
+	// Если параметры фильтра недопустимы, возвращает InvalidFilterError.
+	GetPhotos(ctx context.Context, userUUID string, filter servicePhotoModel.PhotoFilter) ([]model.Photo, error)
+
+	// SearchPhotos ищет фотографии пользователя не из корзины по названию, подписи, названиям альбомов,
+	// в которые фотография добавлена, имени файла и камере, начиная с самых релевантных. Каждое слово запроса ищется как начало слова, фото должно содержать все слова.
+	// Если запрос пуст или параметры фильтра недопустимы, возвращает InvalidFilterError.
+	SearchPhotos(ctx context.Context, userUUID string, query string, filter servicePhotoModel.PhotoFilter) ([]model.FoundPhoto, error)
+
+	// GetPhotoClusters группирует фото пользователя внутри filter.BBox по ячейкам сетки, размер которых
+	// зависит от уровня масштаба карты. Скрытые фото не учитываются.
+	// Если параметры фильтра недопустимы, возвращает InvalidFilterError.
+	GetPhotoClusters(ctx context.Context, userUUID string, filter servicePhotoModel.ClusterFilter) ([]model.PhotoCluster, error)
+
+	// GetTimeline группирует фото пользователя по дням, месяцам или годам съемки, начиная с последнего периода
+	// или с периода filter.Cursor. Фото без времени съемки относятся к периоду загрузки. Скрытые фото не учитываются.
+	// Если параметры фильтра недопустимы, возвращает InvalidFilterError.
+	GetTimeline(ctx context.Context, userUUID string, filter servicePhotoModel.TimelineFilter) (*servicePhotoModel.Timeline, error)
+
+	// GetPhotosOnThisDay возвращает фото пользователя, снятые в тот же день в прошлые годы, начиная со снятых последними.
+	// Скрытые фото не возвращаются. Если параметры фильтра недопустимы, возвращает InvalidFilterError.
+	GetPhotosOnThisDay(ctx context.Context, userUUID string, filter servicePhotoModel.OnThisDayFilter) ([]model.Photo, error)
+
+	// GetPhoto возвращает фотографию с ее атрибутами без версий.
+	// Осуществляет проверку прав доступа к фотографии.
+	GetPhoto(ctx context.Context, userUUID string, photoID int) (*model.Photo, error)
+
+	// UpdatePhotoAttributes изменяет атрибуты фотографии документом JSON Merge Patch (RFC 7396).
+	// Если ifMatch не пустой и не совпадает с ETag фотографии, или фотография изменилась во время обновления,
+	// возвращает PreconditionFailedError. Некорректный документ - InvalidPatchError.
+	// Осуществляет проверку прав доступа к фотографии.
+	UpdatePhotoAttributes(ctx context.Context, userUUID string, photoID int, patch []byte, ifMatch string) (*model.Photo, error)
 
 	// GetPhotoVersions получает все версии фотографии по ее ID.
 	// Осуществляет проверку прав доступа к фотографии.
@@ -43,17 +80,209 @@ type PhotoService interface {
 	GetPhotoVersions(ctx context.Context, userUUID string, photoID int) ([]model.PhotoVersion, error)
 
 	// GetPhotoFileByVersionAndToken получает файл публичной фотографии по ее версии и токену.
-	// TODO: tests
-	GetPhotoFileByVersionAndToken(ctx context.Context, token string, version string) ([]byte, error)
+	// Если клиент принимает WebP, может вернуть WebP вариант версии (см. GetPhotoFile).
+	// Фото, опубликованные с водяным знаком, отдаются с ним; такие файлы кэшируются как варианты.
+	// Остальные отдаются без метаданных, удаляемых политикой публикации: копия без метаданных создается
+	// при первом запросе и хранится как вариант версии.
+	GetPhotoFileByVersionAndToken(ctx context.Context, token string, version string, opts servicePhotoModel.FileOptions) (*servicePhotoModel.PhotoFile, error)
+
+	// GetPhotoFile получает файл версии фотографии владельца. Осуществляет проверку прав доступа к фотографии.
+	// Если клиент принимает WebP, а версия хранится в другом формате, возвращает ее WebP вариант,
+	// созданный при первом запросе и сохраненный в хранилище, если он меньше исходного файла.
+	// Если у фото нет такой версии, возвращает ошибку PhotoNotFoundError.
+	GetPhotoFile(ctx context.Context, userUUID string, photoID int, version string, opts servicePhotoModel.FileOptions) (*servicePhotoModel.PhotoFile, error)
+
+	// GetResizedPhotoFileByToken получает публичную фотографию, приведенную к заданному размеру и формату.
+	// Размер и качество должны входить в разрешенные конфигурацией, иначе возвращается InvalidResizeParamsError.
+	// На фото, опубликованные с водяным знаком, он накладывается после изменения размера.
+	// Готовые варианты кэшируются в хранилище и удаляются при отмене публикации.
+	GetResizedPhotoFileByToken(ctx context.Context, token string, opts servicePhotoModel.ResizeOptions) (*servicePhotoModel.PhotoFile, error)
 
 	// UnpublishPhoto отменяет публикацию фотографии, делая ее недоступной для других пользователей.
 	// Осуществляет проверку прав доступа к фотографии.
 	UnpublishPhoto(ctx context.Context, userUUID string, photoID int) error
 
+	// DeletePhoto переносит фотографию в корзину: она пропадает из выдачи, а ее публичная ссылка перестает работать.
+	// Осуществляет проверку прав доступа к фотографии.
+	DeletePhoto(ctx context.Context, userUUID string, photoID int) error
+
+	// BulkUpdatePhotos выполняет действие над каждой фотографией из списка (повторяющиеся ID учитываются один раз).
+	// Осуществляет проверку прав доступа к каждой фотографии; ошибка одной фотографии не отменяет остальные
+	// и прикрепляется к ее результату. Как и UploadBatchPhotos, возвращает ParticalSuccessError или AllFailedError,
+	// если действие удалось не для всех фотографий.
+	// Для add_to_album альбом передается в opts, пользователь должен иметь право добавлять в него фото.
+	BulkUpdatePhotos(ctx context.Context, userUUID string, action servicePhotoModel.BulkAction, photoIDs []int, opts servicePhotoModel.BulkOptions) (servicePhotoModel.BulkResults, error)
+
+	// EditPhoto применяет операции правки к оригиналу фотографии и сохраняет результат как версию edited,
+	// заменяя прежнюю. Оригинал не меняется. Осуществляет проверку прав доступа к фотографии.
+	// Если операции неизвестны или неприменимы к фото, возвращает InvalidEditError.
+	EditPhoto(ctx context.Context, userUUID string, photoID int, ops []servicePhotoModel.EditOperation) (*servicePhotoModel.PhotoEdit, error)
+
+	// GetPhotoEdit возвращает рецепт правок фотографии и ее отредактированную версию.
+	// Если фотография не редактировалась, возвращает PhotoNotFoundError.
+	GetPhotoEdit(ctx context.Context, userUUID string, photoID int) (*servicePhotoModel.PhotoEdit, error)
+
+	// ReapplyPhotoEdit заново создает отредактированную версию из оригинала по сохраненному рецепту.
+	// Если фотография не редактировалась, возвращает PhotoNotFoundError.
+	ReapplyPhotoEdit(ctx context.Context, userUUID string, photoID int) (*servicePhotoModel.PhotoEdit, error)
+
+	// RevertPhotoEdit отменяет правки: удаляет отредактированную версию вместе с рецептом.
+	// Если фотография не редактировалась, возвращает PhotoNotFoundError.
+	RevertPhotoEdit(ctx context.Context, userUUID string, photoID int) error
+
+	// UploadPhotoRevision сохраняет файл как новую текущую ревизию оригинала фотографии. Прежние ревизии
+	// остаются в истории, существующие производные версии пересоздаются из новой ревизии.
+	// Осуществляет проверку прав доступа к фотографии.
+	UploadPhotoRevision(ctx context.Context, userUUID string, photoID int, file *multipart.FileHeader) (*model.PhotoVersion, error)
+
+	// GetPhotoRevisions возвращает историю ревизий оригинала фотографии от новых к старым.
+	// Текущая ревизия имеет тип original. Осуществляет проверку прав доступа к фотографии.
+	GetPhotoRevisions(ctx context.Context, userUUID string, photoID int) ([]model.PhotoVersion, error)
+
+	// RestorePhotoRevision делает ревизию с номером revision текущей и пересоздает из нее производные версии.
+	// Если фотография или ревизия не найдены, возвращает PhotoNotFoundError.
+	RestorePhotoRevision(ctx context.Context, userUUID string, photoID, revision int) (*model.PhotoVersion, error)
+
+	// GetWatermark возвращает настройки водяного знака пользователя.
+	// Если они не заданы, возвращает WatermarkNotFoundError.
+	GetWatermark(ctx context.Context, userUUID string) (*servicePhotoModel.Watermark, error)
+
+	// SaveWatermark создает или заменяет настройки водяного знака, заполняя значения по умолчанию.
+	// Недопустимые настройки или тип logo без загруженного логотипа - InvalidWatermarkError.
+	// Публикации с водяным знаком начинают отдаваться с новым знаком.
+	SaveWatermark(ctx context.Context, userUUID string, settings servicePhotoModel.WatermarkSettings) (*servicePhotoModel.Watermark, error)
+
+	// UploadWatermarkLogo сохраняет PNG логотип и делает водяной знак логотипом, заменяя прежний логотип.
+	// Если файл не PNG или слишком большой, возвращает InvalidWatermarkError.
+	UploadWatermarkLogo(ctx context.Context, userUUID string, logoFile *multipart.FileHeader) (*servicePhotoModel.Watermark, error)
+
+	// DeleteWatermark удаляет настройки водяного знака вместе с логотипом. Если настроек нет, возвращает
+	// WatermarkNotFoundError. Пока есть фото или альбомы, опубликованные с водяным знаком, настройки
+	// не удаляются и возвращается WatermarkInUseError.
+	DeleteWatermark(ctx context.Context, userUUID string) error
+
+	// GetPublishSettings возвращает настройки публикации пользователя.
+	// Если пользователь их не менял, возвращает настройки по умолчанию.
+	GetPublishSettings(ctx context.Context, userUUID string) (*servicePhotoModel.PublishSettings, error)
+
+	// SavePublishSettings сохраняет настройки публикации пользователя. Они применяются к новым публикациям,
+	// уже опубликованные фото сохраняют свою политику. Неизвестная политика - InvalidMetadataPolicyError.
+	SavePublishSettings(ctx context.Context, userUUID string, settings servicePhotoModel.PublishSettings) (*servicePhotoModel.PublishSettings, error)
+
+	// CreateAlbum создает альбом: фото владельца отбираются фильтрами при каждом чтении, в ручной альбом -
+	// только если в фильтрах задан хотя бы один критерий. Недопустимое название или фильтры - InvalidAlbumError.
+	CreateAlbum(ctx context.Context, userUUID string, params servicePhotoModel.SaveAlbumParams) (*servicePhotoModel.Album, error)
+
+	// GetAlbums возвращает альбомы пользователя, начиная с созданных последними.
+	GetAlbums(ctx context.Context, userUUID string) ([]servicePhotoModel.Album, error)
+
+	// GetAlbum возвращает альбом пользователя или альбом, к которому ему выдан доступ.
+	// Если альбом не найден, возвращает AlbumNotFoundError.
+	// Осуществляет проверку прав доступа к альбому.
+	GetAlbum(ctx context.Context, userUUID string, albumID int) (*servicePhotoModel.Album, error)
+
+	// UpdateAlbum заменяет название, фильтры и режим альбома, публикация сохраняется.
+	// Недопустимое название или фильтры - InvalidAlbumError. Осуществляет проверку прав доступа к альбому.
+	UpdateAlbum(ctx context.Context, userUUID string, albumID int, params servicePhotoModel.SaveAlbumParams) (*servicePhotoModel.Album, error)
+
+	// DeleteAlbum удаляет альбом вместе с публикацией, фото остаются.
+	// Осуществляет проверку прав доступа к альбому.
+	DeleteAlbum(ctx context.Context, userUUID string, albumID int) error
+
+	// GetAlbumPhotos возвращает страницу фото, отобранных фильтрами альбома, и добавленных в альбом фото
+	// в порядке списка фото.
+	// Скрытые фото видит только владелец альбома. Осуществляет проверку прав доступа к альбому.
+	GetAlbumPhotos(ctx context.Context, userUUID string, albumID int, limit, offset int) ([]model.Photo, error)
+
+	// PublishAlbum публикует альбом и возвращает токен публичной ссылки. Повторная публикация сохраняет ссылку.
+	// opts.Metadata задает политику метаданных файлов альбома, пустая - политику из настроек пользователя.
+	// Если opts.Watermark, файлы альбома отдаются с водяным знаком владельца; если он не настроен,
+	// возвращает WatermarkNotFoundError. Осуществляет проверку прав доступа к альбому.
+	PublishAlbum(ctx context.Context, userUUID string, albumID int, opts servicePhotoModel.PublishOptions) (string, error)
+
+	// UnpublishAlbum снимает альбом с публикации. Если альбом не опубликован, возвращает AlbumNotFoundError.
+	// Осуществляет проверку прав доступа к альбому.
+	UnpublishAlbum(ctx context.Context, userUUID string, albumID int) error
+
+	// UploadAlbumPhotos загружает фотографии как UploadBatchPhotos и добавляет их в альбом. Загрузить фото
+	// в альбом может его владелец и пользователи с ролью contributor. Фото остаются у загрузившего пользователя
+	// и показываются в альбоме независимо от его фильтров. Если фото загрузилось, но не добавилось в альбом,
+	// ошибка прикрепляется к информации о нем.
+	UploadAlbumPhotos(ctx context.Context, userUUID string, albumID int, photoFiles []*multipart.FileHeader) (*servicePhotoModel.UploadInfoList, error)
+
+	// RemoveAlbumPhoto убирает добавленную фотографию из альбома, сама фотография остается у загрузившего.
+	// Осуществляет проверку прав доступа к альбому. Если фото не добавлено в альбом, возвращает PhotoNotFoundError.
+	RemoveAlbumPhoto(ctx context.Context, userUUID string, albumID int, photoID int) error
+
+	// GetPublicAlbum возвращает опубликованный альбом со страницей фото. Скрытые фото не показываются.
+	// Если альбом не найден или не опубликован, возвращает AlbumNotFoundError.
+	GetPublicAlbum(ctx context.Context, token string, limit, offset int) (*servicePhotoModel.PublicAlbum, error)
+
+	// GetPublicAlbumPhotoFile возвращает файл версии фото опубликованного альбома без метаданных,
+	// удаляемых политикой альбома, или с водяным знаком, если альбом опубликован с ним.
+	// Если фото больше не отбирается фильтрами альбома, возвращает PhotoNotFoundError.
+	GetPublicAlbumPhotoFile(ctx context.Context, token string, photoID int, version string, opts servicePhotoModel.FileOptions) (*servicePhotoModel.PhotoFile, error)
+
+	// SharePhoto выдает пользователю granteeUUID доступ к фотографии с ролью viewer или меняет роль уже выданного
+	// доступа. Недопустимый пользователь или роль - InvalidShareError. Осуществляет проверку прав доступа к фотографии.
+	SharePhoto(ctx context.Context, userUUID string, photoID int, granteeUUID string, role string) (*servicePhotoModel.Share, error)
+
+	// UnsharePhoto отзывает доступ пользователя к фотографии. Если доступ не выдан, возвращает ShareNotFoundError.
+	UnsharePhoto(ctx context.Context, userUUID string, photoID int, granteeUUID string) error
+
+	// GetPhotoShares возвращает доступы к фотографии в порядке выдачи. Осуществляет проверку прав доступа к фотографии.
+	GetPhotoShares(ctx context.Context, userUUID string, photoID int) ([]servicePhotoModel.Share, error)
+
+	// ShareAlbum выдает пользователю granteeUUID доступ к альбому с ролью viewer или contributor или меняет роль
+	// уже выданного доступа. Доступ к альбому открывает и фото, отобранные его фильтрами, кроме скрытых.
+	// Недопустимый пользователь или роль - InvalidShareError. Осуществляет проверку прав доступа к альбому.
+	ShareAlbum(ctx context.Context, userUUID string, albumID int, granteeUUID string, role string) (*servicePhotoModel.Share, error)
+
+	// UnshareAlbum отзывает доступ пользователя к альбому. Если доступ не выдан, возвращает ShareNotFoundError.
+	UnshareAlbum(ctx context.Context, userUUID string, albumID int, granteeUUID string) error
+
+	// GetAlbumShares возвращает доступы к альбому в порядке выдачи. Осуществляет проверку прав доступа к альбому.
+	GetAlbumShares(ctx context.Context, userUUID string, albumID int) ([]servicePhotoModel.Share, error)
+
+	// GetSharedWithMe возвращает страницу фото не из корзины, к которым пользователю выдан доступ напрямую,
+	// и альбомы, к которым ему выдан доступ.
+	GetSharedWithMe(ctx context.Context, userUUID string, limit, offset int) (*servicePhotoModel.SharedWithMe, error)
+
+	// GetTrash возвращает фотографии пользователя в корзине.
+	GetTrash(ctx context.Context, userUUID string) ([]servicePhotoModel.TrashedPhoto, error)
+
+	// RestorePhoto восстанавливает фотографию из корзины вместе с публикацией.
+	// Осуществляет проверку прав доступа к фотографии.
+	// Если фотография не в корзине, возвращает PhotoNotFoundError.
+	RestorePhoto(ctx context.Context, userUUID string, photoID int) error
+
+	// PurgeTrash окончательно удаляет фотографии, пролежавшие в корзине дольше Deps.TrashRetention, вместе с файлами.
+	PurgeTrash(ctx context.Context) error
+
+	// BackfillPlaceholders рассчитывает заглушки (BlurHash и палитру) версий фото, для которых они еще не рассчитаны:
+	// загруженных до появления заглушек, отредактированных и тех, для которых расчет при загрузке не удался.
+	BackfillPlaceholders(ctx context.Context) error
+
+	// BackfillExif читает координаты и время съемки из EXIF оригиналов, из которых они еще не прочитаны:
+	// загруженных до появления этих сведений, новых ревизий и тех, которые не удалось прочитать при загрузке.
+	BackfillExif(ctx context.Context) error
+
+	// ReconcileUploads завершает или откатывает загрузки, прерванные остановкой процесса:
+	// переносит в хранилище файлы уже сохраненных фото и удаляет файлы несохраненных.
+	// Обрабатываются только загрузки старше Deps.StaleUploadAfter.
+	ReconcileUploads(ctx context.Context) error
+
 	// HandleRepoErr обрабатывает ошибки, возвращаемые репозиторием.
 	// Обрабатывает ошибки:
 	// - NotFoundError
 	// - ConflictError
-	// Если ошибка не распознана, возвращает UnexpectedError.
-	HandleRepoErr(err error) error
+	// Если ошибка не распознана, логирует ее логгером из контекста и возвращает UnexpectedError.
+	HandleRepoErr(ctx context.Context, err error) error
+}
+
+type StorageChecker interface {
+	// Check сверяет файлы в хранилище с версиями фото в БД: ищет отсутствующие файлы,
+	// файлы без версий, несовпадения размера и контрольной суммы и версии несуществующих фото.
+	// Исправляет найденное согласно opts, в режиме DryRun только сообщает о действиях.
+	Check(ctx context.Context, opts fsckModel.Options) (*fsckModel.Report, error)
 }
@@ -0,0 +1,164 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+)
+
+// action действие над фото или альбомом, право на которое проверяет политика доступа.
+type action int
+
+const (
+	// actionView просмотр атрибутов, версий и файлов
+	actionView action = iota
+	// actionContribute добавление своих фото в альбом
+	actionContribute
+	// actionManage изменение, публикация, удаление и выдача доступа
+	actionManage
+)
+
+// roleAllows сообщает, разрешает ли роль выданного доступа действие. Управлять может только владелец.
+func roleAllows(role serviceModel.ShareRole, act action) bool {
+	switch act {
+	case actionView:
+		return role == serviceModel.ShareViewer || role == serviceModel.ShareContributor
+	case actionContribute:
+		return role == serviceModel.ShareContributor
+	default:
+		return false
+	}
+}
+
+// getPhoto возвращает фотографию по ее ID, если пользователю разрешено действие над ней (см. authorizePhoto).
+// Если фотография не найдена, возвращает ошибку PhotoNotFoundError.
+// Если действие не разрешено, возвращает ошибку AccessDeniedError.
+// Фотографии в корзине считаются ненайденными.
+func (s *service) getPhoto(ctx context.Context, userUUID string, photoID int, act action) (*repoModel.Photo, error) {
+	photo, err := s.photoRepository.GetPhotoByID(ctx, photoID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return &repoModel.Photo{}, err
+	}
+
+	if err := s.authorizePhoto(ctx, userUUID, photo, act); err != nil {
+		return &repoModel.Photo{}, err
+	}
+	if photo.IsTrashed() {
+		return &repoModel.Photo{}, fmt.Errorf("%w: photo %d is in trash", serviceErr.PhotoNotFoundError, photoID)
+	}
+
+	return photo, nil
+}
+
+// authorizePhoto проверяет, разрешено ли пользователю действие над фото. Владельцу разрешено все,
+// остальным - то, что разрешает роль доступа к самому фото или к альбому, в котором есть это фото.
+// Владелец альбома может просматривать добавленные в него фото участников.
+// Скрытые фото через выданный доступ к альбомам недоступны, как и в самих альбомах.
+// Если действие не разрешено, возвращает AccessDeniedError.
+func (s *service) authorizePhoto(ctx context.Context, userUUID string, photo *repoModel.Photo, act action) error {
+	if photo.UserUUID == userUUID {
+		return nil
+	}
+	if act == actionManage {
+		return serviceErr.AccessDeniedError
+	}
+
+	share, err := s.photoRepository.GetPhotoShare(ctx, photo.ID, userUUID)
+	if err != nil && !errors.Is(err, repoErr.NotFoundError) {
+		return s.HandleRepoErr(ctx, err)
+	}
+	if share != nil && roleAllows(serviceModel.ShareRole(share.Role), act) {
+		return nil
+	}
+
+	// Фото, добавленные в альбом участниками, видны владельцу альбома
+	contributed, err := s.photoRepository.GetContributedAlbums(ctx, photo.ID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return err
+	}
+	inAlbum := make(map[int]bool, len(contributed))
+	for i := range contributed {
+		if contributed[i].UserUUID == userUUID && act == actionView {
+			return nil
+		}
+		inAlbum[contributed[i].ID] = true
+	}
+
+	albums, err := s.photoRepository.GetSharedAlbums(ctx, userUUID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return err
+	}
+	for i := range albums {
+		album := &albums[i]
+		if !roleAllows(serviceModel.ShareRole(album.Role), act) {
+			continue
+		}
+
+		switch {
+		case inAlbum[album.ID] && !photo.Hidden:
+			return nil
+		case album.UserUUID == photo.UserUUID:
+			albumPhoto, err := s.albumPhoto(ctx, &album.Album, photo.ID, true)
+			if err != nil {
+				return err
+			}
+			if albumPhoto != nil {
+				return nil
+			}
+		}
+	}
+
+	return serviceErr.AccessDeniedError
+}
+
+// getAlbum возвращает альбом по его ID, если пользователю разрешено действие над ним: владельцу разрешено все,
+// остальным - то, что разрешает выданная им роль.
+// Если альбом не найден, возвращает ошибку AlbumNotFoundError.
+// Если действие не разрешено, возвращает ошибку AccessDeniedError.
+func (s *service) getAlbum(ctx context.Context, userUUID string, albumID int, act action) (*repoModel.Album, error) {
+	album, err := s.photoRepository.GetAlbumByID(ctx, albumID)
+	if err := s.handleAlbumRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	if album.UserUUID == userUUID {
+		return album, nil
+	}
+	if act == actionManage {
+		return nil, serviceErr.AccessDeniedError
+	}
+
+	share, err := s.photoRepository.GetAlbumShare(ctx, albumID, userUUID)
+	if err != nil && !errors.Is(err, repoErr.NotFoundError) {
+		return nil, s.HandleRepoErr(ctx, err)
+	}
+	if share == nil || !roleAllows(serviceModel.ShareRole(share.Role), act) {
+		return nil, serviceErr.AccessDeniedError
+	}
+
+	return album, nil
+}
+
+// albumPhoto возвращает фото альбома по ID или nil, если фото нет в альбоме. Если visibleOnly,
+// скрытые фото в альбом не попадают.
+func (s *service) albumPhoto(ctx context.Context, album *repoModel.Album, photoID int, visibleOnly bool) (*repoModel.Photo, error) {
+	listParams, ownerPhotos, err := albumListParams(album, 1, 0, visibleOnly)
+	if err != nil {
+		return nil, err
+	}
+	listParams.PhotoID = &photoID
+
+	photos, err := s.photoRepository.GetAlbumPhotos(ctx, album.ID, listParams, ownerPhotos)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+	if len(photos) == 0 {
+		return nil, nil
+	}
+
+	return &photos[0], nil
+}
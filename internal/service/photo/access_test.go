@@ -0,0 +1,163 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	"testing"
+)
+
+func TestService_authorizePhoto(t *testing.T) {
+	const (
+		ownerUUID = "owner-id"
+		userUUID  = "user-id"
+	)
+
+	photo := &repoModel.Photo{ID: 1, UserUUID: ownerUUID}
+	hidden := false
+	photoID := 1
+	sharedAlbum := func(owner, role string) repoModel.SharedAlbum {
+		return repoModel.SharedAlbum{
+			Album: repoModel.Album{ID: 7, UserUUID: owner, Title: "Trip", Filter: []byte(`{}`)},
+			Role:  role,
+		}
+	}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		userUUID     string
+		act          action
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:         "Owner manages",
+			userUUID:     ownerUUID,
+			act:          actionManage,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+		},
+		{
+			name:         "Other user cannot manage",
+			userUUID:     userUUID,
+			act:          actionManage,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.AccessDeniedError,
+		},
+		{
+			name:     "Photo shared directly",
+			userUUID: userUUID,
+			act:      actionView,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoShare(gomock.Any(), 1, userUUID).Return(&repoModel.Share{GranteeUUID: userUUID, Role: "viewer"}, nil)
+			},
+		},
+		{
+			name:     "Photo in shared album",
+			userUUID: userUUID,
+			act:      actionView,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoShare(gomock.Any(), 1, userUUID).Return(nil, repoErr.NotFoundError)
+				repo.EXPECT().GetContributedAlbums(gomock.Any(), 1).Return(nil, nil)
+				repo.EXPECT().GetSharedAlbums(gomock.Any(), userUUID).
+					Return([]repoModel.SharedAlbum{sharedAlbum(ownerUUID, "viewer")}, nil)
+				repo.EXPECT().GetAlbumPhotos(gomock.Any(), 7, &repoModel.PhotoListParams{Hidden: &hidden, PhotoID: &photoID, Limit: 1}, true).
+					Return([]repoModel.Photo{*photo}, nil)
+			},
+		},
+		{
+			name:     "Photo not in shared album",
+			userUUID: userUUID,
+			act:      actionView,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoShare(gomock.Any(), 1, userUUID).Return(nil, repoErr.NotFoundError)
+				repo.EXPECT().GetContributedAlbums(gomock.Any(), 1).Return(nil, nil)
+				repo.EXPECT().GetSharedAlbums(gomock.Any(), userUUID).
+					Return([]repoModel.SharedAlbum{sharedAlbum(ownerUUID, "viewer")}, nil)
+				repo.EXPECT().GetAlbumPhotos(gomock.Any(), 7, gomock.Any(), true).Return(nil, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+		{
+			name:     "Album of another owner is ignored",
+			userUUID: userUUID,
+			act:      actionView,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoShare(gomock.Any(), 1, userUUID).Return(nil, repoErr.NotFoundError)
+				repo.EXPECT().GetContributedAlbums(gomock.Any(), 1).Return(nil, nil)
+				repo.EXPECT().GetSharedAlbums(gomock.Any(), userUUID).
+					Return([]repoModel.SharedAlbum{sharedAlbum("stranger-id", "contributor")}, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+		{
+			name:     "Album owner views contribution",
+			userUUID: userUUID,
+			act:      actionView,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoShare(gomock.Any(), 1, userUUID).Return(nil, repoErr.NotFoundError)
+				repo.EXPECT().GetContributedAlbums(gomock.Any(), 1).
+					Return([]repoModel.Album{{ID: 7, UserUUID: userUUID, Filter: []byte(`{}`)}}, nil)
+			},
+		},
+		{
+			name:     "Contribution in shared album",
+			userUUID: userUUID,
+			act:      actionView,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoShare(gomock.Any(), 1, userUUID).Return(nil, repoErr.NotFoundError)
+				repo.EXPECT().GetContributedAlbums(gomock.Any(), 1).
+					Return([]repoModel.Album{{ID: 7, UserUUID: "stranger-id", Filter: []byte(`{}`)}}, nil)
+				repo.EXPECT().GetSharedAlbums(gomock.Any(), userUUID).
+					Return([]repoModel.SharedAlbum{sharedAlbum("stranger-id", "viewer")}, nil)
+			},
+		},
+		{
+			name:     "Viewer cannot contribute",
+			userUUID: userUUID,
+			act:      actionContribute,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoShare(gomock.Any(), 1, userUUID).Return(&repoModel.Share{GranteeUUID: userUUID, Role: "viewer"}, nil)
+				repo.EXPECT().GetContributedAlbums(gomock.Any(), 1).Return(nil, nil)
+				repo.EXPECT().GetSharedAlbums(gomock.Any(), userUUID).
+					Return([]repoModel.SharedAlbum{sharedAlbum(ownerUUID, "viewer")}, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+		{
+			name:     "Repo error",
+			userUUID: userUUID,
+			act:      actionView,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoShare(gomock.Any(), 1, userUUID).Return(nil, errors.New("db error"))
+			},
+			expectedErr: serviceErr.UnexpectedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			err := s.authorizePhoto(context.Background(), tt.userUUID, photo, tt.act)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
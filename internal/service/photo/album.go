@@ -0,0 +1,365 @@
+package photo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-photo/internal/model"
+	repoErr "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/converter"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"go-photo/pkg/logger"
+	"mime/multipart"
+	"strings"
+	"unicode/utf8"
+)
+
+func (s *service) CreateAlbum(ctx context.Context, userUUID string, params serviceModel.SaveAlbumParams) (*serviceModel.Album, error) {
+	saveParams, err := toSaveAlbumParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	album, err := s.photoRepository.CreateAlbum(ctx, userUUID, saveParams)
+	if err := s.handleAlbumRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toAlbum(album)
+}
+
+func (s *service) GetAlbums(ctx context.Context, userUUID string) ([]serviceModel.Album, error) {
+	albums, err := s.photoRepository.GetUserAlbums(ctx, userUUID)
+	if err := s.handleAlbumRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	res := make([]serviceModel.Album, len(albums))
+	for i := range albums {
+		album, err := toAlbum(&albums[i])
+		if err != nil {
+			return nil, err
+		}
+		res[i] = *album
+	}
+
+	return res, nil
+}
+
+func (s *service) GetAlbum(ctx context.Context, userUUID string, albumID int) (*serviceModel.Album, error) {
+	album, err := s.getAlbum(ctx, userUUID, albumID, actionView)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := toAlbum(album)
+	if err != nil {
+		return nil, err
+	}
+	// Публикацией управляет только владелец
+	if album.UserUUID != userUUID {
+		res.PublicToken = ""
+		res.MetadataPolicy = ""
+		res.Watermark = false
+	}
+
+	return res, nil
+}
+
+func (s *service) UpdateAlbum(ctx context.Context, userUUID string, albumID int, params serviceModel.SaveAlbumParams) (*serviceModel.Album, error) {
+	saveParams, err := toSaveAlbumParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getAlbum(ctx, userUUID, albumID, actionManage); err != nil {
+		return nil, err
+	}
+
+	album, err := s.photoRepository.UpdateAlbum(ctx, albumID, saveParams)
+	if err := s.handleAlbumRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toAlbum(album)
+}
+
+func (s *service) DeleteAlbum(ctx context.Context, userUUID string, albumID int) error {
+	if _, err := s.getAlbum(ctx, userUUID, albumID, actionManage); err != nil {
+		return err
+	}
+
+	err := s.photoRepository.DeleteAlbum(ctx, albumID)
+	return s.handleAlbumRepoErr(ctx, err)
+}
+
+func (s *service) GetAlbumPhotos(ctx context.Context, userUUID string, albumID int, limit, offset int) ([]model.Photo, error) {
+	album, err := s.getAlbum(ctx, userUUID, albumID, actionView)
+	if err != nil {
+		return nil, err
+	}
+
+	// Скрытые фото видит только владелец
+	listParams, ownerPhotos, err := albumListParams(album, limit, offset, album.UserUUID != userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	photos, err := s.photoRepository.GetAlbumPhotos(ctx, album.ID, listParams, ownerPhotos)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return converter.ToPhotosFromRepo(photos), nil
+}
+
+func (s *service) PublishAlbum(ctx context.Context, userUUID string, albumID int, opts serviceModel.PublishOptions) (string, error) {
+	if _, err := s.getAlbum(ctx, userUUID, albumID, actionManage); err != nil {
+		return "", err
+	}
+
+	if opts.Watermark {
+		_, err := s.photoRepository.GetWatermarkSettings(ctx, userUUID)
+		if err := s.handleWatermarkRepoErr(ctx, err); err != nil {
+			return "", err
+		}
+	}
+
+	policy := opts.Metadata
+	if policy == "" {
+		settings, err := s.GetPublishSettings(ctx, userUUID)
+		if err != nil {
+			return "", err
+		}
+		policy = settings.MetadataPolicy
+	}
+	if _, err := serviceModel.ParseMetadataPolicy(string(policy)); err != nil {
+		return "", err
+	}
+
+	publicToken, err := s.photoRepository.PublishAlbum(ctx, albumID, string(policy), opts.Watermark)
+	if err := s.handleAlbumRepoErr(ctx, err); err != nil {
+		return "", err
+	}
+
+	return publicToken, nil
+}
+
+func (s *service) UnpublishAlbum(ctx context.Context, userUUID string, albumID int) error {
+	if _, err := s.getAlbum(ctx, userUUID, albumID, actionManage); err != nil {
+		return err
+	}
+
+	err := s.photoRepository.UnpublishAlbum(ctx, albumID)
+	return s.handleAlbumRepoErr(ctx, err)
+}
+
+func (s *service) UploadAlbumPhotos(ctx context.Context, userUUID string, albumID int, photoFiles []*multipart.FileHeader) (*serviceModel.UploadInfoList, error) {
+	if _, err := s.getAlbum(ctx, userUUID, albumID, actionContribute); err != nil {
+		return nil, err
+	}
+
+	uploaded, err := s.UploadBatchPhotos(ctx, userUUID, photoFiles)
+	if uploaded == nil {
+		return nil, err
+	}
+
+	infos := uploaded.Get()
+	for i := range infos {
+		if infos[i].Error != nil {
+			continue
+		}
+
+		err := s.photoRepository.AddAlbumPhoto(ctx, albumID, infos[i].PhotoID, userUUID)
+		if err := s.handleAlbumRepoErr(ctx, err); err != nil {
+			// Фото уже загружено и остается у пользователя
+			logger.FromContext(ctx).Errorf("Failed to add photo %d to album %d: %v", infos[i].PhotoID, albumID, err)
+			infos[i].Error = fmt.Errorf("photo %d is uploaded but not added to album: %w", infos[i].PhotoID, err)
+		}
+	}
+
+	uploaded = serviceModel.NewUploadInfoList(infos)
+	return uploaded, uploadListErr(uploaded)
+}
+
+// addAlbumPhoto добавляет фото пользователя в альбом, в который ему разрешено добавлять фото.
+// Добавить можно только свое фото, оно остается у пользователя.
+func (s *service) addAlbumPhoto(ctx context.Context, userUUID string, albumID int, photoID int) error {
+	album, err := s.getAlbum(ctx, userUUID, albumID, actionContribute)
+	if err != nil {
+		return err
+	}
+
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return err
+	}
+
+	err = s.photoRepository.AddAlbumPhoto(ctx, album.ID, photo.ID, userUUID)
+	return s.handleAlbumRepoErr(ctx, err)
+}
+
+func (s *service) RemoveAlbumPhoto(ctx context.Context, userUUID string, albumID int, photoID int) error {
+	if _, err := s.getAlbum(ctx, userUUID, albumID, actionManage); err != nil {
+		return err
+	}
+
+	err := s.photoRepository.DeleteAlbumPhoto(ctx, albumID, photoID)
+	return s.HandleRepoErr(ctx, err)
+}
+
+func (s *service) GetPublicAlbum(ctx context.Context, token string, limit, offset int) (*serviceModel.PublicAlbum, error) {
+	album, err := s.photoRepository.GetAlbumByToken(ctx, token)
+	if err := s.handleAlbumRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	listParams, ownerPhotos, err := albumListParams(album, limit, offset, true)
+	if err != nil {
+		return nil, err
+	}
+
+	photos, err := s.photoRepository.GetAlbumPhotos(ctx, album.ID, listParams, ownerPhotos)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return &serviceModel.PublicAlbum{
+		Title:  album.Title,
+		Photos: converter.ToPhotosFromRepo(photos),
+	}, nil
+}
+
+func (s *service) GetPublicAlbumPhotoFile(ctx context.Context, token string, photoID int, version string, opts serviceModel.FileOptions) (*serviceModel.PhotoFile, error) {
+	versionType, err := model.ParseVersionType(version)
+	if err != nil {
+		return nil, serviceErr.InvalidVersionTypeError
+	}
+
+	album, err := s.photoRepository.GetAlbumByToken(ctx, token)
+	if err := s.handleAlbumRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	// Фото доступно, только пока оно отбирается фильтрами альбома или добавлено в него
+	photo, err := s.albumPhoto(ctx, album, photoID, true)
+	if err != nil {
+		return nil, err
+	}
+	if photo == nil {
+		return nil, fmt.Errorf("%w: photo %d is not in album %d", serviceErr.PhotoNotFoundError, photoID, album.ID)
+	}
+
+	mark, err := s.albumWatermark(ctx, album)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.photoRepository.GetPhotoVersions(ctx, photoID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	for i := range versions {
+		if versions[i].VersionType.String != string(versionType) {
+			continue
+		}
+		if mark != nil {
+			return s.watermarkedVersionFile(ctx, token, photo.UserUUID, &versions[i], mark, opts)
+		}
+		return s.publicVersionFile(ctx, photo.UserUUID, &versions[i], serviceModel.MetadataPolicy(album.MetadataPolicy.String), opts)
+	}
+
+	return nil, fmt.Errorf("%w: photo %d has no %s version", serviceErr.PhotoNotFoundError, photoID, versionType)
+}
+
+// handleAlbumRepoErr как HandleRepoErr, но отсутствие записи означает, что альбом не найден.
+func (s *service) handleAlbumRepoErr(ctx context.Context, err error) error {
+	if errors.Is(err, repoErr.NotFoundError) {
+		return fmt.Errorf("%w: %v", serviceErr.AlbumNotFoundError, err)
+	}
+	return s.HandleRepoErr(ctx, err)
+}
+
+// toSaveAlbumParams проверяет название и фильтры альбома. Фильтры сохраняются в каноническом JSON.
+func toSaveAlbumParams(params serviceModel.SaveAlbumParams) (*repoModel.SaveAlbumParams, error) {
+	title := strings.TrimSpace(params.Title)
+	if title == "" {
+		return nil, fmt.Errorf("%w: title is required", serviceErr.InvalidAlbumError)
+	}
+	if utf8.RuneCountInString(title) > serviceModel.MaxTitleLength {
+		return nil, fmt.Errorf("%w: title is longer than %d characters", serviceErr.InvalidAlbumError, serviceModel.MaxTitleLength)
+	}
+
+	rawFilter := params.Filter
+	if len(rawFilter) == 0 {
+		rawFilter = []byte("{}")
+	}
+	filter, err := serviceModel.ParseAlbumFilter(rawFilter)
+	if err != nil {
+		return nil, err
+	}
+	photoFilter, err := filter.PhotoFilter()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := toPhotoListParams(photoFilter); err != nil {
+		return nil, fmt.Errorf("%w: %v", serviceErr.InvalidAlbumError, err)
+	}
+
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to marshal album filter: %v", serviceErr.UnexpectedError, err)
+	}
+
+	return &repoModel.SaveAlbumParams{Title: title, Filter: data, Manual: params.Manual}, nil
+}
+
+// albumListParams строит параметры списка фото альбома и сообщает, отбираются ли в альбом фото владельца:
+// в ручной альбом они попадают только по непустому фильтру. Если visibleOnly (опубликованный альбом
+// или альбом другого пользователя), скрытые фото не показываются, даже если фильтры альбома их допускают.
+func albumListParams(album *repoModel.Album, limit, offset int, visibleOnly bool) (*repoModel.PhotoListParams, bool, error) {
+	filter, err := serviceModel.ParseAlbumFilter(album.Filter)
+	if err != nil {
+		return nil, false, err
+	}
+	photoFilter, err := filter.PhotoFilter()
+	if err != nil {
+		return nil, false, err
+	}
+	photoFilter.Limit = limit
+	photoFilter.Offset = offset
+	if visibleOnly {
+		visible := false
+		photoFilter.Hidden = &visible
+	}
+
+	listParams, err := toPhotoListParams(photoFilter)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return listParams, !album.Manual || !filter.IsEmpty(), nil
+}
+
+func toAlbum(album *repoModel.Album) (*serviceModel.Album, error) {
+	filter, err := serviceModel.ParseAlbumFilter(album.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &serviceModel.Album{
+		ID:             album.ID,
+		Title:          album.Title,
+		Filter:         filter,
+		Manual:         album.Manual,
+		PublicToken:    album.PublicToken.String,
+		MetadataPolicy: serviceModel.MetadataPolicy(album.MetadataPolicy.String),
+		Watermark:      album.Watermark,
+		CreatedAt:      album.CreatedAt,
+		UpdatedAt:      album.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,497 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_CreateAlbum(t *testing.T) {
+	const userUUID = "user-id"
+
+	tests := []struct {
+		name           string
+		params         serviceModel.SaveAlbumParams
+		expectedParams *repoModel.SaveAlbumParams
+		expectedErr    error
+	}{
+		{
+			name: "Filters stored canonically",
+			params: serviceModel.SaveAlbumParams{
+				Title:  "  Summer 2024 ",
+				Filter: []byte(`{"taken_from": "2024-06-01", "taken_to": "2024-08-31", "min_rating": 4, "camera": "fuji"}`),
+			},
+			expectedParams: &repoModel.SaveAlbumParams{
+				Title:  "Summer 2024",
+				Filter: []byte(`{"min_rating":4,"taken_from":"2024-06-01","taken_to":"2024-08-31","camera":"fuji"}`),
+			},
+		},
+		{
+			name:           "No filter",
+			params:         serviceModel.SaveAlbumParams{Title: "Everything"},
+			expectedParams: &repoModel.SaveAlbumParams{Title: "Everything", Filter: []byte(`{}`)},
+		},
+		{
+			name:           "Manual album",
+			params:         serviceModel.SaveAlbumParams{Title: "Trip", Manual: true},
+			expectedParams: &repoModel.SaveAlbumParams{Title: "Trip", Filter: []byte(`{}`), Manual: true},
+		},
+		{
+			name:        "No title",
+			params:      serviceModel.SaveAlbumParams{Title: " ", Filter: []byte(`{}`)},
+			expectedErr: serviceErr.InvalidAlbumError,
+		},
+		{
+			name:        "Unknown filter",
+			params:      serviceModel.SaveAlbumParams{Title: "Tagged", Filter: []byte(`{"tags": ["cats"]}`)},
+			expectedErr: serviceErr.InvalidAlbumError,
+		},
+		{
+			name:        "Invalid date",
+			params:      serviceModel.SaveAlbumParams{Title: "Summer", Filter: []byte(`{"taken_from": "June"}`)},
+			expectedErr: serviceErr.InvalidAlbumError,
+		},
+		{
+			name:        "Invalid rating",
+			params:      serviceModel.SaveAlbumParams{Title: "Best", Filter: []byte(`{"min_rating": 9}`)},
+			expectedErr: serviceErr.InvalidAlbumError,
+		},
+		{
+			name:        "Invalid bounding box",
+			params:      serviceModel.SaveAlbumParams{Title: "Moscow", Filter: []byte(`{"bbox": {"west": 37.3, "south": 56, "east": 37.9, "north": 55.5}}`)},
+			expectedErr: serviceErr.InvalidAlbumError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			if tt.expectedParams != nil {
+				mockRepo.EXPECT().CreateAlbum(gomock.Any(), userUUID, tt.expectedParams).
+					Return(&repoModel.Album{ID: 1, UserUUID: userUUID, Title: tt.expectedParams.Title, Filter: tt.expectedParams.Filter, CreatedAt: createdAt}, nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			album, err := s.CreateAlbum(context.Background(), userUUID, tt.params)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, album.ID)
+			assert.Equal(t, tt.expectedParams.Title, album.Title)
+			assert.Equal(t, createdAt, album.CreatedAt)
+		})
+	}
+}
+
+func TestService_GetAlbumPhotos(t *testing.T) {
+	const userUUID = "user-id"
+
+	visible := false
+	minRating := 4
+	takenFrom := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	takenBefore := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	album := &repoModel.Album{
+		ID: 1, UserUUID: userUUID, Title: "Summer",
+		Filter: []byte(`{"min_rating": 4, "taken_from": "2024-06-01", "taken_to": "2024-08-31"}`),
+	}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		userUUID     string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:     "Filters evaluated with page",
+			userUUID: userUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().GetAlbumPhotos(gomock.Any(), 1, &repoModel.PhotoListParams{
+					Hidden: &visible, MinRating: &minRating, TakenFrom: &takenFrom, TakenBefore: &takenBefore,
+					Limit: 10, Offset: 20,
+				}, true).Return([]repoModel.Photo{{ID: 5, UserUUID: userUUID}}, nil)
+			},
+		},
+		{
+			name:     "Album shared with user",
+			userUUID: "other-user",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().GetAlbumShare(gomock.Any(), 1, "other-user").Return(&repoModel.Share{GranteeUUID: "other-user", Role: "viewer"}, nil)
+				repo.EXPECT().GetAlbumPhotos(gomock.Any(), 1, &repoModel.PhotoListParams{
+					Hidden: &visible, MinRating: &minRating, TakenFrom: &takenFrom, TakenBefore: &takenBefore,
+					Limit: 10, Offset: 20,
+				}, true).Return([]repoModel.Photo{{ID: 5, UserUUID: userUUID}}, nil)
+			},
+		},
+		{
+			name:     "Manual album without criteria shared with user",
+			userUUID: "other-user",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				manual := &repoModel.Album{ID: 1, UserUUID: userUUID, Title: "Trip", Filter: []byte(`{}`), Manual: true}
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(manual, nil)
+				repo.EXPECT().GetAlbumShare(gomock.Any(), 1, "other-user").Return(&repoModel.Share{GranteeUUID: "other-user", Role: "viewer"}, nil)
+				// Фото владельца не отбираются, остаются только добавленные
+				repo.EXPECT().GetAlbumPhotos(gomock.Any(), 1, &repoModel.PhotoListParams{
+					Hidden: &visible, Limit: 10, Offset: 20,
+				}, false).Return([]repoModel.Photo{{ID: 5, UserUUID: "other-user"}}, nil)
+			},
+		},
+		{
+			name:     "Manual album with criteria",
+			userUUID: userUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				manual := *album
+				manual.Manual = true
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(&manual, nil)
+				repo.EXPECT().GetAlbumPhotos(gomock.Any(), 1, &repoModel.PhotoListParams{
+					Hidden: &visible, MinRating: &minRating, TakenFrom: &takenFrom, TakenBefore: &takenBefore,
+					Limit: 10, Offset: 20,
+				}, true).Return([]repoModel.Photo{{ID: 5, UserUUID: userUUID}}, nil)
+			},
+		},
+		{
+			name:     "Album of other user",
+			userUUID: "other-user",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().GetAlbumShare(gomock.Any(), 1, "other-user").Return(nil, repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+		{
+			name:     "Album not found",
+			userUUID: userUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(nil, repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.AlbumNotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			photos, err := s.GetAlbumPhotos(context.Background(), tt.userUUID, 1, 10, 20)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, photos, 1)
+			assert.Equal(t, 5, photos[0].ID)
+		})
+	}
+}
+
+func TestService_PublishAlbum(t *testing.T) {
+	const userUUID = "user-id"
+
+	tests := []struct {
+		name           string
+		policy         serviceModel.MetadataPolicy
+		watermark      bool
+		mockBehavior   func(repo *mock_repository.MockPhotoRepository)
+		expectedPolicy string
+		expectedErr    error
+	}{
+		{
+			name:           "Explicit policy",
+			policy:         serviceModel.MetadataKeep,
+			mockBehavior:   func(repo *mock_repository.MockPhotoRepository) {},
+			expectedPolicy: "keep",
+		},
+		{
+			name: "No user settings",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublishSettings(gomock.Any(), userUUID).Return(nil, repoErr.NotFoundError)
+			},
+			expectedPolicy: "strip_private",
+		},
+		{
+			name:         "Unknown policy",
+			policy:       "strip_some",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidMetadataPolicyError,
+		},
+		{
+			name:      "Watermark",
+			policy:    serviceModel.MetadataStripAll,
+			watermark: true,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetWatermarkSettings(gomock.Any(), userUUID).Return(&repoModel.WatermarkSettings{}, nil)
+			},
+			expectedPolicy: "strip_all",
+		},
+		{
+			name:      "Watermark not configured",
+			policy:    serviceModel.MetadataStripAll,
+			watermark: true,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetWatermarkSettings(gomock.Any(), userUUID).Return(nil, repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.WatermarkNotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			mockRepo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(&repoModel.Album{ID: 1, UserUUID: userUUID, Filter: []byte(`{}`)}, nil)
+			tt.mockBehavior(mockRepo)
+			if tt.expectedErr == nil {
+				mockRepo.EXPECT().PublishAlbum(gomock.Any(), 1, tt.expectedPolicy, tt.watermark).Return("token", nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			token, err := s.PublishAlbum(context.Background(), userUUID, 1, serviceModel.PublishOptions{
+				Watermark: tt.watermark,
+				Metadata:  tt.policy,
+			})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "token", token)
+		})
+	}
+}
+
+func TestService_GetPublicAlbum(t *testing.T) {
+	const userUUID = "user-id"
+
+	visible := false
+	album := &repoModel.Album{
+		ID: 1, UserUUID: userUUID, Title: "Everything", Filter: []byte(`{"hidden": "any"}`),
+		PublicToken: sql.NullString{String: "token", Valid: true}, MetadataPolicy: sql.NullString{String: "keep", Valid: true},
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetAlbumByToken(gomock.Any(), "token").Return(album, nil)
+	// Скрытые фото не показываются по публичной ссылке, даже если фильтры альбома их допускают
+	mockRepo.EXPECT().GetAlbumPhotos(gomock.Any(), 1, &repoModel.PhotoListParams{Hidden: &visible, Limit: 50}, true).
+		Return([]repoModel.Photo{{ID: 5, UserUUID: userUUID}}, nil)
+	mockRepo.EXPECT().GetAlbumByToken(gomock.Any(), "unknown").Return(nil, repoErr.NotFoundError)
+
+	s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+	public, err := s.GetPublicAlbum(context.Background(), "token", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Everything", public.Title)
+	require.Len(t, public.Photos, 1)
+
+	_, err = s.GetPublicAlbum(context.Background(), "unknown", 0, 0)
+	assert.ErrorIs(t, err, serviceErr.AlbumNotFoundError)
+}
+
+func TestService_GetPublicAlbumPhotoFile(t *testing.T) {
+	const userUUID = "user-id"
+
+	storage := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(storage, userUUID), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(storage, userUUID, "original.png"), []byte("png data"), 0644))
+
+	visible := false
+	album := &repoModel.Album{
+		ID: 1, UserUUID: userUUID, Filter: []byte(`{"favorite": true}`),
+		PublicToken: sql.NullString{String: "token", Valid: true}, MetadataPolicy: sql.NullString{String: "keep", Valid: true},
+	}
+	favorite := true
+	inAlbum := 5
+	notInAlbum := 6
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetAlbumByToken(gomock.Any(), "token").Return(album, nil).Times(2)
+	mockRepo.EXPECT().GetAlbumPhotos(gomock.Any(), 1, &repoModel.PhotoListParams{
+		Favorite: &favorite, Hidden: &visible, PhotoID: &inAlbum, Limit: 1,
+	}, true).Return([]repoModel.Photo{{ID: inAlbum, UserUUID: userUUID}}, nil)
+	mockRepo.EXPECT().GetAlbumPhotos(gomock.Any(), 1, &repoModel.PhotoListParams{
+		Favorite: &favorite, Hidden: &visible, PhotoID: &notInAlbum, Limit: 1,
+	}, true).Return([]repoModel.Photo{}, nil)
+	mockRepo.EXPECT().GetPhotoVersions(gomock.Any(), inAlbum).Return([]repoModel.PhotoVersion{
+		{ID: 10, PhotoID: inAlbum, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "original.png"},
+	}, nil)
+
+	s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+	file, err := s.GetPublicAlbumPhotoFile(context.Background(), "token", inAlbum, "original", serviceModel.FileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png data"), file.Data)
+
+	_, err = s.GetPublicAlbumPhotoFile(context.Background(), "token", notInAlbum, "original", serviceModel.FileOptions{})
+	assert.ErrorIs(t, err, serviceErr.PhotoNotFoundError)
+}
+
+func TestService_UploadAlbumPhotos(t *testing.T) {
+	const (
+		ownerUUID       = "owner-id"
+		contributorUUID = "contributor-id"
+	)
+
+	album := &repoModel.Album{ID: 1, UserUUID: ownerUUID, Title: "Trip", Filter: []byte(`{}`)}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "Contributor uploads",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().GetAlbumShare(gomock.Any(), 1, contributorUUID).
+					Return(&repoModel.Share{GranteeUUID: contributorUUID, Role: "contributor"}, nil)
+				repo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(1, nil)
+				repo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).Return(5, nil)
+				repo.EXPECT().DeletePendingUpload(gomock.Any(), 1).Return(nil)
+				repo.EXPECT().AddAlbumPhoto(gomock.Any(), 1, 5, contributorUUID).Return(nil)
+			},
+		},
+		{
+			name: "Album deleted during upload",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().GetAlbumShare(gomock.Any(), 1, contributorUUID).
+					Return(&repoModel.Share{GranteeUUID: contributorUUID, Role: "contributor"}, nil)
+				repo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(1, nil)
+				repo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).Return(5, nil)
+				repo.EXPECT().DeletePendingUpload(gomock.Any(), 1).Return(nil)
+				repo.EXPECT().AddAlbumPhoto(gomock.Any(), 1, 5, contributorUUID).Return(repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.AllFailedError,
+		},
+		{
+			name: "Viewer cannot upload",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().GetAlbumShare(gomock.Any(), 1, contributorUUID).
+					Return(&repoModel.Share{GranteeUUID: contributorUUID, Role: "viewer"}, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageDir := t.TempDir()
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: storageDir}, mockRepo, nil)
+
+			uploaded, err := s.UploadAlbumPhotos(context.Background(), contributorUUID, 1,
+				[]*multipart.FileHeader{mockFileHeader("test1.jpg", 100, "file content")})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 1, uploaded.SuccessCount())
+			// Фото хранится у загрузившего участника, а не у владельца альбома
+			assert.FileExists(t, filepath.Join(storageDir, contributorUUID, uploaded.Get()[0].UUIDFilename))
+		})
+	}
+}
+
+func TestService_RemoveAlbumPhoto(t *testing.T) {
+	const ownerUUID = "owner-id"
+
+	album := &repoModel.Album{ID: 1, UserUUID: ownerUUID, Title: "Trip", Filter: []byte(`{}`)}
+
+	tests := []struct {
+		name         string
+		userUUID     string
+		mockBehavior func(repo *mock_repository.MockPhotoRepository)
+		expectedErr  error
+	}{
+		{
+			name:     "Owner removes contribution",
+			userUUID: ownerUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().DeleteAlbumPhoto(gomock.Any(), 1, 5).Return(nil)
+			},
+		},
+		{
+			name:     "Photo not added",
+			userUUID: ownerUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().DeleteAlbumPhoto(gomock.Any(), 1, 5).Return(repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+		{
+			name:     "Contributor cannot remove",
+			userUUID: "contributor-id",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			err := s.RemoveAlbumPhoto(context.Background(), tt.userUUID, 1, 5)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	repoErr "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/converter"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"go-photo/pkg/imaging"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+func (s *service) GetPhotos(ctx context.Context, userUUID string, filter serviceModel.PhotoFilter) ([]model.Photo, error) {
+	listParams, err := toPhotoListParams(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	photos, err := s.photoRepository.GetUserPhotos(ctx, userUUID, listParams)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return converter.ToPhotosFromRepo(photos), nil
+}
+
+func (s *service) GetPhoto(ctx context.Context, userUUID string, photoID int) (*model.Photo, error) {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionView)
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ToPhotoFromRepo(photo, nil), nil
+}
+
+func (s *service) UpdatePhotoAttributes(ctx context.Context, userUUID string, photoID int, patch []byte, ifMatch string) (*model.Photo, error) {
+	repoPhoto, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return nil, err
+	}
+	photo := converter.ToPhotoFromRepo(repoPhoto, nil)
+
+	if !etagMatches(ifMatch, photo.ETag()) {
+		return nil, fmt.Errorf("%w: photo %d has version %s", serviceErr.PreconditionFailedError, photoID, photo.ETag())
+	}
+
+	attrs, err := serviceModel.ToPhotoAttributes(photo).ApplyMergePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt, err := s.photoRepository.UpdatePhotoAttributes(ctx, photo.ID, &repoModel.UpdatePhotoAttributesParams{
+		Title:     attrs.Title,
+		Caption:   attrs.Caption,
+		Favorite:  attrs.Favorite,
+		Rating:    attrs.Rating,
+		Hidden:    attrs.Hidden,
+		UpdatedAt: repoPhoto.UpdatedAt,
+	})
+	// Фото изменили или удалили между чтением и записью: клиент должен перечитать его
+	if errors.Is(err, repoErr.NotFoundError) {
+		return nil, fmt.Errorf("%w: photo %d was modified concurrently", serviceErr.PreconditionFailedError, photoID)
+	}
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	photo.Title = attrs.Title
+	photo.Caption = attrs.Caption
+	photo.Favorite = attrs.Favorite
+	photo.Rating = attrs.Rating
+	photo.Hidden = attrs.Hidden
+	photo.UpdatedAt = updatedAt
+
+	return photo, nil
+}
+
+// etagMatches проверяет заголовок If-Match: пустой заголовок и * подходят к любой версии,
+// иначе версия должна совпасть с одним из перечисленных ETag.
+func etagMatches(ifMatch, etag string) bool {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(candidate) == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toPhotoListParams(filter serviceModel.PhotoFilter) (*repoModel.PhotoListParams, error) {
+	if filter.Limit < 0 || filter.Limit > config.MaxPhotoListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", serviceErr.InvalidFilterError, config.MaxPhotoListLimit)
+	}
+	if filter.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", serviceErr.InvalidFilterError)
+	}
+	for _, rating := range []*int{filter.MinRating, filter.MaxRating} {
+		if rating != nil && (*rating < 0 || *rating > serviceModel.MaxRating) {
+			return nil, fmt.Errorf("%w: rating must be between 0 and %d", serviceErr.InvalidFilterError, serviceModel.MaxRating)
+		}
+	}
+
+	colorFilter, err := toColorFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var bbox *repoModel.BBox
+	if filter.BBox != nil {
+		if err := filter.BBox.Validate(); err != nil {
+			return nil, err
+		}
+		b := toRepoBBox(*filter.BBox)
+		bbox = &b
+	}
+
+	takenFrom, takenBefore, err := toTakenRange(filter.TakenFrom, filter.TakenTo)
+	if err != nil {
+		return nil, err
+	}
+	if filter.TimeZone != "" {
+		if _, _, err := loadTimeZone(filter.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+
+	var camera *string
+	if c := strings.TrimSpace(filter.Camera); c != "" {
+		if utf8.RuneCountInString(c) > serviceModel.MaxCameraFilterLength {
+			return nil, fmt.Errorf("%w: camera is longer than %d characters", serviceErr.InvalidFilterError, serviceModel.MaxCameraFilterLength)
+		}
+		camera = &c
+	}
+
+	var tag *string
+	if filter.Tag != "" {
+		t, err := serviceModel.NormalizeTag(filter.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", serviceErr.InvalidFilterError, err)
+		}
+		tag = &t
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = config.DefaultPhotoListLimit
+	}
+
+	return &repoModel.PhotoListParams{
+		Favorite:    filter.Favorite,
+		Hidden:      filter.Hidden,
+		MinRating:   filter.MinRating,
+		MaxRating:   filter.MaxRating,
+		Color:       colorFilter,
+		BBox:        bbox,
+		TakenFrom:   takenFrom,
+		TakenBefore: takenBefore,
+		TimeZone:    filter.TimeZone,
+		Camera:      camera,
+		Published:   filter.Published,
+		Tag:         tag,
+		Limit:       limit,
+		Offset:      filter.Offset,
+	}, nil
+}
+
+// toTakenRange переводит дни съемки from и to включительно в полуинтервал [from, before).
+func toTakenRange(from, to *time.Time) (*time.Time, *time.Time, error) {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+
+	var takenFrom, takenBefore *time.Time
+	if from != nil {
+		d := day(*from)
+		takenFrom = &d
+	}
+	if to != nil {
+		d := day(*to).AddDate(0, 0, 1)
+		takenBefore = &d
+	}
+	if takenFrom != nil && takenBefore != nil && !takenFrom.Before(*takenBefore) {
+		return nil, nil, fmt.Errorf("%w: taken_from must not be after taken_to", serviceErr.InvalidFilterError)
+	}
+
+	return takenFrom, takenBefore, nil
+}
+
+func toColorFilter(filter serviceModel.PhotoFilter) (*repoModel.ColorFilter, error) {
+	if filter.ColorTolerance < 0 || filter.ColorTolerance > config.MaxColorTolerance {
+		return nil, fmt.Errorf("%w: tolerance must be between 1 and %d", serviceErr.InvalidFilterError, config.MaxColorTolerance)
+	}
+	if filter.Color == "" {
+		if filter.ColorTolerance != 0 {
+			return nil, fmt.Errorf("%w: tolerance requires color", serviceErr.InvalidFilterError)
+		}
+		return nil, nil
+	}
+
+	c, err := imaging.ParseHexColor(filter.Color)
+	if err != nil {
+		return nil, fmt.Errorf("%w: color must be in #rrggbb format", serviceErr.InvalidFilterError)
+	}
+
+	tolerance := filter.ColorTolerance
+	if tolerance == 0 {
+		tolerance = config.DefaultColorTolerance
+	}
+
+	return &repoModel.ColorFilter{Color: imaging.ToLab(c), Tolerance: float64(tolerance)}, nil
+}
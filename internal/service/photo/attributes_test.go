@@ -0,0 +1,300 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/config"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"go-photo/pkg/imaging"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestService_UpdatePhotoAttributes(t *testing.T) {
+	const userUUID = "user-id"
+	readAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := readAt.Add(time.Second)
+	currentETag := fmt.Sprintf(`"1-%d"`, readAt.UnixMicro())
+
+	stored := func() *repoModel.Photo {
+		return &repoModel.Photo{
+			ID:        1,
+			UserUUID:  userUUID,
+			Title:     sql.NullString{String: "Old title", Valid: true},
+			Caption:   sql.NullString{String: "Caption", Valid: true},
+			Rating:    3,
+			UpdatedAt: readAt,
+		}
+	}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		patch        string
+		ifMatch      string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:  "Set, reset and keep fields",
+			patch: `{"title": "New title", "caption": null, "favorite": true}`,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(stored(), nil)
+				repo.EXPECT().UpdatePhotoAttributes(gomock.Any(), 1, &repoModel.UpdatePhotoAttributesParams{
+					Title:     "New title",
+					Favorite:  true,
+					Rating:    3,
+					UpdatedAt: readAt,
+				}).Return(updatedAt, nil)
+			},
+		},
+		{
+			name:    "Matching If-Match",
+			patch:   `{"rating": 5}`,
+			ifMatch: `"0-0", ` + currentETag,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(stored(), nil)
+				repo.EXPECT().UpdatePhotoAttributes(gomock.Any(), 1, &repoModel.UpdatePhotoAttributesParams{
+					Title:     "Old title",
+					Caption:   "Caption",
+					Rating:    5,
+					UpdatedAt: readAt,
+				}).Return(updatedAt, nil)
+			},
+		},
+		{
+			name:    "Stale If-Match",
+			patch:   `{"rating": 5}`,
+			ifMatch: `"1-0"`,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(stored(), nil)
+			},
+			expectedErr: serviceErr.PreconditionFailedError,
+		},
+		{
+			name:  "Modified concurrently",
+			patch: `{"rating": 5}`,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(stored(), nil)
+				repo.EXPECT().UpdatePhotoAttributes(gomock.Any(), 1, gomock.Any()).Return(time.Time{}, repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.PreconditionFailedError,
+		},
+		{
+			name:  "Rating out of range",
+			patch: `{"rating": 6}`,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(stored(), nil)
+			},
+			expectedErr: serviceErr.InvalidPatchError,
+		},
+		{
+			name:  "Unknown field",
+			patch: `{"filename": "x.jpg"}`,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(stored(), nil)
+			},
+			expectedErr: serviceErr.InvalidPatchError,
+		},
+		{
+			name:  "Wrong type",
+			patch: `{"favorite": "yes"}`,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(stored(), nil)
+			},
+			expectedErr: serviceErr.InvalidPatchError,
+		},
+		{
+			name:  "Not an object",
+			patch: `[1]`,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(stored(), nil)
+			},
+			expectedErr: serviceErr.InvalidPatchError,
+		},
+		{
+			name:  "Photo of another user",
+			patch: `{"rating": 5}`,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: "other"}, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			photo, err := s.UpdatePhotoAttributes(context.Background(), userUUID, 1, []byte(tt.patch), tt.ifMatch)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, updatedAt, photo.UpdatedAt)
+			assert.NotEqual(t, currentETag, photo.ETag())
+		})
+	}
+}
+
+func TestService_GetPhotos(t *testing.T) {
+	const userUUID = "user-id"
+	invalidRating := 6
+	camera := "X-T4"
+	tag := "sea"
+	newYear := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newYearMidnight := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextDay := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		filter             serviceModel.PhotoFilter
+		expectedListParams *repoModel.PhotoListParams
+		expectedErr        error
+	}{
+		{
+			name:               "Default limit",
+			filter:             serviceModel.PhotoFilter{Offset: 10},
+			expectedListParams: &repoModel.PhotoListParams{Limit: config.DefaultPhotoListLimit, Offset: 10},
+		},
+		{
+			name:        "Limit too large",
+			filter:      serviceModel.PhotoFilter{Limit: config.MaxPhotoListLimit + 1},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Rating out of range",
+			filter:      serviceModel.PhotoFilter{MinRating: &invalidRating},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:   "Color with default tolerance",
+			filter: serviceModel.PhotoFilter{Color: "#3366CC"},
+			expectedListParams: &repoModel.PhotoListParams{
+				Color: &repoModel.ColorFilter{Color: imaging.ToLab(color.RGBA{R: 0x33, G: 0x66, B: 0xCC, A: 0xFF}), Tolerance: config.DefaultColorTolerance},
+				Limit: config.DefaultPhotoListLimit,
+			},
+		},
+		{
+			name:   "Color without hash",
+			filter: serviceModel.PhotoFilter{Color: "3366cc", ColorTolerance: 25},
+			expectedListParams: &repoModel.PhotoListParams{
+				Color: &repoModel.ColorFilter{Color: imaging.ToLab(color.RGBA{R: 0x33, G: 0x66, B: 0xCC, A: 0xFF}), Tolerance: 25},
+				Limit: config.DefaultPhotoListLimit,
+			},
+		},
+		{
+			name:        "Invalid color",
+			filter:      serviceModel.PhotoFilter{Color: "blue"},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Tolerance too large",
+			filter:      serviceModel.PhotoFilter{Color: "#3366cc", ColorTolerance: config.MaxColorTolerance + 1},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:   "Bounding box",
+			filter: serviceModel.PhotoFilter{BBox: &serviceModel.BBox{West: 37, South: 55, East: 38, North: 56}},
+			expectedListParams: &repoModel.PhotoListParams{
+				BBox:  &repoModel.BBox{West: 37, South: 55, East: 38, North: 56},
+				Limit: config.DefaultPhotoListLimit,
+			},
+		},
+		{
+			name:        "Invalid bounding box",
+			filter:      serviceModel.PhotoFilter{BBox: &serviceModel.BBox{West: 37, South: 55, East: 190, North: 56}},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:   "Capture day and camera",
+			filter: serviceModel.PhotoFilter{TakenFrom: &newYear, TakenTo: &newYear, Camera: "  X-T4 "},
+			expectedListParams: &repoModel.PhotoListParams{
+				TakenFrom:   &newYearMidnight,
+				TakenBefore: &nextDay,
+				Camera:      &camera,
+				Limit:       config.DefaultPhotoListLimit,
+			},
+		},
+		{
+			name:   "Capture day in time zone",
+			filter: serviceModel.PhotoFilter{TakenFrom: &newYear, TimeZone: "Europe/Moscow"},
+			expectedListParams: &repoModel.PhotoListParams{
+				TakenFrom: &newYearMidnight,
+				TimeZone:  "Europe/Moscow",
+				Limit:     config.DefaultPhotoListLimit,
+			},
+		},
+		{
+			name:        "Unknown time zone",
+			filter:      serviceModel.PhotoFilter{TakenFrom: &newYear, TimeZone: "Mars/Olympus"},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Capture days reversed",
+			filter:      serviceModel.PhotoFilter{TakenFrom: &newYear, TakenTo: &december},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Tolerance without color",
+			filter:      serviceModel.PhotoFilter{ColorTolerance: 20},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:   "Tag",
+			filter: serviceModel.PhotoFilter{Tag: " Sea "},
+			expectedListParams: &repoModel.PhotoListParams{
+				Tag:   &tag,
+				Limit: config.DefaultPhotoListLimit,
+			},
+		},
+		{
+			name:        "Blank tag",
+			filter:      serviceModel.PhotoFilter{Tag: "  "},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			if tt.expectedListParams != nil {
+				mockRepo.EXPECT().GetUserPhotos(gomock.Any(), userUUID, tt.expectedListParams).
+					Return([]repoModel.Photo{{ID: 1, UserUUID: userUUID}}, nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			photos, err := s.GetPhotos(context.Background(), userUUID, tt.filter)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, photos, 1)
+		})
+	}
+}
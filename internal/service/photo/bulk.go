@@ -0,0 +1,103 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (s *service) BulkUpdatePhotos(ctx context.Context, userUUID string, action serviceModel.BulkAction, photoIDs []int, opts serviceModel.BulkOptions) (serviceModel.BulkResults, error) {
+	apply, err := s.bulkActionFunc(action, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "PhotoService.BulkUpdatePhotos",
+		trace.WithAttributes(
+			attribute.String("bulk.action", string(action)),
+			attribute.Int("photos.count", len(photoIDs)),
+		))
+	defer span.End()
+
+	// Действие над каждым фото выполняется отдельно со своей проверкой владельца,
+	// поэтому ошибка одного фото не отменяет остальные
+	seen := make(map[int]struct{}, len(photoIDs))
+	results := make(serviceModel.BulkResults, 0, len(photoIDs))
+	for _, photoID := range photoIDs {
+		if _, ok := seen[photoID]; ok {
+			continue
+		}
+		seen[photoID] = struct{}{}
+
+		result := serviceModel.BulkResult{PhotoID: photoID}
+		result.PublicToken, result.Error = apply(ctx, userUUID, photoID)
+		results = append(results, result)
+	}
+
+	span.SetAttributes(
+		attribute.Int("photos.success_count", results.SuccessCount()),
+		attribute.Int("photos.error_count", results.ErrorCount()),
+	)
+
+	if results.SuccessCount() == 0 {
+		span.SetStatus(codes.Error, serviceErr.AllFailedError.Error())
+		return results, serviceErr.AllFailedError
+	}
+	if results.ErrorCount() > 0 {
+		return results, serviceErr.ParticalSuccessError
+	}
+
+	return results, nil
+}
+
+// bulkActionFunc возвращает функцию, выполняющую действие над одним фото.
+// Первое возвращаемое значение - публичный токен, заполняется только при публикации.
+func (s *service) bulkActionFunc(action serviceModel.BulkAction, opts serviceModel.BulkOptions) (func(ctx context.Context, userUUID string, photoID int) (string, error), error) {
+	switch action {
+	case serviceModel.BulkPublish:
+		return func(ctx context.Context, userUUID string, photoID int) (string, error) {
+			return s.PublishPhoto(ctx, userUUID, photoID, serviceModel.PublishOptions{})
+		}, nil
+	case serviceModel.BulkUnpublish:
+		return func(ctx context.Context, userUUID string, photoID int) (string, error) {
+			return "", s.UnpublishPhoto(ctx, userUUID, photoID)
+		}, nil
+	case serviceModel.BulkDelete:
+		return func(ctx context.Context, userUUID string, photoID int) (string, error) {
+			return "", s.DeletePhoto(ctx, userUUID, photoID)
+		}, nil
+	case serviceModel.BulkAddToAlbum:
+		if opts.AlbumID == 0 {
+			return nil, fmt.Errorf("%w: album is required for %s", serviceErr.InvalidAlbumError, action)
+		}
+		return func(ctx context.Context, userUUID string, photoID int) (string, error) {
+			return "", s.addAlbumPhoto(ctx, userUUID, opts.AlbumID, photoID)
+		}, nil
+	case serviceModel.BulkTag:
+		tag, err := serviceModel.NormalizeTag(opts.Tag)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, userUUID string, photoID int) (string, error) {
+			return "", s.tagPhoto(ctx, userUUID, photoID, tag)
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", serviceErr.UnsupportedBulkActionError, action)
+	}
+}
+
+// tagPhoto добавляет нормализованный тег фото пользователя.
+func (s *service) tagPhoto(ctx context.Context, userUUID string, photoID int, tag string) error {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return err
+	}
+
+	err = s.photoRepository.AddPhotoTag(ctx, photo.ID, tag)
+	return s.HandleRepoErr(ctx, err)
+}
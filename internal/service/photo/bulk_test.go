@@ -0,0 +1,209 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"testing"
+	"time"
+)
+
+func TestService_BulkUpdatePhotos(t *testing.T) {
+	const userUUID = "user-id"
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	expectOwnPhoto := func(repo *mock_repository.MockPhotoRepository, photoID int) {
+		repo.EXPECT().GetPhotoByID(gomock.Any(), photoID).Return(&repoModel.Photo{ID: photoID, UserUUID: userUUID}, nil)
+	}
+
+	tests := []struct {
+		name            string
+		action          serviceModel.BulkAction
+		photoIDs        []int
+		opts            serviceModel.BulkOptions
+		mockBehavior    mockBehavior
+		expectedResults serviceModel.BulkResults
+		expectedErr     error
+	}{
+		{
+			name:     "Publish all",
+			action:   serviceModel.BulkPublish,
+			photoIDs: []int{1, 2},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				expectOwnPhoto(repo, 1)
+				repo.EXPECT().GetPublishSettings(gomock.Any(), userUUID).Return(nil, repoErr.NotFoundError)
+				repo.EXPECT().CreatePhotoPublishedInfo(gomock.Any(), &repoModel.CreatePhotoPublishedInfoParams{
+					PhotoID: 1, MetadataPolicy: "strip_private",
+				}).Return("token-1", nil)
+				expectOwnPhoto(repo, 2)
+				repo.EXPECT().GetPublishSettings(gomock.Any(), userUUID).Return(nil, repoErr.NotFoundError)
+				repo.EXPECT().CreatePhotoPublishedInfo(gomock.Any(), &repoModel.CreatePhotoPublishedInfoParams{
+					PhotoID: 2, MetadataPolicy: "strip_private",
+				}).Return("token-2", nil)
+			},
+			expectedResults: serviceModel.BulkResults{
+				{PhotoID: 1, PublicToken: "token-1"},
+				{PhotoID: 2, PublicToken: "token-2"},
+			},
+		},
+		{
+			name:     "Duplicate ids are processed once",
+			action:   serviceModel.BulkDelete,
+			photoIDs: []int{1, 1},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				expectOwnPhoto(repo, 1)
+				repo.EXPECT().TrashPhoto(gomock.Any(), 1).Return(nil)
+			},
+			expectedResults: serviceModel.BulkResults{
+				{PhotoID: 1},
+			},
+		},
+		{
+			name:     "Partial success",
+			action:   serviceModel.BulkDelete,
+			photoIDs: []int{1, 2, 3},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				expectOwnPhoto(repo, 1)
+				repo.EXPECT().TrashPhoto(gomock.Any(), 1).Return(nil)
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 2).Return(&repoModel.Photo{ID: 2, UserUUID: "other"}, nil)
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 3).Return(&repoModel.Photo{
+					ID: 3, UserUUID: userUUID, DeletedAt: sql.NullTime{Time: time.Now(), Valid: true},
+				}, nil)
+			},
+			expectedResults: serviceModel.BulkResults{
+				{PhotoID: 1},
+				{PhotoID: 2, Error: serviceErr.AccessDeniedError},
+				{PhotoID: 3, Error: serviceErr.PhotoNotFoundError},
+			},
+			expectedErr: serviceErr.ParticalSuccessError,
+		},
+		{
+			name:     "All failed",
+			action:   serviceModel.BulkUnpublish,
+			photoIDs: []int{1},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				expectOwnPhoto(repo, 1)
+				repo.EXPECT().DeletePhotoPublishedInfo(gomock.Any(), 1).Return(repoErr.NotFoundError)
+			},
+			expectedResults: serviceModel.BulkResults{
+				{PhotoID: 1, Error: serviceErr.PhotoNotFoundError},
+			},
+			expectedErr: serviceErr.AllFailedError,
+		},
+		{
+			name:     "Add to shared album",
+			action:   serviceModel.BulkAddToAlbum,
+			photoIDs: []int{1, 2},
+			opts:     serviceModel.BulkOptions{AlbumID: 7},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				album := &repoModel.Album{ID: 7, UserUUID: "owner", Filter: []byte(`{}`)}
+				contributor := &repoModel.Share{GranteeUUID: userUUID, Role: "contributor"}
+
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 7).Return(album, nil)
+				repo.EXPECT().GetAlbumShare(gomock.Any(), 7, userUUID).Return(contributor, nil)
+				expectOwnPhoto(repo, 1)
+				repo.EXPECT().AddAlbumPhoto(gomock.Any(), 7, 1, userUUID).Return(nil)
+
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 7).Return(album, nil)
+				repo.EXPECT().GetAlbumShare(gomock.Any(), 7, userUUID).Return(contributor, nil)
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 2).Return(&repoModel.Photo{ID: 2, UserUUID: "owner"}, nil)
+			},
+			expectedResults: serviceModel.BulkResults{
+				{PhotoID: 1},
+				{PhotoID: 2, Error: serviceErr.AccessDeniedError},
+			},
+			expectedErr: serviceErr.ParticalSuccessError,
+		},
+		{
+			name:     "Add to album as viewer",
+			action:   serviceModel.BulkAddToAlbum,
+			photoIDs: []int{1},
+			opts:     serviceModel.BulkOptions{AlbumID: 7},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 7).Return(&repoModel.Album{ID: 7, UserUUID: "owner", Filter: []byte(`{}`)}, nil)
+				repo.EXPECT().GetAlbumShare(gomock.Any(), 7, userUUID).Return(&repoModel.Share{GranteeUUID: userUUID, Role: "viewer"}, nil)
+			},
+			expectedResults: serviceModel.BulkResults{
+				{PhotoID: 1, Error: serviceErr.AccessDeniedError},
+			},
+			expectedErr: serviceErr.AllFailedError,
+		},
+		{
+			name:         "Add to album without album",
+			action:       serviceModel.BulkAddToAlbum,
+			photoIDs:     []int{1},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidAlbumError,
+		},
+		{
+			name:     "Tag",
+			action:   serviceModel.BulkTag,
+			photoIDs: []int{1, 2, 3},
+			opts:     serviceModel.BulkOptions{Tag: " Sea "},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				expectOwnPhoto(repo, 1)
+				repo.EXPECT().AddPhotoTag(gomock.Any(), 1, "sea").Return(nil)
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 2).Return(&repoModel.Photo{ID: 2, UserUUID: "other"}, nil)
+				expectOwnPhoto(repo, 3)
+				repo.EXPECT().AddPhotoTag(gomock.Any(), 3, "sea").Return(repoErr.NotFoundError)
+			},
+			expectedResults: serviceModel.BulkResults{
+				{PhotoID: 1},
+				{PhotoID: 2, Error: serviceErr.AccessDeniedError},
+				{PhotoID: 3, Error: serviceErr.PhotoNotFoundError},
+			},
+			expectedErr: serviceErr.ParticalSuccessError,
+		},
+		{
+			name:         "Tag without tag",
+			action:       serviceModel.BulkTag,
+			photoIDs:     []int{1},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidTagError,
+		},
+		{
+			name:         "Unsupported action",
+			action:       "rotate",
+			photoIDs:     []int{1},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.UnsupportedBulkActionError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			results, err := s.BulkUpdatePhotos(context.Background(), userUUID, tt.action, tt.photoIDs, tt.opts)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Len(t, results, len(tt.expectedResults))
+			for i, expected := range tt.expectedResults {
+				assert.Equal(t, expected.PhotoID, results[i].PhotoID)
+				assert.Equal(t, expected.PublicToken, results[i].PublicToken)
+				if expected.Error != nil {
+					assert.ErrorIs(t, results[i].Error, expected.Error)
+				} else {
+					assert.NoError(t, results[i].Error)
+				}
+			}
+		})
+	}
+}
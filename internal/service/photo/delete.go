@@ -5,12 +5,17 @@ import (
 )
 
 func (s *service) UnpublishPhoto(ctx context.Context, userUUID string, photoID int) error {
-	photo, err := s.getUserPhoto(ctx, userUUID, photoID)
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
 	if err != nil {
 		return err
 	}
 
 	err = s.photoRepository.DeletePhotoPublishedInfo(ctx, photo.ID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return err
+	}
+
+	s.removeVariants(ctx, photo.ID)
 
-	return s.HandleRepoErr(err)
+	return nil
 }
@@ -0,0 +1,246 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-photo/internal/model"
+	"go-photo/internal/repository/photo/converter"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"go-photo/internal/utils"
+	"go-photo/pkg/imaging"
+	"go-photo/pkg/logger"
+	"image"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// editedQuality качество JPEG и WebP файла отредактированной версии.
+// Правки применяются к оригиналу заново, поэтому потери не накапливаются.
+const editedQuality = 95
+
+func (s *service) EditPhoto(ctx context.Context, userUUID string, photoID int, ops []serviceModel.EditOperation) (*serviceModel.PhotoEdit, error) {
+	if err := serviceModel.ValidateEditOperations(ops); err != nil {
+		return nil, err
+	}
+
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saveEdit(ctx, photo, ops)
+}
+
+func (s *service) GetPhotoEdit(ctx context.Context, userUUID string, photoID int) (*serviceModel.PhotoEdit, error) {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	edit, ops, err := s.getEdit(ctx, photo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.photoRepository.GetPhotoVersions(ctx, photo.ID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	res := &serviceModel.PhotoEdit{PhotoID: photo.ID, Operations: ops, UpdatedAt: edit.UpdatedAt}
+	for _, v := range versions {
+		if v.ID == edit.VersionID {
+			res.Version = converter.ToPhotoVersionFromRepo(v)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *service) ReapplyPhotoEdit(ctx context.Context, userUUID string, photoID int) (*serviceModel.PhotoEdit, error) {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	_, ops, err := s.getEdit(ctx, photo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saveEdit(ctx, photo, ops)
+}
+
+func (s *service) RevertPhotoEdit(ctx context.Context, userUUID string, photoID int) error {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return err
+	}
+
+	version, err := s.photoRepository.DeletePhotoEdit(ctx, photo.ID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return err
+	}
+
+	s.removeVersionFile(ctx, photo.UserUUID, *version)
+
+	return nil
+}
+
+// getEdit возвращает рецепт правок фото. Если фото не редактировалось, возвращает PhotoNotFoundError.
+func (s *service) getEdit(ctx context.Context, photoID int) (*repoModel.PhotoEdit, []serviceModel.EditOperation, error) {
+	edit, err := s.photoRepository.GetPhotoEdit(ctx, photoID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, nil, err
+	}
+
+	var ops []serviceModel.EditOperation
+	if err := json.Unmarshal(edit.Operations, &ops); err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to decode edit of photo %d: %v", serviceErr.UnexpectedError, photoID, err)
+	}
+
+	return edit, ops, nil
+}
+
+// saveEdit применяет операции к оригиналу фото и сохраняет результат как отредактированную версию,
+// заменяя прежнюю. Оригинал не меняется.
+func (s *service) saveEdit(ctx context.Context, photo *repoModel.Photo, ops []serviceModel.EditOperation) (*serviceModel.PhotoEdit, error) {
+	versions, err := s.photoRepository.GetPhotoVersions(ctx, photo.ID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	var original *repoModel.PhotoVersion
+	for i, v := range versions {
+		if !v.VersionType.Valid || v.VersionType.String == string(model.Original) {
+			original = &versions[i]
+		}
+	}
+	if original == nil {
+		return nil, fmt.Errorf("%w: photo %d has no original version", serviceErr.UnexpectedError, photo.ID)
+	}
+
+	img, err := decodeImageFile(filepath.Join(s.d.StorageFolderPath, photo.UserUUID, original.UUIDFilename))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", serviceErr.UnexpectedError, err)
+	}
+
+	img, err = applyEdits(img, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	// Форматы, которые нельзя закодировать (например, GIF), сохраняются в PNG без потерь
+	format, ok := serviceModel.ParseImageFormat(filepath.Ext(original.UUIDFilename))
+	if !ok {
+		format = serviceModel.FormatPNG
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format.Ext(), editedQuality); err != nil {
+		return nil, fmt.Errorf("%w: failed to encode edited version: %v", serviceErr.UnexpectedError, err)
+	}
+	data := buf.Bytes()
+
+	recipe, err := json.Marshal(ops)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to encode edit: %v", serviceErr.UnexpectedError, err)
+	}
+
+	uuidFilename := s.utils.UUIDFilename(string(model.Edited) + format.Ext())
+	path := filepath.Join(s.d.StorageFolderPath, photo.UserUUID, uuidFilename)
+	if err := writeFileAtomically(path, data); err != nil {
+		return nil, fmt.Errorf("%w: failed to write edited version: %v", serviceErr.UnexpectedError, err)
+	}
+
+	checksum := utils.NewChecksum()
+	checksum.Write(data)
+
+	params := &repoModel.SavePhotoEditParams{
+		PhotoID:      photo.ID,
+		Operations:   recipe,
+		UUIDFilename: uuidFilename,
+		Size:         int64(len(data)),
+		Height:       img.Bounds().Dy(),
+		Width:        img.Bounds().Dx(),
+		Checksum:     utils.FormatChecksum(checksum),
+		SavedAt:      time.Now(),
+	}
+	versionID, replaced, err := s.photoRepository.SavePhotoEdit(ctx, params)
+	if err != nil {
+		if rmErr := os.Remove(path); rmErr != nil {
+			logger.FromContext(ctx).Errorf("Failed to remove unsaved edited version %s: %v", path, rmErr)
+		}
+		return nil, s.HandleRepoErr(ctx, err)
+	}
+
+	if replaced != nil {
+		s.removeVersionFile(ctx, photo.UserUUID, *replaced)
+	}
+
+	return &serviceModel.PhotoEdit{
+		PhotoID:    photo.ID,
+		Operations: ops,
+		Version: model.PhotoVersion{
+			ID:           versionID,
+			PhotoID:      photo.ID,
+			VersionType:  model.Edited,
+			UUIDFilename: params.UUIDFilename,
+			Size:         params.Size,
+			Height:       params.Height,
+			Width:        params.Width,
+			SavedAt:      params.SavedAt,
+		},
+		UpdatedAt: params.SavedAt,
+	}, nil
+}
+
+// removeVersionFile удаляет файл версии, уже удаленной из БД, и ее варианты в других форматах.
+// Ошибка только логируется: оставшийся файл найдет fsck.
+func (s *service) removeVersionFile(ctx context.Context, userUUID string, version repoModel.PhotoVersion) {
+	path := filepath.Join(s.d.StorageFolderPath, userUUID, version.UUIDFilename)
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.FromContext(ctx).Errorf("Failed to remove file %s of version %d: %v", path, version.ID, err)
+	}
+	s.removeRenditions(ctx, userUUID, []repoModel.PhotoVersion{version})
+}
+
+// applyEdits применяет операции к изображению по порядку.
+// Операции, неприменимые к изображению (например, обрезка за его границами), дают InvalidEditError.
+func applyEdits(img image.Image, ops []serviceModel.EditOperation) (image.Image, error) {
+	var err error
+	for i, op := range ops {
+		switch op.Type {
+		case serviceModel.EditRotate:
+			img, err = imaging.Rotate(img, op.Angle)
+		case serviceModel.EditFlip:
+			if op.Direction == serviceModel.FlipVertical {
+				img = imaging.FlipVertical(img)
+			} else {
+				img = imaging.FlipHorizontal(img)
+			}
+		case serviceModel.EditCrop:
+			img, err = imaging.Crop(img, image.Rect(op.X, op.Y, op.X+op.Width, op.Y+op.Height))
+		case serviceModel.EditBrightness:
+			img, err = imaging.AdjustBrightness(img, op.Value)
+		case serviceModel.EditContrast:
+			img, err = imaging.AdjustContrast(img, op.Value)
+		case serviceModel.EditGrayscale:
+			img = imaging.Grayscale(img)
+		default:
+			err = fmt.Errorf("unknown operation %q", op.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", serviceErr.InvalidEditError, i, err)
+		}
+	}
+
+	return img, nil
+}
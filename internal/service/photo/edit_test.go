@@ -0,0 +1,273 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/model"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_EditPhoto(t *testing.T) {
+	const userUUID = "user-id"
+
+	photo := &repoModel.Photo{ID: 1, UserUUID: userUUID, Filename: "a.png"}
+	// Оригинал 40x20 и прежняя отредактированная версия
+	versions := []repoModel.PhotoVersion{
+		{ID: 10, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "original.png", Width: 40, Height: 20},
+		{ID: 11, PhotoID: 1, VersionType: sql.NullString{String: "edited", Valid: true}, UUIDFilename: "old-edited.png", Width: 20, Height: 40},
+	}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name           string
+		userUUID       string
+		ops            []serviceModel.EditOperation
+		mockBehavior   mockBehavior
+		expectedWidth  int
+		expectedHeight int
+		// expectedOldRemoved прежний файл отредактированной версии удален
+		expectedOldRemoved bool
+		expectedErr        error
+	}{
+		{
+			name:     "Rotate and crop",
+			userUUID: userUUID,
+			ops: []serviceModel.EditOperation{
+				{Type: serviceModel.EditRotate, Angle: 90},
+				{Type: serviceModel.EditCrop, X: 0, Y: 5, Width: 20, Height: 10},
+				{Type: serviceModel.EditGrayscale},
+			},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(photo, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+				repo.EXPECT().SavePhotoEdit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, params *repoModel.SavePhotoEditParams) (int, *repoModel.PhotoVersion, error) {
+						assert.Equal(t, 20, params.Width)
+						assert.Equal(t, 10, params.Height)
+						assert.JSONEq(t, `[{"type":"rotate","angle":90},{"type":"crop","y":5,"width":20,"height":10},{"type":"grayscale"}]`,
+							string(params.Operations))
+						assert.Equal(t, ".png", filepath.Ext(params.UUIDFilename))
+						return 12, &versions[1], nil
+					})
+			},
+			expectedWidth:      20,
+			expectedHeight:     10,
+			expectedOldRemoved: true,
+		},
+		{
+			name:     "Crop out of bounds",
+			userUUID: userUUID,
+			ops:      []serviceModel.EditOperation{{Type: serviceModel.EditCrop, X: 30, Y: 0, Width: 20, Height: 10}},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(photo, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+			},
+			expectedErr: serviceErr.InvalidEditError,
+		},
+		{
+			name:         "Invalid operation",
+			userUUID:     userUUID,
+			ops:          []serviceModel.EditOperation{{Type: serviceModel.EditRotate, Angle: 45}},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidEditError,
+		},
+		{
+			name:         "No operations",
+			userUUID:     userUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidEditError,
+		},
+		{
+			name:     "Access denied",
+			userUUID: "other-user",
+			ops:      []serviceModel.EditOperation{{Type: serviceModel.EditGrayscale}},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(photo, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+		{
+			name:     "Photo deleted concurrently",
+			userUUID: userUUID,
+			ops:      []serviceModel.EditOperation{{Type: serviceModel.EditGrayscale}},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(photo, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+				repo.EXPECT().SavePhotoEdit(gomock.Any(), gomock.Any()).Return(0, nil, repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := t.TempDir()
+			userDir := filepath.Join(storage, userUUID)
+			writeTestImage(t, filepath.Join(userDir, "original.png"), 40, 20)
+			writeTestImage(t, filepath.Join(userDir, "old-edited.png"), 20, 40)
+
+			mockRepo := mock_repository.NewMockPhotoRepository(ctrl)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+			edit, err := s.EditPhoto(context.Background(), tt.userUUID, 1, tt.ops)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				// Несохраненный файл не остается в хранилище
+				entries, err := os.ReadDir(userDir)
+				require.NoError(t, err)
+				assert.Len(t, entries, 2)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 12, edit.Version.ID)
+			assert.Equal(t, model.Edited, edit.Version.VersionType)
+			assert.Equal(t, tt.expectedWidth, edit.Version.Width)
+			assert.Equal(t, tt.expectedHeight, edit.Version.Height)
+			assert.Equal(t, tt.ops, edit.Operations)
+
+			img, err := decodeImageFile(filepath.Join(userDir, edit.Version.UUIDFilename))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedWidth, img.Bounds().Dx())
+			assert.Equal(t, tt.expectedHeight, img.Bounds().Dy())
+
+			// Оригинал не меняется
+			original, err := decodeImageFile(filepath.Join(userDir, "original.png"))
+			require.NoError(t, err)
+			assert.Equal(t, 40, original.Bounds().Dx())
+
+			_, err = os.Stat(filepath.Join(userDir, "old-edited.png"))
+			assert.Equal(t, tt.expectedOldRemoved, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestService_ReapplyPhotoEdit(t *testing.T) {
+	const userUUID = "user-id"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := t.TempDir()
+	writeTestImage(t, filepath.Join(storage, userUUID, "original.png"), 40, 20)
+
+	photo := &repoModel.Photo{ID: 1, UserUUID: userUUID}
+	versions := []repoModel.PhotoVersion{
+		{ID: 10, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "original.png"},
+	}
+
+	mockRepo := mock_repository.NewMockPhotoRepository(ctrl)
+	mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(photo, nil)
+	mockRepo.EXPECT().GetPhotoEdit(gomock.Any(), 1).
+		Return(&repoModel.PhotoEdit{PhotoID: 1, VersionID: 11, Operations: []byte(`[{"type":"rotate","angle":-90}]`)}, nil)
+	mockRepo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+	mockRepo.EXPECT().SavePhotoEdit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, params *repoModel.SavePhotoEditParams) (int, *repoModel.PhotoVersion, error) {
+			assert.Equal(t, 20, params.Width)
+			assert.Equal(t, 40, params.Height)
+			return 12, nil, nil
+		})
+
+	s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+	edit, err := s.ReapplyPhotoEdit(context.Background(), userUUID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []serviceModel.EditOperation{{Type: serviceModel.EditRotate, Angle: -90}}, edit.Operations)
+	assert.Equal(t, 12, edit.Version.ID)
+}
+
+func TestService_RevertPhotoEdit(t *testing.T) {
+	const userUUID = "user-id"
+
+	tests := []struct {
+		name        string
+		deleteErr   error
+		expectedErr error
+	}{
+		{name: "Reverted"},
+		{name: "Not edited", deleteErr: repoErr.NotFoundError, expectedErr: serviceErr.PhotoNotFoundError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := t.TempDir()
+			path := filepath.Join(storage, userUUID, "edited.png")
+			writeTestImage(t, path, 10, 10)
+
+			var version *repoModel.PhotoVersion
+			if tt.deleteErr == nil {
+				version = &repoModel.PhotoVersion{ID: 11, PhotoID: 1, UUIDFilename: "edited.png"}
+			}
+
+			mockRepo := mock_repository.NewMockPhotoRepository(ctrl)
+			mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+			mockRepo.EXPECT().DeletePhotoEdit(gomock.Any(), 1).Return(version, tt.deleteErr)
+
+			s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+			err := s.RevertPhotoEdit(context.Background(), userUUID, 1)
+			_, statErr := os.Stat(path)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.NoError(t, statErr)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, os.IsNotExist(statErr))
+			}
+		})
+	}
+}
+
+func TestService_GetPhotoEdit(t *testing.T) {
+	const userUUID = "user-id"
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(ctrl)
+	mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil).Times(2)
+	mockRepo.EXPECT().GetPhotoEdit(gomock.Any(), 1).
+		Return(&repoModel.PhotoEdit{PhotoID: 1, VersionID: 11, Operations: []byte(`[{"type":"grayscale"}]`), UpdatedAt: updatedAt}, nil)
+	mockRepo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return([]repoModel.PhotoVersion{
+		{ID: 10, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "original.png"},
+		{
+			ID: 11, PhotoID: 1, VersionType: sql.NullString{String: "edited", Valid: true}, UUIDFilename: "edited.png",
+			SavedAt: &sql.NullTime{Time: updatedAt, Valid: true},
+		},
+	}, nil)
+	mockRepo.EXPECT().GetPhotoEdit(gomock.Any(), 1).Return(nil, repoErr.NotFoundError)
+
+	s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+	edit, err := s.GetPhotoEdit(context.Background(), userUUID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []serviceModel.EditOperation{{Type: serviceModel.EditGrayscale}}, edit.Operations)
+	assert.Equal(t, 11, edit.Version.ID)
+	assert.Equal(t, model.Edited, edit.Version.VersionType)
+	assert.Equal(t, updatedAt, edit.UpdatedAt)
+	assert.Equal(t, updatedAt, edit.Version.SavedAt)
+
+	_, err = s.GetPhotoEdit(context.Background(), userUUID, 1)
+	assert.ErrorIs(t, err, serviceErr.PhotoNotFoundError)
+}
@@ -1,14 +1,15 @@
 package photo
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	log "github.com/sirupsen/logrus"
 	repoErr "go-photo/internal/repository/error"
 	serviceErr "go-photo/internal/service/error"
+	"go-photo/pkg/logger"
 )
 
-func (s *service) HandleRepoErr(err error) error {
+func (s *service) HandleRepoErr(ctx context.Context, err error) error {
 	if errors.Is(err, repoErr.NotFoundError) {
 		return fmt.Errorf("%w: %v", serviceErr.PhotoNotFoundError, err)
 	}
@@ -16,7 +17,7 @@ func (s *service) HandleRepoErr(err error) error {
 		return fmt.Errorf("%w: %v", serviceErr.AlreadyExists, err)
 	}
 	if err != nil {
-		log.Errorf("%v: %v", serviceErr.UnexpectedError, err)
+		logger.FromContext(ctx).Errorf("%v: %v", serviceErr.UnexpectedError, err)
 		return fmt.Errorf("%w: %v", serviceErr.UnexpectedError, err)
 	}
 
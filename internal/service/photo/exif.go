@@ -0,0 +1,124 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/model"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/imaging"
+	"go-photo/pkg/logger"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// exifBatchSize количество версий, EXIF которых читается за один запрос к БД
+const exifBatchSize = 100
+
+// fileExif сведения о съемке из EXIF файла.
+type fileExif struct {
+	// location координаты съемки, nil - их нет
+	location *model.Location
+	// takenAt местное время съемки, нулевое - его нет
+	takenAt time.Time
+	// camera производитель и модель камеры, пустые - их нет
+	camera model.Camera
+}
+
+func (s *service) BackfillExif(ctx context.Context) error {
+	var errs []error
+	for {
+		versions, err := s.photoRepository.GetVersionsWithoutExif(ctx, exifBatchSize)
+		if err != nil {
+			return errors.Join(append(errs, fmt.Errorf("failed to get versions without exif: %w", err))...)
+		}
+
+		saved := 0
+		for _, v := range versions {
+			if err := s.backfillExif(ctx, v); err != nil {
+				errs = append(errs, fmt.Errorf("photo version %d: %w", v.ID, err))
+				continue
+			}
+			saved++
+		}
+
+		if saved > 0 {
+			logger.FromContext(ctx).Infof("Read EXIF of %d photo versions", saved)
+		}
+
+		// Необработанные версии вернутся в следующей выборке, поэтому без прогресса останавливаемся
+		if len(versions) < exifBatchSize || saved == 0 {
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// backfillExif читает и сохраняет координаты, время съемки и камеру версии.
+// Ошибки чтения файла возвращаются: версия будет обработана на следующем запуске.
+func (s *service) backfillExif(ctx context.Context, v repoModel.PhotoVersionWithOwner) error {
+	exif, err := exifOfFile(filepath.Join(s.d.StorageFolderPath, v.UserUUID.String, v.UUIDFilename))
+	if err != nil {
+		return err
+	}
+
+	return s.photoRepository.SavePhotoVersionExif(ctx, v.ID, repoModel.PhotoVersionExif{
+		Location:    toRepoGeoPoint(exif.location),
+		TakenAt:     toRepoTakenAt(exif.takenAt),
+		CameraMake:  exif.camera.Make,
+		CameraModel: exif.camera.Model,
+	})
+}
+
+// exifOfFile возвращает координаты, время съемки и камеру из EXIF файла path.
+// Файлы без EXIF и с неразобранным EXIF считаются файлами без сведений о съемке.
+func exifOfFile(path string) (fileExif, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileExif{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var res fileExif
+	location, err := imaging.ReadLocation(data)
+	if err = ignoreUnreadableExif(err); err != nil {
+		return fileExif{}, err
+	}
+	if location != nil {
+		res.location = &model.Location{Latitude: location.Latitude, Longitude: location.Longitude}
+	}
+
+	takenAt, err := imaging.ReadTakenAt(data)
+	if err = ignoreUnreadableExif(err); err != nil {
+		return fileExif{}, err
+	}
+	if takenAt != nil {
+		res.takenAt = *takenAt
+	}
+
+	camera, err := imaging.ReadCamera(data)
+	if err = ignoreUnreadableExif(err); err != nil {
+		return fileExif{}, err
+	}
+	if camera != nil {
+		res.camera = model.Camera{Make: camera.Make, Model: camera.Model}
+	}
+
+	return res, nil
+}
+
+// ignoreUnreadableExif пропускает ошибки форматов без EXIF и неразобранного EXIF.
+func ignoreUnreadableExif(err error) error {
+	if errors.Is(err, imaging.UnsupportedFormatError) || errors.Is(err, imaging.MalformedImageError) {
+		return nil
+	}
+	return err
+}
+
+func toRepoTakenAt(takenAt time.Time) *time.Time {
+	if takenAt.IsZero() {
+		return nil
+	}
+	return &takenAt
+}
@@ -0,0 +1,285 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	"image"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCameraModel модель камеры, которую jpegWithExif записывает в IFD0
+const testCameraModel = "Pixel 8"
+
+// jpegWithExif JPEG с EXIF, в котором записаны модель камеры, время съемки в формате EXIF
+// и координаты в северном и восточном полушариях.
+func jpegWithExif(t *testing.T, latitude, longitude float64, takenAt string) []byte {
+	t.Helper()
+	require.Len(t, takenAt, 19)
+
+	// TIFF: заголовок, IFD0 с моделью камеры и ссылками на Exif IFD и GPS IFD, Exif IFD с датой съемки,
+	// GPS IFD с четырьмя тегами, значения координат, дата съемки, модель камеры
+	const exifOffset = 8 + 2 + 3*12 + 4
+	const gpsOffset = exifOffset + 2 + 12 + 4
+	const valuesOffset = gpsOffset + 2 + 4*12 + 4
+	const dateOffset = valuesOffset + 2*24
+	const modelOffset = dateOffset + 20
+
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x03\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0110)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = binary.LittleEndian.AppendUint32(tiff, uint32(len(testCameraModel)+1))
+	tiff = binary.LittleEndian.AppendUint32(tiff, modelOffset)
+	for _, pointer := range [][2]uint32{{0x8769, exifOffset}, {0x8825, gpsOffset}} {
+		tiff = binary.LittleEndian.AppendUint16(tiff, uint16(pointer[0]))
+		tiff = binary.LittleEndian.AppendUint16(tiff, 4)
+		tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+		tiff = binary.LittleEndian.AppendUint32(tiff, pointer[1])
+	}
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x9003)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 20)
+	tiff = binary.LittleEndian.AppendUint32(tiff, dateOffset)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+
+	tiff = binary.LittleEndian.AppendUint16(tiff, 4)
+	for i, ref := range []string{"N", "E"} {
+		tiff = binary.LittleEndian.AppendUint16(tiff, uint16(2*i+1))
+		tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+		tiff = binary.LittleEndian.AppendUint32(tiff, 2)
+		tiff = append(tiff, ref[0], 0, 0, 0)
+
+		tiff = binary.LittleEndian.AppendUint16(tiff, uint16(2*i+2))
+		tiff = binary.LittleEndian.AppendUint16(tiff, 5)
+		tiff = binary.LittleEndian.AppendUint32(tiff, 3)
+		tiff = binary.LittleEndian.AppendUint32(tiff, uint32(valuesOffset+i*24))
+	}
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+
+	for _, degrees := range []float64{latitude, longitude} {
+		tiff = binary.LittleEndian.AppendUint32(tiff, uint32(degrees*1e6))
+		tiff = binary.LittleEndian.AppendUint32(tiff, 1e6)
+		tiff = append(tiff, "\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00"...)
+	}
+	tiff = append(append(tiff, takenAt...), 0)
+	tiff = append(append(tiff, testCameraModel...), 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 16)), nil))
+	encoded := buf.Bytes()
+
+	return append(append(append([]byte{}, encoded[:2]...), segment...), encoded[2:]...)
+}
+
+// jpegWithLocation JPEG с EXIF, в GPS IFD которого записаны координаты в северном и восточном полушариях.
+func jpegWithLocation(t *testing.T, latitude, longitude float64) []byte {
+	t.Helper()
+
+	// TIFF: заголовок, IFD0 со ссылкой на GPS IFD, GPS IFD с четырьмя тегами, значения координат
+	const gpsOffset, valuesOffset = 26, 26 + 2 + 4*12 + 4
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x8825)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 4)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint32(tiff, gpsOffset)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+
+	tiff = binary.LittleEndian.AppendUint16(tiff, 4)
+	for i, ref := range []string{"N", "E"} {
+		tiff = binary.LittleEndian.AppendUint16(tiff, uint16(2*i+1))
+		tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+		tiff = binary.LittleEndian.AppendUint32(tiff, 2)
+		tiff = append(tiff, ref[0], 0, 0, 0)
+
+		tiff = binary.LittleEndian.AppendUint16(tiff, uint16(2*i+2))
+		tiff = binary.LittleEndian.AppendUint16(tiff, 5)
+		tiff = binary.LittleEndian.AppendUint32(tiff, 3)
+		tiff = binary.LittleEndian.AppendUint32(tiff, uint32(valuesOffset+i*24))
+	}
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+
+	for _, degrees := range []float64{latitude, longitude} {
+		tiff = binary.LittleEndian.AppendUint32(tiff, uint32(degrees*1e6))
+		tiff = binary.LittleEndian.AppendUint32(tiff, 1e6)
+		tiff = append(tiff, "\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00"...)
+	}
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 16)), nil))
+	encoded := buf.Bytes()
+
+	return append(append(append([]byte{}, encoded[:2]...), segment...), encoded[2:]...)
+}
+
+func fileHeaderOf(t *testing.T, filename string, data []byte) *multipart.FileHeader {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "/", body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(10<<20))
+
+	return req.MultipartForm.File["file"][0]
+}
+
+func TestService_UploadPhoto_Exif(t *testing.T) {
+	takenAt := time.Date(2024, 7, 14, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		data             func(t *testing.T) []byte
+		expectedLocation *repoModel.GeoPoint
+		expectedTakenAt  *time.Time
+		expectedModel    string
+	}{
+		{
+			name:             "With EXIF",
+			data:             func(t *testing.T) []byte { return jpegWithExif(t, 55.75, 37.61, "2024:07:14 18:30:00") },
+			expectedLocation: &repoModel.GeoPoint{Latitude: 55.75, Longitude: 37.61},
+			expectedTakenAt:  &takenAt,
+			expectedModel:    testCameraModel,
+		},
+		{
+			name:             "With GPS",
+			data:             func(t *testing.T) []byte { return jpegWithLocation(t, 55.75, 37.61) },
+			expectedLocation: &repoModel.GeoPoint{Latitude: 55.75, Longitude: 37.61},
+		},
+		{
+			name: "Without EXIF",
+			data: func(t *testing.T) []byte { return whitePNG(t, 4, 4) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			mockRepo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(1, nil)
+			mockRepo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, params *repoModel.CreateOriginalPhotoParams) (int, error) {
+					assert.False(t, params.ExifUnknown)
+					assert.Equal(t, tt.expectedTakenAt, params.TakenAt)
+					assert.Empty(t, params.CameraMake)
+					assert.Equal(t, tt.expectedModel, params.CameraModel)
+					if tt.expectedLocation == nil {
+						assert.Nil(t, params.Location)
+						return 1, nil
+					}
+					require.NotNil(t, params.Location)
+					assert.InDelta(t, tt.expectedLocation.Latitude, params.Location.Latitude, 1e-6)
+					assert.InDelta(t, tt.expectedLocation.Longitude, params.Location.Longitude, 1e-6)
+					return 1, nil
+				})
+			mockRepo.EXPECT().DeletePendingUpload(gomock.Any(), 1).Return(nil)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			_, err := s.UploadPhoto(context.Background(), "user-id", fileHeaderOf(t, "photo.jpg", tt.data(t)))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_BackfillExif(t *testing.T) {
+	const userUUID = "user-id"
+
+	storage := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(storage, userUUID), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(storage, userUUID, "gps.jpg"), jpegWithExif(t, 59.93, 30.33, "2019:06:30 18:45:12"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(storage, userUUID, "gps-only.jpg"), jpegWithLocation(t, 55.75, 37.61), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(storage, userUUID, "drawing.svg"), []byte("<svg/>"), 0644))
+
+	version := func(id int, filename string) repoModel.PhotoVersionWithOwner {
+		return repoModel.PhotoVersionWithOwner{
+			PhotoVersion: repoModel.PhotoVersion{ID: id, PhotoID: id, UUIDFilename: filename},
+			UserUUID:     sql.NullString{String: userUUID, Valid: true},
+		}
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetVersionsWithoutExif(gomock.Any(), exifBatchSize).Return([]repoModel.PhotoVersionWithOwner{
+		version(1, "gps.jpg"),
+		version(2, "drawing.svg"),
+		version(3, "missing.jpg"),
+		version(4, "gps-only.jpg"),
+	}, nil)
+	mockRepo.EXPECT().SavePhotoVersionExif(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, exif repoModel.PhotoVersionExif) error {
+			require.NotNil(t, exif.Location)
+			assert.InDelta(t, 59.93, exif.Location.Latitude, 1e-6)
+			assert.InDelta(t, 30.33, exif.Location.Longitude, 1e-6)
+			require.NotNil(t, exif.TakenAt)
+			assert.Equal(t, time.Date(2019, 6, 30, 18, 45, 12, 0, time.UTC), *exif.TakenAt)
+			assert.Equal(t, testCameraModel, exif.CameraModel)
+			return nil
+		})
+	// Файл без EXIF помечается как файл без сведений о съемке
+	mockRepo.EXPECT().SavePhotoVersionExif(gomock.Any(), 2, repoModel.PhotoVersionExif{}).Return(nil)
+	mockRepo.EXPECT().SavePhotoVersionExif(gomock.Any(), 4, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, exif repoModel.PhotoVersionExif) error {
+			require.NotNil(t, exif.Location)
+			assert.InDelta(t, 55.75, exif.Location.Latitude, 1e-6)
+			assert.InDelta(t, 37.61, exif.Location.Longitude, 1e-6)
+			assert.Nil(t, exif.TakenAt)
+			assert.Empty(t, exif.CameraModel)
+			return nil
+		})
+
+	s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+	err := s.BackfillExif(context.Background())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "photo version 3")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestService_BackfillExif_RepoError(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetVersionsWithoutExif(gomock.Any(), exifBatchSize).Return(nil, errors.New("db error"))
+
+	s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+	assert.ErrorContains(t, s.BackfillExif(context.Background()), "db error")
+}
@@ -3,27 +3,21 @@ package photo
 import (
 	"context"
 	"fmt"
-	log "github.com/sirupsen/logrus"
 	"go-photo/internal/model"
 	"go-photo/internal/repository/photo/converter"
 	repoModel "go-photo/internal/repository/photo/model"
 	serviceErr "go-photo/internal/service/error"
-	"os"
-	"path/filepath"
+	serviceModel "go-photo/internal/service/photo/model"
 )
 
 func (s *service) GetPhotoVersions(ctx context.Context, userUUID string, photoID int) ([]model.PhotoVersion, error) {
-	photo, err := s.photoRepository.GetPhotoByID(ctx, photoID)
-	if err := s.HandleRepoErr(err); err != nil {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionView)
+	if err != nil {
 		return nil, err
 	}
 
-	if photo.UserUUID != userUUID {
-		return nil, serviceErr.AccessDeniedError
-	}
-
-	repoVersions, err := s.photoRepository.GetPhotoVersions(ctx, photoID)
-	if err := s.HandleRepoErr(err); err != nil {
+	repoVersions, err := s.photoRepository.GetPhotoVersions(ctx, photo.ID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
 		return nil, err
 	}
 
@@ -32,7 +26,7 @@ func (s *service) GetPhotoVersions(ctx context.Context, userUUID string, photoID
 	return versions, nil
 }
 
-func (s *service) GetPhotoFileByVersionAndToken(ctx context.Context, token string, version string) ([]byte, error) {
+func (s *service) GetPhotoFileByVersionAndToken(ctx context.Context, token string, version string, opts serviceModel.FileOptions) (*serviceModel.PhotoFile, error) {
 	versionType, err := model.ParseVersionType(version)
 	if err != nil {
 		return nil, serviceErr.InvalidVersionTypeError
@@ -41,44 +35,52 @@ func (s *service) GetPhotoFileByVersionAndToken(ctx context.Context, token strin
 	photoVersion, err := s.photoRepository.GetPhotoVersionByToken(ctx, token, &repoModel.FilterParams{
 		VersionType: versionType,
 	})
-	if err := s.HandleRepoErr(err); err != nil {
+	if err := s.HandleRepoErr(ctx, err); err != nil {
 		return nil, err
 	}
 
 	photo, err := s.photoRepository.GetPhotoByID(ctx, photoVersion.PhotoID)
-	if err := s.HandleRepoErr(err); err != nil {
+	if err := s.HandleRepoErr(ctx, err); err != nil {
 		return nil, err
 	}
 
-	photoFilepath := filepath.Join(s.d.StorageFolderPath, photo.UserUUID, photoVersion.UUIDFilename)
-	log.Printf("%s", photoFilepath)
-	file, err := os.Open(photoFilepath)
+	mark, err := s.publicWatermark(ctx, token)
 	if err != nil {
-		return nil, fmt.Errorf("%w: failed to open file: %v", serviceErr.UnexpectedError, err)
+		return nil, err
+	}
+	if mark != nil {
+		return s.watermarkedVersionFile(ctx, token, photo.UserUUID, photoVersion, mark, opts)
 	}
-	defer file.Close()
 
-	buff := make([]byte, photoVersion.Size)
-	_, err = file.Read(buff)
-	if err != nil {
-		return nil, fmt.Errorf("%w: failed to read file: %v", serviceErr.UnexpectedError, err)
+	policy, err := s.photoRepository.GetMetadataPolicyByToken(ctx, token)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
 	}
 
-	return buff, nil
+	return s.publicVersionFile(ctx, photo.UserUUID, photoVersion, serviceModel.MetadataPolicy(policy), opts)
 }
 
-// getUserPhoto возвращает фотографию пользователя по ее ID.
-// Если фотография не найдена, возвращает ошибку PhotoNotFoundError.
-// Если фотография найдена, но принадлежит другому пользователю, возвращает ошибку AccessDeniedError.
-func (s *service) getUserPhoto(ctx context.Context, userUUID string, photoID int) (*repoModel.Photo, error) {
-	photo, err := s.photoRepository.GetPhotoByID(ctx, photoID)
-	if err := s.HandleRepoErr(err); err != nil {
-		return &repoModel.Photo{}, err
+func (s *service) GetPhotoFile(ctx context.Context, userUUID string, photoID int, version string, opts serviceModel.FileOptions) (*serviceModel.PhotoFile, error) {
+	versionType, err := model.ParseVersionType(version)
+	if err != nil {
+		return nil, serviceErr.InvalidVersionTypeError
+	}
+
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionView)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.photoRepository.GetPhotoVersions(ctx, photo.ID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
 	}
 
-	if photo.UserUUID != userUUID {
-		return &repoModel.Photo{}, serviceErr.AccessDeniedError
+	for i := range versions {
+		if versions[i].VersionType.String == string(versionType) {
+			return s.versionFile(ctx, photo.UserUUID, &versions[i], opts)
+		}
 	}
 
-	return photo, nil
+	return nil, fmt.Errorf("%w: photo %d has no %s version", serviceErr.PhotoNotFoundError, photo.ID, versionType)
 }
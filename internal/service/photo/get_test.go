@@ -8,9 +8,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go-photo/internal/model"
+	repoErr "go-photo/internal/repository/error"
 	mock_repository "go-photo/internal/repository/mock"
 	repoModel "go-photo/internal/repository/photo/model"
 	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
 	"os"
 	"path/filepath"
 	"testing"
@@ -186,15 +188,19 @@ func TestPhotoService_GetPhotoFileByVersionAndToken(t *testing.T) {
 
 			mockRepo := mock_repository.NewMockPhotoRepository(ctrl)
 			tt.mockBehavior(mockRepo, tt.inputToken, tt.inputVersion)
+			// Фото опубликовано без водяного знака, файлы отдаются как есть
+			mockRepo.EXPECT().GetWatermarkByToken(gomock.Any(), tt.inputToken).Return(nil, repoErr.NotFoundError).AnyTimes()
+			mockRepo.EXPECT().GetMetadataPolicyByToken(gomock.Any(), tt.inputToken).Return("keep", nil).AnyTimes()
 
 			s := NewService(Deps{StorageFolderPath: tmpDir}, mockRepo, nil)
 
-			bytes, err := s.GetPhotoFileByVersionAndToken(context.TODO(), tt.inputToken, tt.inputVersion)
+			file, err := s.GetPhotoFileByVersionAndToken(context.TODO(), tt.inputToken, tt.inputVersion, serviceModel.FileOptions{})
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedBytes, bytes)
+				assert.Equal(t, tt.expectedBytes, file.Data)
+				assert.Equal(t, "image/png", file.ContentType)
 			}
 		})
 	}
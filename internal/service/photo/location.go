@@ -0,0 +1,46 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	"go-photo/internal/repository/photo/converter"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"math"
+)
+
+func (s *service) GetPhotoClusters(ctx context.Context, userUUID string, filter serviceModel.ClusterFilter) ([]model.PhotoCluster, error) {
+	if err := filter.BBox.Validate(); err != nil {
+		return nil, err
+	}
+	if filter.Zoom < 0 || filter.Zoom > config.MaxMapZoom {
+		return nil, fmt.Errorf("%w: zoom must be between 0 and %d", serviceErr.InvalidFilterError, config.MaxMapZoom)
+	}
+
+	// Тайл на уровне масштаба zoom занимает 360/2^zoom градусов долготы
+	cellSize := 360 / math.Exp2(float64(filter.Zoom)) / config.MapClusterCellsPerTile
+
+	clusters, err := s.photoRepository.GetUserPhotoClusters(ctx, userUUID, &repoModel.PhotoClusterParams{
+		BBox:     toRepoBBox(filter.BBox),
+		CellSize: cellSize,
+	})
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return converter.ToPhotoClustersFromRepo(clusters), nil
+}
+
+func toRepoGeoPoint(location *model.Location) *repoModel.GeoPoint {
+	if location == nil {
+		return nil
+	}
+	return &repoModel.GeoPoint{Latitude: location.Latitude, Longitude: location.Longitude}
+}
+
+func toRepoBBox(bbox serviceModel.BBox) repoModel.BBox {
+	return repoModel.BBox{West: bbox.West, South: bbox.South, East: bbox.East, North: bbox.North}
+}
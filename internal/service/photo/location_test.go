@@ -0,0 +1,87 @@
+package photo
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"testing"
+)
+
+func TestService_GetPhotoClusters(t *testing.T) {
+	const userUUID = "user-id"
+	moscow := serviceModel.BBox{West: 37, South: 55, East: 38, North: 56}
+
+	tests := []struct {
+		name             string
+		filter           serviceModel.ClusterFilter
+		expectedCellSize float64
+		expectedErr      error
+	}{
+		{
+			name:             "World",
+			filter:           serviceModel.ClusterFilter{BBox: serviceModel.BBox{West: -180, South: -90, East: 180, North: 90}},
+			expectedCellSize: 90,
+		},
+		{
+			name:             "City",
+			filter:           serviceModel.ClusterFilter{BBox: moscow, Zoom: 10},
+			expectedCellSize: 360.0 / 1024 / 4,
+		},
+		{
+			name:             "Across antimeridian",
+			filter:           serviceModel.ClusterFilter{BBox: serviceModel.BBox{West: 170, South: -50, East: -170, North: -30}, Zoom: 3},
+			expectedCellSize: 11.25,
+		},
+		{
+			name:        "Zoom too large",
+			filter:      serviceModel.ClusterFilter{BBox: moscow, Zoom: config.MaxMapZoom + 1},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Latitude out of range",
+			filter:      serviceModel.ClusterFilter{BBox: serviceModel.BBox{West: 0, South: -91, East: 10, North: 10}},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "South above north",
+			filter:      serviceModel.ClusterFilter{BBox: serviceModel.BBox{West: 0, South: 20, East: 10, North: 10}},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			if tt.expectedErr == nil {
+				b := tt.filter.BBox
+				mockRepo.EXPECT().GetUserPhotoClusters(gomock.Any(), userUUID, &repoModel.PhotoClusterParams{
+					BBox:     repoModel.BBox{West: b.West, South: b.South, East: b.East, North: b.North},
+					CellSize: tt.expectedCellSize,
+				}).Return([]repoModel.PhotoCluster{{Count: 3, Latitude: 55.7, Longitude: 37.6, PhotoID: 5}}, nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			clusters, err := s.GetPhotoClusters(context.Background(), userUUID, tt.filter)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []model.PhotoCluster{
+				{Location: model.Location{Latitude: 55.7, Longitude: 37.6}, Count: 3, PhotoID: 5},
+			}, clusters)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	"time"
+)
+
+// albumDateLayout формат дней съемки в фильтрах альбома
+const albumDateLayout = time.DateOnly
+
+// AlbumFilter фильтры умного альбома, хранятся в JSON. Поля соответствуют параметрам списка фото,
+// отсутствующее поле не применяется. Пагинация задается при чтении альбома.
+type AlbumFilter struct {
+	Favorite *bool `json:"favorite,omitempty"`
+	// Hidden true, false или any. Пустая строка - скрытые фото в альбом не попадают
+	Hidden    string `json:"hidden,omitempty"`
+	MinRating *int   `json:"min_rating,omitempty"`
+	MaxRating *int   `json:"max_rating,omitempty"`
+	// TakenFrom и TakenTo первый и последний день съемки в формате YYYY-MM-DD
+	TakenFrom string `json:"taken_from,omitempty"`
+	TakenTo   string `json:"taken_to,omitempty"`
+	// TimeZone часовой пояс IANA для дней съемки фото без EXIF, пустая строка - UTC
+	TimeZone  string `json:"tz,omitempty"`
+	Camera    string `json:"camera,omitempty"`
+	Published *bool  `json:"published,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	Color     string `json:"color,omitempty"`
+	Tolerance int    `json:"tolerance,omitempty"`
+	BBox      *BBox  `json:"bbox,omitempty"`
+}
+
+// ParseAlbumFilter разбирает фильтры альбома из JSON. Неизвестные поля и значения неверного типа -
+// ошибка InvalidAlbumError.
+func ParseAlbumFilter(data []byte) (AlbumFilter, error) {
+	var f AlbumFilter
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return AlbumFilter{}, fmt.Errorf("%w: filter must be a JSON object with known fields: %v", serviceErr.InvalidAlbumError, err)
+	}
+	return f, nil
+}
+
+// PhotoFilter переводит фильтры альбома в фильтры списка фото без пагинации.
+// Значения проверяются только на формат, допустимость проверяется вместе с остальными параметрами списка.
+func (f AlbumFilter) PhotoFilter() (PhotoFilter, error) {
+	res := PhotoFilter{
+		Favorite:       f.Favorite,
+		TimeZone:       f.TimeZone,
+		MinRating:      f.MinRating,
+		MaxRating:      f.MaxRating,
+		Camera:         f.Camera,
+		Published:      f.Published,
+		Tag:            f.Tag,
+		Color:          f.Color,
+		ColorTolerance: f.Tolerance,
+		BBox:           f.BBox,
+	}
+
+	switch f.Hidden {
+	case "", "false":
+		hidden := false
+		res.Hidden = &hidden
+	case "true":
+		hidden := true
+		res.Hidden = &hidden
+	case "any":
+	default:
+		return PhotoFilter{}, fmt.Errorf("%w: hidden must be true, false or any", serviceErr.InvalidAlbumError)
+	}
+
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   **time.Time
+	}{
+		{"taken_from", f.TakenFrom, &res.TakenFrom},
+		{"taken_to", f.TakenTo, &res.TakenTo},
+	} {
+		if d.value == "" {
+			continue
+		}
+		t, err := time.Parse(albumDateLayout, d.value)
+		if err != nil {
+			return PhotoFilter{}, fmt.Errorf("%w: %s must be in YYYY-MM-DD format", serviceErr.InvalidAlbumError, d.name)
+		}
+		*d.dst = &t
+	}
+
+	return res, nil
+}
+
+// IsEmpty сообщает, что в фильтрах не задан ни один критерий отбора фото. Hidden и TimeZone
+// только уточняют другие критерии.
+func (f AlbumFilter) IsEmpty() bool {
+	f.Hidden, f.TimeZone = "", ""
+	return f == AlbumFilter{}
+}
+
+// SaveAlbumParams название, фильтры и режим нового или изменяемого альбома.
+type SaveAlbumParams struct {
+	Title string
+	// Filter фильтры альбома в JSON (AlbumFilter), пустой - все фото, кроме скрытых
+	Filter []byte
+	// Manual ручной альбом из добавленных фото. Фото владельца попадают в него, только если
+	// в Filter задан хотя бы один критерий
+	Manual bool
+}
+
+// Album альбом, фото владельца которого отбираются по фильтрам при каждом чтении. В альбом также
+// попадают добавленные фото, а ручной альбом без критериев в фильтрах состоит только из них.
+type Album struct {
+	ID     int
+	Title  string
+	Filter AlbumFilter
+	Manual bool
+	// PublicToken токен публичной ссылки, пустой, если альбом не опубликован
+	PublicToken string
+	// MetadataPolicy политика метаданных файлов опубликованного альбома
+	MetadataPolicy MetadataPolicy
+	// Watermark файлы опубликованного альбома отдаются с водяным знаком владельца
+	Watermark bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PublicAlbum опубликованный альбом со страницей фото.
+type PublicAlbum struct {
+	Title  string
+	Photos []model.Photo
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxTitleLength   = 255
+	MaxCaptionLength = 2000
+	MaxRating        = 5
+	// MaxCameraFilterLength максимальная длина фильтра по камере
+	MaxCameraFilterLength = 100
+)
+
+// PhotoAttributes редактируемые пользователем атрибуты фото.
+// JSON представление - документ, к которому применяется JSON Merge Patch.
+type PhotoAttributes struct {
+	Title    string `json:"title,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+	Favorite bool   `json:"favorite,omitempty"`
+	Rating   int    `json:"rating,omitempty"`
+	Hidden   bool   `json:"hidden,omitempty"`
+}
+
+func ToPhotoAttributes(photo *model.Photo) PhotoAttributes {
+	return PhotoAttributes{
+		Title:    photo.Title,
+		Caption:  photo.Caption,
+		Favorite: photo.Favorite,
+		Rating:   photo.Rating,
+		Hidden:   photo.Hidden,
+	}
+}
+
+// ApplyMergePatch применяет к атрибутам изменение по RFC 7396: отсутствующие поля не меняются,
+// null сбрасывает поле в значение по умолчанию, остальные значения заменяют текущие.
+// Неизвестные поля и значения неверного типа - ошибка InvalidPatchError.
+func (a PhotoAttributes) ApplyMergePatch(patch []byte) (PhotoAttributes, error) {
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &changes); err != nil || changes == nil {
+		return PhotoAttributes{}, fmt.Errorf("%w: patch must be a JSON object", serviceErr.InvalidPatchError)
+	}
+
+	current, err := json.Marshal(a)
+	if err != nil {
+		return PhotoAttributes{}, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(current, &doc); err != nil {
+		return PhotoAttributes{}, err
+	}
+
+	for field, value := range changes {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			delete(doc, field)
+			continue
+		}
+		doc[field] = value
+	}
+
+	merged, err := json.Marshal(doc)
+	if err != nil {
+		return PhotoAttributes{}, err
+	}
+
+	var res PhotoAttributes
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&res); err != nil {
+		return PhotoAttributes{}, fmt.Errorf("%w: %v", serviceErr.InvalidPatchError, err)
+	}
+
+	return res, res.Validate()
+}
+
+func (a PhotoAttributes) Validate() error {
+	if utf8.RuneCountInString(a.Title) > MaxTitleLength {
+		return fmt.Errorf("%w: title is longer than %d characters", serviceErr.InvalidPatchError, MaxTitleLength)
+	}
+	if utf8.RuneCountInString(a.Caption) > MaxCaptionLength {
+		return fmt.Errorf("%w: caption is longer than %d characters", serviceErr.InvalidPatchError, MaxCaptionLength)
+	}
+	if a.Rating < 0 || a.Rating > MaxRating {
+		return fmt.Errorf("%w: rating must be between 0 and %d", serviceErr.InvalidPatchError, MaxRating)
+	}
+
+	return nil
+}
+
+// PhotoFilter фильтры и пагинация списка фото. nil фильтр не применяется.
+type PhotoFilter struct {
+	Favorite  *bool
+	Hidden    *bool
+	MinRating *int
+	MaxRating *int
+	// Color цвет #rrggbb, близкий к одному из преобладающих цветов фото, пустая строка - без фильтра
+	Color string
+	// ColorTolerance допустимое отличие от Color, 0 - значение по умолчанию
+	ColorTolerance int
+	// BBox оставляет фото, снятые внутри прямоугольника
+	BBox *BBox
+	// TakenFrom и TakenTo первый и последний день съемки включительно, время суток не учитывается.
+	// Фото без времени съемки в EXIF отбираются по дню загрузки
+	TakenFrom *time.Time
+	TakenTo   *time.Time
+	// TimeZone часовой пояс IANA, в котором время загрузки фото без EXIF сравнивается с днями съемки,
+	// пустая строка - UTC
+	TimeZone string
+	// Camera часть названия производителя или модели камеры, пустая строка - без фильтра
+	Camera string
+	// Published оставляет опубликованные (true) или неопубликованные (false) фото
+	Published *bool
+	// Tag оставляет фото с этим тегом, пустая строка - без фильтра
+	Tag string
+	// Limit количество фото на странице, 0 - значение по умолчанию
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+	serviceErr "go-photo/internal/service/error"
+)
+
+// BulkAction действие над группой фото.
+type BulkAction string
+
+const (
+	BulkPublish    BulkAction = "publish"
+	BulkUnpublish  BulkAction = "unpublish"
+	BulkDelete     BulkAction = "delete"
+	BulkAddToAlbum BulkAction = "add_to_album"
+	BulkTag        BulkAction = "tag"
+)
+
+func ParseBulkAction(action string) (BulkAction, error) {
+	switch BulkAction(action) {
+	case BulkPublish, BulkUnpublish, BulkDelete, BulkAddToAlbum, BulkTag:
+		return BulkAction(action), nil
+	default:
+		return "", fmt.Errorf("%w: %s", serviceErr.UnsupportedBulkActionError, action)
+	}
+}
+
+// BulkOptions параметры группового действия.
+type BulkOptions struct {
+	// AlbumID альбом, в который добавляются фото, для BulkAddToAlbum.
+	AlbumID int
+	// Tag тег, который добавляется фото, для BulkTag.
+	Tag string
+}
+
+// BulkResult результат действия над одним фото.
+// PublicToken заполняется только при успешной публикации.
+type BulkResult struct {
+	PhotoID     int
+	PublicToken string
+	Error       error
+}
+
+type BulkResults []BulkResult
+
+func (r BulkResults) ErrorCount() int {
+	cnt := 0
+	for _, result := range r {
+		if result.Error != nil {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func (r BulkResults) SuccessCount() int {
+	return len(r) - r.ErrorCount()
+}
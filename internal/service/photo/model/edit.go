@@ -0,0 +1,96 @@
+package model
+
+import (
+	"fmt"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	"time"
+)
+
+// MaxEditOperations максимальное количество операций в одном рецепте правок.
+const MaxEditOperations = 32
+
+// EditOperationType вид операции правки.
+type EditOperationType string
+
+const (
+	EditRotate     EditOperationType = "rotate"
+	EditFlip       EditOperationType = "flip"
+	EditCrop       EditOperationType = "crop"
+	EditBrightness EditOperationType = "brightness"
+	EditContrast   EditOperationType = "contrast"
+	EditGrayscale  EditOperationType = "grayscale"
+)
+
+const (
+	FlipHorizontal = "horizontal"
+	FlipVertical   = "vertical"
+)
+
+// EditOperation операция правки. Заполняются только поля ее вида: Angle для rotate (кратен 90,
+// по часовой стрелке), Direction для flip, X, Y, Width, Height для crop (в пикселях изображения
+// после предыдущих операций), Value для brightness и contrast (от -100 до 100).
+// Рецепт хранится в БД в JSON, поэтому теги полей менять нельзя.
+type EditOperation struct {
+	Type      EditOperationType `json:"type"`
+	Angle     int               `json:"angle,omitempty"`
+	Direction string            `json:"direction,omitempty"`
+	X         int               `json:"x,omitempty"`
+	Y         int               `json:"y,omitempty"`
+	Width     int               `json:"width,omitempty"`
+	Height    int               `json:"height,omitempty"`
+	Value     int               `json:"value,omitempty"`
+}
+
+// Validate проверяет параметры операции, не зависящие от размеров изображения.
+func (o EditOperation) Validate() error {
+	switch o.Type {
+	case EditRotate:
+		if o.Angle%90 != 0 {
+			return fmt.Errorf("%w: rotation angle %d is not a multiple of 90", serviceErr.InvalidEditError, o.Angle)
+		}
+	case EditFlip:
+		if o.Direction != FlipHorizontal && o.Direction != FlipVertical {
+			return fmt.Errorf("%w: unknown flip direction %q", serviceErr.InvalidEditError, o.Direction)
+		}
+	case EditCrop:
+		if o.X < 0 || o.Y < 0 || o.Width <= 0 || o.Height <= 0 {
+			return fmt.Errorf("%w: invalid crop area %dx%d at (%d, %d)", serviceErr.InvalidEditError, o.Width, o.Height, o.X, o.Y)
+		}
+	case EditBrightness, EditContrast:
+		if o.Value < -100 || o.Value > 100 {
+			return fmt.Errorf("%w: %s %d is out of range [-100, 100]", serviceErr.InvalidEditError, o.Type, o.Value)
+		}
+	case EditGrayscale:
+	default:
+		return fmt.Errorf("%w: unknown operation %q", serviceErr.InvalidEditError, o.Type)
+	}
+
+	return nil
+}
+
+// ValidateEditOperations проверяет рецепт правок целиком.
+func ValidateEditOperations(ops []EditOperation) error {
+	if len(ops) == 0 {
+		return fmt.Errorf("%w: no operations", serviceErr.InvalidEditError)
+	}
+	if len(ops) > MaxEditOperations {
+		return fmt.Errorf("%w: too many operations, at most %d allowed", serviceErr.InvalidEditError, MaxEditOperations)
+	}
+
+	for i, op := range ops {
+		if err := op.Validate(); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// PhotoEdit рецепт правок фото и отредактированная версия, полученная по нему из оригинала.
+type PhotoEdit struct {
+	PhotoID    int
+	Operations []EditOperation
+	Version    model.PhotoVersion
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"fmt"
+	serviceErr "go-photo/internal/service/error"
+)
+
+// BBox прямоугольник на карте в градусах: долгота западной и восточной границ, широта южной и северной.
+// Если West больше East, прямоугольник пересекает 180-й меридиан.
+type BBox struct {
+	West  float64 `json:"west"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	North float64 `json:"north"`
+}
+
+func (b BBox) Validate() error {
+	for _, longitude := range []float64{b.West, b.East} {
+		if longitude < -180 || longitude > 180 {
+			return fmt.Errorf("%w: bbox longitude must be between -180 and 180", serviceErr.InvalidFilterError)
+		}
+	}
+	for _, latitude := range []float64{b.South, b.North} {
+		if latitude < -90 || latitude > 90 {
+			return fmt.Errorf("%w: bbox latitude must be between -90 and 90", serviceErr.InvalidFilterError)
+		}
+	}
+	if b.South > b.North {
+		return fmt.Errorf("%w: bbox south must not be greater than north", serviceErr.InvalidFilterError)
+	}
+
+	return nil
+}
+
+// ClusterFilter область карты и уровень масштаба, для которых фото группируются в кластеры.
+type ClusterFilter struct {
+	BBox BBox
+	// Zoom уровень масштаба карты в тайлах: 0 - весь мир в одном тайле
+	Zoom int
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"go-photo/internal/handler/response/photo"
+	"go-photo/internal/model"
 	"sync"
 	"time"
 )
@@ -21,6 +22,19 @@ type UploadInfo struct {
 	Height       int
 	Width        int
 	SavedAt      time.Time
+	Checksum     string
+	// Placeholder заглушка фото, пустая, если ее не удалось рассчитать при загрузке
+	Placeholder model.Placeholder
+	// Location координаты съемки из EXIF, nil - их нет в файле
+	Location *model.Location
+	// TakenAt местное время съемки из EXIF, нулевое - его нет в файле
+	TakenAt time.Time
+	// Camera камера из EXIF, пустая - ее нет в файле
+	Camera model.Camera
+	// ExifUnknown EXIF не удалось прочитать при загрузке
+	ExifUnknown bool
+	// PendingUploadID запись журнала загрузки, 0 - загрузка не журналируется
+	PendingUploadID int
 }
 
 func NewUploadInfoList(infos []UploadInfo) *UploadInfoList {
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"fmt"
+	serviceErr "go-photo/internal/service/error"
+	"go-photo/pkg/imaging"
+	"time"
+)
+
+// MetadataPolicy определяет, какие метаданные удаляются из файлов публикации.
+type MetadataPolicy string
+
+const (
+	// MetadataKeep файлы публикации отдаются как есть
+	MetadataKeep MetadataPolicy = "keep"
+	// MetadataStripPrivate удаляются геолокация, сведения о владельце и серийные номера
+	MetadataStripPrivate MetadataPolicy = "strip_private"
+	// MetadataStripAll удаляются все метаданные
+	MetadataStripAll MetadataPolicy = "strip_all"
+
+	// DefaultMetadataPolicy политика пользователей, не изменивших настройки публикации
+	DefaultMetadataPolicy = MetadataStripPrivate
+)
+
+func ParseMetadataPolicy(s string) (MetadataPolicy, error) {
+	switch p := MetadataPolicy(s); p {
+	case MetadataKeep, MetadataStripPrivate, MetadataStripAll:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w: %q", serviceErr.InvalidMetadataPolicyError, s)
+	}
+}
+
+// StripMode возвращает режим удаления метаданных. Для MetadataKeep возвращает false.
+func (p MetadataPolicy) StripMode() (imaging.StripMode, bool) {
+	switch p {
+	case MetadataStripPrivate:
+		return imaging.StripPrivate, true
+	case MetadataStripAll:
+		return imaging.StripAll, true
+	default:
+		return 0, false
+	}
+}
+
+// PublishOptions параметры публикации фото.
+type PublishOptions struct {
+	// Watermark файлы публикации отдаются с водяным знаком владельца, владелец получает их без знака.
+	Watermark bool
+	// Metadata политика метаданных публикации. Пустая означает политику из настроек пользователя.
+	Metadata MetadataPolicy
+}
+
+// PublishSettings настройки публикации пользователя.
+type PublishSettings struct {
+	// MetadataPolicy политика метаданных для публикаций без явной политики
+	MetadataPolicy MetadataPolicy
+	// UpdatedAt время изменения настроек, нулевое, если пользователь их не менял
+	UpdatedAt time.Time
+}
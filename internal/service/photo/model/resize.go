@@ -0,0 +1,85 @@
+package model
+
+import "strings"
+
+type FitMode string
+
+const (
+	// FitContain вписывает изображение в область с сохранением пропорций.
+	FitContain FitMode = "contain"
+	// FitCover заполняет область целиком, обрезая выступающие края.
+	FitCover FitMode = "cover"
+)
+
+type ImageFormat string
+
+const (
+	FormatJPEG ImageFormat = "jpeg"
+	FormatPNG  ImageFormat = "png"
+	FormatWebP ImageFormat = "webp"
+)
+
+// ParseImageFormat возвращает формат по имени или расширению файла (".jpg", "png").
+func ParseImageFormat(s string) (ImageFormat, bool) {
+	switch strings.TrimPrefix(strings.ToLower(s), ".") {
+	case "jpeg", "jpg":
+		return FormatJPEG, true
+	case "png":
+		return FormatPNG, true
+	case "webp":
+		return FormatWebP, true
+	default:
+		return "", false
+	}
+}
+
+func (f ImageFormat) Ext() string {
+	switch f {
+	case FormatPNG:
+		return ".png"
+	case FormatWebP:
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
+
+func (f ImageFormat) ContentType() string {
+	switch f {
+	case FormatPNG:
+		return "image/png"
+	case FormatWebP:
+		return "image/webp"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// ResizeOptions параметры изменения размера публичного фото.
+// Нулевые Fit, Quality и Format означают значения по умолчанию:
+// contain, первое разрешенное качество и WebP, если клиент его принимает, иначе формат исходной версии.
+// Нулевые Width и Height означают размер сохраненной версии Version: меняются только формат и качество.
+type ResizeOptions struct {
+	Width   int
+	Height  int
+	Fit     FitMode
+	Quality int
+	Format  ImageFormat
+	// Version версия, которая перекодируется без изменения размера, пустая строка - оригинал.
+	// Используется, только если Width и Height не заданы
+	Version string
+	// AcceptWebP клиент принимает WebP
+	AcceptWebP bool
+}
+
+// FileOptions параметры выдачи файла версии фото.
+type FileOptions struct {
+	// AcceptWebP клиент принимает WebP: вместо сохраненного файла можно отдать его WebP вариант.
+	AcceptWebP bool
+}
+
+// PhotoFile содержимое файла фото вместе с его MIME типом.
+type PhotoFile struct {
+	Data        []byte
+	ContentType string
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"fmt"
+	"go-photo/internal/model"
+	serviceErr "go-photo/internal/service/error"
+	"time"
+)
+
+// ShareRole роль пользователя, которому выдан доступ к фото или альбому.
+type ShareRole string
+
+const (
+	// ShareViewer может просматривать фото и их файлы
+	ShareViewer ShareRole = "viewer"
+	// ShareContributor может еще и добавлять свои фото в альбом, только для альбомов
+	ShareContributor ShareRole = "contributor"
+)
+
+func ParseShareRole(s string) (ShareRole, error) {
+	switch r := ShareRole(s); r {
+	case ShareViewer, ShareContributor:
+		return r, nil
+	default:
+		return "", fmt.Errorf("%w: unknown role %q", serviceErr.InvalidShareError, s)
+	}
+}
+
+// Share доступ, выданный пользователю.
+type Share struct {
+	UserUUID  string
+	Role      ShareRole
+	CreatedAt time.Time
+}
+
+// SharedAlbum альбом другого пользователя, к которому выдан доступ.
+type SharedAlbum struct {
+	Album
+	OwnerUUID string
+	Role      ShareRole
+}
+
+// SharedWithMe фото и альбомы других пользователей, к которым выдан доступ.
+type SharedWithMe struct {
+	// Photos страница фото, доступ к которым выдан напрямую
+	Photos []model.Photo
+	Albums []SharedAlbum
+}
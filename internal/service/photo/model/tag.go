@@ -0,0 +1,24 @@
+package model
+
+import (
+	"fmt"
+	serviceErr "go-photo/internal/service/error"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTagLength максимальная длина тега
+const MaxTagLength = 50
+
+// NormalizeTag приводит тег к виду, в котором он хранится и ищется: без пробелов по краям,
+// в нижнем регистре. Пустой или слишком длинный тег - ошибка InvalidTagError.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", fmt.Errorf("%w: tag is empty", serviceErr.InvalidTagError)
+	}
+	if utf8.RuneCountInString(tag) > MaxTagLength {
+		return "", fmt.Errorf("%w: tag is longer than %d characters", serviceErr.InvalidTagError, MaxTagLength)
+	}
+	return tag, nil
+}
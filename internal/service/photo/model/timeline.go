@@ -0,0 +1,38 @@
+package model
+
+import (
+	"go-photo/internal/model"
+	"time"
+)
+
+// TimelineFilter параметры ленты фото по периодам съемки.
+type TimelineFilter struct {
+	// Granularity размер периода, пустой - месяц
+	Granularity model.TimelineGranularity
+	// TimeZone часовой пояс IANA, в котором считается время загрузки фото без EXIF. Пустая строка - UTC.
+	TimeZone string
+	// Cursor ключ периода в формате Granularity.Layout(), с которого начинается страница.
+	// Пустая строка - с последнего периода.
+	Cursor string
+	// Limit количество периодов на странице, 0 - значение по умолчанию
+	Limit int
+}
+
+// Timeline страница ленты фото.
+type Timeline struct {
+	Granularity model.TimelineGranularity
+	Buckets     []model.TimelineBucket
+	// NextCursor ключ первого периода следующей страницы, пустая строка - это последняя страница
+	NextCursor string
+}
+
+// OnThisDayFilter параметры поиска фото, снятых в тот же день в прошлые годы.
+type OnThisDayFilter struct {
+	// TimeZone часовой пояс IANA, в котором определяется сегодняшний день и считается время загрузки
+	// фото без EXIF. Пустая строка - UTC.
+	TimeZone string
+	// Date день, для которого ищутся фото. Нулевой - сегодня.
+	Date time.Time
+	// Limit количество фото, 0 - значение по умолчанию
+	Limit int
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"go-photo/internal/handler/response/photo"
+	"go-photo/internal/model"
+	"time"
+)
+
+// TrashedPhoto фото в корзине. PurgeAt - время, после которого фото будет удалено окончательно.
+type TrashedPhoto struct {
+	model.Photo
+	PurgeAt time.Time
+}
+
+func ToTrashedPhotosFromService(photos []TrashedPhoto) []photo.TrashedPhoto {
+	trashedPhotos := make([]photo.TrashedPhoto, 0, len(photos))
+	for _, p := range photos {
+		trashedPhotos = append(trashedPhotos, photo.TrashedPhoto{
+			PhotoID:    p.ID,
+			Filename:   p.Filename,
+			UploadedAt: p.UploadedAt.Format(time.DateTime),
+			DeletedAt:  p.DeletedAt.Format(time.DateTime),
+			PurgeAt:    p.PurgeAt.Format(time.DateTime),
+		})
+	}
+	return trashedPhotos
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	serviceErr "go-photo/internal/service/error"
+	"go-photo/pkg/imaging"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxWatermarkTextLength = 100
+
+	DefaultWatermarkPosition = WatermarkBottomRight
+	DefaultWatermarkOpacity  = 50
+	DefaultWatermarkScale    = 20
+)
+
+type WatermarkType string
+
+const (
+	WatermarkText WatermarkType = "text"
+	// WatermarkLogo знак - загруженный пользователем PNG логотип
+	WatermarkLogo WatermarkType = "logo"
+)
+
+type WatermarkPosition string
+
+const (
+	WatermarkTopLeft     = WatermarkPosition(imaging.TopLeft)
+	WatermarkTopRight    = WatermarkPosition(imaging.TopRight)
+	WatermarkBottomLeft  = WatermarkPosition(imaging.BottomLeft)
+	WatermarkBottomRight = WatermarkPosition(imaging.BottomRight)
+	WatermarkCenter      = WatermarkPosition(imaging.Center)
+)
+
+// WatermarkSettings изменяемые пользователем настройки водяного знака.
+// Нулевые Position, Opacity и Scale означают значения по умолчанию.
+type WatermarkSettings struct {
+	Type WatermarkType
+	// Text текст знака, обязателен для типа text
+	Text     string
+	Position WatermarkPosition
+	// Opacity непрозрачность в процентах, от 1 до 100
+	Opacity int
+	// Scale ширина знака в процентах от ширины фото, от 1 до 100
+	Scale int
+}
+
+// Watermark настройки водяного знака пользователя.
+type Watermark struct {
+	WatermarkSettings
+	// HasLogo загружен ли логотип. Логотип сохраняется при переключении на текстовый знак.
+	HasLogo   bool
+	UpdatedAt time.Time
+}
+
+// WithDefaults возвращает настройки с заполненными значениями по умолчанию.
+func (s WatermarkSettings) WithDefaults() WatermarkSettings {
+	if s.Position == "" {
+		s.Position = DefaultWatermarkPosition
+	}
+	if s.Opacity == 0 {
+		s.Opacity = DefaultWatermarkOpacity
+	}
+	if s.Scale == 0 {
+		s.Scale = DefaultWatermarkScale
+	}
+	return s
+}
+
+func (s WatermarkSettings) Validate() error {
+	switch s.Type {
+	case WatermarkText:
+		if strings.TrimSpace(s.Text) == "" {
+			return fmt.Errorf("%w: text is required for text watermark", serviceErr.InvalidWatermarkError)
+		}
+		if utf8.RuneCountInString(s.Text) > MaxWatermarkTextLength {
+			return fmt.Errorf("%w: text is longer than %d characters", serviceErr.InvalidWatermarkError, MaxWatermarkTextLength)
+		}
+		if !imaging.CanRenderText(s.Text) {
+			return fmt.Errorf("%w: text may contain only printable ASCII characters", serviceErr.InvalidWatermarkError)
+		}
+	case WatermarkLogo:
+	default:
+		return fmt.Errorf("%w: unknown type %q", serviceErr.InvalidWatermarkError, s.Type)
+	}
+
+	switch s.Position {
+	case WatermarkTopLeft, WatermarkTopRight, WatermarkBottomLeft, WatermarkBottomRight, WatermarkCenter:
+	default:
+		return fmt.Errorf("%w: unknown position %q", serviceErr.InvalidWatermarkError, s.Position)
+	}
+
+	if s.Opacity < 1 || s.Opacity > 100 {
+		return fmt.Errorf("%w: opacity must be between 1 and 100", serviceErr.InvalidWatermarkError)
+	}
+	if s.Scale < 1 || s.Scale > 100 {
+		return fmt.Errorf("%w: scale must be between 1 and 100", serviceErr.InvalidWatermarkError)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/model"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/imaging"
+	"go-photo/pkg/logger"
+	"image"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// placeholderBatchSize количество версий, заглушки которых рассчитываются за один запрос к БД
+	placeholderBatchSize = 100
+	// paletteSize максимальное количество преобладающих цветов в заглушке
+	paletteSize = 5
+)
+
+// imageDecodeError возвращается, если файл версии не удалось разобрать как изображение.
+var imageDecodeError = errors.New("failed to decode image")
+
+func (s *service) BackfillPlaceholders(ctx context.Context) error {
+	var errs []error
+	for {
+		versions, err := s.photoRepository.GetVersionsWithoutPlaceholder(ctx, placeholderBatchSize)
+		if err != nil {
+			return errors.Join(append(errs, fmt.Errorf("failed to get versions without placeholder: %w", err))...)
+		}
+
+		saved := 0
+		for _, v := range versions {
+			if err := s.backfillPlaceholder(ctx, v); err != nil {
+				errs = append(errs, fmt.Errorf("photo version %d: %w", v.ID, err))
+				continue
+			}
+			saved++
+		}
+
+		if saved > 0 {
+			logger.FromContext(ctx).Infof("Computed placeholders of %d photo versions", saved)
+		}
+
+		// Необработанные версии вернутся в следующей выборке, поэтому без прогресса останавливаемся
+		if len(versions) < placeholderBatchSize || saved == 0 {
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// backfillPlaceholder рассчитывает и сохраняет заглушку версии.
+// Если файл не удалось разобрать, сохраняется пустая заглушка, чтобы не разбирать его повторно.
+// Ошибки чтения файла возвращаются: версия будет обработана на следующем запуске.
+func (s *service) backfillPlaceholder(ctx context.Context, v repoModel.PhotoVersionWithOwner) error {
+	path := filepath.Join(s.d.StorageFolderPath, v.UserUUID.String, v.UUIDFilename)
+
+	placeholder, err := placeholderOfFile(path)
+	if errors.Is(err, imageDecodeError) {
+		logger.FromContext(ctx).Warnf("No placeholder for photo version %d: %v", v.ID, err)
+	} else if err != nil {
+		return err
+	}
+
+	return s.photoRepository.SavePhotoVersionPlaceholder(ctx, v.ID, &repoModel.SavePhotoVersionPlaceholderParams{
+		Blurhash: placeholder.BlurHash,
+		Palette:  placeholder.Palette,
+	})
+}
+
+// placeholderOfFile рассчитывает заглушку изображения из файла path.
+// Если файл не является поддерживаемым растровым изображением, возвращает imageDecodeError.
+func placeholderOfFile(path string) (model.Placeholder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return model.Placeholder{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return model.Placeholder{}, fmt.Errorf("%w: %v", imageDecodeError, err)
+	}
+
+	return placeholderOf(img)
+}
+
+// placeholderOf рассчитывает BlurHash и палитру изображения.
+// Детализация BlurHash больше по длинной стороне изображения.
+func placeholderOf(img image.Image) (model.Placeholder, error) {
+	xComponents, yComponents := 4, 3
+	if img.Bounds().Dy() > img.Bounds().Dx() {
+		xComponents, yComponents = 3, 4
+	}
+
+	hash, err := imaging.BlurHash(img, xComponents, yComponents)
+	if err != nil {
+		return model.Placeholder{}, fmt.Errorf("%w: %v", imageDecodeError, err)
+	}
+
+	colors := imaging.DominantColors(img, paletteSize)
+	palette := make([]string, len(colors))
+	for i, c := range colors {
+		palette[i] = imaging.FormatHexColor(c)
+	}
+
+	return model.Placeholder{BlurHash: hash, Palette: palette}, nil
+}
@@ -0,0 +1,105 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestService_UploadPhoto_Placeholder(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(1, nil)
+	mockRepo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, params *repoModel.CreateOriginalPhotoParams) (int, error) {
+			assert.Len(t, params.Blurhash, 28)
+			require.Len(t, params.Palette, 1)
+			assert.Regexp(t, `^#[0-9a-f]{6}$`, params.Palette[0])
+			return 1, nil
+		})
+	mockRepo.EXPECT().DeletePendingUpload(gomock.Any(), 1).Return(nil)
+
+	s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+	id, err := s.UploadPhoto(context.Background(), "user-id", mockFileHeader("test.jpg", 100, ""))
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+}
+
+func TestService_BackfillPlaceholders(t *testing.T) {
+	const userUUID = "user-id"
+
+	storage := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(storage, userUUID), 0755))
+
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 20; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 0x33, G: 0x66, B: 0xCC, A: 255})
+		}
+	}
+	f, err := os.Create(filepath.Join(storage, userUUID, "photo.png"))
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, img))
+	require.NoError(t, f.Close())
+
+	require.NoError(t, os.WriteFile(filepath.Join(storage, userUUID, "drawing.svg"), []byte("<svg/>"), 0644))
+
+	version := func(id int, filename string) repoModel.PhotoVersionWithOwner {
+		return repoModel.PhotoVersionWithOwner{
+			PhotoVersion: repoModel.PhotoVersion{ID: id, PhotoID: id, UUIDFilename: filename},
+			UserUUID:     sql.NullString{String: userUUID, Valid: true},
+		}
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetVersionsWithoutPlaceholder(gomock.Any(), placeholderBatchSize).Return([]repoModel.PhotoVersionWithOwner{
+		version(1, "photo.png"),
+		version(2, "drawing.svg"),
+		version(3, "missing.jpg"),
+	}, nil)
+	mockRepo.EXPECT().SavePhotoVersionPlaceholder(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, params *repoModel.SavePhotoVersionPlaceholderParams) error {
+			// Портретное изображение кодируется с 3x4 компонентами
+			assert.Equal(t, "T", params.Blurhash[:1])
+			assert.Equal(t, []string{"#3366cc"}, params.Palette)
+			return nil
+		})
+	// Файл, который не удалось разобрать, помечается пустой заглушкой
+	mockRepo.EXPECT().SavePhotoVersionPlaceholder(gomock.Any(), 2, &repoModel.SavePhotoVersionPlaceholderParams{}).Return(nil)
+
+	s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+	err = s.BackfillPlaceholders(context.Background())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "photo version 3")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestService_BackfillPlaceholders_RepoError(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetVersionsWithoutPlaceholder(gomock.Any(), placeholderBatchSize).Return(nil, errors.New("db error"))
+
+	s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+	assert.ErrorContains(t, s.BackfillPlaceholders(context.Background()), "db error")
+}
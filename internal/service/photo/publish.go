@@ -2,18 +2,81 @@ package photo
 
 import (
 	"context"
+	"errors"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceModel "go-photo/internal/service/photo/model"
 )
 
-func (s *service) PublishPhoto(ctx context.Context, userUUID string, photoID int) (string, error) {
-	photo, err := s.getUserPhoto(ctx, userUUID, photoID)
+func (s *service) PublishPhoto(ctx context.Context, userUUID string, photoID int, opts serviceModel.PublishOptions) (string, error) {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
 	if err != nil {
 		return "", err
 	}
 
-	publicToken, err := s.photoRepository.CreatePhotoPublishedInfo(ctx, photo.ID)
-	if err := s.HandleRepoErr(err); err != nil {
+	if opts.Watermark {
+		_, err := s.photoRepository.GetWatermarkSettings(ctx, userUUID)
+		if err := s.handleWatermarkRepoErr(ctx, err); err != nil {
+			return "", err
+		}
+	}
+
+	policy := opts.Metadata
+	if policy == "" {
+		settings, err := s.GetPublishSettings(ctx, userUUID)
+		if err != nil {
+			return "", err
+		}
+		policy = settings.MetadataPolicy
+	}
+	if _, err := serviceModel.ParseMetadataPolicy(string(policy)); err != nil {
+		return "", err
+	}
+
+	publicToken, err := s.photoRepository.CreatePhotoPublishedInfo(ctx, &repoModel.CreatePhotoPublishedInfoParams{
+		PhotoID:        photo.ID,
+		Watermark:      opts.Watermark,
+		MetadataPolicy: string(policy),
+	})
+	if err := s.HandleRepoErr(ctx, err); err != nil {
 		return "", err
 	}
 
 	return publicToken, nil
 }
+
+func (s *service) GetPublishSettings(ctx context.Context, userUUID string) (*serviceModel.PublishSettings, error) {
+	settings, err := s.photoRepository.GetPublishSettings(ctx, userUUID)
+	if errors.Is(err, repoErr.NotFoundError) {
+		return &serviceModel.PublishSettings{MetadataPolicy: serviceModel.DefaultMetadataPolicy}, nil
+	}
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toPublishSettings(settings), nil
+}
+
+func (s *service) SavePublishSettings(ctx context.Context, userUUID string, settings serviceModel.PublishSettings) (*serviceModel.PublishSettings, error) {
+	policy, err := serviceModel.ParseMetadataPolicy(string(settings.MetadataPolicy))
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.photoRepository.SavePublishSettings(ctx, &repoModel.SavePublishSettingsParams{
+		UserUUID:       userUUID,
+		MetadataPolicy: string(policy),
+	})
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toPublishSettings(saved), nil
+}
+
+func toPublishSettings(settings *repoModel.PublishSettings) *serviceModel.PublishSettings {
+	return &serviceModel.PublishSettings{
+		MetadataPolicy: serviceModel.MetadataPolicy(settings.MetadataPolicy),
+		UpdatedAt:      settings.UpdatedAt,
+	}
+}
@@ -0,0 +1,233 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// jpegWithArtist JPEG с EXIF, в котором записан автор.
+func jpegWithArtist(t *testing.T, artist string) []byte {
+	t.Helper()
+
+	// TIFF: заголовок, IFD0 с одним тегом Artist, значение сразу после IFD
+	value := append([]byte(artist), 0)
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x013B)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = binary.LittleEndian.AppendUint32(tiff, uint32(len(value)))
+	tiff = binary.LittleEndian.AppendUint32(tiff, 26)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = append(tiff, value...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 16)), nil))
+	encoded := buf.Bytes()
+
+	return append(append(append([]byte{}, encoded[:2]...), segment...), encoded[2:]...)
+}
+
+func TestService_PublishPhoto_MetadataPolicy(t *testing.T) {
+	const userUUID = "user-id"
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name           string
+		metadata       serviceModel.MetadataPolicy
+		mockBehavior   mockBehavior
+		expectedPolicy string
+		expectedErr    error
+	}{
+		{
+			name:           "Explicit policy",
+			metadata:       serviceModel.MetadataStripAll,
+			mockBehavior:   func(repo *mock_repository.MockPhotoRepository) {},
+			expectedPolicy: "strip_all",
+		},
+		{
+			name: "User default",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublishSettings(gomock.Any(), userUUID).
+					Return(&repoModel.PublishSettings{UserUUID: userUUID, MetadataPolicy: "keep"}, nil)
+			},
+			expectedPolicy: "keep",
+		},
+		{
+			name: "No user settings",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublishSettings(gomock.Any(), userUUID).Return(nil, repoErr.NotFoundError)
+			},
+			expectedPolicy: "strip_private",
+		},
+		{
+			name:         "Unknown policy",
+			metadata:     "strip_some",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidMetadataPolicyError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+			tt.mockBehavior(mockRepo)
+			if tt.expectedErr == nil {
+				mockRepo.EXPECT().CreatePhotoPublishedInfo(gomock.Any(), &repoModel.CreatePhotoPublishedInfoParams{
+					PhotoID: 1, MetadataPolicy: tt.expectedPolicy,
+				}).Return("token", nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			token, err := s.PublishPhoto(context.Background(), userUUID, 1, serviceModel.PublishOptions{Metadata: tt.metadata})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "token", token)
+		})
+	}
+}
+
+func TestService_GetPhotoFileByVersionAndToken_StripMetadata(t *testing.T) {
+	const userUUID = "user-id"
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storage := t.TempDir()
+	source := filepath.Join(storage, userUUID, "original.jpg")
+	require.NoError(t, os.MkdirAll(filepath.Dir(source), 0755))
+	require.NoError(t, os.WriteFile(source, jpegWithArtist(t, "John Doe"), 0644))
+
+	version := &repoModel.PhotoVersion{ID: 10, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "original.jpg"}
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetPhotoVersionByToken(gomock.Any(), "token", gomock.Any()).Return(version, nil).Times(2)
+	mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil).Times(3)
+	mockRepo.EXPECT().GetWatermarkByToken(gomock.Any(), "token").Return(nil, repoErr.NotFoundError).Times(2)
+	mockRepo.EXPECT().GetMetadataPolicyByToken(gomock.Any(), "token").Return("strip_private", nil).Times(2)
+	gomock.InOrder(
+		mockRepo.EXPECT().GetPhotoVersionRendition(gomock.Any(), 10, "strip_private").Return(nil, repoErr.NotFoundError),
+		mockRepo.EXPECT().GetPhotoVersionRendition(gomock.Any(), 10, "strip_private").
+			Return(&repoModel.PhotoVersionRendition{VersionID: 10, Format: "strip_private"}, nil),
+	)
+	// Тег удаляется на месте, размер файла не меняется
+	mockRepo.EXPECT().SavePhotoVersionRendition(gomock.Any(), &repoModel.SavePhotoVersionRenditionParams{
+		VersionID: 10, Format: "strip_private", UUIDFilename: "original.strip_private.jpg", Size: int64(len(jpegWithArtist(t, "John Doe"))),
+	}).Return(nil)
+	mockRepo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return([]repoModel.PhotoVersion{*version}, nil)
+
+	s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+	file, err := s.GetPhotoFileByVersionAndToken(context.Background(), "token", "original", serviceModel.FileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", file.ContentType)
+	assert.NotContains(t, string(file.Data), "John Doe")
+	_, err = jpeg.Decode(bytes.NewReader(file.Data))
+	require.NoError(t, err)
+
+	cached, err := os.ReadFile(filepath.Join(storage, RenditionsFolderName, userUUID, "original.strip_private.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, file.Data, cached)
+
+	// Повторный запрос отдает сохраненную копию
+	again, err := s.GetPhotoFileByVersionAndToken(context.Background(), "token", "original", serviceModel.FileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, file.Data, again.Data)
+
+	// Владелец получает исходный файл
+	own, err := s.GetPhotoFile(context.Background(), userUUID, 1, "original", serviceModel.FileOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, string(own.Data), "John Doe")
+}
+
+func TestService_SavePublishSettings(t *testing.T) {
+	const userUUID = "user-id"
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		policy           serviceModel.MetadataPolicy
+		mockBehavior     func(repo *mock_repository.MockPhotoRepository)
+		expectedSettings *serviceModel.PublishSettings
+		expectedErr      error
+	}{
+		{
+			name:   "Saved",
+			policy: serviceModel.MetadataStripAll,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().SavePublishSettings(gomock.Any(), &repoModel.SavePublishSettingsParams{
+					UserUUID: userUUID, MetadataPolicy: "strip_all",
+				}).Return(&repoModel.PublishSettings{UserUUID: userUUID, MetadataPolicy: "strip_all", UpdatedAt: updatedAt}, nil)
+			},
+			expectedSettings: &serviceModel.PublishSettings{MetadataPolicy: serviceModel.MetadataStripAll, UpdatedAt: updatedAt},
+		},
+		{
+			name:         "Unknown policy",
+			policy:       "",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidMetadataPolicyError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			settings, err := s.SavePublishSettings(context.Background(), userUUID, serviceModel.PublishSettings{MetadataPolicy: tt.policy})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSettings, settings)
+		})
+	}
+}
+
+func TestService_GetPublishSettings_Default(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetPublishSettings(gomock.Any(), "user-id").Return(nil, repoErr.NotFoundError)
+
+	s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+	settings, err := s.GetPublishSettings(context.Background(), "user-id")
+	require.NoError(t, err)
+	assert.Equal(t, &serviceModel.PublishSettings{MetadataPolicy: serviceModel.DefaultMetadataPolicy}, settings)
+}
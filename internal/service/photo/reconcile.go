@@ -0,0 +1,116 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/pkg/logger"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func (s *service) ReconcileUploads(ctx context.Context) error {
+	uploads, err := s.photoRepository.GetPendingUploads(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pending uploads: %w", err)
+	}
+
+	// Более новые загрузки могут еще выполняться
+	staleBefore := time.Now().Add(-s.d.StaleUploadAfter)
+
+	var errs []error
+	journaled := make(map[string]struct{}, len(uploads))
+	for _, upload := range uploads {
+		journaled[upload.UUIDFilename] = struct{}{}
+		if upload.CreatedAt.After(staleBefore) {
+			continue
+		}
+
+		err = s.reconcileUpload(ctx, upload)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pending upload %d: %w", upload.ID, err))
+		}
+	}
+
+	err = s.removeOrphanStagedFiles(ctx, journaled, staleBefore)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *service) reconcileUpload(ctx context.Context, upload repoModel.PendingUpload) error {
+	log := logger.FromContext(ctx)
+
+	if upload.IsCommitted() {
+		finalPath := filepath.Join(s.d.StorageFolderPath, upload.UserUUID, upload.UUIDFilename)
+		err := promoteStagedFile(s.stagedFilePath(upload.UUIDFilename), finalPath)
+		if err != nil {
+			return err
+		}
+
+		err = s.photoRepository.DeletePendingUpload(ctx, upload.ID)
+		if err != nil && !errors.Is(err, repoErr.NotFoundError) {
+			return err
+		}
+
+		log.Infof("Finished interrupted upload of %s for photo %d", upload.UUIDFilename, upload.PhotoID.Int64)
+		return nil
+	}
+
+	// Сначала удаляется запись журнала: если фото успело сохраниться, удаление не пройдет
+	// и файл будет перенесен при следующей проверке
+	err := s.photoRepository.DeleteUncommittedPendingUpload(ctx, upload.ID)
+	if errors.Is(err, repoErr.NotFoundError) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(s.stagedFilePath(upload.UUIDFilename))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove staged file: %w", err)
+	}
+
+	log.Infof("Rolled back interrupted upload of %s", upload.UUIDFilename)
+	return nil
+}
+
+// removeOrphanStagedFiles удаляет устаревшие staging файлы без записи в журнале.
+func (s *service) removeOrphanStagedFiles(ctx context.Context, journaled map[string]struct{}, staleBefore time.Time) error {
+	entries, err := os.ReadDir(s.stagingFolder())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read staging folder: %w", err)
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if _, ok := journaled[entry.Name()]; ok || entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(staleBefore) {
+			continue
+		}
+
+		err = os.Remove(s.stagedFilePath(entry.Name()))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to remove orphan staged file: %w", err))
+			continue
+		}
+
+		logger.FromContext(ctx).Infof("Removed orphan staged file %s", entry.Name())
+	}
+
+	return errors.Join(errs...)
+}
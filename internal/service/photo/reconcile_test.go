@@ -0,0 +1,173 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_ReconcileUploads(t *testing.T) {
+	const userUUID = "user-id"
+	stale := time.Now().Add(-time.Hour)
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		upload       repoModel.PendingUpload
+		staged       bool
+		promoted     bool
+		mockBehavior mockBehavior
+		// expectedStaged и expectedPromoted где должен оказаться файл после проверки
+		expectedStaged   bool
+		expectedPromoted bool
+		expectedErr      bool
+	}{
+		{
+			name: "Committed upload is promoted",
+			upload: repoModel.PendingUpload{
+				ID: 1, UserUUID: userUUID, UUIDFilename: "committed.jpg",
+				PhotoID: sql.NullInt64{Int64: 10, Valid: true}, CreatedAt: stale,
+			},
+			staged: true,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().DeletePendingUpload(gomock.Any(), 1).Return(nil)
+			},
+			expectedPromoted: true,
+		},
+		{
+			name: "Committed upload already promoted",
+			upload: repoModel.PendingUpload{
+				ID: 1, UserUUID: userUUID, UUIDFilename: "committed.jpg",
+				PhotoID: sql.NullInt64{Int64: 10, Valid: true}, CreatedAt: stale,
+			},
+			promoted: true,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().DeletePendingUpload(gomock.Any(), 1).Return(repoErr.NotFoundError)
+			},
+			expectedPromoted: true,
+		},
+		{
+			name: "Committed upload lost its file",
+			upload: repoModel.PendingUpload{
+				ID: 1, UserUUID: userUUID, UUIDFilename: "committed.jpg",
+				PhotoID: sql.NullInt64{Int64: 10, Valid: true}, CreatedAt: stale,
+			},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  true,
+		},
+		{
+			name: "Uncommitted upload is rolled back",
+			upload: repoModel.PendingUpload{
+				ID: 2, UserUUID: userUUID, UUIDFilename: "abandoned.jpg", CreatedAt: stale,
+			},
+			staged: true,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().DeleteUncommittedPendingUpload(gomock.Any(), 2).Return(nil)
+			},
+		},
+		{
+			name: "Upload committed concurrently",
+			upload: repoModel.PendingUpload{
+				ID: 2, UserUUID: userUUID, UUIDFilename: "abandoned.jpg", CreatedAt: stale,
+			},
+			staged: true,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().DeleteUncommittedPendingUpload(gomock.Any(), 2).Return(repoErr.NotFoundError)
+			},
+			expectedStaged: true,
+		},
+		{
+			name: "Fresh upload is skipped",
+			upload: repoModel.PendingUpload{
+				ID: 3, UserUUID: userUUID, UUIDFilename: "in-progress.jpg", CreatedAt: time.Now(),
+			},
+			staged:         true,
+			mockBehavior:   func(repo *mock_repository.MockPhotoRepository) {},
+			expectedStaged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageDir := t.TempDir()
+			stagedPath := filepath.Join(storageDir, StagingFolderName, tt.upload.UUIDFilename)
+			promotedPath := filepath.Join(storageDir, userUUID, tt.upload.UUIDFilename)
+			writeFile(t, stagedPath, tt.staged)
+			writeFile(t, promotedPath, tt.promoted)
+
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			mockRepo.EXPECT().GetPendingUploads(gomock.Any()).Return([]repoModel.PendingUpload{tt.upload}, nil)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: storageDir, StaleUploadAfter: time.Minute}, mockRepo, nil)
+
+			err := s.ReconcileUploads(context.Background())
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assertFileExists(t, stagedPath, tt.expectedStaged)
+			assertFileExists(t, promotedPath, tt.expectedPromoted)
+		})
+	}
+}
+
+func TestService_ReconcileUploads_OrphanFiles(t *testing.T) {
+	storageDir := t.TempDir()
+	orphan := filepath.Join(storageDir, StagingFolderName, "orphan.jpg")
+	fresh := filepath.Join(storageDir, StagingFolderName, "fresh.jpg")
+	journaled := filepath.Join(storageDir, StagingFolderName, "journaled.jpg")
+	for _, path := range []string{orphan, fresh, journaled} {
+		writeFile(t, path, true)
+	}
+	old := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(orphan, old, old))
+	assert.NoError(t, os.Chtimes(journaled, old, old))
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetPendingUploads(gomock.Any()).Return([]repoModel.PendingUpload{
+		{ID: 1, UserUUID: "user-id", UUIDFilename: "journaled.jpg", CreatedAt: time.Now()},
+	}, nil)
+
+	s := NewService(Deps{StorageFolderPath: storageDir, StaleUploadAfter: time.Minute}, mockRepo, nil)
+
+	assert.NoError(t, s.ReconcileUploads(context.Background()))
+	assert.NoFileExists(t, orphan)
+	assert.FileExists(t, fresh)
+	assert.FileExists(t, journaled)
+}
+
+func writeFile(t *testing.T, path string, create bool) {
+	t.Helper()
+	if !create {
+		return
+	}
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte("file content"), 0644))
+}
+
+func assertFileExists(t *testing.T, path string, exists bool) {
+	t.Helper()
+	if exists {
+		assert.FileExists(t, path)
+	} else {
+		assert.NoFileExists(t, path)
+	}
+}
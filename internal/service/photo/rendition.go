@@ -0,0 +1,253 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"go-photo/pkg/imaging"
+	"go-photo/pkg/logger"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// versionFile возвращает файл версии фото. Если клиент принимает WebP, а версия хранится в другом
+// формате, отдается ее WebP вариант, но только если он меньше исходного файла.
+// При ошибке создания варианта отдается исходный файл.
+func (s *service) versionFile(ctx context.Context, userUUID string, version *repoModel.PhotoVersion, opts serviceModel.FileOptions) (*serviceModel.PhotoFile, error) {
+	if file := s.negotiatedWebP(ctx, userUUID, version, opts); file != nil {
+		return file, nil
+	}
+
+	path := filepath.Join(s.d.StorageFolderPath, userUUID, version.UUIDFilename)
+	logger.FromContext(ctx).Debugf("reading photo file %s", path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read file: %v", serviceErr.UnexpectedError, err)
+	}
+
+	return versionPhotoFile(version, data), nil
+}
+
+// publicVersionFile возвращает файл версии публикации с политикой метаданных policy. WebP вариант
+// перекодирован и метаданных не содержит, вместо сохраненного файла отдается его копия без метаданных,
+// созданная при первом запросе. Если копию создать не удалось, исходный файл не отдается.
+func (s *service) publicVersionFile(ctx context.Context, userUUID string, version *repoModel.PhotoVersion, policy serviceModel.MetadataPolicy, opts serviceModel.FileOptions) (*serviceModel.PhotoFile, error) {
+	mode, strip := policy.StripMode()
+	// Файлы, формат которых не распознан по расширению, не декодируются и отдаются как есть
+	if _, known := serviceModel.ParseImageFormat(filepath.Ext(version.UUIDFilename)); !strip || !known {
+		return s.versionFile(ctx, userUUID, version, opts)
+	}
+
+	if file := s.negotiatedWebP(ctx, userUUID, version, opts); file != nil {
+		return file, nil
+	}
+
+	data, err := s.strippedRendition(ctx, userUUID, version, policy, mode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to strip metadata of photo version %d: %v", serviceErr.UnexpectedError, version.ID, err)
+	}
+
+	return versionPhotoFile(version, data), nil
+}
+
+// negotiatedWebP возвращает WebP вариант версии, если клиент его принимает и он меньше исходного файла.
+func (s *service) negotiatedWebP(ctx context.Context, userUUID string, version *repoModel.PhotoVersion, opts serviceModel.FileOptions) *serviceModel.PhotoFile {
+	format, _ := serviceModel.ParseImageFormat(filepath.Ext(version.UUIDFilename))
+	if !opts.AcceptWebP || !s.d.WebP.Enabled || format == serviceModel.FormatWebP {
+		return nil
+	}
+
+	file, err := s.webpRendition(ctx, userUUID, version)
+	if err != nil {
+		logger.FromContext(ctx).Warnf("Failed to get WebP rendition of photo version %d, serving stored file: %v", version.ID, err)
+	}
+	return file
+}
+
+func versionPhotoFile(version *repoModel.PhotoVersion, data []byte) *serviceModel.PhotoFile {
+	format, known := serviceModel.ParseImageFormat(filepath.Ext(version.UUIDFilename))
+	contentType := format.ContentType()
+	if !known {
+		contentType = http.DetectContentType(data)
+	}
+
+	return &serviceModel.PhotoFile{Data: data, ContentType: contentType}
+}
+
+// webpRendition возвращает WebP вариант версии, создавая его при первом запросе.
+// Возвращает nil, если вариант не меньше исходного файла и отдавать его нет смысла.
+func (s *service) webpRendition(ctx context.Context, userUUID string, version *repoModel.PhotoVersion) (*serviceModel.PhotoFile, error) {
+	format := serviceModel.FormatWebP
+	path := s.renditionPath(userUUID, version.UUIDFilename, format)
+
+	res, err, _ := s.variants.Do(path, func() (any, error) {
+		rendition, err := s.photoRepository.GetPhotoVersionRendition(ctx, version.ID, string(format))
+		switch {
+		case err == nil && rendition.Size >= version.Size:
+			return []byte(nil), nil
+		case err == nil:
+			data, err := os.ReadFile(path)
+			if err == nil {
+				return data, nil
+			}
+			logger.FromContext(ctx).Warnf("Failed to read rendition %s, creating it again: %v", path, err)
+		case !errors.Is(err, repoErr.NotFoundError):
+			return nil, err
+		}
+
+		img, err := decodeImageFile(filepath.Join(s.d.StorageFolderPath, userUUID, version.UUIDFilename))
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, img, format.Ext(), s.d.WebP.Quality); err != nil {
+			return nil, fmt.Errorf("failed to encode rendition: %w", err)
+		}
+		data := buf.Bytes()
+
+		if err := writeFileAtomically(path, data); err != nil {
+			return nil, fmt.Errorf("failed to write rendition: %w", err)
+		}
+
+		// Без записи в БД вариант будет создан заново при следующем запросе
+		err = s.photoRepository.SavePhotoVersionRendition(ctx, &repoModel.SavePhotoVersionRenditionParams{
+			VersionID:    version.ID,
+			Format:       string(format),
+			UUIDFilename: filepath.Base(path),
+			Size:         int64(len(data)),
+		})
+		if err != nil {
+			logger.FromContext(ctx).Errorf("Failed to save rendition of photo version %d: %v", version.ID, err)
+		}
+
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := res.([]byte)
+	if len(data) == 0 || int64(len(data)) >= version.Size {
+		return nil, nil
+	}
+
+	return &serviceModel.PhotoFile{Data: data, ContentType: format.ContentType()}, nil
+}
+
+// renditionPath путь к варианту версии в формате format. Имя повторяет имя файла версии,
+// поэтому вариант, созданный заново, заменяет прежний.
+func (s *service) renditionPath(userUUID, uuidFilename string, format serviceModel.ImageFormat) string {
+	name := strings.TrimSuffix(uuidFilename, filepath.Ext(uuidFilename)) + format.Ext()
+	return filepath.Join(s.d.StorageFolderPath, RenditionsFolderName, userUUID, name)
+}
+
+// strippedRendition возвращает копию файла версии без метаданных, создавая ее при первом запросе.
+// JPEG и PNG очищаются без перекодирования, остальные форматы перекодируются.
+func (s *service) strippedRendition(ctx context.Context, userUUID string, version *repoModel.PhotoVersion, policy serviceModel.MetadataPolicy, mode imaging.StripMode) ([]byte, error) {
+	path := s.strippedRenditionPath(userUUID, version.UUIDFilename, policy)
+
+	res, err, _ := s.variants.Do(path, func() (any, error) {
+		_, err := s.photoRepository.GetPhotoVersionRendition(ctx, version.ID, string(policy))
+		switch {
+		case err == nil:
+			data, err := os.ReadFile(path)
+			if err == nil {
+				return data, nil
+			}
+			logger.FromContext(ctx).Warnf("Failed to read rendition %s, creating it again: %v", path, err)
+		case !errors.Is(err, repoErr.NotFoundError):
+			return nil, err
+		}
+
+		sourcePath := filepath.Join(s.d.StorageFolderPath, userUUID, version.UUIDFilename)
+		source, err := os.ReadFile(sourcePath)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := imaging.StripMetadata(source, mode)
+		if err != nil {
+			if !errors.Is(err, imaging.UnsupportedFormatError) {
+				logger.FromContext(ctx).Warnf("Failed to strip metadata of %s, re-encoding it: %v", sourcePath, err)
+			}
+			data, err = s.reencode(sourcePath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if err := writeFileAtomically(path, data); err != nil {
+			return nil, fmt.Errorf("failed to write rendition: %w", err)
+		}
+
+		// Без записи в БД копия будет создана заново при следующем запросе
+		err = s.photoRepository.SavePhotoVersionRendition(ctx, &repoModel.SavePhotoVersionRenditionParams{
+			VersionID:    version.ID,
+			Format:       string(policy),
+			UUIDFilename: filepath.Base(path),
+			Size:         int64(len(data)),
+		})
+		if err != nil {
+			logger.FromContext(ctx).Errorf("Failed to save rendition of photo version %d: %v", version.ID, err)
+		}
+
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.([]byte), nil
+}
+
+// reencode декодирует и заново кодирует файл в том же формате. Метаданные при этом не сохраняются.
+func (s *service) reencode(path string) ([]byte, error) {
+	img, err := decodeImageFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	format, _ := serviceModel.ParseImageFormat(filepath.Ext(path))
+	quality := publicJPEGQuality
+	if format == serviceModel.FormatWebP {
+		quality = s.d.WebP.Quality
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format.Ext(), quality); err != nil {
+		return nil, fmt.Errorf("failed to re-encode %s: %w", path, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// strippedRenditionPath путь к копии версии без метаданных по политике policy, в формате версии.
+func (s *service) strippedRenditionPath(userUUID, uuidFilename string, policy serviceModel.MetadataPolicy) string {
+	ext := filepath.Ext(uuidFilename)
+	name := strings.TrimSuffix(uuidFilename, ext) + "." + string(policy) + ext
+	return filepath.Join(s.d.StorageFolderPath, RenditionsFolderName, userUUID, name)
+}
+
+// removeRenditions удаляет файлы вариантов версий. Записи о них удаляются из БД вместе с версиями.
+func (s *service) removeRenditions(ctx context.Context, userUUID string, versions []repoModel.PhotoVersion) {
+	for _, v := range versions {
+		paths := []string{
+			s.renditionPath(userUUID, v.UUIDFilename, serviceModel.FormatWebP),
+			s.strippedRenditionPath(userUUID, v.UUIDFilename, serviceModel.MetadataStripPrivate),
+			s.strippedRenditionPath(userUUID, v.UUIDFilename, serviceModel.MetadataStripAll),
+		}
+		for _, path := range paths {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				logger.FromContext(ctx).Errorf("Failed to remove rendition %s: %v", path, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/config"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "golang.org/x/image/webp"
+)
+
+func TestService_GetPhotoFile(t *testing.T) {
+	const userUUID = "user-id"
+
+	// Размер версии в БД заведомо больше любого WebP варианта тестового изображения
+	original := repoModel.PhotoVersion{
+		ID: 10, PhotoID: 1, UUIDFilename: "original.png", Size: 1 << 20,
+		VersionType: sql.NullString{String: "original", Valid: true},
+	}
+	versions := []repoModel.PhotoVersion{original}
+	renditionFile := filepath.Join(RenditionsFolderName, userUUID, "original.webp")
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name                string
+		version             string
+		opts                serviceModel.FileOptions
+		cachedRendition     []byte
+		mockBehavior        mockBehavior
+		expectedContentType string
+		expectedData        []byte
+		expectedErr         error
+	}{
+		{
+			name:    "Stored file",
+			version: "original",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+			},
+			expectedContentType: "image/png",
+		},
+		{
+			name:    "WebP rendition created",
+			version: "original",
+			opts:    serviceModel.FileOptions{AcceptWebP: true},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+				repo.EXPECT().GetPhotoVersionRendition(gomock.Any(), original.ID, "webp").
+					Return(nil, repoErr.NotFoundError)
+				repo.EXPECT().SavePhotoVersionRendition(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, params *repoModel.SavePhotoVersionRenditionParams) error {
+						assert.Equal(t, original.ID, params.VersionID)
+						assert.Equal(t, "webp", params.Format)
+						assert.Equal(t, "original.webp", params.UUIDFilename)
+						return nil
+					})
+			},
+			expectedContentType: "image/webp",
+		},
+		{
+			name:            "WebP rendition from cache",
+			version:         "original",
+			opts:            serviceModel.FileOptions{AcceptWebP: true},
+			cachedRendition: []byte("cached"),
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+				repo.EXPECT().GetPhotoVersionRendition(gomock.Any(), original.ID, "webp").
+					Return(&repoModel.PhotoVersionRendition{VersionID: original.ID, Format: "webp", Size: 6}, nil)
+			},
+			expectedContentType: "image/webp",
+			expectedData:        []byte("cached"),
+		},
+		{
+			name:    "WebP rendition not smaller than stored file",
+			version: "original",
+			opts:    serviceModel.FileOptions{AcceptWebP: true},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+				repo.EXPECT().GetPhotoVersionRendition(gomock.Any(), original.ID, "webp").
+					Return(&repoModel.PhotoVersionRendition{VersionID: original.ID, Format: "webp", Size: original.Size}, nil)
+			},
+			expectedContentType: "image/png",
+		},
+		{
+			name:    "Rendition lookup failed",
+			version: "original",
+			opts:    serviceModel.FileOptions{AcceptWebP: true},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+				repo.EXPECT().GetPhotoVersionRendition(gomock.Any(), original.ID, "webp").
+					Return(nil, errors.New("connection refused"))
+			},
+			expectedContentType: "image/png",
+		},
+		{
+			name:    "Version not found",
+			version: "preview",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return(versions, nil)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+		{
+			name:    "Photo of another user",
+			version: "original",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: "other"}, nil)
+				repo.EXPECT().GetPhotoShare(gomock.Any(), 1, userUUID).Return(nil, repoErr.NotFoundError)
+				repo.EXPECT().GetContributedAlbums(gomock.Any(), 1).Return(nil, nil)
+				repo.EXPECT().GetSharedAlbums(gomock.Any(), userUUID).Return(nil, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+		{
+			name:         "Invalid version",
+			version:      "huge",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidVersionTypeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			storage := t.TempDir()
+			writeTestImage(t, filepath.Join(storage, userUUID, "original.png"), 64, 48)
+			if tt.cachedRendition != nil {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(storage, renditionFile)), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(storage, renditionFile), tt.cachedRendition, 0644))
+			}
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{
+				StorageFolderPath: storage,
+				WebP:              config.WebPSettings{Enabled: true},
+			}, mockRepo, nil)
+
+			file, err := s.GetPhotoFile(context.Background(), userUUID, 1, tt.version, tt.opts)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedContentType, file.ContentType)
+
+			if tt.expectedData != nil {
+				assert.Equal(t, tt.expectedData, file.Data)
+				return
+			}
+
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(file.Data))
+			require.NoError(t, err)
+			assert.Equal(t, "image/"+format, file.ContentType)
+			assert.Equal(t, image.Rect(0, 0, 64, 48), image.Rect(0, 0, cfg.Width, cfg.Height))
+			if format == "webp" {
+				assert.FileExists(t, filepath.Join(storage, renditionFile))
+			}
+		})
+	}
+}
+
+func TestService_GetPhotoFile_WebPDisabled(t *testing.T) {
+	const userUUID = "user-id"
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storage := t.TempDir()
+	writeTestImage(t, filepath.Join(storage, userUUID, "original.png"), 64, 48)
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+	mockRepo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return([]repoModel.PhotoVersion{{
+		ID: 10, PhotoID: 1, UUIDFilename: "original.png", Size: 1 << 20,
+		VersionType: sql.NullString{String: "original", Valid: true},
+	}}, nil)
+
+	s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+	file, err := s.GetPhotoFile(context.Background(), userUUID, 1, "original", serviceModel.FileOptions{AcceptWebP: true})
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", file.ContentType)
+	assert.NoDirExists(t, filepath.Join(storage, RenditionsFolderName))
+}
@@ -0,0 +1,294 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"go-photo/internal/utils"
+	"go-photo/pkg/imaging"
+	"go-photo/pkg/logger"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+func (s *service) GetResizedPhotoFileByToken(ctx context.Context, token string, opts serviceModel.ResizeOptions) (*serviceModel.PhotoFile, error) {
+	opts, err := s.resizeOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.photoRepository.GetPublicPhotoVersions(ctx, token)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	mark, err := s.publicWatermark(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var source repoModel.PhotoWithPhotoVersion
+	if opts.Width == 0 && opts.Height == 0 {
+		source, err = storedVersion(versions, opts.Version)
+		if err != nil {
+			return nil, err
+		}
+		opts.Width, opts.Height = source.Width, source.Height
+	} else {
+		source = resizeSource(versions, opts)
+	}
+	if opts.Format == "" {
+		opts.Format = s.variantFormat(source.UUIDFilename, opts.AcceptWebP)
+	}
+
+	data, err := s.cachedVariant(ctx, s.variantPath(source, token, opts, mark), func() ([]byte, error) {
+		return s.renderVariant(source, opts, mark)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", serviceErr.UnexpectedError, err)
+	}
+
+	return &serviceModel.PhotoFile{
+		Data:        data,
+		ContentType: opts.Format.ContentType(),
+	}, nil
+}
+
+// cachedVariant возвращает вариант из кэша по пути path или создает его функцией render и кэширует.
+// Одновременные запросы одного варианта создают его один раз.
+func (s *service) cachedVariant(ctx context.Context, path string, render func() ([]byte, error)) ([]byte, error) {
+	res, err, _ := s.variants.Do(path, func() (any, error) {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.FromContext(ctx).Warnf("Failed to read cached variant %s: %v", path, err)
+		}
+
+		data, err = render()
+		if err != nil {
+			return nil, err
+		}
+
+		// Вариант отдается, даже если его не удалось закэшировать
+		if err := writeFileAtomically(path, data); err != nil {
+			logger.FromContext(ctx).Errorf("Failed to cache variant %s: %v", path, err)
+		}
+
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.([]byte), nil
+}
+
+// resizeOptions проверяет параметры по разрешенным в конфигурации и заполняет значения по умолчанию.
+func (s *service) resizeOptions(opts serviceModel.ResizeOptions) (serviceModel.ResizeOptions, error) {
+	// Без размера меняются только формат и качество сохраненной версии, новых размеров в кэше не появляется
+	if opts.Width != 0 || opts.Height != 0 {
+		size := config.ImageSize{Width: opts.Width, Height: opts.Height}
+		if !slices.Contains(s.d.Resize.Sizes, size) {
+			return opts, fmt.Errorf("%w: size %dx%d is not allowed", serviceErr.InvalidResizeParamsError, opts.Width, opts.Height)
+		}
+	} else {
+		// Пустой список размеров отключает и перекодирование без изменения размера
+		if len(s.d.Resize.Sizes) == 0 {
+			return opts, fmt.Errorf("%w: resizing is disabled", serviceErr.InvalidResizeParamsError)
+		}
+		if opts.Version == "" {
+			opts.Version = string(model.Original)
+		}
+		if _, err := model.ParseVersionType(opts.Version); err != nil {
+			return opts, serviceErr.InvalidVersionTypeError
+		}
+	}
+
+	if opts.Quality == 0 && len(s.d.Resize.Qualities) > 0 {
+		opts.Quality = s.d.Resize.Qualities[0]
+	}
+	if !slices.Contains(s.d.Resize.Qualities, opts.Quality) {
+		return opts, fmt.Errorf("%w: quality %d is not allowed", serviceErr.InvalidResizeParamsError, opts.Quality)
+	}
+
+	switch opts.Fit {
+	case "":
+		opts.Fit = serviceModel.FitContain
+	case serviceModel.FitContain, serviceModel.FitCover:
+	default:
+		return opts, fmt.Errorf("%w: unknown fit mode %q", serviceErr.InvalidResizeParamsError, opts.Fit)
+	}
+
+	if opts.Format != "" {
+		if _, ok := serviceModel.ParseImageFormat(string(opts.Format)); !ok {
+			return opts, fmt.Errorf("%w: unsupported format %q", serviceErr.InvalidResizeParamsError, opts.Format)
+		}
+	}
+
+	return opts, nil
+}
+
+// variantFormat выбирает формат варианта, если клиент его не указал: WebP, если клиент его принимает,
+// иначе формат исходной версии. Клиенту без поддержки WebP исходная версия в WebP отдается в JPEG.
+func (s *service) variantFormat(uuidFilename string, acceptWebP bool) serviceModel.ImageFormat {
+	if acceptWebP && s.d.WebP.Enabled {
+		return serviceModel.FormatWebP
+	}
+
+	format, ok := serviceModel.ParseImageFormat(filepath.Ext(uuidFilename))
+	if !ok || format == serviceModel.FormatWebP && !acceptWebP {
+		return serviceModel.FormatJPEG
+	}
+
+	return format
+}
+
+// resizeSource выбирает наименьшую версию, из которой вариант получается без увеличения,
+// или оригинал, если такой версии нет.
+func resizeSource(versions []repoModel.PhotoWithPhotoVersion, opts serviceModel.ResizeOptions) repoModel.PhotoWithPhotoVersion {
+	var best *repoModel.PhotoWithPhotoVersion
+	var original repoModel.PhotoWithPhotoVersion
+
+	for i, v := range versions {
+		if v.VersionType.String == string(model.Original) {
+			original = v
+		}
+		// Отредактированная версия может быть обрезана или повернута относительно оригинала
+		if v.VersionType.String == string(model.Edited) {
+			continue
+		}
+
+		// contain: достаточно одной стороны не меньше области, cover: нужны обе
+		covers := v.Width >= opts.Width || v.Height >= opts.Height
+		if opts.Fit == serviceModel.FitCover {
+			covers = v.Width >= opts.Width && v.Height >= opts.Height
+		}
+		if covers && (best == nil || v.Width*v.Height < best.Width*best.Height) {
+			best = &versions[i]
+		}
+	}
+
+	if best == nil {
+		if original.UUIDFilename == "" {
+			return versions[0]
+		}
+		return original
+	}
+
+	return *best
+}
+
+// storedVersion возвращает сохраненную версию фото по типу. Если такой версии нет, возвращает PhotoNotFoundError.
+func storedVersion(versions []repoModel.PhotoWithPhotoVersion, version string) (repoModel.PhotoWithPhotoVersion, error) {
+	for _, v := range versions {
+		if v.VersionType.String == version {
+			return v, nil
+		}
+	}
+	return repoModel.PhotoWithPhotoVersion{}, fmt.Errorf("%w: no %s version", serviceErr.PhotoNotFoundError, version)
+}
+
+// renderVariant создает вариант исходной версии. Водяной знак mark, если задан, накладывается после изменения размера.
+func (s *service) renderVariant(source repoModel.PhotoWithPhotoVersion, opts serviceModel.ResizeOptions, mark *repoModel.WatermarkSettings) ([]byte, error) {
+	img, err := decodeImageFile(filepath.Join(s.d.StorageFolderPath, source.UserUUID, source.UUIDFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Fit == serviceModel.FitCover {
+		img = imaging.Cover(img, opts.Width, opts.Height)
+	} else {
+		img = imaging.Fit(img, opts.Width, opts.Height)
+	}
+
+	if mark != nil {
+		img, err = s.applyWatermark(img, mark)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, opts.Format.Ext(), opts.Quality); err != nil {
+		return nil, fmt.Errorf("failed to encode variant: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeImageFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source version: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode source version: %w", err)
+	}
+
+	return img, nil
+}
+
+// variantPath путь к варианту в кэше. Ключ включает файл исходной версии и время изменения
+// водяного знака, поэтому при замене версии или знака старые варианты перестают использоваться.
+func (s *service) variantPath(source repoModel.PhotoWithPhotoVersion, token string, opts serviceModel.ResizeOptions, mark *repoModel.WatermarkSettings) string {
+	key := sha256.Sum256([]byte(token + "\x00" + source.UUIDFilename + "\x00" +
+		strconv.Itoa(opts.Width) + "x" + strconv.Itoa(opts.Height) + "\x00" +
+		string(opts.Fit) + "\x00" + strconv.Itoa(opts.Quality) + watermarkKey(mark)))
+
+	return filepath.Join(s.variantsFolder(source.PhotoID), hex.EncodeToString(key[:16])+opts.Format.Ext())
+}
+
+func (s *service) variantsFolder(photoID int) string {
+	return filepath.Join(s.d.StorageFolderPath, VariantsFolderName, strconv.Itoa(photoID))
+}
+
+// removeVariants удаляет кэш вариантов фото. Ошибка только логируется: устаревший кэш не отдается
+// после отмены публикации, потому что путь к вариантам зависит от токена.
+func (s *service) removeVariants(ctx context.Context, photoID int) {
+	if err := os.RemoveAll(s.variantsFolder(photoID)); err != nil {
+		logger.FromContext(ctx).Errorf("Failed to remove variants of photo %d: %v", photoID, err)
+	}
+}
+
+// writeFileAtomically записывает данные во временный файл рядом с path и переименовывает его,
+// чтобы читатели никогда не видели недописанный файл.
+func writeFileAtomically(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := utils.EnsureDirectoryExists(dir); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".variant-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
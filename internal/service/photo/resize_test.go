@@ -0,0 +1,262 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/config"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "golang.org/x/image/webp"
+)
+
+func writeTestImage(t *testing.T, path string, width, height int) {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestService_GetResizedPhotoFileByToken(t *testing.T) {
+	const (
+		userUUID = "user-id"
+		token    = "token"
+	)
+
+	resize := config.ResizeSettings{
+		Sizes:     []config.ImageSize{{Width: 100, Height: 100}, {Width: 300, Height: 300}},
+		Qualities: []int{85, 60},
+	}
+	webp := config.WebPSettings{Enabled: true, Quality: config.DefaultWebPQuality}
+
+	// Оригинал 400x200 и превью 200x100
+	versions := []repoModel.PhotoWithPhotoVersion{
+		{
+			PhotoID: 1, UserUUID: userUUID, Filename: "a.png", UUIDFilename: "original.png",
+			VersionType: sql.NullString{String: "original", Valid: true}, Width: 400, Height: 200,
+		},
+		{
+			PhotoID: 1, UserUUID: userUUID, Filename: "a.png", UUIDFilename: "preview.png",
+			VersionType: sql.NullString{String: "preview", Valid: true}, Width: 200, Height: 100,
+		},
+	}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name                string
+		opts                serviceModel.ResizeOptions
+		mockBehavior        mockBehavior
+		expectedContentType string
+		expectedBounds      image.Rectangle
+		expectedErr         error
+	}{
+		{
+			name: "Contain from preview",
+			opts: serviceModel.ResizeOptions{Width: 100, Height: 100},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublicPhotoVersions(gomock.Any(), token).Return(versions, nil)
+			},
+			expectedContentType: "image/png",
+			expectedBounds:      image.Rect(0, 0, 100, 50),
+		},
+		{
+			name: "Cover from original",
+			opts: serviceModel.ResizeOptions{Width: 300, Height: 300, Fit: serviceModel.FitCover},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublicPhotoVersions(gomock.Any(), token).Return(versions, nil)
+			},
+			expectedContentType: "image/png",
+			// Без увеличения: по ширине обрезается до области, высота остается как у оригинала
+			expectedBounds: image.Rect(0, 0, 300, 200),
+		},
+		{
+			name: "Converted to jpeg",
+			opts: serviceModel.ResizeOptions{Width: 100, Height: 100, Quality: 60, Format: serviceModel.FormatJPEG},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublicPhotoVersions(gomock.Any(), token).Return(versions, nil)
+			},
+			expectedContentType: "image/jpeg",
+			expectedBounds:      image.Rect(0, 0, 100, 50),
+		},
+		{
+			name: "WebP accepted",
+			opts: serviceModel.ResizeOptions{Width: 100, Height: 100, AcceptWebP: true},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublicPhotoVersions(gomock.Any(), token).Return(versions, nil)
+			},
+			expectedContentType: "image/webp",
+			expectedBounds:      image.Rect(0, 0, 100, 50),
+		},
+		{
+			name: "Explicit format over WebP",
+			opts: serviceModel.ResizeOptions{Width: 100, Height: 100, Format: serviceModel.FormatPNG, AcceptWebP: true},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublicPhotoVersions(gomock.Any(), token).Return(versions, nil)
+			},
+			expectedContentType: "image/png",
+			expectedBounds:      image.Rect(0, 0, 100, 50),
+		},
+		{
+			name: "Format only keeps original size",
+			opts: serviceModel.ResizeOptions{Format: serviceModel.FormatWebP},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublicPhotoVersions(gomock.Any(), token).Return(versions, nil)
+			},
+			expectedContentType: "image/webp",
+			expectedBounds:      image.Rect(0, 0, 400, 200),
+		},
+		{
+			name: "Quality only keeps version size",
+			opts: serviceModel.ResizeOptions{Quality: 60, Version: "preview"},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublicPhotoVersions(gomock.Any(), token).Return(versions, nil)
+			},
+			expectedContentType: "image/png",
+			expectedBounds:      image.Rect(0, 0, 200, 100),
+		},
+		{
+			name:         "Quality only not allowed",
+			opts:         serviceModel.ResizeOptions{Quality: 100},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidResizeParamsError,
+		},
+		{
+			name:         "Format only with invalid version",
+			opts:         serviceModel.ResizeOptions{Format: serviceModel.FormatPNG, Version: "huge"},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidVersionTypeError,
+		},
+		{
+			name: "Format only without version",
+			opts: serviceModel.ResizeOptions{Format: serviceModel.FormatPNG, Version: "thumbnail"},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublicPhotoVersions(gomock.Any(), token).Return(versions, nil)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+		{
+			name:         "Size not allowed",
+			opts:         serviceModel.ResizeOptions{Width: 101, Height: 100},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidResizeParamsError,
+		},
+		{
+			name:         "Quality not allowed",
+			opts:         serviceModel.ResizeOptions{Width: 100, Height: 100, Quality: 100},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidResizeParamsError,
+		},
+		{
+			name:         "Unknown fit",
+			opts:         serviceModel.ResizeOptions{Width: 100, Height: 100, Fit: "stretch"},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidResizeParamsError,
+		},
+		{
+			name:         "Unsupported format",
+			opts:         serviceModel.ResizeOptions{Width: 100, Height: 100, Format: "bmp"},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidResizeParamsError,
+		},
+		{
+			name: "Photo not found",
+			opts: serviceModel.ResizeOptions{Width: 100, Height: 100},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPublicPhotoVersions(gomock.Any(), token).Return(nil, repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			storage := t.TempDir()
+			writeTestImage(t, filepath.Join(storage, userUUID, "original.png"), 400, 200)
+			writeTestImage(t, filepath.Join(storage, userUUID, "preview.png"), 200, 100)
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+			// Фото опубликовано без водяного знака
+			mockRepo.EXPECT().GetWatermarkByToken(gomock.Any(), token).Return(nil, repoErr.NotFoundError).AnyTimes()
+
+			s := NewService(Deps{StorageFolderPath: storage, Resize: resize, WebP: webp}, mockRepo, nil)
+
+			file, err := s.GetResizedPhotoFileByToken(context.Background(), token, tt.opts)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedContentType, file.ContentType)
+
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(file.Data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBounds, image.Rect(0, 0, cfg.Width, cfg.Height))
+		})
+	}
+}
+
+func TestService_GetResizedPhotoFileByToken_Cache(t *testing.T) {
+	const userUUID = "user-id"
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storage := t.TempDir()
+	source := filepath.Join(storage, userUUID, "original.png")
+	writeTestImage(t, source, 400, 200)
+
+	versions := []repoModel.PhotoWithPhotoVersion{{
+		PhotoID: 1, UserUUID: userUUID, UUIDFilename: "original.png",
+		VersionType: sql.NullString{String: "original", Valid: true}, Width: 400, Height: 200,
+	}}
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetPublicPhotoVersions(gomock.Any(), "token").Return(versions, nil).Times(2)
+	mockRepo.EXPECT().GetWatermarkByToken(gomock.Any(), "token").Return(nil, repoErr.NotFoundError).Times(2)
+	mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+	mockRepo.EXPECT().DeletePhotoPublishedInfo(gomock.Any(), 1).Return(nil)
+
+	s := NewService(Deps{
+		StorageFolderPath: storage,
+		Resize:            config.ResizeSettings{Sizes: []config.ImageSize{{Width: 100, Height: 100}}, Qualities: []int{85}},
+	}, mockRepo, nil)
+	opts := serviceModel.ResizeOptions{Width: 100, Height: 100}
+
+	first, err := s.GetResizedPhotoFileByToken(context.Background(), "token", opts)
+	require.NoError(t, err)
+
+	// Повторный запрос отдается из кэша без чтения исходной версии
+	require.NoError(t, os.Remove(source))
+	second, err := s.GetResizedPhotoFileByToken(context.Background(), "token", opts)
+	require.NoError(t, err)
+	assert.Equal(t, first.Data, second.Data)
+
+	// Отмена публикации удаляет кэш вариантов
+	require.NoError(t, s.UnpublishPhoto(context.Background(), userUUID, 1))
+	assert.NoDirExists(t, filepath.Join(storage, VariantsFolderName, "1"))
+}
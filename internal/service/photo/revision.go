@@ -0,0 +1,180 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	"go-photo/internal/repository/photo/converter"
+	repoModel "go-photo/internal/repository/photo/model"
+	"go-photo/internal/utils"
+	"go-photo/pkg/imaging"
+	"go-photo/pkg/logger"
+	"image"
+	"mime/multipart"
+	"path/filepath"
+	"time"
+)
+
+func (s *service) UploadPhotoRevision(ctx context.Context, userUUID string, photoID int, file *multipart.FileHeader) (*model.PhotoVersion, error) {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	userFolder, err := ensureUserFolder(s.d.StorageFolderPath, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.EnsureDirectoryExists(s.stagingFolder()); err != nil {
+		return nil, fmt.Errorf("failed to ensure staging folder exists: %w", err)
+	}
+
+	// Файл ревизии журналируется так же, как загрузка нового фото:
+	// прерванную загрузку доведет до конца или откатит reconciler
+	info := s.stageFile(ctx, userUUID, file)
+	if info.Error != nil {
+		return nil, info.Error
+	}
+
+	current, _, err := s.photoRepository.CreatePhotoRevision(ctx, &repoModel.CreatePhotoRevisionParams{
+		PhotoID:         photo.ID,
+		UUIDFilename:    info.UUIDFilename,
+		Size:            info.Size,
+		Height:          info.Height,
+		Width:           info.Width,
+		SavedAt:         info.SavedAt,
+		Checksum:        info.Checksum,
+		PendingUploadID: info.PendingUploadID,
+	})
+	if err != nil {
+		s.abandonUpload(ctx, info)
+		return nil, s.HandleRepoErr(ctx, err)
+	}
+
+	s.finishUpload(ctx, userFolder, info)
+	s.refreshDerived(ctx, photo, current)
+
+	res := converter.ToPhotoVersionFromRepo(*current)
+	return &res, nil
+}
+
+func (s *service) GetPhotoRevisions(ctx context.Context, userUUID string, photoID int) ([]model.PhotoVersion, error) {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.photoRepository.GetPhotoRevisions(ctx, photo.ID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return converter.ToPhotoVersionsFromRepo(revisions), nil
+}
+
+func (s *service) RestorePhotoRevision(ctx context.Context, userUUID string, photoID, revision int) (*model.PhotoVersion, error) {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	current, previous, err := s.photoRepository.RestorePhotoRevision(ctx, photo.ID, revision)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	// Ревизия уже была текущей: производные версии получены из нее
+	if previous != nil {
+		s.refreshDerived(ctx, photo, current)
+	}
+
+	res := converter.ToPhotoVersionFromRepo(*current)
+	return &res, nil
+}
+
+// refreshDerived пересоздает производные версии фото из текущей ревизии оригинала под прежними именами
+// и сбрасывает кэш вариантов публичного фото. Отредактированная версия не пересоздается:
+// ее можно пересоздать по рецепту из новой ревизии через ReapplyPhotoEdit.
+// Ревизия к этому моменту уже сохранена, поэтому ошибки только логируются.
+func (s *service) refreshDerived(ctx context.Context, photo *repoModel.Photo, current *repoModel.PhotoVersion) {
+	log := logger.FromContext(ctx)
+
+	s.removeVariants(ctx, photo.ID)
+
+	versions, err := s.photoRepository.GetPhotoVersions(ctx, photo.ID)
+	if err != nil {
+		log.Errorf("Failed to get versions of photo %d to refresh them: %v", photo.ID, err)
+		return
+	}
+
+	var img image.Image
+	for _, v := range versions {
+		preset, ok := s.derivedPreset(v)
+		if !ok {
+			continue
+		}
+
+		if img == nil {
+			img, err = decodeImageFile(filepath.Join(s.d.StorageFolderPath, photo.UserUUID, current.UUIDFilename))
+			if err != nil {
+				log.Errorf("Failed to decode revision %d of photo %d: %v", current.Revision.Int64, photo.ID, err)
+				return
+			}
+		}
+
+		if err := s.rederive(ctx, photo.UserUUID, v, img, preset); err != nil {
+			log.Errorf("Failed to refresh %s version of photo %d: %v", v.VersionType.String, photo.ID, err)
+		}
+	}
+}
+
+// derivedPreset возвращает пресет, по которому получена версия.
+// Оригинал, его ревизии и отредактированная версия не получаются по пресету.
+func (s *service) derivedPreset(v repoModel.PhotoVersion) (config.VersionPreset, bool) {
+	switch model.PhotoVersionType(v.VersionType.String) {
+	case "", model.Original, model.Revision, model.Edited:
+		return config.VersionPreset{}, false
+	}
+
+	for _, p := range s.d.Versions {
+		if p.Name == v.VersionType.String {
+			return p, true
+		}
+	}
+	return config.VersionPreset{}, false
+}
+
+// rederive заново записывает файл версии из изображения оригинала по пресету и обновляет версию в БД.
+func (s *service) rederive(ctx context.Context, userUUID string, v repoModel.PhotoVersion, original image.Image, preset config.VersionPreset) error {
+	img := imaging.Fit(original, preset.Width, preset.Height)
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, filepath.Ext(v.UUIDFilename), preset.Quality); err != nil {
+		return fmt.Errorf("failed to encode version: %w", err)
+	}
+	data := buf.Bytes()
+
+	if err := writeFileAtomically(filepath.Join(s.d.StorageFolderPath, userUUID, v.UUIDFilename), data); err != nil {
+		return fmt.Errorf("failed to write version: %w", err)
+	}
+
+	checksum := utils.NewChecksum()
+	checksum.Write(data)
+
+	// Записи о вариантах версии в других форматах удаляются вместе с обновлением файла
+	err := s.photoRepository.UpdatePhotoVersionFile(ctx, v.ID, &repoModel.UpdatePhotoVersionFileParams{
+		Size:     int64(len(data)),
+		Height:   img.Bounds().Dy(),
+		Width:    img.Bounds().Dx(),
+		Checksum: utils.FormatChecksum(checksum),
+		SavedAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.removeRenditions(ctx, userUUID, []repoModel.PhotoVersion{v})
+	return nil
+}
@@ -0,0 +1,210 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testVersionPresets = []config.VersionPreset{{Name: "thumbnail", Width: 8, Height: 8, Quality: 80}}
+
+func TestService_UploadPhotoRevision(t *testing.T) {
+	const userUUID = "user-id"
+
+	photo := &repoModel.Photo{ID: 1, UserUUID: userUUID}
+	previous := &repoModel.PhotoVersion{
+		ID: 10, PhotoID: 1, VersionType: sql.NullString{String: "revision", Valid: true}, UUIDFilename: "rev1.png",
+		Revision: sql.NullInt64{Int64: 1, Valid: true},
+	}
+	thumbnail := repoModel.PhotoVersion{ID: 11, PhotoID: 1, VersionType: sql.NullString{String: "thumbnail", Valid: true}, UUIDFilename: "thumb.png"}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		userUUID     string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:     "Valid",
+			userUUID: userUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(photo, nil)
+				repo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(3, nil)
+				var current *repoModel.PhotoVersion
+				repo.EXPECT().CreatePhotoRevision(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, params *repoModel.CreatePhotoRevisionParams) (*repoModel.PhotoVersion, *repoModel.PhotoVersion, error) {
+						assert.Equal(t, 1, params.PhotoID)
+						assert.Equal(t, 3, params.PendingUploadID)
+						assert.Equal(t, 1, params.Width)
+						assert.NotEmpty(t, params.Checksum)
+						current = &repoModel.PhotoVersion{
+							ID: 12, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true},
+							UUIDFilename: params.UUIDFilename, Size: params.Size, Height: params.Height, Width: params.Width,
+							SavedAt: &sql.NullTime{Time: params.SavedAt, Valid: true}, Revision: sql.NullInt64{Int64: 2, Valid: true},
+						}
+						return current, previous, nil
+					})
+				repo.EXPECT().DeletePendingUpload(gomock.Any(), 3).Return(nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).
+					DoAndReturn(func(context.Context, int) ([]repoModel.PhotoVersion, error) {
+						return []repoModel.PhotoVersion{*previous, thumbnail, *current}, nil
+					})
+				repo.EXPECT().UpdatePhotoVersionFile(gomock.Any(), 11, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int, params *repoModel.UpdatePhotoVersionFileParams) error {
+						assert.Equal(t, 1, params.Width)
+						assert.Equal(t, 1, params.Height)
+						return nil
+					})
+			},
+		},
+		{
+			name:     "Photo deleted concurrently",
+			userUUID: userUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(photo, nil)
+				repo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(3, nil)
+				repo.EXPECT().CreatePhotoRevision(gomock.Any(), gomock.Any()).Return(nil, nil, repoErr.NotFoundError)
+				repo.EXPECT().DeleteUncommittedPendingUpload(gomock.Any(), 3).Return(nil)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+		{
+			name:     "Access denied",
+			userUUID: "other-user",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(photo, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := t.TempDir()
+			userDir := filepath.Join(storage, userUUID)
+			writeTestImage(t, filepath.Join(userDir, "rev1.png"), 40, 20)
+			writeTestImage(t, filepath.Join(userDir, "thumb.png"), 8, 4)
+			variant := filepath.Join(storage, VariantsFolderName, "1", "cached.png")
+			writeTestImage(t, variant, 4, 4)
+
+			mockRepo := mock_repository.NewMockPhotoRepository(ctrl)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: storage, Versions: testVersionPresets}, mockRepo, nil)
+
+			revision, err := s.UploadPhotoRevision(context.Background(), tt.userUUID, 1, mockFileHeader("new.jpg", 0, ""))
+
+			staged, readErr := os.ReadDir(filepath.Join(storage, StagingFolderName))
+			if readErr == nil {
+				assert.Empty(t, staged)
+			}
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 2, revision.Revision)
+			assert.Equal(t, model.Original, revision.VersionType)
+			assert.FileExists(t, filepath.Join(userDir, revision.UUIDFilename))
+			assert.FileExists(t, filepath.Join(userDir, "rev1.png"))
+			assert.NoFileExists(t, variant)
+
+			// Миниатюра пересоздана из новой ревизии 1x1
+			thumb, err := decodeImageFile(filepath.Join(userDir, "thumb.png"))
+			require.NoError(t, err)
+			assert.Equal(t, 1, thumb.Bounds().Dx())
+		})
+	}
+}
+
+func TestService_RestorePhotoRevision(t *testing.T) {
+	const userUUID = "user-id"
+
+	restored := &repoModel.PhotoVersion{
+		ID: 10, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "rev1.png",
+		SavedAt: &sql.NullTime{}, Revision: sql.NullInt64{Int64: 1, Valid: true},
+	}
+	previous := &repoModel.PhotoVersion{ID: 12, PhotoID: 1, VersionType: sql.NullString{String: "revision", Valid: true}, UUIDFilename: "rev2.png"}
+	thumbnail := repoModel.PhotoVersion{ID: 11, PhotoID: 1, VersionType: sql.NullString{String: "thumbnail", Valid: true}, UUIDFilename: "thumb.png"}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "Restored",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().RestorePhotoRevision(gomock.Any(), 1, 1).Return(restored, previous, nil)
+				repo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return([]repoModel.PhotoVersion{*restored, thumbnail, *previous}, nil)
+				repo.EXPECT().UpdatePhotoVersionFile(gomock.Any(), 11, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int, params *repoModel.UpdatePhotoVersionFileParams) error {
+						// Оригинал 40x20 вписывается в 8x8
+						assert.Equal(t, 8, params.Width)
+						assert.Equal(t, 4, params.Height)
+						return nil
+					})
+			},
+		},
+		{
+			name: "Already current",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().RestorePhotoRevision(gomock.Any(), 1, 1).Return(restored, nil, nil)
+			},
+		},
+		{
+			name: "Revision not found",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().RestorePhotoRevision(gomock.Any(), 1, 1).Return(nil, nil, repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := t.TempDir()
+			writeTestImage(t, filepath.Join(storage, userUUID, "rev1.png"), 40, 20)
+			writeTestImage(t, filepath.Join(storage, userUUID, "thumb.png"), 2, 2)
+
+			mockRepo := mock_repository.NewMockPhotoRepository(ctrl)
+			mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: storage, Versions: testVersionPresets}, mockRepo, nil)
+
+			revision, err := s.RestorePhotoRevision(context.Background(), userUUID, 1, 1)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, revision.Revision)
+			assert.Equal(t, model.Original, revision.VersionType)
+		})
+	}
+}
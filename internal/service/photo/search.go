@@ -0,0 +1,55 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	"go-photo/internal/repository/photo/converter"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"strings"
+	"unicode"
+)
+
+func (s *service) SearchPhotos(ctx context.Context, userUUID string, query string, filter serviceModel.PhotoFilter) ([]model.FoundPhoto, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: search query must contain letters or digits", serviceErr.InvalidFilterError)
+	}
+	if len(terms) > config.MaxSearchTerms {
+		return nil, fmt.Errorf("%w: search query must contain at most %d words", serviceErr.InvalidFilterError, config.MaxSearchTerms)
+	}
+
+	listParams, err := toPhotoListParams(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	photos, err := s.photoRepository.SearchUserPhotos(ctx, userUUID, &repoModel.PhotoSearchParams{
+		Terms:           terms,
+		PhotoListParams: *listParams,
+	})
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return converter.ToFoundPhotosFromRepo(photos), nil
+}
+
+// searchTerms разбивает запрос на слова из букв и цифр в нижнем регистре без повторов.
+// Остальные символы разделяют слова: IMG_1234.jpg ищется как img, 1234 и jpg.
+func searchTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
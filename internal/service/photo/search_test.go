@@ -0,0 +1,92 @@
+package photo
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestService_SearchPhotos(t *testing.T) {
+	const userUUID = "user-id"
+	favorite := true
+	manyWords := make([]string, config.MaxSearchTerms+1)
+	for i := range manyWords {
+		manyWords[i] = "w" + strconv.Itoa(i)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		filter         serviceModel.PhotoFilter
+		expectedParams *repoModel.PhotoSearchParams
+		expectedErr    error
+	}{
+		{
+			name:  "Words of filename",
+			query: "  IMG_1234.JPG img",
+			expectedParams: &repoModel.PhotoSearchParams{
+				Terms:           []string{"img", "1234", "jpg"},
+				PhotoListParams: repoModel.PhotoListParams{Limit: config.DefaultPhotoListLimit},
+			},
+		},
+		{
+			name:   "With list filters",
+			query:  "Отпуск, море!",
+			filter: serviceModel.PhotoFilter{Favorite: &favorite, Limit: 10, Offset: 20},
+			expectedParams: &repoModel.PhotoSearchParams{
+				Terms:           []string{"отпуск", "море"},
+				PhotoListParams: repoModel.PhotoListParams{Favorite: &favorite, Limit: 10, Offset: 20},
+			},
+		},
+		{
+			name:        "Only tsquery operators",
+			query:       "!& | <->",
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Too many words",
+			query:       strings.Join(manyWords, " "),
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Invalid filter",
+			query:       "canon",
+			filter:      serviceModel.PhotoFilter{Limit: config.MaxPhotoListLimit + 1},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			if tt.expectedParams != nil {
+				mockRepo.EXPECT().SearchUserPhotos(gomock.Any(), userUUID, tt.expectedParams).
+					Return([]repoModel.FoundPhoto{{Photo: repoModel.Photo{ID: 1, UserUUID: userUUID}, Rank: 0.5, Highlight: "<b>img</b>"}}, nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			photos, err := s.SearchPhotos(context.Background(), userUUID, tt.query, tt.filter)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []model.FoundPhoto{{Photo: model.Photo{ID: 1, UserUUID: userUUID}, Rank: 0.5, Highlight: "<b>img</b>"}}, photos)
+		})
+	}
+}
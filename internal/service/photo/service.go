@@ -1,28 +1,91 @@
 package photo
 
 import (
+	"go-photo/internal/config"
 	"go-photo/internal/repository"
 	def "go-photo/internal/service"
 	"go-photo/internal/utils"
+	"go-photo/pkg/executor"
+	"golang.org/x/sync/singleflight"
+	"path/filepath"
+	"time"
 )
 
 // Проверка на соответствие интерфейсу UserService (для статической проверки)
 var _ def.PhotoService = (*service)(nil)
 
+const tracerName = "go-photo/internal/service/photo"
+
 type Deps struct {
 	// абсолютный путь к папке с фотографиями
 	StorageFolderPath string
+	// FileExecutor записывает загружаемые файлы на диск, DBExecutor сохраняет их в БД.
+	// Общие на весь процесс, ограничивают конкурентность и глубину очереди загрузок.
+	// Если не заданы, создаются с одним воркером и без ограничения очереди.
+	FileExecutor *executor.Executor
+	DBExecutor   *executor.Executor
+	// StaleUploadAfter возраст незавершенной загрузки, после которого reconciler считает ее прерванной.
+	StaleUploadAfter time.Duration
+	// TrashRetention сколько фото хранится в корзине до окончательного удаления.
+	TrashRetention time.Duration
+	// Resize разрешенные размеры и качества публичных фото. Пустой список размеров отключает изменение размера.
+	Resize config.ResizeSettings
+	// WebP настройки выдачи фото в WebP клиентам, которые его принимают.
+	WebP config.WebPSettings
+	// Versions пресеты производных версий. Существующие производные версии фото пересоздаются
+	// по ним из оригинала, когда меняется его текущая ревизия.
+	Versions []config.VersionPreset
 }
 
+// StagingFolderName папка внутри хранилища для файлов, загрузка которых еще не закоммичена.
+// Находится на той же файловой системе, что и папки пользователей, поэтому перенос атомарный.
+const StagingFolderName = ".staging"
+
+// VariantsFolderName папка внутри хранилища для кэша производных вариантов публичных фото.
+// Кэш можно удалить целиком: варианты будут созданы заново при следующем запросе.
+const VariantsFolderName = ".variants"
+
+// RenditionsFolderName папка внутри хранилища для версий фото, перекодированных в другой формат
+// (по подпапке на пользователя). Записи о них хранятся в БД вместе с версиями.
+const RenditionsFolderName = ".renditions"
+
+// WatermarksFolderName папка внутри хранилища для логотипов водяных знаков (по подпапке на пользователя).
+const WatermarksFolderName = ".watermarks"
+
 type service struct {
 	d               Deps
 	utils           utils.Interface
 	photoRepository repository.PhotoRepository
+	// variants объединяет одновременные запросы одного некэшированного варианта
+	variants singleflight.Group
 }
 
 func NewService(d Deps, photoRepository repository.PhotoRepository, u utils.Interface) *service {
 	if u == nil {
 		u = utils.New()
 	}
+	if d.FileExecutor == nil {
+		d.FileExecutor = executor.New(executor.Config{Workers: 1})
+	}
+	if d.DBExecutor == nil {
+		d.DBExecutor = executor.New(executor.Config{Workers: 1})
+	}
+	if d.StaleUploadAfter <= 0 {
+		d.StaleUploadAfter = config.DefaultUploadStaleAfter
+	}
+	if d.TrashRetention <= 0 {
+		d.TrashRetention = config.DefaultTrashRetention
+	}
+	if d.WebP.Quality <= 0 {
+		d.WebP.Quality = config.DefaultWebPQuality
+	}
 	return &service{d: d, utils: u, photoRepository: photoRepository}
 }
+
+func (s *service) stagingFolder() string {
+	return filepath.Join(s.d.StorageFolderPath, StagingFolderName)
+}
+
+func (s *service) stagedFilePath(uuidFilename string) string {
+	return filepath.Join(s.stagingFolder(), uuidFilename)
+}
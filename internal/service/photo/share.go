@@ -0,0 +1,199 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	repoErr "go-photo/internal/repository/error"
+	"go-photo/internal/repository/photo/converter"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+)
+
+func (s *service) SharePhoto(ctx context.Context, userUUID string, photoID int, granteeUUID string, role string) (*serviceModel.Share, error) {
+	params, err := toSaveShareParams(userUUID, granteeUUID, role)
+	if err != nil {
+		return nil, err
+	}
+	if params.Role != string(serviceModel.ShareViewer) {
+		return nil, fmt.Errorf("%w: only %s role can be granted on a photo", serviceErr.InvalidShareError, serviceModel.ShareViewer)
+	}
+
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	share, err := s.photoRepository.SavePhotoShare(ctx, photo.ID, params)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toShare(share), nil
+}
+
+func (s *service) UnsharePhoto(ctx context.Context, userUUID string, photoID int, granteeUUID string) error {
+	grantee, err := parseGrantee(granteeUUID)
+	if err != nil {
+		return err
+	}
+
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return err
+	}
+
+	err = s.photoRepository.DeletePhotoShare(ctx, photo.ID, grantee)
+	return s.handleShareRepoErr(ctx, err)
+}
+
+func (s *service) GetPhotoShares(ctx context.Context, userUUID string, photoID int) ([]serviceModel.Share, error) {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := s.photoRepository.GetPhotoShares(ctx, photo.ID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toShares(shares), nil
+}
+
+func (s *service) ShareAlbum(ctx context.Context, userUUID string, albumID int, granteeUUID string, role string) (*serviceModel.Share, error) {
+	params, err := toSaveShareParams(userUUID, granteeUUID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getAlbum(ctx, userUUID, albumID, actionManage); err != nil {
+		return nil, err
+	}
+
+	share, err := s.photoRepository.SaveAlbumShare(ctx, albumID, params)
+	if err := s.handleAlbumRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toShare(share), nil
+}
+
+func (s *service) UnshareAlbum(ctx context.Context, userUUID string, albumID int, granteeUUID string) error {
+	grantee, err := parseGrantee(granteeUUID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getAlbum(ctx, userUUID, albumID, actionManage); err != nil {
+		return err
+	}
+
+	err = s.photoRepository.DeleteAlbumShare(ctx, albumID, grantee)
+	return s.handleShareRepoErr(ctx, err)
+}
+
+func (s *service) GetAlbumShares(ctx context.Context, userUUID string, albumID int) ([]serviceModel.Share, error) {
+	if _, err := s.getAlbum(ctx, userUUID, albumID, actionManage); err != nil {
+		return nil, err
+	}
+
+	shares, err := s.photoRepository.GetAlbumShares(ctx, albumID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toShares(shares), nil
+}
+
+func (s *service) GetSharedWithMe(ctx context.Context, userUUID string, limit, offset int) (*serviceModel.SharedWithMe, error) {
+	listParams, err := toPhotoListParams(serviceModel.PhotoFilter{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+
+	photos, err := s.photoRepository.GetSharedPhotos(ctx, userUUID, listParams)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	albums, err := s.photoRepository.GetSharedAlbums(ctx, userUUID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	res := &serviceModel.SharedWithMe{
+		Photos: converter.ToPhotosFromRepo(photos),
+		Albums: make([]serviceModel.SharedAlbum, len(albums)),
+	}
+	for i := range albums {
+		album, err := toAlbum(&albums[i].Album)
+		if err != nil {
+			return nil, err
+		}
+		// Публикацией управляет только владелец
+		album.PublicToken = ""
+		album.MetadataPolicy = ""
+
+		res.Albums[i] = serviceModel.SharedAlbum{
+			Album:     *album,
+			OwnerUUID: albums[i].UserUUID,
+			Role:      serviceModel.ShareRole(albums[i].Role),
+		}
+	}
+
+	return res, nil
+}
+
+// handleShareRepoErr как HandleRepoErr, но отсутствие записи означает, что доступ не выдан.
+func (s *service) handleShareRepoErr(ctx context.Context, err error) error {
+	if errors.Is(err, repoErr.NotFoundError) {
+		return fmt.Errorf("%w: %v", serviceErr.ShareNotFoundError, err)
+	}
+	return s.HandleRepoErr(ctx, err)
+}
+
+// toSaveShareParams проверяет пользователя и роль доступа. Выдать доступ самому себе нельзя.
+func toSaveShareParams(userUUID, granteeUUID, role string) (*repoModel.SaveShareParams, error) {
+	grantee, err := parseGrantee(granteeUUID)
+	if err != nil {
+		return nil, err
+	}
+	if grantee == userUUID {
+		return nil, fmt.Errorf("%w: cannot share with yourself", serviceErr.InvalidShareError)
+	}
+
+	shareRole, err := serviceModel.ParseShareRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repoModel.SaveShareParams{GranteeUUID: grantee, Role: string(shareRole)}, nil
+}
+
+// parseGrantee проверяет UUID пользователя, которому выдается доступ, и возвращает его в каноническом виде.
+func parseGrantee(granteeUUID string) (string, error) {
+	grantee, err := uuid.Parse(granteeUUID)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid user uuid %q", serviceErr.InvalidShareError, granteeUUID)
+	}
+	return grantee.String(), nil
+}
+
+func toShare(share *repoModel.Share) *serviceModel.Share {
+	return &serviceModel.Share{
+		UserUUID:  share.GranteeUUID,
+		Role:      serviceModel.ShareRole(share.Role),
+		CreatedAt: share.CreatedAt,
+	}
+}
+
+func toShares(shares []repoModel.Share) []serviceModel.Share {
+	res := make([]serviceModel.Share, len(shares))
+	for i := range shares {
+		res[i] = *toShare(&shares[i])
+	}
+	return res
+}
@@ -0,0 +1,189 @@
+package photo
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"testing"
+	"time"
+)
+
+func TestService_SharePhoto(t *testing.T) {
+	const (
+		userUUID    = "6f1d2c3e-0000-4000-8000-000000000001"
+		granteeUUID = "6f1d2c3e-0000-4000-8000-000000000002"
+	)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		granteeUUID  string
+		role         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:        "Shared",
+			granteeUUID: "6F1D2C3E-0000-4000-8000-000000000002",
+			role:        "viewer",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+				repo.EXPECT().SavePhotoShare(gomock.Any(), 1, &repoModel.SaveShareParams{GranteeUUID: granteeUUID, Role: "viewer"}).
+					Return(&repoModel.Share{GranteeUUID: granteeUUID, Role: "viewer", CreatedAt: createdAt}, nil)
+			},
+		},
+		{
+			name:         "Contributor on photo",
+			granteeUUID:  granteeUUID,
+			role:         "contributor",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidShareError,
+		},
+		{
+			name:         "Unknown role",
+			granteeUUID:  granteeUUID,
+			role:         "editor",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidShareError,
+		},
+		{
+			name:         "Invalid user",
+			granteeUUID:  "bob",
+			role:         "viewer",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidShareError,
+		},
+		{
+			name:         "Share with yourself",
+			granteeUUID:  userUUID,
+			role:         "viewer",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidShareError,
+		},
+		{
+			name:        "Photo of another user",
+			granteeUUID: granteeUUID,
+			role:        "viewer",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: granteeUUID}, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			share, err := s.SharePhoto(context.Background(), userUUID, 1, tt.granteeUUID, tt.role)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &serviceModel.Share{UserUUID: granteeUUID, Role: serviceModel.ShareViewer, CreatedAt: createdAt}, share)
+		})
+	}
+}
+
+func TestService_UnshareAlbum(t *testing.T) {
+	const (
+		userUUID    = "6f1d2c3e-0000-4000-8000-000000000001"
+		granteeUUID = "6f1d2c3e-0000-4000-8000-000000000002"
+	)
+
+	album := &repoModel.Album{ID: 1, UserUUID: userUUID, Title: "Trip", Filter: []byte(`{}`)}
+
+	tests := []struct {
+		name         string
+		userUUID     string
+		mockBehavior func(repo *mock_repository.MockPhotoRepository)
+		expectedErr  error
+	}{
+		{
+			name:     "Unshared",
+			userUUID: userUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().DeleteAlbumShare(gomock.Any(), 1, granteeUUID).Return(nil)
+			},
+		},
+		{
+			name:     "Not shared",
+			userUUID: userUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+				repo.EXPECT().DeleteAlbumShare(gomock.Any(), 1, granteeUUID).Return(repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.ShareNotFoundError,
+		},
+		{
+			name:     "Contributor cannot manage shares",
+			userUUID: granteeUUID,
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetAlbumByID(gomock.Any(), 1).Return(album, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			err := s.UnshareAlbum(context.Background(), tt.userUUID, 1, granteeUUID)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_GetSharedWithMe(t *testing.T) {
+	const userUUID = "user-id"
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetSharedPhotos(gomock.Any(), userUUID, &repoModel.PhotoListParams{Limit: 10, Offset: 5}).
+		Return([]repoModel.Photo{{ID: 3, UserUUID: "owner-id"}}, nil)
+	album := repoModel.Album{ID: 7, UserUUID: "owner-id", Title: "Trip", Filter: []byte(`{"min_rating":4}`)}
+	album.PublicToken.String, album.PublicToken.Valid = "token", true
+	mockRepo.EXPECT().GetSharedAlbums(gomock.Any(), userUUID).
+		Return([]repoModel.SharedAlbum{{Album: album, Role: "contributor"}}, nil)
+
+	s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+	shared, err := s.GetSharedWithMe(context.Background(), userUUID, 10, 5)
+	require.NoError(t, err)
+	require.Len(t, shared.Photos, 1)
+	assert.Equal(t, 3, shared.Photos[0].ID)
+	require.Len(t, shared.Albums, 1)
+	assert.Equal(t, "owner-id", shared.Albums[0].OwnerUUID)
+	assert.Equal(t, serviceModel.ShareContributor, shared.Albums[0].Role)
+	assert.Empty(t, shared.Albums[0].PublicToken)
+}
@@ -0,0 +1,110 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	"go-photo/internal/repository/photo/converter"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"time"
+)
+
+func (s *service) GetTimeline(ctx context.Context, userUUID string, filter serviceModel.TimelineFilter) (*serviceModel.Timeline, error) {
+	granularity := filter.Granularity
+	if granularity == "" {
+		granularity = model.TimelineMonth
+	}
+	if _, err := model.ParseTimelineGranularity(string(granularity)); err != nil {
+		return nil, fmt.Errorf("%w: %v", serviceErr.InvalidFilterError, err)
+	}
+	timeZone, _, err := loadTimeZone(filter.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit < 0 || filter.Limit > config.MaxTimelineLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", serviceErr.InvalidFilterError, config.MaxTimelineLimit)
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = config.DefaultTimelineLimit
+	}
+
+	var before *time.Time
+	if filter.Cursor != "" {
+		start, err := time.Parse(granularity.Layout(), filter.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cursor must be in %s format", serviceErr.InvalidFilterError, granularity.Layout())
+		}
+		before = &start
+	}
+
+	// Лишний период показывает, что есть следующая страница, и служит ее курсором
+	buckets, err := s.photoRepository.GetUserTimeline(ctx, userUUID, &repoModel.TimelineParams{
+		Granularity: string(granularity),
+		TimeZone:    timeZone,
+		Before:      before,
+		Limit:       limit + 1,
+	})
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	timeline := &serviceModel.Timeline{
+		Granularity: granularity,
+		Buckets:     converter.ToTimelineBucketsFromRepo(buckets),
+	}
+	if len(timeline.Buckets) > limit {
+		timeline.NextCursor = timeline.Buckets[limit].Start.Format(granularity.Layout())
+		timeline.Buckets = timeline.Buckets[:limit]
+	}
+
+	return timeline, nil
+}
+
+func (s *service) GetPhotosOnThisDay(ctx context.Context, userUUID string, filter serviceModel.OnThisDayFilter) ([]model.Photo, error) {
+	timeZone, location, err := loadTimeZone(filter.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit < 0 || filter.Limit > config.MaxPhotoListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", serviceErr.InvalidFilterError, config.MaxPhotoListLimit)
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = config.DefaultPhotoListLimit
+	}
+
+	date := filter.Date
+	if date.IsZero() {
+		date = time.Now().In(location)
+	}
+
+	photos, err := s.photoRepository.GetUserPhotosOnThisDay(ctx, userUUID, &repoModel.OnThisDayParams{
+		TimeZone: timeZone,
+		Date:     time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+		Limit:    limit,
+	})
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return converter.ToPhotosFromRepo(photos), nil
+}
+
+// loadTimeZone проверяет часовой пояс IANA и возвращает его имя для БД. Пустое имя - UTC.
+func loadTimeZone(name string) (string, *time.Location, error) {
+	if name == "" {
+		return "UTC", time.UTC, nil
+	}
+
+	// Local - часовой пояс процесса, БД его не знает
+	location, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return "", nil, fmt.Errorf("%w: unknown time zone %q", serviceErr.InvalidFilterError, name)
+	}
+
+	return name, location, nil
+}
@@ -0,0 +1,187 @@
+package photo
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/config"
+	"go-photo/internal/model"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"testing"
+	"time"
+)
+
+func TestService_GetTimeline(t *testing.T) {
+	const userUUID = "user-id"
+	month := func(year int, m time.Month) time.Time { return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC) }
+	cursor := month(2023, time.May)
+
+	tests := []struct {
+		name             string
+		filter           serviceModel.TimelineFilter
+		expectedParams   *repoModel.TimelineParams
+		repoBuckets      []repoModel.TimelineBucket
+		expectedTimeline *serviceModel.Timeline
+		expectedErr      error
+	}{
+		{
+			name:           "Defaults",
+			filter:         serviceModel.TimelineFilter{},
+			expectedParams: &repoModel.TimelineParams{Granularity: "month", TimeZone: "UTC", Limit: config.DefaultTimelineLimit + 1},
+			repoBuckets:    []repoModel.TimelineBucket{{Start: month(2024, time.July), Count: 12, CoverPhotoID: 7}},
+			expectedTimeline: &serviceModel.Timeline{
+				Granularity: model.TimelineMonth,
+				Buckets:     []model.TimelineBucket{{Start: month(2024, time.July), Count: 12, CoverPhotoID: 7}},
+			},
+		},
+		{
+			name:   "From cursor with next page",
+			filter: serviceModel.TimelineFilter{Granularity: model.TimelineMonth, TimeZone: "Europe/Moscow", Cursor: "2023-05", Limit: 2},
+			expectedParams: &repoModel.TimelineParams{
+				Granularity: "month", TimeZone: "Europe/Moscow", Before: &cursor, Limit: 3,
+			},
+			repoBuckets: []repoModel.TimelineBucket{
+				{Start: month(2023, time.May), Count: 3, CoverPhotoID: 5},
+				{Start: month(2023, time.February), Count: 1, CoverPhotoID: 4},
+				{Start: month(2022, time.December), Count: 8, CoverPhotoID: 2},
+			},
+			expectedTimeline: &serviceModel.Timeline{
+				Granularity: model.TimelineMonth,
+				Buckets: []model.TimelineBucket{
+					{Start: month(2023, time.May), Count: 3, CoverPhotoID: 5},
+					{Start: month(2023, time.February), Count: 1, CoverPhotoID: 4},
+				},
+				NextCursor: "2022-12",
+			},
+		},
+		{
+			name:           "Years",
+			filter:         serviceModel.TimelineFilter{Granularity: model.TimelineYear, Limit: 1},
+			expectedParams: &repoModel.TimelineParams{Granularity: "year", TimeZone: "UTC", Limit: 2},
+			repoBuckets: []repoModel.TimelineBucket{
+				{Start: month(2024, time.January), Count: 30, CoverPhotoID: 9},
+				{Start: month(2023, time.January), Count: 12, CoverPhotoID: 5},
+			},
+			expectedTimeline: &serviceModel.Timeline{
+				Granularity: model.TimelineYear,
+				Buckets:     []model.TimelineBucket{{Start: month(2024, time.January), Count: 30, CoverPhotoID: 9}},
+				NextCursor:  "2023",
+			},
+		},
+		{
+			name:        "Cursor of another granularity",
+			filter:      serviceModel.TimelineFilter{Granularity: model.TimelineDay, Cursor: "2023-05"},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Unknown granularity",
+			filter:      serviceModel.TimelineFilter{Granularity: "week"},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Unknown time zone",
+			filter:      serviceModel.TimelineFilter{TimeZone: "Mars/Olympus"},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Limit too large",
+			filter:      serviceModel.TimelineFilter{Limit: config.MaxTimelineLimit + 1},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			if tt.expectedParams != nil {
+				mockRepo.EXPECT().GetUserTimeline(gomock.Any(), userUUID, tt.expectedParams).Return(tt.repoBuckets, nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			timeline, err := s.GetTimeline(context.Background(), userUUID, tt.filter)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTimeline, timeline)
+		})
+	}
+}
+
+func TestService_GetPhotosOnThisDay(t *testing.T) {
+	const userUUID = "user-id"
+
+	tests := []struct {
+		name           string
+		filter         serviceModel.OnThisDayFilter
+		expectedParams func() *repoModel.OnThisDayParams
+		expectedErr    error
+	}{
+		{
+			name:   "Given date",
+			filter: serviceModel.OnThisDayFilter{TimeZone: "Asia/Tokyo", Date: time.Date(2024, 7, 14, 23, 30, 0, 0, time.UTC)},
+			expectedParams: func() *repoModel.OnThisDayParams {
+				return &repoModel.OnThisDayParams{TimeZone: "Asia/Tokyo", Date: time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC), Limit: config.DefaultPhotoListLimit}
+			},
+		},
+		{
+			name:   "Today in time zone",
+			filter: serviceModel.OnThisDayFilter{TimeZone: "Pacific/Kiritimati", Limit: 10},
+			expectedParams: func() *repoModel.OnThisDayParams {
+				location, err := time.LoadLocation("Pacific/Kiritimati")
+				require.NoError(t, err)
+				today := time.Now().In(location)
+				return &repoModel.OnThisDayParams{
+					TimeZone: "Pacific/Kiritimati",
+					Date:     time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC),
+					Limit:    10,
+				}
+			},
+		},
+		{
+			name:        "Unknown time zone",
+			filter:      serviceModel.OnThisDayFilter{TimeZone: "Local"},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+		{
+			name:        "Negative limit",
+			filter:      serviceModel.OnThisDayFilter{Limit: -1},
+			expectedErr: serviceErr.InvalidFilterError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			if tt.expectedParams != nil {
+				mockRepo.EXPECT().GetUserPhotosOnThisDay(gomock.Any(), userUUID, tt.expectedParams()).
+					Return([]repoModel.Photo{{ID: 1, Filename: "a.jpg"}}, nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			photos, err := s.GetPhotosOnThisDay(context.Background(), userUUID, tt.filter)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, photos, 1)
+			assert.Equal(t, 1, photos[0].ID)
+		})
+	}
+}
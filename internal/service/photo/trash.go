@@ -0,0 +1,123 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-photo/internal/repository/photo/converter"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"go-photo/pkg/logger"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// purgeBatchSize количество фото, удаляемых за один запрос к БД при очистке корзины
+const purgeBatchSize = 100
+
+func (s *service) DeletePhoto(ctx context.Context, userUUID string, photoID int) error {
+	photo, err := s.getPhoto(ctx, userUUID, photoID, actionManage)
+	if err != nil {
+		return err
+	}
+
+	err = s.photoRepository.TrashPhoto(ctx, photo.ID)
+
+	return s.HandleRepoErr(ctx, err)
+}
+
+func (s *service) GetTrash(ctx context.Context, userUUID string) ([]serviceModel.TrashedPhoto, error) {
+	photos, err := s.photoRepository.GetTrashedPhotos(ctx, userUUID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	trash := make([]serviceModel.TrashedPhoto, 0, len(photos))
+	for _, p := range photos {
+		photo := converter.ToPhotoFromRepo(&p, nil)
+		trash = append(trash, serviceModel.TrashedPhoto{
+			Photo:   *photo,
+			PurgeAt: photo.DeletedAt.Add(s.d.TrashRetention),
+		})
+	}
+
+	return trash, nil
+}
+
+func (s *service) RestorePhoto(ctx context.Context, userUUID string, photoID int) error {
+	photo, err := s.photoRepository.GetPhotoByID(ctx, photoID)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return err
+	}
+
+	if err := s.authorizePhoto(ctx, userUUID, photo, actionManage); err != nil {
+		return err
+	}
+	if !photo.IsTrashed() {
+		return fmt.Errorf("%w: photo %d is not in trash", serviceErr.PhotoNotFoundError, photoID)
+	}
+
+	err = s.photoRepository.RestorePhoto(ctx, photo.ID)
+
+	return s.HandleRepoErr(ctx, err)
+}
+
+func (s *service) PurgeTrash(ctx context.Context) error {
+	before := time.Now().Add(-s.d.TrashRetention)
+
+	var errs []error
+	for {
+		photos, err := s.photoRepository.GetPhotosTrashedBefore(ctx, before, purgeBatchSize)
+		if err != nil {
+			return errors.Join(append(errs, fmt.Errorf("failed to get trashed photos: %w", err))...)
+		}
+
+		purged := 0
+		for _, photo := range photos {
+			if err := s.purgePhoto(ctx, photo); err != nil {
+				errs = append(errs, fmt.Errorf("photo %d: %w", photo.ID, err))
+				continue
+			}
+			purged++
+		}
+
+		if purged > 0 {
+			logger.FromContext(ctx).Infof("Purged %d photos from trash", purged)
+		}
+
+		// Неудаленные фото вернутся в следующей выборке, поэтому без прогресса останавливаемся
+		if len(photos) < purgeBatchSize || purged == 0 {
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// purgePhoto окончательно удаляет фото из БД, а затем его файлы.
+// Файлы, которые не удалось удалить, остаются лишними и убираются fsck.
+func (s *service) purgePhoto(ctx context.Context, photo repoModel.Photo) error {
+	versions, err := s.photoRepository.GetPhotoVersions(ctx, photo.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get versions: %w", err)
+	}
+
+	err = s.photoRepository.DeleteTrashedPhoto(ctx, photo.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		path := filepath.Join(s.d.StorageFolderPath, photo.UserUUID, v.UUIDFilename)
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logger.FromContext(ctx).Errorf("Failed to remove file %s of purged photo %d: %v", path, photo.ID, err)
+		}
+	}
+	s.removeRenditions(ctx, photo.UserUUID, versions)
+	s.removeVariants(ctx, photo.ID)
+
+	return nil
+}
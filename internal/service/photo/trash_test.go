@@ -0,0 +1,197 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_DeletePhoto(t *testing.T) {
+	const userUUID = "user-id"
+	trashedAt := sql.NullTime{Time: time.Now(), Valid: true}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "Moved to trash",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+				repo.EXPECT().TrashPhoto(gomock.Any(), 1).Return(nil)
+			},
+		},
+		{
+			name: "Photo of another user",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: "other"}, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+		{
+			name: "Already in trash",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).
+					Return(&repoModel.Photo{ID: 1, UserUUID: userUUID, DeletedAt: trashedAt}, nil)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+		{
+			name: "Trashed concurrently",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+				repo.EXPECT().TrashPhoto(gomock.Any(), 1).Return(repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			err := s.DeletePhoto(context.Background(), userUUID, 1)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_RestorePhoto(t *testing.T) {
+	const userUUID = "user-id"
+	trashedAt := sql.NullTime{Time: time.Now(), Valid: true}
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "Restored",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).
+					Return(&repoModel.Photo{ID: 1, UserUUID: userUUID, DeletedAt: trashedAt}, nil)
+				repo.EXPECT().RestorePhoto(gomock.Any(), 1).Return(nil)
+			},
+		},
+		{
+			name: "Not in trash",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+		{
+			name: "Photo of another user",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).
+					Return(&repoModel.Photo{ID: 1, UserUUID: "other", DeletedAt: trashedAt}, nil)
+			},
+			expectedErr: serviceErr.AccessDeniedError,
+		},
+		{
+			name: "Purged",
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(nil, repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.PhotoNotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			err := s.RestorePhoto(context.Background(), userUUID, 1)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_GetTrash(t *testing.T) {
+	const userUUID = "user-id"
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetTrashedPhotos(gomock.Any(), userUUID).Return([]repoModel.Photo{
+		{ID: 1, UserUUID: userUUID, Filename: "a.jpg", DeletedAt: sql.NullTime{Time: deletedAt, Valid: true}},
+	}, nil)
+
+	s := NewService(Deps{StorageFolderPath: t.TempDir(), TrashRetention: 24 * time.Hour}, mockRepo, nil)
+
+	trash, err := s.GetTrash(context.Background(), userUUID)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, 1, trash[0].ID)
+	assert.Equal(t, deletedAt, trash[0].DeletedAt)
+	assert.Equal(t, deletedAt.Add(24*time.Hour), trash[0].PurgeAt)
+}
+
+func TestService_PurgeTrash(t *testing.T) {
+	const userUUID = "user-id"
+	storageDir := t.TempDir()
+	purgedPath := filepath.Join(storageDir, userUUID, "purged.jpg")
+	failedPath := filepath.Join(storageDir, userUUID, "failed.jpg")
+	writeFile(t, purgedPath, true)
+	writeFile(t, failedPath, true)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetPhotosTrashedBefore(gomock.Any(), gomock.Any(), purgeBatchSize).Return([]repoModel.Photo{
+		{ID: 1, UserUUID: userUUID},
+		{ID: 2, UserUUID: userUUID},
+	}, nil)
+	mockRepo.EXPECT().GetPhotoVersions(gomock.Any(), 1).
+		Return([]repoModel.PhotoVersion{{PhotoID: 1, UUIDFilename: "purged.jpg"}}, nil)
+	mockRepo.EXPECT().DeleteTrashedPhoto(gomock.Any(), 1).Return(nil)
+	mockRepo.EXPECT().GetPhotoVersions(gomock.Any(), 2).
+		Return([]repoModel.PhotoVersion{{PhotoID: 2, UUIDFilename: "failed.jpg"}}, nil)
+	mockRepo.EXPECT().DeleteTrashedPhoto(gomock.Any(), 2).Return(repoErr.NotFoundError)
+
+	s := NewService(Deps{StorageFolderPath: storageDir}, mockRepo, nil)
+
+	err := s.PurgeTrash(context.Background())
+	assert.ErrorIs(t, err, repoErr.NotFoundError)
+
+	assertFileExists(t, purgedPath, false)
+	// Файлы фото, которое не удалось удалить из БД, остаются на месте
+	assertFileExists(t, failedPath, true)
+}
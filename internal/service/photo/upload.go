@@ -2,31 +2,37 @@ package photo
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	log "github.com/sirupsen/logrus"
 	repoModel "go-photo/internal/repository/photo/model"
 	serviceErr "go-photo/internal/service/error"
 	serviceModel "go-photo/internal/service/photo/model"
 	"go-photo/internal/utils"
+	"go-photo/pkg/executor"
+	"go-photo/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	_ "golang.org/x/image/webp"
 	"image"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 	"io"
+	"io/fs"
 	"mime/multipart"
 	"os"
 	"path/filepath"
-	"runtime"
-	"sync"
 	"time"
 )
 
 type saveToDiskInfo struct {
-	size    int64
-	height  int
-	width   int
-	savedAt time.Time
+	size     int64
+	height   int
+	width    int
+	checksum string
+	savedAt  time.Time
 }
 
 func (s *service) UploadPhoto(ctx context.Context, userUUID string, photoFile *multipart.FileHeader) (int, error) {
@@ -35,15 +41,14 @@ func (s *service) UploadPhoto(ctx context.Context, userUUID string, photoFile *m
 		return 0, fmt.Errorf("failed to ensure user's photos folder exists: %w", err)
 	}
 
-	info := s.saveFile(ctx, photoFile, userFolder)
-	if info.Error != nil {
-		log.Errorf("Failed to save file %s: %v", photoFile.Filename, info.Error)
-		return 0, info.Error
+	infos, err := s.upload(ctx, userUUID, userFolder, []*multipart.FileHeader{photoFile})
+	if err != nil {
+		return 0, err
 	}
 
-	info = s.saveToDatabase(ctx, userUUID, info)
+	info := infos[0]
 	if info.Error != nil {
-		log.Errorf("Failed to save file %s to database: %v", photoFile.Filename, info.Error)
+		logger.FromContext(ctx).Errorf("Failed to upload file %s: %v", photoFile.Filename, info.Error)
 		return 0, info.Error
 	}
 
@@ -51,146 +56,280 @@ func (s *service) UploadPhoto(ctx context.Context, userUUID string, photoFile *m
 }
 
 func (s *service) UploadBatchPhotos(ctx context.Context, userUUID string, photoFiles []*multipart.FileHeader) (*serviceModel.UploadInfoList, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "PhotoService.UploadBatchPhotos",
+		trace.WithAttributes(attribute.Int("photos.count", len(photoFiles))))
+	defer span.End()
+
 	destFolder, err := ensureUserFolder(s.d.StorageFolderPath, userUUID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to ensure user's photos folder exists: %w", err)
 	}
 
-	uploaded := &serviceModel.UploadInfoList{}
-	fileTaskChan := make(chan *multipart.FileHeader)
-	dbTaskChan := make(chan serviceModel.UploadInfo)
-	resultChan := make(chan serviceModel.UploadInfo)
-
-	fileWorkerCount := runtime.NumCPU() / 3
-	dbWorkerCount := runtime.NumCPU() / 3
-
-	fileWg := sync.WaitGroup{}
-	for i := 0; i < fileWorkerCount; i++ {
-		fileWg.Add(1)
-		go func(workerID int) {
-			defer fileWg.Done()
-			for file := range fileTaskChan {
-				select {
-				case <-ctx.Done():
-					log.Warnf("File worker %d stopped due to context cancellation. Context: %v", workerID, ctx.Err())
-				default:
-				}
-
-				info := s.saveFile(ctx, file, destFolder)
-				if info.Error != nil {
-					log.Errorf("Failed to save file %s: %v", info.Filename, info.Error)
-					log.Warnf("Skipping DB save for file %s due to disk save error: %v", info.Filename, info.Error)
-					resultChan <- info
-					continue
-				}
-
-				dbTaskChan <- info
-			}
-		}(i)
-	}
-
-	dbWg := sync.WaitGroup{}
-	for i := 0; i < dbWorkerCount; i++ {
-		dbWg.Add(1)
-		go func(workerID int) {
-			defer dbWg.Done()
-			for info := range dbTaskChan {
-				select {
-				case <-ctx.Done():
-					log.Warnf("DB worker %d stopped due to context cancellation. Context: %v", workerID, ctx.Err())
-				default:
-				}
-
-				info = s.saveToDatabase(ctx, userUUID, info)
-				resultChan <- info
-			}
-		}(i)
-	}
-
-	// Ожидание завершения всех задач
-	go func() {
-		fileWg.Wait()
-		close(dbTaskChan)
-		dbWg.Wait()
-		close(resultChan)
-	}()
-
-	// Отправка задач на сохранение файлов
-	go func() {
-		for _, file := range photoFiles {
-			select {
-			case <-ctx.Done():
-				log.Warn("File task sending stopped due to context cancellation")
-				return
-			case fileTaskChan <- file:
-			}
-		}
-		close(fileTaskChan)
-	}()
+	infos, err := s.upload(ctx, userUUID, destFolder, photoFiles)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	uploaded := serviceModel.NewUploadInfoList(infos)
 
-	for res := range resultChan {
-		uploaded.Add(res)
+	span.SetAttributes(
+		attribute.Int("photos.success_count", uploaded.SuccessCount()),
+		attribute.Int("photos.error_count", uploaded.ErrorCount()),
+	)
+	if uploaded.IsAllError() {
+		span.SetStatus(codes.Error, serviceErr.AllFailedError.Error())
 	}
 
+	return uploaded, uploadListErr(uploaded)
+}
+
+// uploadListErr возвращает AllFailedError, если не загрузился ни один файл, и ParticalSuccessError,
+// если загрузились не все.
+func uploadListErr(uploaded *serviceModel.UploadInfoList) error {
 	if uploaded.IsAllError() {
-		return uploaded, serviceErr.AllFailedError
+		return serviceErr.AllFailedError
 	}
 	if uploaded.IsSomeError() {
-		return uploaded, serviceErr.ParticalSuccessError
+		return serviceErr.ParticalSuccessError
+	}
+
+	return nil
+}
+
+// upload ставит файлы в общие для процесса очереди загрузки и дожидается результата.
+// Загрузка двухфазная: файл записывается в staging (FileExecutor), затем фото сохраняется
+// в БД (DBExecutor), и только после коммита файл переносится в папку пользователя destFolder.
+// Каждая загрузка журналируется в pending_uploads, поэтому прерванные загрузки
+// доводятся до конца или откатываются в ReconcileUploads.
+//
+// Если очередь переполнена, возвращается UploadQueueFullError и ни один файл не загружается.
+// При отмене ctx возвращает ошибку контекста, не дожидаясь оставшихся файлов:
+// они будут пропущены воркерами.
+func (s *service) upload(ctx context.Context, userUUID, destFolder string, files []*multipart.FileHeader) ([]serviceModel.UploadInfo, error) {
+	if err := utils.EnsureDirectoryExists(s.stagingFolder()); err != nil {
+		return nil, fmt.Errorf("failed to ensure staging folder exists: %w", err)
+	}
+
+	// Буфер на все файлы: воркеры не блокируются, даже если вызывающий уже не ждет результат
+	results := make(chan serviceModel.UploadInfo, len(files))
+
+	tasks := make([]executor.Task, 0, len(files))
+	for _, file := range files {
+		tasks = append(tasks, func() {
+			s.uploadFileTask(ctx, userUUID, destFolder, file, results)
+		})
+	}
+
+	err := s.d.FileExecutor.Submit(userUUID, tasks...)
+	if errors.Is(err, executor.QueueFullError) {
+		logger.FromContext(ctx).Warnf("Upload queue is full, rejecting %d files", len(files))
+		return nil, serviceErr.UploadQueueFullError
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue upload: %w", err)
+	}
+
+	infos := make([]serviceModel.UploadInfo, 0, len(files))
+	for range files {
+		select {
+		case info := <-results:
+			infos = append(infos, info)
+		case <-ctx.Done():
+			return nil, fmt.Errorf("upload canceled: %w", ctx.Err())
+		}
+	}
+
+	return infos, nil
+}
+
+// uploadFileTask записывает файл в staging и передает его в очередь сохранения в БД.
+// Ровно один результат по файлу отправляется в results.
+func (s *service) uploadFileTask(ctx context.Context, userUUID, destFolder string, file *multipart.FileHeader, results chan<- serviceModel.UploadInfo) {
+	if err := ctx.Err(); err != nil {
+		results <- serviceModel.UploadInfo{Filename: file.Filename, Error: fmt.Errorf("upload canceled: %w", err)}
+		return
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "PhotoService.upload.stageFile",
+		trace.WithAttributes(attribute.String("photo.filename", file.Filename)))
+	info := s.stageFile(ctx, userUUID, file)
+	if info.Error != nil {
+		span.RecordError(info.Error)
+		logger.FromContext(ctx).Warnf("Skipping DB save for file %s due to disk save error: %v", file.Filename, info.Error)
+	}
+	span.End()
+
+	if info.Error != nil {
+		results <- info
+		return
+	}
+
+	err := s.d.DBExecutor.Submit(userUUID, func() {
+		s.saveToDatabaseTask(ctx, userUUID, destFolder, info, results)
+	})
+	if err != nil {
+		s.abandonUpload(ctx, info)
+		info.Error = fmt.Errorf("failed to enqueue db save: %w", err)
+		results <- info
+	}
+}
+
+func (s *service) saveToDatabaseTask(ctx context.Context, userUUID, destFolder string, info serviceModel.UploadInfo, results chan<- serviceModel.UploadInfo) {
+	if err := ctx.Err(); err != nil {
+		s.abandonUpload(ctx, info)
+		info.Error = fmt.Errorf("upload canceled: %w", err)
+		results <- info
+		return
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "PhotoService.upload.saveToDatabase",
+		trace.WithAttributes(attribute.String("photo.filename", info.Filename)))
+	defer span.End()
+
+	info = s.saveToDatabase(ctx, userUUID, info)
+	if info.Error != nil {
+		span.RecordError(info.Error)
+		results <- info
+		return
+	}
+
+	s.finishUpload(ctx, destFolder, info)
+
+	results <- info
+}
+
+// stageFile создает запись журнала загрузки и записывает файл в staging под UUID именем.
+func (s *service) stageFile(ctx context.Context, userUUID string, file *multipart.FileHeader) serviceModel.UploadInfo {
+	uuidFilename := s.utils.UUIDFilename(file.Filename)
+
+	// Запись журнала создается до записи файла: после падения на любом шаге
+	// reconciler найдет загрузку и удалит staging файл
+	pendingID, err := s.photoRepository.CreatePendingUpload(ctx, &repoModel.CreatePendingUploadParams{
+		UserUUID:     userUUID,
+		UUIDFilename: uuidFilename,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Failed to journal upload of file %s: %v", file.Filename, err)
+		return serviceModel.UploadInfo{
+			Filename: file.Filename,
+			Error:    fmt.Errorf("journal error: %w", err),
+		}
 	}
 
-	return uploaded, nil
+	info := s.saveFile(ctx, file, uuidFilename, s.stagingFolder())
+	info.PendingUploadID = pendingID
+	if info.Error != nil {
+		s.abandonUpload(ctx, info)
+	}
+
+	return info
+}
+
+// finishUpload переносит файл закоммиченной загрузки из staging в папку пользователя и удаляет запись журнала.
+// Ошибки не возвращаются: фото уже сохранено, а незавершенный перенос выполнит reconciler.
+func (s *service) finishUpload(ctx context.Context, destFolder string, info serviceModel.UploadInfo) {
+	err := promoteStagedFile(s.stagedFilePath(info.UUIDFilename), filepath.Join(destFolder, info.UUIDFilename))
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Failed to move file %s to storage, it will be retried by reconciler: %v", info.UUIDFilename, err)
+		return
+	}
+
+	err = s.photoRepository.DeletePendingUpload(ctx, info.PendingUploadID)
+	if err != nil {
+		logger.FromContext(ctx).Warnf("Failed to delete pending upload %d: %v", info.PendingUploadID, err)
+	}
 }
 
-// saveFile сохраняет файл на диск и возвращает информацию о нем
-// Название файла генерируется с помощью UUID
-func (s *service) saveFile(_ context.Context, file *multipart.FileHeader, destFolder string) serviceModel.UploadInfo {
-	originalFilename := file.Filename
-	uuidFilename := s.utils.UUIDFilename(originalFilename)
+// abandonUpload откатывает незакоммиченную загрузку: удаляет запись журнала и staging файл.
+// Если фото все-таки сохранено (например, ошибка пришла после коммита), файл не трогается
+// и загрузку завершит reconciler.
+func (s *service) abandonUpload(ctx context.Context, info serviceModel.UploadInfo) {
+	if info.PendingUploadID != 0 {
+		err := s.photoRepository.DeleteUncommittedPendingUpload(ctx, info.PendingUploadID)
+		if err != nil {
+			logger.FromContext(ctx).Warnf("Failed to delete pending upload %d, leaving it to reconciler: %v", info.PendingUploadID, err)
+			return
+		}
+	}
 
-	file.Filename = uuidFilename
+	if info.UUIDFilename == "" {
+		return
+	}
 
-	saveInfo, err := saveFileToDisk(file, destFolder)
+	filePath := s.stagedFilePath(info.UUIDFilename)
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.FromContext(ctx).Errorf("Failed to remove staged file %s: %v", filePath, err)
+	}
+}
+
+// saveFile сохраняет файл на диск в destFolder под именем uuidFilename и возвращает информацию о нем
+func (s *service) saveFile(ctx context.Context, file *multipart.FileHeader, uuidFilename, destFolder string) serviceModel.UploadInfo {
+	saveInfo, err := saveFileToDisk(file, filepath.Join(destFolder, uuidFilename))
 	if err != nil {
-		log.Errorf("Failed to save file %s: %v", file.Filename, err)
+		logger.FromContext(ctx).Errorf("Failed to save file %s: %v", uuidFilename, err)
 		return serviceModel.UploadInfo{
-			Error: fmt.Errorf("disk save error: %w", err),
+			Filename:     file.Filename,
+			UUIDFilename: uuidFilename,
+			Error:        fmt.Errorf("disk save error: %w", err),
 		}
 	}
 
+	// Без заглушки фото все равно сохраняется, ее рассчитает фоновая задача
+	placeholder, err := placeholderOfFile(filepath.Join(destFolder, uuidFilename))
+	if err != nil {
+		logger.FromContext(ctx).Warnf("Failed to compute placeholder of file %s: %v", uuidFilename, err)
+	}
+
+	// Непрочитанный EXIF прочитает фоновая задача
+	exif, exifErr := exifOfFile(filepath.Join(destFolder, uuidFilename))
+	if exifErr != nil {
+		logger.FromContext(ctx).Warnf("Failed to read EXIF of file %s: %v", uuidFilename, exifErr)
+	}
+
 	return serviceModel.UploadInfo{
-		Filename:     originalFilename,
+		Filename:     file.Filename,
 		UUIDFilename: uuidFilename,
 		Size:         file.Size,
 		Height:       saveInfo.height,
 		Width:        saveInfo.width,
 		SavedAt:      saveInfo.savedAt,
+		Checksum:     saveInfo.checksum,
+		Placeholder:  placeholder,
+		Location:     exif.location,
+		TakenAt:      exif.takenAt,
+		Camera:       exif.camera,
+		ExifUnknown:  exifErr != nil,
 	}
 }
 
-// saveToDatabase сохраняет информацию о файле в базе данных. Если произошла ошибка, файл удаляется с диска
+// saveToDatabase сохраняет информацию о файле в базе данных и помечает запись журнала загрузки сохраненной.
+// Если произошла ошибка, загрузка откатывается.
 func (s *service) saveToDatabase(ctx context.Context, userUUID string, info serviceModel.UploadInfo) serviceModel.UploadInfo {
 	id, err := s.photoRepository.CreateOriginalPhoto(ctx, &repoModel.CreateOriginalPhotoParams{
-		UserUUID:     userUUID,
-		Filename:     info.Filename,
-		UUIDFilename: info.UUIDFilename,
-		Size:         info.Size,
-		Height:       info.Height,
-		Width:        info.Width,
-		SavedAt:      info.SavedAt,
+		UserUUID:        userUUID,
+		Filename:        info.Filename,
+		UUIDFilename:    info.UUIDFilename,
+		Size:            info.Size,
+		Height:          info.Height,
+		Width:           info.Width,
+		SavedAt:         info.SavedAt,
+		Checksum:        info.Checksum,
+		Blurhash:        info.Placeholder.BlurHash,
+		Palette:         info.Placeholder.Palette,
+		Location:        toRepoGeoPoint(info.Location),
+		TakenAt:         toRepoTakenAt(info.TakenAt),
+		CameraMake:      info.Camera.Make,
+		CameraModel:     info.Camera.Model,
+		ExifUnknown:     info.ExifUnknown,
+		PendingUploadID: info.PendingUploadID,
 	})
 
 	if err != nil {
-		log.Errorf("DB save error for file %s: %v", info.Filename, err)
+		logger.FromContext(ctx).Errorf("DB save error for file %s: %v", info.Filename, err)
 		info.Error = fmt.Errorf("db save error: %w", err)
-
-		filePath := filepath.Join(s.d.StorageFolderPath, userUUID, info.Filename)
-		if rmErr := os.Remove(filePath); rmErr != nil {
-			log.Errorf("Failed to remove file %s after DB save error: %v", filePath, rmErr)
-			info.Error = fmt.Errorf("%w; additionally, rollback failed: %v", info.Error, rmErr)
-		} else {
-			log.Infof("File %s removed due to failed DB save", filePath)
-		}
+		s.abandonUpload(ctx, info)
 	} else {
 		info.PhotoID = id
 	}
@@ -207,25 +346,32 @@ func ensureUserFolder(storageFolderPath, userUUID string) (string, error) {
 	return userFolder, nil
 }
 
-func saveFileToDisk(file *multipart.FileHeader, destFolder string) (saveToDiskInfo, error) {
+// saveFileToDisk записывает файл по пути filePath и сбрасывает его на диск.
+func saveFileToDisk(file *multipart.FileHeader, filePath string) (saveToDiskInfo, error) {
 	src, err := file.Open()
 	if err != nil {
 		return saveToDiskInfo{}, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer src.Close()
 
-	filePath := filepath.Join(destFolder, file.Filename)
 	out, err := os.Create(filePath)
 	if err != nil {
 		return saveToDiskInfo{}, fmt.Errorf("failed to create file: %w", err)
 	}
 	defer out.Close()
 
-	_, err = io.Copy(out, src)
+	checksum := utils.NewChecksum()
+	_, err = io.Copy(io.MultiWriter(out, checksum), src)
 	if err != nil {
 		return saveToDiskInfo{}, fmt.Errorf("failed to write file to disk: %w", err)
 	}
 
+	// Файл должен оказаться на диске до коммита в БД
+	err = out.Sync()
+	if err != nil {
+		return saveToDiskInfo{}, fmt.Errorf("failed to sync file to disk: %w", err)
+	}
+
 	src.Seek(0, 0)
 
 	config, _, err := image.DecodeConfig(src)
@@ -234,11 +380,34 @@ func saveFileToDisk(file *multipart.FileHeader, destFolder string) (saveToDiskIn
 	}
 
 	info := saveToDiskInfo{
-		savedAt: time.Now(),
-		size:    file.Size,
-		height:  config.Height,
-		width:   config.Width,
+		savedAt:  time.Now(),
+		size:     file.Size,
+		height:   config.Height,
+		width:    config.Width,
+		checksum: utils.FormatChecksum(checksum),
 	}
 
 	return info, nil
 }
+
+// promoteStagedFile переносит staging файл в постоянное хранилище.
+// Повторный перенос уже перенесенного файла не считается ошибкой.
+func promoteStagedFile(stagedPath, finalPath string) error {
+	err := utils.EnsureDirectoryExists(filepath.Dir(finalPath))
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(stagedPath, finalPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		if exists, _ := utils.Exist(finalPath); exists {
+			return nil
+		}
+		return fmt.Errorf("staged file %s is missing: %w", stagedPath, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move staged file: %w", err)
+	}
+
+	return nil
+}
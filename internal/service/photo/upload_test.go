@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	repoErr "go-photo/internal/repository/error"
 	mock_repository "go-photo/internal/repository/mock"
 	serviceErr "go-photo/internal/service/error"
 	serviceModel "go-photo/internal/service/photo/model"
+	"go-photo/pkg/executor"
 	"image"
 	"image/color"
 	"image/jpeg"
@@ -41,9 +43,11 @@ func TestService_UploadBatchPhotos(t *testing.T) {
 				}
 			},
 			mockBehavior: func(repo *mock_repository.MockPhotoRepository, userUUID string, photoFiles []*multipart.FileHeader) {
+				repo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(1, nil).Times(len(photoFiles))
 				for i := range photoFiles {
 					repo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).Return(i+1, nil).Times(1)
 				}
+				repo.EXPECT().DeletePendingUpload(gomock.Any(), 1).Return(nil).Times(len(photoFiles))
 			},
 			expectedUploaded: []string{"test1.jpg", "test2.jpg"},
 			expectedError:    nil,
@@ -58,8 +62,11 @@ func TestService_UploadBatchPhotos(t *testing.T) {
 				}
 			},
 			mockBehavior: func(repo *mock_repository.MockPhotoRepository, userUUID string, photoFiles []*multipart.FileHeader) {
+				repo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(1, nil).Times(2)
 				repo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).Times(1)
 				repo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).Times(1).Return(0, serviceErr.DbError)
+				repo.EXPECT().DeletePendingUpload(gomock.Any(), 1).Return(nil).Times(1)
+				repo.EXPECT().DeleteUncommittedPendingUpload(gomock.Any(), 1).Return(nil).Times(1)
 			},
 			expectedUploaded: []string{"test1.jpg"},
 			expectedError:    serviceErr.ParticalSuccessError,
@@ -74,8 +81,10 @@ func TestService_UploadBatchPhotos(t *testing.T) {
 				}
 			},
 			mockBehavior: func(repo *mock_repository.MockPhotoRepository, userUUID string, photoFiles []*multipart.FileHeader) {
+				repo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(1, nil).Times(2)
 				repo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).
 					Return(0, fmt.Errorf("db error")).Times(2)
+				repo.EXPECT().DeleteUncommittedPendingUpload(gomock.Any(), 1).Return(nil).Times(2)
 			},
 			expectedUploaded: nil,
 			expectedError:    serviceErr.AllFailedError,
@@ -94,6 +103,9 @@ func TestService_UploadBatchPhotos(t *testing.T) {
 				repo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).Return(1, nil).Times(1)
 				repo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).Return(0, fmt.Errorf("db error")).Times(1)
 				repo.EXPECT().CreateOriginalPhoto(gomock.Any(), gomock.Any()).Return(2, nil).Times(1)
+				repo.EXPECT().CreatePendingUpload(gomock.Any(), gomock.Any()).Return(1, nil).Times(3)
+				repo.EXPECT().DeletePendingUpload(gomock.Any(), 1).Return(nil).Times(2)
+				repo.EXPECT().DeleteUncommittedPendingUpload(gomock.Any(), 1).Return(nil).Times(1)
 			},
 			expectedUploaded: []string{"test1.jpg", "test3.jpg"},
 			expectedError:    serviceErr.ParticalSuccessError,
@@ -125,10 +137,67 @@ func TestService_UploadBatchPhotos(t *testing.T) {
 				}
 				assert.ElementsMatch(t, tt.expectedUploaded, uploadedFiles)
 			}
+
+			for _, info := range uploaded.Get() {
+				if info.Error != nil {
+					continue
+				}
+				assert.FileExists(t, filepath.Join(storageDir, tt.userUUID, info.UUIDFilename))
+			}
+			// Все загрузки завершены или откачены, в staging ничего не остается
+			staged, err := os.ReadDir(filepath.Join(storageDir, StagingFolderName))
+			assert.NoError(t, err)
+			assert.Empty(t, staged)
 		})
 	}
 }
 
+func TestService_UploadBatchPhotos_QueueFull(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	fileExecutor := executor.New(executor.Config{Workers: 1, MaxPendingPerKey: 1})
+	defer fileExecutor.Close(context.Background())
+
+	s := NewService(Deps{StorageFolderPath: t.TempDir(), FileExecutor: fileExecutor}, mock_repository.NewMockPhotoRepository(c), nil)
+
+	files := []*multipart.FileHeader{
+		mockFileHeader("test1.jpg", 100, "file content"),
+		mockFileHeader("test2.jpg", 100, "file content"),
+	}
+
+	_, err := s.UploadBatchPhotos(context.Background(), "user-id", files)
+	assert.ErrorIs(t, err, serviceErr.UploadQueueFullError)
+}
+
+func TestService_UploadBatchPhotos_Canceled(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storageDir := t.TempDir()
+	fileExecutor := executor.New(executor.Config{Workers: 1})
+
+	// Занимаем единственного воркера, чтобы загрузка осталась в очереди
+	release := make(chan struct{})
+	assert.NoError(t, fileExecutor.Submit("other-user", func() { <-release }))
+
+	// Репозиторий не должен вызываться: задачи отмененной загрузки пропускаются
+	s := NewService(Deps{StorageFolderPath: storageDir, FileExecutor: fileExecutor}, mock_repository.NewMockPhotoRepository(c), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.UploadBatchPhotos(ctx, "user-id", []*multipart.FileHeader{mockFileHeader("test1.jpg", 100, "file content")})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	assert.NoError(t, fileExecutor.Close(context.Background()))
+
+	entries, err := os.ReadDir(filepath.Join(storageDir, "user-id"))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
 func TestService_SaveToDatabase(t *testing.T) {
 	type mockBehavior func(repo *mock_repository.MockPhotoRepository, ctx context.Context, userUUID string, info serviceModel.UploadInfo)
 
@@ -140,18 +209,23 @@ func TestService_SaveToDatabase(t *testing.T) {
 		mockBehavior   mockBehavior
 		expectedInfo   serviceModel.UploadInfo
 		expectedLogMsg string
+		// expectedStaged должен ли staging файл остаться после сохранения
+		expectedStaged bool
 	}{
 		{
 			name:     "Successful Save to Database",
 			userUUID: "user-id",
 			filePath: "test1.jpg",
 			uploadInfo: serviceModel.UploadInfo{
-				Filename: "test1.jpg",
-				Size:     100,
+				Filename:        "test1.jpg",
+				UUIDFilename:    "uuid-test1.jpg",
+				Size:            100,
+				PendingUploadID: 7,
 			},
 			mockBehavior: func(repo *mock_repository.MockPhotoRepository, ctx context.Context, userUUID string, info serviceModel.UploadInfo) {
 				repo.EXPECT().CreateOriginalPhoto(ctx, gomock.Any()).Return(1, nil).Times(1)
 			},
+			expectedStaged: true,
 			expectedInfo: serviceModel.UploadInfo{
 				Filename: "test1.jpg",
 				Size:     100,
@@ -163,11 +237,14 @@ func TestService_SaveToDatabase(t *testing.T) {
 			userUUID: "user-id",
 			filePath: "test1.jpg",
 			uploadInfo: serviceModel.UploadInfo{
-				Filename: "test1.jpg",
-				Size:     100,
+				Filename:        "test1.jpg",
+				UUIDFilename:    "uuid-test1.jpg",
+				Size:            100,
+				PendingUploadID: 7,
 			},
 			mockBehavior: func(repo *mock_repository.MockPhotoRepository, ctx context.Context, userUUID string, info serviceModel.UploadInfo) {
 				repo.EXPECT().CreateOriginalPhoto(ctx, gomock.Any()).Return(0, fmt.Errorf("db error")).Times(1)
+				repo.EXPECT().DeleteUncommittedPendingUpload(ctx, 7).Return(nil).Times(1)
 			},
 			expectedInfo: serviceModel.UploadInfo{
 				Filename: "test1.jpg",
@@ -176,6 +253,26 @@ func TestService_SaveToDatabase(t *testing.T) {
 			},
 			expectedLogMsg: "Failed to remove file",
 		},
+		{
+			name:     "Database Error after Commit",
+			userUUID: "user-id",
+			uploadInfo: serviceModel.UploadInfo{
+				Filename:        "test1.jpg",
+				UUIDFilename:    "uuid-test1.jpg",
+				Size:            100,
+				PendingUploadID: 7,
+			},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository, ctx context.Context, userUUID string, info serviceModel.UploadInfo) {
+				repo.EXPECT().CreateOriginalPhoto(ctx, gomock.Any()).Return(0, fmt.Errorf("connection reset")).Times(1)
+				// Фото сохранено: запись журнала уже закоммичена, файл должен остаться для reconciler
+				repo.EXPECT().DeleteUncommittedPendingUpload(ctx, 7).Return(repoErr.NotFoundError).Times(1)
+			},
+			expectedInfo: serviceModel.UploadInfo{
+				Filename: "test1.jpg",
+				Error:    fmt.Errorf("db save error: connection reset"),
+			},
+			expectedStaged: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -184,8 +281,9 @@ func TestService_SaveToDatabase(t *testing.T) {
 			storageDir := t.TempDir()
 			defer os.RemoveAll(storageDir)
 
-			filePath := filepath.Join(storageDir, tt.filePath)
-			_ = os.WriteFile(filePath, []byte("file content"), 0644)
+			stagedPath := filepath.Join(storageDir, StagingFolderName, tt.uploadInfo.UUIDFilename)
+			assert.NoError(t, os.MkdirAll(filepath.Dir(stagedPath), 0755))
+			assert.NoError(t, os.WriteFile(stagedPath, []byte("file content"), 0644))
 
 			c := gomock.NewController(t)
 			defer c.Finish()
@@ -206,6 +304,11 @@ func TestService_SaveToDatabase(t *testing.T) {
 			} else {
 				assert.NoError(t, info.Error)
 			}
+			if tt.expectedStaged {
+				assert.FileExists(t, stagedPath)
+			} else {
+				assert.NoFileExists(t, stagedPath)
+			}
 		})
 	}
 }
@@ -0,0 +1,271 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	repoErr "go-photo/internal/repository/error"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"go-photo/pkg/imaging"
+	"go-photo/pkg/logger"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// publicJPEGQuality качество JPEG файлов публикации, которые приходится перекодировать:
+// с водяным знаком или без метаданных, если их не удалось удалить без перекодирования.
+// WebP кодируется с качеством из настроек WebP.
+const publicJPEGQuality = 90
+
+// maxWatermarkLogoSide наибольшая сторона логотипа в пикселях. Логотип декодируется
+// при создании каждого варианта с водяным знаком, поэтому большие изображения не принимаются.
+const maxWatermarkLogoSide = 4096
+
+func (s *service) GetWatermark(ctx context.Context, userUUID string) (*serviceModel.Watermark, error) {
+	settings, err := s.photoRepository.GetWatermarkSettings(ctx, userUUID)
+	if err := s.handleWatermarkRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toWatermark(settings), nil
+}
+
+func (s *service) SaveWatermark(ctx context.Context, userUUID string, settings serviceModel.WatermarkSettings) (*serviceModel.Watermark, error) {
+	settings = settings.WithDefaults()
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	if settings.Type == serviceModel.WatermarkLogo {
+		current, err := s.photoRepository.GetWatermarkSettings(ctx, userUUID)
+		if err != nil && !errors.Is(err, repoErr.NotFoundError) {
+			return nil, s.HandleRepoErr(ctx, err)
+		}
+		if current == nil || !current.LogoFilename.Valid {
+			return nil, fmt.Errorf("%w: upload a logo first", serviceErr.InvalidWatermarkError)
+		}
+	}
+
+	saved, err := s.photoRepository.SaveWatermarkSettings(ctx, &repoModel.SaveWatermarkSettingsParams{
+		UserUUID: userUUID,
+		Type:     string(settings.Type),
+		Text:     settings.Text,
+		Position: string(settings.Position),
+		Opacity:  settings.Opacity,
+		Scale:    settings.Scale,
+	})
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return toWatermark(saved), nil
+}
+
+func (s *service) UploadWatermarkLogo(ctx context.Context, userUUID string, logoFile *multipart.FileHeader) (*serviceModel.Watermark, error) {
+	data, err := readWatermarkLogo(logoFile)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := s.utils.UUIDFilename(logoFile.Filename)
+	path := s.watermarkLogoPath(userUUID, filename)
+	if err := writeFileAtomically(path, data); err != nil {
+		return nil, fmt.Errorf("%w: failed to write watermark logo: %v", serviceErr.UnexpectedError, err)
+	}
+
+	settings, previous, err := s.photoRepository.SaveWatermarkLogo(ctx, userUUID, filename)
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		s.removeWatermarkLogo(ctx, userUUID, filename)
+		return nil, err
+	}
+
+	if previous != "" && previous != filename {
+		s.removeWatermarkLogo(ctx, userUUID, previous)
+	}
+
+	return toWatermark(settings), nil
+}
+
+func (s *service) DeleteWatermark(ctx context.Context, userUUID string) error {
+	settings, err := s.photoRepository.DeleteWatermarkSettings(ctx, userUUID)
+	if errors.Is(err, repoErr.ConflictError) {
+		return fmt.Errorf("%w: %v", serviceErr.WatermarkInUseError, err)
+	}
+	if err := s.handleWatermarkRepoErr(ctx, err); err != nil {
+		return err
+	}
+
+	if settings.LogoFilename.Valid {
+		s.removeWatermarkLogo(ctx, userUUID, settings.LogoFilename.String)
+	}
+
+	return nil
+}
+
+// handleWatermarkRepoErr как HandleRepoErr, но отсутствие записи означает, что водяной знак не настроен.
+func (s *service) handleWatermarkRepoErr(ctx context.Context, err error) error {
+	if errors.Is(err, repoErr.NotFoundError) {
+		return fmt.Errorf("%w: %v", serviceErr.WatermarkNotFoundError, err)
+	}
+	return s.HandleRepoErr(ctx, err)
+}
+
+// readWatermarkLogo читает загруженный логотип и проверяет, что это PNG допустимого размера.
+func readWatermarkLogo(logoFile *multipart.FileHeader) ([]byte, error) {
+	f, err := logoFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open logo: %v", serviceErr.UnexpectedError, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read logo: %v", serviceErr.UnexpectedError, err)
+	}
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: logo must be a PNG image", serviceErr.InvalidWatermarkError)
+	}
+	if cfg.Width > maxWatermarkLogoSide || cfg.Height > maxWatermarkLogoSide {
+		return nil, fmt.Errorf("%w: logo is larger than %dx%d", serviceErr.InvalidWatermarkError, maxWatermarkLogoSide, maxWatermarkLogoSide)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("%w: logo is not a valid PNG image: %v", serviceErr.InvalidWatermarkError, err)
+	}
+
+	return data, nil
+}
+
+// publicWatermark возвращает водяной знак, которым помечаются файлы публикации, или nil, если публикация
+// без знака. Если настроек знака нет, возвращает ошибку: файлы публикации со знаком не отдаются без него.
+func (s *service) publicWatermark(ctx context.Context, token string) (*repoModel.WatermarkSettings, error) {
+	mark, err := s.photoRepository.GetWatermarkByToken(ctx, token)
+	if errors.Is(err, repoErr.NotFoundError) {
+		return nil, nil
+	}
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return mark, nil
+}
+
+// albumWatermark возвращает водяной знак владельца опубликованного альбома или nil, если альбом
+// опубликован без знака. Как и у фото, без настроек знака файлы альбома не отдаются.
+func (s *service) albumWatermark(ctx context.Context, album *repoModel.Album) (*repoModel.WatermarkSettings, error) {
+	if !album.Watermark {
+		return nil, nil
+	}
+
+	mark, err := s.photoRepository.GetWatermarkSettings(ctx, album.UserUUID)
+	if errors.Is(err, repoErr.NotFoundError) {
+		err = fmt.Errorf("watermark settings are missing for album %d: %v", album.ID, err)
+	}
+	if err := s.HandleRepoErr(ctx, err); err != nil {
+		return nil, err
+	}
+
+	return mark, nil
+}
+
+// watermarkedVersionFile возвращает файл версии публикации с водяным знаком. Файл создается
+// при первом запросе и кэшируется вместе с вариантами публичного фото.
+func (s *service) watermarkedVersionFile(ctx context.Context, token, userUUID string, version *repoModel.PhotoVersion, mark *repoModel.WatermarkSettings, opts serviceModel.FileOptions) (*serviceModel.PhotoFile, error) {
+	format := s.variantFormat(version.UUIDFilename, opts.AcceptWebP)
+	quality := publicJPEGQuality
+	if format == serviceModel.FormatWebP {
+		quality = s.d.WebP.Quality
+	}
+
+	key := sha256.Sum256([]byte(token + "\x00" + version.UUIDFilename + "\x00" + "full" + watermarkKey(mark)))
+	path := filepath.Join(s.variantsFolder(version.PhotoID), hex.EncodeToString(key[:16])+format.Ext())
+
+	data, err := s.cachedVariant(ctx, path, func() ([]byte, error) {
+		img, err := decodeImageFile(filepath.Join(s.d.StorageFolderPath, userUUID, version.UUIDFilename))
+		if err != nil {
+			return nil, err
+		}
+
+		img, err = s.applyWatermark(img, mark)
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, img, format.Ext(), quality); err != nil {
+			return nil, fmt.Errorf("failed to encode watermarked version: %w", err)
+		}
+		return buf.Bytes(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", serviceErr.UnexpectedError, err)
+	}
+
+	return &serviceModel.PhotoFile{Data: data, ContentType: format.ContentType()}, nil
+}
+
+func (s *service) applyWatermark(img image.Image, mark *repoModel.WatermarkSettings) (image.Image, error) {
+	var markImg image.Image
+	if mark.Type == string(serviceModel.WatermarkLogo) {
+		logo, err := decodeImageFile(s.watermarkLogoPath(mark.UserUUID, mark.LogoFilename.String))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load watermark logo: %w", err)
+		}
+		markImg = logo
+	} else {
+		markImg = imaging.TextMark(mark.Text.String)
+	}
+
+	return imaging.Watermark(img, markImg, imaging.WatermarkOptions{
+		Position: imaging.Position(mark.Position),
+		Opacity:  float64(mark.Opacity) / 100,
+		Scale:    float64(mark.Scale) / 100,
+	}), nil
+}
+
+// watermarkKey часть ключа кэша вариантов, которая меняется при каждом изменении водяного знака.
+// Пустая для публикаций без знака, чтобы ключи их вариантов не зависели от водяных знаков.
+func watermarkKey(mark *repoModel.WatermarkSettings) string {
+	if mark == nil {
+		return ""
+	}
+	return "\x00watermark:" + mark.UpdatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+func (s *service) watermarkLogoPath(userUUID, filename string) string {
+	return filepath.Join(s.d.StorageFolderPath, WatermarksFolderName, userUUID, filename)
+}
+
+// removeWatermarkLogo удаляет файл логотипа. Ошибка только логируется.
+func (s *service) removeWatermarkLogo(ctx context.Context, userUUID, filename string) {
+	path := s.watermarkLogoPath(userUUID, filename)
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.FromContext(ctx).Errorf("Failed to remove watermark logo %s: %v", path, err)
+	}
+}
+
+func toWatermark(settings *repoModel.WatermarkSettings) *serviceModel.Watermark {
+	return &serviceModel.Watermark{
+		WatermarkSettings: serviceModel.WatermarkSettings{
+			Type:     serviceModel.WatermarkType(settings.Type),
+			Text:     settings.Text.String,
+			Position: serviceModel.WatermarkPosition(settings.Position),
+			Opacity:  settings.Opacity,
+			Scale:    settings.Scale,
+		},
+		HasLogo:   settings.LogoFilename.Valid,
+		UpdatedAt: settings.UpdatedAt,
+	}
+}
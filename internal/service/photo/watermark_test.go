@@ -0,0 +1,499 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/internal/config"
+	repoErr "go-photo/internal/repository/error"
+	mock_repository "go-photo/internal/repository/mock"
+	repoModel "go-photo/internal/repository/photo/model"
+	serviceErr "go-photo/internal/service/error"
+	serviceModel "go-photo/internal/service/photo/model"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var opaqueWhite = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+
+// whitePNG белое непрозрачное изображение в PNG.
+func whitePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func pngFileHeader(t *testing.T, filename string, width, height int) *multipart.FileHeader {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write(whitePNG(t, width, height))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "/", body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(10<<20))
+
+	return req.MultipartForm.File["file"][0]
+}
+
+// whitePixels количество белых пикселей: на тестовых изображениях они есть только у водяного знака.
+func whitePixels(t *testing.T, data []byte) int {
+	t.Helper()
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	var n int
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if color.NRGBAModel.Convert(img.At(x, y)) == opaqueWhite {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestService_GetPhotoFileByVersionAndToken_Watermark(t *testing.T) {
+	const userUUID = "user-id"
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storage := t.TempDir()
+	source := filepath.Join(storage, userUUID, "original.png")
+	writeTestImage(t, source, 400, 200)
+
+	version := &repoModel.PhotoVersion{ID: 10, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "original.png"}
+	mark := &repoModel.WatermarkSettings{
+		UserUUID: userUUID, Type: "text", Text: sql.NullString{String: "(c) me", Valid: true},
+		Position: "center", Opacity: 100, Scale: 50, UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetPhotoVersionByToken(gomock.Any(), "token", gomock.Any()).Return(version, nil).Times(2)
+	mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil).Times(3)
+	mockRepo.EXPECT().GetWatermarkByToken(gomock.Any(), "token").Return(mark, nil).Times(2)
+	mockRepo.EXPECT().GetPhotoVersions(gomock.Any(), 1).Return([]repoModel.PhotoVersion{*version}, nil)
+
+	s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+	public, err := s.GetPhotoFileByVersionAndToken(context.Background(), "token", "original", serviceModel.FileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", public.ContentType)
+	assert.Positive(t, whitePixels(t, public.Data))
+
+	// Повторный запрос отдается из кэша без чтения исходной версии
+	clean, err := os.ReadFile(source)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(source))
+	cached, err := s.GetPhotoFileByVersionAndToken(context.Background(), "token", "original", serviceModel.FileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, public.Data, cached.Data)
+
+	// Владелец получает файл без водяного знака
+	require.NoError(t, os.WriteFile(source, clean, 0644))
+	owner, err := s.GetPhotoFile(context.Background(), userUUID, 1, "original", serviceModel.FileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, clean, owner.Data)
+	assert.Zero(t, whitePixels(t, owner.Data))
+}
+
+func TestService_GetResizedPhotoFileByToken_Watermark(t *testing.T) {
+	const userUUID = "user-id"
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storage := t.TempDir()
+	writeTestImage(t, filepath.Join(storage, userUUID, "original.png"), 400, 200)
+	logo := filepath.Join(storage, WatermarksFolderName, userUUID, "logo.png")
+	require.NoError(t, os.MkdirAll(filepath.Dir(logo), 0755))
+	require.NoError(t, os.WriteFile(logo, whitePNG(t, 40, 20), 0644))
+
+	versions := []repoModel.PhotoWithPhotoVersion{{
+		PhotoID: 1, UserUUID: userUUID, UUIDFilename: "original.png",
+		VersionType: sql.NullString{String: "original", Valid: true}, Width: 400, Height: 200,
+	}}
+	mark := repoModel.WatermarkSettings{
+		UserUUID: userUUID, Type: "logo", LogoFilename: sql.NullString{String: "logo.png", Valid: true},
+		Position: "top-left", Opacity: 100, Scale: 50, UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	changed := mark
+	changed.UpdatedAt = mark.UpdatedAt.Add(time.Minute)
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetPublicPhotoVersions(gomock.Any(), "token").Return(versions, nil).Times(2)
+	gomock.InOrder(
+		mockRepo.EXPECT().GetWatermarkByToken(gomock.Any(), "token").Return(&mark, nil),
+		mockRepo.EXPECT().GetWatermarkByToken(gomock.Any(), "token").Return(&changed, nil),
+	)
+
+	s := NewService(Deps{
+		StorageFolderPath: storage,
+		Resize:            config.ResizeSettings{Sizes: []config.ImageSize{{Width: 100, Height: 100}}, Qualities: []int{85}},
+	}, mockRepo, nil)
+	opts := serviceModel.ResizeOptions{Width: 100, Height: 100}
+
+	file, err := s.GetResizedPhotoFileByToken(context.Background(), "token", opts)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(file.Data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds())
+	// Логотип шириной в половину фото прижат к левому верхнему углу
+	assert.Equal(t, opaqueWhite, color.NRGBAModel.Convert(img.At(10, 10)))
+	assert.NotEqual(t, opaqueWhite, color.NRGBAModel.Convert(img.At(90, 40)))
+
+	// Измененный водяной знак не берется из кэша
+	_, err = s.GetResizedPhotoFileByToken(context.Background(), "token", opts)
+	require.NoError(t, err)
+	entries, err := os.ReadDir(filepath.Join(storage, VariantsFolderName, "1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestService_SaveWatermark(t *testing.T) {
+	const userUUID = "user-id"
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type mockBehavior func(repo *mock_repository.MockPhotoRepository)
+
+	tests := []struct {
+		name         string
+		settings     serviceModel.WatermarkSettings
+		mockBehavior mockBehavior
+		expected     *serviceModel.Watermark
+		expectedErr  error
+	}{
+		{
+			name:     "Text with defaults",
+			settings: serviceModel.WatermarkSettings{Type: serviceModel.WatermarkText, Text: "(c) me"},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().SaveWatermarkSettings(gomock.Any(), &repoModel.SaveWatermarkSettingsParams{
+					UserUUID: userUUID, Type: "text", Text: "(c) me", Position: "bottom-right", Opacity: 50, Scale: 20,
+				}).Return(&repoModel.WatermarkSettings{
+					UserUUID: userUUID, Type: "text", Text: sql.NullString{String: "(c) me", Valid: true},
+					Position: "bottom-right", Opacity: 50, Scale: 20, UpdatedAt: updatedAt,
+				}, nil)
+			},
+			expected: &serviceModel.Watermark{
+				WatermarkSettings: serviceModel.WatermarkSettings{
+					Type: serviceModel.WatermarkText, Text: "(c) me", Position: serviceModel.WatermarkBottomRight, Opacity: 50, Scale: 20,
+				},
+				UpdatedAt: updatedAt,
+			},
+		},
+		{
+			name:     "Logo",
+			settings: serviceModel.WatermarkSettings{Type: serviceModel.WatermarkLogo, Position: serviceModel.WatermarkCenter, Opacity: 30, Scale: 10},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetWatermarkSettings(gomock.Any(), userUUID).
+					Return(&repoModel.WatermarkSettings{LogoFilename: sql.NullString{String: "logo.png", Valid: true}}, nil)
+				repo.EXPECT().SaveWatermarkSettings(gomock.Any(), gomock.Any()).Return(&repoModel.WatermarkSettings{
+					UserUUID: userUUID, Type: "logo", LogoFilename: sql.NullString{String: "logo.png", Valid: true},
+					Position: "center", Opacity: 30, Scale: 10, UpdatedAt: updatedAt,
+				}, nil)
+			},
+			expected: &serviceModel.Watermark{
+				WatermarkSettings: serviceModel.WatermarkSettings{
+					Type: serviceModel.WatermarkLogo, Position: serviceModel.WatermarkCenter, Opacity: 30, Scale: 10,
+				},
+				HasLogo:   true,
+				UpdatedAt: updatedAt,
+			},
+		},
+		{
+			name:     "Logo not uploaded",
+			settings: serviceModel.WatermarkSettings{Type: serviceModel.WatermarkLogo},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {
+				repo.EXPECT().GetWatermarkSettings(gomock.Any(), userUUID).Return(nil, repoErr.NotFoundError)
+			},
+			expectedErr: serviceErr.InvalidWatermarkError,
+		},
+		{
+			name:         "Text missing",
+			settings:     serviceModel.WatermarkSettings{Type: serviceModel.WatermarkText, Text: "  "},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidWatermarkError,
+		},
+		{
+			name:         "Non-ASCII text",
+			settings:     serviceModel.WatermarkSettings{Type: serviceModel.WatermarkText, Text: "© Фото"},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidWatermarkError,
+		},
+		{
+			name:         "Unknown position",
+			settings:     serviceModel.WatermarkSettings{Type: serviceModel.WatermarkText, Text: "me", Position: "left"},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidWatermarkError,
+		},
+		{
+			name:         "Opacity out of range",
+			settings:     serviceModel.WatermarkSettings{Type: serviceModel.WatermarkText, Text: "me", Opacity: 101},
+			mockBehavior: func(repo *mock_repository.MockPhotoRepository) {},
+			expectedErr:  serviceErr.InvalidWatermarkError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			tt.mockBehavior(mockRepo)
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			watermark, err := s.SaveWatermark(context.Background(), userUUID, tt.settings)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, watermark)
+		})
+	}
+}
+
+func TestService_UploadWatermarkLogo(t *testing.T) {
+	const userUUID = "user-id"
+
+	t.Run("Replaces previous logo", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		storage := t.TempDir()
+		previous := filepath.Join(storage, WatermarksFolderName, userUUID, "old.png")
+		writeTestImage(t, previous, 4, 4)
+
+		var saved string
+		mockRepo := mock_repository.NewMockPhotoRepository(c)
+		mockRepo.EXPECT().SaveWatermarkLogo(gomock.Any(), userUUID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, logoFilename string) (*repoModel.WatermarkSettings, string, error) {
+				saved = logoFilename
+				return &repoModel.WatermarkSettings{
+					UserUUID: userUUID, Type: "logo", LogoFilename: sql.NullString{String: logoFilename, Valid: true},
+					Position: "bottom-right", Opacity: 50, Scale: 20,
+				}, "old.png", nil
+			})
+
+		s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+		watermark, err := s.UploadWatermarkLogo(context.Background(), userUUID, pngFileHeader(t, "logo.png", 8, 4))
+		require.NoError(t, err)
+		assert.Equal(t, serviceModel.WatermarkLogo, watermark.Type)
+		assert.True(t, watermark.HasLogo)
+		assert.FileExists(t, filepath.Join(storage, WatermarksFolderName, userUUID, saved))
+		assert.NoFileExists(t, previous)
+	})
+
+	t.Run("Not a PNG", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		storage := t.TempDir()
+		s := NewService(Deps{StorageFolderPath: storage}, mock_repository.NewMockPhotoRepository(c), nil)
+
+		_, err := s.UploadWatermarkLogo(context.Background(), userUUID, mockFileHeader("logo.png", 100, ""))
+		assert.ErrorIs(t, err, serviceErr.InvalidWatermarkError)
+		assert.NoDirExists(t, filepath.Join(storage, WatermarksFolderName))
+	})
+
+	t.Run("Repo error removes new logo", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		storage := t.TempDir()
+		mockRepo := mock_repository.NewMockPhotoRepository(c)
+		mockRepo.EXPECT().SaveWatermarkLogo(gomock.Any(), userUUID, gomock.Any()).Return(nil, "", repoErr.InsertError)
+
+		s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+		_, err := s.UploadWatermarkLogo(context.Background(), userUUID, pngFileHeader(t, "logo.png", 8, 4))
+		assert.ErrorIs(t, err, serviceErr.UnexpectedError)
+		entries, err := os.ReadDir(filepath.Join(storage, WatermarksFolderName, userUUID))
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestService_DeleteWatermark(t *testing.T) {
+	const userUUID = "user-id"
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storage := t.TempDir()
+	logo := filepath.Join(storage, WatermarksFolderName, userUUID, "logo.png")
+	writeTestImage(t, logo, 4, 4)
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	gomock.InOrder(
+		mockRepo.EXPECT().DeleteWatermarkSettings(gomock.Any(), userUUID).
+			Return(&repoModel.WatermarkSettings{LogoFilename: sql.NullString{String: "logo.png", Valid: true}}, nil),
+		mockRepo.EXPECT().DeleteWatermarkSettings(gomock.Any(), userUUID).Return(nil, repoErr.NotFoundError),
+		mockRepo.EXPECT().DeleteWatermarkSettings(gomock.Any(), userUUID).Return(nil, repoErr.ConflictError),
+	)
+
+	s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+	require.NoError(t, s.DeleteWatermark(context.Background(), userUUID))
+	assert.NoFileExists(t, logo)
+
+	assert.ErrorIs(t, s.DeleteWatermark(context.Background(), userUUID), serviceErr.WatermarkNotFoundError)
+	assert.ErrorIs(t, s.DeleteWatermark(context.Background(), userUUID), serviceErr.WatermarkInUseError)
+}
+
+func TestService_GetPhotoFileByVersionAndToken_MissingWatermark(t *testing.T) {
+	const userUUID = "user-id"
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	storage := t.TempDir()
+	writeTestImage(t, filepath.Join(storage, userUUID, "original.png"), 40, 20)
+	version := &repoModel.PhotoVersion{ID: 10, PhotoID: 1, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "original.png"}
+
+	mockRepo := mock_repository.NewMockPhotoRepository(c)
+	mockRepo.EXPECT().GetPhotoVersionByToken(gomock.Any(), "token", gomock.Any()).Return(version, nil)
+	mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+	mockRepo.EXPECT().GetWatermarkByToken(gomock.Any(), "token").Return(nil, errors.New("watermark settings are missing"))
+
+	s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+	// Файл публикации со знаком не отдается без знака
+	file, err := s.GetPhotoFileByVersionAndToken(context.Background(), "token", "original", serviceModel.FileOptions{})
+	assert.ErrorIs(t, err, serviceErr.UnexpectedError)
+	assert.Nil(t, file)
+}
+
+func TestService_GetPublicAlbumPhotoFile_Watermark(t *testing.T) {
+	const userUUID = "user-id"
+
+	storage := t.TempDir()
+	writeTestImage(t, filepath.Join(storage, userUUID, "original.png"), 400, 200)
+
+	visible := false
+	photoID := 5
+	album := &repoModel.Album{
+		ID: 1, UserUUID: userUUID, Filter: []byte(`{}`), Watermark: true,
+		PublicToken: sql.NullString{String: "token", Valid: true}, MetadataPolicy: sql.NullString{String: "keep", Valid: true},
+	}
+	mark := &repoModel.WatermarkSettings{
+		UserUUID: userUUID, Type: "text", Text: sql.NullString{String: "(c) me", Valid: true},
+		Position: "center", Opacity: 100, Scale: 50, UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name        string
+		settingsErr error
+		expectedErr error
+	}{
+		{name: "Watermarked"},
+		{name: "Settings missing", settingsErr: repoErr.NotFoundError, expectedErr: serviceErr.UnexpectedError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			mockRepo.EXPECT().GetAlbumByToken(gomock.Any(), "token").Return(album, nil)
+			mockRepo.EXPECT().GetAlbumPhotos(gomock.Any(), 1, &repoModel.PhotoListParams{
+				Hidden: &visible, PhotoID: &photoID, Limit: 1,
+			}, true).Return([]repoModel.Photo{{ID: photoID, UserUUID: userUUID}}, nil)
+			if tt.settingsErr != nil {
+				mockRepo.EXPECT().GetWatermarkSettings(gomock.Any(), userUUID).Return(nil, tt.settingsErr)
+			} else {
+				mockRepo.EXPECT().GetWatermarkSettings(gomock.Any(), userUUID).Return(mark, nil)
+				mockRepo.EXPECT().GetPhotoVersions(gomock.Any(), photoID).Return([]repoModel.PhotoVersion{
+					{ID: 10, PhotoID: photoID, VersionType: sql.NullString{String: "original", Valid: true}, UUIDFilename: "original.png"},
+				}, nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: storage}, mockRepo, nil)
+
+			file, err := s.GetPublicAlbumPhotoFile(context.Background(), "token", photoID, "original", serviceModel.FileOptions{})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "image/png", file.ContentType)
+			assert.Positive(t, whitePixels(t, file.Data))
+		})
+	}
+}
+
+func TestService_PublishPhoto_Watermark(t *testing.T) {
+	const userUUID = "user-id"
+
+	tests := []struct {
+		name          string
+		settingsErr   error
+		expectedToken string
+		expectedErr   error
+	}{
+		{name: "Configured", expectedToken: "token"},
+		{name: "Not configured", settingsErr: repoErr.NotFoundError, expectedErr: serviceErr.WatermarkNotFoundError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			mockRepo := mock_repository.NewMockPhotoRepository(c)
+			mockRepo.EXPECT().GetPhotoByID(gomock.Any(), 1).Return(&repoModel.Photo{ID: 1, UserUUID: userUUID}, nil)
+			if tt.settingsErr != nil {
+				mockRepo.EXPECT().GetWatermarkSettings(gomock.Any(), userUUID).Return(nil, tt.settingsErr)
+			} else {
+				mockRepo.EXPECT().GetWatermarkSettings(gomock.Any(), userUUID).Return(&repoModel.WatermarkSettings{}, nil)
+				mockRepo.EXPECT().CreatePhotoPublishedInfo(gomock.Any(), &repoModel.CreatePhotoPublishedInfoParams{
+					PhotoID: 1, Watermark: true, MetadataPolicy: "keep",
+				}).Return("token", nil)
+			}
+
+			s := NewService(Deps{StorageFolderPath: t.TempDir()}, mockRepo, nil)
+
+			token, err := s.PublishPhoto(context.Background(), userUUID, 1, serviceModel.PublishOptions{
+				Watermark: true,
+				Metadata:  serviceModel.MetadataKeep,
+			})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedToken, token)
+		})
+	}
+}
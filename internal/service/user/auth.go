@@ -3,12 +3,12 @@ package user
 import (
 	"context"
 	"fmt"
-	log "github.com/sirupsen/logrus"
 	"go-photo/internal/config"
 	serviceErr "go-photo/internal/service/error"
 	"go-photo/internal/service/user/converter"
 	serviceUserModel "go-photo/internal/service/user/model"
 	def "go-photo/pkg/account_v1"
+	"go-photo/pkg/logger"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -97,7 +97,7 @@ func (s *service) getPublicKey(ctx context.Context) (*string, error) {
 
 	publicKey, err := s.accountClient.GetPublicKey(ctx, &emptypb.Empty{})
 	if err != nil {
-		log.Warn("Error getting public key: ", err)
+		logger.FromContext(ctx).Warn("Error getting public key: ", err)
 		return nil, s.handleGRPCErr(err)
 	}
 
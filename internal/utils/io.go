@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
+	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -51,3 +55,49 @@ func EnsureDirectoryExists(path string) error {
 	}
 	return nil
 }
+
+// CheckWritable проверяет, что в директорию можно записать файл.
+// Создает и сразу удаляет временный файл.
+func CheckWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, err)
+	}
+
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file %s: %w", name, err)
+	}
+
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("failed to remove temp file %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// NewChecksum возвращает хеш, которым считаются контрольные суммы файлов фото.
+func NewChecksum() hash.Hash {
+	return sha256.New()
+}
+
+// FormatChecksum возвращает контрольную сумму в виде, в котором она хранится в БД.
+func FormatChecksum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FileChecksum считает контрольную сумму файла.
+func FileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := NewChecksum()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	return FormatChecksum(h), nil
+}
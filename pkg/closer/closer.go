@@ -0,0 +1,61 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Func освобождает ресурс. Должна уважать дедлайн ctx.
+type Func func(ctx context.Context) error
+
+type namedFunc struct {
+	name string
+	f    Func
+}
+
+// Closer хранит функции освобождения ресурсов приложения
+// (пул БД, gRPC соединение, фоновые воркеры и т.д.).
+type Closer struct {
+	mu    sync.Mutex
+	funcs []namedFunc
+	once  sync.Once
+	err   error
+}
+
+func New() *Closer {
+	return &Closer{}
+}
+
+// Add регистрирует функцию освобождения ресурса.
+func (c *Closer) Add(name string, f Func) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.funcs = append(c.funcs, namedFunc{name: name, f: f})
+}
+
+// CloseAll вызывает зарегистрированные функции в обратном порядке регистрации,
+// чтобы ресурсы, созданные позже (и, возможно, зависящие от ранних), закрывались первыми.
+// Ошибки не прерывают закрытие остальных ресурсов и возвращаются объединенными.
+// Повторные вызовы возвращают результат первого.
+func (c *Closer) CloseAll(ctx context.Context) error {
+	c.once.Do(func() {
+		c.mu.Lock()
+		funcs := c.funcs
+		c.funcs = nil
+		c.mu.Unlock()
+
+		var errs []error
+		for i := len(funcs) - 1; i >= 0; i-- {
+			if err := funcs[i].f(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close %s: %w", funcs[i].name, err))
+			}
+		}
+
+		c.err = errors.Join(errs...)
+	})
+
+	return c.err
+}
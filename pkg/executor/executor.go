@@ -0,0 +1,170 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// QueueFullError возвращается, если задачи не помещаются в очередь.
+	QueueFullError = errors.New("executor queue is full")
+	// ClosedError возвращается при попытке добавить задачи в остановленный Executor.
+	ClosedError = errors.New("executor is closed")
+)
+
+// Task единица работы. Задача сама отвечает за проверку отмены своего контекста.
+type Task func()
+
+type Config struct {
+	// Workers количество одновременно выполняющихся задач (минимум 1).
+	Workers int
+	// MaxPending максимальное количество задач, ожидающих выполнения, по всем ключам. 0 - без ограничения.
+	MaxPending int
+	// MaxPendingPerKey максимальное количество ожидающих задач одного ключа. 0 - без ограничения.
+	MaxPendingPerKey int
+}
+
+// Executor выполняет задачи фиксированным числом воркеров.
+// Задачи группируются по ключу (например, UUID пользователя), и воркеры берут
+// их из очередей ключей по кругу, поэтому большой пакет одного пользователя
+// не блокирует остальных.
+type Executor struct {
+	cfg Config
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// queues очереди ожидающих задач по ключам
+	queues map[string][]Task
+	// ring ключи с непустыми очередями в порядке обхода
+	ring    []string
+	pending int
+	closed  bool
+
+	wg sync.WaitGroup
+}
+
+func New(cfg Config) *Executor {
+	cfg.Workers = max(1, cfg.Workers)
+
+	e := &Executor{
+		cfg:    cfg,
+		queues: make(map[string][]Task),
+	}
+	e.cond = sync.NewCond(&e.mu)
+
+	e.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go e.worker()
+	}
+
+	return e
+}
+
+// Submit ставит задачи в очередь ключа key. Задачи принимаются целиком или не принимаются вовсе:
+// если они не помещаются в очередь, возвращается QueueFullError.
+func (e *Executor) Submit(key string, tasks ...Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return ClosedError
+	}
+
+	queue := e.queues[key]
+	if e.cfg.MaxPending > 0 && e.pending+len(tasks) > e.cfg.MaxPending {
+		return QueueFullError
+	}
+	if e.cfg.MaxPendingPerKey > 0 && len(queue)+len(tasks) > e.cfg.MaxPendingPerKey {
+		return QueueFullError
+	}
+
+	if len(queue) == 0 {
+		e.ring = append(e.ring, key)
+	}
+	e.queues[key] = append(queue, tasks...)
+	e.pending += len(tasks)
+
+	e.cond.Broadcast()
+
+	return nil
+}
+
+// Pending возвращает количество задач, ожидающих выполнения.
+func (e *Executor) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.pending
+}
+
+// Close перестает принимать новые задачи и дожидается выполнения уже принятых.
+// Возвращает ошибку контекста, если задачи не успели выполниться.
+func (e *Executor) Close(ctx context.Context) error {
+	e.mu.Lock()
+	e.closed = true
+	e.cond.Broadcast()
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Executor) worker() {
+	defer e.wg.Done()
+
+	for {
+		task, ok := e.next()
+		if !ok {
+			return
+		}
+
+		task()
+	}
+}
+
+// next блокируется до появления задачи. Возвращает false, если Executor
+// остановлен и очереди пусты.
+func (e *Executor) next() (Task, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for len(e.ring) == 0 {
+		if e.closed {
+			return nil, false
+		}
+		e.cond.Wait()
+	}
+
+	key := e.ring[0]
+	e.ring = e.ring[1:]
+
+	queue := e.queues[key]
+	task := queue[0]
+	queue[0] = nil
+	queue = queue[1:]
+	e.pending--
+
+	if len(queue) == 0 {
+		delete(e.queues, key)
+	} else {
+		e.queues[key] = queue
+		e.ring = append(e.ring, key)
+	}
+
+	return task, true
+}
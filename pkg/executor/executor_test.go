@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_FairScheduling(t *testing.T) {
+	e := New(Config{Workers: 1})
+
+	// Блокируем единственного воркера, чтобы все задачи успели встать в очередь
+	release := make(chan struct{})
+	require.NoError(t, e.Submit("blocker", func() { <-release }))
+
+	var mu sync.Mutex
+	var order []string
+	record := func(key string) Task {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, key)
+		}
+	}
+
+	require.NoError(t, e.Submit("a", record("a"), record("a"), record("a")))
+	require.NoError(t, e.Submit("b", record("b")))
+	require.NoError(t, e.Submit("c", record("c"), record("c")))
+
+	close(release)
+	require.NoError(t, e.Close(context.Background()))
+
+	assert.Equal(t, []string{"a", "b", "c", "a", "c", "a"}, order)
+}
+
+func TestExecutor_Submit(t *testing.T) {
+	noop := func() {}
+
+	tests := []struct {
+		name        string
+		cfg         Config
+		queued      map[string]int
+		key         string
+		count       int
+		expectedErr error
+	}{
+		{
+			name:   "Fits",
+			cfg:    Config{MaxPending: 3, MaxPendingPerKey: 2},
+			queued: map[string]int{"a": 1},
+			key:    "b",
+			count:  2,
+		},
+		{
+			name:        "Total limit exceeded",
+			cfg:         Config{MaxPending: 3},
+			queued:      map[string]int{"a": 2},
+			key:         "b",
+			count:       2,
+			expectedErr: QueueFullError,
+		},
+		{
+			name:        "Per key limit exceeded",
+			cfg:         Config{MaxPendingPerKey: 2},
+			queued:      map[string]int{"a": 1},
+			key:         "a",
+			count:       2,
+			expectedErr: QueueFullError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Workers = 1
+			e := New(tt.cfg)
+
+			release := make(chan struct{})
+			started := make(chan struct{})
+			require.NoError(t, e.Submit("blocker", func() {
+				close(started)
+				<-release
+			}))
+			<-started
+
+			for key, n := range tt.queued {
+				for i := 0; i < n; i++ {
+					require.NoError(t, e.Submit(key, noop))
+				}
+			}
+
+			tasks := make([]Task, tt.count)
+			for i := range tasks {
+				tasks[i] = noop
+			}
+			err := e.Submit(tt.key, tasks...)
+			assert.ErrorIs(t, err, tt.expectedErr)
+
+			close(release)
+			require.NoError(t, e.Close(context.Background()))
+			assert.Zero(t, e.Pending())
+		})
+	}
+}
+
+func TestExecutor_Close(t *testing.T) {
+	e := New(Config{Workers: 1})
+
+	release := make(chan struct{})
+	require.NoError(t, e.Submit("a", func() { <-release }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, e.Close(ctx), context.DeadlineExceeded)
+
+	assert.ErrorIs(t, e.Submit("a", func() {}), ClosedError)
+
+	close(release)
+	assert.NoError(t, e.Close(context.Background()))
+}
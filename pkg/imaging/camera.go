@@ -0,0 +1,50 @@
+package imaging
+
+import "strings"
+
+// Camera производитель и модель камеры. Пустая строка - значение неизвестно.
+type Camera struct {
+	Make  string
+	Model string
+}
+
+// Теги IFD0 с производителем и моделью камеры.
+const (
+	tagMake  = 0x010F
+	tagModel = 0x0110
+)
+
+// ReadCamera возвращает производителя и модель камеры из EXIF JPEG или PNG файла, или nil, если их нет.
+// Для других форматов возвращает UnsupportedFormatError.
+func ReadCamera(data []byte) (*Camera, error) {
+	exif, err := readEXIF(data, "camera")
+	if err != nil || exif == nil {
+		return nil, err
+	}
+
+	return tiffCamera(exif)
+}
+
+// tiffCamera читает производителя и модель камеры из IFD0.
+// Камеры дополняют значения пробелами до фиксированной длины, они отбрасываются.
+func tiffCamera(data []byte) (*Camera, error) {
+	t, ifd0, err := parseTIFF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var camera Camera
+	for _, e := range ifd0 {
+		switch e.tag {
+		case tagMake:
+			camera.Make = strings.TrimSpace(t.ascii(e))
+		case tagModel:
+			camera.Model = strings.TrimSpace(t.ascii(e))
+		}
+	}
+	if camera == (Camera{}) {
+		return nil, nil
+	}
+
+	return &camera, nil
+}
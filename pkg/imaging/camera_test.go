@@ -0,0 +1,98 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ifd0EXIF TIFF структура, в IFD0 которой только переданные теги.
+func ifd0EXIF(tags ...testTag) []byte {
+	const ifd0Offset = 8
+
+	buf := make([]byte, ifd0Offset+2+12*len(tags)+4)
+	copy(buf, "II*\x00")
+	binary.LittleEndian.PutUint32(buf[4:], ifd0Offset)
+
+	binary.LittleEndian.PutUint16(buf[ifd0Offset:], uint16(len(tags)))
+	for i, tag := range tags {
+		entry := buf[ifd0Offset+2+i*12:]
+		binary.LittleEndian.PutUint16(entry, tag.tag)
+		binary.LittleEndian.PutUint16(entry[2:], tag.typ)
+		binary.LittleEndian.PutUint32(entry[4:], tag.count)
+		if len(tag.value) <= 4 {
+			copy(entry[8:12], tag.value)
+			continue
+		}
+		binary.LittleEndian.PutUint32(entry[8:], uint32(len(buf)))
+		buf = append(buf, tag.value...)
+	}
+
+	return buf
+}
+
+func TestReadCamera(t *testing.T) {
+	exifJPEG := func(t *testing.T, exif []byte) []byte {
+		return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), exif...)))
+	}
+
+	tests := []struct {
+		name           string
+		data           func(t *testing.T) []byte
+		expectedCamera *Camera
+		expectedErr    error
+	}{
+		{
+			name: "Make and model",
+			data: func(t *testing.T) []byte {
+				return exifJPEG(t, ifd0EXIF(asciiTag(tagMake, "FUJIFILM"), asciiTag(tagModel, "X-T4    ")))
+			},
+			expectedCamera: &Camera{Make: "FUJIFILM", Model: "X-T4"},
+		},
+		{
+			name:           "Only make",
+			data:           func(t *testing.T) []byte { return exifJPEG(t, testEXIF()) },
+			expectedCamera: &Camera{Make: "Canon"},
+		},
+		{
+			name: "PNG",
+			data: func(t *testing.T) []byte {
+				return testPNGWithChunks(t, pngChunkBytes("eXIf", ifd0EXIF(asciiTag(tagModel, "Pixel 8"))))
+			},
+			expectedCamera: &Camera{Model: "Pixel 8"},
+		},
+		{
+			name: "Blank values",
+			data: func(t *testing.T) []byte { return exifJPEG(t, ifd0EXIF(asciiTag(tagMake, "    "))) },
+		},
+		{
+			name: "No EXIF",
+			data: func(t *testing.T) []byte { return testJPEG(t) },
+		},
+		{
+			name:        "Truncated EXIF",
+			data:        func(t *testing.T) []byte { return exifJPEG(t, testEXIF()[:30]) },
+			expectedErr: MalformedImageError,
+		},
+		{
+			name:        "Unsupported format",
+			data:        func(t *testing.T) []byte { return []byte("GIF89a") },
+			expectedErr: UnsupportedFormatError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			camera, err := ReadCamera(tt.data(t))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCamera, camera)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/image/draw"
+	"image"
+)
+
+// InvalidEditError возвращается, если параметры преобразования недопустимы для изображения.
+var InvalidEditError = errors.New("invalid image edit")
+
+// Rotate поворачивает изображение по часовой стрелке на угол, кратный 90 градусам.
+// Отрицательный угол поворачивает против часовой стрелки.
+func Rotate(src image.Image, angle int) (image.Image, error) {
+	if angle%90 != 0 {
+		return nil, fmt.Errorf("%w: rotation angle %d is not a multiple of 90", InvalidEditError, angle)
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	switch (angle%360 + 360) % 360 {
+	case 90:
+		return remap(src, h, w, func(x, y int) (int, int) { return y, h - 1 - x }), nil
+	case 180:
+		return remap(src, w, h, func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }), nil
+	case 270:
+		return remap(src, h, w, func(x, y int) (int, int) { return w - 1 - y, x }), nil
+	default:
+		return src, nil
+	}
+}
+
+// FlipHorizontal отражает изображение слева направо.
+func FlipHorizontal(src image.Image) image.Image {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	return remap(src, w, h, func(x, y int) (int, int) { return w - 1 - x, y })
+}
+
+// FlipVertical отражает изображение сверху вниз.
+func FlipVertical(src image.Image) image.Image {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	return remap(src, w, h, func(x, y int) (int, int) { return x, h - 1 - y })
+}
+
+// Crop вырезает из изображения область rect, заданную относительно его левого верхнего угла.
+// Область должна быть непустой и целиком лежать внутри изображения.
+func Crop(src image.Image, rect image.Rectangle) (image.Image, error) {
+	b := src.Bounds()
+	if rect.Empty() || !rect.In(image.Rect(0, 0, b.Dx(), b.Dy())) {
+		return nil, fmt.Errorf("%w: crop area %v is outside of %dx%d image", InvalidEditError, rect, b.Dx(), b.Dy())
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min.Add(rect.Min), draw.Src)
+
+	return dst, nil
+}
+
+// AdjustBrightness меняет яркость на percent процентов от -100 (черное изображение) до 100 (белое).
+func AdjustBrightness(src image.Image, percent int) (image.Image, error) {
+	if percent < -100 || percent > 100 {
+		return nil, fmt.Errorf("%w: brightness %d is out of range [-100, 100]", InvalidEditError, percent)
+	}
+
+	shift := float64(percent) * 255 / 100
+	return applyLUT(src, func(c float64) float64 { return c + shift }), nil
+}
+
+// AdjustContrast меняет контраст на percent процентов от -100 (серое изображение) до 100 (вдвое больше).
+func AdjustContrast(src image.Image, percent int) (image.Image, error) {
+	if percent < -100 || percent > 100 {
+		return nil, fmt.Errorf("%w: contrast %d is out of range [-100, 100]", InvalidEditError, percent)
+	}
+
+	factor := float64(100+percent) / 100
+	return applyLUT(src, func(c float64) float64 { return (c-128)*factor + 128 }), nil
+}
+
+// Grayscale переводит изображение в оттенки серого (яркость по ITU-R BT.601), сохраняя прозрачность.
+func Grayscale(src image.Image) image.Image {
+	dst := toNRGBA(src)
+	for i := 0; i < len(dst.Pix); i += 4 {
+		p := dst.Pix[i : i+3 : i+3]
+		y := uint8((299*int(p[0]) + 587*int(p[1]) + 114*int(p[2]) + 500) / 1000)
+		p[0], p[1], p[2] = y, y, y
+	}
+
+	return dst
+}
+
+// remap создает изображение w x h, пиксель (x, y) которого берется из точки from(x, y) исходного.
+func remap(src image.Image, w, h int, from func(x, y int) (int, int)) *image.NRGBA {
+	s := toNRGBA(src)
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx, sy := from(x, y)
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], s.Pix[s.PixOffset(sx, sy):s.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
+
+// applyLUT применяет f к каждому цветовому каналу, прозрачность не меняется.
+func applyLUT(src image.Image, f func(c float64) float64) *image.NRGBA {
+	var lut [256]uint8
+	for i := range lut {
+		lut[i] = clampUint8(f(float64(i)))
+	}
+
+	dst := toNRGBA(src)
+	for i := 0; i < len(dst.Pix); i += 4 {
+		p := dst.Pix[i : i+3 : i+3]
+		p[0], p[1], p[2] = lut[p[0]], lut[p[1]], lut[p[2]]
+	}
+
+	return dst
+}
+
+// toNRGBA копирует изображение в новое *image.NRGBA с началом координат в (0, 0).
+// Копия нужна всегда: результат изменяется на месте.
+func toNRGBA(src image.Image) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+func clampUint8(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	default:
+		return uint8(v + 0.5)
+	}
+}
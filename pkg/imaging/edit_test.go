@@ -0,0 +1,134 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	red   = color.NRGBA{R: 255, A: 255}
+	green = color.NRGBA{G: 255, A: 255}
+	blue  = color.NRGBA{B: 255, A: 255}
+	white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// testImage изображение 3x2 с началом не в нуле:
+//
+//	red   green blue
+//	white white white
+func testImage() image.Image {
+	img := image.NewNRGBA(image.Rect(10, 10, 13, 12))
+	img.Set(10, 10, red)
+	img.Set(11, 10, green)
+	img.Set(12, 10, blue)
+	for x := 10; x < 13; x++ {
+		img.Set(x, 11, white)
+	}
+	return img
+}
+
+func pixel(img image.Image, x, y int) color.NRGBA {
+	b := img.Bounds()
+	return color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name           string
+		angle          int
+		expectedBounds image.Rectangle
+		// expectedTopLeft и expectedTopRight цвета верхних углов результата
+		expectedTopLeft  color.NRGBA
+		expectedTopRight color.NRGBA
+	}{
+		{name: "90", angle: 90, expectedBounds: image.Rect(0, 0, 2, 3), expectedTopLeft: white, expectedTopRight: red},
+		{name: "180", angle: 180, expectedBounds: image.Rect(0, 0, 3, 2), expectedTopLeft: white, expectedTopRight: white},
+		{name: "270", angle: 270, expectedBounds: image.Rect(0, 0, 2, 3), expectedTopLeft: blue, expectedTopRight: white},
+		{name: "-90", angle: -90, expectedBounds: image.Rect(0, 0, 2, 3), expectedTopLeft: blue, expectedTopRight: white},
+		{name: "360", angle: 360, expectedBounds: image.Rect(10, 10, 13, 12), expectedTopLeft: red, expectedTopRight: blue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Rotate(testImage(), tt.angle)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBounds, img.Bounds())
+			assert.Equal(t, tt.expectedTopLeft, pixel(img, 0, 0))
+			assert.Equal(t, tt.expectedTopRight, pixel(img, img.Bounds().Dx()-1, 0))
+		})
+	}
+
+	_, err := Rotate(testImage(), 45)
+	assert.ErrorIs(t, err, InvalidEditError)
+}
+
+func TestFlip(t *testing.T) {
+	img := FlipHorizontal(testImage())
+	assert.Equal(t, blue, pixel(img, 0, 0))
+	assert.Equal(t, red, pixel(img, 2, 0))
+
+	img = FlipVertical(testImage())
+	assert.Equal(t, white, pixel(img, 0, 0))
+	assert.Equal(t, red, pixel(img, 0, 1))
+}
+
+func TestCrop(t *testing.T) {
+	img, err := Crop(testImage(), image.Rect(1, 0, 3, 1))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), img.Bounds())
+	assert.Equal(t, green, pixel(img, 0, 0))
+	assert.Equal(t, blue, pixel(img, 1, 0))
+
+	for _, rect := range []image.Rectangle{image.Rect(0, 0, 4, 1), image.Rect(-1, 0, 1, 1), image.Rect(1, 1, 1, 2)} {
+		_, err = Crop(testImage(), rect)
+		assert.ErrorIs(t, err, InvalidEditError, rect)
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	gray := color.NRGBA{R: 100, G: 100, B: 100, A: 128}
+	src := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	src.SetNRGBA(0, 0, gray)
+
+	tests := []struct {
+		name     string
+		adjust   func(image.Image) (image.Image, error)
+		expected color.NRGBA
+	}{
+		{name: "Brighter", adjust: func(img image.Image) (image.Image, error) { return AdjustBrightness(img, 20) },
+			expected: color.NRGBA{R: 151, G: 151, B: 151, A: 128}},
+		{name: "Black", adjust: func(img image.Image) (image.Image, error) { return AdjustBrightness(img, -100) },
+			expected: color.NRGBA{A: 128}},
+		{name: "More contrast", adjust: func(img image.Image) (image.Image, error) { return AdjustContrast(img, 50) },
+			expected: color.NRGBA{R: 86, G: 86, B: 86, A: 128}},
+		{name: "No contrast", adjust: func(img image.Image) (image.Image, error) { return AdjustContrast(img, -100) },
+			expected: color.NRGBA{R: 128, G: 128, B: 128, A: 128}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := tt.adjust(src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pixel(img, 0, 0))
+			// Исходное изображение не меняется
+			assert.Equal(t, gray, src.NRGBAAt(0, 0))
+		})
+	}
+
+	_, err := AdjustBrightness(src, 101)
+	assert.ErrorIs(t, err, InvalidEditError)
+	_, err = AdjustContrast(src, -101)
+	assert.ErrorIs(t, err, InvalidEditError)
+}
+
+func TestGrayscale(t *testing.T) {
+	img := Grayscale(testImage())
+
+	assert.Equal(t, color.NRGBA{R: 76, G: 76, B: 76, A: 255}, pixel(img, 0, 0))
+	assert.Equal(t, color.NRGBA{R: 150, G: 150, B: 150, A: 255}, pixel(img, 1, 0))
+	assert.Equal(t, white, pixel(img, 0, 1))
+}
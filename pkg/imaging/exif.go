@@ -0,0 +1,153 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// readEXIF возвращает EXIF данные (TIFF структуру) JPEG или PNG файла или nil, если их нет.
+// Для других форматов возвращает UnsupportedFormatError, what описывает читаемые сведения.
+func readEXIF(data []byte, what string) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, jpegSOI):
+		return jpegEXIF(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return pngEXIF(data)
+	default:
+		return nil, fmt.Errorf("%w: %s can be read only from JPEG and PNG", UnsupportedFormatError, what)
+	}
+}
+
+// jpegEXIF возвращает EXIF данные (TIFF структуру) JPEG файла или nil, если их нет.
+func jpegEXIF(data []byte) ([]byte, error) {
+	pos := len(jpegSOI)
+	for {
+		if pos+2 > len(data) || data[pos] != 0xFF {
+			return nil, fmt.Errorf("%w: no JPEG marker at offset %d", MalformedImageError, pos)
+		}
+		marker := data[pos+1]
+		switch {
+		case marker == 0xFF:
+			pos++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			pos += 2
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			return nil, nil
+		}
+
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf("%w: truncated JPEG segment at offset %d", MalformedImageError, pos)
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end > len(data) || end < pos+4 {
+			return nil, fmt.Errorf("%w: invalid JPEG segment length at offset %d", MalformedImageError, pos)
+		}
+
+		if payload := data[pos+4 : end]; marker == 0xE1 && bytes.HasPrefix(payload, exifHeader) {
+			return payload[len(exifHeader):], nil
+		}
+		pos = end
+	}
+}
+
+// pngEXIF возвращает содержимое чанка eXIf PNG файла или nil, если его нет.
+func pngEXIF(data []byte) ([]byte, error) {
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, fmt.Errorf("%w: truncated PNG chunk at offset %d", MalformedImageError, pos)
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if end > len(data) || end < pos {
+			return nil, fmt.Errorf("%w: invalid PNG chunk length at offset %d", MalformedImageError, pos)
+		}
+
+		switch string(data[pos+4 : pos+8]) {
+		case "eXIf":
+			return data[pos+8 : pos+8+length], nil
+		case "IDAT", "IEND":
+			// eXIf должен идти до данных изображения
+			return nil, nil
+		}
+		pos = end
+	}
+
+	return nil, nil
+}
+
+// parseTIFF разбирает заголовок TIFF структуры и возвращает записи IFD0.
+func parseTIFF(data []byte) (*tiff, []ifdEntry, error) {
+	t := &tiff{data: data}
+	if len(t.data) < 8 {
+		return nil, nil, fmt.Errorf("%w: truncated TIFF header", MalformedImageError)
+	}
+	switch string(t.data[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, nil, fmt.Errorf("%w: invalid TIFF byte order", MalformedImageError)
+	}
+
+	ifd0, err := t.ifdEntries(int(t.order.Uint32(t.data[4:])))
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, ifd0, nil
+}
+
+// subIFD возвращает записи IFD, на который ссылается тег pointer из ifd, по номерам тегов.
+// Возвращает nil, если ссылки нет.
+func (t *tiff) subIFD(ifd []ifdEntry, pointer uint16) (map[uint16]ifdEntry, error) {
+	offset := 0
+	for _, e := range ifd {
+		if e.tag == pointer {
+			offset = int(t.order.Uint32(e.raw[8:]))
+		}
+	}
+	if offset == 0 {
+		return nil, nil
+	}
+
+	entries, err := t.ifdEntries(offset)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uint16]ifdEntry, len(entries))
+	for _, e := range entries {
+		res[e.tag] = e
+	}
+	return res, nil
+}
+
+// value возвращает значение тега, которое хранится в записи IFD или по смещению.
+func (t *tiff) value(e ifdEntry) ([]byte, error) {
+	size := tiffTypeSizes[t.order.Uint16(e.raw[2:])] * int(t.order.Uint32(e.raw[4:]))
+	if size <= 4 {
+		return e.raw[8 : 8+size], nil
+	}
+	offset := int(t.order.Uint32(e.raw[8:]))
+	if offset < 8 || offset+size > len(t.data) || offset+size < offset {
+		return nil, fmt.Errorf("%w: value of tag %#04x is out of range", MalformedImageError, e.tag)
+	}
+	return t.data[offset : offset+size], nil
+}
+
+// ascii возвращает строковое значение тега без завершающего нуля.
+// Значения других типов и некорректные значения возвращаются пустой строкой.
+func (t *tiff) ascii(e ifdEntry) string {
+	if t.order.Uint16(e.raw[2:]) != 2 {
+		return ""
+	}
+	v, err := t.value(e)
+	if err != nil {
+		return ""
+	}
+	s, _, _ := bytes.Cut(v, []byte{0})
+	return string(s)
+}
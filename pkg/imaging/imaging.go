@@ -0,0 +1,81 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"github.com/chai2010/webp"
+	"golang.org/x/image/draw"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// UnsupportedFormatError возвращается, если изображение нельзя закодировать в запрошенный формат.
+var UnsupportedFormatError = errors.New("unsupported image format")
+
+// Fit уменьшает изображение так, чтобы оно вписалось в width x height с сохранением пропорций.
+// Изображения, которые уже помещаются, не увеличиваются и возвращаются как есть.
+func Fit(src image.Image, width, height int) image.Image {
+	b := src.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	if srcW <= width && srcH <= height {
+		return src
+	}
+
+	// Масштаб по более ограничивающей стороне
+	dstW, dstH := width, srcH*width/srcW
+	if dstH > height {
+		dstW, dstH = srcW*height/srcH, height
+	}
+	dstW, dstH = max(1, dstW), max(1, dstH)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+
+	return dst
+}
+
+// Cover масштабирует изображение так, чтобы оно заполнило width x height, и обрезает выступающие
+// края по центру. Изображение не увеличивается: если оно меньше области, результат меньше width x height.
+func Cover(src image.Image, width, height int) image.Image {
+	b := src.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+
+	// Масштаб по менее ограничивающей стороне, но не больше 1
+	scaledW, scaledH := srcW, srcH
+	if srcW > width && srcH > height {
+		scaledW, scaledH = width, srcH*width/srcW
+		if scaledH < height {
+			scaledW, scaledH = srcW*height/srcH, height
+		}
+	}
+	scaledW, scaledH = max(1, scaledW), max(1, scaledH)
+	dstW, dstH := min(width, scaledW), min(height, scaledH)
+
+	// Область исходного изображения, которая после масштабирования попадет в результат
+	cropW, cropH := dstW*srcW/scaledW, dstH*srcH/scaledH
+	x0, y0 := b.Min.X+(srcW-cropW)/2, b.Min.Y+(srcH-cropH)/2
+	crop := image.Rect(x0, y0, x0+cropW, y0+cropH)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+
+	return dst
+}
+
+// Encode кодирует изображение в формат, соответствующий расширению файла ext (".jpg", ".png", ".webp").
+// quality используется для JPEG и WebP. WebP кодируется libwebp с потерями.
+func Encode(w io.Writer, img image.Image, ext string, quality int) error {
+	switch strings.ToLower(ext) {
+	case ".jpg", ".jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case ".png":
+		return png.Encode(w, img)
+	case ".webp":
+		return webp.Encode(w, img, &webp.Options{Quality: float32(quality)})
+	default:
+		return fmt.Errorf("%w: %q", UnsupportedFormatError, ext)
+	}
+}
@@ -0,0 +1,65 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	_ "golang.org/x/image/webp"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name           string
+		srcW, srcH     int
+		width, height  int
+		expectedBounds image.Rectangle
+	}{
+		{name: "Landscape", srcW: 400, srcH: 200, width: 100, height: 100, expectedBounds: image.Rect(0, 0, 100, 50)},
+		{name: "Portrait", srcW: 200, srcH: 400, width: 100, height: 100, expectedBounds: image.Rect(0, 0, 50, 100)},
+		{name: "Already fits", srcW: 50, srcH: 20, width: 100, height: 100, expectedBounds: image.Rect(0, 0, 50, 20)},
+		{name: "Extreme ratio", srcW: 1000, srcH: 1, width: 10, height: 10, expectedBounds: image.Rect(0, 0, 10, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.srcW, tt.srcH))
+			assert.Equal(t, tt.expectedBounds, Fit(src, tt.width, tt.height).Bounds())
+		})
+	}
+}
+
+func TestCover(t *testing.T) {
+	tests := []struct {
+		name           string
+		srcW, srcH     int
+		width, height  int
+		expectedBounds image.Rectangle
+	}{
+		{name: "Landscape", srcW: 400, srcH: 200, width: 100, height: 100, expectedBounds: image.Rect(0, 0, 100, 100)},
+		{name: "Portrait", srcW: 200, srcH: 400, width: 100, height: 50, expectedBounds: image.Rect(0, 0, 100, 50)},
+		{name: "Smaller than area", srcW: 50, srcH: 20, width: 100, height: 100, expectedBounds: image.Rect(0, 0, 50, 20)},
+		{name: "One side smaller", srcW: 400, srcH: 50, width: 100, height: 100, expectedBounds: image.Rect(0, 0, 100, 50)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.srcW, tt.srcH))
+			assert.Equal(t, tt.expectedBounds, Cover(src, tt.width, tt.height).Bounds())
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+
+	for _, ext := range []string{".jpg", ".JPEG", ".png", ".webp"} {
+		var buf bytes.Buffer
+		assert.NoError(t, Encode(&buf, img, ext, 80), ext)
+		_, _, err := image.Decode(&buf)
+		assert.NoError(t, err, ext)
+	}
+
+	assert.ErrorIs(t, Encode(&bytes.Buffer{}, img, ".svg", 80), UnsupportedFormatError)
+}
@@ -0,0 +1,69 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// InvalidColorError возвращается, если строка не является цветом в виде #rrggbb.
+var InvalidColorError = errors.New("invalid color")
+
+// Lab цвет в пространстве CIE L*a*b* с белой точкой D65.
+// Евклидово расстояние в нем приближенно соответствует воспринимаемой разнице цветов.
+type Lab struct {
+	L, A, B float64
+}
+
+// ToLab переводит цвет sRGB в CIE L*a*b*. Прозрачность не учитывается.
+func ToLab(c color.RGBA) Lab {
+	r, g, b := sRGBToLinear(c.R), sRGBToLinear(c.G), sRGBToLinear(c.B)
+
+	// sRGB -> XYZ, нормированные на белую точку D65
+	x := (0.4124*r + 0.3576*g + 0.1805*b) / 0.95047
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+	z := (0.0193*r + 0.1192*g + 0.9505*b) / 1.08883
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+
+	return Lab{
+		L: 116*fy - 16,
+		A: 500 * (fx - fy),
+		B: 200 * (fy - fz),
+	}
+}
+
+// DeltaE возвращает разницу цветов CIE76: 1 - едва заметная разница, больше 50 - разные цвета.
+func (l Lab) DeltaE(o Lab) float64 {
+	return math.Sqrt((l.L-o.L)*(l.L-o.L) + (l.A-o.A)*(l.A-o.A) + (l.B-o.B)*(l.B-o.B))
+}
+
+// ParseHexColor разбирает цвет в виде #rrggbb или rrggbb.
+func ParseHexColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("%w: %q, expected #rrggbb", InvalidColorError, s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("%w: %q, expected #rrggbb", InvalidColorError, s)
+	}
+
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, nil
+}
+
+// FormatHexColor возвращает цвет в виде #rrggbb.
+func FormatHexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func labF(t float64) float64 {
+	if t > 216.0/24389 {
+		return math.Cbrt(t)
+	}
+	return (24389.0/27*t + 16) / 116
+}
@@ -0,0 +1,55 @@
+package imaging
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToLab(t *testing.T) {
+	tests := []struct {
+		name     string
+		color    color.RGBA
+		expected Lab
+	}{
+		{name: "White", color: color.RGBA{R: 255, G: 255, B: 255, A: 255}, expected: Lab{L: 100}},
+		{name: "Black", color: color.RGBA{A: 255}, expected: Lab{}},
+		{name: "Red", color: color.RGBA{R: 255, A: 255}, expected: Lab{L: 53.24, A: 80.09, B: 67.20}},
+		{name: "Blue", color: color.RGBA{B: 255, A: 255}, expected: Lab{L: 32.30, A: 79.19, B: -107.86}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lab := ToLab(tt.color)
+			assert.InDelta(t, tt.expected.L, lab.L, 0.05)
+			assert.InDelta(t, tt.expected.A, lab.A, 0.05)
+			assert.InDelta(t, tt.expected.B, lab.B, 0.05)
+		})
+	}
+}
+
+func TestLab_DeltaE(t *testing.T) {
+	blue := ToLab(color.RGBA{R: 0x33, G: 0x66, B: 0xCC, A: 255})
+
+	assert.Zero(t, blue.DeltaE(blue))
+	assert.Less(t, blue.DeltaE(ToLab(color.RGBA{R: 0x35, G: 0x68, B: 0xD0, A: 255})), 3.0)
+	assert.Greater(t, blue.DeltaE(ToLab(color.RGBA{R: 0xCC, G: 0x22, B: 0x11, A: 255})), 50.0)
+}
+
+func TestParseHexColor(t *testing.T) {
+	c, err := ParseHexColor("#3366cc")
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 0x33, G: 0x66, B: 0xCC, A: 255}, c)
+	assert.Equal(t, "#3366cc", FormatHexColor(c))
+
+	c, err = ParseHexColor("FF0000")
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 0xFF, A: 255}, c)
+
+	for _, s := range []string{"", "#fff", "#3366cz", "#3366cc00", "blue"} {
+		_, err := ParseHexColor(s)
+		assert.ErrorIs(t, err, InvalidColorError, s)
+	}
+}
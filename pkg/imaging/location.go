@@ -0,0 +1,99 @@
+package imaging
+
+import (
+	"fmt"
+	"math"
+)
+
+// Location координаты съемки в градусах WGS 84.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// GPS теги EXIF, из которых собираются координаты.
+const (
+	gpsLatitudeRef  = 0x0001
+	gpsLatitude     = 0x0002
+	gpsLongitudeRef = 0x0003
+	gpsLongitude    = 0x0004
+	gpsStatus       = 0x0009
+)
+
+// ReadLocation возвращает координаты съемки из EXIF JPEG или PNG файла, или nil, если их нет.
+// Для других форматов возвращает UnsupportedFormatError.
+func ReadLocation(data []byte) (*Location, error) {
+	exif, err := readEXIF(data, "location")
+	if err != nil || exif == nil {
+		return nil, err
+	}
+
+	return tiffLocation(exif)
+}
+
+// tiffLocation читает координаты из GPS IFD. Возвращает nil, если GPS IFD нет,
+// в нем нет координат или приемник не определил положение.
+func tiffLocation(data []byte) (*Location, error) {
+	t, ifd0, err := parseTIFF(data)
+	if err != nil {
+		return nil, err
+	}
+	gps, err := t.subIFD(ifd0, tagGPSIFD)
+	if err != nil || gps == nil {
+		return nil, err
+	}
+
+	// V (void) - координаты не измерены, часто это нули
+	if e, ok := gps[gpsStatus]; ok && t.ascii(e) == "V" {
+		return nil, nil
+	}
+	for _, tag := range []uint16{gpsLatitudeRef, gpsLatitude, gpsLongitudeRef, gpsLongitude} {
+		if _, ok := gps[tag]; !ok {
+			return nil, nil
+		}
+	}
+
+	latitude, err := t.degrees(gps[gpsLatitude])
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := t.degrees(gps[gpsLongitude])
+	if err != nil {
+		return nil, err
+	}
+	if t.ascii(gps[gpsLatitudeRef]) == "S" {
+		latitude = -latitude
+	}
+	if t.ascii(gps[gpsLongitudeRef]) == "W" {
+		longitude = -longitude
+	}
+	if math.Abs(latitude) > 90 || math.Abs(longitude) > 180 {
+		return nil, fmt.Errorf("%w: GPS coordinates %f, %f are out of range", MalformedImageError, latitude, longitude)
+	}
+
+	return &Location{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// degrees переводит координату из трех RATIONAL (градусы, минуты, секунды) в градусы.
+func (t *tiff) degrees(e ifdEntry) (float64, error) {
+	if t.order.Uint16(e.raw[2:]) != 5 || t.order.Uint32(e.raw[4:]) != 3 {
+		return 0, fmt.Errorf("%w: GPS coordinate tag %#04x is not three rationals", MalformedImageError, e.tag)
+	}
+	v, err := t.value(e)
+	if err != nil {
+		return 0, err
+	}
+
+	var res float64
+	for i, unit := range []float64{1, 60, 3600} {
+		numerator, denominator := t.order.Uint32(v[i*8:]), t.order.Uint32(v[i*8+4:])
+		if denominator == 0 {
+			if numerator == 0 {
+				continue
+			}
+			return 0, fmt.Errorf("%w: GPS coordinate tag %#04x has zero denominator", MalformedImageError, e.tag)
+		}
+		res += float64(numerator) / float64(denominator) / unit
+	}
+	return res, nil
+}
@@ -0,0 +1,182 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rationalTag(tag uint16, values ...uint32) testTag {
+	var value []byte
+	for _, v := range values {
+		value = binary.LittleEndian.AppendUint32(value, v)
+	}
+	return testTag{tag: tag, typ: 5, count: uint32(len(values) / 2), value: value}
+}
+
+// gpsEXIF собирает EXIF (little endian TIFF), в IFD0 которого есть только ссылка на GPS IFD с тегами gps.
+func gpsEXIF(gps ...testTag) []byte {
+	return subIFDEXIF(tagGPSIFD, gps...)
+}
+
+// subIFDEXIF собирает EXIF (little endian TIFF), в IFD0 которого есть только тег pointer,
+// ссылающийся на IFD с тегами tags.
+func subIFDEXIF(pointer uint16, tags ...testTag) []byte {
+	const ifd0Offset, ifdOffset = 8, 8 + 2 + 12 + 4
+
+	buf := make([]byte, ifdOffset+2+12*len(tags)+4)
+	copy(buf, "II*\x00")
+	binary.LittleEndian.PutUint32(buf[4:], ifd0Offset)
+	binary.LittleEndian.PutUint16(buf[ifd0Offset:], 1)
+	binary.LittleEndian.PutUint16(buf[ifd0Offset+2:], pointer)
+	binary.LittleEndian.PutUint16(buf[ifd0Offset+4:], 4)
+	binary.LittleEndian.PutUint32(buf[ifd0Offset+6:], 1)
+	binary.LittleEndian.PutUint32(buf[ifd0Offset+10:], ifdOffset)
+
+	binary.LittleEndian.PutUint16(buf[ifdOffset:], uint16(len(tags)))
+	for i, tag := range tags {
+		entry := buf[ifdOffset+2+i*12:]
+		binary.LittleEndian.PutUint16(entry, tag.tag)
+		binary.LittleEndian.PutUint16(entry[2:], tag.typ)
+		binary.LittleEndian.PutUint32(entry[4:], tag.count)
+		if len(tag.value) <= 4 {
+			copy(entry[8:12], tag.value)
+			continue
+		}
+		binary.LittleEndian.PutUint32(entry[8:], uint32(len(buf)))
+		buf = append(buf, tag.value...)
+	}
+
+	return buf
+}
+
+// Москва, 55°45'21" N 37°37'4.5" E
+var moscowGPS = []testTag{
+	asciiTag(gpsLatitudeRef, "N"),
+	rationalTag(gpsLatitude, 55, 1, 45, 1, 21, 1),
+	asciiTag(gpsLongitudeRef, "E"),
+	rationalTag(gpsLongitude, 37, 1, 37, 1, 45, 10),
+}
+
+func TestReadLocation(t *testing.T) {
+	tests := []struct {
+		name             string
+		data             func(t *testing.T) []byte
+		expectedLocation *Location
+		expectedErr      error
+	}{
+		{
+			name: "JPEG",
+			data: func(t *testing.T) []byte {
+				return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), gpsEXIF(moscowGPS...)...)))
+			},
+			expectedLocation: &Location{Latitude: 55.755833, Longitude: 37.617917},
+		},
+		{
+			name: "Southern and western hemispheres",
+			data: func(t *testing.T) []byte {
+				return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), gpsEXIF(
+					asciiTag(gpsLatitudeRef, "S"),
+					rationalTag(gpsLatitude, 3390, 100, 0, 1, 0, 1),
+					asciiTag(gpsLongitudeRef, "W"),
+					rationalTag(gpsLongitude, 7040, 100, 0, 0, 0, 0),
+				)...)))
+			},
+			expectedLocation: &Location{Latitude: -33.9, Longitude: -70.4},
+		},
+		{
+			name: "PNG",
+			data: func(t *testing.T) []byte {
+				return testPNGWithChunks(t, pngChunkBytes("eXIf", gpsEXIF(moscowGPS...)))
+			},
+			expectedLocation: &Location{Latitude: 55.755833, Longitude: 37.617917},
+		},
+		{
+			name: "Void GPS status",
+			data: func(t *testing.T) []byte {
+				return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...),
+					gpsEXIF(append([]testTag{asciiTag(gpsStatus, "V")}, moscowGPS...)...)...)))
+			},
+		},
+		{
+			name: "No longitude",
+			data: func(t *testing.T) []byte {
+				return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), gpsEXIF(moscowGPS[:2]...)...)))
+			},
+		},
+		{
+			name: "No GPS",
+			data: func(t *testing.T) []byte {
+				stripped, err := StripMetadata(testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), testEXIF()...))), StripPrivate)
+				require.NoError(t, err)
+				return stripped
+			},
+		},
+		{
+			name: "No EXIF",
+			data: func(t *testing.T) []byte { return testJPEG(t) },
+		},
+		{
+			name: "Out of range",
+			data: func(t *testing.T) []byte {
+				return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), gpsEXIF(
+					asciiTag(gpsLatitudeRef, "N"),
+					rationalTag(gpsLatitude, 95, 1, 0, 1, 0, 1),
+					asciiTag(gpsLongitudeRef, "E"),
+					rationalTag(gpsLongitude, 10, 1, 0, 1, 0, 1),
+				)...)))
+			},
+			expectedErr: MalformedImageError,
+		},
+		{
+			name: "Truncated EXIF",
+			data: func(t *testing.T) []byte {
+				return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), gpsEXIF(moscowGPS...)[:30]...)))
+			},
+			expectedErr: MalformedImageError,
+		},
+		{
+			name:        "Unsupported format",
+			data:        func(t *testing.T) []byte { return []byte("GIF89a") },
+			expectedErr: UnsupportedFormatError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := ReadLocation(tt.data(t))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.expectedLocation == nil {
+				assert.Nil(t, location)
+				return
+			}
+			require.NotNil(t, location)
+			assert.InDelta(t, tt.expectedLocation.Latitude, location.Latitude, 1e-6)
+			assert.InDelta(t, tt.expectedLocation.Longitude, location.Longitude, 1e-6)
+		})
+	}
+}
+
+func testPNGWithChunks(t *testing.T, chunks ...[]byte) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+
+	// Чанки вставляются сразу после IHDR
+	data := buf.Bytes()
+	ihdrEnd := len(pngSignature) + 12 + int(binary.BigEndian.Uint32(data[len(pngSignature):]))
+	out := append([]byte{}, data[:ihdrEnd]...)
+	for _, c := range chunks {
+		out = append(out, c...)
+	}
+	return append(out, data[ihdrEnd:]...)
+}
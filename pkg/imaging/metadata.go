@@ -0,0 +1,343 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// MalformedImageError возвращается, если структуру файла изображения не удалось разобрать.
+var MalformedImageError = errors.New("malformed image")
+
+// StripMode определяет, какие метаданные удаляет StripMetadata.
+type StripMode int
+
+const (
+	// StripPrivate удаляет геолокацию, сведения о владельце и серийные номера, остальные EXIF теги сохраняются.
+	// XMP и IPTC удаляются целиком: в них могут быть те же сведения.
+	StripPrivate StripMode = iota + 1
+	// StripAll удаляет все метаданные, кроме необходимых для отображения (JFIF, ICC профиль, Adobe).
+	StripAll
+)
+
+var (
+	jpegSOI      = []byte{0xFF, 0xD8}
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+	exifHeader = []byte("Exif\x00\x00")
+	iccHeader  = []byte("ICC_PROFILE\x00")
+)
+
+// StripMetadata возвращает копию JPEG или PNG файла без метаданных, выбранных mode.
+// Сжатые данные изображения не перекодируются. Для других форматов возвращает UnsupportedFormatError.
+func StripMetadata(data []byte, mode StripMode) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, jpegSOI):
+		return stripJPEG(data, mode)
+	case bytes.HasPrefix(data, pngSignature):
+		return stripPNG(data, mode)
+	default:
+		return nil, fmt.Errorf("%w: metadata can be stripped only from JPEG and PNG", UnsupportedFormatError)
+	}
+}
+
+func stripJPEG(data []byte, mode StripMode) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, jpegSOI...)
+
+	pos := len(jpegSOI)
+	for {
+		if pos+2 > len(data) || data[pos] != 0xFF {
+			return nil, fmt.Errorf("%w: no JPEG marker at offset %d", MalformedImageError, pos)
+		}
+		marker := data[pos+1]
+		switch {
+		case marker == 0xFF:
+			// Байт заполнения перед маркером
+			pos++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			out = append(out, data[pos:pos+2]...)
+			pos += 2
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// После начала скана метаданных не бывает, остаток копируется как есть
+			return append(out, data[pos:]...), nil
+		}
+
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf("%w: truncated JPEG segment at offset %d", MalformedImageError, pos)
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end > len(data) || end < pos+4 {
+			return nil, fmt.Errorf("%w: invalid JPEG segment length at offset %d", MalformedImageError, pos)
+		}
+
+		segment := jpegSegment(marker, data[pos+4:end], mode)
+		if segment != nil {
+			out = append(out, 0xFF, marker)
+			out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+			out = append(out, segment...)
+		}
+		pos = end
+	}
+}
+
+// jpegSegment возвращает содержимое сегмента, которое остается в файле, или nil, если сегмент удаляется.
+func jpegSegment(marker byte, payload []byte, mode StripMode) []byte {
+	switch {
+	case marker == 0xE0 || marker == 0xEE:
+		// APP0 (JFIF) и APP14 (Adobe) нужны для правильного декодирования цветов
+		return payload
+	case marker == 0xE2:
+		// ICC профиль сохраняется, остальные APP2 (например, MPF с превью и их EXIF) удаляются
+		if bytes.HasPrefix(payload, iccHeader) {
+			return payload
+		}
+		return nil
+	case marker == 0xE1 && mode == StripPrivate && bytes.HasPrefix(payload, exifHeader):
+		tiff, err := stripPrivateTIFF(payload[len(exifHeader):])
+		if err != nil {
+			// Неразобранный EXIF может содержать геолокацию, поэтому удаляется целиком
+			return nil
+		}
+		return append(append([]byte{}, exifHeader...), tiff...)
+	case marker == 0xFE:
+		if mode == StripPrivate {
+			return payload
+		}
+		return nil
+	case marker >= 0xE0 && marker <= 0xEF:
+		return nil
+	default:
+		return payload
+	}
+}
+
+func stripPNG(data []byte, mode StripMode) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, fmt.Errorf("%w: truncated PNG chunk at offset %d", MalformedImageError, pos)
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if end > len(data) {
+			return nil, fmt.Errorf("%w: invalid PNG chunk length at offset %d", MalformedImageError, pos)
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		chunkData := data[pos+8 : pos+8+length]
+
+		switch keep, replaced := pngChunk(chunkType, chunkData, mode); {
+		case replaced != nil:
+			out = binary.BigEndian.AppendUint32(out, uint32(len(replaced)))
+			out = append(out, chunkType...)
+			out = append(out, replaced...)
+			crc := crc32.NewIEEE()
+			crc.Write([]byte(chunkType))
+			crc.Write(replaced)
+			out = binary.BigEndian.AppendUint32(out, crc.Sum32())
+		case keep:
+			out = append(out, data[pos:end]...)
+		}
+
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+// pngChunk решает, остается ли чанк в файле. Если содержимое чанка изменено, возвращает его в replaced.
+func pngChunk(chunkType string, data []byte, mode StripMode) (keep bool, replaced []byte) {
+	switch chunkType {
+	case "eXIf":
+		if mode == StripAll {
+			return false, nil
+		}
+		tiff, err := stripPrivateTIFF(data)
+		if err != nil {
+			return false, nil
+		}
+		return true, tiff
+	case "tEXt", "zTXt", "iTXt":
+		if mode == StripAll {
+			return false, nil
+		}
+		keyword, _, _ := bytes.Cut(data, []byte{0})
+		return !isPrivatePNGKeyword(string(keyword)), nil
+	case "tIME":
+		return mode != StripAll, nil
+	default:
+		return true, nil
+	}
+}
+
+// isPrivatePNGKeyword сообщает, может ли текстовый чанк с этим ключевым словом содержать сведения о владельце
+// или встроенные EXIF и XMP, которые записывают некоторые редакторы.
+func isPrivatePNGKeyword(keyword string) bool {
+	return keyword == "Author" || keyword == "XML:com.adobe.xmp" || strings.HasPrefix(keyword, "Raw profile type")
+}
+
+// EXIF теги, удаляемые StripPrivate, по IFD, в которых они встречаются.
+const (
+	tagExifIFD = 0x8769
+	tagGPSIFD  = 0x8825
+)
+
+var (
+	privateIFD0Tags = map[uint16]bool{
+		tagGPSIFD: true,
+		0x013B:    true, // Artist
+		0x013C:    true, // HostComputer
+		0x9C9D:    true, // XPAuthor
+		0xC62F:    true, // CameraSerialNumber (DNG)
+	}
+	privateExifTags = map[uint16]bool{
+		0x927C: true, // MakerNote: у большинства камер содержит серийные номера, иногда и координаты
+		0xA420: true, // ImageUniqueID
+		0xA430: true, // CameraOwnerName
+		0xA431: true, // BodySerialNumber
+		0xA435: true, // LensSerialNumber
+	}
+)
+
+// stripPrivateTIFF возвращает копию EXIF данных (TIFF структуры) без приватных тегов.
+// Теги удаляются на месте: записи IFD сдвигаются, а их значения и GPS IFD затираются нулями,
+// поэтому смещения остальных значений не меняются.
+func stripPrivateTIFF(data []byte) ([]byte, error) {
+	t := &tiff{data: append([]byte{}, data...)}
+	if len(t.data) < 8 {
+		return nil, fmt.Errorf("%w: truncated TIFF header", MalformedImageError)
+	}
+	switch string(t.data[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("%w: invalid TIFF byte order", MalformedImageError)
+	}
+
+	// IFD0 описывает изображение, IFD1 - миниатюру; в обоих могут быть одни и те же теги
+	offset := int(t.order.Uint32(t.data[4:]))
+	for i := 0; offset != 0 && i < 2; i++ {
+		next, exifOffset, err := t.stripIFD(offset, privateIFD0Tags)
+		if err != nil {
+			return nil, err
+		}
+		if exifOffset != 0 {
+			if _, _, err := t.stripIFD(exifOffset, privateExifTags); err != nil {
+				return nil, err
+			}
+		}
+		offset = next
+	}
+
+	return t.data, nil
+}
+
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// tiffTypeSizes размеры значений TIFF по номеру типа.
+var tiffTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8, 13: 4}
+
+// stripIFD удаляет из IFD по смещению offset теги из private. Возвращает смещение следующего IFD
+// и смещение Exif IFD, если IFD на него ссылается.
+func (t *tiff) stripIFD(offset int, private map[uint16]bool) (next, exifOffset int, err error) {
+	entries, err := t.ifdEntries(offset)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.tag == tagExifIFD {
+			exifOffset = int(t.order.Uint32(e.raw[8:]))
+		}
+		if !private[e.tag] {
+			kept = append(kept, e)
+			continue
+		}
+
+		if e.tag == tagGPSIFD {
+			t.clearIFD(int(t.order.Uint32(e.raw[8:])))
+		}
+		t.clearValue(e)
+	}
+
+	next = int(t.order.Uint32(t.data[offset+2+len(entries)*12:]))
+	if len(kept) == len(entries) {
+		return next, exifOffset, nil
+	}
+
+	// Записи переписываются сдвинутыми, освободившееся место затирается нулями
+	ifd := t.data[offset : offset+2+len(entries)*12+4]
+	rewritten := make([]byte, 2, len(ifd))
+	t.order.PutUint16(rewritten, uint16(len(kept)))
+	for _, e := range kept {
+		rewritten = append(rewritten, e.raw...)
+	}
+	clear(ifd)
+	copy(ifd, rewritten)
+	t.order.PutUint32(ifd[len(rewritten):], uint32(next))
+
+	return next, exifOffset, nil
+}
+
+type ifdEntry struct {
+	tag uint16
+	raw []byte
+}
+
+func (t *tiff) ifdEntries(offset int) ([]ifdEntry, error) {
+	if offset < 8 || offset+2 > len(t.data) {
+		return nil, fmt.Errorf("%w: IFD offset %d is out of range", MalformedImageError, offset)
+	}
+	count := int(t.order.Uint16(t.data[offset:]))
+	if offset+2+count*12+4 > len(t.data) {
+		return nil, fmt.Errorf("%w: IFD at offset %d is truncated", MalformedImageError, offset)
+	}
+
+	entries := make([]ifdEntry, count)
+	for i := range entries {
+		raw := t.data[offset+2+i*12 : offset+2+(i+1)*12]
+		entries[i] = ifdEntry{tag: t.order.Uint16(raw), raw: append([]byte{}, raw...)}
+	}
+	return entries, nil
+}
+
+// clearValue затирает значение тега, если оно хранится вне записи IFD.
+func (t *tiff) clearValue(e ifdEntry) {
+	size := tiffTypeSizes[t.order.Uint16(e.raw[2:])] * int(t.order.Uint32(e.raw[4:]))
+	if size <= 4 {
+		return
+	}
+	valueOffset := int(t.order.Uint32(e.raw[8:]))
+	if valueOffset >= 8 && valueOffset+size <= len(t.data) && valueOffset+size > valueOffset {
+		clear(t.data[valueOffset : valueOffset+size])
+	}
+}
+
+// clearIFD затирает IFD вместе со значениями его тегов. Некорректный IFD пропускается.
+func (t *tiff) clearIFD(offset int) {
+	entries, err := t.ifdEntries(offset)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		t.clearValue(e)
+	}
+	clear(t.data[offset : offset+2+len(entries)*12+4])
+}
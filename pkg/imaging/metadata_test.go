@@ -0,0 +1,251 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTag struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+func asciiTag(tag uint16, s string) testTag {
+	return testTag{tag: tag, typ: 2, count: uint32(len(s) + 1), value: append([]byte(s), 0)}
+}
+
+func longTag(tag uint16, v uint32) testTag {
+	return testTag{tag: tag, typ: 4, count: 1, value: binary.LittleEndian.AppendUint32(nil, v)}
+}
+
+// testEXIF собирает EXIF (little endian TIFF) с производителем, автором, серийным номером и координатами.
+func testEXIF() []byte {
+	latitude := binary.LittleEndian.AppendUint32(nil, 55)
+	latitude = binary.LittleEndian.AppendUint32(latitude, 1)
+	latitude = binary.LittleEndian.AppendUint32(latitude, 4512)
+	latitude = binary.LittleEndian.AppendUint32(latitude, 100)
+
+	exif := []testTag{
+		asciiTag(0x9003, "2024:01:01 00:00:00"),
+		asciiTag(0xA431, "SN-12345678"),
+	}
+	gps := []testTag{
+		asciiTag(0x0001, "N"),
+		{tag: 0x0002, typ: 5, count: 2, value: latitude},
+	}
+
+	ifdSize := func(n int) int { return 2 + 12*n + 4 }
+	ifd0Offset := 8
+	exifOffset := ifd0Offset + ifdSize(4)
+	gpsOffset := exifOffset + ifdSize(len(exif))
+	dataOffset := gpsOffset + ifdSize(len(gps))
+
+	ifd0 := []testTag{
+		asciiTag(0x010F, "Canon"),
+		asciiTag(0x013B, "John Doe"),
+		longTag(tagExifIFD, uint32(exifOffset)),
+		longTag(tagGPSIFD, uint32(gpsOffset)),
+	}
+
+	buf := make([]byte, dataOffset)
+	copy(buf, "II*\x00")
+	binary.LittleEndian.PutUint32(buf[4:], uint32(ifd0Offset))
+
+	writeIFD := func(offset int, tags []testTag) {
+		binary.LittleEndian.PutUint16(buf[offset:], uint16(len(tags)))
+		for i, tag := range tags {
+			entry := buf[offset+2+i*12:]
+			binary.LittleEndian.PutUint16(entry, tag.tag)
+			binary.LittleEndian.PutUint16(entry[2:], tag.typ)
+			binary.LittleEndian.PutUint32(entry[4:], tag.count)
+			if len(tag.value) <= 4 {
+				copy(entry[8:12], tag.value)
+				continue
+			}
+			binary.LittleEndian.PutUint32(entry[8:], uint32(len(buf)))
+			buf = append(buf, tag.value...)
+		}
+	}
+	writeIFD(ifd0Offset, ifd0)
+	writeIFD(exifOffset, exif)
+	writeIFD(gpsOffset, gps)
+
+	return buf
+}
+
+func testJPEG(t *testing.T, segments ...[]byte) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil))
+
+	data := append([]byte{}, jpegSOI...)
+	for _, s := range segments {
+		data = append(data, s...)
+	}
+	return append(data, buf.Bytes()[len(jpegSOI):]...)
+}
+
+func jpegAppSegment(marker byte, payload []byte) []byte {
+	s := []byte{0xFF, marker}
+	s = binary.BigEndian.AppendUint16(s, uint16(len(payload)+2))
+	return append(s, payload...)
+}
+
+// jpegSegmentsOf возвращает содержимое сегментов JPEG до начала скана по маркерам.
+func jpegSegmentsOf(t *testing.T, data []byte) map[byte][][]byte {
+	segments := make(map[byte][][]byte)
+	pos := 2
+	for data[pos+1] != 0xDA {
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		require.LessOrEqual(t, end, len(data))
+		segments[data[pos+1]] = append(segments[data[pos+1]], data[pos+4:end])
+		pos = end
+	}
+	return segments
+}
+
+func tagsOf(t *testing.T, tf *tiff, offset int) map[uint16]bool {
+	entries, err := tf.ifdEntries(offset)
+	require.NoError(t, err)
+
+	tags := make(map[uint16]bool)
+	for _, e := range entries {
+		tags[e.tag] = true
+	}
+	return tags
+}
+
+func TestStripMetadata_JPEG(t *testing.T) {
+	xmp := append([]byte("http://ns.adobe.com/xap/1.0/\x00"), `<x:xmpmeta><exif:GPSLatitude>55,45.12N</exif:GPSLatitude></x:xmpmeta>`...)
+	icc := append(append([]byte{}, iccHeader...), "\x01\x01profile"...)
+
+	src := testJPEG(t,
+		jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), testEXIF()...)),
+		jpegAppSegment(0xE1, xmp),
+		jpegAppSegment(0xED, []byte("Photoshop 3.0\x00IPTC byline")),
+		jpegAppSegment(0xE2, icc),
+		jpegAppSegment(0xFE, []byte("holiday")),
+	)
+
+	t.Run("Private", func(t *testing.T) {
+		data, err := StripMetadata(src, StripPrivate)
+		require.NoError(t, err)
+
+		_, err = jpeg.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		segments := jpegSegmentsOf(t, data)
+		require.Len(t, segments[0xE1], 1)
+		assert.Empty(t, segments[0xED])
+		assert.Equal(t, [][]byte{icc}, segments[0xE2])
+		assert.Equal(t, [][]byte{[]byte("holiday")}, segments[0xFE])
+
+		exif := segments[0xE1][0][len(exifHeader):]
+		assert.Len(t, exif, len(testEXIF()), "offsets of remaining values must not change")
+		assert.Contains(t, string(exif), "Canon")
+		assert.Contains(t, string(exif), "2024:01:01 00:00:00")
+		assert.NotContains(t, string(exif), "John Doe")
+		assert.NotContains(t, string(exif), "SN-12345678")
+		assert.False(t, bytes.Contains(exif, binary.LittleEndian.AppendUint32(nil, 4512)), "GPS values must be cleared")
+
+		tf := &tiff{data: exif, order: binary.LittleEndian}
+		assert.Equal(t, map[uint16]bool{0x010F: true, tagExifIFD: true}, tagsOf(t, tf, 8))
+		assert.Equal(t, map[uint16]bool{0x9003: true}, tagsOf(t, tf, 8+2+12*4+4))
+	})
+
+	t.Run("All", func(t *testing.T) {
+		data, err := StripMetadata(src, StripAll)
+		require.NoError(t, err)
+
+		_, err = jpeg.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		segments := jpegSegmentsOf(t, data)
+		assert.Empty(t, segments[0xE1])
+		assert.Empty(t, segments[0xED])
+		assert.Empty(t, segments[0xFE])
+		assert.Equal(t, [][]byte{icc}, segments[0xE2])
+		assert.NotEmpty(t, segments[0xDB], "quantization tables must be kept")
+	})
+
+	t.Run("Malformed EXIF is removed", func(t *testing.T) {
+		exif := append(append([]byte{}, exifHeader...), "II*\x00\xff\xff\x00\x00"...)
+
+		data, err := StripMetadata(testJPEG(t, jpegAppSegment(0xE1, exif)), StripPrivate)
+		require.NoError(t, err)
+		assert.Empty(t, jpegSegmentsOf(t, data)[0xE1])
+	})
+}
+
+func pngChunkBytes(chunkType string, data []byte) []byte {
+	c := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	c = append(c, chunkType...)
+	c = append(c, data...)
+	return binary.BigEndian.AppendUint32(c, crc32.ChecksumIEEE(c[4:]))
+}
+
+func pngChunkTypes(data []byte) []string {
+	var types []string
+	for pos := len(pngSignature); pos < len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		types = append(types, string(data[pos+4:pos+8]))
+		pos += 12 + length
+	}
+	return types
+}
+
+func TestStripMetadata_PNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	encoded := buf.Bytes()
+
+	// Метаданные вставляются сразу после IHDR
+	ihdrEnd := len(pngSignature) + 12 + int(binary.BigEndian.Uint32(encoded[len(pngSignature):]))
+	src := append([]byte{}, encoded[:ihdrEnd]...)
+	src = append(src, pngChunkBytes("tEXt", []byte("Title\x00Sunset"))...)
+	src = append(src, pngChunkBytes("tEXt", []byte("Author\x00John Doe"))...)
+	src = append(src, pngChunkBytes("eXIf", testEXIF())...)
+	src = append(src, encoded[ihdrEnd:]...)
+
+	t.Run("Private", func(t *testing.T) {
+		data, err := StripMetadata(src, StripPrivate)
+		require.NoError(t, err)
+
+		_, err = png.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"IHDR", "tEXt", "eXIf", "IDAT", "IEND"}, pngChunkTypes(data))
+		assert.Contains(t, string(data), "Sunset")
+		assert.Contains(t, string(data), "Canon")
+		assert.NotContains(t, string(data), "John Doe")
+		assert.NotContains(t, string(data), "SN-12345678")
+	})
+
+	t.Run("All", func(t *testing.T) {
+		data, err := StripMetadata(src, StripAll)
+		require.NoError(t, err)
+
+		_, err = png.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"IHDR", "IDAT", "IEND"}, pngChunkTypes(data))
+	})
+}
+
+func TestStripMetadata_Errors(t *testing.T) {
+	_, err := StripMetadata([]byte("GIF89a"), StripAll)
+	assert.ErrorIs(t, err, UnsupportedFormatError)
+
+	truncated := testJPEG(t, jpegAppSegment(0xE1, []byte("Exif\x00\x00")))[:6]
+	_, err = StripMetadata(truncated, StripAll)
+	assert.ErrorIs(t, err, MalformedImageError)
+}
@@ -0,0 +1,83 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlurHash(t *testing.T) {
+	t.Run("Solid color", func(t *testing.T) {
+		hash, err := BlurHash(filled(40, 30, color.NRGBA{R: 255, A: 255}), 4, 3)
+		require.NoError(t, err)
+
+		// Размер 4x3 и DC #FF0000
+		assert.Len(t, hash, 1+1+4+2*11)
+		assert.Equal(t, "L", hash[:1])
+		assert.Equal(t, "TI:j", hash[2:6])
+	})
+
+	t.Run("Gradient", func(t *testing.T) {
+		img := image.NewNRGBA(image.Rect(0, 0, 300, 200))
+		for y := 0; y < 200; y++ {
+			for x := 0; x < 300; x++ {
+				img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 255 / 299), B: uint8(y * 255 / 199), A: 255})
+			}
+		}
+
+		hash, err := BlurHash(img, 4, 3)
+		require.NoError(t, err)
+		assert.Len(t, hash, 1+1+4+2*11)
+		assert.Equal(t, "L", hash[:1])
+
+		single, err := BlurHash(img, 1, 1)
+		require.NoError(t, err)
+		assert.Len(t, single, 6)
+	})
+
+	t.Run("Invalid components", func(t *testing.T) {
+		_, err := BlurHash(filled(4, 4, color.NRGBA{A: 255}), 0, 3)
+		assert.Error(t, err)
+		_, err = BlurHash(filled(4, 4, color.NRGBA{A: 255}), 4, 10)
+		assert.Error(t, err)
+	})
+
+	t.Run("Empty image", func(t *testing.T) {
+		_, err := BlurHash(image.NewNRGBA(image.Rect(0, 0, 0, 0)), 4, 3)
+		assert.Error(t, err)
+	})
+}
+
+func TestDominantColors(t *testing.T) {
+	blue := color.NRGBA{R: 0x33, G: 0x66, B: 0xCC, A: 255}
+	red := color.NRGBA{R: 0xCC, G: 0x22, B: 0x11, A: 255}
+
+	// Три четверти синего с близким оттенком, четверть красного и прозрачная полоса
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			switch {
+			case y < 4:
+				img.SetNRGBA(x, y, color.NRGBA{G: 0xFF})
+			case x < 16:
+				img.SetNRGBA(x, y, red)
+			case x < 24:
+				img.SetNRGBA(x, y, color.NRGBA{R: 0x35, G: 0x68, B: 0xD4, A: 255})
+			default:
+				img.SetNRGBA(x, y, blue)
+			}
+		}
+	}
+
+	colors := DominantColors(img, 5)
+	assert.Equal(t, []color.RGBA{
+		{R: 0x33, G: 0x66, B: 0xCC, A: 255},
+		{R: 0xCC, G: 0x22, B: 0x11, A: 255},
+	}, colors)
+
+	assert.Len(t, DominantColors(img, 1), 1)
+	assert.Empty(t, DominantColors(image.NewNRGBA(image.Rect(0, 0, 8, 8)), 5), "transparent image has no colors")
+}
@@ -0,0 +1,55 @@
+package imaging
+
+import (
+	"strings"
+	"time"
+)
+
+// Теги Exif IFD с датой съемки.
+const (
+	exifDateTimeOriginal  = 0x9003
+	exifDateTimeDigitized = 0x9004
+)
+
+// exifTimeLayout формат дат EXIF. Часовой пояс в дате не указывается.
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// ReadTakenAt возвращает время съемки из EXIF JPEG или PNG файла, или nil, если его нет.
+// Время возвращается в UTC, но означает местное время камеры: EXIF не хранит часовой пояс даты.
+// Для других форматов возвращает UnsupportedFormatError.
+func ReadTakenAt(data []byte) (*time.Time, error) {
+	exif, err := readEXIF(data, "capture time")
+	if err != nil || exif == nil {
+		return nil, err
+	}
+
+	return tiffTakenAt(exif)
+}
+
+// tiffTakenAt читает дату съемки из Exif IFD, а если ее нет, то дату оцифровки.
+// Незаполненные камерой и некорректные даты пропускаются.
+func tiffTakenAt(data []byte) (*time.Time, error) {
+	t, ifd0, err := parseTIFF(data)
+	if err != nil {
+		return nil, err
+	}
+	exif, err := t.subIFD(ifd0, tagExifIFD)
+	if err != nil || exif == nil {
+		return nil, err
+	}
+
+	for _, tag := range []uint16{exifDateTimeOriginal, exifDateTimeDigitized} {
+		e, ok := exif[tag]
+		if !ok {
+			continue
+		}
+		// Неизвестные части даты камеры заполняют пробелами или нулями
+		takenAt, err := time.Parse(exifTimeLayout, strings.TrimSpace(t.ascii(e)))
+		if err != nil || takenAt.Year() < 1800 {
+			continue
+		}
+		return &takenAt, nil
+	}
+
+	return nil, nil
+}
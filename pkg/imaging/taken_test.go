@@ -0,0 +1,90 @@
+package imaging
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTakenAt(t *testing.T) {
+	exifJPEG := func(t *testing.T, tags ...testTag) []byte {
+		return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), subIFDEXIF(tagExifIFD, tags...)...)))
+	}
+
+	tests := []struct {
+		name            string
+		data            func(t *testing.T) []byte
+		expectedTakenAt time.Time
+		expectedErr     error
+	}{
+		{
+			name: "JPEG",
+			data: func(t *testing.T) []byte {
+				return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), testEXIF()...)))
+			},
+			expectedTakenAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "PNG",
+			data: func(t *testing.T) []byte {
+				return testPNGWithChunks(t, pngChunkBytes("eXIf", subIFDEXIF(tagExifIFD, asciiTag(exifDateTimeOriginal, "2019:06:30 18:45:12"))))
+			},
+			expectedTakenAt: time.Date(2019, 6, 30, 18, 45, 12, 0, time.UTC),
+		},
+		{
+			name: "Digitized when original is unknown",
+			data: func(t *testing.T) []byte {
+				return exifJPEG(t,
+					asciiTag(exifDateTimeOriginal, "    :  :     :  :  "),
+					asciiTag(exifDateTimeDigitized, "2020:02:29 23:59:59"))
+			},
+			expectedTakenAt: time.Date(2020, 2, 29, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name: "Zero date",
+			data: func(t *testing.T) []byte { return exifJPEG(t, asciiTag(exifDateTimeOriginal, "0000:00:00 00:00:00")) },
+		},
+		{
+			name: "No Exif IFD",
+			data: func(t *testing.T) []byte {
+				return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), gpsEXIF(moscowGPS...)...)))
+			},
+		},
+		{
+			name: "No EXIF",
+			data: func(t *testing.T) []byte { return testJPEG(t) },
+		},
+		{
+			name: "Truncated EXIF",
+			data: func(t *testing.T) []byte {
+				return testJPEG(t, jpegAppSegment(0xE1, append(append([]byte{}, exifHeader...), testEXIF()[:30]...)))
+			},
+			expectedErr: MalformedImageError,
+		},
+		{
+			name:        "Unsupported format",
+			data:        func(t *testing.T) []byte { return []byte("GIF89a") },
+			expectedErr: UnsupportedFormatError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			takenAt, err := ReadTakenAt(tt.data(t))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.expectedTakenAt.IsZero() {
+				assert.Nil(t, takenAt)
+				return
+			}
+			require.NotNil(t, takenAt)
+			assert.Equal(t, tt.expectedTakenAt, *takenAt)
+		})
+	}
+}
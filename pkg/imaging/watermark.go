@@ -0,0 +1,123 @@
+package imaging
+
+import (
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+)
+
+// Position угол или центр изображения, к которому прижимается водяной знак.
+type Position string
+
+const (
+	TopLeft     Position = "top-left"
+	TopRight    Position = "top-right"
+	BottomLeft  Position = "bottom-left"
+	BottomRight Position = "bottom-right"
+	Center      Position = "center"
+)
+
+// WatermarkOptions параметры наложения водяного знака.
+type WatermarkOptions struct {
+	Position Position
+	// Opacity непрозрачность знака от 0 (невидим) до 1
+	Opacity float64
+	// Scale ширина знака относительно ширины изображения, от 0 до 1.
+	// Знак, который по высоте не помещается в изображение, уменьшается до его высоты.
+	Scale float64
+}
+
+// Watermark накладывает знак mark на копию src. Знак масштабируется с сохранением пропорций
+// и отступает от краев на 2% меньшей стороны изображения.
+func Watermark(src, mark image.Image, opts WatermarkOptions) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+
+	mb := mark.Bounds()
+	if mb.Empty() || opts.Opacity <= 0 {
+		return dst
+	}
+
+	w := max(1, int(float64(b.Dx())*opts.Scale))
+	h := max(1, mb.Dy()*w/mb.Dx())
+	if h > b.Dy() {
+		w, h = max(1, mb.Dx()*b.Dy()/mb.Dy()), b.Dy()
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), mark, mb, draw.Src, nil)
+
+	at := watermarkOrigin(dst.Bounds().Size(), scaled.Bounds().Size(), opts.Position)
+	mask := image.NewUniform(color.Alpha{A: clampUint8(opts.Opacity * 255)})
+	draw.DrawMask(dst, scaled.Bounds().Add(at), scaled, image.Point{}, mask, image.Point{}, draw.Over)
+
+	return dst
+}
+
+// watermarkOrigin левый верхний угол знака размера mark на изображении размера img.
+func watermarkOrigin(img, mark image.Point, pos Position) image.Point {
+	margin := min(img.X, img.Y) / 50
+	left, top := margin, margin
+	right, bottom := max(0, img.X-mark.X-margin), max(0, img.Y-mark.Y-margin)
+
+	switch pos {
+	case TopLeft:
+		return image.Pt(left, top)
+	case TopRight:
+		return image.Pt(right, top)
+	case BottomLeft:
+		return image.Pt(left, bottom)
+	case Center:
+		return image.Pt((img.X-mark.X)/2, (img.Y-mark.Y)/2)
+	default:
+		return image.Pt(right, bottom)
+	}
+}
+
+// textFace шрифт текстовых водяных знаков. Растровый, поэтому знак рисуется в маленьком размере
+// и растягивается до нужной ширины при наложении.
+var textFace = basicfont.Face7x13
+
+// CanRenderText сообщает, есть ли в шрифте водяных знаков все символы текста.
+// Шрифт поддерживает только печатные символы ASCII.
+func CanRenderText(text string) bool {
+	for _, r := range text {
+		if _, ok := textFace.GlyphAdvance(r); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// TextMark рисует текст белым с темной тенью, чтобы знак читался на любом фоне.
+// Символы, которых нет в шрифте, пропускаются (см. CanRenderText).
+func TextMark(text string) image.Image {
+	const padding = 2
+
+	d := &font.Drawer{Face: textFace}
+	metrics := textFace.Metrics()
+	width := d.MeasureString(text).Ceil()
+	height := (metrics.Ascent + metrics.Descent).Ceil()
+
+	// Лишний пиксель по каждой стороне под смещенную тень
+	img := image.NewRGBA(image.Rect(0, 0, width+2*padding+1, height+2*padding+1))
+	layers := []struct {
+		color  color.Color
+		offset int
+	}{
+		{color: color.RGBA{A: 192}, offset: 1},
+		{color: color.White, offset: 0},
+	}
+	for _, l := range layers {
+		d.Dst = img
+		d.Src = image.NewUniform(l.color)
+		d.Dot = fixed.P(padding+l.offset, padding+l.offset+metrics.Ascent.Ceil())
+		d.DrawString(text)
+	}
+
+	return img
+}
@@ -0,0 +1,102 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func filled(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestWatermark(t *testing.T) {
+	tests := []struct {
+		name string
+		opts WatermarkOptions
+		// expectedMarked точка, закрытая знаком, expectedClean - точка вне знака
+		expectedMarked image.Point
+		expectedClean  image.Point
+		expectedColor  color.NRGBA
+	}{
+		{
+			name:           "Bottom right",
+			opts:           WatermarkOptions{Position: BottomRight, Opacity: 1, Scale: 0.2},
+			expectedMarked: image.Pt(90, 95),
+			expectedClean:  image.Pt(5, 5),
+			expectedColor:  red,
+		},
+		{
+			name:           "Top left",
+			opts:           WatermarkOptions{Position: TopLeft, Opacity: 1, Scale: 0.2},
+			expectedMarked: image.Pt(5, 3),
+			expectedClean:  image.Pt(90, 95),
+			expectedColor:  red,
+		},
+		{
+			name:           "Center",
+			opts:           WatermarkOptions{Position: Center, Opacity: 1, Scale: 0.5},
+			expectedMarked: image.Pt(50, 50),
+			expectedClean:  image.Pt(5, 5),
+			expectedColor:  red,
+		},
+		{
+			name:           "Half opacity",
+			opts:           WatermarkOptions{Position: Center, Opacity: 0.5, Scale: 0.5},
+			expectedMarked: image.Pt(50, 50),
+			expectedClean:  image.Pt(5, 5),
+			expectedColor:  color.NRGBA{R: 255, G: 127, B: 127, A: 255},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := filled(100, 100, white)
+			mark := filled(20, 10, red)
+
+			img := Watermark(src, mark, tt.opts)
+
+			assert.Equal(t, image.Rect(0, 0, 100, 100), img.Bounds())
+			assert.InDelta(t, tt.expectedColor.G, pixel(img, tt.expectedMarked.X, tt.expectedMarked.Y).G, 1)
+			assert.Equal(t, tt.expectedColor.R, pixel(img, tt.expectedMarked.X, tt.expectedMarked.Y).R)
+			assert.Equal(t, white, pixel(img, tt.expectedClean.X, tt.expectedClean.Y))
+			assert.Equal(t, white, pixel(src, tt.expectedMarked.X, tt.expectedMarked.Y), "source must not change")
+		})
+	}
+}
+
+func TestWatermark_TallMarkFitsHeight(t *testing.T) {
+	img := Watermark(filled(100, 20, white), filled(10, 100, red), WatermarkOptions{Position: TopLeft, Opacity: 1, Scale: 1})
+
+	assert.Equal(t, image.Rect(0, 0, 100, 20), img.Bounds())
+	assert.Equal(t, red, pixel(img, 0, 10))
+	assert.Equal(t, white, pixel(img, 50, 10))
+}
+
+func TestCanRenderText(t *testing.T) {
+	assert.True(t, CanRenderText("(c) John Doe 2024"))
+	assert.False(t, CanRenderText("© Иван"))
+}
+
+func TestTextMark(t *testing.T) {
+	mark := TextMark("Hi")
+
+	b := mark.Bounds()
+	assert.Greater(t, b.Dx(), 10)
+	assert.Greater(t, b.Dy(), 10)
+
+	var hasWhite bool
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if pixel(mark, x, y) == white {
+				hasWhite = true
+			}
+		}
+	}
+	assert.True(t, hasWhite)
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"go-photo/pkg/tracing"
+)
+
+type ctxKey struct{}
+
+// WithContext сохраняет entry в контексте.
+// Все записи, сделанные через FromContext, будут содержать поля entry (например, request_id).
+func WithContext(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, entry)
+}
+
+// FromContext возвращает логгер, привязанный к контексту.
+// Если в контексте нет логгера, возвращает стандартный логгер logrus.
+// Если в контексте есть активный span, добавляет поля trace_id и span_id.
+func FromContext(ctx context.Context) *log.Entry {
+	if ctx == nil {
+		return log.NewEntry(log.StandardLogger())
+	}
+
+	entry, ok := ctx.Value(ctxKey{}).(*log.Entry)
+	if !ok || entry == nil {
+		entry = log.NewEntry(log.StandardLogger())
+	}
+
+	if traceID, spanID, ok := tracing.IDs(ctx); ok {
+		entry = entry.WithFields(log.Fields{
+			"trace_id": traceID,
+			"span_id":  spanID,
+		})
+	}
+
+	return entry.WithContext(ctx)
+}
@@ -0,0 +1,236 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"io/fs"
+	"os"
+)
+
+// lockID ключ advisory lock, под которым выполняются миграции.
+// Гарантирует, что несколько реплик не будут мигрировать одновременно.
+const lockID int64 = 0x676f2d70686f746f // "go-photo"
+
+var (
+	// SchemaOutdatedError возвращается, если версия схемы БД меньше ожидаемой кодом.
+	SchemaOutdatedError = errors.New("database schema is outdated")
+	// SchemaDirtyError возвращается, если предыдущая миграция завершилась с ошибкой.
+	SchemaDirtyError = errors.New("database schema is dirty")
+)
+
+type Migrator struct {
+	db     *sql.DB
+	source fs.FS
+}
+
+// MigrationStatus описывает одну миграцию из источника.
+type MigrationStatus struct {
+	Version    uint
+	Identifier string
+	Applied    bool
+}
+
+type Status struct {
+	// Version текущая версия схемы в БД (0, если миграции не применялись).
+	Version uint
+	Dirty   bool
+	// Latest последняя версия, известная коду.
+	Latest     uint
+	Migrations []MigrationStatus
+}
+
+// New создает мигратор. source должен содержать файлы миграций в корне.
+func New(db *sql.DB, source fs.FS) *Migrator {
+	return &Migrator{
+		db:     db,
+		source: source,
+	}
+}
+
+// Up применяет все непримененные миграции под advisory lock.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(mg *migrate.Migrate) error {
+		err := mg.Up()
+		if errors.Is(err, migrate.ErrNoChange) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Down откатывает steps последних миграций под advisory lock.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	return m.withLock(ctx, func(mg *migrate.Migrate) error {
+		err := mg.Steps(-steps)
+		if errors.Is(err, migrate.ErrNoChange) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Version возвращает текущую версию схемы. Если миграции не применялись, возвращает 0.
+func (m *Migrator) Version(ctx context.Context) (version uint, dirty bool, err error) {
+	err = m.run(ctx, func(mg *migrate.Migrate) error {
+		version, dirty, err = mg.Version()
+		if errors.Is(err, migrate.ErrNilVersion) {
+			return nil
+		}
+		return err
+	})
+
+	return version, dirty, err
+}
+
+// Status возвращает текущую версию схемы и список известных коду миграций.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	migrations, err := m.migrations()
+	if err != nil {
+		return Status{}, err
+	}
+
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{
+		Version:    version,
+		Dirty:      dirty,
+		Migrations: migrations,
+	}
+	for i := range status.Migrations {
+		status.Migrations[i].Applied = status.Migrations[i].Version <= version
+		status.Latest = status.Migrations[i].Version
+	}
+
+	return status, nil
+}
+
+// Latest возвращает последнюю версию миграции, известную коду.
+func (m *Migrator) Latest() (uint, error) {
+	migrations, err := m.migrations()
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// CheckUpToDate возвращает SchemaOutdatedError, если схема в БД отстает от кода,
+// и SchemaDirtyError, если последняя миграция не была завершена.
+func (m *Migrator) CheckUpToDate(ctx context.Context) error {
+	latest, err := m.Latest()
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: version %d", SchemaDirtyError, version)
+	}
+	if version < latest {
+		return fmt.Errorf("%w: current version %d, expected %d", SchemaOutdatedError, version, latest)
+	}
+
+	return nil
+}
+
+func (m *Migrator) migrations() ([]MigrationStatus, error) {
+	src, err := iofs.New(m.source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations source: %w", err)
+	}
+	defer src.Close()
+
+	return listMigrations(src)
+}
+
+func listMigrations(src source.Driver) ([]MigrationStatus, error) {
+	var migrations []MigrationStatus
+
+	version, err := src.First()
+	for err == nil {
+		identifier := ""
+		if r, ident, readErr := src.ReadUp(version); readErr == nil {
+			identifier = ident
+			r.Close()
+		}
+		migrations = append(migrations, MigrationStatus{Version: version, Identifier: identifier})
+
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	return migrations, nil
+}
+
+// withLock выполняет fn, удерживая advisory lock на отдельном соединении.
+// pg_advisory_lock блокируется, пока другая реплика не закончит миграцию (или не истечет ctx).
+func (m *Migrator) withLock(ctx context.Context, fn func(mg *migrate.Migrate) error) error {
+	lockConn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+	defer lockConn.Close()
+
+	_, err = lockConn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Используем фоновый контекст: lock нужно снять даже если ctx уже отменен
+		_, _ = lockConn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+	}()
+
+	return m.run(ctx, fn)
+}
+
+func (m *Migrator) run(ctx context.Context, fn func(mg *migrate.Migrate) error) error {
+	src, err := iofs.New(m.source, ".")
+	if err != nil {
+		return fmt.Errorf("failed to open migrations source: %w", err)
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		src.Close()
+		return fmt.Errorf("failed to get connection for migrations: %w", err)
+	}
+
+	// WithConnection (в отличие от WithInstance) не закрывает пул при Close
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		src.Close()
+		conn.Close()
+		return fmt.Errorf("failed to create migrate driver: %w", err)
+	}
+
+	mg, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return fmt.Errorf("failed to create migrator: %w", err)
+	}
+	defer mg.Close()
+
+	return fn(mg)
+}
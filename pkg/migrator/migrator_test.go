@@ -0,0 +1,69 @@
+package migrator
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-photo/schema"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrator_Latest(t *testing.T) {
+	tests := []struct {
+		name           string
+		source         fstest.MapFS
+		expectedLatest uint
+		expectedIDs    []string
+	}{
+		{
+			name: "Ordered by version",
+			source: fstest.MapFS{
+				"000010_tags.up.sql":   {Data: []byte("SELECT 1;")},
+				"000010_tags.down.sql": {Data: []byte("SELECT 1;")},
+				"000002_b.up.sql":      {Data: []byte("SELECT 1;")},
+				"000002_b.down.sql":    {Data: []byte("SELECT 1;")},
+				"000001_a.up.sql":      {Data: []byte("SELECT 1;")},
+				"000001_a.down.sql":    {Data: []byte("SELECT 1;")},
+			},
+			expectedLatest: 10,
+			expectedIDs:    []string{"a", "b", "tags"},
+		},
+		{
+			name:           "Empty source",
+			source:         fstest.MapFS{},
+			expectedLatest: 0,
+			expectedIDs:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(nil, tt.source)
+
+			latest, err := m.Latest()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedLatest, latest)
+
+			migrations, err := m.migrations()
+			require.NoError(t, err)
+
+			var ids []string
+			for _, mg := range migrations {
+				ids = append(ids, mg.Identifier)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestMigrator_EmbeddedSchema(t *testing.T) {
+	m := New(nil, schema.FS)
+
+	migrations, err := m.migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, mg := range migrations {
+		assert.Equal(t, uint(i+1), mg.Version, "версии миграций должны идти подряд")
+	}
+}
@@ -1,3 +1,6 @@
 package repository
 
-const UniqueViolationErrorCode = "23505"
+const (
+	UniqueViolationErrorCode     = "23505"
+	ForeignKeyViolationErrorCode = "23503"
+)
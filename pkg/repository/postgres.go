@@ -3,6 +3,7 @@ package repository
 import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 type PSQLConfig struct {
@@ -12,6 +13,12 @@ type PSQLConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// Настройки пула соединений. Нулевые значения оставляют значения database/sql по умолчанию.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
 }
 
 type DBifyable interface {
@@ -25,6 +32,19 @@ func NewPostgresDB(cfg PSQLConfig) (*sqlx.DB, error) {
 		return nil, err
 	}
 
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+
 	err = db.Ping()
 	if err != nil {
 		return nil, err
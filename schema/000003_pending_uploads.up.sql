@@ -0,0 +1,15 @@
+-- Журнал незавершенных загрузок. Запись создается до записи файла в staging,
+-- photo_id выставляется в той же транзакции, что и создание фото,
+-- запись удаляется после переноса файла в постоянное хранилище.
+CREATE TABLE pending_uploads
+(
+    id            SERIAL PRIMARY KEY,
+    user_uuid     UUID         NOT NULL,
+    uuid_filename VARCHAR(255) NOT NULL UNIQUE,
+    photo_id      INTEGER      DEFAULT NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (photo_id) REFERENCES photos (id)
+);
+
+CREATE INDEX idx_pending_uploads_created_at ON pending_uploads (created_at);
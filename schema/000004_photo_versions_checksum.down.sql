@@ -0,0 +1 @@
+ALTER TABLE photo_versions DROP COLUMN IF EXISTS checksum;
@@ -0,0 +1,2 @@
+-- SHA-256 содержимого файла версии в hex. NULL у файлов, загруженных до появления колонки.
+ALTER TABLE photo_versions ADD COLUMN checksum CHAR(64) DEFAULT NULL;
@@ -0,0 +1,4 @@
+-- Время переноса фото в корзину. NULL - фото не удалено.
+ALTER TABLE photos ADD COLUMN deleted_at TIMESTAMPTZ DEFAULT NULL;
+
+CREATE INDEX idx_photos_deleted_at ON photos (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_photos_user_uuid_uploaded_at;
+
+ALTER TABLE photos
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS caption,
+    DROP COLUMN IF EXISTS favorite,
+    DROP COLUMN IF EXISTS rating,
+    DROP COLUMN IF EXISTS hidden,
+    DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,11 @@
+-- Редактируемые пользователем атрибуты фото.
+-- updated_at меняется при каждом изменении атрибутов и служит ETag для оптимистичной блокировки.
+ALTER TABLE photos
+    ADD COLUMN title      VARCHAR(255) DEFAULT NULL,
+    ADD COLUMN caption    TEXT         DEFAULT NULL,
+    ADD COLUMN favorite   BOOLEAN      NOT NULL DEFAULT false,
+    ADD COLUMN rating     SMALLINT     NOT NULL DEFAULT 0 CHECK (rating BETWEEN 0 AND 5),
+    ADD COLUMN hidden     BOOLEAN      NOT NULL DEFAULT false,
+    ADD COLUMN updated_at TIMESTAMPTZ  NOT NULL DEFAULT now();
+
+CREATE INDEX idx_photos_user_uuid_uploaded_at ON photos (user_uuid, uploaded_at DESC, id DESC) WHERE deleted_at IS NULL;
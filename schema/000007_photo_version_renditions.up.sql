@@ -0,0 +1,14 @@
+-- Производные файлы версий фото в другом формате (например, WebP), создаваемые при первом запросе.
+-- Файлы хранятся в служебной папке хранилища; записи удаляются вместе с версией.
+CREATE TABLE photo_version_renditions
+(
+    id            SERIAL PRIMARY KEY,
+    version_id    INTEGER      NOT NULL,
+    format        VARCHAR(16)  NOT NULL,
+    uuid_filename VARCHAR(255) NOT NULL,
+    size          INTEGER      NOT NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT now(),
+
+    UNIQUE (version_id, format),
+    FOREIGN KEY (version_id) REFERENCES photo_versions (id) ON DELETE CASCADE
+);
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS photo_edits;
+
+DELETE FROM photo_versions WHERE version_type = 'edited';
+
+-- Значение нельзя удалить из перечисления, поэтому тип пересоздается без него
+ALTER TYPE version_type_enum RENAME TO version_type_enum_old;
+CREATE TYPE version_type_enum AS ENUM ('original', 'thumbnail', 'preview');
+
+ALTER TABLE photo_versions
+    ALTER COLUMN version_type DROP DEFAULT,
+    ALTER COLUMN version_type TYPE version_type_enum USING version_type::text::version_type_enum,
+    ALTER COLUMN version_type SET DEFAULT 'original';
+
+DROP TYPE version_type_enum_old;
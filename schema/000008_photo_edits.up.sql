@@ -0,0 +1,15 @@
+-- Версия фото, полученная применением правок к оригиналу. Оригинал при этом не меняется.
+ALTER TYPE version_type_enum ADD VALUE IF NOT EXISTS 'edited';
+
+-- Рецепт правок фото: список операций в порядке применения к оригиналу.
+-- У фото не больше одной отредактированной версии; рецепт удаляется вместе с ней.
+CREATE TABLE photo_edits
+(
+    photo_id   INTEGER     PRIMARY KEY,
+    version_id INTEGER     NOT NULL,
+    operations JSONB       NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE,
+    FOREIGN KEY (version_id) REFERENCES photo_versions (id) ON DELETE CASCADE
+);
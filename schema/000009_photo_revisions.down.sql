@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_photo_versions_current_original;
+DROP INDEX IF EXISTS idx_photo_versions_revision;
+
+-- Файлы прежних ревизий остаются в хранилище, fsck найдет их как осиротевшие
+DELETE FROM photo_versions WHERE version_type = 'revision';
+
+ALTER TABLE photo_versions DROP COLUMN IF EXISTS revision;
+
+-- Значение нельзя удалить из перечисления, поэтому тип пересоздается без него
+ALTER TYPE version_type_enum RENAME TO version_type_enum_old;
+CREATE TYPE version_type_enum AS ENUM ('original', 'thumbnail', 'preview', 'edited');
+
+ALTER TABLE photo_versions
+    ALTER COLUMN version_type DROP DEFAULT,
+    ALTER COLUMN version_type TYPE version_type_enum USING version_type::text::version_type_enum,
+    ALTER COLUMN version_type SET DEFAULT 'original';
+
+DROP TYPE version_type_enum_old;
@@ -0,0 +1,11 @@
+-- Прежняя ревизия оригинала, замененная загруженным позже файлом.
+-- Текущая ревизия хранится с типом original, поэтому у фото по-прежнему один оригинал.
+ALTER TYPE version_type_enum ADD VALUE IF NOT EXISTS 'revision';
+
+-- Номер ревизии оригинала, начиная с 1. У производных и отредактированных версий не заполнен.
+ALTER TABLE photo_versions ADD COLUMN revision INTEGER DEFAULT NULL;
+
+UPDATE photo_versions SET revision = 1 WHERE version_type = 'original' OR version_type IS NULL;
+
+CREATE UNIQUE INDEX idx_photo_versions_revision ON photo_versions (photo_id, revision);
+CREATE UNIQUE INDEX idx_photo_versions_current_original ON photo_versions (photo_id) WHERE version_type = 'original';
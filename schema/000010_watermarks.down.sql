@@ -0,0 +1,4 @@
+ALTER TABLE published_photo_info
+    DROP COLUMN IF EXISTS watermark;
+
+DROP TABLE IF EXISTS watermark_settings;
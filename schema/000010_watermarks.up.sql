@@ -0,0 +1,20 @@
+-- Настройки водяного знака пользователя: текст или загруженный PNG логотип.
+-- Логотип хранится в служебной папке хранилища, здесь только имя его файла.
+-- updated_at меняется при каждом изменении и входит в ключ кэша публичных вариантов с водяным знаком.
+CREATE TABLE watermark_settings
+(
+    user_uuid     UUID         PRIMARY KEY,
+    type          VARCHAR(8)   NOT NULL CHECK (type IN ('text', 'logo')),
+    text          VARCHAR(255) DEFAULT NULL,
+    logo_filename VARCHAR(255) DEFAULT NULL,
+    position      VARCHAR(16)  NOT NULL DEFAULT 'bottom-right',
+    opacity       SMALLINT     NOT NULL DEFAULT 50 CHECK (opacity BETWEEN 1 AND 100),
+    scale         SMALLINT     NOT NULL DEFAULT 20 CHECK (scale BETWEEN 1 AND 100),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT now(),
+
+    CHECK (type <> 'logo' OR logo_filename IS NOT NULL)
+);
+
+-- Публичные версии фото отдаются с водяным знаком владельца
+ALTER TABLE published_photo_info
+    ADD COLUMN watermark BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+ALTER TABLE published_photo_info
+    DROP COLUMN IF EXISTS metadata_policy;
+
+DROP TABLE IF EXISTS publish_settings;
@@ -0,0 +1,14 @@
+-- Политика метаданных публикуемых файлов: keep - файл отдается как есть, strip_private - без геолокации,
+-- сведений о владельце и серийных номеров, strip_all - без всех метаданных.
+-- Настройки содержат политику пользователя по умолчанию, ее можно переопределить при публикации.
+CREATE TABLE publish_settings
+(
+    user_uuid       UUID        PRIMARY KEY,
+    metadata_policy VARCHAR(16) NOT NULL CHECK (metadata_policy IN ('keep', 'strip_private', 'strip_all')),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Существующие публикации продолжают отдавать файлы без изменений
+ALTER TABLE published_photo_info
+    ADD COLUMN metadata_policy VARCHAR(16) NOT NULL DEFAULT 'keep'
+        CHECK (metadata_policy IN ('keep', 'strip_private', 'strip_all'));
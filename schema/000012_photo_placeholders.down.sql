@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS photo_versions_blurhash_missing_idx;
+
+ALTER TABLE photo_versions
+    DROP COLUMN IF EXISTS palette,
+    DROP COLUMN IF EXISTS blurhash;
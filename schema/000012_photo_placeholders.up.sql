@@ -0,0 +1,7 @@
+-- Заглушки для отображения фото до загрузки миниатюры: BlurHash и преобладающие цвета в виде #rrggbb.
+-- NULL - заглушка еще не рассчитана (ее рассчитает фоновая задача), пустая строка - файл не удалось разобрать.
+ALTER TABLE photo_versions
+    ADD COLUMN blurhash TEXT   DEFAULT NULL,
+    ADD COLUMN palette  TEXT[] DEFAULT NULL;
+
+CREATE INDEX photo_versions_blurhash_missing_idx ON photo_versions (id) WHERE blurhash IS NULL;
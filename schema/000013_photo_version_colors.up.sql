@@ -0,0 +1,20 @@
+-- Преобладающие цвета версий фото в CIE L*a*b* для поиска по цвету.
+-- bucket - номер ячейки сетки Lab со стороной 10: по нему индекс отбирает кандидатов,
+-- а точное расстояние проверяется по l, a, b.
+CREATE TABLE photo_version_colors
+(
+    version_id INTEGER  NOT NULL,
+    position   SMALLINT NOT NULL,
+    l          REAL     NOT NULL,
+    a          REAL     NOT NULL,
+    b          REAL     NOT NULL,
+    bucket     INTEGER  NOT NULL,
+
+    PRIMARY KEY (version_id, position),
+    FOREIGN KEY (version_id) REFERENCES photo_versions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX photo_version_colors_bucket_idx ON photo_version_colors (bucket, version_id);
+
+-- Палитры, рассчитанные раньше, не попали в таблицу: их заново рассчитает фоновая задача
+UPDATE photo_versions SET blurhash = NULL, palette = NULL WHERE palette IS NOT NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS photo_versions_location_idx;
+DROP INDEX IF EXISTS photo_versions_exif_missing_idx;
+
+ALTER TABLE photo_versions
+    DROP COLUMN IF EXISTS exif_extracted,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,14 @@
+-- Координаты съемки из EXIF оригинала. exif_extracted - EXIF уже прочитан из файла
+-- (в файле его может не быть), иначе его прочитает фоновая задача. Признак общий для всех
+-- сведений из EXIF: они лежат в одних IFD и читаются из файла вместе.
+ALTER TABLE photo_versions
+    ADD COLUMN latitude       DOUBLE PRECISION DEFAULT NULL,
+    ADD COLUMN longitude      DOUBLE PRECISION DEFAULT NULL,
+    ADD COLUMN exif_extracted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX photo_versions_exif_missing_idx ON photo_versions (id)
+    WHERE NOT exif_extracted AND version_type = 'original';
+
+-- Поиск фото на карте: point(долгота, широта) внутри прямоугольника
+CREATE INDEX photo_versions_location_idx ON photo_versions USING gist (point(longitude, latitude))
+    WHERE version_type = 'original' AND latitude IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE photo_versions
+    DROP COLUMN IF EXISTS taken_at;
@@ -0,0 +1,4 @@
+-- Время съемки из EXIF оригинала: местное время камеры без часового пояса.
+-- Читается из файла вместе с координатами, признак exif_extracted общий.
+ALTER TABLE photo_versions
+    ADD COLUMN taken_at TIMESTAMP DEFAULT NULL;
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS photos_search_vector_idx;
+
+DROP TRIGGER IF EXISTS photo_versions_search_vector_update ON photo_versions;
+DROP FUNCTION IF EXISTS photo_versions_search_vector_update();
+DROP TRIGGER IF EXISTS photos_search_vector_update ON photos;
+DROP FUNCTION IF EXISTS photos_search_vector_update();
+DROP FUNCTION IF EXISTS photo_search_vector(INTEGER, TEXT, TEXT, TEXT);
+
+ALTER TABLE photos
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE photo_versions
+    DROP COLUMN IF EXISTS camera_model,
+    DROP COLUMN IF EXISTS camera_make;
@@ -0,0 +1,57 @@
+-- Полнотекстовый поиск фото. Документ собирается из названия, подписи, имени файла
+-- и камеры текущего оригинала, вес убывает в этом порядке. Конфигурация simple не зависит
+-- от языка: названия и подписи бывают на разных языках, а имена файлов и модели камер не склоняются.
+ALTER TABLE photo_versions
+    ADD COLUMN camera_make TEXT DEFAULT NULL,
+    ADD COLUMN camera_model TEXT DEFAULT NULL;
+
+ALTER TABLE photos
+    ADD COLUMN search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+-- Имя файла индексируется и целиком, и по частям: IMG_1234.jpg находится и по img, и по 1234
+CREATE FUNCTION photo_search_vector(p_id INTEGER, p_title TEXT, p_caption TEXT, p_filename TEXT) RETURNS TSVECTOR
+    LANGUAGE sql STABLE AS
+$$
+SELECT setweight(to_tsvector('simple', coalesce(p_title, '')), 'A') ||
+       setweight(to_tsvector('simple', coalesce(p_caption, '')), 'B') ||
+       setweight(to_tsvector('simple', p_filename || ' ' || regexp_replace(p_filename, '[^[:alnum:]]+', ' ', 'g')), 'C') ||
+       setweight(to_tsvector('simple', coalesce((SELECT concat_ws(' ', pv.camera_make, pv.camera_model)
+                                                 FROM photo_versions pv
+                                                 WHERE pv.photo_id = p_id AND pv.version_type = 'original'), '')), 'D')
+$$;
+
+CREATE FUNCTION photos_search_vector_update() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    NEW.search_vector := photo_search_vector(NEW.id, NEW.title, NEW.caption, NEW.filename);
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER photos_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, caption, filename ON photos
+    FOR EACH ROW
+EXECUTE FUNCTION photos_search_vector_update();
+
+-- Камера берется из оригинала: документ пересобирается, когда оригинал прочитан или заменен ревизией
+CREATE FUNCTION photo_versions_search_vector_update() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    UPDATE photos
+    SET search_vector = photo_search_vector(id, title, caption, filename)
+    WHERE id = NEW.photo_id;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER photo_versions_search_vector_update
+    AFTER INSERT OR UPDATE OF camera_make, camera_model, version_type ON photo_versions
+    FOR EACH ROW
+    WHEN (NEW.version_type = 'original')
+EXECUTE FUNCTION photo_versions_search_vector_update();
+
+UPDATE photos SET search_vector = photo_search_vector(id, title, caption, filename);
+
+CREATE INDEX photos_search_vector_idx ON photos USING GIN (search_vector);
@@ -0,0 +1,16 @@
+-- Умные альбомы. Альбом не хранит фото: filter - сохраненные фильтры списка фото в JSON,
+-- по которым альбом наполняется при чтении. Опубликованный альбом доступен по public_token,
+-- metadata_policy определяет метаданные файлов публикации, как у опубликованных фото.
+CREATE TABLE albums
+(
+    id              SERIAL PRIMARY KEY,
+    user_uuid       UUID         NOT NULL,
+    title           VARCHAR(255) NOT NULL,
+    filter          JSONB        NOT NULL,
+    public_token    VARCHAR(16) UNIQUE DEFAULT NULL,
+    metadata_policy VARCHAR(16)  DEFAULT NULL,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX albums_user_uuid_idx ON albums (user_uuid);
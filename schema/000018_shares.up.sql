@@ -0,0 +1,21 @@
+-- Доступ к фото и альбомам, выданный владельцем другим пользователям. Каждая запись дает доступ
+-- ровно к одному фото или альбому. viewer может просматривать, contributor - еще и добавлять фото в альбом.
+-- Записи удаляются вместе с фото или альбомом.
+CREATE TABLE shares
+(
+    id           SERIAL PRIMARY KEY,
+    photo_id     INTEGER     DEFAULT NULL,
+    album_id     INTEGER     DEFAULT NULL,
+    grantee_uuid UUID        NOT NULL,
+    role         VARCHAR(16) NOT NULL CHECK (role IN ('viewer', 'contributor')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE,
+    FOREIGN KEY (album_id) REFERENCES albums (id) ON DELETE CASCADE,
+    CHECK ((photo_id IS NULL) <> (album_id IS NULL)),
+    CHECK (role = 'viewer' OR album_id IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX shares_photo_grantee_idx ON shares (photo_id, grantee_uuid) WHERE photo_id IS NOT NULL;
+CREATE UNIQUE INDEX shares_album_grantee_idx ON shares (album_id, grantee_uuid) WHERE album_id IS NOT NULL;
+CREATE INDEX shares_grantee_uuid_idx ON shares (grantee_uuid);
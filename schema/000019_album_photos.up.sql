@@ -0,0 +1,16 @@
+-- Фото, загруженные в альбом участниками (и владельцем). Фото остаются у загрузившего пользователя
+-- и показываются в альбоме вместе с фото владельца, отобранными фильтрами.
+-- Записи удаляются вместе с альбомом или фото.
+CREATE TABLE album_photos
+(
+    album_id         INTEGER     NOT NULL,
+    photo_id         INTEGER     NOT NULL,
+    contributor_uuid UUID        NOT NULL,
+    added_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (album_id, photo_id),
+    FOREIGN KEY (album_id) REFERENCES albums (id) ON DELETE CASCADE,
+    FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE
+);
+
+CREATE INDEX album_photos_photo_id_idx ON album_photos (photo_id);
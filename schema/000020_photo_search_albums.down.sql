@@ -0,0 +1,19 @@
+DROP TRIGGER IF EXISTS albums_search_vector_update ON albums;
+DROP FUNCTION IF EXISTS albums_search_vector_update();
+DROP TRIGGER IF EXISTS album_photos_search_vector_update ON album_photos;
+DROP FUNCTION IF EXISTS album_photos_search_vector_update();
+
+CREATE OR REPLACE FUNCTION photo_search_vector(p_id INTEGER, p_title TEXT, p_caption TEXT, p_filename TEXT) RETURNS TSVECTOR
+    LANGUAGE sql STABLE AS
+$$
+SELECT setweight(to_tsvector('simple', coalesce(p_title, '')), 'A') ||
+       setweight(to_tsvector('simple', coalesce(p_caption, '')), 'B') ||
+       setweight(to_tsvector('simple', p_filename || ' ' || regexp_replace(p_filename, '[^[:alnum:]]+', ' ', 'g')), 'C') ||
+       setweight(to_tsvector('simple', coalesce((SELECT concat_ws(' ', pv.camera_make, pv.camera_model)
+                                                 FROM photo_versions pv
+                                                 WHERE pv.photo_id = p_id AND pv.version_type = 'original'), '')), 'D')
+$$;
+
+UPDATE photos
+SET search_vector = photo_search_vector(id, title, caption, filename)
+WHERE id IN (SELECT ap.photo_id FROM album_photos ap);
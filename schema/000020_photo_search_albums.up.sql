@@ -0,0 +1,57 @@
+-- Названия альбомов, в которые фото добавлено, входят в документ поиска с весом подписи.
+-- Умные альбомы наполняются фильтрами при чтении, поэтому в документ попадают только альбомы из album_photos.
+CREATE OR REPLACE FUNCTION photo_search_vector(p_id INTEGER, p_title TEXT, p_caption TEXT, p_filename TEXT) RETURNS TSVECTOR
+    LANGUAGE sql STABLE AS
+$$
+SELECT setweight(to_tsvector('simple', coalesce(p_title, '')), 'A') ||
+       setweight(to_tsvector('simple', coalesce(p_caption, '')), 'B') ||
+       setweight(to_tsvector('simple', coalesce((SELECT string_agg(a.title, ' ' ORDER BY a.id)
+                                                 FROM album_photos ap
+                                                 JOIN albums a ON a.id = ap.album_id
+                                                 WHERE ap.photo_id = p_id), '')), 'B') ||
+       setweight(to_tsvector('simple', p_filename || ' ' || regexp_replace(p_filename, '[^[:alnum:]]+', ' ', 'g')), 'C') ||
+       setweight(to_tsvector('simple', coalesce((SELECT concat_ws(' ', pv.camera_make, pv.camera_model)
+                                                 FROM photo_versions pv
+                                                 WHERE pv.photo_id = p_id AND pv.version_type = 'original'), '')), 'D')
+$$;
+
+-- Документ пересобирается, когда фото добавлено в альбом или убрано из него (в том числе при удалении альбома)
+CREATE FUNCTION album_photos_search_vector_update() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+DECLARE
+    v_photo_id INTEGER := CASE WHEN TG_OP = 'DELETE' THEN OLD.photo_id ELSE NEW.photo_id END;
+BEGIN
+    UPDATE photos
+    SET search_vector = photo_search_vector(id, title, caption, filename)
+    WHERE id = v_photo_id;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER album_photos_search_vector_update
+    AFTER INSERT OR DELETE ON album_photos
+    FOR EACH ROW
+EXECUTE FUNCTION album_photos_search_vector_update();
+
+-- и когда альбом переименован
+CREATE FUNCTION albums_search_vector_update() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    UPDATE photos
+    SET search_vector = photo_search_vector(id, title, caption, filename)
+    WHERE id IN (SELECT ap.photo_id FROM album_photos ap WHERE ap.album_id = NEW.id);
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER albums_search_vector_update
+    AFTER UPDATE OF title ON albums
+    FOR EACH ROW
+    WHEN (OLD.title IS DISTINCT FROM NEW.title)
+EXECUTE FUNCTION albums_search_vector_update();
+
+UPDATE photos
+SET search_vector = photo_search_vector(id, title, caption, filename)
+WHERE id IN (SELECT ap.photo_id FROM album_photos ap);
@@ -0,0 +1,2 @@
+ALTER TABLE albums
+    DROP COLUMN manual;
@@ -0,0 +1,5 @@
+-- Ручные альбомы состоят только из добавленных фото (album_photos). Фото владельца попадают в ручной альбом
+-- по фильтрам, только если в filter задан хотя бы один критерий отбора: пустой фильтр не открывает
+-- участникам и зрителям всю библиотеку владельца.
+ALTER TABLE albums
+    ADD COLUMN manual BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+-- Файлы опубликованного альбома отдаются с водяным знаком владельца альбома
+ALTER TABLE albums
+    ADD COLUMN watermark BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,12 @@
+-- Теги фото. Тег хранится в нормализованном виде (без пробелов по краям, в нижнем регистре),
+-- записи удаляются вместе с фото.
+CREATE TABLE photo_tags
+(
+    photo_id INTEGER     NOT NULL,
+    tag      VARCHAR(50) NOT NULL,
+
+    PRIMARY KEY (photo_id, tag),
+    FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE
+);
+
+CREATE INDEX photo_tags_tag_idx ON photo_tags (tag);
@@ -0,0 +1,9 @@
+package schema
+
+import "embed"
+
+// FS содержит SQL миграции в формате golang-migrate (<version>_<name>.<up|down>.sql).
+// Встраивается в бинарник, чтобы миграции можно было применять без внешнего контейнера.
+//
+//go:embed *.sql
+var FS embed.FS